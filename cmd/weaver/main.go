@@ -27,6 +27,7 @@ import (
 
 	itool "github.com/ServiceWeaver/weaver/internal/tool"
 	"github.com/ServiceWeaver/weaver/internal/tool/callgraph"
+	"github.com/ServiceWeaver/weaver/internal/tool/compose"
 	"github.com/ServiceWeaver/weaver/internal/tool/generate"
 	"github.com/ServiceWeaver/weaver/internal/tool/multi"
 	"github.com/ServiceWeaver/weaver/internal/tool/single"
@@ -41,6 +42,7 @@ const usage = `USAGE
   weaver single    <command> ...  // for single process deployments
   weaver multi     <command> ...  // for multiprocess deployments
   weaver ssh       <command> ...  // for multimachine deployments
+  weaver compose   <command> ...  // for Docker Compose deployments
   weaver gke       <command> ...  // for GKE deployments
   weaver gke-local <command> ...  // for simulated GKE deployments
   weaver kube      <command> ...  // for vanilla Kubernetes deployments
@@ -49,9 +51,10 @@ DESCRIPTION
 
   Use the "weaver" command to deploy and manage Weaver applications.
 
-  The "weaver generate", "weaver version", "weaver single", "weaver multi", and
-  "weaver ssh" subcommands are baked in, but all other subcommands of the form
-  "weaver <deployer>" dispatch to a binary called "weaver-<deployer>".
+  The "weaver generate", "weaver version", "weaver single", "weaver multi",
+  "weaver ssh", and "weaver compose" subcommands are baked in, but all other
+  subcommands of the form "weaver <deployer>" dispatch to a binary called
+  "weaver-<deployer>".
   "weaver gke status", for example, dispatches to "weaver-gke status".
 `
 
@@ -66,15 +69,21 @@ func main() {
 
 	// Handle the internal deployers.
 	internals := map[string]map[string]*tool.Command{
-		"single": single.Commands,
-		"multi":  multi.Commands,
-		"ssh":    ssh.Commands,
+		"single":  single.Commands,
+		"multi":   multi.Commands,
+		"ssh":     ssh.Commands,
+		"compose": compose.Commands,
 	}
 
 	switch flag.Arg(0) {
 	case "generate":
 		generateFlags := flag.NewFlagSet("generate", flag.ExitOnError)
 		tags := generateFlags.String("tags", "", "Optional tags for the generate command")
+		http := generateFlags.Bool("http", false, "Generate JSON-over-HTTP handlers for components")
+		schema := generateFlags.Bool("schema", false, "Generate canonical format descriptors for AutoMarshal types")
+		openapi := generateFlags.Bool("openapi", false, "Generate an OpenAPI document for the JSON-over-HTTP handlers")
+		cli := generateFlags.Bool("cli", false, "Generate a command-line client for components")
+		fuzz := generateFlags.Bool("fuzz", false, "Generate round-trip fuzz tests for AutoMarshal types")
 		generateFlags.Usage = func() {
 			fmt.Fprintln(os.Stderr, generate.Usage)
 		}
@@ -86,7 +95,7 @@ func main() {
 			// extra validation at some point.
 			buildTags = buildTags + "," + *tags
 		}
-		if err := generate.Generate(".", generateFlags.Args(), generate.Options{BuildTags: buildTags}); err != nil {
+		if err := generate.Generate(".", generateFlags.Args(), generate.Options{BuildTags: buildTags, HTTP: *http, Schema: *schema, OpenAPI: *openapi, CLI: *cli, Fuzz: *fuzz}); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
@@ -128,7 +137,7 @@ Description:
 		fmt.Println(s)
 		return
 
-	case "single", "multi", "ssh":
+	case "single", "multi", "ssh", "compose":
 		os.Args = os.Args[1:]
 		tool.Run("weaver "+flag.Arg(0), internals[flag.Arg(0)])
 		return
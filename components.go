@@ -0,0 +1,184 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ServiceWeaver/weaver/internal/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+// ComponentInfo describes a component registered in the current deployment.
+type ComponentInfo struct {
+	Name    string   // full package-prefixed component name
+	Methods []string // names of the component's methods, sorted
+	Healthy bool     // whether the component reports that it is healthy
+	Err     error    // the reason the component is unhealthy, if any
+}
+
+// healthChecker is the interface implemented by components that report their
+// own health.
+type healthChecker interface {
+	Healthy(context.Context) error
+}
+
+// ListComponents returns information about every component registered in the
+// current deployment, sorted by name. ctx must be derived from the context
+// passed to the function given to Run.
+//
+// A component is healthy if it can be constructed and, if its interface has a
+// method
+//
+//	Healthy(context.Context) error
+//
+// that method returns nil. Note that ListComponents constructs every
+// component that has not been constructed yet.
+func ListComponents(ctx context.Context) ([]ComponentInfo, error) {
+	wlet, ok := weaver.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("ListComponents: context not derived from weaver.Run")
+	}
+
+	regs := codegen.Registered()
+	infos := make([]ComponentInfo, 0, len(regs))
+	for _, reg := range regs {
+		info := ComponentInfo{Name: reg.Name}
+		c, err := wlet.GetIntf(reg.Iface)
+		if err != nil {
+			info.Err = err
+			infos = append(infos, info)
+			continue
+		}
+
+		server := reg.ServerStubFn(c, func(uint64, float64) {})
+		for i := 0; i < reg.Iface.NumMethod(); i++ {
+			name := reg.Iface.Method(i).Name
+			if server.GetStubFn(name) != nil {
+				info.Methods = append(info.Methods, name)
+			}
+		}
+
+		if h, ok := c.(healthChecker); ok {
+			info.Err = h.Healthy(ctx)
+		}
+		info.Healthy = info.Err == nil
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// HealthStatus is the health of a component, as reported by its
+// "Healthy(context.Context) error" method. See OnHealthChange.
+type HealthStatus = weaver.HealthStatus
+
+const (
+	HealthUnknown   = weaver.HealthUnknown   // not polled yet
+	HealthHealthy   = weaver.HealthHealthy   // Healthy returned nil
+	HealthUnhealthy = weaver.HealthUnhealthy // Healthy returned an error
+)
+
+// OnHealthChange subscribes f to the health status transitions of the
+// component with the provided full package-prefixed name, e.g.,
+// "github.com/example/catalog/Catalog". ctx must be derived from the context
+// passed to the function given to Run, and the component's interface must have
+// a method
+//
+//	Healthy(context.Context) error
+//
+// Service Weaver polls the component's health in the background and calls f
+// with the old and new status every time the status changes, including once
+// the first poll changes it from HealthUnknown. Calls to f are made
+// sequentially. Polling stops when ctx is done or when the application shuts
+// down.
+//
+// The health of a component is polled every 10 seconds by default, and every
+// wait between two polls is randomly adjusted by up to 20% so that different
+// subscribers don't poll in lockstep. Both can be changed in the
+// "[health_polling]" section of the application config:
+//
+//	[health_polling]
+//	interval = "5s"
+//	jitter = 0.1
+func OnHealthChange(ctx context.Context, component string, f func(old, new HealthStatus)) error {
+	wlet, ok := weaver.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("OnHealthChange: context not derived from weaver.Run")
+	}
+	return wlet.OnHealthChange(ctx, component, f)
+}
+
+// Placement is where a component runs, relative to the current process. See
+// Inspect.
+type Placement = weaver.Placement
+
+const (
+	PlacementUnknown = weaver.PlacementUnknown // not known yet
+	PlacementLocal   = weaver.PlacementLocal   // in the current process
+	PlacementRemote  = weaver.PlacementRemote  // in another process
+)
+
+// ComponentDescription describes a registered component. See Inspect.
+type ComponentDescription struct {
+	Name      string              // full package-prefixed component name
+	Methods   []MethodDescription // methods of the component interface, sorted by name
+	Placement Placement           // where the component runs
+	RefData   string              // the components it references, see codegen.ExtractEdges
+}
+
+// MethodDescription describes a method of a component interface.
+type MethodDescription struct {
+	Name      string // method name, e.g., "Get"
+	Signature string // method signature, e.g., "func(context.Context, string) (int, error)"
+}
+
+// Inspect returns a description of every component registered in the binary,
+// sorted by name. ctx must be derived from the context passed to the function
+// given to Run.
+//
+// Unlike ListComponents, Inspect doesn't construct or activate any component.
+// A component's placement is PlacementUnknown until it is activated, which
+// typically happens the first time it is called.
+func Inspect(ctx context.Context) ([]ComponentDescription, error) {
+	wlet, ok := weaver.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("Inspect: context not derived from weaver.Run")
+	}
+	return inspect(codegen.Registered(), wlet.Placement), nil
+}
+
+// inspect returns a description of the provided components, sorted by name.
+// placement returns the placement of a component, given its full name.
+func inspect(regs []*codegen.Registration, placement func(string) Placement) []ComponentDescription {
+	descs := make([]ComponentDescription, 0, len(regs))
+	for _, reg := range regs {
+		desc := ComponentDescription{
+			Name:      reg.Name,
+			Methods:   make([]MethodDescription, reg.Iface.NumMethod()),
+			Placement: placement(reg.Name),
+			RefData:   reg.RefData,
+		}
+		for i := range desc.Methods {
+			m := reg.Iface.Method(i)
+			desc.Methods[i] = MethodDescription{Name: m.Name, Signature: m.Type.String()}
+		}
+		descs = append(descs, desc)
+	}
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Name < descs[j].Name })
+	return descs
+}
@@ -60,7 +60,7 @@ func (s t_local_stub) AddContact(ctx context.Context, a0 string, a1 Contact) (er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "contacts.T.AddContact", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "AddContact", "contacts.T.AddContact", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -70,6 +70,13 @@ func (s t_local_stub) AddContact(ctx context.Context, a0 string, a1 Contact) (er
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "AddContact", func(ctx context.Context) (err error) {
+			err = s.impl.AddContact(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.AddContact(ctx, a0, a1)
 }
 
@@ -80,7 +87,7 @@ func (s t_local_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "contacts.T.GetContacts", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "GetContacts", "contacts.T.GetContacts", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -90,6 +97,13 @@ func (s t_local_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact,
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "GetContacts", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetContacts(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetContacts(ctx, a0)
 }
 
@@ -113,7 +127,7 @@ func (s t_client_stub) AddContact(ctx context.Context, a0 string, a1 Contact) (e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "contacts.T.AddContact", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "AddContact", "contacts.T.AddContact", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -121,6 +135,7 @@ func (s t_client_stub) AddContact(ctx context.Context, a0 string, a1 Contact) (e
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.addContactMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -131,6 +146,8 @@ func (s t_client_stub) AddContact(ctx context.Context, a0 string, a1 Contact) (e
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -145,20 +162,26 @@ func (s t_client_stub) AddContact(ctx context.Context, a0 string, a1 Contact) (e
 	(a1).WeaverMarshal(enc)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s t_client_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact, err error) {
@@ -170,7 +193,7 @@ func (s t_client_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "contacts.T.GetContacts", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "GetContacts", "contacts.T.GetContacts", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -178,6 +201,7 @@ func (s t_client_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getContactsMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -188,6 +212,8 @@ func (s t_client_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -200,30 +226,36 @@ func (s t_client_stub) GetContacts(ctx context.Context, a0 string) (r0 []Contact
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_Contact_d00a3378(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_Contact_d00a3378(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -263,24 +295,42 @@ func (s t_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s t_server_stub) addContact(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 Contact
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "AddContact", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 Contact
 	(&a1).WeaverUnmarshal(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.AddContact(ctx, a0, a1)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "AddContact", func(ctx context.Context) (err error) {
+			err = s.impl.AddContact(ctx, a0, a1)
+			return err
+		})
+	} else {
+		appErr = s.impl.AddContact(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -289,22 +339,41 @@ func (s t_server_stub) addContact(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s t_server_stub) getContacts(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "GetContacts", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetContacts(ctx, a0)
+	var r0 []Contact
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/contacts/T", "GetContacts", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetContacts(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetContacts(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -369,6 +438,21 @@ func (x *Contact) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.IsExternal = dec.Bool()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *Contact) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Username", Value: x.Username},
+		codegen.DebugField{Name: "Label", Value: x.Label},
+		codegen.DebugField{Name: "AccountNum", Value: x.AccountNum},
+		codegen.DebugField{Name: "RoutingNum", Value: x.RoutingNum},
+		codegen.DebugField{Name: "IsExternal", Value: x.IsExternal},
+	)
+}
+
 // Encoding/decoding implementations.
 
 func serviceweaver_enc_slice_Contact_d00a3378(enc *codegen.Encoder, arg []Contact) {
@@ -387,7 +471,7 @@ func serviceweaver_dec_slice_Contact_d00a3378(dec *codegen.Decoder) []Contact {
 	if n == -1 {
 		return nil
 	}
-	res := make([]Contact, n)
+	res := codegen.MakeSlice[Contact](dec, n)
 	for i := 0; i < n; i++ {
 		(&res[i]).WeaverUnmarshal(dec)
 	}
@@ -59,7 +59,7 @@ func (s t_local_stub) AddTransaction(ctx context.Context, a0 string, a1 string,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "ledgerwriter.T.AddTransaction", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T", "AddTransaction", "ledgerwriter.T.AddTransaction", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -69,6 +69,13 @@ func (s t_local_stub) AddTransaction(ctx context.Context, a0 string, a1 string,
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T", "AddTransaction", func(ctx context.Context) (err error) {
+			err = s.impl.AddTransaction(ctx, a0, a1, a2)
+			return err
+		})
+		return
+	}
 	return s.impl.AddTransaction(ctx, a0, a1, a2)
 }
 
@@ -91,7 +98,7 @@ func (s t_client_stub) AddTransaction(ctx context.Context, a0 string, a1 string,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "ledgerwriter.T.AddTransaction", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T", "AddTransaction", "ledgerwriter.T.AddTransaction", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -99,6 +106,7 @@ func (s t_client_stub) AddTransaction(ctx context.Context, a0 string, a1 string,
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.addTransactionMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -109,6 +117,8 @@ func (s t_client_stub) AddTransaction(ctx context.Context, a0 string, a1 string,
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -118,29 +128,35 @@ func (s t_client_stub) AddTransaction(ctx context.Context, a0 string, a1 string,
 	(a2).WeaverMarshal(enc)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -178,26 +194,44 @@ func (s t_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s t_server_stub) addTransaction(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 string
+	var a2 model.Transaction
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T", "AddTransaction", r, a0, a1, a2)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 string
 	a1 = dec.String()
-	var a2 model.Transaction
 	(&a2).WeaverUnmarshal(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.AddTransaction(ctx, a0, a1, a2)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/ledgerwriter/T", "AddTransaction", func(ctx context.Context) (err error) {
+			err = s.impl.AddTransaction(ctx, a0, a1, a2)
+			return err
+		})
+	} else {
+		appErr = s.impl.AddTransaction(ctx, a0, a1, a2)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -21,10 +21,10 @@ import (
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -85,6 +85,22 @@ func (x *Transaction) WeaverUnmarshal(dec *codegen.Decoder) {
 	dec.DecodeBinaryUnmarshaler(&x.Timestamp)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *Transaction) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "FromAccountNum", Value: x.FromAccountNum},
+		codegen.DebugField{Name: "FromRoutingNum", Value: x.FromRoutingNum},
+		codegen.DebugField{Name: "ToAccountNum", Value: x.ToAccountNum},
+		codegen.DebugField{Name: "ToRoutingNum", Value: x.ToRoutingNum},
+		codegen.DebugField{Name: "Amount", Value: x.Amount},
+		codegen.DebugField{Name: "Timestamp", Value: x.Timestamp},
+	)
+}
+
 var _ codegen.AutoMarshal = (*TransactionWithID)(nil)
 
 type __is_TransactionWithID[T ~struct {
@@ -110,3 +126,15 @@ func (x *TransactionWithID) WeaverUnmarshal(dec *codegen.Decoder) {
 	(&x.Transaction).WeaverUnmarshal(dec)
 	x.TransactionID = dec.Int64()
 }
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *TransactionWithID) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Transaction", Value: x.Transaction},
+		codegen.DebugField{Name: "TransactionID", Value: x.TransactionID},
+	)
+}
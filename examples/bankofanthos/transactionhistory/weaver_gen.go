@@ -59,7 +59,7 @@ func (s t_local_stub) GetTransactions(ctx context.Context, a0 string) (r0 []mode
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "transactionhistory.T.GetTransactions", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T", "GetTransactions", "transactionhistory.T.GetTransactions", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -69,6 +69,13 @@ func (s t_local_stub) GetTransactions(ctx context.Context, a0 string) (r0 []mode
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T", "GetTransactions", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetTransactions(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetTransactions(ctx, a0)
 }
 
@@ -91,7 +98,7 @@ func (s t_client_stub) GetTransactions(ctx context.Context, a0 string) (r0 []mod
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "transactionhistory.T.GetTransactions", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T", "GetTransactions", "transactionhistory.T.GetTransactions", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -99,6 +106,7 @@ func (s t_client_stub) GetTransactions(ctx context.Context, a0 string) (r0 []mod
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getTransactionsMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -109,6 +117,8 @@ func (s t_client_stub) GetTransactions(ctx context.Context, a0 string) (r0 []mod
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -121,30 +131,36 @@ func (s t_client_stub) GetTransactions(ctx context.Context, a0 string) (r0 []mod
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_Transaction_d2a36fba(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_Transaction_d2a36fba(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -182,22 +198,41 @@ func (s t_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s t_server_stub) getTransactions(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T", "GetTransactions", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetTransactions(ctx, a0)
+	var r0 []model.Transaction
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/transactionhistory/T", "GetTransactions", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetTransactions(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetTransactions(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -238,7 +273,7 @@ func serviceweaver_dec_slice_Transaction_d2a36fba(dec *codegen.Decoder) []model.
 	if n == -1 {
 		return nil
 	}
-	res := make([]model.Transaction, n)
+	res := codegen.MakeSlice[model.Transaction](dec, n)
 	for i := 0; i < n; i++ {
 		(&res[i]).WeaverUnmarshal(dec)
 	}
@@ -60,7 +60,7 @@ func (s t_local_stub) CreateUser(ctx context.Context, a0 CreateUserRequest) (err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "userservice.T.CreateUser", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "CreateUser", "userservice.T.CreateUser", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -70,6 +70,13 @@ func (s t_local_stub) CreateUser(ctx context.Context, a0 CreateUserRequest) (err
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "CreateUser", func(ctx context.Context) (err error) {
+			err = s.impl.CreateUser(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.CreateUser(ctx, a0)
 }
 
@@ -80,7 +87,7 @@ func (s t_local_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "userservice.T.Login", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "Login", "userservice.T.Login", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -90,6 +97,13 @@ func (s t_local_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, er
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "Login", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Login(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Login(ctx, a0)
 }
 
@@ -113,7 +127,7 @@ func (s t_client_stub) CreateUser(ctx context.Context, a0 CreateUserRequest) (er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "userservice.T.CreateUser", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "CreateUser", "userservice.T.CreateUser", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -121,6 +135,7 @@ func (s t_client_stub) CreateUser(ctx context.Context, a0 CreateUserRequest) (er
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.createUserMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -131,6 +146,8 @@ func (s t_client_stub) CreateUser(ctx context.Context, a0 CreateUserRequest) (er
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -143,20 +160,26 @@ func (s t_client_stub) CreateUser(ctx context.Context, a0 CreateUserRequest) (er
 	(a0).WeaverMarshal(enc)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s t_client_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, err error) {
@@ -168,7 +191,7 @@ func (s t_client_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "userservice.T.Login", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "Login", "userservice.T.Login", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -176,6 +199,7 @@ func (s t_client_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, e
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.loginMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -186,6 +210,8 @@ func (s t_client_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, e
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -198,30 +224,36 @@ func (s t_client_stub) Login(ctx context.Context, a0 LoginRequest) (r0 string, e
 	(a0).WeaverMarshal(enc)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.String()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.String()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -261,22 +293,40 @@ func (s t_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s t_server_stub) createUser(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 CreateUserRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "CreateUser", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 CreateUserRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.CreateUser(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "CreateUser", func(ctx context.Context) (err error) {
+			err = s.impl.CreateUser(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.CreateUser(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -285,22 +335,41 @@ func (s t_server_stub) createUser(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s t_server_stub) login(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 LoginRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "Login", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 LoginRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Login(ctx, a0)
+	var r0 string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/bankofanthos/userservice/T", "Login", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Login(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Login(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -383,6 +452,27 @@ func (x *CreateUserRequest) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.Ssn = dec.String()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *CreateUserRequest) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Username", Value: x.Username},
+		codegen.DebugField{Name: "Password", Value: x.Password},
+		codegen.DebugField{Name: "PasswordRepeat", Value: x.PasswordRepeat},
+		codegen.DebugField{Name: "FirstName", Value: x.FirstName},
+		codegen.DebugField{Name: "LastName", Value: x.LastName},
+		codegen.DebugField{Name: "Birthday", Value: x.Birthday},
+		codegen.DebugField{Name: "Timezone", Value: x.Timezone},
+		codegen.DebugField{Name: "Address", Value: x.Address},
+		codegen.DebugField{Name: "State", Value: x.State},
+		codegen.DebugField{Name: "Zip", Value: x.Zip},
+		codegen.DebugField{Name: "Ssn", Value: x.Ssn},
+	)
+}
+
 var _ codegen.AutoMarshal = (*LoginRequest)(nil)
 
 type __is_LoginRequest[T ~struct {
@@ -409,6 +499,18 @@ func (x *LoginRequest) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.Password = dec.String()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *LoginRequest) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Username", Value: x.Username},
+		codegen.DebugField{Name: "Password", Value: x.Password},
+	)
+}
+
 var _ codegen.AutoMarshal = (*User)(nil)
 
 type __is_User[T ~struct {
@@ -462,27 +564,33 @@ func (x *User) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.SSN = dec.String()
 }
 
-func serviceweaver_enc_slice_byte_87461245(enc *codegen.Encoder, arg []byte) {
-	if arg == nil {
-		enc.Len(-1)
-		return
-	}
-	enc.Len(len(arg))
-	for i := 0; i < len(arg); i++ {
-		enc.Byte(arg[i])
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *User) DebugString() string {
+	if x == nil {
+		return "null"
 	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "AccountID", Value: x.AccountID},
+		codegen.DebugField{Name: "Username", Value: x.Username},
+		codegen.DebugField{Name: "Passhash", Value: x.Passhash},
+		codegen.DebugField{Name: "Firstname", Value: x.Firstname},
+		codegen.DebugField{Name: "Lastname", Value: x.Lastname},
+		codegen.DebugField{Name: "Birthday", Value: x.Birthday},
+		codegen.DebugField{Name: "Timezone", Value: x.Timezone},
+		codegen.DebugField{Name: "Address", Value: x.Address},
+		codegen.DebugField{Name: "State", Value: x.State},
+		codegen.DebugField{Name: "Zip", Value: x.Zip},
+		codegen.DebugField{Name: "SSN", Value: x.SSN},
+	)
+}
+
+func serviceweaver_enc_slice_byte_87461245(enc *codegen.Encoder, arg []byte) {
+	enc.ByteSlice(arg)
 }
 
 func serviceweaver_dec_slice_byte_87461245(dec *codegen.Decoder) []byte {
-	n := dec.Len()
-	if n == -1 {
-		return nil
-	}
-	res := make([]byte, n)
-	for i := 0; i < n; i++ {
-		res[i] = dec.Byte()
-	}
-	return res
+	return dec.ByteSlice()
 }
 
 // Size implementations.
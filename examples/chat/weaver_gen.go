@@ -120,7 +120,7 @@ func (s imageScaler_local_stub) Scale(ctx context.Context, a0 []byte, a1 int, a2
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.ImageScaler.Scale", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/ImageScaler", "Scale", "main.ImageScaler.Scale", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -130,6 +130,13 @@ func (s imageScaler_local_stub) Scale(ctx context.Context, a0 []byte, a1 int, a2
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/ImageScaler") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/ImageScaler", "Scale", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Scale(ctx, a0, a1, a2)
+			return err
+		})
+		return
+	}
 	return s.impl.Scale(ctx, a0, a1, a2)
 }
 
@@ -150,7 +157,7 @@ func (s localCache_local_stub) Get(ctx context.Context, a0 string) (r0 string, e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.LocalCache.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Get", "main.LocalCache.Get", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -160,6 +167,13 @@ func (s localCache_local_stub) Get(ctx context.Context, a0 string) (r0 string, e
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/LocalCache") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Get(ctx, a0)
 }
 
@@ -170,7 +184,7 @@ func (s localCache_local_stub) Put(ctx context.Context, a0 string, a1 string) (e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.LocalCache.Put", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Put", "main.LocalCache.Put", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -180,6 +194,13 @@ func (s localCache_local_stub) Put(ctx context.Context, a0 string, a1 string) (e
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/LocalCache") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Put", func(ctx context.Context) (err error) {
+			err = s.impl.Put(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Put(ctx, a0, a1)
 }
 
@@ -210,7 +231,7 @@ func (s sQLStore_local_stub) CreatePost(ctx context.Context, a0 string, a1 time.
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.SQLStore.CreatePost", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreatePost", "main.SQLStore.CreatePost", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -220,6 +241,13 @@ func (s sQLStore_local_stub) CreatePost(ctx context.Context, a0 string, a1 time.
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreatePost", func(ctx context.Context) (err error) {
+			err = s.impl.CreatePost(ctx, a0, a1, a2, a3)
+			return err
+		})
+		return
+	}
 	return s.impl.CreatePost(ctx, a0, a1, a2, a3)
 }
 
@@ -230,7 +258,7 @@ func (s sQLStore_local_stub) CreateThread(ctx context.Context, a0 string, a1 tim
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.SQLStore.CreateThread", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreateThread", "main.SQLStore.CreateThread", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -240,6 +268,13 @@ func (s sQLStore_local_stub) CreateThread(ctx context.Context, a0 string, a1 tim
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreateThread", func(ctx context.Context) (err error) {
+			r0, err = s.impl.CreateThread(ctx, a0, a1, a2, a3, a4)
+			return err
+		})
+		return
+	}
 	return s.impl.CreateThread(ctx, a0, a1, a2, a3, a4)
 }
 
@@ -250,7 +285,7 @@ func (s sQLStore_local_stub) GetFeed(ctx context.Context, a0 string) (r0 []Threa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.SQLStore.GetFeed", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetFeed", "main.SQLStore.GetFeed", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -260,6 +295,13 @@ func (s sQLStore_local_stub) GetFeed(ctx context.Context, a0 string) (r0 []Threa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetFeed", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetFeed(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetFeed(ctx, a0)
 }
 
@@ -270,7 +312,7 @@ func (s sQLStore_local_stub) GetImage(ctx context.Context, a0 string, a1 ImageID
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.SQLStore.GetImage", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetImage", "main.SQLStore.GetImage", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -280,6 +322,13 @@ func (s sQLStore_local_stub) GetImage(ctx context.Context, a0 string, a1 ImageID
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetImage", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetImage(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.GetImage(ctx, a0, a1)
 }
 
@@ -302,7 +351,7 @@ func (s imageScaler_client_stub) Scale(ctx context.Context, a0 []byte, a1 int, a
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.ImageScaler.Scale", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/ImageScaler", "Scale", "main.ImageScaler.Scale", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -310,6 +359,7 @@ func (s imageScaler_client_stub) Scale(ctx context.Context, a0 []byte, a1 int, a
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.scaleMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -320,6 +370,8 @@ func (s imageScaler_client_stub) Scale(ctx context.Context, a0 []byte, a1 int, a
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -336,21 +388,27 @@ func (s imageScaler_client_stub) Scale(ctx context.Context, a0 []byte, a1 int, a
 	enc.Int(a2)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_byte_87461245(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_byte_87461245(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type localCache_client_stub struct {
@@ -371,7 +429,7 @@ func (s localCache_client_stub) Get(ctx context.Context, a0 string) (r0 string,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.LocalCache.Get", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Get", "main.LocalCache.Get", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -379,6 +437,7 @@ func (s localCache_client_stub) Get(ctx context.Context, a0 string) (r0 string,
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -389,6 +448,8 @@ func (s localCache_client_stub) Get(ctx context.Context, a0 string) (r0 string,
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -401,21 +462,27 @@ func (s localCache_client_stub) Get(ctx context.Context, a0 string) (r0 string,
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.String()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.String()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s localCache_client_stub) Put(ctx context.Context, a0 string, a1 string) (err error) {
@@ -427,7 +494,7 @@ func (s localCache_client_stub) Put(ctx context.Context, a0 string, a1 string) (
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.LocalCache.Put", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Put", "main.LocalCache.Put", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -435,6 +502,7 @@ func (s localCache_client_stub) Put(ctx context.Context, a0 string, a1 string) (
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.putMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -445,6 +513,8 @@ func (s localCache_client_stub) Put(ctx context.Context, a0 string, a1 string) (
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -459,20 +529,26 @@ func (s localCache_client_stub) Put(ctx context.Context, a0 string, a1 string) (
 	enc.String(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type main_client_stub struct {
@@ -502,7 +578,7 @@ func (s sQLStore_client_stub) CreatePost(ctx context.Context, a0 string, a1 time
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.SQLStore.CreatePost", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreatePost", "main.SQLStore.CreatePost", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -510,6 +586,7 @@ func (s sQLStore_client_stub) CreatePost(ctx context.Context, a0 string, a1 time
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.createPostMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -520,6 +597,8 @@ func (s sQLStore_client_stub) CreatePost(ctx context.Context, a0 string, a1 time
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -530,20 +609,26 @@ func (s sQLStore_client_stub) CreatePost(ctx context.Context, a0 string, a1 time
 	enc.String(a3)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s sQLStore_client_stub) CreateThread(ctx context.Context, a0 string, a1 time.Time, a2 []string, a3 string, a4 []byte) (r0 ThreadID, err error) {
@@ -555,7 +640,7 @@ func (s sQLStore_client_stub) CreateThread(ctx context.Context, a0 string, a1 ti
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.SQLStore.CreateThread", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreateThread", "main.SQLStore.CreateThread", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -563,6 +648,7 @@ func (s sQLStore_client_stub) CreateThread(ctx context.Context, a0 string, a1 ti
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.createThreadMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -573,6 +659,8 @@ func (s sQLStore_client_stub) CreateThread(ctx context.Context, a0 string, a1 ti
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -584,21 +672,27 @@ func (s sQLStore_client_stub) CreateThread(ctx context.Context, a0 string, a1 ti
 	serviceweaver_enc_slice_byte_87461245(enc, a4)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	*(*int64)(&r0) = dec.Int64()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		*(*int64)(&r0) = dec.Int64()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s sQLStore_client_stub) GetFeed(ctx context.Context, a0 string) (r0 []Thread, err error) {
@@ -610,7 +704,7 @@ func (s sQLStore_client_stub) GetFeed(ctx context.Context, a0 string) (r0 []Thre
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.SQLStore.GetFeed", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetFeed", "main.SQLStore.GetFeed", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -618,6 +712,7 @@ func (s sQLStore_client_stub) GetFeed(ctx context.Context, a0 string) (r0 []Thre
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getFeedMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -628,6 +723,8 @@ func (s sQLStore_client_stub) GetFeed(ctx context.Context, a0 string) (r0 []Thre
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 2, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -640,21 +737,27 @@ func (s sQLStore_client_stub) GetFeed(ctx context.Context, a0 string) (r0 []Thre
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 2, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_Thread_511e1469(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_Thread_511e1469(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s sQLStore_client_stub) GetImage(ctx context.Context, a0 string, a1 ImageID) (r0 []byte, err error) {
@@ -666,7 +769,7 @@ func (s sQLStore_client_stub) GetImage(ctx context.Context, a0 string, a1 ImageI
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.SQLStore.GetImage", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetImage", "main.SQLStore.GetImage", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -674,6 +777,7 @@ func (s sQLStore_client_stub) GetImage(ctx context.Context, a0 string, a1 ImageI
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getImageMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -684,6 +788,8 @@ func (s sQLStore_client_stub) GetImage(ctx context.Context, a0 string, a1 ImageI
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 3, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -698,30 +804,36 @@ func (s sQLStore_client_stub) GetImage(ctx context.Context, a0 string, a1 ImageI
 	enc.Int64((int64)(a1))
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 3, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_byte_87461245(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_byte_87461245(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -759,26 +871,45 @@ func (s imageScaler_server_stub) GetStubFn(method string) func(ctx context.Conte
 }
 
 func (s imageScaler_server_stub) scale(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 []byte
+	var a1 int
+	var a2 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/ImageScaler", "Scale", r, a0, a1, a2)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 []byte
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_slice_byte_87461245(dec)
-	var a1 int
 	a1 = dec.Int()
-	var a2 int
 	a2 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Scale(ctx, a0, a1, a2)
+	var r0 []byte
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/ImageScaler") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/ImageScaler", "Scale", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Scale(ctx, a0, a1, a2)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Scale(ctx, a0, a1, a2)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -808,22 +939,41 @@ func (s localCache_server_stub) GetStubFn(method string) func(ctx context.Contex
 }
 
 func (s localCache_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Get", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Get(ctx, a0)
+	var r0 string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/LocalCache") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Get(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -833,24 +983,42 @@ func (s localCache_server_stub) get(ctx context.Context, args []byte) (res []byt
 }
 
 func (s localCache_server_stub) put(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Put", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 string
 	a1 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Put(ctx, a0, a1)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/LocalCache") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/LocalCache", "Put", func(ctx context.Context) (err error) {
+			err = s.impl.Put(ctx, a0, a1)
+			return err
+		})
+	} else {
+		appErr = s.impl.Put(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -899,28 +1067,46 @@ func (s sQLStore_server_stub) GetStubFn(method string) func(ctx context.Context,
 }
 
 func (s sQLStore_server_stub) createPost(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 time.Time
+	var a2 ThreadID
+	var a3 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreatePost", r, a0, a1, a2, a3)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 time.Time
 	dec.DecodeBinaryUnmarshaler(&a1)
-	var a2 ThreadID
 	*(*int64)(&a2) = dec.Int64()
-	var a3 string
 	a3 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.CreatePost(ctx, a0, a1, a2, a3)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreatePost", func(ctx context.Context) (err error) {
+			err = s.impl.CreatePost(ctx, a0, a1, a2, a3)
+			return err
+		})
+	} else {
+		appErr = s.impl.CreatePost(ctx, a0, a1, a2, a3)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -929,30 +1115,49 @@ func (s sQLStore_server_stub) createPost(ctx context.Context, args []byte) (res
 }
 
 func (s sQLStore_server_stub) createThread(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 time.Time
+	var a2 []string
+	var a3 string
+	var a4 []byte
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreateThread", r, a0, a1, a2, a3, a4)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 time.Time
 	dec.DecodeBinaryUnmarshaler(&a1)
-	var a2 []string
 	a2 = serviceweaver_dec_slice_string_4af10117(dec)
-	var a3 string
 	a3 = dec.String()
-	var a4 []byte
 	a4 = serviceweaver_dec_slice_byte_87461245(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.CreateThread(ctx, a0, a1, a2, a3, a4)
+	var r0 ThreadID
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "CreateThread", func(ctx context.Context) (err error) {
+			r0, err = s.impl.CreateThread(ctx, a0, a1, a2, a3, a4)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.CreateThread(ctx, a0, a1, a2, a3, a4)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -962,22 +1167,41 @@ func (s sQLStore_server_stub) createThread(ctx context.Context, args []byte) (re
 }
 
 func (s sQLStore_server_stub) getFeed(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetFeed", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetFeed(ctx, a0)
+	var r0 []Thread
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetFeed", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetFeed(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetFeed(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -987,24 +1211,43 @@ func (s sQLStore_server_stub) getFeed(ctx context.Context, args []byte) (res []b
 }
 
 func (s sQLStore_server_stub) getImage(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 ImageID
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetImage", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 ImageID
 	*(*int64)(&a1) = dec.Int64()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetImage(ctx, a0, a1)
+	var r0 []byte
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/chat/SQLStore") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/chat/SQLStore", "GetImage", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetImage(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetImage(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1115,6 +1358,21 @@ func (x *Post) WeaverUnmarshal(dec *codegen.Decoder) {
 	*(*int64)(&x.ImageID) = dec.Int64()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *Post) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "ID", Value: x.ID},
+		codegen.DebugField{Name: "Creator", Value: x.Creator},
+		codegen.DebugField{Name: "When", Value: x.When},
+		codegen.DebugField{Name: "Text", Value: x.Text},
+		codegen.DebugField{Name: "ImageID", Value: x.ImageID},
+	)
+}
+
 var _ codegen.AutoMarshal = (*Thread)(nil)
 
 type __is_Thread[T ~struct {
@@ -1141,6 +1399,18 @@ func (x *Thread) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.Posts = serviceweaver_dec_slice_Post_29a9ee83(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *Thread) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "ID", Value: x.ID},
+		codegen.DebugField{Name: "Posts", Value: x.Posts},
+	)
+}
+
 func serviceweaver_enc_slice_Post_29a9ee83(enc *codegen.Encoder, arg []Post) {
 	if arg == nil {
 		enc.Len(-1)
@@ -1157,7 +1427,7 @@ func serviceweaver_dec_slice_Post_29a9ee83(dec *codegen.Decoder) []Post {
 	if n == -1 {
 		return nil
 	}
-	res := make([]Post, n)
+	res := codegen.MakeSlice[Post](dec, n)
 	for i := 0; i < n; i++ {
 		(&res[i]).WeaverUnmarshal(dec)
 	}
@@ -1167,26 +1437,11 @@ func serviceweaver_dec_slice_Post_29a9ee83(dec *codegen.Decoder) []Post {
 // Encoding/decoding implementations.
 
 func serviceweaver_enc_slice_byte_87461245(enc *codegen.Encoder, arg []byte) {
-	if arg == nil {
-		enc.Len(-1)
-		return
-	}
-	enc.Len(len(arg))
-	for i := 0; i < len(arg); i++ {
-		enc.Byte(arg[i])
-	}
+	enc.ByteSlice(arg)
 }
 
 func serviceweaver_dec_slice_byte_87461245(dec *codegen.Decoder) []byte {
-	n := dec.Len()
-	if n == -1 {
-		return nil
-	}
-	res := make([]byte, n)
-	for i := 0; i < n; i++ {
-		res[i] = dec.Byte()
-	}
-	return res
+	return dec.ByteSlice()
 }
 
 func serviceweaver_enc_slice_string_4af10117(enc *codegen.Encoder, arg []string) {
@@ -1205,7 +1460,7 @@ func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
 	if n == -1 {
 		return nil
 	}
-	res := make([]string, n)
+	res := codegen.MakeSlice[string](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.String()
 	}
@@ -1228,7 +1483,7 @@ func serviceweaver_dec_slice_Thread_511e1469(dec *codegen.Decoder) []Thread {
 	if n == -1 {
 		return nil
 	}
-	res := make([]Thread, n)
+	res := codegen.MakeSlice[Thread](dec, n)
 	for i := 0; i < n; i++ {
 		(&res[i]).WeaverUnmarshal(dec)
 	}
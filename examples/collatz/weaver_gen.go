@@ -97,7 +97,7 @@ func (s even_local_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.Even.Do", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/collatz/Even", "Do", "main.Even.Do", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -107,6 +107,13 @@ func (s even_local_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/collatz/Even") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/collatz/Even", "Do", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Do(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Do(ctx, a0)
 }
 
@@ -134,7 +141,7 @@ func (s odd_local_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.Odd.Do", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/collatz/Odd", "Do", "main.Odd.Do", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -144,6 +151,13 @@ func (s odd_local_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/collatz/Odd") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/collatz/Odd", "Do", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Do(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Do(ctx, a0)
 }
 
@@ -166,7 +180,7 @@ func (s even_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.Even.Do", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/collatz/Even", "Do", "main.Even.Do", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -174,6 +188,7 @@ func (s even_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.doMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -184,6 +199,8 @@ func (s even_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -196,21 +213,27 @@ func (s even_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type main_client_stub struct {
@@ -237,7 +260,7 @@ func (s odd_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.Odd.Do", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/collatz/Odd", "Do", "main.Odd.Do", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -245,6 +268,7 @@ func (s odd_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.doMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -255,6 +279,8 @@ func (s odd_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -267,30 +293,36 @@ func (s odd_client_stub) Do(ctx context.Context, a0 int) (r0 int, err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -328,22 +360,41 @@ func (s even_server_stub) GetStubFn(method string) func(ctx context.Context, arg
 }
 
 func (s even_server_stub) do(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/collatz/Even", "Do", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Do(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/collatz/Even") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/collatz/Even", "Do", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Do(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Do(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -387,22 +438,41 @@ func (s odd_server_stub) GetStubFn(method string) func(ctx context.Context, args
 }
 
 func (s odd_server_stub) do(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/collatz/Odd", "Do", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Do(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/collatz/Odd") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/collatz/Odd", "Do", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Do(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Do(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -8,9 +8,11 @@ import (
 	"errors"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"reflect"
+	"strconv"
 )
 
 func init() {
@@ -81,7 +83,7 @@ func (s factorer_local_stub) Factors(ctx context.Context, a0 int) (r0 []int, err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.Factorer.Factors", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/factors/Factorer", "Factors", "main.Factorer.Factors", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -91,6 +93,13 @@ func (s factorer_local_stub) Factors(ctx context.Context, a0 int) (r0 []int, err
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/factors/Factorer") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/factors/Factorer", "Factors", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Factors(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Factors(ctx, a0)
 }
 
@@ -121,7 +130,7 @@ func (s factorer_client_stub) Factors(ctx context.Context, a0 int) (r0 []int, er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.Factorer.Factors", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/factors/Factorer", "Factors", "main.Factorer.Factors", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -129,6 +138,7 @@ func (s factorer_client_stub) Factors(ctx context.Context, a0 int) (r0 []int, er
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.factorsMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -139,6 +149,8 @@ func (s factorer_client_stub) Factors(ctx context.Context, a0 int) (r0 []int, er
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -153,22 +165,32 @@ func (s factorer_client_stub) Factors(ctx context.Context, a0 int) (r0 []int, er
 	// Set the shardKey.
 	var r router
 	shardKey := _hashFactorer(r.Factors(ctx, a0))
+	if span.SpanContext().IsValid() && shardKey != 0 {
+		// Record the shard key to help debug hot shards.
+		span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "main.Factorer.Factors"))
+	}
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_int_7c8c8866(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_int_7c8c8866(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type main_client_stub struct {
@@ -181,10 +203,10 @@ var _ weaver.Main = (*main_client_stub)(nil)
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -222,24 +244,43 @@ func (s factorer_server_stub) GetStubFn(method string) func(ctx context.Context,
 }
 
 func (s factorer_server_stub) factors(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/factors/Factorer", "Factors", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 	var r router
 	s.addLoad(_hashFactorer(r.Factors(ctx, a0)), 1.0)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Factors(ctx, a0)
+	var r0 []int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/factors/Factorer") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/factors/Factorer", "Factors", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Factors(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Factors(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -319,7 +360,7 @@ func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
 	if n == -1 {
 		return nil
 	}
-	res := make([]int, n)
+	res := codegen.MakeSlice[int](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.Int()
 	}
@@ -58,7 +58,7 @@ func (s clock_local_stub) UnixMicro(ctx context.Context) (r0 int64, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "fakes.Clock.UnixMicro", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/examples/fakes/Clock", "UnixMicro", "fakes.Clock.UnixMicro", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -68,6 +68,13 @@ func (s clock_local_stub) UnixMicro(ctx context.Context) (r0 int64, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/fakes/Clock") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/fakes/Clock", "UnixMicro", func(ctx context.Context) (err error) {
+			r0, err = s.impl.UnixMicro(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.UnixMicro(ctx)
 }
 
@@ -90,7 +97,7 @@ func (s clock_client_stub) UnixMicro(ctx context.Context) (r0 int64, err error)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "fakes.Clock.UnixMicro", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/examples/fakes/Clock", "UnixMicro", "fakes.Clock.UnixMicro", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -98,6 +105,7 @@ func (s clock_client_stub) UnixMicro(ctx context.Context) (r0 int64, err error)
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.unixMicroMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -108,33 +116,40 @@ func (s clock_client_stub) UnixMicro(ctx context.Context) (r0 int64, err error)
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int64()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int64()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -175,14 +190,28 @@ func (s clock_server_stub) unixMicro(ctx context.Context, args []byte) (res []by
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/examples/fakes/Clock", "UnixMicro", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.UnixMicro(ctx)
+	var r0 int64
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/examples/fakes/Clock") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/examples/fakes/Clock", "UnixMicro", func(ctx context.Context) (err error) {
+			r0, err = s.impl.UnixMicro(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.UnixMicro(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -15,6 +15,7 @@
 package weaver
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/ServiceWeaver/weaver/internal/reflection"
 	"github.com/ServiceWeaver/weaver/internal/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
 )
 
 func init() {
@@ -30,10 +32,14 @@ func init() {
 	weaver.SetWeaverInfo = setWeaverInfo
 	weaver.HasRefs = hasRefs
 	weaver.FillRefs = fillRefs
+	weaver.FillReplicaRefs = fillReplicaRefs
 	weaver.HasListeners = hasListeners
 	weaver.FillListeners = fillListeners
+	weaver.GetWorkers = getWorkers
 	weaver.HasConfig = hasConfig
 	weaver.GetConfig = getConfig
+	weaver.PrepareConfig = prepareConfig
+	weaver.SetConfig = setConfig
 }
 
 // See internal/weaver/types.go.
@@ -103,8 +109,16 @@ func fillRefs(impl any, get func(reflect.Type) (any, error)) error {
 		}
 
 		// Set the component.
-		valueField := f.Field(0)
-		component, err := get(valueField.Type())
+		t := f.Field(0).Type() // a Ref[T]'s value field
+		if tag, ok := s.Type().Field(i).Tag.Lookup("weaver"); ok && tag == lazyRefTag {
+			component, err := lazyComponent(s.Type(), t, get)
+			if err != nil {
+				return fmt.Errorf("FillRefs: setting field %v.%s: %w", s.Type(), s.Type().Field(i).Name, err)
+			}
+			x.setRef(component)
+			continue
+		}
+		component, err := get(t)
 		if err != nil {
 			return fmt.Errorf("FillRefs: setting field %v.%s: %w", s.Type(), s.Type().Field(i).Name, err)
 		}
@@ -113,6 +127,55 @@ func fillRefs(impl any, get func(reflect.Type) (any, error)) error {
 	return nil
 }
 
+// lazyComponent returns a handle to the component with interface t, held by
+// the component implementation impl, that is dialed with get on its first
+// method call.
+func lazyComponent(impl, t reflect.Type, get func(reflect.Type) (any, error)) (any, error) {
+	var reg *codegen.Registration
+	caller := impl.String()
+	for _, r := range codegen.Registered() {
+		if r.Iface == t {
+			reg = r
+		}
+		if r.Impl == impl {
+			caller = r.Name
+		}
+	}
+	if reg == nil {
+		return nil, fmt.Errorf("component %v was not registered; maybe you forgot to run weaver generate", t)
+	}
+	return newLazyRef(reg, caller, func() (any, error) { return get(t) }), nil
+}
+
+// See internal/weaver/types.go.
+func fillReplicaRefs(impl any, get func(reflect.Type) func() ([]any, error)) error {
+	p := reflect.ValueOf(impl)
+	if p.Kind() != reflect.Pointer {
+		return fmt.Errorf("FillReplicaRefs: %T not a pointer", impl)
+	}
+	s := p.Elem()
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("FillReplicaRefs: %T not a struct pointer", impl)
+	}
+
+	for i, n := 0, s.NumField(); i < n; i++ {
+		f := s.Field(i)
+		if !f.CanAddr() {
+			continue
+		}
+		p := reflect.NewAt(f.Type(), f.Addr().UnsafePointer()).Interface()
+		x, ok := p.(interface{ setRefs(func() ([]any, error)) })
+		if !ok {
+			continue
+		}
+
+		// A Refs[T]'s get field has type func() ([]T, error).
+		t := f.Field(0).Type().Out(0).Elem()
+		x.setRefs(get(t))
+	}
+	return nil
+}
+
 // See internal/weaver/types.go.
 func hasListeners(impl any) bool {
 	p := reflect.ValueOf(impl)
@@ -175,6 +238,38 @@ func fillListeners(impl any, get func(name string) (net.Listener, string, error)
 	return nil
 }
 
+// See internal/weaver/types.go.
+func getWorkers(impl any) map[string]func(context.Context) error {
+	p := reflect.ValueOf(impl)
+	if p.Kind() != reflect.Pointer {
+		return nil
+	}
+	s := p.Elem()
+	if s.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var workers map[string]func(context.Context) error
+	for i, n := 0, s.NumField(); i < n; i++ {
+		f := s.Field(i)
+		if f.Type() != reflection.Type[Worker]() {
+			continue
+		}
+
+		// Read the worker. We have to use UnsafePointer because the field may
+		// not be exported.
+		w := *(*Worker)(f.Addr().UnsafePointer())
+		if w == nil {
+			continue
+		}
+		if workers == nil {
+			workers = map[string]func(context.Context) error{}
+		}
+		workers[s.Type().Field(i).Name] = w
+	}
+	return workers
+}
+
 // See internal/weaver/types.go.
 func hasConfig(impl any) bool {
 	_, ok := impl.(interface{ getConfig() any })
@@ -188,3 +283,17 @@ func getConfig(impl any) any {
 	}
 	return nil
 }
+
+// See internal/weaver/types.go.
+func prepareConfig(impl any) {
+	if c, ok := impl.(interface{ prepareConfig() }); ok {
+		c.prepareConfig()
+	}
+}
+
+// See internal/weaver/types.go.
+func setConfig(impl any, cfg any) {
+	if c, ok := impl.(interface{ setConfig(any) }); ok {
+		c.setConfig(cfg)
+	}
+}
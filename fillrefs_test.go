@@ -15,10 +15,15 @@
 package weaver
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
 )
 
 type impl struct {
@@ -75,3 +80,199 @@ func TestFillRefsErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestFillLazyRefs(t *testing.T) {
+	var x struct {
+		c Ref[weaveletControl] `weaver:"lazy"`
+	}
+	dials := 0
+	errDial := errors.New("dial failed")
+	if err := fillRefs(&x, func(reflect.Type) (any, error) {
+		dials++
+		if dials == 1 {
+			return nil, errDial
+		}
+		return &noopWeaveletControl{}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if dials != 0 {
+		t.Fatalf("dials before the first call: got %d, want 0", dials)
+	}
+
+	// The first call fails to dial, and the next one dials again.
+	ctx := context.Background()
+	if _, err := x.c.Get().GetHealth(ctx, nil); !errors.Is(err, errDial) {
+		t.Fatalf("GetHealth: got %v, want %v", err, errDial)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := x.c.Get().GetHealth(ctx, nil)
+		if err == nil || !strings.Contains(err.Error(), "not implemented") {
+			t.Fatalf("GetHealth: got %v, want not implemented", err)
+		}
+	}
+	if dials != 2 {
+		t.Fatalf("dials: got %d, want 2", dials)
+	}
+}
+
+// adder is a component with a variadic method.
+type adder interface {
+	Add(context.Context, ...int) (int, error)
+}
+
+type adderImpl struct{}
+
+func (adderImpl) Add(_ context.Context, xs ...int) (int, error) {
+	sum := 0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum, nil
+}
+
+// adderReflectStub is the reflect stub of adder, as generated by "weaver
+// generate".
+type adderReflectStub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+func (s adderReflectStub) Add(ctx context.Context, a0 ...int) (r0 int, err error) {
+	err = s.caller("Add", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func TestLazyRefVariadic(t *testing.T) {
+	reg := &codegen.Registration{
+		Name:  "TestLazyRefVariadic/adder",
+		Iface: reflection.Type[adder](),
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return adderReflectStub{caller: caller}
+		},
+	}
+	a := newLazyRef(reg, "caller", func() (any, error) { return adderImpl{}, nil }).(adder)
+	ctx := context.Background()
+	for _, test := range []struct {
+		xs   []int
+		want int
+	}{
+		{nil, 0},
+		{[]int{1}, 1},
+		{[]int{1, 2, 3}, 6},
+	} {
+		got, err := a.Add(ctx, test.xs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("Add(%v): got %d, want %d", test.xs, got, test.want)
+		}
+	}
+}
+
+func TestFillReplicaRefs(t *testing.T) {
+	var x struct {
+		a Refs[int]
+		b Ref[string]
+	}
+	if _, err := x.a.Get(); err == nil {
+		t.Error("unexpected success getting an unfilled Refs")
+	}
+	if err := fillReplicaRefs(&x, func(t reflect.Type) func() ([]any, error) {
+		return func() ([]any, error) {
+			if t != reflect.TypeOf(int(0)) {
+				return nil, fmt.Errorf("unsupported type %v", t)
+			}
+			return []any{1, 2, 3}, nil
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	replicas, err := x.a.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(replicas, want) {
+		t.Errorf("x.a.Get(): got %v, want %v", replicas, want)
+	}
+}
+
+func TestCallAll(t *testing.T) {
+	var refs Refs[int]
+	refs.setRefs(func() ([]any, error) { return []any{1, 2, 3}, nil })
+
+	errOdd := errors.New("odd")
+	results, err := CallAll(context.Background(), refs, func(_ context.Context, x int) (string, error) {
+		if x%2 == 1 {
+			return "", fmt.Errorf("%d: %w", x, errOdd)
+		}
+		return fmt.Sprint(x * 10), nil
+	})
+	if !errors.Is(err, errOdd) {
+		t.Errorf("CallAll: got error %v, want %v", err, errOdd)
+	}
+	if want := []string{"", "20", ""}; !reflect.DeepEqual(results, want) {
+		t.Errorf("CallAll: got %v, want %v", results, want)
+	}
+}
+
+func TestCallQuorum(t *testing.T) {
+	var refs Refs[int]
+	refs.setRefs(func() ([]any, error) { return []any{1, 2, 3}, nil })
+
+	// Replica 3 blocks until its call is canceled, so the calls can only
+	// return once replicas 1 and 2 reach a quorum of 2.
+	canceled := make(chan struct{})
+	results, err := CallQuorum(context.Background(), refs, 2, func(ctx context.Context, x int) (string, error) {
+		if x == 3 {
+			<-ctx.Done()
+			close(canceled)
+			return "", ctx.Err()
+		}
+		return fmt.Sprint(x * 10), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10", "20", ""}; !reflect.DeepEqual(results, want) {
+		t.Errorf("CallQuorum: got %v, want %v", results, want)
+	}
+	<-canceled
+}
+
+func TestCallQuorumFails(t *testing.T) {
+	var refs Refs[int]
+	refs.setRefs(func() ([]any, error) { return []any{1, 2, 3}, nil })
+
+	// Replicas 1 and 3 fail, so a quorum of 2 can't be reached.
+	errOdd := errors.New("odd")
+	_, err := CallQuorum(context.Background(), refs, 2, func(_ context.Context, x int) (string, error) {
+		if x%2 == 1 {
+			return "", fmt.Errorf("%d: %w", x, errOdd)
+		}
+		return fmt.Sprint(x * 10), nil
+	})
+	if !errors.Is(err, errOdd) {
+		t.Errorf("CallQuorum: got error %v, want %v", err, errOdd)
+	}
+	var qerr *QuorumError
+	if !errors.As(err, &qerr) {
+		t.Fatalf("CallQuorum: got error %v, want *QuorumError", err)
+	}
+	if qerr.Quorum != 2 || qerr.Succeeded > 1 {
+		t.Errorf("CallQuorum: got %d of quorum %d, want at most 1 of quorum 2", qerr.Succeeded, qerr.Quorum)
+	}
+	for i, err := range qerr.Errs {
+		if got, want := err != nil, i == 0 || i == 2; got != want {
+			t.Errorf("CallQuorum: replica %d: got error %v, want failure %t", i, err, want)
+		}
+	}
+
+	// A quorum larger than the number of replicas can't be reached.
+	if _, err := CallQuorum(context.Background(), refs, 4, func(context.Context, int) (string, error) {
+		t.Error("unexpected call")
+		return "", nil
+	}); !errors.As(err, &qerr) {
+		t.Errorf("CallQuorum: got error %v, want *QuorumError", err)
+	}
+}
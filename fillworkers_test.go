@@ -0,0 +1,37 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetWorkers(t *testing.T) {
+	work := func(context.Context) error { return nil }
+	var x struct {
+		A Worker
+		b Worker
+		c Worker // left nil
+		d func(context.Context) error
+	}
+	x.A = work
+	x.b = work
+	x.d = work
+	workers := getWorkers(&x)
+	if len(workers) != 2 || workers["A"] == nil || workers["b"] == nil {
+		t.Fatalf(`expecting workers "A" and "b", got %v`, workers)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import "github.com/ServiceWeaver/weaver/runtime/codegen"
+
+// LatLng is a point on the surface of the Earth, in degrees. LatLng is
+// serializable and is encoded compactly as two fixed-precision integers with a
+// resolution of 1e-7 degrees (about 1.1cm at the equator). A LatLng received
+// from another component may therefore differ from the one sent by up to 5e-8
+// degrees in each coordinate. Encoding a LatLng with a latitude outside
+// [-90, 90] or a longitude outside [-180, 180] fails.
+type LatLng struct {
+	Lat float64 // latitude, in [-90, 90]
+	Lng float64 // longitude, in [-180, 180]
+}
+
+// WeaverMarshal implements the codegen.AutoMarshal interface.
+func (p LatLng) WeaverMarshal(enc *codegen.Encoder) {
+	enc.Latitude(p.Lat)
+	enc.Longitude(p.Lng)
+}
+
+// WeaverUnmarshal implements the codegen.AutoMarshal interface.
+func (p *LatLng) WeaverUnmarshal(dec *codegen.Decoder) {
+	p.Lat = dec.Latitude()
+	p.Lng = dec.Longitude()
+}
+
+// BBox is a bounding box on the surface of the Earth, delimited by its
+// south-west and north-east corners. A box whose south-west longitude is
+// greater than its north-east longitude crosses the antimeridian (±180°
+// longitude). For example, the following box contains Fiji:
+//
+//	weaver.BBox{
+//	    SW: weaver.LatLng{Lat: -21, Lng: 176},
+//	    NE: weaver.LatLng{Lat: -12, Lng: -178},
+//	}
+//
+// BBox is serializable and is encoded like two LatLngs.
+type BBox struct {
+	SW LatLng // south-west corner
+	NE LatLng // north-east corner
+}
+
+// CrossesAntimeridian returns whether the box crosses the antimeridian.
+func (b BBox) CrossesAntimeridian() bool {
+	return b.SW.Lng > b.NE.Lng
+}
+
+// Contains returns whether the box contains the provided point. Points on the
+// edges of the box are contained in the box.
+func (b BBox) Contains(p LatLng) bool {
+	if p.Lat < b.SW.Lat || p.Lat > b.NE.Lat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return p.Lng >= b.SW.Lng || p.Lng <= b.NE.Lng
+	}
+	return p.Lng >= b.SW.Lng && p.Lng <= b.NE.Lng
+}
+
+// WeaverMarshal implements the codegen.AutoMarshal interface.
+func (b BBox) WeaverMarshal(enc *codegen.Encoder) {
+	b.SW.WeaverMarshal(enc)
+	b.NE.WeaverMarshal(enc)
+}
+
+// WeaverUnmarshal implements the codegen.AutoMarshal interface.
+func (b *BBox) WeaverUnmarshal(dec *codegen.Decoder) {
+	b.SW.WeaverUnmarshal(dec)
+	b.NE.WeaverUnmarshal(dec)
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+// TestBBoxContains tests BBox.Contains on boxes that do and don't cross the
+// antimeridian.
+func TestBBoxContains(t *testing.T) {
+	// A box around the San Francisco Bay Area.
+	bay := BBox{SW: LatLng{Lat: 37.2, Lng: -122.6}, NE: LatLng{Lat: 38.0, Lng: -121.7}}
+
+	// A box around Fiji, which crosses the antimeridian.
+	fiji := BBox{SW: LatLng{Lat: -21, Lng: 176}, NE: LatLng{Lat: -12, Lng: -178}}
+
+	for _, test := range []struct {
+		box  BBox
+		p    LatLng
+		want bool
+	}{
+		{bay, LatLng{Lat: 37.7749295, Lng: -122.4194155}, true},
+		{bay, LatLng{Lat: 37.2, Lng: -122.6}, true},
+		{bay, LatLng{Lat: 38.0, Lng: -121.7}, true},
+		{bay, LatLng{Lat: 36.9, Lng: -122.0}, false},
+		{bay, LatLng{Lat: 37.5, Lng: -120.0}, false},
+		{fiji, LatLng{Lat: -17.7, Lng: 178.0}, true},
+		{fiji, LatLng{Lat: -17.7, Lng: 180}, true},
+		{fiji, LatLng{Lat: -17.7, Lng: -180}, true},
+		{fiji, LatLng{Lat: -16.5, Lng: -179.9}, true},
+		{fiji, LatLng{Lat: -17.7, Lng: 0}, false},
+		{fiji, LatLng{Lat: -17.7, Lng: 175}, false},
+		{fiji, LatLng{Lat: -17.7, Lng: -177}, false},
+		{fiji, LatLng{Lat: -22, Lng: 179}, false},
+	} {
+		t.Run(fmt.Sprintf("%v/%v", test.box, test.p), func(t *testing.T) {
+			if got := test.box.Contains(test.p); got != test.want {
+				t.Fatalf("Contains: got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+// TestBBoxEncodeDecode tests that bounding boxes whose coordinates fit in the
+// encoding's precision round trip exactly.
+func TestBBoxEncodeDecode(t *testing.T) {
+	for _, want := range []BBox{
+		{},
+		{SW: LatLng{Lat: 37.2, Lng: -122.6}, NE: LatLng{Lat: 38.0, Lng: -121.7}},
+		{SW: LatLng{Lat: -21, Lng: 176}, NE: LatLng{Lat: -12, Lng: -178}},
+		{SW: LatLng{Lat: -90, Lng: -180}, NE: LatLng{Lat: 90, Lng: 180}},
+	} {
+		t.Run(fmt.Sprint(want), func(t *testing.T) {
+			enc := codegen.NewEncoder()
+			want.WeaverMarshal(enc)
+			if got, want := len(enc.Data()), 16; got != want {
+				t.Fatalf("encoded size: got %d, want %d", got, want)
+			}
+			var got BBox
+			got.WeaverUnmarshal(codegen.NewDecoder(enc.Data()))
+			if got != want {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+			if got.CrossesAntimeridian() != want.CrossesAntimeridian() {
+				t.Fatalf("CrossesAntimeridian: got %t, want %t", got.CrossesAntimeridian(), want.CrossesAntimeridian())
+			}
+		})
+	}
+}
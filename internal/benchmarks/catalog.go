@@ -0,0 +1,84 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Catalog is a component with small, representative methods that are used to
+// benchmark the overhead of the stub layer. See loopback_test.go.
+type Catalog interface {
+	//weaver:cacheable
+	GetProduct(ctx context.Context, id string, opts productOptions) (product, error)
+
+	//weaver:cacheable
+	Convert(ctx context.Context, cents int64, from, to string) (int64, error)
+}
+
+type catalog struct {
+	weaver.Implements[Catalog]
+}
+
+// productOptions controls which parts of a product are returned by
+// GetProduct.
+type productOptions struct {
+	weaver.AutoMarshal
+	Currency string
+	WithTags bool
+}
+
+// product is a product in the catalog.
+type product struct {
+	weaver.AutoMarshal
+	ID          string
+	Name        string
+	Description string
+	PriceCents  int64
+	Currency    string
+	Tags        []string
+}
+
+// rates maps currencies to their value in USD cents.
+var rates = map[string]int64{"USD": 100, "EUR": 108, "GBP": 126, "JPY": 1}
+
+func (c *catalog) GetProduct(_ context.Context, id string, opts productOptions) (product, error) {
+	p := product{
+		ID:          id,
+		Name:        "Sunglasses",
+		Description: "Add a modern touch to your outfits with these sleek aviator sunglasses.",
+		PriceCents:  1999,
+		Currency:    "USD",
+	}
+	if opts.WithTags {
+		p.Tags = []string{"accessories", "summer"}
+	}
+	return p, nil
+}
+
+func (c *catalog) Convert(_ context.Context, cents int64, from, to string) (int64, error) {
+	f, ok := rates[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %q", from)
+	}
+	t, ok := rates[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %q", to)
+	}
+	return cents * f / t, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// loopbackStub is a codegen.Stub that passes the serialized arguments of a
+// method call directly to a generated server stub in the same process. Unlike
+// the codec benchmarks, calls through a loopbackStub exercise the full
+// generated stub path (encoding, decoding, spans, and metrics) on both the
+// client and the server, but without the cost of a network transport.
+type loopbackStub struct {
+	tracer  trace.Tracer
+	server  codegen.Server
+	methods []string // method names, indexed by method number
+}
+
+var _ codegen.Stub = &loopbackStub{}
+
+// Tracer implements the codegen.Stub interface.
+func (l *loopbackStub) Tracer() trace.Tracer {
+	return l.tracer
+}
+
+// Run implements the codegen.Stub interface.
+func (l *loopbackStub) Run(ctx context.Context, method int, args []byte, _ uint64) ([]byte, error) {
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		// Create a server span, as the RPC server would.
+		var serverSpan trace.Span
+		ctx, serverSpan = l.tracer.Start(ctx, l.methods[method], trace.WithSpanKind(trace.SpanKindServer))
+		defer serverSpan.End()
+	}
+	return l.server.GetStubFn(l.methods[method])(ctx, args)
+}
+
+// loopback returns a client stub for component T that calls the provided
+// implementation of T through a loopbackStub.
+func loopback[T any](tb testing.TB, impl any, tracer trace.Tracer) T {
+	tb.Helper()
+	name := reflection.ComponentName[T]()
+	reg, ok := codegen.Find(name)
+	if !ok {
+		tb.Fatalf("component %s not found", name)
+	}
+	methods := make([]string, reg.Iface.NumMethod())
+	for i := range methods {
+		methods[i] = reg.Iface.Method(i).Name
+	}
+	stub := &loopbackStub{
+		tracer:  tracer,
+		server:  reg.ServerStubFn(impl, func(uint64, float64) {}),
+		methods: methods,
+	}
+	return reg.ClientStubFn(stub, "loopback").(T)
+}
+
+// benchmarkLoopback benchmarks f, which calls a method on a Catalog, through a
+// loopback stub, both with and without tracing enabled.
+func benchmarkLoopback(b *testing.B, f func(context.Context, Catalog) error) {
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("loopback")
+
+	for _, traced := range []bool{false, true} {
+		b.Run(fmt.Sprintf("traced=%t", traced), func(b *testing.B) {
+			client := loopback[Catalog](b, &catalog{}, tracer)
+			ctx := context.Background()
+			if traced {
+				var span trace.Span
+				ctx, span = tracer.Start(ctx, "benchmark")
+				defer span.End()
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := f(ctx, client); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLoopbackGetProduct measures the end-to-end cost of calling
+// GetProduct through the generated client and server stubs.
+func BenchmarkLoopbackGetProduct(b *testing.B) {
+	opts := productOptions{Currency: "EUR", WithTags: true}
+	benchmarkLoopback(b, func(ctx context.Context, c Catalog) error {
+		_, err := c.GetProduct(ctx, "OLJCESPC7Z", opts)
+		return err
+	})
+}
+
+// BenchmarkLoopbackConvert measures the end-to-end cost of calling Convert
+// through the generated client and server stubs.
+func BenchmarkLoopbackConvert(b *testing.B) {
+	benchmarkLoopback(b, func(ctx context.Context, c Catalog) error {
+		_, err := c.Convert(ctx, 1999, "USD", "EUR")
+		return err
+	})
+}
+
+// TestLoopback checks that calls through a loopback stub return the same
+// results as calls on the implementation.
+func TestLoopback(t *testing.T) {
+	ctx := context.Background()
+	impl := &catalog{}
+	client := loopback[Catalog](t, impl, trace.NewNoopTracerProvider().Tracer(""))
+
+	opts := productOptions{WithTags: true}
+	got, err := client.GetProduct(ctx, "OLJCESPC7Z", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := impl.GetProduct(ctx, "OLJCESPC7Z", opts)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(product{})); diff != "" {
+		t.Fatalf("GetProduct (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.Convert(ctx, 1999, "USD", "XYZ"); err == nil {
+		t.Fatal("Convert: unexpected success for unknown currency")
+	}
+}
@@ -15,6 +15,25 @@ import (
 )
 
 func init() {
+	codegen.Register(codegen.Registration{
+		Name:      "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog",
+		Iface:     reflect.TypeOf((*Catalog)(nil)).Elem(),
+		Impl:      reflect.TypeOf(catalog{}),
+		Cacheable: []int{0, 1},
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return catalog_local_stub{impl: impl.(Catalog), tracer: tracer, convertMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", Method: "Convert", Remote: false, Generated: true}), getProductMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", Method: "GetProduct", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return catalog_client_stub{stub: stub, convertMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", Method: "Convert", Remote: true, Generated: true}), getProductMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", Method: "GetProduct", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return catalog_server_stub{impl: impl.(Catalog), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return catalog_reflect_stub{caller: caller}
+		},
+		RefData: "",
+	})
 	codegen.Register(codegen.Registration{
 		Name:  "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1",
 		Iface: reflect.TypeOf((*Ping1)(nil)).Elem(),
@@ -198,6 +217,7 @@ func init() {
 }
 
 // weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Catalog] = (*catalog)(nil)
 var _ weaver.InstanceOf[Ping1] = (*ping1)(nil)
 var _ weaver.InstanceOf[Ping10] = (*ping10)(nil)
 var _ weaver.InstanceOf[Ping2] = (*ping2)(nil)
@@ -210,6 +230,7 @@ var _ weaver.InstanceOf[Ping8] = (*ping8)(nil)
 var _ weaver.InstanceOf[Ping9] = (*ping9)(nil)
 
 // weaver.Router checks.
+var _ weaver.Unrouted = (*catalog)(nil)
 var _ weaver.Unrouted = (*ping1)(nil)
 var _ weaver.Unrouted = (*ping10)(nil)
 var _ weaver.Unrouted = (*ping2)(nil)
@@ -223,6 +244,70 @@ var _ weaver.Unrouted = (*ping9)(nil)
 
 // Local stub implementations.
 
+type catalog_local_stub struct {
+	impl              Catalog
+	tracer            trace.Tracer
+	convertMetrics    *codegen.MethodMetrics
+	getProductMetrics *codegen.MethodMetrics
+}
+
+// Check that catalog_local_stub implements the Catalog interface.
+var _ Catalog = (*catalog_local_stub)(nil)
+
+func (s catalog_local_stub) Convert(ctx context.Context, a0 int64, a1 string, a2 string) (r0 int64, err error) {
+	// Update metrics.
+	begin := s.convertMetrics.Begin()
+	defer func() { s.convertMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "Convert", "benchmarks.Catalog.Convert", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "Convert", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Convert(ctx, a0, a1, a2)
+			return err
+		})
+		return
+	}
+	return s.impl.Convert(ctx, a0, a1, a2)
+}
+
+func (s catalog_local_stub) GetProduct(ctx context.Context, a0 string, a1 productOptions) (r0 product, err error) {
+	// Update metrics.
+	begin := s.getProductMetrics.Begin()
+	defer func() { s.getProductMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "GetProduct", "benchmarks.Catalog.GetProduct", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "GetProduct", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetProduct(ctx, a0, a1)
+			return err
+		})
+		return
+	}
+	return s.impl.GetProduct(ctx, a0, a1)
+}
+
 type ping1_local_stub struct {
 	impl         Ping1
 	tracer       trace.Tracer
@@ -240,7 +325,7 @@ func (s ping1_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping1.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingC", "benchmarks.Ping1.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -250,6 +335,13 @@ func (s ping1_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -260,7 +352,7 @@ func (s ping1_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping1.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingS", "benchmarks.Ping1.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -270,6 +362,13 @@ func (s ping1_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -290,7 +389,7 @@ func (s ping10_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping10.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingC", "benchmarks.Ping10.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -300,6 +399,13 @@ func (s ping10_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -310,7 +416,7 @@ func (s ping10_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping10.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingS", "benchmarks.Ping10.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -320,6 +426,13 @@ func (s ping10_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -340,7 +453,7 @@ func (s ping2_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping2.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingC", "benchmarks.Ping2.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -350,6 +463,13 @@ func (s ping2_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -360,7 +480,7 @@ func (s ping2_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping2.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingS", "benchmarks.Ping2.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -370,6 +490,13 @@ func (s ping2_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -390,7 +517,7 @@ func (s ping3_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping3.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingC", "benchmarks.Ping3.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -400,6 +527,13 @@ func (s ping3_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -410,7 +544,7 @@ func (s ping3_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping3.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingS", "benchmarks.Ping3.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -420,6 +554,13 @@ func (s ping3_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -440,7 +581,7 @@ func (s ping4_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping4.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingC", "benchmarks.Ping4.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -450,6 +591,13 @@ func (s ping4_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -460,7 +608,7 @@ func (s ping4_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping4.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingS", "benchmarks.Ping4.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -470,6 +618,13 @@ func (s ping4_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -490,7 +645,7 @@ func (s ping5_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping5.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingC", "benchmarks.Ping5.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -500,6 +655,13 @@ func (s ping5_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -510,7 +672,7 @@ func (s ping5_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping5.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingS", "benchmarks.Ping5.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -520,6 +682,13 @@ func (s ping5_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -540,7 +709,7 @@ func (s ping6_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping6.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingC", "benchmarks.Ping6.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -550,6 +719,13 @@ func (s ping6_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -560,7 +736,7 @@ func (s ping6_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping6.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingS", "benchmarks.Ping6.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -570,6 +746,13 @@ func (s ping6_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -590,7 +773,7 @@ func (s ping7_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping7.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingC", "benchmarks.Ping7.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -600,6 +783,13 @@ func (s ping7_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -610,7 +800,7 @@ func (s ping7_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping7.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingS", "benchmarks.Ping7.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -620,6 +810,13 @@ func (s ping7_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -640,7 +837,7 @@ func (s ping8_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping8.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingC", "benchmarks.Ping8.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -650,6 +847,13 @@ func (s ping8_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -660,7 +864,7 @@ func (s ping8_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping8.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingS", "benchmarks.Ping8.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -670,6 +874,13 @@ func (s ping8_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
@@ -690,7 +901,7 @@ func (s ping9_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping9.PingC", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingC", "benchmarks.Ping9.PingC", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -700,6 +911,13 @@ func (s ping9_local_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingC(ctx, a0, a1)
 }
 
@@ -710,7 +928,7 @@ func (s ping9_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "benchmarks.Ping9.PingS", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingS", "benchmarks.Ping9.PingS", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -720,30 +938,37 @@ func (s ping9_local_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 pa
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.PingS(ctx, a0, a1)
 }
 
 // Client stub implementations.
 
-type ping1_client_stub struct {
-	stub         codegen.Stub
-	pingCMetrics *codegen.MethodMetrics
-	pingSMetrics *codegen.MethodMetrics
+type catalog_client_stub struct {
+	stub              codegen.Stub
+	convertMetrics    *codegen.MethodMetrics
+	getProductMetrics *codegen.MethodMetrics
 }
 
-// Check that ping1_client_stub implements the Ping1 interface.
-var _ Ping1 = (*ping1_client_stub)(nil)
+// Check that catalog_client_stub implements the Catalog interface.
+var _ Catalog = (*catalog_client_stub)(nil)
 
-func (s ping1_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s catalog_client_stub) Convert(ctx context.Context, a0 int64, a1 string, a2 string) (r0 int64, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.pingCMetrics.Begin()
-	defer func() { s.pingCMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.convertMetrics.Begin()
+	defer func() { s.convertMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping1.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "Convert", "benchmarks.Catalog.Convert", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -751,6 +976,7 @@ func (s ping1_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.convertMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -761,47 +987,61 @@ func (s ping1_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
 	size := 0
-	size += serviceweaver_size_payloadC_7e82696e(&a0)
 	size += 8
+	size += (4 + len(a1))
+	size += (4 + len(a2))
 	enc := codegen.NewEncoder()
 	enc.Reset(size)
 
 	// Encode arguments.
-	(a0).WeaverMarshal(enc)
-	enc.Int(a1)
+	enc.Int64(a0)
+	enc.String(a1)
+	enc.String(a2)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int64()
+		err = dec.Error()
+		if err == nil {
+			// Cache the reply, if enabled, now that we know the call succeeded.
+			codegen.CacheReply(s.stub, 0, enc.Data(), results)
+		}
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s ping1_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+func (s catalog_client_stub) GetProduct(ctx context.Context, a0 string, a1 productOptions) (r0 product, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.pingSMetrics.Begin()
-	defer func() { s.pingSMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.getProductMetrics.Begin()
+	defer func() { s.getProductMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping1.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "GetProduct", "benchmarks.Catalog.GetProduct", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -809,6 +1049,7 @@ func (s ping1_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getProductMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -819,41 +1060,59 @@ func (s ping1_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
-	// Encode arguments.
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += serviceweaver_size_productOptions_906f98fd(&a1)
 	enc := codegen.NewEncoder()
-	(a0).WeaverMarshal(enc)
-	enc.Int(a1)
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	(a1).WeaverMarshal(enc)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if err == nil {
+			// Cache the reply, if enabled, now that we know the call succeeded.
+			codegen.CacheReply(s.stub, 1, enc.Data(), results)
+		}
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-type ping10_client_stub struct {
+type ping1_client_stub struct {
 	stub         codegen.Stub
 	pingCMetrics *codegen.MethodMetrics
 	pingSMetrics *codegen.MethodMetrics
 }
 
-// Check that ping10_client_stub implements the Ping10 interface.
-var _ Ping10 = (*ping10_client_stub)(nil)
+// Check that ping1_client_stub implements the Ping1 interface.
+var _ Ping1 = (*ping1_client_stub)(nil)
 
-func (s ping10_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s ping1_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingCMetrics.Begin()
@@ -862,7 +1121,7 @@ func (s ping10_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping10.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingC", "benchmarks.Ping1.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -870,6 +1129,7 @@ func (s ping10_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -880,6 +1140,8 @@ func (s ping10_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -894,24 +1156,30 @@ func (s ping10_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s ping10_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+func (s ping1_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingSMetrics.Begin()
@@ -920,7 +1188,7 @@ func (s ping10_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping10.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingS", "benchmarks.Ping1.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -928,6 +1196,7 @@ func (s ping10_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -938,6 +1207,8 @@ func (s ping10_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -946,33 +1217,39 @@ func (s ping10_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-type ping2_client_stub struct {
+type ping10_client_stub struct {
 	stub         codegen.Stub
 	pingCMetrics *codegen.MethodMetrics
 	pingSMetrics *codegen.MethodMetrics
 }
 
-// Check that ping2_client_stub implements the Ping2 interface.
-var _ Ping2 = (*ping2_client_stub)(nil)
+// Check that ping10_client_stub implements the Ping10 interface.
+var _ Ping10 = (*ping10_client_stub)(nil)
 
-func (s ping2_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s ping10_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingCMetrics.Begin()
@@ -981,7 +1258,7 @@ func (s ping2_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping2.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingC", "benchmarks.Ping10.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -989,6 +1266,7 @@ func (s ping2_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -999,6 +1277,8 @@ func (s ping2_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1013,24 +1293,30 @@ func (s ping2_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s ping2_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+func (s ping10_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingSMetrics.Begin()
@@ -1039,7 +1325,7 @@ func (s ping2_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping2.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingS", "benchmarks.Ping10.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1047,6 +1333,7 @@ func (s ping2_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1057,6 +1344,8 @@ func (s ping2_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1065,33 +1354,39 @@ func (s ping2_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-type ping3_client_stub struct {
+type ping2_client_stub struct {
 	stub         codegen.Stub
 	pingCMetrics *codegen.MethodMetrics
 	pingSMetrics *codegen.MethodMetrics
 }
 
-// Check that ping3_client_stub implements the Ping3 interface.
-var _ Ping3 = (*ping3_client_stub)(nil)
+// Check that ping2_client_stub implements the Ping2 interface.
+var _ Ping2 = (*ping2_client_stub)(nil)
 
-func (s ping3_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s ping2_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingCMetrics.Begin()
@@ -1100,7 +1395,7 @@ func (s ping3_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping3.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingC", "benchmarks.Ping2.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1108,6 +1403,7 @@ func (s ping3_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1118,6 +1414,8 @@ func (s ping3_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1132,24 +1430,30 @@ func (s ping3_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s ping3_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+func (s ping2_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingSMetrics.Begin()
@@ -1158,7 +1462,7 @@ func (s ping3_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping3.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingS", "benchmarks.Ping2.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1166,6 +1470,7 @@ func (s ping3_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1176,6 +1481,8 @@ func (s ping3_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1184,33 +1491,39 @@ func (s ping3_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-type ping4_client_stub struct {
+type ping3_client_stub struct {
 	stub         codegen.Stub
 	pingCMetrics *codegen.MethodMetrics
 	pingSMetrics *codegen.MethodMetrics
 }
 
-// Check that ping4_client_stub implements the Ping4 interface.
-var _ Ping4 = (*ping4_client_stub)(nil)
+// Check that ping3_client_stub implements the Ping3 interface.
+var _ Ping3 = (*ping3_client_stub)(nil)
 
-func (s ping4_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s ping3_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingCMetrics.Begin()
@@ -1219,7 +1532,7 @@ func (s ping4_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping4.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingC", "benchmarks.Ping3.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1227,6 +1540,7 @@ func (s ping4_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1237,6 +1551,8 @@ func (s ping4_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1251,24 +1567,30 @@ func (s ping4_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s ping4_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+func (s ping3_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingSMetrics.Begin()
@@ -1277,7 +1599,7 @@ func (s ping4_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping4.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingS", "benchmarks.Ping3.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1285,6 +1607,7 @@ func (s ping4_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1295,6 +1618,8 @@ func (s ping4_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1303,33 +1628,39 @@ func (s ping4_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-type ping5_client_stub struct {
+type ping4_client_stub struct {
 	stub         codegen.Stub
 	pingCMetrics *codegen.MethodMetrics
 	pingSMetrics *codegen.MethodMetrics
 }
 
-// Check that ping5_client_stub implements the Ping5 interface.
-var _ Ping5 = (*ping5_client_stub)(nil)
+// Check that ping4_client_stub implements the Ping4 interface.
+var _ Ping4 = (*ping4_client_stub)(nil)
 
-func (s ping5_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s ping4_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingCMetrics.Begin()
@@ -1338,7 +1669,7 @@ func (s ping5_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping5.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingC", "benchmarks.Ping4.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1346,6 +1677,7 @@ func (s ping5_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1356,6 +1688,8 @@ func (s ping5_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1370,24 +1704,30 @@ func (s ping5_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s ping5_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+func (s ping4_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingSMetrics.Begin()
@@ -1396,7 +1736,7 @@ func (s ping5_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping5.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingS", "benchmarks.Ping4.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1404,6 +1744,7 @@ func (s ping5_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1414,6 +1755,8 @@ func (s ping5_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1422,33 +1765,39 @@ func (s ping5_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-type ping6_client_stub struct {
+type ping5_client_stub struct {
 	stub         codegen.Stub
 	pingCMetrics *codegen.MethodMetrics
 	pingSMetrics *codegen.MethodMetrics
 }
 
-// Check that ping6_client_stub implements the Ping6 interface.
-var _ Ping6 = (*ping6_client_stub)(nil)
+// Check that ping5_client_stub implements the Ping5 interface.
+var _ Ping5 = (*ping5_client_stub)(nil)
 
-func (s ping6_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+func (s ping5_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
 	begin := s.pingCMetrics.Begin()
@@ -1457,7 +1806,7 @@ func (s ping6_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping6.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingC", "benchmarks.Ping5.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1465,6 +1814,7 @@ func (s ping6_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1475,6 +1825,8 @@ func (s ping6_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1489,21 +1841,164 @@ func (s ping6_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
 	}
+}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+func (s ping5_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.pingSMetrics.Begin()
+	defer func() { s.pingSMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingS", "benchmarks.Ping5.PingS", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.pingSMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	(a0).WeaverMarshal(enc)
+	enc.Int(a1)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+type ping6_client_stub struct {
+	stub         codegen.Stub
+	pingCMetrics *codegen.MethodMetrics
+	pingSMetrics *codegen.MethodMetrics
+}
+
+// Check that ping6_client_stub implements the Ping6 interface.
+var _ Ping6 = (*ping6_client_stub)(nil)
+
+func (s ping6_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 payloadC, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.pingCMetrics.Begin()
+	defer func() { s.pingCMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingC", "benchmarks.Ping6.PingC", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.pingCMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += serviceweaver_size_payloadC_7e82696e(&a0)
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	(a0).WeaverMarshal(enc)
+	enc.Int(a1)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s ping6_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
@@ -1515,7 +2010,7 @@ func (s ping6_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping6.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingS", "benchmarks.Ping6.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1523,6 +2018,7 @@ func (s ping6_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1533,6 +2029,8 @@ func (s ping6_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1541,21 +2039,27 @@ func (s ping6_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type ping7_client_stub struct {
@@ -1576,7 +2080,7 @@ func (s ping7_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping7.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingC", "benchmarks.Ping7.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1584,6 +2088,7 @@ func (s ping7_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1594,6 +2099,8 @@ func (s ping7_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1608,21 +2115,27 @@ func (s ping7_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s ping7_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
@@ -1634,7 +2147,7 @@ func (s ping7_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping7.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingS", "benchmarks.Ping7.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1642,6 +2155,7 @@ func (s ping7_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1652,6 +2166,8 @@ func (s ping7_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1660,21 +2176,27 @@ func (s ping7_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type ping8_client_stub struct {
@@ -1695,7 +2217,7 @@ func (s ping8_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping8.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingC", "benchmarks.Ping8.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1703,6 +2225,7 @@ func (s ping8_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1713,6 +2236,8 @@ func (s ping8_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1727,21 +2252,27 @@ func (s ping8_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s ping8_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
@@ -1753,7 +2284,7 @@ func (s ping8_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping8.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingS", "benchmarks.Ping8.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1761,6 +2292,7 @@ func (s ping8_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1771,6 +2303,8 @@ func (s ping8_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1779,21 +2313,27 @@ func (s ping8_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type ping9_client_stub struct {
@@ -1814,7 +2354,7 @@ func (s ping9_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping9.PingC", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingC", "benchmarks.Ping9.PingC", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1822,6 +2362,7 @@ func (s ping9_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingCMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1832,6 +2373,8 @@ func (s ping9_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -1846,21 +2389,27 @@ func (s ping9_client_stub) PingC(ctx context.Context, a0 payloadC, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s ping9_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 payloadS, err error) {
@@ -1872,7 +2421,7 @@ func (s ping9_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "benchmarks.Ping9.PingS", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingS", "benchmarks.Ping9.PingS", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1880,6 +2429,7 @@ func (s ping9_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingSMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -1890,6 +2440,8 @@ func (s ping9_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1898,30 +2450,36 @@ func (s ping9_client_stub) PingS(ctx context.Context, a0 payloadS, a1 int) (r0 p
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -1940,6 +2498,120 @@ please file an issue at https://github.com/ServiceWeaver/weaver/issues.
 
 // Server stub implementations.
 
+type catalog_server_stub struct {
+	impl    Catalog
+	addLoad func(key uint64, load float64)
+}
+
+// Check that catalog_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*catalog_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s catalog_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Convert":
+		return s.convert
+	case "GetProduct":
+		return s.getProduct
+	default:
+		return nil
+	}
+}
+
+func (s catalog_server_stub) convert(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int64
+	var a1 string
+	var a2 string
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "Convert", r, a0, a1, a2)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.Int64()
+	a1 = dec.String()
+	a2 = dec.String()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 int64
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "Convert", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Convert(ctx, a0, a1, a2)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Convert(ctx, a0, a1, a2)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Int64(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) getProduct(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 productOptions
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "GetProduct", r, a0, a1)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.String()
+	(&a1).WeaverUnmarshal(dec)
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 product
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Catalog", "GetProduct", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetProduct(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetProduct(ctx, a0, a1)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 type ping1_server_stub struct {
 	impl    Ping1
 	addLoad func(key uint64, load float64)
@@ -1961,24 +2633,43 @@ func (s ping1_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping1_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1988,24 +2679,43 @@ func (s ping1_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping1_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping1", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2035,24 +2745,43 @@ func (s ping10_server_stub) GetStubFn(method string) func(ctx context.Context, a
 }
 
 func (s ping10_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2062,24 +2791,43 @@ func (s ping10_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping10_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping10", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2109,24 +2857,43 @@ func (s ping2_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping2_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2136,24 +2903,43 @@ func (s ping2_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping2_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping2", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2183,24 +2969,43 @@ func (s ping3_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping3_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2210,24 +3015,43 @@ func (s ping3_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping3_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping3", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2257,24 +3081,43 @@ func (s ping4_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping4_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2284,24 +3127,43 @@ func (s ping4_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping4_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping4", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2331,24 +3193,43 @@ func (s ping5_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping5_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2358,24 +3239,43 @@ func (s ping5_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping5_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping5", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2405,24 +3305,43 @@ func (s ping6_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping6_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2432,24 +3351,43 @@ func (s ping6_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping6_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping6", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2479,24 +3417,43 @@ func (s ping7_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping7_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2506,24 +3463,43 @@ func (s ping7_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping7_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping7", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2553,24 +3529,43 @@ func (s ping8_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping8_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2580,24 +3575,43 @@ func (s ping8_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping8_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping8", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2627,24 +3641,43 @@ func (s ping9_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s ping9_server_stub) pingC(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadC
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingC", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadC
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingC(ctx, a0, a1)
+	var r0 payloadC
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingC", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingC(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingC(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2654,24 +3687,43 @@ func (s ping9_server_stub) pingC(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s ping9_server_stub) pingS(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 payloadS
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingS", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 payloadS
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	(&a0).WeaverUnmarshal(dec)
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.PingS(ctx, a0, a1)
+	var r0 payloadS
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/benchmarks/Ping9", "PingS", func(ctx context.Context) (err error) {
+			r0, err = s.impl.PingS(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.PingS(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -2682,6 +3734,23 @@ func (s ping9_server_stub) pingS(ctx context.Context, args []byte) (res []byte,
 
 // Reflect stub implementations.
 
+type catalog_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that catalog_reflect_stub implements the Catalog interface.
+var _ Catalog = (*catalog_reflect_stub)(nil)
+
+func (s catalog_reflect_stub) Convert(ctx context.Context, a0 int64, a1 string, a2 string) (r0 int64, err error) {
+	err = s.caller("Convert", ctx, []any{a0, a1, a2}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) GetProduct(ctx context.Context, a0 string, a1 productOptions) (r0 product, err error) {
+	err = s.caller("GetProduct", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
 type ping1_reflect_stub struct {
 	caller func(string, context.Context, []any, []any) error
 }
@@ -2880,6 +3949,18 @@ func (x *X1) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.B = serviceweaver_dec_slice_int64_a8f7f092(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *X1) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+		codegen.DebugField{Name: "B", Value: x.B},
+	)
+}
+
 func serviceweaver_enc_slice_int64_a8f7f092(enc *codegen.Encoder, arg []int64) {
 	if arg == nil {
 		enc.Len(-1)
@@ -2896,7 +3977,7 @@ func serviceweaver_dec_slice_int64_a8f7f092(dec *codegen.Decoder) []int64 {
 	if n == -1 {
 		return nil
 	}
-	res := make([]int64, n)
+	res := codegen.MakeSlice[int64](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.Int64()
 	}
@@ -2926,6 +4007,17 @@ func (x *X2) WeaverUnmarshal(dec *codegen.Decoder) {
 	(&x.A).WeaverUnmarshal(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *X2) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+	)
+}
+
 var _ codegen.AutoMarshal = (*X3)(nil)
 
 type __is_X3[T ~struct {
@@ -2955,6 +4047,19 @@ func (x *X3) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.C = dec.Int64()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *X3) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+		codegen.DebugField{Name: "B", Value: x.B},
+		codegen.DebugField{Name: "C", Value: x.C},
+	)
+}
+
 var _ codegen.AutoMarshal = (*X4)(nil)
 
 type __is_X4[T ~struct {
@@ -2984,6 +4089,19 @@ func (x *X4) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.C = dec.Int64()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *X4) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+		codegen.DebugField{Name: "B", Value: x.B},
+		codegen.DebugField{Name: "C", Value: x.C},
+	)
+}
+
 var _ codegen.AutoMarshal = (*X5)(nil)
 
 type __is_X5[T ~struct {
@@ -3010,6 +4128,18 @@ func (x *X5) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.B = dec.Int64()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *X5) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+		codegen.DebugField{Name: "B", Value: x.B},
+	)
+}
+
 var _ codegen.AutoMarshal = (*X6)(nil)
 
 type __is_X6[T ~struct {
@@ -3033,6 +4163,17 @@ func (x *X6) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.A = serviceweaver_dec_slice_bool_c791c3b0(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *X6) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+	)
+}
+
 func serviceweaver_enc_slice_bool_c791c3b0(enc *codegen.Encoder, arg []bool) {
 	if arg == nil {
 		enc.Len(-1)
@@ -3049,7 +4190,7 @@ func serviceweaver_dec_slice_bool_c791c3b0(dec *codegen.Decoder) []bool {
 	if n == -1 {
 		return nil
 	}
-	res := make([]bool, n)
+	res := codegen.MakeSlice[bool](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.Bool()
 	}
@@ -3109,6 +4250,27 @@ func (x *payloadC) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.K = dec.String()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *payloadC) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "A", Value: x.A},
+		codegen.DebugField{Name: "B", Value: x.B},
+		codegen.DebugField{Name: "C", Value: x.C},
+		codegen.DebugField{Name: "D", Value: x.D},
+		codegen.DebugField{Name: "E", Value: x.E},
+		codegen.DebugField{Name: "F", Value: x.F},
+		codegen.DebugField{Name: "G", Value: x.G},
+		codegen.DebugField{Name: "H", Value: x.H},
+		codegen.DebugField{Name: "I", Value: x.I},
+		codegen.DebugField{Name: "J", Value: x.J},
+		codegen.DebugField{Name: "K", Value: x.K},
+	)
+}
+
 var _ codegen.AutoMarshal = (*payloadS)(nil)
 
 type __is_payloadS[T ~struct {
@@ -3132,6 +4294,17 @@ func (x *payloadS) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.Values = serviceweaver_dec_slice_string_4af10117(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *payloadS) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Values", Value: x.Values},
+	)
+}
+
 func serviceweaver_enc_slice_string_4af10117(enc *codegen.Encoder, arg []string) {
 	if arg == nil {
 		enc.Len(-1)
@@ -3148,13 +4321,105 @@ func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
 	if n == -1 {
 		return nil
 	}
-	res := make([]string, n)
+	res := codegen.MakeSlice[string](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.String()
 	}
 	return res
 }
 
+var _ codegen.AutoMarshal = (*product)(nil)
+
+type __is_product[T ~struct {
+	weaver.AutoMarshal
+	ID          string
+	Name        string
+	Description string
+	PriceCents  int64
+	Currency    string
+	Tags        []string
+}] struct{}
+
+var _ __is_product[product]
+
+func (x *product) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("product.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ID)
+	enc.String(x.Name)
+	enc.String(x.Description)
+	enc.Int64(x.PriceCents)
+	enc.String(x.Currency)
+	serviceweaver_enc_slice_string_4af10117(enc, x.Tags)
+}
+
+func (x *product) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("product.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.String()
+	x.Name = dec.String()
+	x.Description = dec.String()
+	x.PriceCents = dec.Int64()
+	x.Currency = dec.String()
+	x.Tags = serviceweaver_dec_slice_string_4af10117(dec)
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *product) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "ID", Value: x.ID},
+		codegen.DebugField{Name: "Name", Value: x.Name},
+		codegen.DebugField{Name: "Description", Value: x.Description},
+		codegen.DebugField{Name: "PriceCents", Value: x.PriceCents},
+		codegen.DebugField{Name: "Currency", Value: x.Currency},
+		codegen.DebugField{Name: "Tags", Value: x.Tags},
+	)
+}
+
+var _ codegen.AutoMarshal = (*productOptions)(nil)
+
+type __is_productOptions[T ~struct {
+	weaver.AutoMarshal
+	Currency string
+	WithTags bool
+}] struct{}
+
+var _ __is_productOptions[productOptions]
+
+func (x *productOptions) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("productOptions.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Currency)
+	enc.Bool(x.WithTags)
+}
+
+func (x *productOptions) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("productOptions.WeaverUnmarshal: nil receiver"))
+	}
+	x.Currency = dec.String()
+	x.WithTags = dec.Bool()
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *productOptions) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Currency", Value: x.Currency},
+		codegen.DebugField{Name: "WithTags", Value: x.WithTags},
+	)
+}
+
 // Size implementations.
 
 // serviceweaver_size_X1_25e7d26b returns the size (in bytes) of the serialization
@@ -3235,3 +4500,13 @@ func serviceweaver_size_payloadC_7e82696e(x *payloadC) int {
 	size += (4 + len(x.K))
 	return size
 }
+
+// serviceweaver_size_productOptions_906f98fd returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_productOptions_906f98fd(x *productOptions) int {
+	size := 0
+	size += 0
+	size += (4 + len(x.Currency))
+	size += 1
+	return size
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clock provides the source of time used by the Service Weaver
+// runtime to measure elapsed time, e.g., when recording method latencies.
+//
+// The source defaults to the system clock. Tests can replace it with a fake
+// clock (see weavertest.FakeClock) to make time-dependent code deterministic.
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Clock tells the current time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// systemClock is a Clock that reads the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// current holds the current source of time. It is read on every component
+// method call, so it is stored in an atomic rather than guarded by a mutex.
+var current atomic.Pointer[Clock]
+
+func init() {
+	var c Clock = systemClock{}
+	current.Store(&c)
+}
+
+// Get returns the current source of time.
+func Get() Clock {
+	return *current.Load()
+}
+
+// Set replaces the current source of time with c and returns a function that
+// restores the previous one. Set(nil) restores the system clock.
+func Set(c Clock) (restore func()) {
+	if c == nil {
+		c = systemClock{}
+	}
+	prev := current.Swap(&c)
+	return func() { current.Store(prev) }
+}
+
+// Now returns the current time, according to the current source of time.
+func Now() time.Time {
+	return Get().Now()
+}
+
+// Since returns the time elapsed since t, according to the current source of
+// time.
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
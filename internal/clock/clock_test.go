@@ -0,0 +1,40 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestSet(t *testing.T) {
+	start := time.Unix(1000, 0)
+	restore := Set(fixedClock(start))
+	if got := Now(); !got.Equal(start) {
+		t.Fatalf("Now: got %v, want %v", got, start)
+	}
+	if got, want := Since(start.Add(-time.Minute)), time.Minute; got != want {
+		t.Fatalf("Since: got %v, want %v", got, want)
+	}
+
+	restore()
+	if _, ok := Get().(systemClock); !ok {
+		t.Fatalf("Get after restore: got %T, want systemClock", Get())
+	}
+}
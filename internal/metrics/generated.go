@@ -16,11 +16,51 @@ package metrics
 
 // Names of automatically populated metrics.
 const (
-	MethodCountsName       = "serviceweaver_method_count"
-	MethodErrorsName       = "serviceweaver_method_error_count"
-	MethodLatenciesName    = "serviceweaver_method_latency_micros"
-	MethodBytesRequestName = "serviceweaver_method_bytes_request"
-	MethodBytesReplyName   = "serviceweaver_method_bytes_reply"
+	MethodCountsName              = "serviceweaver_method_count"
+	MethodErrorsName              = "serviceweaver_method_error_count"
+	MethodSerializationErrorsName = "serviceweaver_method_serialization_error_count"
+	MethodLatenciesName           = "serviceweaver_method_latency_micros"
+	MethodQueueLatenciesName      = "serviceweaver_method_queue_latency_micros"
+	MethodBytesRequestName        = "serviceweaver_method_bytes_request"
+	MethodBytesReplyName          = "serviceweaver_method_bytes_reply"
+	MethodDeadlineBudgetName      = "serviceweaver_method_deadline_budget_used"
+
+	// Client side cache of component methods.
+	MethodCacheHitsName   = "serviceweaver_method_cache_hit_count"
+	MethodCacheMissesName = "serviceweaver_method_cache_miss_count"
+
+	// Server side cancellation of component methods.
+	MethodCancellationsName = "serviceweaver_method_cancel_count"
+
+	// Server side rate limiting of component methods.
+	MethodRateLimitedName = "serviceweaver_method_rate_limited_count"
+
+	// Client side bulkheads of calls between components.
+	BulkheadInUseName = "serviceweaver_bulkhead_in_use"
+
+	// Server side fair scheduling of calls across callers.
+	FairQueueDepthName = "serviceweaver_fair_queue_depth"
+
+	// Client side adaptive concurrency limits of calls to components.
+	AdaptiveConcurrencyLimitName = "serviceweaver_adaptive_concurrency_limit"
+
+	// Server side worker pools that run calls to components.
+	HandlerPoolBusyName       = "serviceweaver_handler_pool_busy"
+	HandlerPoolQueueDepthName = "serviceweaver_handler_pool_queue_depth"
+	HandlerPoolQueueWaitName  = "serviceweaver_handler_pool_queue_wait_micros"
+	HandlerPoolRejectedName   = "serviceweaver_handler_pool_rejected_count"
+
+	// Client side rerouting of calls to routed components.
+	RoutingReroutedName = "serviceweaver_routing_rerouted_count"
+
+	// Restarts of component background workers.
+	WorkerRestartsName = "serviceweaver_worker_restart_count"
+
+	// Go runtime metrics of the process hosting a component.
+	RuntimeGoroutinesName = "serviceweaver_runtime_goroutines"
+	RuntimeHeapBytesName  = "serviceweaver_runtime_heap_bytes"
+	RuntimeGCCountName    = "serviceweaver_runtime_gc_count"
+	RuntimeGCPauseName    = "serviceweaver_runtime_gc_pause_micros"
 )
 
 // GeneratedBuckets provides rounded bucket boundaries for histograms
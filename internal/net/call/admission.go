@@ -0,0 +1,109 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"sync"
+)
+
+// admission bounds the number of handlers that run concurrently on a server.
+// A call that can't run right away waits for a slot, and free slots are handed
+// out to the waiting calls in priority order (see metadata.WithPriority), with
+// calls of the same priority served in FIFO order.
+//
+// An admission is safe for concurrent use.
+type admission struct {
+	limit int // maximum number of concurrently running handlers
+
+	mu      sync.Mutex
+	running int       // number of calls currently holding a slot
+	waiters []*waiter // waiting calls, by decreasing priority and then FIFO
+}
+
+// waiter is a call waiting for a slot.
+type waiter struct {
+	priority int
+	ready    chan struct{} // closed when the call is granted a slot
+}
+
+// newAdmission returns a new admission that runs at most limit handlers at
+// once.
+func newAdmission(limit int) *admission {
+	return &admission{limit: limit}
+}
+
+// acquire blocks until the call with the provided priority is granted a slot
+// or the context is done. On success, the caller must call release exactly
+// once when the handler returns.
+func (a *admission) acquire(ctx context.Context, priority int) error {
+	a.mu.Lock()
+	if a.running < a.limit && len(a.waiters) == 0 {
+		a.running++
+		a.mu.Unlock()
+		return nil
+	}
+
+	// Queue the call behind all waiting calls with the same or a higher
+	// priority.
+	w := &waiter{priority: priority, ready: make(chan struct{})}
+	i := len(a.waiters)
+	for i > 0 && a.waiters[i-1].priority < priority {
+		i--
+	}
+	a.waiters = append(a.waiters, nil)
+	copy(a.waiters[i+1:], a.waiters[i:])
+	a.waiters[i] = w
+	a.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		for i, other := range a.waiters {
+			if other == w {
+				a.waiters = append(a.waiters[:i], a.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// We were granted a slot concurrently with the context being
+		// canceled. Give the slot back.
+		a.running--
+		a.wakeLocked()
+		return ctx.Err()
+	}
+}
+
+// release releases a slot acquired by acquire.
+func (a *admission) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running--
+	a.wakeLocked()
+}
+
+// wakeLocked hands out free slots to waiting calls.
+//
+// REQUIRES: a.mu is held.
+func (a *admission) wakeLocked() {
+	for len(a.waiters) > 0 && a.running < a.limit {
+		w := a.waiters[0]
+		a.waiters = a.waiters[1:]
+		a.running++
+		close(w.ready)
+	}
+}
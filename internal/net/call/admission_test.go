@@ -0,0 +1,94 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// waitForWaiters waits until n calls are waiting for a slot in a.
+func waitForWaiters(t *testing.T, a *admission, n int) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(time.Millisecond) {
+		a.mu.Lock()
+		waiting := len(a.waiters)
+		a.mu.Unlock()
+		if waiting == n {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %d waiters", n)
+}
+
+func TestAdmissionPriority(t *testing.T) {
+	a := newAdmission(1)
+	if err := a.acquire(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queue calls with priorities -1, 0, 1, and 0, in that order.
+	admitted := make(chan int, 4)
+	for i, priority := range []int{-1, 0, 1, 0} {
+		priority := priority
+		go func() {
+			if err := a.acquire(context.Background(), priority); err != nil {
+				t.Error(err)
+				return
+			}
+			admitted <- priority
+		}()
+		waitForWaiters(t, a, i+1)
+	}
+
+	// Release slots one at a time, and check that calls run in priority
+	// order.
+	var got []int
+	for i := 0; i < 4; i++ {
+		a.release()
+		got = append(got, <-admitted)
+	}
+	a.release()
+	if want := []int{1, 0, 0, -1}; !cmp.Equal(got, want) {
+		t.Fatalf("admission order: got %v, want %v", got, want)
+	}
+}
+
+func TestAdmissionCancel(t *testing.T) {
+	a := newAdmission(1)
+	if err := a.acquire(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// A canceled call gives up its place in line.
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- a.acquire(ctx, 1) }()
+	waitForWaiters(t, a, 1)
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("acquire: got %v, want %v", err, context.Canceled)
+	}
+	waitForWaiters(t, a, 0)
+
+	// The slot can be reacquired after it is released.
+	a.release()
+	if err := a.acquire(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+}
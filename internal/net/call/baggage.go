@@ -0,0 +1,53 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// writeBaggage serializes the OpenTelemetry baggage (if any) contained in ctx
+// into enc.
+func writeBaggage(ctx context.Context, enc *codegen.Encoder) {
+	b := baggage.FromContext(ctx)
+	if b.Len() == 0 {
+		enc.Bool(false)
+		return
+	}
+	enc.Bool(true)
+
+	// We send the baggage in its W3C text format, which is what baggage
+	// propagators use as well.
+	enc.String(b.String())
+}
+
+// readBaggage returns ctx with the OpenTelemetry baggage (if any) stored in
+// dec.
+func readBaggage(ctx context.Context, dec *codegen.Decoder) context.Context {
+	hasBaggage := dec.Bool()
+	if !hasBaggage {
+		return ctx
+	}
+	b, err := baggage.Parse(dec.String())
+	if err != nil {
+		// The baggage was serialized by writeBaggage, so it should always
+		// parse. If it doesn't, we drop it rather than fail the call.
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, b)
+}
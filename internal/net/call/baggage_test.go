@@ -0,0 +1,72 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestBaggageSerialization(t *testing.T) {
+	tenant, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := baggage.NewMember("user", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := baggage.New(tenant, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serialize the baggage.
+	enc := codegen.NewEncoder()
+	writeBaggage(baggage.ContextWithBaggage(context.Background(), want), enc)
+
+	// Deserialize the baggage.
+	dec := codegen.NewDecoder(enc.Data())
+	got := baggage.FromContext(readBaggage(context.Background(), dec))
+	// Baggage members are unordered, so we compare them as sets.
+	less := func(x, y baggage.Member) bool { return x.Key() < y.Key() }
+	if diff := cmp.Diff(want.Members(), got.Members(), cmpopts.SortSlices(less), cmp.Comparer(func(x, y baggage.Member) bool {
+		return x.String() == y.String()
+	})); diff != "" {
+		t.Errorf("baggage (-want,+got):\n%s", diff)
+	}
+	if !dec.Empty() {
+		t.Errorf("unexpected bytes left to be read")
+	}
+}
+
+func TestEmptyBaggageSerialization(t *testing.T) {
+	enc := codegen.NewEncoder()
+	writeBaggage(context.Background(), enc)
+
+	dec := codegen.NewDecoder(enc.Data())
+	ctx := readBaggage(context.Background(), dec)
+	if got := baggage.FromContext(ctx); got.Len() != 0 {
+		t.Errorf("baggage: got %q, want empty", got)
+	}
+	if !dec.Empty() {
+		t.Errorf("unexpected bytes left to be read")
+	}
+}
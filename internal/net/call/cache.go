@@ -0,0 +1,117 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+)
+
+// maxCacheEntries is the maximum number of replies cached per method.
+const maxCacheEntries = 1024
+
+var (
+	cacheHits = metrics.NewCounterMap[cacheLabels](
+		imetrics.MethodCacheHitsName,
+		"Count of Service Weaver component method calls served from the client side cache",
+	)
+	cacheMisses = metrics.NewCounterMap[cacheLabels](
+		imetrics.MethodCacheMissesName,
+		"Count of Service Weaver component method calls not found in the client side cache",
+	)
+)
+
+type cacheLabels struct {
+	Component string // full component name
+	Method    string // method name
+}
+
+// replyCache caches the replies of the calls to a method, keyed by their
+// serialized arguments, for a fixed amount of time. It is safe for concurrent
+// use.
+type replyCache struct {
+	ttl    time.Duration    // how long a reply is cached
+	hits   *metrics.Counter // calls served from the cache
+	misses *metrics.Counter // calls not found in the cache
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry // cached replies, by serialized arguments
+}
+
+// cacheEntry is a cached reply.
+type cacheEntry struct {
+	reply   []byte    // serialized results
+	expires time.Time // when the entry expires
+}
+
+// newReplyCache returns a cache for the replies of the provided method that
+// caches replies for ttl.
+func newReplyCache(component, method string, ttl time.Duration) *replyCache {
+	labels := cacheLabels{Component: component, Method: method}
+	return &replyCache{
+		ttl:     ttl,
+		hits:    cacheHits.Get(labels),
+		misses:  cacheMisses.Get(labels),
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// get returns the cached reply of a call with the provided serialized
+// arguments, if any.
+func (c *replyCache) get(args []byte) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[string(args)]
+	if !ok || !clock.Now().Before(entry.expires) {
+		c.misses.Inc()
+		return nil, false
+	}
+	c.hits.Inc()
+	// The caller may modify the reply, so we return a copy.
+	return bytes.Clone(entry.reply), true
+}
+
+// put caches the reply of a call with the provided serialized arguments,
+// unless a reply of such a call is already cached. This keeps the replies
+// served from the cache from extending their own lifetime.
+func (c *replyCache) put(args, reply []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := clock.Now()
+	if entry, ok := c.entries[string(args)]; ok && now.Before(entry.expires) {
+		return
+	}
+	if len(c.entries) >= maxCacheEntries {
+		// Make room by dropping the expired entries, or an arbitrary entry
+		// if none has expired.
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		for key := range c.entries {
+			if len(c.entries) < maxCacheEntries {
+				break
+			}
+			delete(c.entries, key)
+		}
+	}
+	c.entries[string(args)] = cacheEntry{reply: bytes.Clone(reply), expires: now.Add(c.ttl)}
+}
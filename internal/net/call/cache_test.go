@@ -0,0 +1,80 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/google/go-cmp/cmp"
+)
+
+// manualClock is a clock.Clock that only advances when told to.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func TestReplyCache(t *testing.T) {
+	c := &manualClock{now: time.Now()}
+	defer clock.Set(c)()
+
+	cache := newReplyCache("pkg/Currency", "GetSupportedCurrencies", time.Minute)
+	if _, ok := cache.get([]byte("args")); ok {
+		t.Fatal("empty cache hit")
+	}
+	cache.put([]byte("args"), []byte("reply"))
+	if got, ok := cache.get([]byte("args")); !ok || string(got) != "reply" {
+		t.Fatalf("get: got %q, %t, want %q, true", got, ok, "reply")
+	}
+	if _, ok := cache.get([]byte("other args")); ok {
+		t.Fatal("cache hit for different arguments")
+	}
+
+	// The reply expires after the TTL.
+	c.now = c.now.Add(time.Minute)
+	if _, ok := cache.get([]byte("args")); ok {
+		t.Fatal("expired reply returned")
+	}
+
+	// Check the hit and miss counts.
+	got := map[string]float64{}
+	for _, snap := range metrics.Snapshot() {
+		if snap.Labels["component"] == "pkg/Currency" && snap.Labels["method"] == "GetSupportedCurrencies" {
+			got[snap.Name] = snap.Value
+		}
+	}
+	want := map[string]float64{
+		imetrics.MethodCacheHitsName:   1,
+		imetrics.MethodCacheMissesName: 3,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("bad metrics (-want +got):\n%s", diff)
+	}
+}
+
+func TestReplyCacheBounded(t *testing.T) {
+	cache := newReplyCache("pkg/Currency", "Convert", time.Hour)
+	for i := 0; i < 2*maxCacheEntries; i++ {
+		cache.put([]byte{byte(i), byte(i >> 8)}, []byte("reply"))
+	}
+	if got := len(cache.entries); got > maxCacheEntries {
+		t.Fatalf("got %d entries, want at most %d", got, maxCacheEntries)
+	}
+}
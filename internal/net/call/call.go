@@ -78,16 +78,21 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	"github.com/ServiceWeaver/weaver/internal/session"
+	"github.com/ServiceWeaver/weaver/metadata"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"github.com/ServiceWeaver/weaver/runtime/logging"
 	"github.com/ServiceWeaver/weaver/runtime/retry"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // Connection allows a client to send RPCs.
 type Connection interface {
-	// Call makes an RPC over a Connection.
+	// Call makes an RPC over a Connection. Call does not retain the provided
+	// argument after it returns, so the caller is free to reuse it.
 	Call(context.Context, MethodKey, []byte, CallOptions) ([]byte, error)
 
 	// Close closes a connection. Pending invocations of Call are cancelled and
@@ -193,6 +198,9 @@ type call struct {
 	// synchronized via doneSignal, i.e., it is never concurrent.
 	err      error
 	response []byte
+	token    string        // session token issued by the server, if any
+	queue    time.Duration // time the call waited at the server
+	maxReply int           // see CallOptions.MaxReplyBytes
 
 	// Is the call done?
 	// This field is accessed across goroutines using atomics.
@@ -210,20 +218,31 @@ type serverConnection struct {
 	closed      bool              // has c been closed?
 	version     version           // Version number to use for connection
 	cancelFuncs map[uint64]func() // Cancellation functions for in-progress calls
+	admission   *admission        // If not nil, bounds the number of running handlers
+	replies     *replyBudget      // If not nil, bounds the bytes of unwritten replies
 }
 
 // serverState tracks all live server-side connections so we can clean things up when canceled.
 type serverState struct {
-	opts  ServerOptions
-	mu    sync.Mutex
-	conns map[*serverConnection]struct{} // Live connections
+	opts      ServerOptions
+	admission *admission // Shared by all connections; nil if unbounded
+	mu        sync.Mutex
+	conns     map[*serverConnection]struct{} // Live connections
+}
+
+// newServerState returns a new serverState with the provided options.
+func newServerState(opts ServerOptions) *serverState {
+	ss := &serverState{opts: opts.withDefaults()}
+	if ss.opts.MaxConcurrentCalls > 0 {
+		ss.admission = newAdmission(ss.opts.MaxConcurrentCalls)
+	}
+	return ss
 }
 
 // Serve starts listening for connections and requests on l. It always returns a
 // non-nil error and closes l.
 func Serve(ctx context.Context, l Listener, opts ServerOptions) error {
-	opts = opts.withDefaults()
-	ss := &serverState{opts: opts}
+	ss := newServerState(opts)
 	defer ss.stop()
 	l = &onceCloseListener{Listener: l, closer: sync.OnceValue(l.Close)}
 
@@ -260,7 +279,7 @@ func (oc *onceCloseListener) Close() error {
 // network connection with a client. This can be useful in tests or
 // when using custom networking transports.
 func ServeOn(ctx context.Context, conn net.Conn, hmap *HandlerMap, opts ServerOptions) {
-	ss := &serverState{opts: opts.withDefaults()}
+	ss := newServerState(opts)
 	ss.serveConnection(ctx, conn, hmap)
 }
 
@@ -271,6 +290,10 @@ func (ss *serverState) serveConnection(ctx context.Context, conn net.Conn, hmap
 		cbuf:        bufio.NewReader(conn),
 		version:     initialVersion, // Updated when we hear from client
 		cancelFuncs: map[uint64]func(){},
+		admission:   ss.admission,
+	}
+	if ss.opts.MaxPendingReplyBytes > 0 {
+		c.replies = newReplyBudget(ss.opts.MaxPendingReplyBytes)
 	}
 	ss.register(c)
 
@@ -365,22 +388,53 @@ func (rc *reconnectingConnection) Close() {
 	rc.resolverDone.Wait()
 }
 
-// Call makes an RPC over connection c, retrying it on network errors if retries are allowed.
+// maxRejectedAttempts is the maximum number of times Call sends a call that
+// the server keeps rejecting with a retryable error.
+const maxRejectedAttempts = 5
+
+// Call makes an RPC over connection c, retrying it on network errors if retries
+// are allowed. Calls that the server rejects with a retryable error (see
+// codegen.Retryable), e.g., because it is overloaded or shutting down, were
+// never run, so they are retried a bounded number of times regardless.
+//
+// If opts.Tracer is not nil, every retry is traced as its own span, a child of
+// the span in ctx, with a "retry.attempt" attribute and a link to the span of
+// the previous attempt. The first attempt is traced by the span in ctx itself.
 func (rc *reconnectingConnection) Call(ctx context.Context, h MethodKey, arg []byte, opts CallOptions) ([]byte, error) {
-	if !opts.Retry {
-		return rc.callOnce(ctx, h, arg, opts)
-	}
-	for r := retry.Begin(); r.Continue(ctx); {
-		response, err := rc.callOnce(ctx, h, arg, opts)
-		if errors.Is(err, Unreachable) || errors.Is(err, CommunicationError) {
-			continue
+	r := retry.Begin()
+	r.Continue(ctx) // the first call to Continue does not back off
+	prev := trace.SpanContextFromContext(ctx)
+	for attempt, rejected := 0, 0; ; attempt++ {
+		attemptCtx, span := ctx, trace.Span(nil)
+		if attempt > 0 && opts.Tracer != nil {
+			attemptCtx, span = opts.Tracer.Start(ctx, opts.SpanName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithLinks(trace.Link{SpanContext: prev}),
+				trace.WithAttributes(attribute.Int("retry.attempt", attempt)))
+			prev = span.SpanContext()
+		}
+		response, err := rc.callOnce(attemptCtx, h, arg, opts)
+		if span != nil {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+		switch {
+		case opts.Retry && (errors.Is(err, Unreachable) || errors.Is(err, CommunicationError)):
+		case codegen.IsRetryable(err) && rejected+1 < maxRejectedAttempts:
+			rejected++
+		default:
+			return response, err
+		}
+		if !r.Continue(ctx) {
+			return nil, ctx.Err()
 		}
-		return response, err
 	}
-	return nil, ctx.Err()
 }
 
-func (rc *reconnectingConnection) callOnce(ctx context.Context, h MethodKey, arg []byte, opts CallOptions) ([]byte, error) {
+func (rc *reconnectingConnection) callOnce(ctx context.Context, h MethodKey, arg []byte, opts CallOptions) (result []byte, err error) {
 	var micros int64
 	deadline, haveDeadline := ctx.Deadline()
 	if haveDeadline {
@@ -396,8 +450,68 @@ func (rc *reconnectingConnection) callOnce(ctx context.Context, h MethodKey, arg
 		}
 	}
 
-	// Encode the header.
-	hdr := encodeHeader(ctx, h, micros)
+	rpc := &call{maxReply: opts.MaxReplyBytes}
+	rpc.doneSignal = make(chan struct{})
+
+	// Route calls made in a session to the replica that issued its token.
+	var affinity string
+	jar, hasSession := session.JarFromContext(ctx)
+	if hasSession {
+		_, affinity = jar.Get()
+	}
+
+	// TODO: Arrange to obey deadline in any reconnection done inside startCall.
+	conn, nc, v, err := rc.startCall(ctx, rpc, affinity, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compress the request payload, if it is large enough and the server
+	// supports compression.
+	var comp compressionHeader
+	if v >= compressionVersion {
+		comp = compressionHeader{accept: opts.Compression, threshold: opts.CompressionThreshold}
+	}
+	if comp.accept != NoCompression && len(arg) >= opts.CompressionThreshold {
+		compressed, err := compress(opts.Compression, arg)
+		if err != nil {
+			conn.endCall(rpc)
+			return nil, err
+		}
+		if len(compressed) < len(arg) {
+			arg = compressed
+			comp.request = opts.Compression
+		}
+	}
+	defer func() {
+		if atomic.LoadUint32(&rpc.done) == 0 {
+			return
+		}
+		// Store the session token issued by the server, if any.
+		if hasSession && rpc.token != "" {
+			jar.Set(rpc.token, conn.Address())
+		}
+		// Report how long the call waited at the server.
+		if opts.Queue != nil {
+			*opts.Queue = rpc.queue
+		}
+	}()
+	if obs, ok := rc.opts.Balancer.(callObserver); ok {
+		// Report the outcome of the call to the balancer, unless the call
+		// was canceled or timed out.
+		defer func() {
+			if ctx.Err() != nil {
+				return
+			}
+			rc.mu.Lock()
+			defer rc.mu.Unlock()
+			obs.observe(conn, err)
+		}()
+	}
+
+	// Encode the header, in the format of the version negotiated with the
+	// server.
+	hdr := encodeHeader(ctx, h, micros, comp, v)
 
 	// Note that we send the header and the payload as follows:
 	// [header_length][encoded_header][payload]
@@ -405,14 +519,6 @@ func (rc *reconnectingConnection) callOnce(ctx context.Context, h MethodKey, arg
 	binary.LittleEndian.PutUint32(hdrLen[:], uint32(len(hdr)))
 	hdrSlice := append(hdrLen[:], hdr...)
 
-	rpc := &call{}
-	rpc.doneSignal = make(chan struct{})
-
-	// TODO: Arrange to obey deadline in any reconnection done inside startCall.
-	conn, nc, err := rc.startCall(ctx, rpc, opts)
-	if err != nil {
-		return nil, err
-	}
 	if err := writeMessage(nc, &conn.wlock, requestMessage, rpc.id, hdrSlice, arg, rc.opts.WriteFlattenLimit); err != nil {
 		conn.shutdown("client send request", err)
 		conn.endCall(rpc)
@@ -525,17 +631,26 @@ func (rc *reconnectingConnection) updateEndpoints(ctx context.Context, endpoints
 	return nil
 }
 
-// startCall registers a new in-progress call.
+// startCall registers a new in-progress call. If affinity is the address of a
+// replica in the balancer, the call is sent to that replica. startCall returns
+// the connection the call was registered on, along with the protocol version
+// negotiated for it.
 // REQUIRES: rc.mu is not held.
-func (rc *reconnectingConnection) startCall(ctx context.Context, rpc *call, opts CallOptions) (*clientConnection, net.Conn, error) {
+func (rc *reconnectingConnection) startCall(ctx context.Context, rpc *call, affinity string, opts CallOptions) (*clientConnection, net.Conn, version, error) {
 	for r := retry.Begin(); r.Continue(ctx); {
 		rc.mu.Lock()
 		if rc.closed {
 			rc.mu.Unlock()
-			return nil, nil, fmt.Errorf("Call on closed Connection")
+			return nil, nil, 0, fmt.Errorf("Call on closed Connection")
 		}
 
-		replica, ok := rc.opts.Balancer.Pick(opts)
+		var replica ReplicaConnection
+		var ok bool
+		if c, found := rc.conns[affinity]; found && c.inBalancer {
+			replica, ok = c, true
+		} else {
+			replica, ok = rc.opts.Balancer.Pick(opts)
+		}
 		if !ok {
 			rc.mu.Unlock()
 			continue
@@ -544,20 +659,20 @@ func (rc *reconnectingConnection) startCall(ctx context.Context, rpc *call, opts
 		c, ok := replica.(*clientConnection)
 		if !ok {
 			rc.mu.Unlock()
-			return nil, nil, fmt.Errorf("internal error: wrong connection type %#v returned by load balancer", replica)
+			return nil, nil, 0, fmt.Errorf("internal error: wrong connection type %#v returned by load balancer", replica)
 		}
 
 		c.lastID++
 		rpc.id = c.lastID
 		c.calls[rpc.id] = rpc
 		c.callstart()
-		nc := c.c
+		nc, v := c.c, c.version
 		rc.mu.Unlock()
 
-		return c, nc, nil
+		return c, nc, v, nil
 	}
 
-	return nil, nil, ctx.Err()
+	return nil, nil, 0, ctx.Err()
 }
 
 func (c *clientConnection) Address() string {
@@ -802,10 +917,12 @@ func (c *clientConnection) connectOnce(ctx context.Context) bool {
 	c.connected()
 
 	// Handshake to get the peer version and verify that it is live.
-	if err := c.exchangeVersions(); err != nil {
+	v, err := c.exchangeVersions()
+	if err != nil {
 		c.fail("handshake", err)
 		return false
 	}
+	c.version = v
 	c.checked()
 
 	for c.state == idle || c.state == active || c.state == draining {
@@ -816,8 +933,9 @@ func (c *clientConnection) connectOnce(ctx context.Context) bool {
 	return true
 }
 
-// exchangeVersions sends client version to server and waits for the server version.
-func (c *clientConnection) exchangeVersions() error {
+// exchangeVersions sends client version to server and waits for the server
+// version. It returns the version to use for the connection.
+func (c *clientConnection) exchangeVersions() (version, error) {
 	nc, buf := c.c, c.cbuf
 
 	// Do not hold mutex while reading from the network.
@@ -825,35 +943,60 @@ func (c *clientConnection) exchangeVersions() error {
 	defer c.rc.mu.Lock()
 
 	if err := writeVersion(nc, &c.wlock); err != nil {
-		return err
+		return 0, err
 	}
 	mt, id, msg, err := readMessage(buf)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if mt != versionMessage {
-		return fmt.Errorf("wrong message type %d, expecting %d", mt, versionMessage)
+		return 0, fmt.Errorf("wrong message type %d, expecting %d", mt, versionMessage)
 	}
-	v, err := getVersion(id, msg)
-	if err != nil {
-		return err
-	}
-	c.version = v
-	return nil
+	return getVersion(id, msg)
 }
 
 // readAndProcessMessage reads and handles one message sent from the server.
 func (c *clientConnection) readAndProcessMessage() error {
-	buf := c.cbuf
+	buf, v := c.cbuf, c.version
 
 	// Do not hold mutex while reading from the network.
 	c.rc.mu.Unlock()
 	defer c.rc.mu.Lock()
 
-	mt, id, msg, err := readMessage(buf)
+	mt, id, n, err := readHeader(buf)
 	if err != nil {
 		return err
 	}
+	if mt == responseMessage || mt == compressedResponseMessage {
+		// Discard replies over the limit of their call without reading them
+		// into memory. A compressed reply is never larger than the reply it
+		// compresses.
+		c.rc.mu.Lock()
+		rpc := c.calls[id]
+		c.rc.mu.Unlock()
+		if rpc != nil && rpc.maxReply > 0 {
+			size := n
+			if v >= queueVersion {
+				size -= 8
+			}
+			if err := codegen.CheckPayloadSize("reply", size, rpc.maxReply); err != nil {
+				if _, err := io.CopyN(io.Discard, buf, int64(n)); err != nil {
+					return err
+				}
+				if rpc := c.findAndEndCall(id); rpc != nil {
+					rpc.err = err
+					atomic.StoreUint32(&rpc.done, 1)
+					close(rpc.doneSignal)
+				}
+				return nil
+			}
+		}
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(buf, msg); err != nil {
+		return err
+	}
+
 	switch mt {
 	case versionMessage:
 		_, err := getVersion(id, msg)
@@ -861,18 +1004,48 @@ func (c *clientConnection) readAndProcessMessage() error {
 			return err
 		}
 		// Ignore versions sent after initial hand-shake
-	case responseMessage, responseError:
+	case sessionMessage:
+		c.rc.mu.Lock()
+		rpc := c.calls[id]
+		c.rc.mu.Unlock()
+		if rpc != nil {
+			// The response to the call follows, so the token is visible to
+			// the caller once the call is done.
+			rpc.token = string(msg)
+		}
+	case responseMessage, responseError, compressedResponseMessage:
 		rpc := c.findAndEndCall(id)
 		if rpc == nil {
 			return nil // May have been canceled
 		}
-		if mt == responseError {
+		if v >= queueVersion {
+			if len(msg) < 8 {
+				rpc.err = fmt.Errorf("%w: missing response queue latency", CommunicationError)
+				atomic.StoreUint32(&rpc.done, 1)
+				close(rpc.doneSignal)
+				return nil
+			}
+			rpc.queue = time.Duration(binary.LittleEndian.Uint64(msg)) * time.Microsecond
+			msg = msg[8:]
+		}
+		switch mt {
+		case responseError:
 			if err, ok := decodeError(msg); ok {
 				rpc.err = err
 			} else {
 				rpc.err = fmt.Errorf("%w: could not decode error", CommunicationError)
 			}
-		} else {
+		case compressedResponseMessage:
+			if len(msg) == 0 {
+				rpc.err = fmt.Errorf("%w: missing response compression", CommunicationError)
+			} else if response, err := decompress(Compression(msg[0]), msg[1:], rpc.maxReply); errors.Is(err, codegen.PayloadTooLargeError) {
+				rpc.err = err
+			} else if err != nil {
+				rpc.err = fmt.Errorf("%w: could not decompress response: %v", CommunicationError, err)
+			} else {
+				rpc.response = response
+			}
+		default:
 			rpc.response = msg
 		}
 		atomic.StoreUint32(&rpc.done, 1)
@@ -918,14 +1091,14 @@ func (c *serverConnection) readRequests(ctx context.Context, hmap *HandlerMap, o
 				t := time.AfterFunc(c.opts.InlineHandlerDuration, func() {
 					c.readRequests(ctx, hmap, onDone)
 				})
-				c.runHandler(hmap, id, msg)
+				c.runHandler(hmap, id, msg, clock.Now())
 				if !t.Stop() {
 					// Another goroutine is reading incoming requests: bail out.
 					return
 				}
 			} else {
 				// Run the handler in a separate goroutine.
-				go c.runHandler(hmap, id, msg)
+				go c.runHandler(hmap, id, msg, clock.Now())
 			}
 		case cancelMessage:
 			c.endRequest(id)
@@ -940,8 +1113,9 @@ func (c *serverConnection) readRequests(ctx context.Context, hmap *HandlerMap, o
 }
 
 // runHandler runs an application specified RPC handler at the server side.
-// The result (or error) from the handler is sent back to the client over c.
-func (c *serverConnection) runHandler(hmap *HandlerMap, id uint64, msg []byte) {
+// The result (or error) from the handler is sent back to the client over c,
+// along with how long the request waited since it was received.
+func (c *serverConnection) runHandler(hmap *HandlerMap, id uint64, msg []byte, received time.Time) {
 	msgLen := uint32(len(msg))
 	if msgLen < hdrLenLen {
 		c.shutdown("server handler", fmt.Errorf("missing request header length"))
@@ -957,7 +1131,10 @@ func (c *serverConnection) runHandler(hmap *HandlerMap, id uint64, msg []byte) {
 	}
 
 	// Extracts header information.
-	ctx, hkey, micros, sc := decodeHeader(msg[hdrLenLen:hdrEndOffset])
+	c.mu.Lock()
+	v := c.version
+	c.mu.Unlock()
+	ctx, hkey, micros, sc, comp := decodeHeader(msg[hdrLenLen:hdrEndOffset], v)
 
 	// Extracts the method name.
 	methodName := hmap.names[hkey]
@@ -991,13 +1168,30 @@ func (c *serverConnection) runHandler(hmap *HandlerMap, id uint64, msg []byte) {
 		defer span.End()
 	}
 
+	// Trace debug calls, even if the caller isn't tracing.
+	debug := metadata.IsDebug(ctx)
+	if debug && sc == nil {
+		ctx, span = c.opts.Tracer.Start(ctx, methodName, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+	}
+	var start time.Time
+	if debug {
+		start = time.Now()
+	}
+
 	// Call the handler passing it the payload.
 	payload := msg[hdrEndOffset:]
 	var err error
 	var result []byte
+	var queue time.Duration
 	fn, ok := hmap.handlers[hkey]
 	if !ok {
-		err = fmt.Errorf("internal error: unknown function")
+		// The caller may be running a newer version of the component, with
+		// methods this server doesn't have yet. It retries the call, possibly
+		// against an up-to-date replica.
+		err = codegen.Retryable(fmt.Errorf("method key %x: %w", hkey[:], codegen.UnknownMethodError))
+	} else if payload, err = decompress(comp.request, payload, hmap.maxRequest[hkey]); err != nil {
+		err = fmt.Errorf("decompress request: %w", err)
 	} else {
 		if err := c.startRequest(id, cancelFunc); err != nil {
 			logError(c.opts.Logger, "handle "+hmap.names[hkey], err)
@@ -1005,7 +1199,28 @@ func (c *serverConnection) runHandler(hmap *HandlerMap, id uint64, msg []byte) {
 		}
 		cancelFunc = nil // endRequest() or cancellation will deal with it
 		defer c.endRequest(id)
-		result, err = fn(ctx, payload)
+		if c.replies != nil {
+			// Wait for the client to drain the pending replies, if any.
+			err = c.replies.wait(ctx)
+		}
+		if err == nil && c.admission != nil {
+			// Wait for our turn, if the server is running too many calls.
+			err = c.admission.acquire(ctx, metadata.Priority(ctx))
+		}
+		if err == nil {
+			queue = clock.Since(received)
+			result, err = fn(ctx, payload)
+			if c.admission != nil {
+				c.admission.release()
+			}
+			if ctx.Err() != nil {
+				// The caller stopped waiting for the call before it returned.
+				recordCancellation(hmap.names[hkey])
+				if err == nil {
+					span.SetStatus(codes.Error, "cancelled: "+ctx.Err().Error())
+				}
+			}
+		}
 	}
 
 	mt := responseMessage
@@ -1014,9 +1229,49 @@ func (c *serverConnection) runHandler(hmap *HandlerMap, id uint64, msg []byte) {
 		result = encodeError(err)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+	} else if comp.accept != NoCompression && len(result) >= comp.threshold {
+		// Compress the reply, if the client accepts it and it is large
+		// enough. The codec is sent in the first byte of the payload.
+		if compressed, err := compress(comp.accept, result); err == nil && len(compressed)+1 < len(result) {
+			mt = compressedResponseMessage
+			result = append([]byte{byte(comp.accept)}, compressed...)
+		}
+	}
+
+	if debug {
+		// Record the details of debug calls.
+		span.SetAttributes(
+			attribute.Bool("serviceweaver.debug", true),
+			attribute.Int("serviceweaver.request_bytes", len(payload)),
+			attribute.Int("serviceweaver.reply_bytes", len(result)),
+		)
+		c.opts.Logger.Debug("debug call", "method", methodName, "duration", time.Since(start), "request_bytes", len(payload), "reply_bytes", len(result), "err", err)
 	}
 
-	if err := writeMessage(c.c, &c.wlock, mt, id, nil, result, c.opts.WriteFlattenLimit); err != nil {
+	if s, ok := session.ServerFromContext(ctx); ok {
+		// Send the session token issued by the handler, if any, ahead of the
+		// response.
+		if token, issued := s.Issued(); issued {
+			if err := writeMessage(c.c, &c.wlock, sessionMessage, id, nil, []byte(token), c.opts.WriteFlattenLimit); err != nil {
+				c.shutdown("server write "+hmap.names[hkey], err)
+				return
+			}
+		}
+	}
+
+	if c.replies != nil {
+		c.replies.add(len(result))
+		defer c.replies.done(len(result))
+	}
+	// Send how long the request was queued ahead of the result, if the client
+	// expects it.
+	var queueBuf [8]byte
+	var queueHdr []byte
+	if v >= queueVersion {
+		binary.LittleEndian.PutUint64(queueBuf[:], uint64(queue.Microseconds()))
+		queueHdr = queueBuf[:]
+	}
+	if err := writeMessage(c.c, &c.wlock, mt, id, queueHdr, result, c.opts.WriteFlattenLimit); err != nil {
 		c.shutdown("server write "+hmap.names[hkey], err)
 	}
 }
@@ -1058,8 +1313,9 @@ func (c *serverConnection) shutdown(details string, err error) {
 	}
 }
 
-// encodeHeader encodes the header information that is propagated by each message.
-func encodeHeader(ctx context.Context, h MethodKey, micros int64) []byte {
+// encodeHeader encodes the header information that is propagated by each
+// message, in the format of protocol version v.
+func encodeHeader(ctx context.Context, h MethodKey, micros int64, comp compressionHeader, v version) []byte {
 	enc := codegen.NewEncoder()
 	copy(enc.Grow(len(h)), h[:])
 	enc.Int64(micros)
@@ -1070,11 +1326,42 @@ func encodeHeader(ctx context.Context, h MethodKey, micros int64) []byte {
 	// Send context metadata in the header.
 	writeContextMetadata(ctx, enc)
 
+	// Send baggage in the header.
+	if v >= baggageVersion {
+		writeBaggage(ctx, enc)
+	}
+
+	// Send the debug flag in the header.
+	if v >= debugVersion {
+		writeDebug(ctx, enc)
+	}
+
+	// Send the priority in the header.
+	if v >= priorityVersion {
+		writePriority(ctx, enc)
+	}
+
+	// Send compression information in the header.
+	if v >= compressionVersion {
+		writeCompression(comp, enc)
+	}
+
+	// Send the session token in the header.
+	if v >= sessionVersion {
+		writeSession(ctx, enc)
+	}
+
+	// Send the caller in the header.
+	if v >= callerVersion {
+		writeCaller(ctx, enc)
+	}
+
 	return enc.Data()
 }
 
-// decodeHeader extracts the encoded header information.
-func decodeHeader(hdr []byte) (context.Context, MethodKey, int64, *trace.SpanContext) {
+// decodeHeader extracts the header information encoded by encodeHeader for
+// protocol version v.
+func decodeHeader(hdr []byte, v version) (context.Context, MethodKey, int64, *trace.SpanContext, compressionHeader) {
 	dec := codegen.NewDecoder(hdr)
 
 	// Extract handler key.
@@ -1089,7 +1376,38 @@ func decodeHeader(hdr []byte) (context.Context, MethodKey, int64, *trace.SpanCon
 
 	// Extract metadata context information if any.
 	ctx := readContextMetadata(context.Background(), dec)
-	return ctx, hkey, micros, sc
+
+	// Extract baggage if any.
+	if v >= baggageVersion {
+		ctx = readBaggage(ctx, dec)
+	}
+
+	// Extract the debug flag.
+	if v >= debugVersion {
+		ctx = readDebug(ctx, dec)
+	}
+
+	// Extract the priority.
+	if v >= priorityVersion {
+		ctx = readPriority(ctx, dec)
+	}
+
+	// Extract compression information.
+	var comp compressionHeader
+	if v >= compressionVersion {
+		comp = readCompression(dec)
+	}
+
+	// Extract the session token, if any.
+	if v >= sessionVersion {
+		ctx = readSession(ctx, dec)
+	}
+
+	// Extract the caller, if any.
+	if v >= callerVersion {
+		ctx = readCaller(ctx, dec)
+	}
+	return ctx, hkey, micros, sc, comp
 }
 
 func logError(logger *slog.Logger, details string, err error) {
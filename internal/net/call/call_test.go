@@ -35,10 +35,19 @@ import (
 	"time"
 
 	"github.com/ServiceWeaver/weaver/internal/cond"
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
 	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/internal/reflection"
 	"github.com/ServiceWeaver/weaver/internal/traceio"
+	"github.com/ServiceWeaver/weaver/metadata"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/transport"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -47,10 +56,13 @@ type resolverMaker func(...call.Endpoint) call.Resolver
 var (
 	echoKey       = call.MakeMethodKey("", "echo")
 	whoKey        = call.MakeMethodKey("", "who")
+	outlierKey    = call.MakeMethodKey("", "outlier")
+	sessionKey    = call.MakeMethodKey("", "session")
 	errorKey      = call.MakeMethodKey("", "error")
 	cancelWaitKey = call.MakeMethodKey("", "cancelwait")
 	sleepKey      = call.MakeMethodKey("", "sleep")
 	customKey     = call.MakeMethodKey("", "custom")
+	boundedKey    = call.MakeMethodKey("", "bounded")
 	handlers      = makeHandlerMap()
 	tlsConfig     = makeTLSConfig()
 
@@ -71,6 +83,8 @@ func makeHandlerMap() *call.HandlerMap {
 	m.Set("", "cancelwait", cancelWaitHandler)
 	m.Set("", "sleep", sleepHandler)
 	m.Set("", "custom", customHandler)
+	m.Set("", "bounded", echoHandler)
+	m.SetMaxRequestBytes("", "bounded", boundedMaxRequest)
 	return m
 }
 
@@ -118,6 +132,17 @@ type testListener struct {
 
 var _ call.Listener = &testListener{}
 
+// hmapListener is a call.Listener that serves the provided handlers.
+type hmapListener struct {
+	net.Listener
+	hmap *call.HandlerMap
+}
+
+func (l hmapListener) Accept() (net.Conn, *call.HandlerMap, error) {
+	conn, err := l.Listener.Accept()
+	return conn, l.hmap, err
+}
+
 func (l testListener) Accept() (net.Conn, *call.HandlerMap, error) {
 	conn, err := l.Listener.Accept()
 	if err != nil {
@@ -154,9 +179,18 @@ func startServers(ctx context.Context, opts call.ServerOptions) map[string]call.
 	}
 	go serve(testListener{Listener: mtlsListener, tlsConfig: tlsConfig})
 
+	// Start the server that uses the registered "memory" transport.
+	memory, _ := transport.Lookup("memory")
+	memoryListener, err := memory.Listen("")
+	if err != nil {
+		panic(err)
+	}
+	go serve(testListener{Listener: memoryListener})
+
 	return map[string]call.Endpoint{
-		"tcp":  call.TCP(tcpListener.Addr().String()),
-		"mtls": call.MTLS(tlsConfig, call.TCP(mtlsListener.Addr().String())),
+		"tcp":    call.TCP(tcpListener.Addr().String()),
+		"mtls":   call.MTLS(tlsConfig, call.TCP(mtlsListener.Addr().String())),
+		"memory": call.NetEndpoint{Net: "memory", Addr: memoryListener.Addr().String()},
 	}
 }
 
@@ -169,16 +203,21 @@ const (
 	// much smaller than testTimeout.
 	shortDelay = time.Millisecond * 100
 
+	// boundedMaxRequest is the request limit of the "bounded" method.
+	boundedMaxRequest = 1 << 10
+
 	// delaySlop is extra delay added to account for usual jitter in execution
 	// times (e.g., scheduling delays). It should be much smaller than testTimeout.
 	delaySlop = time.Second
 )
 
 // handlersFor returns a copy of handlers with a whoHandler that returns
-// `server`.
+// `server` and an outlierHandler for `server`.
 func handlersFor(server string) *call.HandlerMap {
 	h := makeHandlerMap()
 	h.Set("", "who", whoHandler(server))
+	h.Set("", "outlier", outlierHandler(server))
+	h.Set("", "session", sessionHandler(server))
 	return h
 }
 
@@ -192,6 +231,29 @@ func whoHandler(name string) call.Handler {
 	}
 }
 
+// sessionHandler returns a handler that issues a session token, unless the
+// caller sent one, and returns `name` followed by the token sent by the caller.
+func sessionHandler(name string) call.Handler {
+	return func(ctx context.Context, _ []byte) ([]byte, error) {
+		token, ok := metadata.SessionToken(ctx)
+		if !ok {
+			metadata.SetSessionToken(ctx, "token-"+name)
+		}
+		return []byte(name + "/" + token), nil
+	}
+}
+
+// outlierHandler returns a handler that always fails on server "1" and returns
+// `name` on every other server.
+func outlierHandler(name string) call.Handler {
+	return func(context.Context, []byte) ([]byte, error) {
+		if name == "1" {
+			return nil, fmt.Errorf("server %s is an outlier", name)
+		}
+		return []byte(name), nil
+	}
+}
+
 // Registered function that should be used on server-side of some RPCs.
 var (
 	registeredFuncsMu     sync.Mutex
@@ -664,7 +726,7 @@ func TestSingleTCPServer(t *testing.T) {
 		{"TestClose", testClose},
 	}
 
-	protocols := []string{"tcp", "mtls"}
+	protocols := []string{"tcp", "mtls", "memory"}
 
 	ctx := context.Background()
 	opts := call.ServerOptions{Logger: logger(t)}
@@ -725,6 +787,316 @@ func TestTracePropagation(t *testing.T) {
 	}
 }
 
+// debugCallHandler is a slog.Handler that counts "debug call" records.
+type debugCallHandler struct {
+	n atomic.Int64
+}
+
+func (h *debugCallHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *debugCallHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *debugCallHandler) WithGroup(string) slog.Handler            { return h }
+func (h *debugCallHandler) Handle(_ context.Context, r slog.Record) error {
+	if r.Message == "debug call" {
+		h.n.Add(1)
+	}
+	return nil
+}
+
+// TestDebugPropagation tests that the debug flag is propagated across an RPC
+// and that only debug calls are logged and traced by the server.
+func TestDebugPropagation(t *testing.T) {
+	ct := startTest(t)
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("server listen failed: %v", err)
+	}
+	var h debugCallHandler
+	ct.fork(func() {
+		opts := call.ServerOptions{Logger: slog.New(&h), Tracer: traceio.TestTracer()}
+		err := call.Serve(ct.ctx, testListener{Listener: lis}, opts)
+		if err != ct.ctx.Err() {
+			t.Errorf("unexpected error from Serve: %v", err)
+		}
+	})
+	client := ct.connect(call.NewConstantResolver(call.TCP(lis.Addr().String())))
+
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprintf("debug=%t", debug), func(t *testing.T) {
+			ctx := context.Background()
+			if debug {
+				ctx = metadata.WithDebug(ctx)
+			}
+			before := h.n.Load()
+			_, err := runAtServer(ctx, client, call.CallOptions{}, func(ctx context.Context) ([]byte, error) {
+				if got := metadata.IsDebug(ctx); got != debug {
+					return nil, fmt.Errorf("IsDebug: got %t, want %t", got, debug)
+				}
+				// Debug calls are traced even though the caller isn't.
+				if got := trace.SpanFromContext(ctx).SpanContext().IsValid(); got != debug {
+					return nil, fmt.Errorf("traced: got %t, want %t", got, debug)
+				}
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := int64(0)
+			if debug {
+				want = 1
+			}
+			if got := h.n.Load() - before; got != want {
+				t.Fatalf("debug call logs: got %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+// TestCallerPropagation tests that the caller is propagated across an RPC.
+func TestCallerPropagation(t *testing.T) {
+	ct := startTest(t)
+	client := ct.connect(call.NewConstantResolver(ct.startTCPServer()))
+
+	for _, caller := range []string{"", "github.com/example/app/Frontend"} {
+		t.Run(fmt.Sprintf("caller=%q", caller), func(t *testing.T) {
+			ctx := context.Background()
+			if caller != "" {
+				ctx = call.WithCaller(ctx, caller)
+			}
+			_, err := runAtServer(ctx, client, call.CallOptions{}, func(ctx context.Context) ([]byte, error) {
+				if got := call.CallerFromContext(ctx); got != caller {
+					return nil, fmt.Errorf("CallerFromContext: got %q, want %q", got, caller)
+				}
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// hedgedComponent is the interface of the component called in
+// TestHedgedCallPriority.
+type hedgedComponent interface {
+	Get(context.Context) error
+}
+
+// TestHedgedCallPriority tests that hedged calls are sent at a lower priority
+// than the call they hedge, so that a busy server runs other calls first.
+func TestHedgedCallPriority(t *testing.T) {
+	ct := startTest(t)
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("server listen failed: %v", err)
+	}
+
+	// The first call blocks until unblocked, holding on to the server's only
+	// slot. The handler records the argument and priority of every call it
+	// runs.
+	var mu sync.Mutex
+	var calls []string
+	unblock := make(chan struct{})
+	hmap := call.NewHandlerMap()
+	hmap.Set("hedged", "Get", func(ctx context.Context, arg []byte) ([]byte, error) {
+		mu.Lock()
+		calls = append(calls, fmt.Sprintf("%s/%d", arg, metadata.Priority(ctx)))
+		first := len(calls) == 1
+		mu.Unlock()
+		if first {
+			<-unblock
+		}
+		return nil, nil
+	})
+	ct.fork(func() {
+		opts := call.ServerOptions{Logger: logger(t), MaxConcurrentCalls: 1}
+		err := call.Serve(ct.ctx, hmapListener{Listener: lis, hmap: hmap}, opts)
+		if err != ct.ctx.Err() {
+			t.Errorf("unexpected error from Serve: %v", err)
+		}
+	})
+	client := ct.connect(call.NewConstantResolver(call.TCP(lis.Addr().String())))
+	key := call.MakeMethodKey("hedged", "Get")
+	reg := &codegen.Registration{Name: "hedged", Iface: reflection.Type[hedgedComponent]()}
+	stub := call.NewStub("hedged", reg, client, traceio.TestTracer(), call.StubOptions{
+		Hedging: map[string]time.Duration{"Get": 10 * time.Millisecond},
+	})
+
+	// Occupy the server.
+	ctx := context.Background()
+	errs := make(chan error, 3)
+	go func() {
+		_, err := client.Call(ctx, key, []byte("a"), call.CallOptions{})
+		errs <- err
+	}()
+	waitUntil(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == 1
+	})
+
+	// Make a hedged call, and wait for the hedged call to be sent. Then, make
+	// another, unhedged call.
+	go func() {
+		_, err := codegen.Run(ctx, stub, &codegen.MethodCallHandle{}, 0, []byte("b"), 0)
+		errs <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	go func() {
+		_, err := client.Call(ctx, key, []byte("c"), call.CallOptions{})
+		errs <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// Even though the hedged call was queued before the unhedged call, the
+	// server runs the unhedged call first.
+	close(unblock)
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	//
+	// Note that the hedged call may be canceled before it runs, as the call
+	// it hedges has already returned.
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"a/0", "b/0", "c/0", "b/-1"}
+	if len(calls) < 3 || !cmp.Equal(calls, want[:len(calls)]) {
+		t.Fatalf("calls: got %v, want %v", calls, want)
+	}
+}
+
+// TestMaxPendingReplyBytes tests that calls succeed when their replies exceed
+// the server's budget of pending reply bytes.
+func TestMaxPendingReplyBytes(t *testing.T) {
+	ct := startTest(t)
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("server listen failed: %v", err)
+	}
+	hmap := call.NewHandlerMap()
+	hmap.Set("budget", "Get", func(_ context.Context, arg []byte) ([]byte, error) {
+		return bytes.Repeat(arg, 64<<10), nil
+	})
+	ct.fork(func() {
+		opts := call.ServerOptions{Logger: logger(t), MaxPendingReplyBytes: 1 << 10}
+		err := call.Serve(ct.ctx, hmapListener{Listener: lis, hmap: hmap}, opts)
+		if err != ct.ctx.Err() {
+			t.Errorf("unexpected error from Serve: %v", err)
+		}
+	})
+	client := ct.connect(call.NewConstantResolver(call.TCP(lis.Addr().String())))
+	key := call.MakeMethodKey("budget", "Get")
+
+	// Every reply is larger than the budget, so the server produces the
+	// replies one at a time.
+	const n = 20
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := client.Call(context.Background(), key, []byte("x"), call.CallOptions{})
+			if err == nil && len(result) != 64<<10 {
+				err = fmt.Errorf("got %d bytes, want %d", len(result), 64<<10)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestCompression tests that compressed requests and replies round trip
+// correctly for every codec, both above and below the compression threshold.
+func TestCompression(t *testing.T) {
+	ct := startTest(t)
+	client := ct.connect(call.NewConstantResolver(ct.startTCPServer()))
+
+	const threshold = 1 << 10
+	small := "hello"
+	large := strings.Repeat("hello, world! ", 10*threshold)
+	for _, codec := range []call.Compression{call.NoCompression, call.Gzip, call.Zstd} {
+		for _, arg := range []string{small, large} {
+			t.Run(fmt.Sprintf("%v/%d", codec, len(arg)), func(t *testing.T) {
+				opts := call.CallOptions{Compression: codec, CompressionThreshold: threshold}
+				result, err := client.Call(context.Background(), echoKey, []byte(arg), opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(result) != arg {
+					t.Fatalf("unexpected result: got %d bytes, want %d bytes", len(result), len(arg))
+				}
+			})
+		}
+	}
+}
+
+// TestCompressedRequestTooLarge tests that a server rejects a small compressed
+// request that decompresses past the request limit of its method.
+func TestCompressedRequestTooLarge(t *testing.T) {
+	ct := startTest(t)
+	client := ct.connect(call.NewConstantResolver(ct.startTCPServer()))
+
+	for _, codec := range []call.Compression{call.Gzip, call.Zstd} {
+		t.Run(codec.String(), func(t *testing.T) {
+			opts := call.CallOptions{Compression: codec}
+			ok := make([]byte, boundedMaxRequest)
+			if _, err := client.Call(context.Background(), boundedKey, ok, opts); err != nil {
+				t.Fatalf("request at the limit: %v", err)
+			}
+			bomb := make([]byte, 1<<20)
+			_, err := client.Call(context.Background(), boundedKey, bomb, opts)
+			if !errors.Is(err, codegen.PayloadTooLargeError) {
+				t.Fatalf("request over the limit: got %v, want %v", err, codegen.PayloadTooLargeError)
+			}
+		})
+	}
+}
+
+// TestReplyTooLarge tests that a client fails calls whose replies are over
+// their limit, and that the connection remains usable afterwards.
+func TestReplyTooLarge(t *testing.T) {
+	ct := startTest(t)
+	client := ct.connect(call.NewConstantResolver(ct.startTCPServer()))
+
+	for _, codec := range []call.Compression{call.NoCompression, call.Gzip, call.Zstd} {
+		t.Run(codec.String(), func(t *testing.T) {
+			ctx := context.Background()
+			opts := call.CallOptions{Compression: codec, MaxReplyBytes: 1 << 10}
+			arg := make([]byte, 1<<20)
+			rand.Read(arg)
+			if _, err := client.Call(ctx, echoKey, arg, opts); !errors.Is(err, codegen.PayloadTooLargeError) {
+				t.Fatalf("reply over the limit: got %v, want %v", err, codegen.PayloadTooLargeError)
+			}
+			got, err := client.Call(ctx, echoKey, arg[:1<<10], opts)
+			if err != nil {
+				t.Fatalf("reply at the limit: %v", err)
+			}
+			if !bytes.Equal(got, arg[:1<<10]) {
+				t.Fatalf("reply at the limit: got %d bytes, want the request", len(got))
+			}
+		})
+	}
+}
+
+// TestParseCompression tests that every codec can be parsed from its name.
+func TestParseCompression(t *testing.T) {
+	for _, want := range []call.Compression{call.NoCompression, call.Gzip, call.Zstd} {
+		got, err := call.ParseCompression(want.String())
+		if err != nil {
+			t.Fatalf("ParseCompression(%q): %v", want.String(), err)
+		}
+		if got != want {
+			t.Fatalf("ParseCompression(%q): got %v, want %v", want.String(), got, want)
+		}
+	}
+	if _, err := call.ParseCompression("lz4"); err == nil {
+		t.Fatal(`ParseCompression("lz4"): unexpected success`)
+	}
+}
+
 // TestMultipleEndpoints tests that RPC calls succeed when the resolver returns
 // a constant set of multiple endpoints.
 func TestMultipleEndpoints(t *testing.T) {
@@ -764,6 +1136,256 @@ func TestMultipleEndpoints(t *testing.T) {
 	}
 }
 
+// TestSessionAffinity tests that calls made in a session echo the session
+// token issued by a replica and are routed to that replica.
+func TestSessionAffinity(t *testing.T) {
+	ctx := context.Background()
+	options := call.ClientOptions{
+		Balancer: call.RoundRobin(),
+		Logger:   logger(t),
+	}
+	client, err := call.Connect(ctx, call.NewConstantResolver(servers(t, 3)...), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		ctx := metadata.WithSession(ctx)
+
+		// The first call doesn't have a token yet, and is issued one.
+		result, err := client.Call(ctx, sessionKey, []byte{}, call.CallOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		replica, token, _ := strings.Cut(string(result), "/")
+		if token != "" {
+			t.Fatalf("first call: got token %q, want none", token)
+		}
+
+		// Subsequent calls echo the token and hit the same replica.
+		want := replica + "/token-" + replica
+		for j := 0; j < 10; j++ {
+			result, err := client.Call(ctx, sessionKey, []byte{}, call.CallOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(result); got != want {
+				t.Fatalf("call %d: got %q, want %q", j, got, want)
+			}
+		}
+	}
+
+	// Calls made outside of a session are neither issued tokens nor pinned.
+	count := map[string]int{}
+	for i := 0; i < 30; i++ {
+		result, err := client.Call(ctx, sessionKey, []byte{}, call.CallOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		count[string(result)]++
+	}
+	if len(count) != 3 {
+		t.Fatalf("calls without a session: got %v, want calls to 3 replicas", count)
+	}
+}
+
+// TestOutlierDetection tests that a replica that consistently fails is ejected
+// and later re-admitted.
+func TestOutlierDetection(t *testing.T) {
+	ctx := context.Background()
+	const cooldown = 200 * time.Millisecond
+	options := call.ClientOptions{
+		Balancer: call.OutlierDetection(call.RoundRobin(), call.OutlierOptions{
+			ErrorRate: 0.5,
+			MinCalls:  5,
+			Cooldown:  cooldown,
+		}),
+		Logger: logger(t),
+	}
+	client, err := call.Connect(ctx, call.NewConstantResolver(servers(t, 3)...), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// calls makes n calls and returns the number of calls that failed.
+	calls := func(n int) int {
+		failures := 0
+		for i := 0; i < n; i++ {
+			result, err := client.Call(ctx, outlierKey, []byte{}, call.CallOptions{})
+			if err != nil {
+				failures++
+			} else if string(result) == "1" {
+				t.Fatalf("unexpected success from server 1")
+			}
+		}
+		return failures
+	}
+
+	// Server 1 fails every call, so it is ejected after at most MinCalls
+	// failures. All calls made after that succeed.
+	if got := calls(100); got > 5 {
+		t.Fatalf("failures before ejection: got %d, want <= 5", got)
+	}
+	if got := calls(30); got != 0 {
+		t.Fatalf("failures after ejection: got %d, want 0", got)
+	}
+
+	// After the cooldown, server 1 is re-admitted and fails calls again.
+	time.Sleep(cooldown + shortDelay)
+	if got := calls(30); got == 0 {
+		t.Fatalf("failures after re-admission: got 0, want > 0")
+	}
+}
+
+// TestRetryRejectedCalls tests that calls rejected by the server with a
+// retryable error are retried, even if retries are not allowed, but only a
+// bounded number of times.
+func TestRetryRejectedCalls(t *testing.T) {
+	ctx := context.Background()
+	client, err := call.Connect(ctx, call.NewConstantResolver(server(t, "0")), call.ClientOptions{Logger: logger(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	errRejected := errors.New("rejected")
+	var attempts atomic.Int32
+	result, err := runAtServer(ctx, client, call.CallOptions{}, func(context.Context) ([]byte, error) {
+		if attempts.Add(1) < 3 {
+			return nil, codegen.Retryable(errRejected)
+		}
+		return []byte("ok"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != "ok" || attempts.Load() != 3 {
+		t.Fatalf("got %q after %d attempts, want %q after 3", result, attempts.Load(), "ok")
+	}
+
+	// A call that is always rejected eventually fails.
+	attempts.Store(0)
+	_, err = runAtServer(ctx, client, call.CallOptions{}, func(context.Context) ([]byte, error) {
+		attempts.Add(1)
+		return nil, codegen.Retryable(errRejected)
+	})
+	if !errors.Is(err, errRejected) || !codegen.IsRetryable(err) {
+		t.Fatalf("got %v, want retryable %v", err, errRejected)
+	}
+	if got := attempts.Load(); got != 5 {
+		t.Fatalf("got %d attempts, want 5", got)
+	}
+}
+
+// TestRetrySpans tests that every retry of a call is traced as its own span,
+// linked to the span of the previous attempt.
+func TestRetrySpans(t *testing.T) {
+	ctx := context.Background()
+	client, err := call.Connect(ctx, call.NewConstantResolver(server(t, "0")), call.ClientOptions{Logger: logger(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, span := tracer.Start(ctx, "call")
+	defer span.End()
+
+	var attempts atomic.Int32
+	opts := call.CallOptions{Tracer: tracer, SpanName: "retry"}
+	if _, err := runAtServer(ctx, client, opts, func(context.Context) ([]byte, error) {
+		if attempts.Add(1) < 3 {
+			return nil, codegen.Retryable(errors.New("rejected"))
+		}
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first attempt is traced by the span in ctx, and the two retries by
+	// their own spans.
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d retry spans, want 2", len(spans))
+	}
+	prev := span.SpanContext()
+	for i, s := range spans {
+		if got, want := s.Parent().SpanID(), span.SpanContext().SpanID(); got != want {
+			t.Errorf("retry %d: got parent %v, want %v", i+1, got, want)
+		}
+		want := []attribute.KeyValue{attribute.Int("retry.attempt", i+1)}
+		if diff := cmp.Diff(want, s.Attributes(), cmp.Comparer(func(x, y attribute.Value) bool { return x == y })); diff != "" {
+			t.Errorf("retry %d: bad attributes (-want +got):\n%s", i+1, diff)
+		}
+		if links := s.Links(); len(links) != 1 || !links[0].SpanContext.Equal(prev) {
+			t.Errorf("retry %d: got links %v, want a link to %v", i+1, links, prev)
+		}
+		prev = s.SpanContext()
+	}
+}
+
+// TestServerCancellation tests that the context passed to a handler is
+// cancelled when the caller cancels the call, and that the call is counted as
+// cancelled even if the handler returns successfully.
+func TestServerCancellation(t *testing.T) {
+	ct := startTest(t)
+	client := ct.connect(call.NewConstantResolver(ct.startTCPServer()))
+
+	cancelled := func() float64 {
+		var n float64
+		for _, snap := range metrics.Snapshot() {
+			if snap.Name == imetrics.MethodCancellationsName && snap.Labels["method"] == "custom" {
+				n += snap.Value
+			}
+		}
+		return n
+	}
+	before := cancelled()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(shortDelay, cancel)
+	_, err := runAtServer(ctx, client, call.CallOptions{}, func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		return []byte("ok"), nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+	waitUntil(t, func() bool { return cancelled() == before+1 })
+}
+
+// TestOutlierDetectionLastReplica tests that the last replica is never
+// ejected, even if it consistently fails.
+func TestOutlierDetectionLastReplica(t *testing.T) {
+	ctx := context.Background()
+	options := call.ClientOptions{
+		Balancer: call.OutlierDetection(call.RoundRobin(), call.OutlierOptions{
+			ErrorRate:          0.5,
+			MinCalls:           5,
+			MaxEjectedFraction: 1,
+		}),
+		Logger: logger(t),
+	}
+	client, err := call.Connect(ctx, call.NewConstantResolver(server(t, "1")), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// If server 1 were ejected, calls would block waiting for a replica.
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(ctx, testTimeout)
+		_, err := client.Call(ctx, outlierKey, []byte{}, call.CallOptions{})
+		cancel()
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("call %d: got %v, want an application error", i, err)
+		}
+	}
+}
+
 // TestChangingEndpoints tests that RPC calls succeed across endpoint changes.
 func TestChangingEndpoints(t *testing.T) {
 	n := 3
@@ -1560,7 +2182,8 @@ func BenchmarkCall(b *testing.B) {
 	endpoints := startServers(ctx, opts)
 
 	for resolverName, maker := range resolverMakers {
-		for _, protocol := range []string{"tcp"} {
+		// Compare the default transport with a registered one.
+		for _, protocol := range []string{"tcp", "memory"} {
 			client := getClientConn(b, protocol, endpoints[protocol], maker)
 			ctx := context.Background()
 			for _, msgSize := range []int{1, 65536, 1048576} {
@@ -0,0 +1,48 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"strings"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+)
+
+var cancellations = metrics.NewCounterMap[cancelLabels](
+	imetrics.MethodCancellationsName,
+	"Count of Service Weaver remote component method calls that the caller stopped waiting for before they returned",
+)
+
+type cancelLabels struct {
+	Component string // full component name
+	Method    string // method name
+}
+
+// recordCancellation records that a call to the method with the provided
+// full name, e.g., "github.com/example/catalog/Catalog.ListProducts", was
+// abandoned by its caller before it returned. This happens when the caller
+// cancels the call, when the call's deadline expires, or when the connection
+// to the caller breaks. In all cases, the context passed to the method is
+// cancelled, so the method can abort its work.
+func recordCancellation(fullMethod string) {
+	var labels cancelLabels
+	if i := strings.LastIndex(fullMethod, "."); i >= 0 {
+		labels = cancelLabels{Component: fullMethod[:i], Method: fullMethod[i+1:]}
+	} else {
+		labels = cancelLabels{Method: fullMethod}
+	}
+	cancellations.Get(labels).Inc()
+}
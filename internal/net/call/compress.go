@@ -0,0 +1,198 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is a codec used to compress request and reply payloads.
+type Compression uint8
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+)
+
+// String implements the fmt.Stringer interface.
+func (c Compression) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case Gzip:
+		return "gzip"
+	case Zstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("Compression(%d)", c)
+	}
+}
+
+// ParseCompression parses the name of a compression codec: "none" (or the
+// empty string), "gzip", or "zstd".
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return NoCompression, nil
+	case "gzip":
+		return Gzip, nil
+	case "zstd":
+		return Zstd, nil
+	default:
+		return NoCompression, fmt.Errorf("unknown compression %q", name)
+	}
+}
+
+// defaultMaxDecompressedBytes bounds the size of a decompressed payload when
+// no limit is configured, so that a small compressed payload can't expand into
+// an arbitrarily large one.
+const defaultMaxDecompressedBytes = 64 << 20
+
+// minZstdDecoderMemory is the smallest amount of memory that a zstd decoder is
+// allowed to use. zstd decoders reject frames whose window is larger than
+// their memory, and even frames with a small payload may have a window of a
+// few kilobytes.
+const minZstdDecoderMemory = 64 << 10
+
+// zstd encoders and decoders are expensive to create, but EncodeAll and
+// DecodeAll can be called concurrently, so we share a single encoder, and a
+// single decoder for every limit on the size of decompressed payloads.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoders   sync.Map // int limit -> *zstd.Decoder
+)
+
+// zstdDecoder returns the shared zstd decoder whose decompressed payloads are
+// at most max(limit, minZstdDecoderMemory) bytes.
+func zstdDecoder(limit int) (*zstd.Decoder, error) {
+	limit = max(limit, minZstdDecoderMemory)
+	if d, ok := zstdDecoders.Load(limit); ok {
+		return d.(*zstd.Decoder), nil
+	}
+	d, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	actual, loaded := zstdDecoders.LoadOrStore(limit, d)
+	if loaded {
+		d.Close()
+	}
+	return actual.(*zstd.Decoder), nil
+}
+
+// compress compresses data using the provided codec.
+func compress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case NoCompression:
+		return data, nil
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown compression %v", c)
+	}
+}
+
+// decompress decompresses data compressed using the provided codec. If the
+// decompressed payload is larger than limit bytes, decompress stops and
+// returns an error that wraps codegen.PayloadTooLargeError. A non-positive
+// limit means defaultMaxDecompressedBytes.
+func decompress(c Compression, data []byte, limit int) ([]byte, error) {
+	if limit <= 0 {
+		limit = defaultMaxDecompressedBytes
+	}
+	tooLarge := func() error {
+		return fmt.Errorf("decompressed payload is over the limit of %d bytes: %w", limit, codegen.PayloadTooLargeError)
+	}
+	switch c {
+	case NoCompression:
+		return data, nil
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		// Read one byte past the limit to detect oversized payloads.
+		result, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(result) > limit {
+			return nil, tooLarge()
+		}
+		return result, nil
+	case Zstd:
+		d, err := zstdDecoder(limit)
+		if err != nil {
+			return nil, err
+		}
+		result, err := d.DecodeAll(data, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, tooLarge()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(result) > limit {
+			// The decoder's memory may be larger than limit.
+			return nil, tooLarge()
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %v", c)
+	}
+}
+
+// compressionHeader holds the compression information sent in the header of
+// a request.
+type compressionHeader struct {
+	request   Compression // codec used to compress the request payload
+	accept    Compression // codec the client accepts for the reply payload
+	threshold int         // replies of at least this many bytes are compressed
+}
+
+// writeCompression encodes the provided compression header.
+func writeCompression(h compressionHeader, enc *codegen.Encoder) {
+	enc.Uint8(uint8(h.request))
+	enc.Uint8(uint8(h.accept))
+	enc.Int(h.threshold)
+}
+
+// readCompression decodes a compression header encoded by writeCompression.
+func readCompression(dec *codegen.Decoder) compressionHeader {
+	return compressionHeader{
+		request:   Compression(dec.Uint8()),
+		accept:    Compression(dec.Uint8()),
+		threshold: dec.Int(),
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/klauspost/compress/zstd"
+)
+
+// bomb returns n zero bytes compressed with the provided codec. The zero bytes
+// are streamed to the compressor, so that they are never held in memory.
+func bomb(t *testing.T, c Compression, n int64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch c {
+	case Gzip:
+		w = gzip.NewWriter(&buf)
+	case Zstd:
+		var err error
+		if w, err = zstd.NewWriter(&buf); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unexpected codec %v", c)
+	}
+	zeros := make([]byte, 1<<20)
+	for n > 0 {
+		k := min(n, int64(len(zeros)))
+		if _, err := w.Write(zeros[:k]); err != nil {
+			t.Fatal(err)
+		}
+		n -= k
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestDecompressBomb tests that decompress rejects payloads that decompress
+// past their limit, including the default one, without decompressing them.
+func TestDecompressBomb(t *testing.T) {
+	for _, c := range []Compression{Gzip, Zstd} {
+		for _, test := range []struct {
+			size  int64 // decompressed size of the bomb
+			limit int   // limit passed to decompress
+		}{
+			{1 << 20, 1 << 10},
+			{2 * defaultMaxDecompressedBytes, 0},
+		} {
+			t.Run(fmt.Sprintf("%v/%d/%d", c, test.size, test.limit), func(t *testing.T) {
+				data := bomb(t, c, test.size)
+				if len(data) >= 1<<20 {
+					t.Fatalf("bomb is %d bytes, want a small compressed payload", len(data))
+				}
+				_, err := decompress(c, data, test.limit)
+				if !errors.Is(err, codegen.PayloadTooLargeError) {
+					t.Fatalf("decompress: got %v, want %v", err, codegen.PayloadTooLargeError)
+				}
+			})
+		}
+	}
+}
+
+// TestDecompressUnderLimit tests that decompress succeeds on payloads that
+// decompress to exactly their limit.
+func TestDecompressUnderLimit(t *testing.T) {
+	const limit = 1 << 10
+	for _, c := range []Compression{Gzip, Zstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			data, err := compress(c, make([]byte, limit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decompress(c, data, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, make([]byte, limit)) {
+				t.Fatalf("decompress: got %d bytes, want %d zero bytes", len(got), limit)
+			}
+		})
+	}
+}
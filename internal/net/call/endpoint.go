@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"net"
 	"strings"
+
+	"github.com/ServiceWeaver/weaver/runtime/transport"
 )
 
 // An endpoint is a dialable entity with an address. For example,
@@ -70,7 +72,9 @@ func MTLS(config *tls.Config, ep Endpoint) Endpoint {
 	return &tlsEndpoint{config: config, ep: ep}
 }
 
-// NetEndpoint is an Endpoint that implements Dial using net.Dial.
+// NetEndpoint is an Endpoint that implements Dial using the transport
+// registered for its network (see the runtime/transport package), or using
+// net.Dial if there is none.
 type NetEndpoint struct {
 	Net  string // e.g., "tcp", "udp", "unix"
 	Addr string // e.g., "localhost:8000", "/tmp/unix.sock"
@@ -81,6 +85,9 @@ var _ Endpoint = NetEndpoint{}
 
 // Dial implements the Endpoint interface.
 func (ne NetEndpoint) Dial(ctx context.Context) (net.Conn, error) {
+	if t, ok := transport.Lookup(ne.Net); ok {
+		return t.Dial(ctx, ne.Addr)
+	}
 	var dialer net.Dialer
 	return dialer.DialContext(ctx, ne.Net, ne.Addr)
 }
@@ -43,16 +43,18 @@ type Handler func(ctx context.Context, args []byte) ([]byte, error)
 // HandlerMap is a mapping from MethodID to a Handler. The zero value for a
 // HandlerMap is an empty map.
 type HandlerMap struct {
-	handlers map[MethodKey]Handler
-	names    map[MethodKey]string
+	handlers   map[MethodKey]Handler
+	names      map[MethodKey]string
+	maxRequest map[MethodKey]int // see SetMaxRequestBytes
 }
 
 // NewHandlerMap returns a handler map to which the server handlers can
 // be added.
 func NewHandlerMap() *HandlerMap {
 	return &HandlerMap{
-		handlers: map[MethodKey]Handler{},
-		names:    map[MethodKey]string{},
+		handlers:   map[MethodKey]Handler{},
+		names:      map[MethodKey]string{},
+		maxRequest: map[MethodKey]int{},
 	}
 }
 
@@ -63,6 +65,15 @@ func (hm *HandlerMap) Set(component, method string, handler Handler) {
 	hm.names[fp] = component + "." + method
 }
 
+// SetMaxRequestBytes bounds the size, in bytes, of the decompressed requests
+// of the specified method of component. Compressed requests that decompress
+// to more than n bytes are rejected, without being fully decompressed, with an
+// error that wraps codegen.PayloadTooLargeError. If n is not positive, or if
+// SetMaxRequestBytes is not called, a default limit is used.
+func (hm *HandlerMap) SetMaxRequestBytes(component, method string, n int) {
+	hm.maxRequest[MakeMethodKey(component, method)] = n
+}
+
 // AddHandlers adds handlers for all methods of the component with the
 // specified name. The handlers invoke methods on the specified impl.
 func (hm *HandlerMap) AddHandlers(name string, impl any) error {
@@ -74,7 +85,7 @@ func (hm *HandlerMap) AddHandlers(name string, impl any) error {
 	serverStub := reg.ServerStubFn(impl, addLoad)
 	for i, n := 0, reg.Iface.NumMethod(); i < n; i++ {
 		mname := reg.Iface.Method(i).Name
-		handler := serverStub.GetStubFn(mname)
+		handler := codegen.MethodHandler(reg, serverStub, i)
 		hm.Set(reg.Name, mname, handler)
 	}
 	return nil
@@ -0,0 +1,98 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/internal/session"
+	"github.com/ServiceWeaver/weaver/metadata"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// headerContext returns a context that sets every field of the call header.
+func headerContext(t *testing.T) context.Context {
+	t.Helper()
+	member, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := baggage.New(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ctx = baggage.ContextWithBaggage(ctx, b)
+	ctx = metadata.WithDebug(ctx)
+	ctx = session.WithJar(ctx)
+	ctx = metadata.WithPriority(ctx, 3)
+	ctx = WithCaller(ctx, "caller")
+	return ctx
+}
+
+func TestHeaderInitialVersion(t *testing.T) {
+	// A peer running the initial version of the protocol expects headers
+	// holding only the method key, deadline, trace context, and metadata.
+	ctx := headerContext(t)
+	key := MakeMethodKey("component", "method")
+	enc := codegen.NewEncoder()
+	copy(enc.Grow(len(key)), key[:])
+	enc.Int64(42)
+	writeTraceContext(ctx, enc)
+	writeContextMetadata(ctx, enc)
+	want := enc.Data()
+
+	got := encodeHeader(ctx, key, 42, compressionHeader{request: Gzip, accept: Gzip}, initialVersion)
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeHeader(initialVersion) = %v, want %v", got, want)
+	}
+}
+
+func TestHeaderVersions(t *testing.T) {
+	ctx := headerContext(t)
+	key := MakeMethodKey("component", "method")
+	comp := compressionHeader{request: Gzip, accept: Zstd, threshold: 10}
+	for v := initialVersion; v <= currentVersion; v++ {
+		hdr := encodeHeader(ctx, key, 42, comp, v)
+		got, hkey, micros, _, gotComp := decodeHeader(hdr, v)
+		if hkey != key {
+			t.Errorf("version %d: method key: got %v, want %v", v, hkey, key)
+		}
+		if micros != 42 {
+			t.Errorf("version %d: deadline: got %d, want 42", v, micros)
+		}
+		if got, want := baggage.FromContext(got).Len() != 0, v >= baggageVersion; got != want {
+			t.Errorf("version %d: baggage propagated: got %t, want %t", v, got, want)
+		}
+		if got, want := metadata.IsDebug(got), v >= debugVersion; got != want {
+			t.Errorf("version %d: debug flag propagated: got %t, want %t", v, got, want)
+		}
+		if got, want := gotComp == comp, v >= compressionVersion; got != want {
+			t.Errorf("version %d: compression propagated: got %t, want %t", v, got, want)
+		}
+		if _, got := session.ServerFromContext(got); got != (v >= sessionVersion) {
+			t.Errorf("version %d: session propagated: got %t, want %t", v, got, v >= sessionVersion)
+		}
+		if got, want := metadata.Priority(got) == 3, v >= priorityVersion; got != want {
+			t.Errorf("version %d: priority propagated: got %t, want %t", v, got, want)
+		}
+		if got, want := CallerFromContext(got) == "caller", v >= callerVersion; got != want {
+			t.Errorf("version %d: caller propagated: got %t, want %t", v, got, want)
+		}
+	}
+}
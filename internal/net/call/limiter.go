@@ -0,0 +1,217 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+
+	// Weight given to a new latency sample when updating the long-term
+	// latency average.
+	longRTTWeight = 0.01
+
+	// Weight given to a newly computed limit when updating the current limit.
+	limitSmoothing = 0.2
+
+	// The ratio between the short-term and long-term latencies that is
+	// tolerated before the limit starts shrinking.
+	rttTolerance = 1.5
+
+	// Factor by which the limit shrinks when a call fails with a
+	// communication error.
+	backoffRatio = 0.9
+)
+
+// LimiterOptions configure an adaptive concurrency limiter.
+type LimiterOptions struct {
+	// InitialLimit is the starting in-flight limit, clamped to the bounds
+	// below. Defaults to 20.
+	InitialLimit int
+
+	// MinLimit is the lower bound on the in-flight limit. Defaults to 1.
+	MinLimit int
+
+	// MaxLimit is the upper bound on the in-flight limit. Defaults to 1000.
+	MaxLimit int
+
+	// If non-nil, OnLimit is called with the in-flight limit when the
+	// limiter is created and every time the limit changes. It is called with
+	// the limiter's lock held, so it must not call the limiter.
+	OnLimit func(limit int)
+}
+
+// withDefaults returns a copy of the LimiterOptions with zero values replaced
+// with default values.
+func (o LimiterOptions) withDefaults() LimiterOptions {
+	if o.InitialLimit == 0 {
+		o.InitialLimit = defaultInitialLimit
+	}
+	if o.MinLimit == 0 {
+		o.MinLimit = defaultMinLimit
+	}
+	if o.MaxLimit == 0 {
+		o.MaxLimit = defaultMaxLimit
+	}
+	return o
+}
+
+// Limiter is an adaptive concurrency limiter. It bounds the number of
+// in-flight calls and adjusts the bound based on observed call latencies,
+// using a gradient algorithm similar to TCP Vegas: the limit grows while
+// latency stays close to its long-term average and shrinks when latency
+// rises, which is a sign that requests are queueing up at the server.
+//
+// A Limiter is safe for concurrent use.
+type Limiter struct {
+	opts LimiterOptions
+
+	mu       sync.Mutex
+	limit    float64         // current in-flight limit
+	inflight int             // number of calls currently holding a slot
+	longRTT  float64         // long-term average latency, in nanoseconds
+	waiters  []chan struct{} // calls waiting for a slot, in FIFO order
+}
+
+// NewLimiter returns a new adaptive concurrency limiter.
+func NewLimiter(opts LimiterOptions) *Limiter {
+	opts = opts.withDefaults()
+	initial := math.Max(float64(opts.MinLimit), math.Min(float64(opts.MaxLimit), float64(opts.InitialLimit)))
+	l := &Limiter{opts: opts, limit: initial}
+	if opts.OnLimit != nil {
+		opts.OnLimit(int(initial))
+	}
+	return l
+}
+
+// Limit returns the current in-flight limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Acquire blocks until a slot is available or the context is done. On
+// success, the caller must call Release exactly once when the call finishes.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inflight < int(l.limit) && len(l.waiters) == 0 {
+		l.inflight++
+		l.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, w := range l.waiters {
+			if w == ch {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// We were granted a slot concurrently with the context being
+		// canceled. Give the slot back.
+		l.inflight--
+		l.wakeLocked()
+		return ctx.Err()
+	}
+}
+
+// Release releases a slot acquired by Acquire. rtt is the observed latency of
+// the call and err is the error it returned, if any. Only successful calls
+// contribute latency samples; calls that fail with a communication error
+// shrink the limit.
+func (l *Limiter) Release(rtt time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	switch {
+	case err == nil:
+		l.sampleLocked(float64(rtt))
+	case errors.Is(err, CommunicationError):
+		l.setLimitLocked(l.limit * backoffRatio)
+	}
+	l.wakeLocked()
+}
+
+// sampleLocked updates the limit with a new latency sample.
+//
+// REQUIRES: l.mu is held.
+func (l *Limiter) sampleLocked(rtt float64) {
+	if rtt <= 0 {
+		return
+	}
+	if l.longRTT == 0 {
+		l.longRTT = rtt
+	} else {
+		l.longRTT = l.longRTT*(1-longRTTWeight) + rtt*longRTTWeight
+	}
+
+	// If the long-term average has drifted well above the latest sample
+	// (e.g., after a period of congestion), pull it down faster so that the
+	// limit can recover.
+	if l.longRTT/rtt > 2 {
+		l.longRTT *= 0.95
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, rttTolerance*l.longRTT/rtt))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	newLimit = l.limit*(1-limitSmoothing) + newLimit*limitSmoothing
+
+	// Don't grow the limit if we're not using it. Otherwise, an idle client
+	// would end up with an arbitrarily large limit.
+	if newLimit > l.limit && float64(l.inflight+1) < l.limit/2 {
+		return
+	}
+	l.setLimitLocked(newLimit)
+}
+
+// setLimitLocked sets the limit, clamped to the configured bounds.
+//
+// REQUIRES: l.mu is held.
+func (l *Limiter) setLimitLocked(limit float64) {
+	old := int(l.limit)
+	l.limit = math.Max(float64(l.opts.MinLimit), math.Min(float64(l.opts.MaxLimit), limit))
+	if l.opts.OnLimit != nil && int(l.limit) != old {
+		l.opts.OnLimit(int(l.limit))
+	}
+}
+
+// wakeLocked hands out free slots to waiting callers.
+//
+// REQUIRES: l.mu is held.
+func (l *Limiter) wakeLocked() {
+	for len(l.waiters) > 0 && l.inflight < int(l.limit) {
+		ch := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inflight++
+		close(ch)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// saturate acquires every available slot of l and then releases them all,
+// reporting the provided latency for each call.
+func saturate(t *testing.T, l *Limiter, rtt time.Duration) {
+	t.Helper()
+	n := l.Limit()
+	for i := 0; i < n; i++ {
+		if err := l.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		l.Release(rtt, nil)
+	}
+}
+
+func TestLimiterGrowsWhenLatencyIsStable(t *testing.T) {
+	l := NewLimiter(LimiterOptions{InitialLimit: 10, MaxLimit: 100})
+	for i := 0; i < 20; i++ {
+		saturate(t, l, 10*time.Millisecond)
+	}
+	if got := l.Limit(); got <= 10 {
+		t.Fatalf("Limit: got %d, want > 10", got)
+	}
+	for i := 0; i < 100; i++ {
+		saturate(t, l, 10*time.Millisecond)
+	}
+	if got, want := l.Limit(), 100; got != want {
+		t.Fatalf("Limit: got %d, want %d", got, want)
+	}
+}
+
+func TestLimiterShrinksWhenLatencyRises(t *testing.T) {
+	l := NewLimiter(LimiterOptions{InitialLimit: 50, MaxLimit: 100})
+	for i := 0; i < 10; i++ {
+		saturate(t, l, 10*time.Millisecond)
+	}
+	before := l.Limit()
+
+	// Simulate the server getting overloaded.
+	for i := 0; i < 3; i++ {
+		saturate(t, l, 100*time.Millisecond)
+	}
+	after := l.Limit()
+	if after >= before/2 {
+		t.Fatalf("Limit: got %d, want < %d", after, before/2)
+	}
+
+	// Simulate the server recovering.
+	for i := 0; i < 50; i++ {
+		saturate(t, l, 10*time.Millisecond)
+	}
+	if got := l.Limit(); got <= after {
+		t.Fatalf("Limit: got %d, want > %d", got, after)
+	}
+}
+
+func TestLimiterIdleDoesNotGrow(t *testing.T) {
+	l := NewLimiter(LimiterOptions{InitialLimit: 10})
+	for i := 0; i < 100; i++ {
+		if err := l.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		l.Release(time.Millisecond, nil)
+	}
+	if got, want := l.Limit(), 10; got != want {
+		t.Fatalf("Limit: got %d, want %d", got, want)
+	}
+}
+
+func TestLimiterBacksOffOnCommunicationError(t *testing.T) {
+	l := NewLimiter(LimiterOptions{InitialLimit: 10})
+	for i := 0; i < 10; i++ {
+		if err := l.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		l.Release(time.Millisecond, CommunicationError)
+	}
+	if got := l.Limit(); got >= 10 {
+		t.Fatalf("Limit: got %d, want < 10", got)
+	}
+
+	// Application errors don't affect the limit.
+	before := l.Limit()
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.Release(time.Millisecond, errors.New("application error"))
+	if got := l.Limit(); got != before {
+		t.Fatalf("Limit: got %d, want %d", got, before)
+	}
+}
+
+func TestLimiterBlocks(t *testing.T) {
+	l := NewLimiter(LimiterOptions{InitialLimit: 1})
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second call blocks until the context is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire: got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A second call blocks until the first one is released.
+	acquired := make(chan error)
+	go func() { acquired <- l.Acquire(context.Background()) }()
+	select {
+	case <-acquired:
+		t.Fatal("Acquire unexpectedly succeeded")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l.Release(time.Millisecond, nil)
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimiterOnLimit(t *testing.T) {
+	var limits []int
+	l := NewLimiter(LimiterOptions{
+		InitialLimit: 10,
+		MaxLimit:     100,
+		OnLimit:      func(limit int) { limits = append(limits, limit) },
+	})
+	for i := 0; i < 20; i++ {
+		saturate(t, l, 10*time.Millisecond)
+	}
+	if len(limits) < 2 || limits[0] != 10 {
+		t.Fatalf("OnLimit: got %v, want the initial limit 10 followed by changes", limits)
+	}
+	if got, want := limits[len(limits)-1], l.Limit(); got != want {
+		t.Errorf("OnLimit: last limit %d, want the current limit %d", got, want)
+	}
+	for i := 1; i < len(limits); i++ {
+		if limits[i] == limits[i-1] {
+			t.Errorf("OnLimit: called twice with unchanged limit %d", limits[i])
+		}
+	}
+}
@@ -17,6 +17,7 @@ package call
 import (
 	"context"
 
+	"github.com/ServiceWeaver/weaver/internal/session"
 	"github.com/ServiceWeaver/weaver/metadata"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 )
@@ -52,3 +53,83 @@ func readContextMetadata(ctx context.Context, dec *codegen.Decoder) context.Cont
 	}
 	return metadata.NewContext(ctx, res)
 }
+
+// writeDebug serializes the debug flag of the context into enc.
+func writeDebug(ctx context.Context, enc *codegen.Encoder) {
+	enc.Bool(metadata.IsDebug(ctx))
+}
+
+// readDebug returns ctx, marked for debugging if the debug flag stored in dec
+// is set.
+func readDebug(ctx context.Context, dec *codegen.Decoder) context.Context {
+	if dec.Bool() {
+		return metadata.WithDebug(ctx)
+	}
+	return ctx
+}
+
+// writePriority serializes the priority of the context into enc.
+func writePriority(ctx context.Context, enc *codegen.Encoder) {
+	enc.Int64(int64(metadata.Priority(ctx)))
+}
+
+// readPriority returns ctx with the priority stored in dec, if it isn't the
+// default priority.
+func readPriority(ctx context.Context, dec *codegen.Decoder) context.Context {
+	if priority := int(dec.Int64()); priority != 0 {
+		return metadata.WithPriority(ctx, priority)
+	}
+	return ctx
+}
+
+// writeSession serializes the session token of the context (if any) into enc.
+func writeSession(ctx context.Context, enc *codegen.Encoder) {
+	jar, ok := session.JarFromContext(ctx)
+	if !ok {
+		enc.Bool(false)
+		return
+	}
+	enc.Bool(true)
+	token, _ := jar.Get()
+	enc.String(token)
+}
+
+// readSession returns ctx with the server session state of the call, if the
+// client stored in dec has a session.
+func readSession(ctx context.Context, dec *codegen.Decoder) context.Context {
+	if !dec.Bool() {
+		return ctx
+	}
+	ctx, _ = session.WithServer(ctx, dec.String())
+	return ctx
+}
+
+// callerKey is the context key for the name of the component making a call.
+type callerKey struct{}
+
+// WithCaller returns a context that records that calls made with it are made
+// by the component with the provided full name. The name is sent to the
+// server in the call header, where it is returned by CallerFromContext.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the full name of the component that made the call
+// with the provided context, or "" if the caller is unknown.
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// writeCaller serializes the caller of the context (if any) into enc.
+func writeCaller(ctx context.Context, enc *codegen.Encoder) {
+	enc.String(CallerFromContext(ctx))
+}
+
+// readCaller returns ctx with the caller stored in dec, if any.
+func readCaller(ctx context.Context, dec *codegen.Decoder) context.Context {
+	if caller := dec.String(); caller != "" {
+		return WithCaller(ctx, caller)
+	}
+	return ctx
+}
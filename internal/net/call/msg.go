@@ -31,6 +31,8 @@ const (
 	responseMessage
 	responseError
 	cancelMessage
+	compressedResponseMessage
+	sessionMessage
 	// Other types to add?
 	// - chunked request/response messages?
 	// - health check
@@ -40,11 +42,21 @@ const (
 // version holds the protocol version number.
 type version uint32
 
+// Every version adds to the format of the messages sent by the previous
+// version. A peer only sends the additions of the version negotiated for the
+// connection, i.e., the minimum of the two peers' versions.
 const (
-	initialVersion version = iota
+	initialVersion     version = iota
+	baggageVersion             // request headers carry OpenTelemetry baggage
+	debugVersion               // request headers carry the debug flag
+	compressionVersion         // requests and replies may be compressed
+	sessionVersion             // request headers carry the session token
+	queueVersion               // responses carry the time the request was queued
+	priorityVersion            // request headers carry the call priority
+	callerVersion              // request headers carry the calling component
 )
 
-const currentVersion = initialVersion
+const currentVersion = callerVersion
 
 const hdrLenLen = uint32(4) // size of the header length included in each message
 
@@ -74,16 +86,40 @@ const hdrLenLen = uint32(4) // size of the header length included in each messag
 //   Deadline        int64
 //   TraceContext    [25]byte
 //   MetadataContext map[string]string
+//   Baggage         string -- since baggageVersion
+//   Debug           bool   -- since debugVersion
+//   Priority        int64  -- since priorityVersion
+//   Compression     uint8  -- codec of the request payload, since compressionVersion
+//   Accept          uint8  -- codec the client accepts for the reply, since compressionVersion
+//   Threshold       int    -- minimum size of a compressed reply, since compressionVersion
+//   Session         bool   -- whether the client has a session, since sessionVersion
+//   SessionToken    string -- the client's session token, if Session is set
+//   Caller          string -- full name of the calling component, or "",
+//                             since callerVersion
 // }
 //
 // responseMessage:
-//    payload holds call result serialization
+//    queue     [8]byte -- microseconds the request waited before its handler
+//                         ran, since queueVersion
+//    payload           -- call result serialization
 //
 // responseError:
-//    payload holds error serialization
+//    queue     [8]byte -- as in responseMessage
+//    payload           -- error serialization
 //
 // cancelMessage:
 //    payload is empty
+//
+// compressedResponseMessage: only sent if the client accepts compressed
+// replies, which requires compressionVersion.
+//    queue     [8]byte -- as in responseMessage
+//    codec     [1]byte -- Compression used to compress the result
+//    payload           -- compressed call result serialization
+//
+// sessionMessage: sent before the response to a call whose handler issued a
+// session token. Handlers can only issue tokens to clients that sent a
+// session in the header, which requires sessionVersion.
+//    payload holds the session token
 
 // writeMessage formats and sends a message over w.
 //
@@ -129,11 +165,21 @@ func writeChunked(w io.Writer, wlock *sync.Mutex, mt messageType, id uint64, ext
 	return err
 }
 
+// flatPool is a pool of buffers used by writeFlat. Messages written by
+// writeFlat are no larger than the flatten limit, so pooled buffers stay
+// small.
+var flatPool = sync.Pool{New: func() any { return new([]byte) }}
+
 // writeFlat concatenates the header, extra header, and the payload into
 // a single flat byte slice, and writes it into w using a single w.Write() call.
 func writeFlat(w io.Writer, wlock *sync.Mutex, mt messageType, id uint64, extraHdr []byte, payload []byte) error {
 	nh, np := len(extraHdr), len(payload)
-	data := make([]byte, 16+nh+np)
+	bufp := flatPool.Get().(*[]byte)
+	defer flatPool.Put(bufp)
+	if cap(*bufp) < 16+nh+np {
+		*bufp = make([]byte, 16+nh+np)
+	}
+	data := (*bufp)[:16+nh+np]
 	binary.LittleEndian.PutUint64(data[0:], id)
 	val := uint64(mt) | (uint64(nh+np) << 8)
 	binary.LittleEndian.PutUint64(data[8:], val)
@@ -155,11 +201,27 @@ func writeFlat(w io.Writer, wlock *sync.Mutex, mt messageType, id uint64, extraH
 
 // readMessage reads, parses, and returns the next message from r.
 func readMessage(r io.Reader) (messageType, uint64, []byte, error) {
-	// Read the header.
+	mt, id, dataLen, err := readHeader(r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// Read the payload.
+	msg := make([]byte, dataLen)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return 0, 0, nil, err
+	}
+	return mt, id, msg, nil
+}
+
+// readHeader reads and parses the header of the next message from r. It
+// returns the type, ID, and payload length of the message, whose payload must
+// be read next.
+func readHeader(r io.Reader) (messageType, uint64, int, error) {
 	const headerSize = 16
 	var hdr [headerSize]byte
 	if _, err := io.ReadFull(r, hdr[:]); err != nil {
-		return 0, 0, nil, err
+		return 0, 0, 0, err
 	}
 
 	// Extract header contents (see writeMessage for header format).
@@ -169,15 +231,9 @@ func readMessage(r io.Reader) (messageType, uint64, []byte, error) {
 	dataLen := w2 >> 8
 	const maxSize = 100 << 20
 	if dataLen > maxSize {
-		return 0, 0, nil, fmt.Errorf("overly large message length %d", dataLen)
-	}
-
-	// Read the payload.
-	msg := make([]byte, int(dataLen))
-	if _, err := io.ReadFull(r, msg); err != nil {
-		return 0, 0, nil, err
+		return 0, 0, 0, fmt.Errorf("overly large message length %d", dataLen)
 	}
-	return mt, id, msg, nil
+	return mt, id, int(dataLen), nil
 }
 
 // writeVersion sends my version number to the peer.
@@ -65,6 +65,61 @@ type ServerOptions struct {
 	// buffer before being written on the connection. If zero, an appropriate
 	// value is picked automatically. If negative, no flattening is done.
 	WriteFlattenLimit int
+
+	// If positive, at most this many handlers run at once. Other calls wait
+	// until a handler returns and are then run in priority order (see
+	// metadata.WithPriority).
+	MaxConcurrentCalls int
+
+	// If positive, bounds the number of bytes of replies that a connection
+	// has produced but not yet written to the client. Once a slow client lets
+	// this many bytes pile up, the connection stops running handlers until
+	// the client catches up.
+	MaxPendingReplyBytes int
+}
+
+// StubOptions are the options to configure a client stub.
+type StubOptions struct {
+	// Number of artificial retries to inject per retriable call. Used for
+	// testing.
+	InjectRetries int
+
+	// Faults to inject, by method name. Before a method is called, its fault
+	// function (if any) is invoked. If it returns a non-nil error, the call
+	// fails with that error without being sent. Used for testing.
+	Faults map[string]func() error
+
+	// If non-nil, the stub bounds the number of in-flight calls using this
+	// adaptive concurrency limiter.
+	Limiter *Limiter
+
+	// If non-nil, the stub logs a sample of its calls to this logger.
+	AccessLogger *slog.Logger
+
+	// Fraction of calls, between 0 and 1, that are logged to AccessLogger.
+	AccessLogRate float64
+
+	// Codec used to compress request and reply payloads of at least
+	// CompressionThreshold bytes. Defaults to NoCompression.
+	Compression          Compression
+	CompressionThreshold int
+
+	// Hedging delays, by method name. If a call to a method in Hedging hasn't
+	// returned after the method's delay, the client stub sends a second,
+	// identical call and uses whichever reply arrives first.
+	Hedging map[string]time.Duration
+
+	// Cache TTLs, by method name. The replies of successful calls to a method
+	// in Caching are cached, keyed by the call's serialized arguments, for the
+	// method's TTL. Calls whose context was marked by codegen.BypassCache
+	// skip the cache lookup, but still cache their reply.
+	Caching map[string]time.Duration
+
+	// If positive, the maximum size, in bytes, of a serialized request or
+	// reply. Larger requests are not sent, and larger replies are dropped,
+	// with an error that wraps codegen.PayloadTooLargeError.
+	MaxRequestBytes int
+	MaxReplyBytes   int
 }
 
 // CallOptions are call-specific options.
@@ -79,6 +134,29 @@ type CallOptions struct {
 	// TODO(mwhittaker): Figure out a way to have 0 be a valid shard key. Could
 	// change to *uint64 for example.
 	ShardKey uint64
+
+	// Compression, if not NoCompression, is the codec used to compress the
+	// request and reply payloads of at least CompressionThreshold bytes. The
+	// server compresses the reply only if the client asks it to.
+	Compression          Compression
+	CompressionThreshold int
+
+	// If positive, the maximum size, in bytes, of the decompressed reply of
+	// the call. A larger reply is discarded as it is read from the
+	// connection, and a compressed reply that decompresses to more bytes is
+	// dropped without being fully decompressed. Either way, the call fails
+	// with an error that wraps codegen.PayloadTooLargeError. If not positive,
+	// a default limit is used for decompressed replies.
+	MaxReplyBytes int
+
+	// If non-nil, Call stores in Queue how long the call waited at the server
+	// between being received and its handler running.
+	Queue *time.Duration
+
+	// Tracer, if not nil, traces every retry of the call as a span named
+	// SpanName, linked to the span of the previous attempt.
+	Tracer   trace.Tracer
+	SpanName string
 }
 
 // withDefaults returns a copy of the ClientOptions with zero values replaced
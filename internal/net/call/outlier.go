@@ -0,0 +1,174 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import "time"
+
+const (
+	defaultOutlierErrorRate          = 0.5
+	defaultOutlierMinCalls           = 10
+	defaultOutlierCooldown           = 30 * time.Second
+	defaultOutlierMaxEjectedFraction = 0.5
+)
+
+// OutlierOptions configures outlier detection. See OutlierDetection.
+type OutlierOptions struct {
+	// Fraction of failed calls, between 0 and 1, at or above which a replica
+	// is ejected. Defaults to 0.5.
+	ErrorRate float64
+
+	// Number of calls to a replica over which its error rate is computed.
+	// Defaults to 10.
+	MinCalls int
+
+	// Duration for which an ejected replica is ejected before it is
+	// re-admitted. Defaults to 30 seconds.
+	Cooldown time.Duration
+
+	// Maximum fraction of replicas, between 0 and 1, that can be ejected at
+	// the same time. Defaults to 0.5. Regardless of this value, the last
+	// remaining replica is never ejected.
+	MaxEjectedFraction float64
+}
+
+// withDefaults returns a copy of the OutlierOptions with zero values replaced
+// with default values.
+func (o OutlierOptions) withDefaults() OutlierOptions {
+	if o.ErrorRate == 0 {
+		o.ErrorRate = defaultOutlierErrorRate
+	}
+	if o.MinCalls == 0 {
+		o.MinCalls = defaultOutlierMinCalls
+	}
+	if o.Cooldown == 0 {
+		o.Cooldown = defaultOutlierCooldown
+	}
+	if o.MaxEjectedFraction == 0 {
+		o.MaxEjectedFraction = defaultOutlierMaxEjectedFraction
+	}
+	return o
+}
+
+// callObserver is an optional interface implemented by a Balancer that wants
+// to learn the outcome of the calls made on the ReplicaConnections it picks.
+// Like the other Balancer methods, observe requires external synchronization.
+type callObserver interface {
+	// observe is called when a call made on c completes with error err.
+	observe(c ReplicaConnection, err error)
+}
+
+// outlierDetector is the Balancer returned by OutlierDetection.
+type outlierDetector struct {
+	balancer Balancer
+	opts     OutlierOptions
+	stats    map[ReplicaConnection]*replicaStats // admitted replicas
+	ejected  map[ReplicaConnection]time.Time     // ejected replicas, with re-admission time
+}
+
+// replicaStats are the call statistics of an admitted replica since its error
+// rate was last computed.
+type replicaStats struct {
+	calls    int
+	failures int
+}
+
+var (
+	_ Balancer     = &outlierDetector{}
+	_ callObserver = &outlierDetector{}
+)
+
+// OutlierDetection returns a Balancer that performs outlier detection on top
+// of the provided balancer. It tracks the error rate of every replica, and a
+// replica whose error rate over its last MinCalls calls is at least ErrorRate
+// is ejected: it is removed from the provided balancer for Cooldown, after
+// which it is re-admitted. Calls that fail because their context is canceled
+// or times out are not counted.
+func OutlierDetection(balancer Balancer, opts OutlierOptions) Balancer {
+	return &outlierDetector{
+		balancer: balancer,
+		opts:     opts.withDefaults(),
+		stats:    map[ReplicaConnection]*replicaStats{},
+		ejected:  map[ReplicaConnection]time.Time{},
+	}
+}
+
+// Add implements the Balancer interface.
+func (o *outlierDetector) Add(c ReplicaConnection) {
+	o.stats[c] = &replicaStats{}
+	o.balancer.Add(c)
+}
+
+// Remove implements the Balancer interface.
+func (o *outlierDetector) Remove(c ReplicaConnection) {
+	if _, ok := o.ejected[c]; ok {
+		// An ejected replica has already been removed from o.balancer.
+		delete(o.ejected, c)
+		return
+	}
+	delete(o.stats, c)
+	o.balancer.Remove(c)
+}
+
+// Pick implements the Balancer interface.
+func (o *outlierDetector) Pick(opts CallOptions) (ReplicaConnection, bool) {
+	o.readmit()
+	return o.balancer.Pick(opts)
+}
+
+// readmit re-admits the ejected replicas whose cooldown has expired.
+func (o *outlierDetector) readmit() {
+	if len(o.ejected) == 0 {
+		return
+	}
+	now := time.Now()
+	for c, until := range o.ejected {
+		if now.Before(until) {
+			continue
+		}
+		delete(o.ejected, c)
+		o.stats[c] = &replicaStats{}
+		o.balancer.Add(c)
+	}
+}
+
+// observe implements the callObserver interface.
+func (o *outlierDetector) observe(c ReplicaConnection, err error) {
+	s, ok := o.stats[c]
+	if !ok {
+		// c has been removed or ejected.
+		return
+	}
+	s.calls++
+	if err != nil {
+		s.failures++
+	}
+	if s.calls < o.opts.MinCalls {
+		return
+	}
+	rate := float64(s.failures) / float64(s.calls)
+	*s = replicaStats{}
+	if rate < o.opts.ErrorRate {
+		return
+	}
+
+	// Eject c, unless doing so would eject too many replicas.
+	total := len(o.stats) + len(o.ejected)
+	if len(o.stats) <= 1 || float64(len(o.ejected)+1) > o.opts.MaxEjectedFraction*float64(total) {
+		return
+	}
+	delete(o.stats, c)
+	o.balancer.Remove(c)
+	o.ejected[c] = time.Now().Add(o.opts.Cooldown)
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver/internal/cond"
+)
+
+// replyBudget bounds the number of bytes of replies that a server connection
+// has produced but not yet written to the client. When a client reads its
+// replies slowly, the replies pile up waiting to be written. Once they exceed
+// the budget, the connection stops running handlers, and hence stops producing
+// more replies, until the client drains them.
+//
+// A replyBudget is safe for concurrent use.
+type replyBudget struct {
+	limit int // maximum number of pending reply bytes
+
+	mu      sync.Mutex
+	changed cond.Cond // signalled when pending decreases
+	pending int       // number of reply bytes produced but not yet written
+}
+
+// newReplyBudget returns a new replyBudget that allows up to limit bytes of
+// pending replies.
+func newReplyBudget(limit int) *replyBudget {
+	b := &replyBudget{limit: limit}
+	b.changed.L = &b.mu
+	return b
+}
+
+// wait blocks until the pending replies are under the budget or the context is
+// done. Note that a single reply may exceed the budget; wait only prevents
+// more replies from being produced while it is written.
+func (b *replyBudget) wait(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.pending >= b.limit {
+		if err := b.changed.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add records that a reply of n bytes is waiting to be written. The caller
+// must call done(n) once the reply is written.
+func (b *replyBudget) add(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending += n
+}
+
+// done records that a reply of n bytes, previously passed to add, has been
+// written.
+func (b *replyBudget) done(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending -= n
+	b.changed.Broadcast()
+}
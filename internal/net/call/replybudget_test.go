@@ -0,0 +1,63 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReplyBudget(t *testing.T) {
+	b := newReplyBudget(10)
+	ctx := context.Background()
+
+	// Replies can be produced while the pending replies are under budget,
+	// even if a reply then exceeds the budget.
+	b.add(4)
+	if err := b.wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	b.add(20)
+
+	// Once over budget, wait blocks until the pending replies are written.
+	done := make(chan error)
+	go func() { done <- b.wait(ctx) }()
+	select {
+	case err := <-done:
+		t.Fatalf("wait returned %v over budget", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	b.done(4)
+	select {
+	case err := <-done:
+		t.Fatalf("wait returned %v over budget", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	b.done(20)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplyBudgetCancel(t *testing.T) {
+	b := newReplyBudget(1)
+	b.add(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("wait: got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -16,7 +16,11 @@ package call
 
 import (
 	"context"
+	"log/slog"
+	"math/rand"
+	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -27,23 +31,46 @@ type stub struct {
 	methods       []stubMethod // per method info
 	tracer        trace.Tracer // component tracer
 	injectRetries int          // Number of artificial retries per retriable call
+	limiter       *Limiter     // if not nil, limits the number of in-flight calls
+	accessLogger  *slog.Logger // if not nil, logs a sample of calls
+	accessLogRate float64      // fraction of calls logged to accessLogger
+	compression   Compression  // codec used to compress large payloads
+	threshold     int          // payloads of at least this size are compressed
+	maxRequest    int          // if positive, maximum request size in bytes
+	maxReply      int          // if positive, maximum reply size in bytes
 }
 
 type stubMethod struct {
-	key   MethodKey // key for remote component method
-	retry bool      // Whether or not the method should be retred
+	name  string        // name of the remote component method
+	span  string        // name of the spans that trace retries of calls
+	key   MethodKey     // key for remote component method
+	retry bool          // Whether or not the method should be retred
+	fault func() error  // if not nil, returns the error to inject, if any
+	hedge time.Duration // if positive, delay before hedging a call
+	cache *replyCache   // if not nil, caches the replies of calls
 }
 
 var _ codegen.Stub = &stub{}
+var _ codegen.AccessLogger = &stub{}
+var _ codegen.Hedger = &stub{}
+var _ codegen.Queuer = &stub{}
+var _ codegen.Cacher = &stub{}
 
 // NewStub creates a client-side stub of the type matching reg. Calls on the stub are sent on
 // conn to the component with the specified name.
-func NewStub(name string, reg *codegen.Registration, conn Connection, tracer trace.Tracer, injectRetries int) codegen.Stub {
+func NewStub(name string, reg *codegen.Registration, conn Connection, tracer trace.Tracer, opts StubOptions) codegen.Stub {
 	return &stub{
 		conn:          conn,
-		methods:       makeStubMethods(name, reg),
+		methods:       makeStubMethods(name, reg, opts.Faults, opts.Hedging, opts.Caching),
 		tracer:        tracer,
-		injectRetries: injectRetries,
+		injectRetries: opts.InjectRetries,
+		limiter:       opts.Limiter,
+		accessLogger:  opts.AccessLogger,
+		accessLogRate: opts.AccessLogRate,
+		compression:   opts.Compression,
+		threshold:     opts.CompressionThreshold,
+		maxRequest:    opts.MaxRequestBytes,
+		maxReply:      opts.MaxReplyBytes,
 	}
 }
 
@@ -53,11 +80,47 @@ func (s *stub) Tracer() trace.Tracer {
 }
 
 // Run implements the codegen.Stub interface.
-func (s *stub) Run(ctx context.Context, method int, args []byte, shardKey uint64) (result []byte, err error) {
+func (s *stub) Run(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, error) {
+	result, _, err := s.RunQueued(ctx, method, args, shardKey)
+	return result, err
+}
+
+// RunQueued implements the codegen.Queuer interface. A call is queued while it
+// waits for the concurrency limiter, if any, and at the server.
+func (s *stub) RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) (result []byte, queue time.Duration, err error) {
 	m := s.methods[method]
+	if m.cache != nil && !codegen.CacheBypassed(ctx) {
+		if reply, ok := m.cache.get(args); ok {
+			return reply, 0, nil
+		}
+	}
+	if err := codegen.CheckPayloadSize("request of "+m.span, len(args), s.maxRequest); err != nil {
+		return nil, 0, err
+	}
+	if m.fault != nil {
+		if err := m.fault(); err != nil {
+			return nil, 0, err
+		}
+	}
+	var serverQueue time.Duration
 	opts := CallOptions{
-		Retry:    m.retry,
-		ShardKey: shardKey,
+		Retry:                m.retry,
+		ShardKey:             shardKey,
+		Compression:          s.compression,
+		CompressionThreshold: s.threshold,
+		MaxReplyBytes:        s.maxReply,
+		Queue:                &serverQueue,
+		Tracer:               s.tracer,
+		SpanName:             m.span,
+	}
+	if s.limiter != nil {
+		acquire := clock.Now()
+		if err := s.limiter.Acquire(ctx); err != nil {
+			return nil, clock.Since(acquire), err
+		}
+		queue = clock.Since(acquire)
+		start := time.Now()
+		defer func() { s.limiter.Release(time.Since(start), err) }()
 	}
 	n := 1
 	if m.retry {
@@ -67,18 +130,68 @@ func (s *stub) Run(ctx context.Context, method int, args []byte, shardKey uint64
 		result, err = s.conn.Call(ctx, m.key, args, opts)
 		// No backoff since these retries are fake ones injected for testing.
 	}
-	return
+	if err == nil {
+		// Drop oversized replies before the caller decodes them.
+		if err = codegen.CheckPayloadSize("reply of "+m.span, len(result), s.maxReply); err != nil {
+			result = nil
+		}
+	}
+	return result, queue + serverQueue, err
+}
+
+// CacheReply implements the codegen.Cacher interface. Replies are cached only
+// once the client stub has checked that the method didn't return an error, so
+// that failed calls, including retryable ones, aren't served from the cache.
+func (s *stub) CacheReply(method int, args, reply []byte) {
+	if m := s.methods[method]; m.cache != nil {
+		m.cache.put(args, reply)
+	}
+}
+
+// LogAccess implements the codegen.AccessLogger interface.
+func (s *stub) LogAccess(ctx context.Context, method int, duration time.Duration, err error) {
+	if s.accessLogger == nil || rand.Float64() >= s.accessLogRate {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	s.accessLogger.InfoContext(ctx, "access",
+		"method", s.methods[method].name,
+		"duration", duration,
+		"status", status)
+}
+
+// HedgeDelay implements the codegen.Hedger interface.
+func (s *stub) HedgeDelay(method int) time.Duration {
+	return s.methods[method].hedge
+}
+
+// Close closes the stub's connection. Pending and future calls through the
+// stub fail.
+func (s *stub) Close() {
+	s.conn.Close()
 }
 
-// makeStubMethods returns a slice of stub methods for the component methods of reg.
-func makeStubMethods(fullName string, reg *codegen.Registration) []stubMethod {
+// makeStubMethods returns a slice of stub methods for the component methods of
+// reg. faults holds the faults to inject, hedging the hedging delays, and
+// caching the cache TTLs, by method name.
+func makeStubMethods(fullName string, reg *codegen.Registration, faults map[string]func() error, hedging, caching map[string]time.Duration) []stubMethod {
 	// Construct method info slice.
 	n := reg.Iface.NumMethod()
 	methods := make([]stubMethod, n)
 	for i := 0; i < n; i++ {
 		mname := reg.Iface.Method(i).Name
+		methods[i].name = mname
+		methods[i].span = fullName + "." + mname
 		methods[i].key = MakeMethodKey(fullName, mname)
 		methods[i].retry = true // Retry by default
+		methods[i].fault = faults[mname]
+		methods[i].hedge = hedging[mname]
+		if ttl := caching[mname]; ttl > 0 {
+			methods[i].cache = newReplyCache(fullName, mname, ttl)
+		}
 	}
 	for _, m := range reg.NoRetry {
 		methods[m].retry = false
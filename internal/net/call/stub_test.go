@@ -18,9 +18,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ServiceWeaver/weaver/internal/reflection"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
@@ -243,7 +247,7 @@ func TestStubMethodRetries(t *testing.T) {
 		NoRetry: []int{1, 3},
 	}
 	want := []bool{true, false, true, false} // Which methods should be retriable?
-	methods := makeStubMethods(reg.Name, reg)
+	methods := makeStubMethods(reg.Name, reg, nil, nil, nil)
 	got := make([]bool, len(methods))
 	for i, m := range methods {
 		got[i] = m.retry
@@ -334,3 +338,179 @@ func weaverDecode(d *codegen.Decoder, output interface{}) interface{} {
 		panic(fmt.Errorf("Unable to decode type %v with Service Weaver decoder\n", x))
 	}
 }
+
+// countingHandler is a slog.Handler that counts the records it handles.
+type countingHandler struct {
+	n atomic.Int64
+}
+
+func (h *countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *countingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *countingHandler) WithGroup(string) slog.Handler            { return h }
+func (h *countingHandler) Handle(context.Context, slog.Record) error {
+	h.n.Add(1)
+	return nil
+}
+
+// TestAccessLogSampling logs a number of calls with various sample rates.
+// Verify that approximately the configured fraction of calls is logged.
+func TestAccessLogSampling(t *testing.T) {
+	const n = 10000
+	for _, rate := range []float64{0, 0.01, 0.1, 0.5, 1} {
+		t.Run(fmt.Sprint(rate), func(t *testing.T) {
+			var h countingHandler
+			stub := stub{
+				methods:       []stubMethod{{name: "test", key: MakeMethodKey("", "test")}},
+				accessLogger:  slog.New(&h),
+				accessLogRate: rate,
+			}
+			ctx := context.Background()
+			for i := 0; i < n; i++ {
+				stub.LogAccess(ctx, 0, time.Millisecond, nil)
+			}
+
+			// Allow for a deviation of 2% of the calls, which is more than 4
+			// standard deviations for every tested rate.
+			got, want := float64(h.n.Load()), rate*n
+			if math.Abs(got-want) > 0.02*n {
+				t.Fatalf("logged calls: got %v, want approximately %v", got, want)
+			}
+		})
+	}
+}
+
+// TestStubMethodKeysStable tests that the key that identifies a method on the
+// wire depends only on the names of the method and its component, and not on
+// the method's index, which changes as methods are added, removed, or renamed.
+func TestStubMethodKeysStable(t *testing.T) {
+	type before interface {
+		GetProduct(context.Context, string) error
+		SearchProducts(context.Context, string) error
+	}
+	type after interface {
+		AddProduct(context.Context, string) error
+		GetProduct(context.Context, string) error
+		Search(context.Context, string) error
+		SearchProducts(context.Context, string) error
+	}
+	const name = "github.com/example/catalog/Catalog"
+	keys := func(iface reflect.Type) map[string]MethodKey {
+		reg := &codegen.Registration{Name: name, Iface: iface}
+		keys := map[string]MethodKey{}
+		for _, m := range makeStubMethods(name, reg, nil, nil, nil) {
+			keys[m.name] = m.key
+		}
+		return keys
+	}
+	old := keys(reflection.Type[before]())
+	new := keys(reflection.Type[after]())
+	for method, key := range old {
+		if new[method] != key {
+			t.Errorf("key of %s changed from %x to %x", method, key, new[method])
+		}
+	}
+}
+
+// replyClient is a Connection that replies to every call with reply.
+type replyClient struct {
+	reply []byte
+	calls int
+}
+
+func (c *replyClient) Call(context.Context, MethodKey, []byte, CallOptions) ([]byte, error) {
+	c.calls++
+	return c.reply, nil
+}
+
+func (c *replyClient) Close() {}
+
+func TestStubPayloadLimits(t *testing.T) {
+	type catalog interface {
+		ListProducts(context.Context, string) error
+	}
+	const name = "github.com/example/catalog/Catalog"
+	reg := &codegen.Registration{Name: name, Iface: reflection.Type[catalog]()}
+	conn := &replyClient{}
+	stub := NewStub(name, reg, conn, nil, StubOptions{MaxRequestBytes: 4, MaxReplyBytes: 8})
+	ctx := context.Background()
+
+	conn.reply = make([]byte, 8)
+	if _, err := stub.Run(ctx, 0, make([]byte, 4), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Oversized requests are not sent.
+	if _, err := stub.Run(ctx, 0, make([]byte, 5), 0); !errors.Is(err, codegen.PayloadTooLargeError) {
+		t.Fatalf("got %v, want %v", err, codegen.PayloadTooLargeError)
+	}
+	if conn.calls != 1 {
+		t.Fatalf("oversized request was sent")
+	}
+
+	// Oversized replies are dropped.
+	conn.reply = make([]byte, 9)
+	if result, err := stub.Run(ctx, 0, nil, 0); !errors.Is(err, codegen.PayloadTooLargeError) || result != nil {
+		t.Fatalf("got %v, %v, want nil, %v", result, err, codegen.PayloadTooLargeError)
+	}
+}
+
+func TestStubCachesOnlySuccessfulReplies(t *testing.T) {
+	type prices interface {
+		Convert(context.Context, string) (string, error)
+	}
+	const name = "github.com/example/prices/Prices"
+	reg := &codegen.Registration{Name: name, Iface: reflection.Type[prices]()}
+	conn := &replyClient{}
+	stub := NewStub(name, reg, conn, nil, StubOptions{
+		Caching: map[string]time.Duration{"Convert": time.Hour},
+	})
+	ctx := context.Background()
+
+	reply := func(result string, err error) []byte {
+		enc := codegen.NewEncoder()
+		enc.String(result)
+		enc.Error(err)
+		return enc.Data()
+	}
+
+	// convert calls Convert the way the generated client stub does.
+	convert := func() (string, error) {
+		args := codegen.NewEncoder()
+		args.String("EUR")
+		results, err := stub.Run(ctx, 0, args.Data(), 0)
+		if err != nil {
+			return "", err
+		}
+		dec := codegen.NewResultDecoder(ctx, results)
+		result := dec.String()
+		if err := dec.Error(); err != nil {
+			return "", err
+		}
+		codegen.CacheReply(stub, 0, args.Data(), results)
+		return result, nil
+	}
+
+	// The first call fails with a retryable error, which must not be cached.
+	conn.reply = reply("", codegen.Retryable(errors.New("unavailable")))
+	if _, err := convert(); !codegen.IsRetryable(err) {
+		t.Fatalf("got %v, want a retryable error", err)
+	}
+
+	// The second call reaches the server and succeeds.
+	conn.reply = reply("1.08", nil)
+	if got, err := convert(); err != nil || got != "1.08" {
+		t.Fatalf("got %q, %v, want %q, nil", got, err, "1.08")
+	}
+	if conn.calls != 2 {
+		t.Fatalf("got %d calls, want 2", conn.calls)
+	}
+
+	// The third call is served from the cache.
+	conn.reply = reply("", errors.New("not cached"))
+	if got, err := convert(); err != nil || got != "1.08" {
+		t.Fatalf("got %q, %v, want %q, nil", got, err, "1.08")
+	}
+	if conn.calls != 2 {
+		t.Fatalf("got %d calls, want 2", conn.calls)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver/runtime/transport"
+)
+
+func init() {
+	transport.Register("memory", &memoryTransport{listeners: map[string]*memoryListener{}})
+}
+
+// memoryTransport is a transport.Transport whose connections are in-memory
+// pipes. It is registered as "memory", and is used to test and benchmark
+// calls over a registered transport.
+type memoryTransport struct {
+	mu        sync.Mutex
+	listeners map[string]*memoryListener // listeners, by address
+	next      int                        // next address to assign
+}
+
+// Listen implements the transport.Transport interface. An empty address is
+// replaced with a fresh one.
+func (t *memoryTransport) Listen(address string) (net.Listener, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if address == "" {
+		address = fmt.Sprintf("pipe-%d", t.next)
+		t.next++
+	}
+	if _, ok := t.listeners[address]; ok {
+		return nil, fmt.Errorf("memory transport: address %q in use", address)
+	}
+	l := &memoryListener{
+		transport: t,
+		addr:      memoryAddr(address),
+		conns:     make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+	t.listeners[address] = l
+	return l, nil
+}
+
+// Dial implements the transport.Transport interface.
+func (t *memoryTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	t.mu.Lock()
+	l, ok := t.listeners[address]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("memory transport: no listener on %q", address)
+	}
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		return nil, fmt.Errorf("memory transport: listener on %q closed", address)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// memoryListener is a net.Listener of a memoryTransport.
+type memoryListener struct {
+	transport *memoryTransport
+	addr      memoryAddr
+	conns     chan net.Conn // connections dialed to the listener
+	done      chan struct{} // closed when the listener is closed
+	closeOnce sync.Once
+}
+
+// Accept implements the net.Listener interface.
+func (l *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements the net.Listener interface.
+func (l *memoryListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		l.transport.mu.Lock()
+		delete(l.transport.listeners, string(l.addr))
+		l.transport.mu.Unlock()
+	})
+	return nil
+}
+
+// Addr implements the net.Listener interface.
+func (l *memoryListener) Addr() net.Addr { return l.addr }
+
+// memoryAddr is the net.Addr of a memoryListener.
+type memoryAddr string
+
+func (a memoryAddr) Network() string { return "memory" }
+func (a memoryAddr) String() string  { return string(a) }
@@ -0,0 +1,136 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package call
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+)
+
+// oldEndpoint is an Endpoint that dials a peer running the initial version of
+// the protocol, implemented by serve.
+type oldEndpoint struct {
+	serve func(net.Conn)
+}
+
+func (e oldEndpoint) Dial(context.Context) (net.Conn, error) {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		e.serve(server)
+	}()
+	return client, nil
+}
+
+func (e oldEndpoint) Address() string { return "old" }
+
+// writeInitialVersion sends the initial protocol version over w.
+func writeInitialVersion(w net.Conn, wlock *sync.Mutex) error {
+	var msg [4]byte
+	binary.LittleEndian.PutUint32(msg[:], uint32(initialVersion))
+	return writeFlat(w, wlock, versionMessage, 0, nil, msg[:])
+}
+
+func TestCallOldServer(t *testing.T) {
+	// Serve echo requests the way a server running the initial version of the
+	// protocol does.
+	serve := func(c net.Conn) {
+		var wlock sync.Mutex
+		if _, _, _, err := readMessage(c); err != nil {
+			t.Errorf("read version: %v", err)
+			return
+		}
+		if err := writeInitialVersion(c, &wlock); err != nil {
+			t.Errorf("write version: %v", err)
+			return
+		}
+		for {
+			mt, id, msg, err := readMessage(c)
+			if err != nil {
+				return
+			}
+			if mt != requestMessage {
+				t.Errorf("message type: got %d, want %d", mt, requestMessage)
+				return
+			}
+			hdrLen := binary.LittleEndian.Uint32(msg)
+			payload := msg[hdrLenLen+hdrLen:]
+			if err := writeMessage(c, &wlock, responseMessage, id, nil, payload, 0); err != nil {
+				t.Errorf("write response: %v", err)
+				return
+			}
+		}
+	}
+
+	ctx := context.Background()
+	conn, err := Connect(ctx, NewConstantResolver(oldEndpoint{serve}), ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	const want = "hello"
+	got, err := conn.Call(ctx, MakeMethodKey("component", "echo"), []byte(want), CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("Call: got %q, want %q", got, want)
+	}
+}
+
+func TestCallFromOldClient(t *testing.T) {
+	hmap := NewHandlerMap()
+	hmap.Set("component", "echo", func(_ context.Context, arg []byte) ([]byte, error) {
+		return arg, nil
+	})
+	client, server := net.Pipe()
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ServeOn(ctx, server, hmap, ServerOptions{})
+
+	// Call the server the way a client running the initial version of the
+	// protocol does.
+	var wlock sync.Mutex
+	if err := writeInitialVersion(client, &wlock); err != nil {
+		t.Fatal(err)
+	}
+	if mt, _, _, err := readMessage(client); err != nil || mt != versionMessage {
+		t.Fatalf("read version: got (%d, %v), want (%d, nil)", mt, err, versionMessage)
+	}
+	const want = "hello"
+	hdr := encodeHeader(ctx, MakeMethodKey("component", "echo"), 0, compressionHeader{}, initialVersion)
+	hdrSlice := binary.LittleEndian.AppendUint32(nil, uint32(len(hdr)))
+	hdrSlice = append(hdrSlice, hdr...)
+	if err := writeMessage(client, &wlock, requestMessage, 1, hdrSlice, []byte(want), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reply must be in the format of the initial version too.
+	mt, id, msg, err := readMessage(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt != responseMessage || id != 1 {
+		t.Fatalf("response: got (type %d, id %d), want (type %d, id 1)", mt, id, responseMessage)
+	}
+	if got := string(msg); got != want {
+		t.Errorf("response payload: got %q, want %q", got, want)
+	}
+}
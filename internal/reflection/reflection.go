@@ -16,6 +16,7 @@
 package reflection
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 )
@@ -34,3 +35,31 @@ func ComponentName[T any]() string {
 	t := Type[T]()
 	return fmt.Sprintf("%s/%s", t.PkgPath(), t.Name())
 }
+
+// CallMethod calls method m of a component with the context and arguments
+// passed to the caller of a reflect stub (see the ReflectStubFn field of
+// codegen.Registration), stores the results of m in returns, and returns the
+// error returned by m. The variadic arguments of a variadic method are passed
+// as a single slice, like the reflect stub does.
+func CallMethod(m reflect.Value, ctx context.Context, args []any, returns []any) error {
+	in := make([]reflect.Value, 1+len(args))
+	in[0] = reflect.ValueOf(ctx)
+	for i, arg := range args {
+		in[i+1] = reflect.ValueOf(arg)
+		if !in[i+1].IsValid() {
+			// A nil interface argument.
+			in[i+1] = reflect.Zero(m.Type().In(i + 1))
+		}
+	}
+	var out []reflect.Value
+	if m.Type().IsVariadic() {
+		out = m.CallSlice(in)
+	} else {
+		out = m.Call(in)
+	}
+	for i, ret := range returns {
+		reflect.ValueOf(ret).Elem().Set(out[i])
+	}
+	err, _ := out[len(out)-1].Interface().(error)
+	return err
+}
@@ -15,6 +15,8 @@
 package reflection_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -38,3 +40,48 @@ func TestType(t *testing.T) {
 		})
 	}
 }
+
+type calculator struct{}
+
+func (calculator) Sum(_ context.Context, xs ...int) (int, error) {
+	sum := 0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum, nil
+}
+
+func (calculator) Div(_ context.Context, x, y int) (int, error) {
+	if y == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return x / y, nil
+}
+
+func TestCallMethod(t *testing.T) {
+	ctx := context.Background()
+	c := reflect.ValueOf(calculator{})
+	for _, test := range []struct {
+		name    string
+		method  string
+		args    []any
+		want    int
+		wantErr bool
+	}{
+		{"Variadic", "Sum", []any{[]int{1, 2, 3}}, 6, false},
+		{"NoVariadicArgs", "Sum", []any{[]int(nil)}, 0, false},
+		{"Plain", "Div", []any{7, 2}, 3, false},
+		{"Error", "Div", []any{7, 0}, 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var got int
+			err := reflection.CallMethod(c.MethodByName(test.method), ctx, test.args, []any{&got})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("CallMethod: got error %v, want error %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("CallMethod: got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
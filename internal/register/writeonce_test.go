@@ -91,3 +91,14 @@ func TestTryWrite(t *testing.T) {
 		t.Fatalf("Read: got %v, want %v", got, want)
 	}
 }
+
+func TestTryRead(t *testing.T) {
+	var r register.WriteOnce[int]
+	if got, ok := r.TryRead(); ok {
+		t.Fatalf("TryRead: got (%v, true) before write, want (0, false)", got)
+	}
+	r.Write(x)
+	if got, ok := r.TryRead(); !ok || got != x {
+		t.Fatalf("TryRead: got (%v, %v), want (%v, true)", got, ok, x)
+	}
+}
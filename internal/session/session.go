@@ -0,0 +1,108 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session implements the session tokens that component methods can
+// issue to their callers with metadata.SetSessionToken.
+//
+// A client starts a session by attaching a Jar to its context. When a remote
+// component method issues a token, the token is stored in the jar, along with
+// the address of the replica that issued it. Subsequent calls made with the
+// context send the token back to the server and are routed to that replica,
+// if it is still available.
+package session
+
+import (
+	"context"
+	"sync"
+)
+
+// A Jar holds the session token issued to a client, like a cookie jar.
+type Jar struct {
+	mu      sync.Mutex
+	token   string // the most recently issued token
+	replica string // address of the replica that issued token, if remote
+}
+
+// Get returns the session token and the address of the replica that issued
+// it. The address is empty if no token was issued or if it was issued by a
+// colocated component.
+func (j *Jar) Get() (token, replica string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.token, j.replica
+}
+
+// Set stores a session token issued by the replica with the provided address.
+func (j *Jar) Set(token, replica string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.token = token
+	j.replica = replica
+}
+
+// A Server holds the session state of a remote method call on the server.
+type Server struct {
+	mu     sync.Mutex
+	token  string // the token sent by the client, or issued by the method
+	issued bool   // whether the method issued token
+}
+
+// Token returns the session token of the call.
+func (s *Server) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// Issued returns the session token issued by the method, if any.
+func (s *Server) Issued() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, s.issued
+}
+
+// Issue issues a session token to the client.
+func (s *Server) Issue(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	s.issued = true
+}
+
+type jarKey struct{}
+type serverKey struct{}
+
+// WithJar returns a new context that carries a new, empty jar.
+func WithJar(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jarKey{}, &Jar{})
+}
+
+// JarFromContext returns the jar carried by ctx, if any.
+func JarFromContext(ctx context.Context) (*Jar, bool) {
+	jar, ok := ctx.Value(jarKey{}).(*Jar)
+	return jar, ok
+}
+
+// WithServer returns a new context for a remote method call that received the
+// provided session token, along with the call's session state.
+func WithServer(ctx context.Context, token string) (context.Context, *Server) {
+	s := &Server{token: token}
+	return context.WithValue(ctx, serverKey{}, s), s
+}
+
+// ServerFromContext returns the server session state carried by ctx, if any.
+func ServerFromContext(ctx context.Context) (*Server, bool) {
+	s, ok := ctx.Value(serverKey{}).(*Server)
+	return s, ok
+}
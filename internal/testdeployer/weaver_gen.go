@@ -119,7 +119,7 @@ func (s a_local_stub) A(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "testdeployer.a.A", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/testdeployer/a", "A", "testdeployer.a.A", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -129,6 +129,13 @@ func (s a_local_stub) A(ctx context.Context, a0 int) (r0 int, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/a") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/a", "A", func(ctx context.Context) (err error) {
+			r0, err = s.impl.A(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.A(ctx, a0)
 }
 
@@ -148,7 +155,7 @@ func (s b_local_stub) B(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "testdeployer.b.B", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/testdeployer/b", "B", "testdeployer.b.B", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -158,6 +165,13 @@ func (s b_local_stub) B(ctx context.Context, a0 int) (r0 int, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/b") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/b", "B", func(ctx context.Context) (err error) {
+			r0, err = s.impl.B(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.B(ctx, a0)
 }
 
@@ -177,7 +191,7 @@ func (s c_local_stub) C(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "testdeployer.c.C", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/testdeployer/c", "C", "testdeployer.c.C", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -187,6 +201,13 @@ func (s c_local_stub) C(ctx context.Context, a0 int) (r0 int, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/c") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/c", "C", func(ctx context.Context) (err error) {
+			r0, err = s.impl.C(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.C(ctx, a0)
 }
 
@@ -206,7 +227,7 @@ func (s d_local_stub) D(ctx context.Context) (r0 string, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "testdeployer.d.D", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/testdeployer/d", "D", "testdeployer.d.D", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -216,6 +237,13 @@ func (s d_local_stub) D(ctx context.Context) (r0 string, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/d") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/d", "D", func(ctx context.Context) (err error) {
+			r0, err = s.impl.D(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.D(ctx)
 }
 
@@ -238,7 +266,7 @@ func (s a_client_stub) A(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "testdeployer.a.A", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/testdeployer/a", "A", "testdeployer.a.A", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -246,6 +274,7 @@ func (s a_client_stub) A(ctx context.Context, a0 int) (r0 int, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.aMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -256,6 +285,8 @@ func (s a_client_stub) A(ctx context.Context, a0 int) (r0 int, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -268,21 +299,27 @@ func (s a_client_stub) A(ctx context.Context, a0 int) (r0 int, err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type b_client_stub struct {
@@ -302,7 +339,7 @@ func (s b_client_stub) B(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "testdeployer.b.B", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/testdeployer/b", "B", "testdeployer.b.B", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -310,6 +347,7 @@ func (s b_client_stub) B(ctx context.Context, a0 int) (r0 int, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.bMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -320,6 +358,8 @@ func (s b_client_stub) B(ctx context.Context, a0 int) (r0 int, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -332,21 +372,27 @@ func (s b_client_stub) B(ctx context.Context, a0 int) (r0 int, err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type c_client_stub struct {
@@ -366,7 +412,7 @@ func (s c_client_stub) C(ctx context.Context, a0 int) (r0 int, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "testdeployer.c.C", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/testdeployer/c", "C", "testdeployer.c.C", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -374,6 +420,7 @@ func (s c_client_stub) C(ctx context.Context, a0 int) (r0 int, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.cMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -384,6 +431,8 @@ func (s c_client_stub) C(ctx context.Context, a0 int) (r0 int, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -396,21 +445,27 @@ func (s c_client_stub) C(ctx context.Context, a0 int) (r0 int, err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type d_client_stub struct {
@@ -430,7 +485,7 @@ func (s d_client_stub) D(ctx context.Context) (r0 string, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "testdeployer.d.D", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/testdeployer/d", "D", "testdeployer.d.D", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -438,6 +493,7 @@ func (s d_client_stub) D(ctx context.Context) (r0 string, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.dMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -448,33 +504,40 @@ func (s d_client_stub) D(ctx context.Context) (r0 string, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.String()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.String()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -512,22 +575,41 @@ func (s a_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s a_server_stub) a(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/testdeployer/a", "A", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.A(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/a") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/a", "A", func(ctx context.Context) (err error) {
+			r0, err = s.impl.A(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.A(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -555,22 +637,41 @@ func (s b_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s b_server_stub) b(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/testdeployer/b", "B", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.B(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/b") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/b", "B", func(ctx context.Context) (err error) {
+			r0, err = s.impl.B(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.B(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -598,22 +699,41 @@ func (s c_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s c_server_stub) c(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/testdeployer/c", "C", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.C(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/c") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/c", "C", func(ctx context.Context) (err error) {
+			r0, err = s.impl.C(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.C(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -644,14 +764,28 @@ func (s d_server_stub) d(ctx context.Context, args []byte) (res []byte, err erro
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/testdeployer/d", "D", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.D(ctx)
+	var r0 string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/testdeployer/d") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/testdeployer/d", "D", func(ctx context.Context) (err error) {
+			r0, err = s.impl.D(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.D(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -0,0 +1,350 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/routing"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/colors"
+	"github.com/ServiceWeaver/weaver/runtime/envelope"
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/prometheus"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+	"github.com/ServiceWeaver/weaver/runtime/tool"
+	"github.com/google/uuid"
+)
+
+// resolveInterval is how often a babysitter resolves the service name of a
+// colocation group to the addresses of the group's containers.
+const resolveInterval = 2 * time.Second
+
+var (
+	babysitterFlags      = flag.NewFlagSet("babysitter", flag.ContinueOnError)
+	babysitterBinary     = babysitterFlags.String("binary", "", "Application binary, if not the one in the config file")
+	babysitterDeployment = babysitterFlags.String("deployment", "", "Deployment id")
+
+	babysitterCmd = tool.Command{
+		Name:        "babysitter",
+		Description: "The weaver compose babysitter",
+		Help: `Usage:
+  weaver compose babysitter [--binary=<binary>] [--deployment=<id>] <configfile> <group>
+
+Flags:
+  -h, --help     Print this help message.
+  --binary       Application binary, if not the one in the config file.
+  --deployment   Deployment id.
+
+Description:
+  "weaver compose babysitter" runs a weavelet for the provided colocation group
+  in a container. It is the entrypoint of the containers in the Docker Compose
+  file written by "weaver compose generate".`,
+		Flags:  babysitterFlags,
+		Fn:     runBabysitter,
+		Hidden: true,
+	}
+)
+
+// babysitter runs and manages the weavelet of a colocation group replica in a
+// container.
+type babysitter struct {
+	ctx      context.Context
+	d        *deployment
+	group    *group
+	logger   *slog.Logger
+	printer  *logging.PrettyPrinter
+	envelope *envelope.Envelope
+
+	mu       sync.Mutex
+	watching map[string]bool // components whose routing info is watched
+}
+
+var _ envelope.EnvelopeHandler = &babysitter{}
+
+// runBabysitter runs a babysitter for the colocation group passed on the
+// command line, until the weavelet exits.
+func runBabysitter(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("want a config file and a group, got %d arguments", len(args))
+	}
+	configFile, groupName := args[0], args[1]
+	if *babysitterDeployment == "" {
+		return fmt.Errorf("no deployment id provided")
+	}
+
+	// Compute the deployment, exactly as "weaver compose generate" did.
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("load config file %q: %w", configFile, err)
+	}
+	app, err := runtime.ParseConfig(configFile, string(contents), codegen.ComponentConfigValidator)
+	if err != nil {
+		return fmt.Errorf("load config file %q: %w", configFile, err)
+	}
+	if *babysitterBinary != "" {
+		app.Binary = *babysitterBinary
+	}
+	d, err := newDeployment(app)
+	if err != nil {
+		return err
+	}
+	g, ok := d.byName[groupName]
+	if !ok || g.name != groupName {
+		return fmt.Errorf("unknown colocation group %q", groupName)
+	}
+
+	id := uuid.New().String()
+	b := &babysitter{
+		ctx:      ctx,
+		d:        d,
+		group:    g,
+		printer:  logging.NewPrettyPrinter(colors.Enabled()),
+		watching: map[string]bool{},
+	}
+	b.logger = slog.New(&logging.LogHandler{
+		Opts: logging.Options{
+			App:        app.Name,
+			Deployment: *babysitterDeployment,
+			Component:  "Babysitter",
+			Weavelet:   id,
+			Attrs:      []string{"serviceweaver/system", ""},
+		},
+		Write: b.log,
+	})
+
+	// Listen on the admin port before starting the weavelet, so that a port
+	// conflict fails fast.
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", d.config.AdminPort))
+	if err != nil {
+		return fmt.Errorf("admin server listen: %w", err)
+	}
+
+	// Start the envelope and the components of the group.
+	wlet := &protos.WeaveletArgs{
+		App:             app.Name,
+		DeploymentId:    *babysitterDeployment,
+		Id:              id,
+		RunMain:         slices.Contains(g.components, runtime.Main),
+		InternalAddress: fmt.Sprintf(":%d", internalPort),
+	}
+	e, err := envelope.NewEnvelope(ctx, wlet, app, envelope.Options{
+		Logger: b.logger,
+	})
+	if err != nil {
+		return err
+	}
+	b.envelope = e
+	if err := e.UpdateComponents(g.components); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := serveHTTP(ctx, lis, b.adminHandler()); err != nil {
+			b.logger.Error("admin server", "err", err)
+		}
+	}()
+	return e.Serve(b)
+}
+
+// adminHandler returns the handler of the admin server, which exports the
+// metrics and the health of the weavelet.
+func (b *babysitter) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		ms, err := b.envelope.GetMetrics()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ms = append(ms, metrics.Snapshot()...)
+		var buf bytes.Buffer
+		prometheus.TranslateMetricsToPrometheusTextFormat(&buf, ms, r.Host, r.URL.Path)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status := b.envelope.GetHealth().Status
+		if status != protos.HealthStatus_HEALTHY {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprintln(w, status)
+	})
+	return mux
+}
+
+// log prints a log entry to stdout, where Docker Compose collects it. Only
+// warnings and errors are printed for system-generated entries.
+func (b *babysitter) log(e *protos.LogEntry) {
+	if logging.IsSystemGenerated(e) && (strings.EqualFold(e.Level, "debug") || strings.EqualFold(e.Level, "info")) {
+		return
+	}
+	fmt.Println(b.printer.Format(e))
+}
+
+// ActivateComponent implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) ActivateComponent(_ context.Context, req *protos.ActivateComponentRequest) (*protos.ActivateComponentReply, error) {
+	// Every component is already running, since every container runs all of
+	// the components in its group. We only have to route to the component.
+	target, ok := b.d.byName[req.Component]
+	if !ok {
+		return nil, fmt.Errorf("unknown component %q", req.Component)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.watching[req.Component] {
+		return &protos.ActivateComponentReply{}, nil
+	}
+	b.watching[req.Component] = true
+
+	if !req.Routed && target == b.group {
+		// Route locally.
+		routing := &protos.RoutingInfo{Component: req.Component, Local: true}
+		return &protos.ActivateComponentReply{}, b.envelope.UpdateRoutingInfo(routing)
+	}
+
+	// Route remotely.
+	go b.watchRoutingInfo(req.Component, req.Routed, target)
+	return &protos.ActivateComponentReply{}, nil
+}
+
+// watchRoutingInfo keeps the routing info of the provided component, hosted by
+// the target colocation group, up to date. Docker Compose's DNS server
+// resolves the name of a service to the addresses of all of its running
+// containers, so the replicas of the target group are found by periodically
+// resolving the group's service name.
+func (b *babysitter) watchRoutingInfo(component string, routed bool, target *group) {
+	var replicas []string
+	assignment := &protos.Assignment{}
+	ticker := time.NewTicker(resolveInterval)
+	defer ticker.Stop()
+	for {
+		addrs, err := net.DefaultResolver.LookupHost(b.ctx, target.service)
+		if err != nil {
+			b.logger.Error("cannot resolve service; will retry", "err", err, "service", target.service, "component", component)
+		} else {
+			latest := make([]string, len(addrs))
+			for i, addr := range addrs {
+				latest[i] = "tcp://" + net.JoinHostPort(addr, strconv.Itoa(internalPort))
+			}
+			sort.Strings(latest)
+			if !slices.Equal(latest, replicas) {
+				info := &protos.RoutingInfo{Component: component, Replicas: latest}
+				if routed {
+					next := routing.EqualSlices(latest)
+					next.Version = assignment.Version + 1
+					info.Assignment = next
+				}
+				if err := b.envelope.UpdateRoutingInfo(info); err != nil {
+					b.logger.Error("cannot update routing info; will retry", "err", err, "component", component)
+				} else {
+					replicas = latest
+					if info.Assignment != nil {
+						assignment = info.Assignment
+					}
+				}
+			}
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetListenerAddress implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) GetListenerAddress(_ context.Context, req *protos.GetListenerAddressRequest) (*protos.GetListenerAddressReply, error) {
+	port, ok := b.d.listeners[req.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown listener %q", req.Name)
+	}
+	return &protos.GetListenerAddressReply{Address: fmt.Sprintf(":%d", port)}, nil
+}
+
+// ExportListener implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) ExportListener(_ context.Context, req *protos.ExportListenerRequest) (*protos.ExportListenerReply, error) {
+	// There is no proxy. Docker Compose publishes the listener's port on the
+	// same host port, unless the group is replicated, in which case the host
+	// port is ephemeral. See composeFile.
+	port := b.d.listeners[req.Listener]
+	if b.group.replicas > 1 {
+		b.logger.Info("Listener exported", "listener", req.Listener, "port", port, "service", b.group.service)
+		return &protos.ExportListenerReply{}, nil
+	}
+	addr := fmt.Sprintf("localhost:%d", port)
+	b.logger.Info("Listener exported", "listener", req.Listener, "address", addr)
+	return &protos.ExportListenerReply{ProxyAddress: addr}, nil
+}
+
+// GetSelfCertificate implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) GetSelfCertificate(context.Context, *protos.GetSelfCertificateRequest) (*protos.GetSelfCertificateReply, error) {
+	return nil, fmt.Errorf("mTLS is not supported by the Docker Compose deployer")
+}
+
+// VerifyClientCertificate implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) VerifyClientCertificate(context.Context, *protos.VerifyClientCertificateRequest) (*protos.VerifyClientCertificateReply, error) {
+	return nil, fmt.Errorf("mTLS is not supported by the Docker Compose deployer")
+}
+
+// VerifyServerCertificate implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) VerifyServerCertificate(context.Context, *protos.VerifyServerCertificateRequest) (*protos.VerifyServerCertificateReply, error) {
+	return nil, fmt.Errorf("mTLS is not supported by the Docker Compose deployer")
+}
+
+// LogBatch implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) LogBatch(_ context.Context, batch *protos.LogEntryBatch) error {
+	for _, entry := range batch.Entries {
+		b.log(entry)
+	}
+	return nil
+}
+
+// HandleTraceSpans implements the envelope.EnvelopeHandler interface.
+func (b *babysitter) HandleTraceSpans(context.Context, *protos.TraceSpans) error {
+	// Traces are not collected.
+	return nil
+}
+
+// serveHTTP serves HTTP traffic on the provided listener using the provided
+// handler. The server is shut down when then provided context is cancelled.
+func serveHTTP(ctx context.Context, lis net.Listener, handler http.Handler) error {
+	server := http.Server{Handler: handler}
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(lis) }()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compose implements the "weaver compose" deployer, which deploys an
+// application locally with Docker Compose. Every colocation group runs in its
+// own service, which can be replicated, and every container of a service runs
+// a babysitter that manages the container's weavelet.
+package compose
+
+import (
+	itool "github.com/ServiceWeaver/weaver/internal/tool"
+	"github.com/ServiceWeaver/weaver/runtime/tool"
+)
+
+var (
+	Commands = map[string]*tool.Command{
+		"generate": &generateCmd,
+		"version":  itool.VersionCmd("weaver compose"),
+
+		// Hidden commands.
+		"babysitter": &babysitterCmd,
+	}
+)
@@ -0,0 +1,242 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/bin"
+	"github.com/ServiceWeaver/weaver/runtime/graph"
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+)
+
+const (
+	configKey      = "github.com/ServiceWeaver/weaver/compose"
+	shortConfigKey = "compose"
+
+	// The default image that containers are created from.
+	defaultImage = "debian:bookworm-slim"
+
+	// The default port, inside every container, of the admin server that
+	// exports the metrics and health of the container's weavelet.
+	defaultAdminPort = 9090
+
+	// The port that listeners without a configured address listen on, in
+	// every container, is the listener's index in the sorted list of the
+	// application's listeners plus defaultListenerPort.
+	defaultListenerPort = 8000
+
+	// The port, inside every container, that the weavelet listens on for
+	// method calls from other weavelets.
+	internalPort = 10000
+)
+
+// composeConfig configures the Docker Compose deployer. It is parsed from the
+// [compose] section of a config file. For example:
+//
+//	[compose]
+//	image = "debian:bookworm-slim"
+//	admin_port = 9090
+//	replicas = { "github.com/example/app/Cache" = 3 }
+//	listeners.hello = { address = ":8080" }
+type composeConfig struct {
+	// The image that containers are created from. The application binary
+	// and the weaver binary are mounted into every container, so the image
+	// only needs to be able to run them.
+	Image string `toml:"image"`
+
+	// The path of the weaver binary mounted into every container, if it
+	// isn't the binary running "weaver compose generate". It must be a
+	// binary that runs in Image, e.g., a linux build of weaver when
+	// generating the compose file on a Mac.
+	WeaverBinary string `toml:"weaver_binary"`
+
+	// The port, inside every container, of the admin server that exports
+	// metrics in the Prometheus text format on /metrics and the health of the
+	// weavelet on /healthz.
+	AdminPort int `toml:"admin_port"`
+
+	// The number of replicas of a component, by full component name. The
+	// colocation group of a component is replicated as many times as the
+	// most replicated component in it. Components are not replicated by
+	// default.
+	Replicas map[string]int `toml:"replicas"`
+
+	// Listener options, by listener name.
+	Listeners map[string]listenerOptions `toml:"listeners"`
+}
+
+// listenerOptions configures a listener.
+type listenerOptions struct {
+	// The address, e.g. ":8080", that the listener listens on inside its
+	// containers. Only the port is used.
+	Address string `toml:"address"`
+}
+
+// deployment is a deployment of an application with the Docker Compose
+// deployer, as computed from a config file and the application binary.
+type deployment struct {
+	id        string // deployment id
+	app       *protos.AppConfig
+	config    *composeConfig
+	groups    []*group          // colocation groups, sorted by name
+	byName    map[string]*group // colocation groups, by component name
+	listeners map[string]int    // listener ports, by listener name
+}
+
+// group is a colocation group, which is deployed as a Docker Compose service.
+type group struct {
+	name       string   // group name, i.e., the first component in the group
+	service    string   // Docker Compose service name
+	components []string // components in the group, sorted
+	listeners  []string // listeners of the components in the group, sorted
+	replicas   int      // number of replicas
+}
+
+// newDeployment computes the deployment of the application with the provided
+// config.
+func newDeployment(app *protos.AppConfig) (*deployment, error) {
+	config := &composeConfig{}
+	if err := runtime.ParseConfigSection(configKey, shortConfigKey, app.Sections, config); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if config.Image == "" {
+		config.Image = defaultImage
+	}
+	if config.AdminPort == 0 {
+		config.AdminPort = defaultAdminPort
+	}
+	if config.AdminPort < 0 || config.AdminPort > 65535 || config.AdminPort == internalPort {
+		return nil, fmt.Errorf("invalid admin_port %d", config.AdminPort)
+	}
+
+	// Form colocation groups. As in the multiprocess deployer, every
+	// component that isn't colocated with other components is placed in its
+	// own group, and a group is named after its first component.
+	components, g, err := bin.ReadComponentGraph(app.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the call graph from the application binary: %w", err)
+	}
+	d := &deployment{app: app, config: config, byName: map[string]*group{}}
+	ensureGroup := func(name string) *group {
+		if g, ok := d.byName[name]; ok {
+			return g
+		}
+		g := &group{name: name, replicas: 1}
+		d.byName[name] = g
+		d.groups = append(d.groups, g)
+		return g
+	}
+	for _, colocate := range app.Colocate {
+		if len(colocate.Components) == 0 {
+			continue
+		}
+		g := ensureGroup(colocate.Components[0])
+		for _, c := range colocate.Components[1:] {
+			d.byName[c] = g
+		}
+	}
+	g.PerNode(func(n graph.Node) {
+		c := components[n]
+		g := ensureGroup(c)
+		g.components = append(g.components, c)
+	})
+	// Drop the groups of colocated components that aren't in the binary.
+	d.groups = slices.DeleteFunc(d.groups, func(g *group) bool { return len(g.components) == 0 })
+	sort.Slice(d.groups, func(i, j int) bool { return d.groups[i].name < d.groups[j].name })
+
+	// Replicate groups.
+	for c, n := range config.Replicas {
+		g, ok := d.byName[c]
+		if !ok || len(g.components) == 0 {
+			return nil, fmt.Errorf("replicas: component %q not found in the binary", c)
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("replicas: component %q has %d replicas, want at least 1", c, n)
+		}
+		g.replicas = max(g.replicas, n)
+	}
+
+	// Assign listener ports.
+	binListeners, err := bin.ReadListeners(app.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read listeners from binary %s: %w", app.Binary, err)
+	}
+	var names []string
+	for _, c := range binListeners {
+		g, ok := d.byName[c.Component]
+		if !ok {
+			continue
+		}
+		g.listeners = append(g.listeners, c.Listeners...)
+		names = append(names, c.Listeners...)
+	}
+	sort.Strings(names)
+	for lis := range config.Listeners {
+		if !slices.Contains(names, lis) {
+			return nil, fmt.Errorf("listener %s specified in the config not found in the binary", lis)
+		}
+	}
+	d.listeners = map[string]int{}
+	for i, lis := range names {
+		port := defaultListenerPort + i
+		if opts, ok := config.Listeners[lis]; ok && opts.Address != "" {
+			_, p, err := net.SplitHostPort(opts.Address)
+			if err == nil {
+				port, err = strconv.Atoi(p)
+			}
+			if err != nil || port <= 0 || port > 65535 {
+				return nil, fmt.Errorf("listener %s: invalid address %q", lis, opts.Address)
+			}
+		}
+		d.listeners[lis] = port
+	}
+
+	// Name services.
+	used := map[string]bool{}
+	for _, g := range d.groups {
+		sort.Strings(g.components)
+		sort.Strings(g.listeners)
+		g.service = serviceName(g.name, used)
+	}
+	return d, nil
+}
+
+// serviceName returns a Docker Compose service name for the colocation group
+// with the provided name that isn't in used, and adds it to used. The service
+// name is also the host name that resolves to the group's containers, so it
+// only contains lowercase letters, digits, and dashes.
+func serviceName(group string, used map[string]bool) string {
+	short := strings.ToLower(logging.ShortenComponent(group))
+	base := strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '-'
+	}, short)
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[name] = true
+	return name
+}
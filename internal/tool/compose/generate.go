@@ -0,0 +1,245 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	itool "github.com/ServiceWeaver/weaver/internal/tool"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/bin"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/tool"
+	"github.com/ServiceWeaver/weaver/runtime/version"
+	"github.com/google/uuid"
+)
+
+// The paths, inside every container, where the files needed to run the
+// application are mounted.
+const (
+	containerDir    = "/weaver"
+	containerWeaver = containerDir + "/weaver"
+	containerBinary = containerDir + "/app"
+	containerConfig = containerDir + "/weaver.toml"
+)
+
+var (
+	generateFlags = flag.NewFlagSet("generate", flag.ContinueOnError)
+	outDir        = generateFlags.String("out", ".", "Directory to write docker-compose.yml to")
+
+	generateCmd = tool.Command{
+		Name:        "generate",
+		Description: "Generate a Docker Compose file for a Service Weaver app",
+		Help: `Usage:
+  weaver compose generate [--out=<dir>] <configfile>
+
+Flags:
+  -h, --help   Print this help message.
+  --out        Directory to write docker-compose.yml to. Defaults to the
+               current directory.
+
+Description:
+  "weaver compose generate" writes a docker-compose.yml file that runs every
+  colocation group of the app in its own Docker Compose service. Run
+  "docker compose up" on the generated file to deploy the app.`,
+		Flags: generateFlags,
+		Fn:    generate,
+	}
+)
+
+// generate writes a Docker Compose file that deploys an application.
+func generate(ctx context.Context, args []string) error {
+	// Validate command line arguments.
+	if len(args) == 0 {
+		return fmt.Errorf("no config file provided")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+
+	// Load the config file.
+	configFile := args[0]
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("load config file %q: %w", configFile, err)
+	}
+
+	// Parse and sanity-check the app config.
+	app, err := runtime.ParseConfig(configFile, string(contents), codegen.ComponentConfigValidator)
+	if err != nil {
+		return fmt.Errorf("load config file %q: %w", configFile, err)
+	}
+	if _, err := os.Stat(app.Binary); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("binary %q doesn't exist", app.Binary)
+	}
+
+	// Check version compatibility.
+	versions, err := bin.ReadVersions(app.Binary)
+	if err != nil {
+		return fmt.Errorf("read versions: %w", err)
+	}
+	if versions.DeployerVersion != version.DeployerVersion {
+		// Try to relativize the binary, defaulting to the absolute path if
+		// there are any errors..
+		binary := app.Binary
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, app.Binary); err == nil {
+				binary = rel
+			}
+		}
+		selfVersion, err := itool.SelfVersion()
+		if err != nil {
+			return fmt.Errorf("read self version: %w", err)
+		}
+		return fmt.Errorf(`
+ERROR: The binary you're trying to deploy (%q) was built with
+github.com/ServiceWeaver/weaver module version %s. However, the 'weaver
+compose' binary you're using was built with weaver module version %s.
+These versions are incompatible.
+
+We recommend updating both the weaver module your application is built with and
+updating the 'weaver compose' command by running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-build your code and re-run 'weaver compose generate'. If the problem
+persists, please file an issue at https://github.com/ServiceWeaver/weaver/issues.`,
+			binary, versions.ModuleVersion, selfVersion)
+	}
+
+	// Compute the deployment.
+	d, err := newDeployment(app)
+	if err != nil {
+		return err
+	}
+	d.id = uuid.New().String()
+
+	// Find the files to mount into the containers.
+	weaver := d.config.WeaverBinary
+	if weaver == "" {
+		if weaver, err = os.Executable(); err != nil {
+			return fmt.Errorf("find weaver binary: %w", err)
+		}
+	}
+	m := mounts{weaver: weaver, binary: app.Binary, config: configFile}
+	for _, path := range []*string{&m.weaver, &m.binary, &m.config} {
+		if *path, err = filepath.Abs(*path); err != nil {
+			return err
+		}
+	}
+
+	// Write the compose file.
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	filename := filepath.Join(*outDir, "docker-compose.yml")
+	if err := os.WriteFile(filename, d.composeFile(m), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s. To deploy the app, run:\n\n    docker compose -f %s up\n", filename, filename)
+	return nil
+}
+
+// mounts are the absolute paths, on the host, of the files mounted into every
+// container.
+type mounts struct {
+	weaver string // weaver binary
+	binary string // application binary
+	config string // config file
+}
+
+// composeFile returns the contents of a Docker Compose file for the
+// deployment. Every colocation group is a service whose containers run the
+// weaver compose babysitter, which in turn runs a weavelet.
+func (d *deployment) composeFile(m mounts) []byte {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, `# Automatically generated by "weaver compose generate"; DO NOT EDIT.`)
+	fmt.Fprintf(&b, "name: %s\n", quote(projectName(d.app.Name)))
+	fmt.Fprintln(&b, "services:")
+	for _, g := range d.groups {
+		fmt.Fprintf(&b, "  %s:\n", quote(g.service))
+		fmt.Fprintf(&b, "    image: %s\n", quote(d.config.Image))
+		entrypoint := []string{
+			containerWeaver, "compose", "babysitter",
+			"--binary=" + containerBinary,
+			"--deployment=" + d.id,
+			containerConfig, g.name,
+		}
+		for i, arg := range entrypoint {
+			entrypoint[i] = quote(arg)
+		}
+		fmt.Fprintf(&b, "    entrypoint: [%s]\n", strings.Join(entrypoint, ", "))
+		fmt.Fprintln(&b, "    volumes:")
+		for _, v := range [][2]string{
+			{m.weaver, containerWeaver},
+			{m.binary, containerBinary},
+			{m.config, containerConfig},
+		} {
+			fmt.Fprintln(&b, "      - type: bind")
+			fmt.Fprintf(&b, "        source: %s\n", quote(v[0]))
+			fmt.Fprintf(&b, "        target: %s\n", quote(v[1]))
+			fmt.Fprintln(&b, "        read_only: true")
+		}
+		fmt.Fprintln(&b, "    deploy:")
+		fmt.Fprintf(&b, "      replicas: %d\n", g.replicas)
+
+		// The admin port of every container is published on an ephemeral
+		// host port; run "docker compose port" to find it. A listener port is
+		// published on the same host port, unless the group is replicated,
+		// since only one container can publish a given host port.
+		fmt.Fprintln(&b, "    ports:")
+		fmt.Fprintf(&b, "      - %s\n", quote(fmt.Sprint(d.config.AdminPort)))
+		for _, lis := range g.listeners {
+			port := d.listeners[lis]
+			if g.replicas == 1 {
+				fmt.Fprintf(&b, "      - %s # listener %s\n", quote(fmt.Sprintf("%d:%d", port, port)), lis)
+			} else {
+				fmt.Fprintf(&b, "      - %s # listener %s\n", quote(fmt.Sprint(port)), lis)
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// projectName returns a valid Docker Compose project name for the app with
+// the provided name.
+func projectName(app string) string {
+	name := strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(app))
+	return strings.TrimLeft(name, "-_")
+}
+
+// quote returns s as a double-quoted YAML string.
+func quote(s string) string {
+	// A JSON string is a valid YAML string.
+	b, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+)
+
+func TestServiceName(t *testing.T) {
+	used := map[string]bool{}
+	for _, test := range []struct{ group, want string }{
+		{"github.com/ServiceWeaver/weaver/Main", "weaver-main"},
+		{"github.com/example/app/Cache", "app-cache"},
+		{"github.com/other/app/Cache", "app-cache-2"},
+		{"github.com/example/my_app/Cache", "my-app-cache"},
+	} {
+		if got := serviceName(test.group, used); got != test.want {
+			t.Errorf("serviceName(%q): got %q, want %q", test.group, got, test.want)
+		}
+	}
+}
+
+func TestComposeFile(t *testing.T) {
+	main := &group{
+		name:       "github.com/ServiceWeaver/weaver/Main",
+		service:    "weaver-main",
+		components: []string{"github.com/ServiceWeaver/weaver/Main"},
+		listeners:  []string{"hello"},
+		replicas:   1,
+	}
+	cache := &group{
+		name:       "github.com/example/app/Cache",
+		service:    "app-cache",
+		components: []string{"github.com/example/app/Cache"},
+		listeners:  []string{"debug"},
+		replicas:   3,
+	}
+	d := &deployment{
+		id:        "1234",
+		app:       &protos.AppConfig{Name: "My App"},
+		config:    &composeConfig{Image: "busybox", AdminPort: 9090},
+		groups:    []*group{main, cache},
+		listeners: map[string]int{"hello": 8000, "debug": 8001},
+	}
+	got := string(d.composeFile(mounts{weaver: "/bin/weaver", binary: "/bin/app", config: "/etc/weaver.toml"}))
+	for _, want := range []string{
+		`name: "my-app"`,
+		`  "weaver-main":`,
+		`    image: "busybox"`,
+		`    entrypoint: ["/weaver/weaver", "compose", "babysitter", "--binary=/weaver/app", "--deployment=1234", "/weaver/weaver.toml", "github.com/ServiceWeaver/weaver/Main"]`,
+		`        source: "/etc/weaver.toml"`,
+		`      replicas: 3`,
+		`      - "9090"`,
+		`      - "8000:8000" # listener hello`,
+		`      - "8001" # listener debug`, // replicated, so not on a fixed host port
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("compose file does not contain %q:\n%s", want, got)
+		}
+	}
+}
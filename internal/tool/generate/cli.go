@@ -0,0 +1,343 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver/internal/files"
+	"golang.org/x/tools/go/packages"
+)
+
+// generatedCLIDir is the name of the directory, inside a package's directory,
+// that holds the command-line client of the package's components. See
+// generateCLI.
+const generatedCLIDir = "weaver_gen_cli"
+
+// generateCLI writes, in a weaver_gen_cli/main.go file, a command-line client
+// that calls the methods of the package's components through their client
+// stubs and prints the JSON encoding of the results. The client takes the
+// name of a method, in the form Component.Method, followed by the method's
+// arguments as flags named after the method's parameters. For example, given
+// the following component,
+//
+//	type Currency interface {
+//	    Convert(ctx context.Context, from string, amount float64, to string) (float64, error)
+//	}
+//
+// the client can be invoked as follows:
+//
+//	weaver_gen_cli Currency.Convert -from=USD -amount=10 -to=EUR
+//
+// Arguments of boolean, numeric, and string types are parsed from their usual
+// textual representation, durations are parsed with time.ParseDuration, and
+// arguments of any other type are parsed as JSON.
+//
+// The client is a Service Weaver application, so it needs its own generated
+// code. generateCLI returns the directory of the client, or "" if no client
+// was generated, and the caller is responsible for running "weaver generate"
+// on the returned directory. No client is generated for main packages, which
+// cannot be imported, nor for unexported components. Methods whose argument
+// or result types cannot be named outside the package are skipped.
+func (g *generator) generateCLI() (string, error) {
+	if g.pkg.Name == "main" {
+		return "", nil
+	}
+
+	// The client lives in its own package, so it gets its own type set.
+	dir := filepath.Join(g.pkgDir(), generatedCLIDir)
+	tset := newTypeSet(&packages.Package{
+		Name:    "main",
+		PkgPath: path.Join(g.pkg.PkgPath, generatedCLIDir),
+	}, g.tset.automarshals, g.tset.automarshalCandidates)
+	ts := tset.genTypeString
+	context := tset.importPackage("context", "context")
+	json := tset.importPackage("encoding/json", "json")
+	flag := tset.importPackage("flag", "flag")
+	fmtpkg := tset.importPackage("fmt", "fmt")
+	ospkg := tset.importPackage("os", "os")
+	weaver := tset.importPackage(weaverPackagePath, "weaver")
+
+	var body bytes.Buffer
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintln(&body, fmt.Sprintf(format, args...))
+	}
+	var usage []string // one line per method
+	var refs []string  // weaver.Ref fields of the main struct
+	p(``)
+	p(`// commands maps the name of every method, in the form Component.Method, to a`)
+	p(`// function that parses the method's arguments from the provided flags and`)
+	p(`// calls the method.`)
+	p(`var commands = map[string]func(%s, *client, []string) ([]any, error){`, context.qualify("Context"))
+	for _, comp := range g.components {
+		if comp.isMain || !comp.intf.Obj().Exported() {
+			continue
+		}
+		var methods []*types.Func
+		for _, m := range comp.methods() {
+			if cliNameable(m.Type()) {
+				methods = append(methods, m)
+			}
+		}
+		if len(methods) == 0 {
+			continue
+		}
+		field := fmt.Sprintf("c%d", len(refs))
+		refs = append(refs, fmt.Sprintf("%s %s", field, weaver.qualify(fmt.Sprintf("Ref[%s]", ts(comp.intf)))))
+		for _, m := range methods {
+			mt := m.Type().(*types.Signature)
+			name := fmt.Sprintf("%s.%s", comp.intfName(), m.Name())
+			line := "  " + name
+			p(`	%q: func(ctx %s, c *client, args []string) ([]any, error) {`, name, context.qualify("Context"))
+			p(`		flags := %s(%q, %s)`, flag.qualify("NewFlagSet"), name, flag.qualify("ContinueOnError"))
+			var args []string
+			for i := 1; i < mt.Params().Len(); i++ {
+				param := mt.Params().At(i)
+				t := param.Type()
+				arg := fmt.Sprintf("a%d", i-1)
+				flagName := param.Name()
+				if flagName == "" || flagName == "_" {
+					flagName = arg
+				}
+				line += fmt.Sprintf(" -%s=%s", flagName, ts(t))
+				p(`		var %s %s`, arg, ts(t))
+				p(`		flags.Func(%q, %q, func(s string) error {`, flagName, ts(t))
+				generateCLIParse(p, tset, arg, t)
+				p(`		})`)
+				if mt.Variadic() && i == mt.Params().Len()-1 {
+					args = append(args, arg+"...")
+				} else {
+					args = append(args, arg)
+				}
+			}
+			usage = append(usage, line)
+			p(`		if err := flags.Parse(args); err != nil {`)
+			p(`			return nil, err`)
+			p(`		}`)
+			call := fmt.Sprintf("c.%s.Get().%s(%s)", field, m.Name(), strings.Join(append([]string{"ctx"}, args...), ", "))
+			var results []string
+			for i := 0; i < mt.Results().Len()-1; i++ {
+				results = append(results, fmt.Sprintf("r%d", i))
+			}
+			if len(results) == 0 {
+				p(`		return nil, %s`, call)
+			} else {
+				p(`		%s, err := %s`, strings.Join(results, ", "), call)
+				p(`		return []any{%s}, err`, strings.Join(results, ", "))
+			}
+			p(`	},`)
+		}
+	}
+	p(`}`)
+	if len(usage) == 0 {
+		return "", nil
+	}
+
+	p(``)
+	p(`// client is the Service Weaver application that calls the methods.`)
+	p(`type client struct {`)
+	p(`	%s`, weaver.qualify(fmt.Sprintf("Implements[%s]", weaver.qualify("Main"))))
+	for _, ref := range refs {
+		p(`	%s`, ref)
+	}
+	p(`}`)
+	p(``)
+	p(`const usage = %s`, "`"+fmt.Sprintf(`Usage: %s <method> [flags]
+
+Calls a method of a component in package
+%s
+and prints the JSON encoding of the method's results. Flags that are not of a
+boolean, numeric, string, or duration type are parsed as JSON.
+
+Methods:
+%s`, generatedCLIDir, g.pkg.PkgPath, strings.Join(usage, "\n"))+"`")
+	p(``)
+	p(`func main() {`)
+	p(`	if err := %s(%s(), run); err != nil {`, weaver.qualify("Run"), context.qualify("Background"))
+	p(`		%s(%s, err)`, fmtpkg.qualify("Fprintln"), ospkg.qualify("Stderr"))
+	p(`		%s(1)`, ospkg.qualify("Exit"))
+	p(`	}`)
+	p(`}`)
+	p(``)
+	p(`func run(ctx %s, c *client) error {`, context.qualify("Context"))
+	p(`	if len(%s) < 2 {`, ospkg.qualify("Args"))
+	p(`		return %s("missing method\n\n%%s", usage)`, fmtpkg.qualify("Errorf"))
+	p(`	}`)
+	p(`	command, ok := commands[%s[1]]`, ospkg.qualify("Args"))
+	p(`	if !ok {`)
+	p(`		return %s("unknown method %%q\n\n%%s", %s[1], usage)`, fmtpkg.qualify("Errorf"), ospkg.qualify("Args"))
+	p(`	}`)
+	p(`	results, err := command(ctx, c, %s[2:])`, ospkg.qualify("Args"))
+	p(`	if err != nil {`)
+	p(`		return err`)
+	p(`	}`)
+	p(`	for _, result := range results {`)
+	p(`		b, err := %s(result, "", "  ")`, json.qualify("MarshalIndent"))
+	p(`		if err != nil {`)
+	p(`			return err`)
+	p(`		}`)
+	p(`		%s(string(b))`, fmtpkg.qualify("Println"))
+	p(`	}`)
+	p(`	return nil`)
+	p(`}`)
+
+	var header bytes.Buffer
+	fmt.Fprintln(&header, generatedHeader)
+	fmt.Fprintln(&header, ``)
+	fmt.Fprintf(&header, "// Command %s calls the methods of the components in package\n", generatedCLIDir)
+	fmt.Fprintf(&header, "// %s.\n", g.pkg.PkgPath)
+	fmt.Fprintln(&header, `package main`)
+	fmt.Fprintln(&header, ``)
+	fmt.Fprintln(&header, `import (`)
+	for _, imp := range tset.imports() {
+		if imp.alias == "" {
+			fmt.Fprintf(&header, "\t%q\n", imp.path)
+		} else {
+			fmt.Fprintf(&header, "\t%s %q\n", imp.alias, imp.path)
+		}
+	}
+	fmt.Fprintln(&header, `)`)
+	formatted, err := format.Source(append(header.Bytes(), body.Bytes()...))
+	if err != nil {
+		return "", fmt.Errorf("format.Source: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	dst := files.NewWriter(filepath.Join(dir, "main.go"))
+	defer dst.Cleanup()
+	if _, err := dst.Write(formatted); err != nil {
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// generateCLIParse generates the body of a function that parses s into the
+// variable arg of type t.
+func generateCLIParse(p printFn, tset *typeSet, arg string, t types.Type) {
+	ts := tset.genTypeString
+	if n, ok := unalias(t).(*types.Named); ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == "time" && n.Obj().Name() == "Duration" {
+		time := tset.importPackage("time", "time")
+		p(`			v, err := %s(s)`, time.qualify("ParseDuration"))
+		p(`			%s = v`, arg)
+		p(`			return err`)
+		return
+	}
+
+	// parse generates code that parses s with the provided strconv function.
+	parse := func(fn string, extra ...string) {
+		strconv := tset.importPackage("strconv", "strconv")
+		p(`			v, err := %s(%s)`, strconv.qualify(fn), strings.Join(append([]string{"s"}, extra...), ", "))
+		p(`			%s = %s(v)`, arg, ts(t))
+		p(`			return err`)
+	}
+	if b, ok := unalias(t).Underlying().(*types.Basic); ok {
+		switch b.Kind() {
+		case types.String:
+			p(`			%s = %s(s)`, arg, ts(t))
+			p(`			return nil`)
+			return
+		case types.Bool:
+			parse("ParseBool")
+			return
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+			parse("ParseInt", "0", fmt.Sprint(cliBitSize(b)))
+			return
+		case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64, types.Uintptr:
+			parse("ParseUint", "0", fmt.Sprint(cliBitSize(b)))
+			return
+		case types.Float32, types.Float64:
+			parse("ParseFloat", fmt.Sprint(cliBitSize(b)))
+			return
+		}
+	}
+	json := tset.importPackage("encoding/json", "json")
+	p(`			return %s([]byte(s), &%s)`, json.qualify("Unmarshal"), arg)
+}
+
+// cliBitSize returns the bit size to pass to the strconv function that parses
+// a value of the provided basic type, with 0 standing for the size of int.
+func cliBitSize(b *types.Basic) int {
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	case types.Int64, types.Uint64, types.Float64:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// cliNameable returns whether t can be named outside of the package that
+// declares it, which is the case unless t refers to unexported types or to
+// struct types with unexported fields.
+func cliNameable(t types.Type) bool {
+	switch x := unalias(t).(type) {
+	case *types.Basic:
+		return true
+	case *types.Named:
+		if x.Obj().Pkg() != nil && !x.Obj().Exported() {
+			return false
+		}
+		for i := 0; i < x.TypeArgs().Len(); i++ {
+			if !cliNameable(x.TypeArgs().At(i)) {
+				return false
+			}
+		}
+		return true
+	case *types.Pointer:
+		return cliNameable(x.Elem())
+	case *types.Slice:
+		return cliNameable(x.Elem())
+	case *types.Array:
+		return cliNameable(x.Elem())
+	case *types.Map:
+		return cliNameable(x.Key()) && cliNameable(x.Elem())
+	case *types.Struct:
+		for i := 0; i < x.NumFields(); i++ {
+			if !x.Field(i).Exported() || !cliNameable(x.Field(i).Type()) {
+				return false
+			}
+		}
+		return true
+	case *types.Interface:
+		return x.NumMethods() == 0
+	case *types.Signature:
+		return cliNameable(x.Params()) && cliNameable(x.Results())
+	case *types.Tuple:
+		for i := 0; i < x.Len(); i++ {
+			if !cliNameable(x.At(i).Type()) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
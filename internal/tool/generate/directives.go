@@ -0,0 +1,647 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// directivePrefix is the prefix of a method directive comment.
+const directivePrefix = "//weaver:"
+
+// A directive is a //weaver: comment attached to a component interface
+// method. For example, the directive "//weaver:readonly" has name "readonly"
+// and no arguments.
+type directive struct {
+	pos  token.Pos // position of the directive comment
+	name string    // e.g., "readonly"
+	args string    // the text between parentheses, if any
+}
+
+// parseDirective parses the provided comment line into a directive. It returns
+// false if the comment is not a directive.
+func parseDirective(c *ast.Comment) (directive, bool) {
+	text, ok := strings.CutPrefix(c.Text, directivePrefix)
+	if !ok {
+		return directive{}, false
+	}
+	text = strings.TrimSpace(text)
+	d := directive{pos: c.Slash, name: text}
+	if name, rest, ok := strings.Cut(text, "("); ok && strings.HasSuffix(rest, ")") {
+		d.name = name
+		d.args = strings.TrimSuffix(rest, ")")
+	}
+	return d, true
+}
+
+// findMethodDirectives finds the directives attached to the methods of the
+// component interfaces declared in the provided file. For example, the
+// following Get method has a readonly directive.
+//
+//	type T interface {
+//	    //weaver:readonly
+//	    Get(context.Context, string) (string, error)
+//	}
+func findMethodDirectives(pkg *packages.Package, f *ast.File, components map[string]*component) error {
+	var errs []error
+	for _, decl := range f.Decls {
+		gendecl, ok := decl.(*ast.GenDecl)
+		if !ok || gendecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gendecl.Specs {
+			typespec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			iface, ok := typespec.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			def, ok := pkg.TypesInfo.Defs[typespec.Name]
+			if !ok {
+				continue
+			}
+			named, ok := def.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			comp, ok := components[fullName(named)]
+			if !ok {
+				continue
+			}
+			for _, m := range iface.Methods.List {
+				if m.Doc == nil || len(m.Names) == 0 {
+					continue
+				}
+				name := m.Names[0].Name
+				for _, c := range m.Doc.List {
+					d, ok := parseDirective(c)
+					if !ok {
+						continue
+					}
+					if err := comp.addDirective(name, d); err != nil {
+						errs = append(errs, errorf(pkg.Fset, d.pos, "%s.%s: %w", comp.intfName(), name, err))
+					}
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// findPromotedMethodDirectives finds the directives attached to the methods
+// that the provided component's interface inherits from the interfaces it
+// embeds. For example, the following Admin component's Get method has a
+// readonly directive.
+//
+//	type Catalog interface {
+//	    //weaver:readonly
+//	    Get(context.Context, string) (string, error)
+//	}
+//
+//	type Admin interface {
+//	    Catalog
+//	    Delete(context.Context, string) error
+//	}
+//
+// The embedded interfaces may be declared in other packages, so the files that
+// declare the promoted methods are parsed anew. parsed caches the parsed files,
+// keyed by filename, and fset is the file set of the parsed files.
+func findPromotedMethodDirectives(pkg *packages.Package, comp *component, fset *token.FileSet, parsed map[string]*ast.File) error {
+	iface := comp.intf.Underlying().(*types.Interface)
+	explicit := map[string]bool{}
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		explicit[iface.ExplicitMethod(i).Name()] = true
+	}
+
+	var errs []error
+	for _, m := range comp.methods() {
+		if explicit[m.Name()] {
+			// Found by findMethodDirectives.
+			continue
+		}
+		pos := pkg.Fset.Position(m.Pos())
+		if !pos.IsValid() {
+			continue
+		}
+		f, err := parseFileCached(fset, parsed, pos.Filename)
+		if err != nil {
+			return err
+		}
+		field, _ := findInterfaceMethod(fset, f, m.Name(), pos.Line)
+		if field == nil || field.Doc == nil {
+			continue
+		}
+		for _, c := range field.Doc.List {
+			d, ok := parseDirective(c)
+			if !ok {
+				continue
+			}
+			if err := comp.addDirective(m.Name(), d); err != nil {
+				errs = append(errs, errorf(fset, d.pos, "%s.%s: %w", comp.intfName(), m.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseFileCached parses the provided file, unless it is in parsed, which
+// caches the parsed files by filename. fset is the file set of the parsed
+// files.
+func parseFileCached(fset *token.FileSet, parsed map[string]*ast.File, filename string) (*ast.File, error) {
+	if f, ok := parsed[filename]; ok {
+		return f, nil
+	}
+	f, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	parsed[filename] = f
+	return f, nil
+}
+
+// findInterfaceMethod returns the interface method with the provided name
+// declared on the provided line of f, along with the name of the interface
+// type that declares it, or nil if there is none.
+func findInterfaceMethod(fset *token.FileSet, f *ast.File, name string, line int) (*ast.Field, string) {
+	var found *ast.Field
+	var intf string
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || found != nil {
+			return found == nil
+		}
+		iface, ok := ts.Type.(*ast.InterfaceType)
+		if !ok {
+			return true
+		}
+		for _, m := range iface.Methods.List {
+			if len(m.Names) > 0 && m.Names[0].Name == name && fset.Position(m.Names[0].Pos()).Line == line {
+				found, intf = m, ts.Name.Name
+			}
+		}
+		return true
+	})
+	return found, intf
+}
+
+// addDirective records the provided directive for the provided method.
+func (c *component) addDirective(method string, d directive) error {
+	switch d.name {
+	case "readonly", "write", "cacheable":
+		if d.args != "" {
+			return errors.New(directivePrefix + d.name + " doesn't take arguments")
+		}
+	}
+
+	switch d.name {
+	case "readonly":
+		if _, ok := c.writes[method]; ok {
+			return errors.New("method cannot be both //weaver:readonly and //weaver:write")
+		}
+		if c.readonly == nil {
+			c.readonly = map[string]struct{}{}
+		}
+		c.readonly[method] = struct{}{}
+	case "write":
+		if _, ok := c.readonly[method]; ok {
+			return errors.New("method cannot be both //weaver:readonly and //weaver:write")
+		}
+		if _, ok := c.cacheable[method]; ok {
+			return errors.New("method cannot be both //weaver:cacheable and //weaver:write")
+		}
+		if c.writes == nil {
+			c.writes = map[string]struct{}{}
+		}
+		c.writes[method] = struct{}{}
+	case "cacheable":
+		if _, ok := c.writes[method]; ok {
+			return errors.New("method cannot be both //weaver:cacheable and //weaver:write")
+		}
+		for _, m := range c.methods() {
+			if m.Name() == method && streamResult(m.Type().(*types.Signature)) >= 0 {
+				return errors.New("a method that returns an io.ReadCloser cannot be //weaver:cacheable")
+			}
+		}
+		if c.cacheable == nil {
+			c.cacheable = map[string]struct{}{}
+		}
+		c.cacheable[method] = struct{}{}
+	case "route":
+		return c.addRoute(method, d.args)
+	case "trace_args":
+		return c.addTraceArgs(method, d.args)
+	default:
+		return errors.New("unknown directive " + directivePrefix + d.name)
+	}
+	return nil
+}
+
+// addRoute records that the provided method, marked //weaver:route(arg), is
+// routed on the provided argument. The argument is either the name of a
+// parameter of the method or its position, e.g., "a0" for the first argument
+// after the context. For example, the following Get method is routed on its id
+// argument.
+//
+//	type T interface {
+//	    //weaver:route(id)
+//	    Get(ctx context.Context, id string) (string, error)
+//	}
+//
+// Every routed method of a component must be routed on an argument of the same
+// type, and a component cannot have both a router and //weaver:route methods.
+func (c *component) addRoute(method, arg string) error {
+	if arg == "" {
+		return errors.New(directivePrefix + "route requires an argument")
+	}
+	if c.router != nil {
+		return fmt.Errorf("%sroute cannot be used on a component with router %s", directivePrefix, c.router.Obj().Name())
+	}
+	if _, ok := c.routeArgs[method]; ok {
+		return errors.New("method has multiple " + directivePrefix + "route directives")
+	}
+
+	// Find the routed argument.
+	var sig *types.Signature
+	for _, m := range c.methods() {
+		if m.Name() == method {
+			sig = m.Type().(*types.Signature)
+		}
+	}
+	if sig == nil {
+		return fmt.Errorf("method %s not found", method)
+	}
+	var param *types.Var
+	var name string
+	for i := 1; i < sig.Params().Len(); i++ { // Skip initial context.Context
+		if v := sig.Params().At(i); v.Name() == arg || fmt.Sprintf("a%d", i-1) == arg {
+			param, name = v, fmt.Sprintf("a%d", i-1)
+			break
+		}
+	}
+	if param == nil {
+		return fmt.Errorf("%sroute(%s): method has no argument %s", directivePrefix, arg, arg)
+	}
+
+	// Check the type of the routed argument.
+	t := param.Type()
+	qualifier := types.RelativeTo(c.intf.Obj().Pkg())
+	if sig.Variadic() && param == sig.Params().At(sig.Params().Len()-1) || !isValidRouterType(t) {
+		return fmt.Errorf("%sroute(%s): argument has invalid routing key type %s. A routing key type should be an integer, float, string, a struct with every field being an integer, float, or string, or a type that implements weaver.ShardKey.",
+			directivePrefix, arg, types.TypeString(t, qualifier))
+	}
+	if c.routingKey != nil && !types.Identical(t, c.routingKey) {
+		return fmt.Errorf("%sroute(%s): argument type %s does not match previously seen routing key type %s",
+			directivePrefix, arg, types.TypeString(t, qualifier), types.TypeString(c.routingKey, qualifier))
+	}
+
+	c.routingKey = t
+	if c.routedMethods == nil {
+		c.routedMethods = map[string]bool{}
+	}
+	c.routedMethods[method] = true
+	if c.routeArgs == nil {
+		c.routeArgs = map[string]string{}
+	}
+	c.routeArgs[method] = name
+	return nil
+}
+
+// traceArg is a method argument recorded as a span attribute.
+type traceArg struct {
+	arg string       // argument, e.g., "a0"
+	key string       // attribute key, e.g., "serviceweaver.arg.id"
+	t   *types.Basic // underlying type of the argument
+}
+
+// addTraceArgs records that the provided method, marked
+// //weaver:trace_args(args), records the provided comma-separated arguments
+// as attributes of its spans. Every argument is either the name of a parameter
+// of the method or its position, e.g., "a0" for the first argument after the
+// context. If args is empty, every string and integer argument is recorded.
+// For example, the id argument of the following Get method is recorded.
+//
+//	type T interface {
+//	    //weaver:trace_args(id)
+//	    Get(ctx context.Context, id string, opts Options) (string, error)
+//	}
+//
+// Only string and integer arguments can be recorded. Notably, slices, which
+// may be arbitrarily large, are never recorded.
+func (c *component) addTraceArgs(method, args string) error {
+	if _, ok := c.traceArgs[method]; ok {
+		return errors.New("method has multiple " + directivePrefix + "trace_args directives")
+	}
+	var sig *types.Signature
+	for _, m := range c.methods() {
+		if m.Name() == method {
+			sig = m.Type().(*types.Signature)
+		}
+	}
+	if sig == nil {
+		return fmt.Errorf("method %s not found", method)
+	}
+
+	// traceable returns the underlying type of the i-th parameter of sig, if
+	// it can be recorded.
+	traceable := func(i int) (*types.Basic, bool) {
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			return nil, false
+		}
+		b, ok := sig.Params().At(i).Type().Underlying().(*types.Basic)
+		if !ok || b.Info()&(types.IsString|types.IsInteger) == 0 {
+			return nil, false
+		}
+		return b, true
+	}
+	// newTraceArg returns the traceArg of the i-th parameter of sig.
+	newTraceArg := func(i int, t *types.Basic) traceArg {
+		arg := fmt.Sprintf("a%d", i-1)
+		name := sig.Params().At(i).Name()
+		if name == "" || name == "_" {
+			name = arg
+		}
+		return traceArg{arg: arg, key: "serviceweaver.arg." + name, t: t}
+	}
+
+	var traced []traceArg
+	if args == "" {
+		for i := 1; i < sig.Params().Len(); i++ { // Skip initial context.Context
+			if t, ok := traceable(i); ok {
+				traced = append(traced, newTraceArg(i, t))
+			}
+		}
+		if len(traced) == 0 {
+			return errors.New(directivePrefix + "trace_args: method has no string or integer arguments")
+		}
+	} else {
+		for _, arg := range strings.Split(args, ",") {
+			arg = strings.TrimSpace(arg)
+			found := -1
+			for i := 1; i < sig.Params().Len(); i++ { // Skip initial context.Context
+				if sig.Params().At(i).Name() == arg || fmt.Sprintf("a%d", i-1) == arg {
+					found = i
+					break
+				}
+			}
+			if found < 0 {
+				return fmt.Errorf("%strace_args(%s): method has no argument %s", directivePrefix, args, arg)
+			}
+			t, ok := traceable(found)
+			if !ok {
+				qualifier := types.RelativeTo(c.intf.Obj().Pkg())
+				return fmt.Errorf("%strace_args(%s): argument %s has type %s. Only string and integer arguments can be traced.",
+					directivePrefix, args, arg, types.TypeString(sig.Params().At(found).Type(), qualifier))
+			}
+			traced = append(traced, newTraceArg(found, t))
+		}
+	}
+
+	if c.traceArgs == nil {
+		c.traceArgs = map[string][]traceArg{}
+	}
+	c.traceArgs[method] = traced
+	return nil
+}
+
+// findTypeDirectives returns the named types declared in the provided file
+// that are marked with a directive with the provided name, along with their
+// directives.
+func findTypeDirectives(pkg *packages.Package, f *ast.File, name string) ([]*types.Named, []directive) {
+	var named []*types.Named
+	var directives []directive
+	for _, decl := range f.Decls {
+		gendecl, ok := decl.(*ast.GenDecl)
+		if !ok || gendecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gendecl.Specs {
+			typespec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			doc := typespec.Doc
+			if doc == nil && len(gendecl.Specs) == 1 {
+				// The doc comment of `type T int` is attached to the
+				// declaration, not the spec.
+				doc = gendecl.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			var found *directive
+			for _, c := range doc.List {
+				if d, ok := parseDirective(c); ok && d.name == name {
+					found = &d
+					break
+				}
+			}
+			if found == nil {
+				continue
+			}
+			def, ok := pkg.TypesInfo.Defs[typespec.Name]
+			if !ok {
+				continue
+			}
+			n, ok := def.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			named = append(named, n)
+			directives = append(directives, *found)
+		}
+	}
+	return named, directives
+}
+
+// hasTypeDirective returns whether the type with the provided name declared
+// on the provided line of f is marked with a directive with the provided name.
+func hasTypeDirective(fset *token.FileSet, f *ast.File, typename string, line int, name string) bool {
+	for _, decl := range f.Decls {
+		gendecl, ok := decl.(*ast.GenDecl)
+		if !ok || gendecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gendecl.Specs {
+			typespec, ok := spec.(*ast.TypeSpec)
+			if !ok || typespec.Name.Name != typename || fset.Position(typespec.Name.Pos()).Line != line {
+				continue
+			}
+			doc := typespec.Doc
+			if doc == nil && len(gendecl.Specs) == 1 {
+				doc = gendecl.Doc
+			}
+			if doc == nil {
+				return false
+			}
+			for _, c := range doc.List {
+				if d, ok := parseDirective(c); ok && d.name == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// findEnums returns the types in the provided file that are marked with a
+// //weaver:enum directive. For example, findEnums finds the following Status
+// type.
+//
+//	//weaver:enum
+//	type Status int
+//
+//	const (
+//	    Pending Status = iota
+//	    Settled
+//	)
+func findEnums(pkg *packages.Package, f *ast.File) ([]*types.Named, error) {
+	var enums []*types.Named
+	var errs []error
+	named, directives := findTypeDirectives(pkg, f, "enum")
+	for i, n := range named {
+		name := n.Obj().Name()
+		if directives[i].args != "" {
+			errs = append(errs, errorf(pkg.Fset, directives[i].pos, "%s: %senum doesn't take arguments", name, directivePrefix))
+			continue
+		}
+		if err := checkEnum(pkg, n); err != nil {
+			errs = append(errs, errorf(pkg.Fset, directives[i].pos, "%s: %w", name, err))
+			continue
+		}
+		enums = append(enums, n)
+	}
+	return enums, errors.Join(errs...)
+}
+
+// findTagged returns the AutoMarshal structs in the provided file that are
+// marked with a //weaver:tagged directive. For example, findTagged finds the
+// following Product type.
+//
+//	//weaver:tagged
+//	type Product struct {
+//	    weaver.AutoMarshal
+//	    Name  string `weaver:"tag=1"`
+//	    Price int64  `weaver:"tag=2"`
+//	}
+//
+// Every field of a tagged struct, other than the embedded weaver.AutoMarshal,
+// must have a distinct, positive field number. Blank fields reserve their
+// numbers. automarshals holds the AutoMarshal structs of the package.
+func findTagged(pkg *packages.Package, f *ast.File, automarshals *typeutil.Map) ([]*types.Named, error) {
+	var tagged []*types.Named
+	var errs []error
+	named, directives := findTypeDirectives(pkg, f, "tagged")
+	for i, n := range named {
+		name := n.Obj().Name()
+		if directives[i].args != "" {
+			errs = append(errs, errorf(pkg.Fset, directives[i].pos, "%s: %stagged doesn't take arguments", name, directivePrefix))
+			continue
+		}
+		if automarshals.At(n) == nil {
+			errs = append(errs, errorf(pkg.Fset, directives[i].pos, "%s: %stagged type must be a struct that embeds weaver.AutoMarshal", name, directivePrefix))
+			continue
+		}
+		s := n.Underlying().(*types.Struct)
+		numbered := map[int]string{} // field names, by field number
+		for j := 0; j < s.NumFields(); j++ {
+			fj := s.Field(j)
+			if isWeaverAutoMarshal(fj.Type()) {
+				continue
+			}
+			if weaverTag(s, j) == "tail" {
+				errs = append(errs, errorf(pkg.Fset, directives[i].pos, `%s: %stagged type cannot have a weaver:"tail" field`, name, directivePrefix))
+			}
+			tag, ok := fieldNumberTag(s, j)
+			if !ok {
+				errs = append(errs, errorf(pkg.Fset, fj.Pos(), `%s: field %s of %stagged type has no weaver:"tag=N" field number`, name, fj.Name(), directivePrefix))
+				continue
+			}
+			number, err := strconv.ParseUint(tag, 10, 32)
+			if err != nil || number == 0 {
+				errs = append(errs, errorf(pkg.Fset, fj.Pos(), `%s: field %s has invalid field number %q; field numbers must be positive 32-bit integers`, name, fj.Name(), tag))
+				continue
+			}
+			if other, ok := numbered[int(number)]; ok {
+				errs = append(errs, errorf(pkg.Fset, fj.Pos(), `%s: fields %s and %s have the same field number %d`, name, other, fj.Name(), number))
+				continue
+			}
+			numbered[int(number)] = fj.Name()
+		}
+		tagged = append(tagged, n)
+	}
+	return tagged, errors.Join(errs...)
+}
+
+// checkEnum checks that the provided type, marked with a //weaver:enum
+// directive, is a valid enum: an integer type with at least one constant and
+// without a user-defined IsValid method.
+func checkEnum(pkg *packages.Package, n *types.Named) error {
+	if n.TypeParams() != nil {
+		return errors.New(directivePrefix + "enum type cannot be generic")
+	}
+	if b, ok := n.Underlying().(*types.Basic); !ok || b.Info()&types.IsInteger == 0 {
+		return errors.New(directivePrefix + "enum type must have an integer underlying type")
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(n, true, n.Obj().Pkg(), "IsValid"); obj != nil {
+		// Ignore the IsValid method generated by a previous run of "weaver
+		// generate".
+		if filepath.Base(pkg.Fset.Position(obj.Pos()).Filename) != generatedCodeFile {
+			return errors.New(directivePrefix + "enum type already has an IsValid method")
+		}
+	}
+	if len(enumValues(pkg, n)) == 0 {
+		return errors.New(directivePrefix + "enum type has no constants")
+	}
+	return nil
+}
+
+// enumValues returns the names of the constants of type t declared at the top
+// level of the provided package, in declaration order. If multiple constants
+// have the same value, only the first one is returned.
+func enumValues(pkg *packages.Package, t *types.Named) []string {
+	var consts []*types.Const
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), t) {
+			consts = append(consts, c)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+
+	var names []string
+	seen := map[string]bool{}
+	for _, c := range consts {
+		if v := c.Val().ExactString(); !seen[v] {
+			seen[v] = true
+			names = append(names, c.Name())
+		}
+	}
+	return names
+}
@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"reflect"
+	"strconv"
 )
 
 func init() {
@@ -91,7 +93,7 @@ func (s a_local_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.A.M1", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M1", "main.A.M1", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -101,6 +103,13 @@ func (s a_local_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/A") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M1", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+		return
+	}
 	return s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
 }
 
@@ -111,7 +120,7 @@ func (s a_local_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.A.M2", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M2", "main.A.M2", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -121,6 +130,13 @@ func (s a_local_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/A") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M2", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+		return
+	}
 	return s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
 }
 
@@ -141,7 +157,7 @@ func (s b_local_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.B.M1", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M1", "main.B.M1", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -151,6 +167,13 @@ func (s b_local_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/B") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M1", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+		return
+	}
 	return s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
 }
 
@@ -161,7 +184,7 @@ func (s b_local_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.B.M2", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M2", "main.B.M2", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -171,6 +194,13 @@ func (s b_local_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/B") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M2", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+		return
+	}
 	return s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
 }
 
@@ -194,7 +224,7 @@ func (s a_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.A.M1", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M1", "main.A.M1", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -202,6 +232,7 @@ func (s a_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.m1Metrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -212,6 +243,8 @@ func (s a_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -227,22 +260,32 @@ func (s a_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	// Set the shardKey.
 	var r router
 	shardKey := _hashA(r.M1(ctx, a0, a1, a2, a3, a4, a5, a6))
+	if span.SpanContext().IsValid() && shardKey != 0 {
+		// Record the shard key to help debug hot shards.
+		span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "main.A.M1"))
+	}
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s a_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10]int, a4 []string, a5 map[bool]int, a6 message) (r0 pair, err error) {
@@ -254,7 +297,7 @@ func (s a_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.A.M2", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M2", "main.A.M2", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -262,6 +305,7 @@ func (s a_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.m2Metrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -272,6 +316,8 @@ func (s a_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -287,22 +333,32 @@ func (s a_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	// Set the shardKey.
 	var r router
 	shardKey := _hashA(r.M2(ctx, a0, a1, a2, a3, a4, a5, a6))
+	if span.SpanContext().IsValid() && shardKey != 0 {
+		// Record the shard key to help debug hot shards.
+		span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "main.A.M2"))
+	}
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type b_client_stub struct {
@@ -323,7 +379,7 @@ func (s b_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.B.M1", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M1", "main.B.M1", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -331,6 +387,7 @@ func (s b_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.m1Metrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -341,6 +398,8 @@ func (s b_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -356,22 +415,32 @@ func (s b_client_stub) M1(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	// Set the shardKey.
 	var r router
 	shardKey := _hashB(r.M1(ctx, a0, a1, a2, a3, a4, a5, a6))
+	if span.SpanContext().IsValid() && shardKey != 0 {
+		// Record the shard key to help debug hot shards.
+		span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "main.B.M1"))
+	}
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s b_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [10]int, a4 []string, a5 map[bool]int, a6 message) (r0 pair, err error) {
@@ -383,7 +452,7 @@ func (s b_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.B.M2", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M2", "main.B.M2", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -391,6 +460,7 @@ func (s b_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.m2Metrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -401,6 +471,8 @@ func (s b_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -416,31 +488,41 @@ func (s b_client_stub) M2(ctx context.Context, a0 int, a1 string, a2 bool, a3 [1
 	// Set the shardKey.
 	var r router
 	shardKey := _hashB(r.M2(ctx, a0, a1, a2, a3, a4, a5, a6))
+	if span.SpanContext().IsValid() && shardKey != 0 {
+		// Record the shard key to help debug hot shards.
+		span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "main.B.M2"))
+	}
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -480,36 +562,55 @@ func (s a_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s a_server_stub) m1(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 string
+	var a2 bool
+	var a3 [10]int
+	var a4 []string
+	var a5 map[bool]int
+	var a6 message
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M1", r, a0, a1, a2, a3, a4, a5, a6)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 string
 	a1 = dec.String()
-	var a2 bool
 	a2 = dec.Bool()
-	var a3 [10]int
 	serviceweaver_dec_array_10_int_03f98313(dec, &a3)
-	var a4 []string
 	a4 = serviceweaver_dec_slice_string_4af10117(dec)
-	var a5 map[bool]int
 	a5 = serviceweaver_dec_map_bool_int_acb668fa(dec)
-	var a6 message
 	(&a6).WeaverUnmarshal(dec)
 	var r router
 	s.addLoad(_hashA(r.M1(ctx, a0, a1, a2, a3, a4, a5, a6)), 1.0)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+	var r0 pair
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/A") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M1", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -519,36 +620,55 @@ func (s a_server_stub) m1(ctx context.Context, args []byte) (res []byte, err err
 }
 
 func (s a_server_stub) m2(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 string
+	var a2 bool
+	var a3 [10]int
+	var a4 []string
+	var a5 map[bool]int
+	var a6 message
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M2", r, a0, a1, a2, a3, a4, a5, a6)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 string
 	a1 = dec.String()
-	var a2 bool
 	a2 = dec.Bool()
-	var a3 [10]int
 	serviceweaver_dec_array_10_int_03f98313(dec, &a3)
-	var a4 []string
 	a4 = serviceweaver_dec_slice_string_4af10117(dec)
-	var a5 map[bool]int
 	a5 = serviceweaver_dec_map_bool_int_acb668fa(dec)
-	var a6 message
 	(&a6).WeaverUnmarshal(dec)
 	var r router
 	s.addLoad(_hashA(r.M2(ctx, a0, a1, a2, a3, a4, a5, a6)), 1.0)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+	var r0 pair
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/A") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/A", "M2", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -578,36 +698,55 @@ func (s b_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s b_server_stub) m1(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 string
+	var a2 bool
+	var a3 [10]int
+	var a4 []string
+	var a5 map[bool]int
+	var a6 message
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M1", r, a0, a1, a2, a3, a4, a5, a6)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 string
 	a1 = dec.String()
-	var a2 bool
 	a2 = dec.Bool()
-	var a3 [10]int
 	serviceweaver_dec_array_10_int_03f98313(dec, &a3)
-	var a4 []string
 	a4 = serviceweaver_dec_slice_string_4af10117(dec)
-	var a5 map[bool]int
 	a5 = serviceweaver_dec_map_bool_int_acb668fa(dec)
-	var a6 message
 	(&a6).WeaverUnmarshal(dec)
 	var r router
 	s.addLoad(_hashB(r.M1(ctx, a0, a1, a2, a3, a4, a5, a6)), 1.0)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+	var r0 pair
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/B") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M1", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.M1(ctx, a0, a1, a2, a3, a4, a5, a6)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -617,36 +756,55 @@ func (s b_server_stub) m1(ctx context.Context, args []byte) (res []byte, err err
 }
 
 func (s b_server_stub) m2(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 string
+	var a2 bool
+	var a3 [10]int
+	var a4 []string
+	var a5 map[bool]int
+	var a6 message
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M2", r, a0, a1, a2, a3, a4, a5, a6)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 string
 	a1 = dec.String()
-	var a2 bool
 	a2 = dec.Bool()
-	var a3 [10]int
 	serviceweaver_dec_array_10_int_03f98313(dec, &a3)
-	var a4 []string
 	a4 = serviceweaver_dec_slice_string_4af10117(dec)
-	var a5 map[bool]int
 	a5 = serviceweaver_dec_map_bool_int_acb668fa(dec)
-	var a6 message
 	(&a6).WeaverUnmarshal(dec)
 	var r router
 	s.addLoad(_hashB(r.M2(ctx, a0, a1, a2, a3, a4, a5, a6)), 1.0)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+	var r0 pair
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/internal/tool/generate/example/B") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/internal/tool/generate/example/B", "M2", func(ctx context.Context) (err error) {
+			r0, err = s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.M2(ctx, a0, a1, a2, a3, a4, a5, a6)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -731,6 +889,22 @@ func (x *message) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.f = serviceweaver_dec_map_bool_int_acb668fa(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *message) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "a", Value: x.a},
+		codegen.DebugField{Name: "b", Value: x.b},
+		codegen.DebugField{Name: "c", Value: x.c},
+		codegen.DebugField{Name: "d", Value: x.d},
+		codegen.DebugField{Name: "e", Value: x.e},
+		codegen.DebugField{Name: "f", Value: x.f},
+	)
+}
+
 func serviceweaver_enc_array_10_int_03f98313(enc *codegen.Encoder, arg *[10]int) {
 	for i := 0; i < 10; i++ {
 		enc.Int(arg[i])
@@ -759,7 +933,7 @@ func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
 	if n == -1 {
 		return nil
 	}
-	res := make([]string, n)
+	res := codegen.MakeSlice[string](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.String()
 	}
@@ -820,6 +994,18 @@ func (x *pair) WeaverUnmarshal(dec *codegen.Decoder) {
 	(&x.b).WeaverUnmarshal(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *pair) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "a", Value: x.a},
+		codegen.DebugField{Name: "b", Value: x.b},
+	)
+}
+
 var _ codegen.AutoMarshal = (*routingKey)(nil)
 
 type __is_routingKey[T ~struct {
@@ -849,6 +1035,19 @@ func (x *routingKey) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.c = dec.Float32()
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *routingKey) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "a", Value: x.a},
+		codegen.DebugField{Name: "b", Value: x.b},
+		codegen.DebugField{Name: "c", Value: x.c},
+	)
+}
+
 // Router methods.
 
 // _hashA returns a 64 bit hash of the provided value.
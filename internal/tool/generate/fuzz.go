@@ -0,0 +1,108 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ServiceWeaver/weaver/internal/files"
+)
+
+const (
+	// generatedFuzzFile is the name of the file that holds the round-trip
+	// fuzz tests of a package's AutoMarshal types. See generateFuzzTests.
+	generatedFuzzFile = "weaver_gen_fuzz_test.go"
+
+	// fuzzBuildConstraint is the build constraint of generatedFuzzFile. The
+	// fuzz tests are ignored unless the "weaverfuzz" tag is provided, so that
+	// they don't slow down regular test runs. Note that the "ignore" tag can't
+	// be used, as it would also build the files that many dependencies
+	// exclude with it.
+	fuzzBuildConstraint = "weaverfuzz"
+)
+
+// generateFuzzTests generates, in a weaver_gen_fuzz_test.go file, a fuzz test
+// for every AutoMarshal type declared in the package. The test checks that
+// the type survives an encoding round trip using weavertest.FuzzRoundTrip.
+// For example:
+//
+//	func FuzzProductRoundTrip(f *testing.F) {
+//	    weavertest.FuzzRoundTrip[Product](f)
+//	}
+//
+// The file is built only with the "weaverfuzz" build tag, so the tests are
+// run with a command like:
+//
+//	go test -tags weaverfuzz -fuzz FuzzProductRoundTrip
+//
+// If the package has no AutoMarshal types, a previously generated
+// weaver_gen_fuzz_test.go file is removed.
+func (g *generator) generateFuzzTests() error {
+	filename := filepath.Join(g.pkgDir(), generatedFuzzFile)
+
+	var named []*types.Named
+	for _, t := range g.tset.automarshalCandidates.Keys() {
+		if n := t.(*types.Named); n.TypeParams().Len() == 0 {
+			named = append(named, n)
+		}
+	}
+	if len(named) == 0 {
+		// Remove any stale tests, but never a file we didn't generate.
+		if b, err := os.ReadFile(filename); err == nil && bytes.HasPrefix(b, []byte(generatedHeader)) {
+			return os.Remove(filename)
+		}
+		return nil
+	}
+	sort.Slice(named, func(i, j int) bool {
+		return named[i].Obj().Name() < named[j].Obj().Name()
+	})
+
+	// The tests have their own imports, so they get their own type set.
+	tset := newTypeSet(g.pkg, g.tset.automarshals, g.tset.automarshalCandidates)
+	testing := tset.importPackage("testing", "testing")
+	weavertest := tset.importPackage(weaverPackagePath+"/weavertest", "weavertest")
+
+	var body bytes.Buffer
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintln(&body, fmt.Sprintf(format, args...))
+	}
+	for _, n := range named {
+		p(``)
+		p(`func Fuzz%sRoundTrip(f *%s) {`, exported(n.Obj().Name()), testing.qualify("F"))
+		p(`	%s[%s](f)`, weavertest.qualify("FuzzRoundTrip"), tset.genTypeString(n))
+		p(`}`)
+	}
+
+	var header bytes.Buffer
+	g.generateFileHeader(func(format string, args ...interface{}) {
+		fmt.Fprintln(&header, fmt.Sprintf(format, args...))
+	}, tset, fuzzBuildConstraint)
+	formatted, err := format.Source(append(header.Bytes(), body.Bytes()...))
+	if err != nil {
+		return fmt.Errorf("format.Source: %w", err)
+	}
+	dst := files.NewWriter(filename)
+	defer dst.Cleanup()
+	if _, err := dst.Write(formatted); err != nil {
+		return err
+	}
+	return dst.Close()
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/format"
 	"go/parser"
 	"go/token"
@@ -40,6 +41,7 @@ import (
 	"github.com/ServiceWeaver/weaver/runtime/colors"
 	"github.com/ServiceWeaver/weaver/runtime/version"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/types/typeutil"
 )
@@ -50,11 +52,15 @@ import (
 
 const (
 	generatedCodeFile = "weaver_gen.go"
+	generatedTestFile = "weaver_gen_test.go"
+
+	// generatedHeader is the first line of every generated file.
+	generatedHeader = `// Code generated by "weaver generate". DO NOT EDIT.`
 
 	Usage = `Generate code for a Service Weaver application.
 
 Usage:
-  weaver generate [-tags taglist] [packages]
+  weaver generate [-tags taglist] [-http] [-schema] [-openapi] [-cli] [-fuzz] [packages]
 
 Description:
   "weaver generate" generates code for the Service Weaver applications in the
@@ -67,6 +73,49 @@ Description:
   You specify build tags for "weaver generate" in the same way you specify build
   tags for go build. See "go help build" for more information.
 
+  If the -http flag is provided, "weaver generate" also generates, for every
+  component interface Foo, a NewFooHTTPHandler function that returns an
+  http.Handler serving Foo's methods as JSON over HTTP. Method M is served at
+  "POST /M"; the request body is a JSON array of M's arguments and the response
+  body is the encoding of M's result in the content type requested by the
+  Accept header, JSON by default.
+
+  If the -schema flag is provided, "weaver generate" also writes, in a
+  weaver_gen_schema.json file, a descriptor of the canonical binary encoding
+  of every struct that embeds weaver.AutoMarshal, and of every such struct that
+  these structs use. The descriptors list the fields of every struct in
+  encoding order, along with their types, and can be used to encode and decode
+  the structs in other languages.
+
+  If the -openapi flag is provided, "weaver generate" also writes, in a
+  weaver_gen_openapi.json file, an OpenAPI 3.1 document that describes the
+  handlers generated by the -http flag, assuming the handler of component
+  interface Foo is served under "/Foo/". Method M of Foo is described as the
+  operation "POST /Foo/M", with request and response schemas derived from M's
+  argument and result types. Errors are described as standard error responses.
+
+  If the -cli flag is provided, "weaver generate" also writes, in a
+  weaver_gen_cli directory inside the package's directory, a command-line
+  client that calls the methods of the package's components and prints their
+  results as JSON. The client takes the name of a method, in the form
+  Component.Method, followed by the method's arguments as flags named after
+  the method's parameters. Arguments of boolean, numeric, string, and duration
+  types are parsed from their usual textual representation, and arguments of
+  any other type are parsed as JSON. The client is itself a Service Weaver
+  application, for which "weaver generate" generates code as well.
+
+  If the -fuzz flag is provided, "weaver generate" also writes, in a
+  weaver_gen_fuzz_test.go file, a fuzz test for every struct that embeds
+  weaver.AutoMarshal. The test encodes and decodes random values of the struct
+  and checks that the decoded values are equal to the encoded ones. See
+  weavertest.FuzzRoundTrip for details. The file is only built with the
+  "weaverfuzz" build tag, e.g.,
+  "go test -tags weaverfuzz -fuzz FuzzFooRoundTrip".
+
+  For every component method marked //weaver:cacheable, "weaver generate" also
+  generates a test, in a weaver_gen_test.go file, that checks that the method
+  is idempotent. See weavertest.CheckCacheable for details.
+
   You specify packages for "weaver generate" in the same way you specify
   packages for go build, go test, go vet, etc. See "go help packages" for more
   information.
@@ -91,6 +140,26 @@ Examples:
   # Generate code for all packages in all subdirectories of current directory.
   weaver generate ./...
 
+  # Generate code, including JSON-over-HTTP handlers, for the package in the
+  # current directory.
+  weaver generate -http
+
+  # Generate code, along with canonical format descriptors, for the package in
+  # the current directory.
+  weaver generate -schema
+
+  # Generate code, along with an OpenAPI document for the JSON-over-HTTP
+  # handlers, for the package in the current directory.
+  weaver generate -http -openapi
+
+  # Generate code, along with a command-line client for the components, for
+  # the package in the current directory.
+  weaver generate -cli
+
+  # Generate code, along with round-trip fuzz tests for the AutoMarshal types,
+  # for the package in the current directory.
+  weaver generate -fuzz
+
   # Generate code for all files that have a "//go:build good" line at the top of
   the file.
   weaver generate -tags good
@@ -104,6 +173,11 @@ Examples:
 type Options struct {
 	Warn      func(error) // If non-nil, use the specified function to report warnings
 	BuildTags string
+	HTTP      bool // If true, generate a JSON-over-HTTP handler for every component
+	Schema    bool // If true, generate the canonical format descriptors of AutoMarshal types
+	OpenAPI   bool // If true, generate an OpenAPI document for the JSON-over-HTTP handlers
+	CLI       bool // If true, generate a command-line client for the components
+	Fuzz      bool // If true, generate round-trip fuzz tests for AutoMarshal types
 }
 
 // Generate generates Service Weaver code for the specified packages.
@@ -130,7 +204,42 @@ func Generate(dir string, pkgs []string, opt Options) error {
 
 	var automarshals typeutil.Map
 	var errs []error
+	var clients []string // directories of the generated command-line clients
 	for _, pkg := range pkgList {
+		if opt.CLI && pkg.Name == "main" && path.Base(pkg.PkgPath) == generatedCLIDir {
+			// The client is regenerated below, after the package it calls.
+			continue
+		}
+		g, err := newGenerator(opt, pkg, fset, &automarshals)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := g.generate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if opt.CLI {
+			dir, err := g.generateCLI()
+			if err != nil {
+				errs = append(errs, err)
+			} else if dir != "" {
+				clients = append(clients, dir)
+			}
+		}
+	}
+	if len(clients) == 0 {
+		return errors.Join(errs...)
+	}
+
+	// The command-line clients are Service Weaver applications themselves, so
+	// we generate code for them too.
+	clientPkgs, err := packages.Load(cfg, clients...)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("packages.Load: %w", err))...)
+	}
+	opt.CLI = false
+	for _, pkg := range clientPkgs {
 		g, err := newGenerator(opt, pkg, fset, &automarshals)
 		if err != nil {
 			errs = append(errs, err)
@@ -158,8 +267,16 @@ type generator struct {
 	tset           *typeSet
 	fileset        *token.FileSet
 	components     []*component
-	sizeFuncNeeded typeutil.Map // types that need a serviceweaver_size_* function
-	generated      typeutil.Map // memo cache for generateEncDecMethodsFor
+	enums          []*types.Named       // types marked //weaver:enum
+	tagged         []*types.Named       // AutoMarshal structs marked //weaver:tagged
+	parsedFset     *token.FileSet       // file set of parsed
+	parsed         map[string]*ast.File // files of other packages, by filename
+	http           bool                 // generate JSON-over-HTTP handlers
+	schema         bool                 // generate canonical format descriptors
+	openapi        bool                 // generate an OpenAPI document
+	fuzz           bool                 // generate round-trip fuzz tests
+	sizeFuncNeeded typeutil.Map         // types that need a serviceweaver_size_* function
+	generated      typeutil.Map         // memo cache for generateEncDecMethodsFor
 }
 
 // errorf is like fmt.Errorf but prefixes the error with the provided position.
@@ -214,6 +331,28 @@ func newGenerator(opt Options, pkg *packages.Package, fset *token.FileSet, autom
 		return nil, err
 	}
 
+	// Search every file in the package for types marked //weaver:enum.
+	var enums []*types.Named
+	for _, file := range pkg.Syntax {
+		filename := fset.Position(file.Package).Filename
+		if filepath.Base(filename) == generatedCodeFile {
+			// Ignore weaver_gen.go files.
+			continue
+		}
+		ts, err := findEnums(pkg, file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		enums = append(enums, ts...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	sort.Slice(enums, func(i, j int) bool {
+		return enums[i].Obj().Name() < enums[j].Obj().Name()
+	})
+
 	// Just because a type embeds weaver.AutoMarshal doesn't mean we can
 	// automatically marshal it. Some types, like `struct { x chan int }`, are
 	// just not serializable. Here, we check that every type that embeds
@@ -224,12 +363,49 @@ func newGenerator(opt Options, pkg *packages.Package, fset *token.FileSet, autom
 			errs = append(errs, errorf(fset, n.Obj().Pos(), "type %v is not serializable\n%w", t, err))
 			continue
 		}
+		if err := errors.Join(checkWeaverTags(pkg, n)...); err != nil {
+			errs = append(errs, errorf(fset, n.Obj().Pos(), "type %v has invalid struct tags\n%w", t, err))
+			continue
+		}
 		tset.automarshals.Set(t, struct{}{})
 	}
 	if err := errors.Join(errs...); err != nil {
 		return nil, err
 	}
 
+	// Search every file in the package for AutoMarshal structs marked
+	// //weaver:tagged.
+	var tagged []*types.Named
+	for _, file := range pkg.Syntax {
+		filename := fset.Position(file.Package).Filename
+		if filepath.Base(filename) == generatedCodeFile {
+			// Ignore weaver_gen.go files.
+			continue
+		}
+		ts, err := findTagged(pkg, file, tset.automarshals)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tagged = append(tagged, ts...)
+	}
+	for _, t := range tset.automarshalCandidates.Keys() {
+		// Field numbers are only meaningful in tagged structs.
+		n := t.(*types.Named)
+		if slices.Contains(tagged, n) {
+			continue
+		}
+		s := n.Underlying().(*types.Struct)
+		for i := 0; i < s.NumFields(); i++ {
+			if _, ok := fieldNumberTag(s, i); ok {
+				errs = append(errs, errorf(fset, s.Field(i).Pos(), `%s: field %s has a weaver:"tag=N" field number, but the type is not marked %stagged`, n.Obj().Name(), s.Field(i).Name(), directivePrefix))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
 	// Find and process all components.
 	components := map[string]*component{}
 	for _, file := range pkg.Syntax {
@@ -272,6 +448,27 @@ func newGenerator(opt Options, pkg *packages.Package, fset *token.FileSet, autom
 		if err := findMethodAttributes(pkg, file, components); err != nil {
 			errs = append(errs, err)
 		}
+		if err := findMethodDirectives(pkg, file, components); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Find the directives and attributes of methods promoted from embedded
+	// interfaces.
+	promotedFset, parsed := token.NewFileSet(), map[string]*ast.File{}
+	buildCtx := build.Default
+	if opt.BuildTags != "" {
+		buildCtx.BuildTags = strings.Split(opt.BuildTags, ",")
+	}
+	names := maps.Keys(components)
+	sort.Strings(names)
+	for _, name := range names {
+		if err := findPromotedMethodDirectives(pkg, components[name], promotedFset, parsed); err != nil {
+			errs = append(errs, err)
+		}
+		if err := findPromotedMethodAttributes(pkg, components[name], &buildCtx, promotedFset, parsed); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	if err := errors.Join(errs...); err != nil {
@@ -283,6 +480,12 @@ func newGenerator(opt Options, pkg *packages.Package, fset *token.FileSet, autom
 		tset:       tset,
 		fileset:    fset,
 		components: maps.Values(components),
+		enums:      enums,
+		tagged:     tagged,
+		http:       opt.HTTP,
+		schema:     opt.Schema,
+		openapi:    opt.OpenAPI,
+		fuzz:       opt.Fuzz,
 	}, nil
 }
 
@@ -358,6 +561,134 @@ func findMethodAttributes(pkg *packages.Package, f *ast.File, components map[str
 	return errors.Join(errs...)
 }
 
+// findPromotedMethodAttributes finds the weaver.NotRetriable declarations of
+// the methods that the provided component's interface inherits from the
+// interfaces it embeds. A promoted method is not retriable if it is declared
+// so in the package of the interface that declares it. For example, the
+// following Admin component's Put method is not retriable.
+//
+//	package catalog
+//
+//	type Catalog interface {
+//	    Put(context.Context, string) error
+//	}
+//
+//	var _ weaver.NotRetriable = Catalog.Put
+//
+//	package admin
+//
+//	type Admin interface {
+//	    catalog.Catalog
+//	    Delete(context.Context, string) error
+//	}
+//
+// Like in findPromotedMethodDirectives, the files of the declaring packages are
+// parsed anew. parsed caches the parsed files, keyed by filename, and fset is
+// the file set of the parsed files. buildCtx selects the files of a package.
+func findPromotedMethodAttributes(pkg *packages.Package, comp *component, buildCtx *build.Context, fset *token.FileSet, parsed map[string]*ast.File) error {
+	iface := comp.intf.Underlying().(*types.Interface)
+	explicit := map[string]bool{}
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		explicit[iface.ExplicitMethod(i).Name()] = true
+	}
+
+	for _, m := range comp.methods() {
+		if explicit[m.Name()] {
+			// Found by findMethodAttributes.
+			continue
+		}
+		pos := pkg.Fset.Position(m.Pos())
+		if !pos.IsValid() {
+			continue
+		}
+		f, err := parseFileCached(fset, parsed, pos.Filename)
+		if err != nil {
+			return err
+		}
+		_, intf := findInterfaceMethod(fset, f, m.Name(), pos.Line)
+		if intf == "" {
+			continue
+		}
+
+		// The declaration may be in any file of the declaring package.
+		dir := filepath.Dir(pos.Filename)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == generatedCodeFile {
+				continue
+			}
+			if ok, err := buildCtx.MatchFile(dir, name); err != nil || !ok {
+				continue
+			}
+			f, err := parseFileCached(fset, parsed, filepath.Join(dir, name))
+			if err != nil {
+				return err
+			}
+			if declaresNotRetriable(f, intf, m.Name()) {
+				if comp.noretry == nil {
+					comp.noretry = map[string]struct{}{}
+				}
+				comp.noretry[m.Name()] = struct{}{}
+			}
+		}
+	}
+	return nil
+}
+
+// declaresNotRetriable returns whether f has a declaration of the form
+//
+//	var _ weaver.NotRetriable = intf.method
+//
+// f is parsed without type information, so the declaration is matched
+// syntactically.
+func declaresNotRetriable(f *ast.File, intf, method string) bool {
+	weaverName := ""
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path != weaverPackagePath {
+			continue
+		}
+		weaverName = "weaver"
+		if imp.Name != nil {
+			weaverName = imp.Name.Name
+		}
+	}
+	if weaverName == "" {
+		return false
+	}
+	for _, decl := range f.Decls {
+		gendecl, ok := decl.(*ast.GenDecl)
+		if !ok || gendecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gendecl.Specs {
+			valspec, ok := spec.(*ast.ValueSpec)
+			if !ok || !isSelector(valspec.Type, weaverName, "NotRetriable") {
+				continue
+			}
+			for _, val := range valspec.Values {
+				if isSelector(val, intf, method) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isSelector returns whether e is the selector expression x.sel.
+func isSelector(e ast.Expr, x, sel string) bool {
+	s, ok := e.(*ast.SelectorExpr)
+	if !ok || s.Sel.Name != sel {
+		return false
+	}
+	id, ok := s.X.(*ast.Ident)
+	return ok && id.Name == x
+}
+
 // findComponentMethod returns the component and method if val is an expression of
 // the form C.M where C is a component listed in components and C has a method named M.
 func findComponentMethod(pkg *packages.Package, components map[string]*component, val ast.Expr) (*component, string, bool) {
@@ -497,7 +828,7 @@ func extractComponent(opt Options, pkg *packages.Package, file *ast.File, tset *
 	var intf *types.Named   // The component interface type
 	var router *types.Named // Router type (if any)
 	var isMain bool         // Is intf weaver.Main?
-	var refs []*types.Named // T for which weaver.Ref[T] exists in struct
+	var refs []*types.Named // T for which weaver.Ref[T] or weaver.Refs[T] exists in struct
 	var listeners []string  // Names of all listener fields declared in struct
 	for _, f := range s.Fields.List {
 		typeAndValue, ok := pkg.TypesInfo.Types[f.Type]
@@ -506,8 +837,9 @@ func extractComponent(opt Options, pkg *packages.Package, file *ast.File, tset *
 		}
 		t := typeAndValue.Type
 
-		if isWeaverRef(t) {
-			// The field f has type weaver.Ref[T].
+		if isWeaverRef(t) || isWeaverRefs(t) {
+			// The field f has type weaver.Ref[T] or weaver.Refs[T].
+			name := t.(*types.Named).Obj().Name()
 			arg := t.(*types.Named).TypeArgs().At(0)
 			if isWeaverMain(arg) {
 				return nil, errorf(pkg.Fset, f.Pos(),
@@ -516,8 +848,8 @@ func extractComponent(opt Options, pkg *packages.Package, file *ast.File, tset *
 			named, ok := arg.(*types.Named)
 			if !ok {
 				return nil, errorf(pkg.Fset, f.Pos(),
-					"weaver.Ref argument %s is not a named type.",
-					formatType(pkg, arg))
+					"weaver.%s argument %s is not a named type.",
+					name, formatType(pkg, arg))
 			}
 			refs = append(refs, named)
 		} else if isWeaverListener(t) {
@@ -690,15 +1022,20 @@ func getListenerNamesFromStructField(pkg *packages.Package, f *ast.Field) ([]str
 //	}
 //	type router struct{}
 type component struct {
-	intf          *types.Named        // component interface
-	impl          *types.Named        // component implementation
-	router        *types.Named        // router, or nil if there is no router
-	routingKey    types.Type          // routing key, or nil if there is no router
-	routedMethods map[string]bool     // the set of methods with a routing function
-	isMain        bool                // intf is weaver.Main
-	refs          []*types.Named      // List of T where a weaver.Ref[T] field is in impl struct
-	listeners     []string            // Names of listener fields declared in impl struct
-	noretry       map[string]struct{} // Methods that should not be retried
+	intf          *types.Named          // component interface
+	impl          *types.Named          // component implementation
+	router        *types.Named          // router, or nil if there is no router
+	routingKey    types.Type            // routing key, or nil if there is no router
+	routedMethods map[string]bool       // the set of methods with a routing function
+	routeArgs     map[string]string     // Routed argument (e.g., "a0") of methods marked //weaver:route
+	traceArgs     map[string][]traceArg // Arguments recorded in the spans of methods marked //weaver:trace_args
+	isMain        bool                  // intf is weaver.Main
+	refs          []*types.Named        // List of T where a weaver.Ref[T] or weaver.Refs[T] field is in impl struct
+	listeners     []string              // Names of listener fields declared in impl struct
+	noretry       map[string]struct{}   // Methods that should not be retried
+	readonly      map[string]struct{}   // Methods marked //weaver:readonly
+	writes        map[string]struct{}   // Methods marked //weaver:write
+	cacheable     map[string]struct{}   // Methods marked //weaver:cacheable
 }
 
 func fullName(t *types.Named) string {
@@ -788,9 +1125,18 @@ func validateMethods(pkg *packages.Package, tset *typeSet, intf *types.Named) er
 			errs = append(errs, bad("return", "The last return must have type error."))
 		}
 
-		// All results but error must be serializable.
+		// All results but error must be serializable, except for at most one
+		// io.ReadCloser, whose body is streamed to the caller.
+		numStreams := 0
 		for i := 0; i < t.Results().Len()-1; i++ {
 			res := t.Results().At(i)
+			if isReadCloser(res.Type()) {
+				numStreams++
+				if numStreams > 1 {
+					errs = append(errs, bad("return", "A method can return at most one io.ReadCloser."))
+				}
+				continue
+			}
 			if err := errors.Join(tset.checkSerializable(res.Type())...); err != nil {
 				// TODO(mwhittaker): Print a link to documentation on which types are serializable.
 				errs = append(errs, bad("return",
@@ -798,10 +1144,85 @@ func validateMethods(pkg *packages.Package, tset *typeSet, intf *types.Named) er
 					i, formatType(pkg, res.Type()), err))
 			}
 		}
+
+		// A struct with a weaver:"tail" field extends to the end of the
+		// encoded arguments, so it can only be the last argument. Results
+		// are always followed by the encoded error, so it can't be returned.
+		for i := 1; i < t.Params().Len(); i++ {
+			at := t.Params().At(i).Type()
+			last := i == t.Params().Len()-1 && !t.Variadic()
+			if containsTail(at) && !(last && hasTail(at)) {
+				errs = append(errs, bad("argument", "Argument %d has type %s, which contains a struct with a weaver:\"tail\" field. Such a struct can only be the last argument.", i, formatType(pkg, at)))
+			}
+		}
+		for i := 0; i < t.Results().Len()-1; i++ {
+			if rt := t.Results().At(i).Type(); containsTail(rt) {
+				errs = append(errs, bad("return", "Return %d has type %s, which contains a struct with a weaver:\"tail\" field. Such a struct can only be the last argument and can't be returned.", i, formatType(pkg, rt)))
+			}
+		}
+
+		// A method that receives a weaver.Offset cursor must return the
+		// offset that follows the returned entries.
+		if offsetArg(t) >= 0 {
+			numOffsetArgs, numOffsetResults := 0, 0
+			for i := 1; i < t.Params().Len(); i++ {
+				if isWeaverOffset(t.Params().At(i).Type()) {
+					numOffsetArgs++
+				}
+			}
+			for i := 0; i < t.Results().Len(); i++ {
+				if isWeaverOffset(t.Results().At(i).Type()) {
+					numOffsetResults++
+				}
+			}
+			if numOffsetArgs > 1 {
+				errs = append(errs, bad("argument", "A method can receive at most one weaver.Offset."))
+			}
+			if numOffsetResults != 1 {
+				errs = append(errs, bad("return", "A method that receives a weaver.Offset must return exactly one weaver.Offset, the offset that follows the returned entries."))
+			}
+		}
 	}
 	return errors.Join(errs...)
 }
 
+// offsetArg returns the index, among the arguments following the initial
+// context.Context, of the first weaver.Offset argument of the provided
+// signature, or -1 if there is none.
+func offsetArg(sig *types.Signature) int {
+	for i := 1; i < sig.Params().Len(); i++ {
+		if isWeaverOffset(sig.Params().At(i).Type()) {
+			return i - 1
+		}
+	}
+	return -1
+}
+
+// streamResult returns the index of the io.ReadCloser result of the provided
+// signature, whose body is streamed to the caller, or -1 if there is none.
+func streamResult(sig *types.Signature) int {
+	for i := 0; i < sig.Results().Len()-1; i++ {
+		if isReadCloser(sig.Results().At(i).Type()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// traceAttributes returns the span attributes, separated by commas, that
+// record the provided arguments.
+func (g *generator) traceAttributes(args []traceArg) string {
+	attrs := make([]string, len(args))
+	for i, a := range args {
+		if a.t.Info()&types.IsString != 0 {
+			attrs[i] = fmt.Sprintf(`%s(%q, string(%s))`, g.attribute().qualify("String"), a.key, a.arg)
+		} else {
+			attrs[i] = fmt.Sprintf(`%s(%q, int64(%s))`, g.attribute().qualify("Int64"), a.key, a.arg)
+		}
+	}
+	return strings.Join(attrs, ", ")
+}
+
 // checkMistypedInitOrShutdown returns an error if the provided component implementation
 // has an Init or a Shutdown method that does not have type "func(context.Context) error".
 func checkMistypedInitOrShutdown(pkg *packages.Package, tset *typeSet, impl *types.Named) error {
@@ -888,7 +1309,7 @@ func routerMethods(pkg *packages.Package, intf, router *types.Named) (types.Type
 		if i == 0 {
 			if !isValidRouterType(ret) {
 				return nil, nil, errorf(pkg.Fset, pos,
-					"Router method %q has invalid routing key type %q. A routing key type should be an integer, float, string, or a struct with every field being an integer, float, or string.",
+					"Router method %q has invalid routing key type %q. A routing key type should be an integer, float, string, a struct with every field being an integer, float, or string, or a type that implements weaver.ShardKey.",
 					m.Name(), formatType(pkg, ret))
 			}
 			routingKey = ret
@@ -912,7 +1333,7 @@ type printFn func(format string, args ...interface{})
 
 // TODO(mwhittaker): Have generate return an error.
 func (g *generator) generate() error {
-	if len(g.components)+g.tset.automarshalCandidates.Len() == 0 {
+	if len(g.components)+g.tset.automarshalCandidates.Len()+len(g.enums) == 0 {
 		// There's nothing to generate.
 		return nil
 	}
@@ -938,7 +1359,11 @@ func (g *generator) generate() error {
 		}
 		g.generateServerStubs(fn)
 		g.generateReflectStubs(fn)
+		if g.http {
+			g.generateHTTPHandlers(fn)
+		}
 		g.generateAutoMarshalMethods(fn)
+		g.generateEnumMethods(fn)
 		g.generateRouterMethods(fn)
 		g.generateEncDecMethods(fn)
 
@@ -964,7 +1389,7 @@ func (g *generator) generate() error {
 		fn := func(format string, args ...interface{}) {
 			fmt.Fprintln(&header, fmt.Sprintf(format, args...))
 		}
-		g.generateImports(fn)
+		g.generateImports(fn, g.tset)
 	}
 
 	// Create a generated file.
@@ -992,6 +1417,113 @@ func (g *generator) generate() error {
 	if err := fmtAndWrite(body); err != nil {
 		return err
 	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if g.schema {
+		if err := g.generateSchema(); err != nil {
+			return err
+		}
+	}
+	if g.openapi {
+		if err := g.generateOpenAPI(); err != nil {
+			return err
+		}
+	}
+	if g.fuzz {
+		if err := g.generateFuzzTests(); err != nil {
+			return err
+		}
+	}
+	return g.generateCacheableTests()
+}
+
+// generateCacheableTests generates, in a weaver_gen_test.go file, a test for
+// every component method marked //weaver:cacheable. The test checks that the
+// method is idempotent using weavertest.CheckCacheable. For example:
+//
+//	func TestCatalogGetProductCacheable(t *testing.T) {
+//	    weavertest.CheckCacheable[Catalog](t, "GetProduct", func(ctx context.Context, comp Catalog) ([]any, error) {
+//	        var a0 string
+//	        r0, err := comp.GetProduct(ctx, a0)
+//	        return []any{r0}, err
+//	    })
+//	}
+//
+// The arguments of the method are zero values. If no methods are marked
+// //weaver:cacheable, a previously generated weaver_gen_test.go file is removed.
+func (g *generator) generateCacheableTests() error {
+	filename := filepath.Join(g.pkgDir(), generatedTestFile)
+
+	// The tests have their own imports, so they get their own type set.
+	tset := newTypeSet(g.pkg, g.tset.automarshals, g.tset.automarshalCandidates)
+	ts := tset.genTypeString
+	testing := tset.importPackage("testing", "testing")
+	context := tset.importPackage("context", "context")
+	weavertest := tset.importPackage(weaverPackagePath+"/weavertest", "weavertest")
+
+	var body bytes.Buffer
+	p := func(format string, args ...interface{}) {
+		fmt.Fprintln(&body, fmt.Sprintf(format, args...))
+	}
+	n := 0
+	for _, comp := range g.components {
+		for _, m := range comp.methods() {
+			if _, ok := comp.cacheable[m.Name()]; !ok {
+				continue
+			}
+			n++
+			mt := m.Type().(*types.Signature)
+			p(``)
+			p(`func Test%s%sCacheable(t *%s) {`, exported(comp.intfName()), m.Name(), testing.qualify("T"))
+			p(`	%s[%s](t, %q, func(ctx %s, comp %s) ([]any, error) {`,
+				weavertest.qualify("CheckCacheable"), ts(comp.intf), m.Name(), context.qualify("Context"), ts(comp.intf))
+			var args []string
+			for i := 1; i < mt.Params().Len(); i++ {
+				p(`		var a%d %s`, i-1, ts(mt.Params().At(i).Type()))
+				if mt.Variadic() && i == mt.Params().Len()-1 {
+					args = append(args, fmt.Sprintf("a%d...", i-1))
+				} else {
+					args = append(args, fmt.Sprintf("a%d", i-1))
+				}
+			}
+			call := fmt.Sprintf("comp.%s(%s)", m.Name(), strings.Join(append([]string{"ctx"}, args...), ", "))
+			var results []string
+			for i := 0; i < mt.Results().Len()-1; i++ {
+				results = append(results, fmt.Sprintf("r%d", i))
+			}
+			if len(results) == 0 {
+				p(`		return nil, %s`, call)
+			} else {
+				p(`		%s, err := %s`, strings.Join(results, ", "), call)
+				p(`		return []any{%s}, err`, strings.Join(results, ", "))
+			}
+			p(`	})`)
+			p(`}`)
+		}
+	}
+
+	if n == 0 {
+		// Remove any stale tests, but never a file we didn't generate.
+		if b, err := os.ReadFile(filename); err == nil && bytes.HasPrefix(b, []byte(generatedHeader)) {
+			return os.Remove(filename)
+		}
+		return nil
+	}
+
+	var header bytes.Buffer
+	g.generateImports(func(format string, args ...interface{}) {
+		fmt.Fprintln(&header, fmt.Sprintf(format, args...))
+	}, tset)
+	formatted, err := format.Source(append(header.Bytes(), body.Bytes()...))
+	if err != nil {
+		return fmt.Errorf("format.Source: %w", err)
+	}
+	dst := files.NewWriter(filename)
+	defer dst.Cleanup()
+	if _, err := dst.Write(formatted); err != nil {
+		return err
+	}
 	return dst.Close()
 }
 
@@ -1014,15 +1546,23 @@ func (g *generator) componentRef(comp *component) string {
 	return comp.intfName() // We already checked that interface is in the same package.
 }
 
-// generateImports generates code to import all the dependencies.
-func (g *generator) generateImports(p printFn) {
-	p(`// Code generated by "weaver generate". DO NOT EDIT.`)
-	p("//go:build !ignoreWeaverGen")
+// generateImports generates code to import all the dependencies in the
+// provided type set.
+func (g *generator) generateImports(p printFn, tset *typeSet) {
+	g.generateFileHeader(p, tset, "!ignoreWeaverGen")
+}
+
+// generateFileHeader generates the header of a generated file with the
+// provided build constraint, along with code to import all the dependencies
+// in the provided type set.
+func (g *generator) generateFileHeader(p printFn, tset *typeSet, constraint string) {
+	p(generatedHeader)
+	p("//go:build %s", constraint)
 	p("")
 	p("package %s", g.pkg.Name)
 	p("")
 	p(`import (`)
-	for _, imp := range g.tset.imports() {
+	for _, imp := range tset.imports() {
 		switch {
 		case imp.local:
 			// Already inside desired package
@@ -1258,7 +1798,7 @@ func (g *generator) generateRegisteredComponents(p printFn) {
 		//   https://pkg.go.dev/reflect#example-TypeOf
 		p(`		Iface: %s((*%s)(nil)).Elem(),`, reflect.qualify("TypeOf"), g.componentRef(comp))
 		p(`		Impl: %s(%s{}),`, reflect.qualify("TypeOf"), comp.implName())
-		if comp.router != nil {
+		if comp.routingKey != nil {
 			p(`		Routed: true,`)
 		}
 		if len(comp.listeners) > 0 {
@@ -1269,7 +1809,13 @@ func (g *generator) generateRegisteredComponents(p printFn) {
 			p(`		Listeners: []string{%s},`, strings.Join(listeners, ", "))
 		}
 		if len(comp.noretry) > 0 {
-			p(`		NoRetry: []int{%s},`, noRetryString(comp))
+			p(`		NoRetry: []int{%s},`, methodIndices(comp, comp.noretry))
+		}
+		if len(comp.readonly) > 0 {
+			p(`		ReadOnly: []int{%s},`, methodIndices(comp, comp.readonly))
+		}
+		if len(comp.cacheable) > 0 {
+			p(`		Cacheable: []int{%s},`, methodIndices(comp, comp.cacheable))
 		}
 		p(`		LocalStubFn: %s,`, localStubFn)
 		p(`		ClientStubFn: %s,`, clientStubFn)
@@ -1281,13 +1827,13 @@ func (g *generator) generateRegisteredComponents(p printFn) {
 	p(`}`)
 }
 
-// noRetryString generates a string of the form "i_1, i_2, ... i_n" where the
-// individual elements are the indices of methods in comp.retry that should not
-// be retried.
-func noRetryString(comp *component) string {
-	list := make([]int, 0, len(comp.noretry))
+// methodIndices generates a string of the form "i_1, i_2, ... i_n" where the
+// individual elements are the indices of the methods of comp in the provided
+// set of method names (e.g., comp.noretry).
+func methodIndices(comp *component, methods map[string]struct{}) string {
+	list := make([]int, 0, len(methods))
 	for i, m := range comp.methods() {
-		if _, ok := comp.noretry[m.Name()]; ok {
+		if _, ok := methods[m.Name()]; ok {
 			list = append(list, i)
 		}
 	}
@@ -1299,6 +1845,33 @@ func noRetryString(comp *component) string {
 	return strings.Join(strs, ", ")
 }
 
+// versionedArgs returns the list of arguments of the provided method that
+// should be passed to codegen.CallVersioned, each preceded by a comma. It
+// returns the empty string if the method doesn't receive any versioned
+// entities. For example, given the following method
+//
+//	Update(context.Context, Account, *Account, int) error
+//
+// where Account has a `weaver:"version"` field, versionedArgs returns
+// ", &a0, a1".
+func (g *generator) versionedArgs(mt *types.Signature) string {
+	var b strings.Builder
+	for i := 1; i < mt.Params().Len(); i++ {
+		if mt.Variadic() && i == mt.Params().Len()-1 {
+			continue
+		}
+		t := mt.Params().At(i).Type()
+		if _, ok := versionField(t); ok {
+			fmt.Fprintf(&b, ", &a%d", i-1)
+		} else if ptr, ok := t.(*types.Pointer); ok {
+			if _, ok := versionField(ptr.Elem()); ok {
+				fmt.Fprintf(&b, ", a%d", i-1)
+			}
+		}
+	}
+	return b.String()
+}
+
 // generateLocalStubs generates code that creates stubs for the local components.
 func (g *generator) generateLocalStubs(p printFn) {
 	p(``)
@@ -1335,7 +1908,10 @@ func (g *generator) generateLocalStubs(p printFn) {
 			p(`	span := %s(ctx)`, g.trace().qualify("SpanFromContext"))
 			p(`	if span.SpanContext().IsValid() {`)
 			p(`		// Create a child span for this method.`)
-			p(`		ctx, span = s.tracer.Start(ctx, "%s.%s.%s", trace.WithSpanKind(trace.SpanKindInternal))`, g.pkg.Name, comp.intfName(), m.Name())
+			p(`		ctx, span = %s(ctx, s.tracer, %q, %q, "%s.%s.%s", trace.SpanKindInternal)`, g.codegen().qualify("StartSpan"), comp.fullIntfName(), m.Name(), g.pkg.Name, comp.intfName(), m.Name())
+			if args, ok := comp.traceArgs[m.Name()]; ok {
+				p(`		span.SetAttributes(%s)`, g.traceAttributes(args))
+			}
 			p(`		defer func() {`)
 			p(`			if err != nil {`)
 			p(`				span.RecordError(err)`)
@@ -1356,7 +1932,31 @@ func (g *generator) generateLocalStubs(p printFn) {
 				}
 			}
 			argList := b.String()
+			if versioned := g.versionedArgs(mt); versioned != "" {
+				b.Reset()
+				for i := 0; i < mt.Results().Len()-1; i++ { // Skip final error
+					fmt.Fprintf(&b, "r%d, ", i)
+				}
+				p(``)
+				p(`	// Check the versions of the versioned arguments, call the method, and`)
+				p(`	// advance the versions in one atomic step.`)
+				p(`	err = %s(ctx, s.impl, func() (err error) {`, g.codegen().qualify("CallVersioned"))
+				p(`	if %s(%q) {`, g.codegen().qualify("Intercepted"), comp.fullIntfName())
+				g.interceptCall(p, comp, m, "r", "err", argList)
+				p(`	} else {`)
+				p(`		%serr = s.impl.%s(%s)`, b.String(), m.Name(), argList)
+				p(`	}`)
+				p(`	return err`)
+				p(`	}%s)`, versioned)
+				p(`	return`)
+				p(`}`)
+				continue
+			}
 			p(``)
+			p(`	if %s(%q) {`, g.codegen().qualify("Intercepted"), comp.fullIntfName())
+			g.interceptCall(p, comp, m, "r", "err", argList)
+			p(`		return`)
+			p(`	}`)
 			p(`	return s.impl.%s(%s)`, m.Name(), argList)
 			p(`}`)
 		}
@@ -1411,7 +2011,7 @@ func (g *generator) generateClientStubs(p printFn) {
 			p(`	span := %s(ctx)`, g.trace().qualify("SpanFromContext"))
 			p(`	if span.SpanContext().IsValid() {`)
 			p(`		// Create a child span for this method.`)
-			p(`		ctx, span = s.stub.Tracer().Start(ctx, "%s.%s.%s", trace.WithSpanKind(trace.SpanKindClient))`, g.pkg.Name, comp.intfName(), m.Name())
+			p(`		ctx, span = %s(ctx, s.stub.Tracer(), %q, %q, "%s.%s.%s", trace.SpanKindClient)`, g.codegen().qualify("StartSpan"), comp.fullIntfName(), m.Name(), g.pkg.Name, comp.intfName(), m.Name())
 			p(`	}`)
 
 			// Handle cleanup.
@@ -1421,6 +2021,7 @@ func (g *generator) generateClientStubs(p printFn) {
 			p(`		if err == nil {`)
 			p(`			err = %s(recover())`, g.codegen().qualify("CatchPanics"))
 			p(`			if err != nil {`)
+			p(`				s.%sMetrics.SerializationError()`, notExported(m.Name()))
 			p(`				err = %s(%s, err)`, g.errorsPackage().qualify("Join"), g.weaver().qualify("RemoteCallError"))
 			p(`			}`)
 			p(`		}`)
@@ -1431,15 +2032,25 @@ func (g *generator) generateClientStubs(p printFn) {
 			p(`		}`)
 			p(`		span.End()`)
 			p(``)
+			p(`		// Log the call, if sampled.`)
+			p(`		%s(ctx, s.stub, %d, begin, err)`, g.codegen().qualify("LogAccess"), methodIndex[m.Name()])
 			p(`	}()`)
 			p(``)
 
 			preallocated := false
 			if mt.Params().Len() > 1 {
 				// Preallocate a perfectly sized buffer if possible.
+				//
+				// A variadic argument is sized element by element if needed.
+				// Unlike an arbitrary slice, the number of variadic arguments
+				// is typically small, so this is cheap.
 				canPreallocate := true
 				for i := 1; i < mt.Params().Len(); i++ { // Skip initial context.Context
-					if !g.preallocatable(mt.Params().At(i).Type()) {
+					at := mt.Params().At(i).Type()
+					if mt.Variadic() && i == mt.Params().Len()-1 && !g.preallocatable(at) {
+						at = at.(*types.Slice).Elem()
+					}
+					if !g.preallocatable(at) {
 						canPreallocate = false
 						break
 					}
@@ -1450,7 +2061,15 @@ func (g *generator) generateClientStubs(p printFn) {
 					p("	size := 0")
 					for i := 1; i < mt.Params().Len(); i++ {
 						at := mt.Params().At(i).Type()
-						p("	size += %s", g.size(fmt.Sprintf("a%d", i-1), at))
+						arg := fmt.Sprintf("a%d", i-1)
+						if mt.Variadic() && i == mt.Params().Len()-1 && !g.preallocatable(at) {
+							p("	size += 4")
+							p("	for _, v := range %s {", arg)
+							p("		size += %s", g.size("v", at.(*types.Slice).Elem()))
+							p("	}")
+							continue
+						}
+						p("	size += %s", g.size(arg, at))
 					}
 					p("	enc := %s", g.codegen().qualify("NewEncoder()"))
 					p("	enc.Reset(size)")
@@ -1470,6 +2089,13 @@ func (g *generator) generateClientStubs(p printFn) {
 			for i := 1; i < mt.Params().Len(); i++ { // Skip initial context.Context
 				at := mt.Params().At(i).Type()
 				arg := fmt.Sprintf("a%d", i-1)
+				if mt.Variadic() && i == mt.Params().Len()-1 {
+					// Encode zero variadic arguments as an empty, rather than
+					// nil, slice.
+					p(`	if %s == nil {`, arg)
+					p(`		%s = %s{}`, arg, g.tset.genTypeString(at))
+					p(`	}`)
+				}
 				p(`	%s`, g.encode("enc", arg, at))
 			}
 
@@ -1477,28 +2103,67 @@ func (g *generator) generateClientStubs(p printFn) {
 			if comp.routedMethods[m.Name()] {
 				p(``)
 				p(`	// Set the shardKey.`)
-				p(`     var r %s`, g.tset.genTypeString(comp.router))
-				n := mt.Params().Len()
-				args := make([]string, n)
-				args[0] = "ctx"
-				for i := 1; i < n; i++ {
-					args[i] = fmt.Sprintf("a%d", i-1)
+				if arg, ok := comp.routeArgs[m.Name()]; ok {
+					p(`	shardKey := _hash%s(%s)`, exported(comp.intfName()), arg)
+				} else {
+					p(`     var r %s`, g.tset.genTypeString(comp.router))
+					n := mt.Params().Len()
+					args := make([]string, n)
+					args[0] = "ctx"
+					for i := 1; i < n; i++ {
+						args[i] = fmt.Sprintf("a%d", i-1)
+					}
+					if mt.Variadic() {
+						args[n-1] += "..."
+					}
+					p(`	shardKey := _hash%s(r.%s(%s))`, exported(comp.intfName()), m.Name(), strings.Join(args, ", "))
 				}
-				p(`	shardKey := _hash%s(r.%s(%s))`, exported(comp.intfName()), m.Name(), strings.Join(args, ", "))
+				// Shard keys are uint64 hashes, which don't fit in an int64
+				// attribute, so the key is recorded as a decimal string.
+				strconv := g.tset.importPackage("strconv", "strconv")
+				p(`	if span.SpanContext().IsValid() && shardKey != 0 {`)
+				p(`		// Record the shard key to help debug hot shards.`)
+				p(`		span.SetAttributes(%s("serviceweaver.shard_key", %s(shardKey, 10)), %s("serviceweaver.method", "%s.%s.%s"))`,
+					g.attribute().qualify("String"), strconv.qualify("FormatUint"), g.attribute().qualify("String"), g.pkg.Name, comp.intfName(), m.Name())
+				p(`	}`)
 			} else {
 				p(`	var shardKey uint64`)
 			}
 
+			// Record the offset cursor, if there is one.
+			if i := offsetArg(mt); i >= 0 {
+				p(``)
+				p(`	if span.SpanContext().IsValid() {`)
+				p(`		// Record the offset to help debug resumed reads.`)
+				p(`		span.SetAttributes(%s("serviceweaver.offset", int64(a%d)))`, g.attribute().qualify("Int64"), i)
+				p(`	}`)
+			}
+
+			// Record the traced arguments, if there are any.
+			if args, ok := comp.traceArgs[m.Name()]; ok {
+				p(``)
+				p(`	if span.SpanContext().IsValid() {`)
+				p(`		// Record the arguments marked //weaver:trace_args.`)
+				p(`		span.SetAttributes(%s)`, g.traceAttributes(args))
+				p(`	}`)
+			}
+
 			// Invoke call.Run.
 			p(``)
-			p(`	// Call the remote method.`)
+			p(`	// Call the remote method, retrying it while it returns a retryable error.`)
 			data := "nil"
 			if mt.Params().Len() > 1 {
 				data = "enc.Data()"
 				p(`	requestBytes = len(enc.Data())`)
+				p(`	defer enc.Release()`)
+			}
+			if streamResult(mt) >= 0 {
+				p(`	ctx = %s(ctx)`, g.codegen().qualify("WithStream"))
 			}
 			p(`	var results []byte`)
-			p(`	results, err = s.stub.Run(ctx, %d, %s, shardKey)`, methodIndex[m.Name()], data)
+			p(`	var retrier %s`, g.codegen().qualify("Retrier"))
+			p(`	for {`)
+			p(`	results, err = %s(ctx, s.stub, &begin, %d, %s, shardKey)`, g.codegen().qualify("Run"), methodIndex[m.Name()], data)
 			p(`	replyBytes = len(results)`)
 			p(`	if err != nil {`)
 			p(`		err = %s(%s, err)`, g.errorsPackage().qualify("Join"), g.weaver().qualify("RemoteCallError"))
@@ -1509,7 +2174,7 @@ func (g *generator) generateClientStubs(p printFn) {
 			b.Reset()
 			p(``)
 			p(`	// Decode the results.`)
-			p(`	dec := %s(results)`, g.codegen().qualify("NewDecoder"))
+			p(`	dec := %s(ctx, results)`, g.codegen().qualify("NewResultDecoder"))
 			for i := 0; i < mt.Results().Len()-1; i++ { // Skip final error
 				rt := mt.Results().At(i).Type()
 				res := fmt.Sprintf("r%d", i)
@@ -1522,13 +2187,23 @@ func (g *generator) generateClientStubs(p printFn) {
 					p(`	var %s %s`, tmp, g.tset.genTypeString(x.Elem()))
 					p(`	%s`, g.decode("dec", ref(tmp), x.Elem()))
 					p(`	%s = %s`, res, ref(tmp))
+				} else if isReadCloser(rt) {
+					p(`	%s = %s(ctx, s.stub, %d, shardKey, dec)`, res, g.codegen().qualify("DecodeStream"), methodIndex[m.Name()])
 				} else {
 					p(`	%s`, g.decode("dec", ref(res), rt))
 				}
 			}
 			p(`	err = dec.Error()`)
-
-			p(`	return`)
+			if _, ok := comp.cacheable[m.Name()]; ok {
+				p(`	if err == nil {`)
+				p(`		// Cache the reply, if enabled, now that we know the call succeeded.`)
+				p(`		%s(s.stub, %d, %s, results)`, g.codegen().qualify("CacheReply"), methodIndex[m.Name()], data)
+				p(`	}`)
+			}
+			p(`	if !retrier.Retry(ctx, err) {`)
+			p(`		return`)
+			p(`	}`)
+			p(`	}`)
 			p(`}`)
 		}
 	}
@@ -1582,7 +2257,7 @@ func (g *generator) isWeaverEncoded(t types.Type) bool {
 		return false
 	}
 
-	switch x := t.(type) {
+	switch x := unalias(t).(type) {
 	case *types.Basic:
 		switch x.Kind() {
 		case types.Bool,
@@ -1649,12 +2324,14 @@ func (g *generator) size(e string, t types.Type) string {
 	// size(e: map[k]v) = 4 + len(e) * (fixedsize(k) + fixedsize(v))
 	// size(e: struct{...}) = serviceweaver_size_struct_XXXXXXXX(e)
 	// size(e: weaver.AutoMarshal) = 0
+	// size(e: weaver.LatLng) = 8
+	// size(e: weaver.BBox) = 16
 	// size(e: type t struct{...}) = serviceweaver_size_t(e)
 	// size(e: type t u) = size(e: u)
 
 	var f func(e string, t types.Type) string
 	f = func(e string, t types.Type) string {
-		switch x := t.(type) {
+		switch x := unalias(t).(type) {
 		case *types.Basic:
 			switch x.Kind() {
 			case types.Bool,
@@ -1694,6 +2371,8 @@ func (g *generator) size(e string, t types.Type) string {
 				// TODO(mwhittaker): This yields a `size += 0` line in the
 				// generated code. Don't produce those lines.
 				return "0"
+			} else if isWeaverLatLng(x) || isWeaverBBox(x) {
+				return strconv.Itoa(g.tset.sizeOfType(t))
 			} else if _, ok := x.Underlying().(*types.Struct); ok {
 				return fmt.Sprintf("serviceweaver_size_%s(&%s)", sanitize(t), e)
 			}
@@ -1738,7 +2417,7 @@ func (g *generator) size(e string, t types.Type) string {
 func (g *generator) findSizeFuncNeededs(t types.Type) {
 	var f func(t types.Type)
 	f = func(t types.Type) {
-		switch x := t.(type) {
+		switch x := unalias(t).(type) {
 		case *types.Pointer:
 			g.sizeFuncNeeded.Set(t, true)
 			f(x.Elem())
@@ -1760,7 +2439,7 @@ func (g *generator) findSizeFuncNeededs(t types.Type) {
 			}
 
 		case *types.Named:
-			if isWeaverAutoMarshal(x) {
+			if isWeaverAutoMarshal(x) || isWeaverLatLng(x) || isWeaverBBox(x) {
 				return
 			}
 			if s, ok := x.Underlying().(*types.Struct); ok {
@@ -1784,7 +2463,7 @@ func (g *generator) generateSizeFunction(p printFn, t types.Type) {
 	p("// serviceweaver_size_%s returns the size (in bytes) of the serialization", sanitize(t))
 	p("// of the provided type.")
 
-	switch x := t.(type) {
+	switch x := unalias(t).(type) {
 	case *types.Pointer:
 		// For example:
 		//
@@ -1881,18 +2560,47 @@ func (g *generator) generateServerStubs(p printFn) {
 			p(`func (s %s) %s(ctx context.Context, args []byte) (res []byte, err error) {`,
 				stub, notExported(m.Name()))
 
+			// Declare the arguments before the deferred function below, which
+			// reports them to the panic handlers.
+			b.Reset()
+			if mt.Params().Len() > 1 {
+				p(`	// Arguments, reported to panic handlers if the call panics.`)
+			}
+			for i := 1; i < mt.Params().Len(); i++ { // Skip initial context.Context
+				p(`	var a%d %s`, i-1, g.tset.genTypeString(mt.Params().At(i).Type()))
+				fmt.Fprintf(&b, ", a%d", i-1)
+			}
+			if mt.Params().Len() > 1 {
+				p(``)
+			}
+
 			// Handle errors triggered during execution.
 			p(`	// Catch and return any panics detected during encoding/decoding/rpc.`)
 			p(`	defer func() {`)
 			p(`		if err == nil {`)
-			p(`			err = %s(recover())`, g.codegen().qualify("CatchPanics"))
+			if mt.Params().Len() > 1 {
+				p(`			if r := recover(); r != nil {`)
+				p(`				err = %s(%q, %q, r%s)`, g.codegen().qualify("CatchServerPanics"), comp.fullIntfName(), m.Name(), b.String())
+				p(`			}`)
+			} else {
+				p(`			err = %s(%q, %q, recover())`, g.codegen().qualify("CatchServerPanics"), comp.fullIntfName(), m.Name())
+			}
 			p(`		}`)
 			p(`	}()`)
 
+			if streamResult(mt) >= 0 {
+				p(``)
+				p(`	// Serve the request for the next chunk of a streamed result, if it is one.`)
+				p(`	if res, ok := %s(ctx, args); ok {`, g.codegen().qualify("ServeStream"))
+				p(`		return res, nil`)
+				p(`	}`)
+			}
+
 			if mt.Params().Len() > 1 {
 				p(``)
 				p(`	// Decode arguments.`)
 				p(`	dec := %s(args)`, g.codegen().qualify("NewDecoder"))
+				p(`	dec.SetMaxAlloc(%s)`, g.codegen().qualify("MaxServerAlloc"))
 			}
 			b.Reset()
 			for i := 1; i < mt.Params().Len(); i++ { // Skip initial context.Context
@@ -1906,9 +2614,8 @@ func (g *generator) generateServerStubs(p printFn) {
 					tmp := fmt.Sprintf("tmp%d", i)
 					p(`	var %s %s`, tmp, g.tset.genTypeString(x.Elem()))
 					p(`	%s`, g.decode("dec", ref(tmp), x.Elem()))
-					p(`	%s := %s`, arg, ref(tmp))
+					p(`	%s = %s`, arg, ref(tmp))
 				} else {
-					p(`	var %s %s`, arg, g.tset.genTypeString(at))
 					p(`	%s`, g.decode("dec", ref(arg), at))
 				}
 			}
@@ -1925,11 +2632,21 @@ func (g *generator) generateServerStubs(p printFn) {
 			argList := b.String()
 
 			// Add load, if needed.
-			if comp.routedMethods[m.Name()] {
+			if arg, ok := comp.routeArgs[m.Name()]; ok {
+				p(`	s.addLoad(_hash%s(%s), 1.0)`, exported(comp.intfName()), arg)
+			} else if comp.routedMethods[m.Name()] {
 				p(`     var r %s`, g.tset.genTypeString(comp.router))
 				p(`	s.addLoad(_hash%s(r.%s(%s)), 1.0)`, exported(comp.intfName()), m.Name(), argList)
 			}
 
+			// Shed the call, after reporting its load, if the runtime marked
+			// it as shed.
+			p(``)
+			p(`	// Reject the call if the component is overloaded.`)
+			p(`	if err := %s(ctx); err != nil {`, g.codegen().qualify("CheckShed"))
+			p(`		return nil, err`)
+			p(`	}`)
+
 			b.Reset()
 			p(``)
 			p(`	// TODO(rgrandl): The deferred function above will recover from panics in the`)
@@ -1950,7 +2667,24 @@ func (g *generator) generateServerStubs(p printFn) {
 				res = fmt.Sprintf("%s, appErr", b.String())
 			}
 
-			p(`	%s := s.impl.%s(%s)`, res, m.Name(), argList)
+			for i := 0; i < mt.Results().Len()-1; i++ { // Skip final error
+				p(`	var r%d %s`, i, g.tset.genTypeString(mt.Results().At(i).Type()))
+			}
+			versioned := g.versionedArgs(mt)
+			if versioned != "" {
+				p(`	appErr := %s(ctx, s.impl, func() (appErr error) {`, g.codegen().qualify("CallVersioned"))
+			} else {
+				p(`	var appErr error`)
+			}
+			p(`	if %s(%q) {`, g.codegen().qualify("Intercepted"), comp.fullIntfName())
+			g.interceptCall(p, comp, m, "r", "appErr", argList)
+			p(`	} else {`)
+			p(`		%s = s.impl.%s(%s)`, res, m.Name(), argList)
+			p(`	}`)
+			if versioned != "" {
+				p(`	return appErr`)
+				p(`	}%s)`, versioned)
+			}
 
 			p(``)
 			p(`	// Encode the results.`)
@@ -1960,6 +2694,10 @@ func (g *generator) generateServerStubs(p printFn) {
 			for i := 0; i < mt.Results().Len()-1; i++ { // Skip final error
 				rt := mt.Results().At(i).Type()
 				res := fmt.Sprintf("r%d", i)
+				if isReadCloser(rt) {
+					p(`	%s(ctx, enc, %s, appErr)`, g.codegen().qualify("EncodeStream"), res)
+					continue
+				}
 				p(`	%s`, g.encode("enc", res, rt))
 			}
 			p(`	enc.Error(appErr)`)
@@ -1969,6 +2707,22 @@ func (g *generator) generateServerStubs(p printFn) {
 	}
 }
 
+// interceptCall generates code that calls method m of the component
+// implementation through the interceptors registered for comp. The results of
+// the call are assigned to variables with the provided prefix (e.g., r0, r1),
+// and the returned error is assigned to errVar.
+func (g *generator) interceptCall(p printFn, comp *component, m *types.Func, prefix, errVar, argList string) {
+	mt := m.Type().(*types.Signature)
+	p(`		%s = %s(ctx, %q, %q, func(ctx context.Context) (err error) {`, errVar, g.codegen().qualify("Intercept"), comp.fullIntfName(), m.Name())
+	var b strings.Builder
+	for i := 0; i < mt.Results().Len()-1; i++ { // Skip final error
+		fmt.Fprintf(&b, "%s%d, ", prefix, i)
+	}
+	p(`			%serr = s.impl.%s(%s)`, b.String(), m.Name(), argList)
+	p(`			return err`)
+	p(`		})`)
+}
+
 // generateReflectStubs generates code for reflect stubs. A reflect stub
 // represents all component method arguments and results as type any and uses a
 // provided caller function to execute the method call.
@@ -2013,6 +2767,104 @@ func (g *generator) generateReflectStubs(p printFn) {
 	}
 }
 
+// generateHTTPHandlers generates, for every component, a function that
+// returns an http.Handler serving the component's methods as JSON over HTTP.
+// For example, for a component interface Foo, it generates:
+//
+//	func NewFooHTTPHandler(comp Foo) http.Handler {
+//	    return codegen.HTTPHandler(map[string]codegen.HTTPMethod{
+//	        "Bar": func(ctx context.Context, args []json.RawMessage) (codegen.HTTPResult, error) {
+//	            var a0 string
+//	            if err := codegen.DecodeHTTPArgs(args, &a0); err != nil {
+//	                return codegen.HTTPResult{}, err
+//	            }
+//	            r0, err := comp.Bar(ctx, a0)
+//	            return codegen.HTTPResult{Value: r0, Encode: func(enc *codegen.Encoder) {
+//	                enc.String(r0)
+//	            }}, err
+//	        },
+//	    }, weaver.RemoteCallError)
+//	}
+func (g *generator) generateHTTPHandlers(p printFn) {
+	p(``)
+	p(``)
+	p(`// HTTP handler implementations.`)
+
+	ts := g.tset.genTypeString
+	http := g.tset.importPackage("net/http", "http")
+	json := g.tset.importPackage("encoding/json", "json")
+	context := g.tset.importPackage("context", "context")
+	result := g.codegen().qualify("HTTPResult")
+	for _, comp := range g.components {
+		if comp.isMain {
+			continue
+		}
+		name := comp.intfName()
+		fn := "New" + name + "HTTPHandler"
+		if !isExported(comp.intf) {
+			fn = "new" + exported(name) + "HTTPHandler"
+		}
+		p(``)
+		p(`// %s returns an http.Handler that serves the methods`, fn)
+		p(`// of the provided %s as JSON over HTTP. Method M is served at "POST /M".`, name)
+		p(`// The body of a request is a JSON array of the method's arguments, excluding`)
+		p(`// the context, and the body of a response is the encoding of the method's`)
+		p(`// result in the content type negotiated with the client, JSON by default.`)
+		p(`// Methods that return an io.ReadCloser are not served.`)
+		p(`// See codegen.HTTPHandler for details.`)
+		p(`func %s(comp %s) %s {`, fn, ts(comp.intf), http.qualify("Handler"))
+		p(`	return %s(map[string]%s{`, g.codegen().qualify("HTTPHandler"), g.codegen().qualify("HTTPMethod"))
+		for _, m := range comp.methods() {
+			mt := m.Type().(*types.Signature)
+			if streamResult(mt) >= 0 {
+				// Streamed results aren't served over HTTP.
+				continue
+			}
+			p(`		%q: func(ctx %s, args []%s) (%s, error) {`, m.Name(), context.qualify("Context"), json.qualify("RawMessage"), result)
+
+			// Decode the arguments.
+			var args, refs []string
+			for i := 1; i < mt.Params().Len(); i++ {
+				p(`			var a%d %s`, i-1, ts(mt.Params().At(i).Type()))
+				refs = append(refs, fmt.Sprintf("&a%d", i-1))
+				if mt.Variadic() && i == mt.Params().Len()-1 {
+					args = append(args, fmt.Sprintf("a%d...", i-1))
+				} else {
+					args = append(args, fmt.Sprintf("a%d", i-1))
+				}
+			}
+			p(`			if err := %s(%s); err != nil {`, g.codegen().qualify("DecodeHTTPArgs"), strings.Join(append([]string{"args"}, refs...), ", "))
+			p(`				return %s{}, err`, result)
+			p(`			}`)
+
+			// Call the method.
+			call := fmt.Sprintf("comp.%s(%s)", m.Name(), strings.Join(append([]string{"ctx"}, args...), ", "))
+			var results []string
+			for i := 0; i < mt.Results().Len()-1; i++ {
+				results = append(results, fmt.Sprintf("r%d", i))
+			}
+			if len(results) == 0 {
+				p(`			return %s{}, %s`, result, call)
+				p(`		},`)
+				continue
+			}
+			p(`			%s, err := %s`, strings.Join(results, ", "), call)
+			value := results[0]
+			if len(results) > 1 {
+				value = fmt.Sprintf("[]any{%s}", strings.Join(results, ", "))
+			}
+			p(`			return %s{Value: %s, Encode: func(enc *%s) {`, result, value, g.codegen().qualify("Encoder"))
+			for i, r := range results {
+				p(`				%s`, g.encode("enc", r, mt.Results().At(i).Type()))
+			}
+			p(`			}}, err`)
+			p(`		},`)
+		}
+		p(`	}, %s)`, g.weaver().qualify("RemoteCallError"))
+		p(`}`)
+	}
+}
+
 // generateAutoMarshalMethods generates WeaverMarshal and WeaverUnmarshal methods
 // for any types that declares itself as weaver.AutoMarshal.
 func (g *generator) generateAutoMarshalMethods(p printFn) {
@@ -2067,12 +2919,25 @@ func (g *generator) generateAutoMarshalMethods(p printFn) {
 		p(`	if x == nil {`)
 		p(`		panic(%s("%s.WeaverMarshal: nil receiver"))`, fmt.qualify("Errorf"), ts(t))
 		p(`	}`)
+		tagged := g.isTagged(t.(*types.Named))
 		for i := 0; i < s.NumFields(); i++ {
 			fi := s.Field(i)
-			if !isWeaverAutoMarshal(fi.Type()) {
-				p(`	%s`, g.encode("enc", "x."+fi.Name(), fi.Type()))
+			if isWeaverAutoMarshal(fi.Type()) || fi.Name() == "_" {
+				continue
+			}
+			if tag := weaverTag(s, i); tag == "" || tag == "version" {
 				innerTypes = append(innerTypes, fi.Type())
 			}
+			if tagged {
+				p(`	%s(enc, %d, func(enc *%s) {`, g.codegen().qualify("EncodeTaggedField"), taggedFieldNumber(s, i), g.codegen().qualify("Encoder"))
+				p(`		%s`, g.encodeField(s, i))
+				p(`	})`)
+			} else {
+				p(`	%s`, g.encodeField(s, i))
+			}
+		}
+		if tagged {
+			p(`	%s(enc)`, g.codegen().qualify("EncodeTaggedEnd"))
 		}
 		p(`}`)
 
@@ -2082,14 +2947,63 @@ func (g *generator) generateAutoMarshalMethods(p printFn) {
 		p(`	if x == nil {`)
 		p(`		panic(%s("%s.WeaverUnmarshal: nil receiver"))`, fmt.qualify("Errorf"), ts(t))
 		p(`	}`)
+		if tagged {
+			// Fields missing from the encoding are left as zero values.
+			p(`	*x = %s{}`, ts(t))
+			p(`	%s(dec, func(field uint32, dec *%s) bool {`, g.codegen().qualify("DecodeTaggedFields"), g.codegen().qualify("Decoder"))
+			p(`		switch field {`)
+		}
 		for i := 0; i < s.NumFields(); i++ {
 			fi := s.Field(i)
-			if !isWeaverAutoMarshal(fi.Type()) {
-				p(`	%s`, g.decode("dec", "&x."+fi.Name(), fi.Type()))
+			if isWeaverAutoMarshal(fi.Type()) || fi.Name() == "_" {
+				continue
 			}
+			if tagged {
+				p(`		case %d:`, taggedFieldNumber(s, i))
+				p(`			%s`, g.decodeField(s, i))
+			} else {
+				p(`	%s`, g.decodeField(s, i))
+			}
+		}
+		if tagged {
+			p(`		default:`)
+			p(`			return false`)
+			p(`		}`)
+			p(`		return true`)
+			p(`	})`)
 		}
 		p(`}`)
 
+		// Generate WeaverVersion method, if needed.
+		if field, ok := versionField(t); ok {
+			p(``)
+			p(`func (x *%s) WeaverVersion() uint64 {`, ts(t))
+			p(`	return uint64(x.%s)`, field)
+			p(`}`)
+		}
+
+		// Generate DebugString method, unless the type already has a
+		// DebugString field or method.
+		if obj, _, _ := types.LookupFieldOrMethod(t, true, g.tset.pkg.Types, "DebugString"); obj == nil {
+			p(``)
+			p(`// DebugString returns a JSON rendering of the fields of x that are`)
+			p(`// serialized by WeaverMarshal.`)
+			p(`func (x *%s) DebugString() string {`, ts(t))
+			p(`	if x == nil {`)
+			p(`		return "null"`)
+			p(`	}`)
+			p(`	return %s(`, g.codegen().qualify("DebugString"))
+			for i := 0; i < s.NumFields(); i++ {
+				fi := s.Field(i)
+				if isWeaverAutoMarshal(fi.Type()) || fi.Name() == "_" {
+					continue
+				}
+				p(`		%s{Name: %q, Value: x.%s},`, g.codegen().qualify("DebugField"), fi.Name(), fi.Name())
+			}
+			p(`	)`)
+			p(`}`)
+		}
+
 		// Generate encoding/decoding methods for any inner types.
 		for _, inner := range innerTypes {
 			g.generateEncDecMethodsFor(p, inner)
@@ -2105,6 +3019,77 @@ func (g *generator) generateAutoMarshalMethods(p printFn) {
 	}
 }
 
+// encodeField returns a statement that encodes field i of the AutoMarshal
+// struct s, stored in x, into enc.
+func (g *generator) encodeField(s *types.Struct, i int) string {
+	fi := s.Field(i)
+	switch weaverTag(s, i) {
+	case "rle":
+		return g.encodeRLE("enc", "x."+fi.Name(), fi.Type())
+	case "bitset":
+		return fmt.Sprintf("%s(enc, x.%s)", g.codegen().qualify("EncodeBitset"), fi.Name())
+	case "tail":
+		return fmt.Sprintf("%s(enc, x.%s)", g.codegen().qualify("EncodeTail"), fi.Name())
+	case "gorilla":
+		return g.encodeGorilla("enc", "x."+fi.Name(), fi.Type())
+	default:
+		return g.encode("enc", "x."+fi.Name(), fi.Type())
+	}
+}
+
+// decodeField returns a statement that decodes field i of the AutoMarshal
+// struct s, stored in x, from dec.
+func (g *generator) decodeField(s *types.Struct, i int) string {
+	fi := s.Field(i)
+	switch weaverTag(s, i) {
+	case "rle":
+		return g.decodeRLE("dec", "&x."+fi.Name(), fi.Type())
+	case "bitset":
+		return fmt.Sprintf("x.%s = %s(dec)", fi.Name(), g.codegen().qualify("DecodeBitset"))
+	case "tail":
+		return fmt.Sprintf("x.%s = %s(dec)", fi.Name(), g.codegen().qualify("DecodeTail"))
+	case "gorilla":
+		return g.decodeGorilla("dec", "&x."+fi.Name(), fi.Type())
+	default:
+		return g.decode("dec", "&x."+fi.Name(), fi.Type())
+	}
+}
+
+// taggedFieldNumber returns the field number of field i of the tagged struct
+// s, from its `weaver:"tag=N"` struct tag. The field numbers of tagged structs
+// are checked by findTagged.
+func taggedFieldNumber(s *types.Struct, i int) int {
+	tag, _ := fieldNumberTag(s, i)
+	n, _ := strconv.Atoi(tag)
+	return n
+}
+
+// isTagged returns whether the provided AutoMarshal struct is marked
+// //weaver:tagged. The structs declared in other packages are looked up in the
+// files that declare them, which are parsed anew.
+func (g *generator) isTagged(t *types.Named) bool {
+	if t.Obj().Pkg() == g.pkg.Types {
+		return slices.Contains(g.tagged, t)
+	}
+	pos := g.pkg.Fset.Position(t.Obj().Pos())
+	if !pos.IsValid() {
+		return false
+	}
+	if g.parsed == nil {
+		g.parsedFset, g.parsed = token.NewFileSet(), map[string]*ast.File{}
+	}
+	f, ok := g.parsed[pos.Filename]
+	if !ok {
+		var err error
+		f, err = parser.ParseFile(g.parsedFset, pos.Filename, nil, parser.ParseComments)
+		if err != nil {
+			return false
+		}
+		g.parsed[pos.Filename] = f
+	}
+	return hasTypeDirective(g.parsedFset, f, t.Obj().Name(), pos.Line, "tagged")
+}
+
 // generateRouterMethods generates methods for router types.
 func (g *generator) generateRouterMethods(p printFn) {
 	printed := false
@@ -2124,6 +3109,20 @@ func (g *generator) generateRouterMethods(p printFn) {
 func (g *generator) generateRouterMethodsFor(p printFn, comp *component, t types.Type) {
 	p(`// _hash%s returns a 64 bit hash of the provided value.`, exported(comp.intfName()))
 	p(`func _hash%s(r %s) uint64 {`, exported(comp.intfName()), g.tset.genTypeString(t))
+	if implementsShardKey(t) {
+		// The routing key computes its own shard key.
+		p(`	return %s(r.Shard())`, g.codegen().qualify("ClampShardKey"))
+		p(`}`)
+		p(``)
+		p(`// _orderedCode%s returns an order-preserving serialization of the provided value.`, exported(comp.intfName()))
+		p(`func _orderedCode%s(r %s) %s {`, exported(comp.intfName()), g.tset.genTypeString(t), g.codegen().qualify("OrderedCode"))
+		p(`	var enc %s`, g.codegen().qualify("OrderedEncoder"))
+		p(`	enc.WriteUint64(r.Shard())`)
+		p(`	return enc.Encode()`)
+		p(`}`)
+		return
+	}
+
 	p(`	var h %s`, g.codegen().qualify("Hasher"))
 	if isPrimitiveRouter(t.Underlying()) {
 		tname := t.Underlying().String()
@@ -2198,17 +3197,28 @@ func (g *generator) encode(stub, e string, t types.Type) string {
 	// shorthand for sanitize(t). under(t) is the underlying type of t.
 	//
 	// enc(stub, e: basic type t) = stub.[t](e)
+	// enc(stub, e: *big.Int) = stub.BigInt(e)
+	// enc(stub, e: *big.Rat) = stub.BigRat(e)
 	// enc(stub, e: *t) = serviceweaver_enc_[*t](&stub, e)
+	// enc(stub, e: [16]byte) = stub.Bytes16(e)
 	// enc(stub, e: [N]t) = serviceweaver_enc_[[N]t](&stub, &e)
 	// enc(stub, e: []t) = serviceweaver_enc_[[]t](&stub, e)
 	// enc(stub, e: map[k]v) = serviceweaver_enc_[map[k]v](&stub, e)
 	// enc(stub, e: struct{...}) = serviceweaver_enc_[struct{...}](&stub, &e)
+	// enc(stub, e: interface{}) = stub.Any(e)
+	// enc(stub, e: error) = stub.Error(e)
+	// enc(stub, e: t) = codegen.EncodeWithMarshaler[t](stub, e)   // t has a registered marshaler
+	// enc(stub, e: type t u) = stub.Bytes16(e)                // t is a well-known UUID type
 	// enc(stub, e: type t u) = stub.EncodeProto(&e)           // t implements proto.Message
 	// enc(stub, e: type t u) = (e).WeaverMarshal(stub)         // t implements AutoMarshal
 	// enc(stub, e: type t u) = stub.EncodeBinaryMarshaler(&e) // t implements BinaryMarshaler
 	// enc(stub, e: type t u) = serviceweaver_enc_[t](&stub, &e)       // under(u) = struct{...}
+	// enc(stub, e: type t u) = serviceweaver_enc_[t](&stub, e)        // t is a sealed union
 	// enc(stub, e: type t u) = enc(&stub, under(t)(e))        // otherwise
-	switch x := t.(type) {
+	if g.tset.hasMarshaler(t) {
+		return fmt.Sprintf("%s[%s](%s, %s)", g.codegen().qualify("EncodeWithMarshaler"), g.tset.genTypeString(t), stub, e)
+	}
+	switch x := unalias(t).(type) {
 	case *types.Basic:
 		switch x.Kind() {
 		case types.Bool,
@@ -2223,9 +3233,18 @@ func (g *generator) encode(stub, e string, t types.Type) string {
 		}
 
 	case *types.Pointer:
+		if isBigIntPtr(x) {
+			return fmt.Sprintf("%s.BigInt(%s)", stub, e)
+		}
+		if isBigRatPtr(x) {
+			return fmt.Sprintf("%s.BigRat(%s)", stub, e)
+		}
 		return fmt.Sprintf("%s(%s, %s)", f(x), stub, e)
 
 	case *types.Array:
+		if isBytes16(x) {
+			return fmt.Sprintf("%s.Bytes16(%s)", stub, e)
+		}
 		return fmt.Sprintf("%s(%s, %s)", f(x), stub, ref(e))
 
 	case *types.Slice:
@@ -2237,7 +3256,16 @@ func (g *generator) encode(stub, e string, t types.Type) string {
 	case *types.Struct:
 		return fmt.Sprintf("%s(%s, %s)", f(x), stub, ref(e))
 
+	case *types.Interface:
+		return fmt.Sprintf("%s.Any(%s)", stub, e)
+
 	case *types.Named:
+		if isError(x) {
+			return fmt.Sprintf("%s.Error(%s)", stub, e)
+		}
+		if isUUID(x) {
+			return fmt.Sprintf("%s.Bytes16(%s)", stub, e)
+		}
 		if g.tset.isProto(x) {
 			return fmt.Sprintf("%s.EncodeProto(%s)", stub, ref(e))
 		}
@@ -2247,6 +3275,9 @@ func (g *generator) encode(stub, e string, t types.Type) string {
 		if g.tset.hasMarshalBinary(x) {
 			return fmt.Sprintf("%s.EncodeBinaryMarshaler(%s)", stub, ref(e))
 		}
+		if _, ok := g.tset.unionVariants(x); ok {
+			return fmt.Sprintf("%s(%s, %s)", f(x), stub, e)
+		}
 		under := x.Underlying()
 		if _, ok := under.(*types.Struct); ok {
 			return fmt.Sprintf("%s(%s, %s)", f(x), stub, ref(e))
@@ -2254,6 +3285,10 @@ func (g *generator) encode(stub, e string, t types.Type) string {
 		return g.encode(stub, fmt.Sprintf("(%s)(%s)", g.tset.genTypeString(x.Underlying()), e), under)
 
 	default:
+		if isEmptyInterface(t) {
+			// E.g., the alias any.
+			return fmt.Sprintf("%s.Any(%s)", stub, e)
+		}
 		panic(fmt.Sprintf("encode: unexpected expression: %v (type %T)", e, t))
 	}
 }
@@ -2271,17 +3306,29 @@ func (g *generator) decode(stub, v string, t types.Type) string {
 	// the underlying type of t.
 	//
 	// dec(stub, v: basic type t) = *v := stub.[t](e)
+	// dec(stub, v: *big.Int) = *v := stub.BigInt()
+	// dec(stub, v: *big.Rat) = *v := stub.BigRat()
 	// dec(stub, v: *t) = *v := serviceweaver_dec_[*t](&stub)
+	// dec(stub, v: [16]byte) = *v = stub.Bytes16()
 	// dec(stub, v: [N]t) = serviceweaver_dec_[[N]t](stub, v)
 	// dec(stub, v: []t) = v := *v = serviceweaver_dec_[[]t](stub)
 	// dec(stub, v: map[k]v) = *v := serviceweaver_dec_[map[k]v](stub)
 	// dec(stub, v: struct{...}) = serviceweaver_dec_[struct{...}](stub, &v)
+	// dec(stub, v: interface{}) = *v = stub.Any()
+	// dec(stub, v: error) = *v = stub.Error()
+	// dec(stub, v: t) = *v = codegen.DecodeWithMarshaler[t](stub)   // t has a registered marshaler
+	// dec(stub, v: type t u) = *v = stub.Bytes16()             // t is a well-known UUID type
 	// dec(stub, v: type t u) = stub.DecodeProto(v)             // t implements proto.Message
 	// dec(stub, v: type t u) = (v).WeaverUnmarshal(stub)        // t implements AutoMarshal
 	// dec(stub, v: type t u) = stub.DecodeBinaryUnmarshaler(v) // t implements BinaryUnmarshaler
 	// dec(stub, v: type t u) = serviceweaver_dec_[t](stub, v)          // under(u) = struct{...}
+	// dec(stub, v: type t u) = *v = serviceweaver_dec_[t](stub)        // t is a sealed union
+	// dec(stub, v: type t u) = *v = serviceweaver_dec_[t](stub)        // t is an enum
 	// dec(stub, v: type t u) = dec(stub, (*under(t))(v))       // otherwise
-	switch x := t.(type) {
+	if g.tset.hasMarshaler(t) {
+		return fmt.Sprintf("%s = %s[%s](%s)", deref(v), g.codegen().qualify("DecodeWithMarshaler"), g.tset.genTypeString(t), stub)
+	}
+	switch x := unalias(t).(type) {
 	case *types.Basic:
 		switch x.Kind() {
 		case types.Bool,
@@ -2296,9 +3343,18 @@ func (g *generator) decode(stub, v string, t types.Type) string {
 		}
 
 	case *types.Pointer:
+		if isBigIntPtr(x) {
+			return fmt.Sprintf("%s = %s.BigInt()", deref(v), stub)
+		}
+		if isBigRatPtr(x) {
+			return fmt.Sprintf("%s = %s.BigRat()", deref(v), stub)
+		}
 		return fmt.Sprintf("%s = %s(%s)", deref(v), f(x), stub)
 
 	case *types.Array:
+		if isBytes16(x) {
+			return fmt.Sprintf("%s = %s.Bytes16()", deref(v), stub)
+		}
 		return fmt.Sprintf("%s(%s, %s)", f(x), stub, v)
 
 	case *types.Slice:
@@ -2310,7 +3366,16 @@ func (g *generator) decode(stub, v string, t types.Type) string {
 	case *types.Struct:
 		return fmt.Sprintf("%s(%s, %s)", f(x), stub, v)
 
+	case *types.Interface:
+		return fmt.Sprintf("%s = %s.Any()", deref(v), stub)
+
 	case *types.Named:
+		if isError(x) {
+			return fmt.Sprintf("%s = %s.Error()", deref(v), stub)
+		}
+		if isUUID(x) {
+			return fmt.Sprintf("%s = %s.Bytes16()", deref(v), stub)
+		}
 		if g.tset.isProto(x) {
 			return fmt.Sprintf("%s.DecodeProto(%s)", stub, v)
 		}
@@ -2320,6 +3385,12 @@ func (g *generator) decode(stub, v string, t types.Type) string {
 		if g.tset.hasMarshalBinary(x) {
 			return fmt.Sprintf("%s.DecodeBinaryUnmarshaler(%s)", stub, v)
 		}
+		if _, ok := g.tset.unionVariants(x); ok {
+			return fmt.Sprintf("%s = %s(%s)", deref(v), f(x), stub)
+		}
+		if g.isEnum(x) {
+			return fmt.Sprintf("%s = %s(%s)", deref(v), f(x), stub)
+		}
 		under := x.Underlying()
 		if _, ok := under.(*types.Struct); ok {
 			return fmt.Sprintf("%s(%s, %s)", f(x), stub, v)
@@ -2327,10 +3398,54 @@ func (g *generator) decode(stub, v string, t types.Type) string {
 		return g.decode(stub, fmt.Sprintf("(*%s)(%s)", g.tset.genTypeString(x.Underlying()), v), under)
 
 	default:
+		if isEmptyInterface(t) {
+			// E.g., the alias any.
+			return fmt.Sprintf("%s = %s.Any()", deref(v), stub)
+		}
 		panic(fmt.Sprintf("encode: unexpected expression: %v (type %T)", v, t))
 	}
 }
 
+// encodeRLE returns a statement that run-length encodes the expression e of
+// type t into stub of type *codegen.Encoder. t must be a slice of primitive
+// types. For example, encodeRLE("enc", "x", []int) is
+// "codegen.EncodeRLE(enc, x, (*codegen.Encoder).Int)".
+func (g *generator) encodeRLE(stub, e string, t types.Type) string {
+	elem := t.Underlying().(*types.Slice).Elem().(*types.Basic)
+	return fmt.Sprintf("%s(%s, %s, (*%s).%s)", g.codegen().qualify("EncodeRLE"), stub, e, g.codegen().qualify("Encoder"), exported(elem.Name()))
+}
+
+// decodeRLE returns a statement that decodes a run-length encoded value of
+// type t from stub of type *codegen.Decoder into the pointer v of type *t. t
+// must be a slice of primitive types. For example, decodeRLE("dec", "p",
+// []int) is "*p = codegen.DecodeRLE(dec, (*codegen.Decoder).Int)".
+func (g *generator) decodeRLE(stub, v string, t types.Type) string {
+	elem := t.Underlying().(*types.Slice).Elem().(*types.Basic)
+	return fmt.Sprintf("%s = %s(%s, (*%s).%s)", deref(v), g.codegen().qualify("DecodeRLE"), stub, g.codegen().qualify("Decoder"), exported(elem.Name()))
+}
+
+// encodeGorilla returns a statement that encodes the expression e of type t
+// into stub of type *codegen.Encoder using Gorilla compression. t must be a
+// time series (see gorillaFields). For example, encodeGorilla("enc", "x",
+// []Sample) is
+// "codegen.EncodeGorilla(enc, x, func(s Sample) (int64, float64) { return s.Timestamp, s.Value })".
+func (g *generator) encodeGorilla(stub, e string, t types.Type) string {
+	elem := t.Underlying().(*types.Slice).Elem()
+	timestamp, value, _ := gorillaFields(g.pkg, t)
+	return fmt.Sprintf("%s(%s, %s, func(s %s) (int64, float64) { return s.%s, s.%s })", g.codegen().qualify("EncodeGorilla"), stub, e, g.tset.genTypeString(elem), timestamp, value)
+}
+
+// decodeGorilla returns a statement that decodes a Gorilla compressed value of
+// type t from stub of type *codegen.Decoder into the pointer v of type *t. t
+// must be a time series (see gorillaFields). For example, decodeGorilla("dec",
+// "p", []Sample) is
+// "*p = codegen.DecodeGorilla(dec, func(t int64, v float64) Sample { return Sample{Timestamp: t, Value: v} })".
+func (g *generator) decodeGorilla(stub, v string, t types.Type) string {
+	elem := g.tset.genTypeString(t.Underlying().(*types.Slice).Elem())
+	timestamp, value, _ := gorillaFields(g.pkg, t)
+	return fmt.Sprintf("%s = %s(%s, func(t int64, v float64) %s { return %s{%s: t, %s: v} })", deref(v), g.codegen().qualify("DecodeGorilla"), stub, elem, elem, timestamp, value)
+}
+
 // generateEncDecMethods generates all necessary encoding and decoding methods.
 func (g *generator) generateEncDecMethods(p printFn) {
 	printedHeader := false
@@ -2350,8 +3465,12 @@ func (g *generator) generateEncDecMethods(p printFn) {
 				g.generateEncDecMethodsFor(printer, sig.Params().At(j).Type())
 			}
 
-			// Generate for result types, skipping the error.
+			// Generate for result types, skipping the error and the
+			// streamed io.ReadCloser, if any.
 			for j := 0; j < sig.Results().Len()-1; j++ {
+				if j == streamResult(sig) {
+					continue
+				}
 				g.generateEncDecMethodsFor(printer, sig.Results().At(j).Type())
 			}
 		}
@@ -2367,15 +3486,31 @@ func (g *generator) generateEncDecMethodsFor(p printFn, t types.Type) {
 		return
 	}
 	g.generated.Set(t, true)
+	if g.tset.hasMarshaler(t) {
+		// Types with a registered marshaler don't need encoding or decoding
+		// methods. Instead, we call codegen.EncodeWithMarshaler and
+		// codegen.DecodeWithMarshaler directly.
+		return
+	}
 
 	ts := g.tset.genTypeString
-	switch x := t.(type) {
+	switch x := unalias(t).(type) {
 	case *types.Basic:
 		// Basic types don't need encoding or decoding methods. Instead, we
 		// call methods directly on a codegen.Encoder or codegen.Decoder
 		// (e.g., enc.Int(42), dec.Bool()).
 
+	case *types.Interface:
+		// Likewise, empty interfaces are encoded with enc.Any(x) and decoded
+		// with dec.Any().
+
 	case *types.Pointer:
+		if isBigIntPtr(x) || isBigRatPtr(x) {
+			// *big.Int and *big.Rat don't need encoding or decoding methods.
+			// Instead, we call enc.BigInt(x) and dec.BigInt(), or
+			// enc.BigRat(x) and dec.BigRat(), directly.
+			return
+		}
 		if g.tset.isProto(x) || g.tset.hasMarshalBinary(x) {
 			// Types implementing proto.Marshal or encoding.BinaryMarshaler and
 			// encoding.BinaryUnmarshaler don't need encoding or decoding
@@ -2408,6 +3543,25 @@ func (g *generator) generateEncDecMethodsFor(p printFn, t types.Type) {
 		p(`}`)
 
 	case *types.Array:
+		if isBytes16(x) {
+			// [16]byte doesn't need encoding or decoding methods. Instead,
+			// we call enc.Bytes16(x) and dec.Bytes16() directly.
+			return
+		}
+		if isByteArray(x) {
+			// Other byte arrays are copied as is, rather than byte by byte.
+			// Note that arg and res are never nil.
+			p(``)
+			p(`func serviceweaver_enc_%s(enc *%s, arg *%s) {`, sanitize(x), g.codegen().qualify("Encoder"), ts(x))
+			p(`	enc.ByteArray(arg[:])`)
+			p(`}`)
+			p(``)
+			p(`func serviceweaver_dec_%s(dec *%s, res *%s) {`, sanitize(x), g.codegen().qualify("Decoder"), ts(x))
+			p(`	dec.ByteArray(res[:])`)
+			p(`}`)
+			return
+		}
+
 		g.generateEncDecMethodsFor(p, x.Elem())
 
 		// Note that arg is never nil.
@@ -2427,6 +3581,18 @@ func (g *generator) generateEncDecMethodsFor(p printFn, t types.Type) {
 		p(`}`)
 
 	case *types.Slice:
+		if isByteSlice(x) {
+			// Byte slices are copied as is, rather than byte by byte.
+			p(``)
+			p(`func serviceweaver_enc_%s(enc *%s, arg %s) {`, sanitize(x), g.codegen().qualify("Encoder"), ts(x))
+			p(`	enc.ByteSlice(arg)`)
+			p(`}`)
+			p(``)
+			p(`func serviceweaver_dec_%s(dec *%s) %s {`, sanitize(x), g.codegen().qualify("Decoder"), ts(x))
+			p(`	return dec.ByteSlice()`)
+			p(`}`)
+			return
+		}
 		g.generateEncDecMethodsFor(p, x.Elem())
 
 		p(``)
@@ -2447,7 +3613,7 @@ func (g *generator) generateEncDecMethodsFor(p printFn, t types.Type) {
 		p(`	if n == -1 {`)
 		p(`		return nil`)
 		p(`	}`)
-		p(`	res := make(%s, n)`, ts(x))
+		p(`	res := %s[%s](dec, n)`, g.codegen().qualify("MakeSlice"), ts(x.Elem()))
 		p(`	for i := 0; i < n; i++ {`)
 		p(`		%s`, g.decode("dec", "&res[i]", x.Elem()))
 		p(`	}`)
@@ -2493,6 +3659,16 @@ func (g *generator) generateEncDecMethodsFor(p printFn, t types.Type) {
 		panic(fmt.Sprintf("generateEncDecFor: unexpected type: %v", t))
 
 	case *types.Named:
+		if isError(x) {
+			// Errors don't need encoding or decoding methods. Instead, we
+			// call enc.Error(x) and dec.Error() directly.
+			return
+		}
+		if isUUID(x) {
+			// Well-known UUID types don't need encoding or decoding methods.
+			// Instead, we call enc.Bytes16(x) and dec.Bytes16() directly.
+			return
+		}
 		if g.tset.isProto(x) || g.tset.automarshals.At(x) != nil || g.tset.implementsAutoMarshal(x) || g.tset.hasMarshalBinary(x) {
 			// Types implementing proto.Marshal, weaver.AutoMarshal, or
 			// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler don't
@@ -2501,16 +3677,121 @@ func (g *generator) generateEncDecMethodsFor(p printFn, t types.Type) {
 			// enc.EncodeProto(x), dec.DecodeBinaryUnmarshaler(x)).
 			return
 		}
+		if variants, ok := g.tset.unionVariants(x); ok {
+			// A sealed union is encoded as a tag, followed by the encoding
+			// of the variant. Tag 0 denotes a nil union, and tag i+1
+			// denotes the ith variant.
+			for _, v := range variants {
+				g.generateEncDecMethodsFor(p, v)
+			}
+
+			p(``)
+			p(`func serviceweaver_enc_%s(enc *%s, arg %s) {`, sanitize(x), g.codegen().qualify("Encoder"), ts(x))
+			p(`	switch x := arg.(type) {`)
+			p(`	case nil:`)
+			p(`		enc.Uint32(0)`)
+			for i, v := range variants {
+				p(`	case %s:`, ts(v))
+				p(`		enc.Uint32(%d)`, i+1)
+				p(`		%s`, g.encode("enc", "x", v))
+			}
+			p(`	default:`)
+			p(`		panic(%s(%q, x))`, g.codegen().qualify("UnknownVariantError"), x.Obj().Name())
+			p(`	}`)
+			p(`}`)
+
+			p(``)
+			p(`func serviceweaver_dec_%s(dec *%s) %s {`, sanitize(x), g.codegen().qualify("Decoder"), ts(x))
+			p(`	switch tag := dec.Uint32(); tag {`)
+			p(`	case 0:`)
+			p(`		return nil`)
+			for i, v := range variants {
+				p(`	case %d:`, i+1)
+				p(`		var res %s`, ts(v))
+				p(`		%s`, g.decode("dec", "&res", v))
+				p(`		return res`)
+			}
+			p(`	default:`)
+			p(`		panic(%s(%q, tag))`, g.codegen().qualify("UnknownTagError"), x.Obj().Name())
+			p(`	}`)
+			p(`}`)
+			return
+		}
+
+		if g.isEnum(x) {
+			// An enum is encoded like its underlying integer type, but
+			// decoding fails if the decoded value is not valid.
+			p(``)
+			p(`func serviceweaver_dec_%s(dec *%s) %s {`, sanitize(x), g.codegen().qualify("Decoder"), ts(x))
+			p(`	var res %s`, ts(x))
+			p(`	%s`, g.decode("dec", fmt.Sprintf("(*%s)(&res)", ts(x.Underlying())), x.Underlying()))
+			p(`	if !res.IsValid() {`)
+			p(`		panic(%s(%q, res))`, g.codegen().qualify("InvalidEnumError"), x.Obj().Name())
+			p(`	}`)
+			p(`	return res`)
+			p(`}`)
+			return
+		}
+
 		// If a named type t is not a struct, e.g. `type t int`, then we
 		// encode and decode values of type by casting it to its underlying
 		// type (e.g., enc.Int(int(x)) where x has type t).
 		g.generateEncDecMethodsFor(p, x.Underlying())
 
 	default:
+		if isEmptyInterface(t) {
+			// E.g., the alias any.
+			return
+		}
 		panic(fmt.Sprintf("generateEncDecFor: unexpected type: %v", t))
 	}
 }
 
+// isEnum returns whether values of the provided type are validated when
+// decoded. This is the case only for types marked //weaver:enum: those of the
+// current package, and those of other packages for which "weaver generate"
+// generated an IsValid method. Hand-written IsValid methods are ignored, as
+// they don't make a type an enum.
+func (g *generator) isEnum(t *types.Named) bool {
+	if slices.Contains(g.enums, t) {
+		return true
+	}
+	if t.Obj().Pkg() == g.pkg.Types {
+		return false
+	}
+	if b, ok := t.Underlying().(*types.Basic); !ok || b.Info()&types.IsInteger == 0 {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, false, t.Obj().Pkg(), "IsValid")
+	if _, ok := obj.(*types.Func); !ok {
+		return false
+	}
+	return filepath.Base(g.pkg.Fset.Position(obj.Pos()).Filename) == generatedCodeFile
+}
+
+// generateEnumMethods generates IsValid methods for the types marked
+// //weaver:enum.
+func (g *generator) generateEnumMethods(p printFn) {
+	if len(g.enums) == 0 {
+		return
+	}
+	p(``)
+	p(`// Enum implementations.`)
+	for _, t := range g.enums {
+		name := t.Obj().Name()
+		p(``)
+		p(`// IsValid returns true if x is equal to one of the %s constants.`, name)
+		p(`func (x %s) IsValid() bool {`, name)
+		p(`	switch x {`)
+		p(`	case %s:`, strings.Join(enumValues(g.pkg, t), ", "))
+		p(`		return true`)
+		p(`	default:`)
+		p(`		return false`)
+		p(`	}`)
+		p(`}`)
+	}
+}
+
 // weaver imports and returns the weaver package.
 func (g *generator) weaver() importPkg {
 	return g.tset.importPackage(weaverPackagePath, "weaver")
@@ -2527,6 +3808,11 @@ func (g *generator) trace() importPkg {
 	return g.tset.importPackage("go.opentelemetry.io/otel/trace", "trace")
 }
 
+// attribute imports and returns the otel attribute package.
+func (g *generator) attribute() importPkg {
+	return g.tset.importPackage("go.opentelemetry.io/otel/attribute", "attribute")
+}
+
 // codes imports and returns the otel codes package.
 func (g *generator) codes() importPkg {
 	return g.tset.importPackage("go.opentelemetry.io/otel/codes", "codes")
@@ -2566,7 +3852,7 @@ func formatType(currentPackage *packages.Package, t types.Type) string {
 func sanitize(t types.Type) string {
 	var sanitize func(types.Type) string
 	sanitize = func(t types.Type) string {
-		switch x := t.(type) {
+		switch x := unalias(t).(type) {
 		case *types.Pointer:
 			return fmt.Sprintf("ptr_%s", sanitize(x.Elem()))
 
@@ -2622,6 +3908,9 @@ func sanitize(t types.Type) string {
 				return x.Name()
 			}
 		}
+		if isEmptyInterface(t) {
+			return "any"
+		}
 		panic(fmt.Sprintf("generator: unable to generate named type suffic for type: %v\n", t))
 	}
 
@@ -2640,7 +3929,7 @@ func sanitize(t types.Type) string {
 // int bool`, then TypeString returns "int" for both the named type int and the
 // primitive type int.
 func uniqueName(t types.Type) string {
-	switch x := t.(type) {
+	switch x := unalias(t).(type) {
 	case *types.Pointer:
 		return fmt.Sprintf("*%s", uniqueName(x.Elem()))
 
@@ -2656,6 +3945,10 @@ func uniqueName(t types.Type) string {
 		return fmt.Sprintf("map[%s]%s", keyName, valName)
 
 	case *types.Named:
+		if isError(x) {
+			// The predeclared error type has no package.
+			return "error"
+		}
 		n := x.TypeArgs().Len()
 		if n == 0 {
 			// This is a plain type.
@@ -2701,7 +3994,11 @@ func uniqueName(t types.Type) string {
 			return x.Name()
 		}
 	}
-	// TODO(mwhittaker): What about Struct and Interface literals?
+	if isEmptyInterface(t) {
+		// Note that interface{} and its alias any are identical.
+		return "interface{}"
+	}
+	// TODO(mwhittaker): What about Struct and non-empty Interface literals?
 	panic(fmt.Sprintf("unsupported type %v (%T)", t, t))
 }
 
@@ -58,12 +58,12 @@ replace github.com/ServiceWeaver/weaver => %s
 // in a file with the provided filename and directory---and returns the
 // directory in which the code was compiled, the output of "weaver generate",
 // and any errors. All provided subdirectories are also included in the call to
-// "weaver generate".
+// "weaver generate". If http is true, "weaver generate -http" is run instead.
 //
 // If "weaver generate" succeeds, the produced weaver_gen.go file is written in
 // the provided directory with name ${filename}_weaver_gen.go.
 func runGenerator(t *testing.T, directory, filename, contents string, subdirs []string,
-	buildTags []string) (string, error) {
+	buildTags []string, opt Options) (string, string, error) {
 	// runGenerator creates a temporary directory, copies the file and all
 	// subdirs into it, writes a go.mod file, runs "go mod tidy", and finally
 	// runs "weaver generate".
@@ -101,17 +101,20 @@ func runGenerator(t *testing.T, directory, filename, contents string, subdirs []
 		t.Fatalf("go mod tidy: %v", err)
 	}
 
-	// Run "weaver generate".
-	opt := Options{
-		Warn:      func(err error) { t.Log(err) },
-		BuildTags: "ignoreWeaverGen" + "," + strings.Join(buildTags, ","),
+	// Run "weaver generate" on the subdirectories, which may declare types
+	// used by the file, and then on the file.
+	opt.Warn = func(err error) { t.Log(err) }
+	opt.BuildTags = "ignoreWeaverGen" + "," + strings.Join(buildTags, ",")
+	var pkgs []string
+	for _, sub := range subdirs {
+		pkgs = append(pkgs, filepath.Join(tmp, sub))
 	}
-	if err := Generate(tmp, []string{tmp}, opt); err != nil {
-		return "", err
+	if err := Generate(tmp, append(pkgs, tmp), opt); err != nil {
+		return "", "", err
 	}
 	output, err := os.ReadFile(filepath.Join(tmp, generatedCodeFile))
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	if *genFilesStorageDir != "" {
@@ -147,7 +150,18 @@ func runGenerator(t *testing.T, directory, filename, contents string, subdirs []
 		t.Fatalf("go build: %v", err)
 	}
 
-	return string(output), nil
+	// Run "go vet" to make sure that weaver_gen_test.go, if any, compiles.
+	if _, err := os.Stat(filepath.Join(tmp, generatedTestFile)); err == nil {
+		govet := exec.Command("go", "vet", "-tags="+opt.BuildTags)
+		govet.Dir = tmp
+		govet.Stdout = os.Stdout
+		govet.Stderr = os.Stderr
+		if err := govet.Run(); err != nil {
+			t.Fatalf("go vet: %v", err)
+		}
+	}
+
+	return tmp, string(output), nil
 }
 
 func printOutput(t *testing.T, output []byte) {
@@ -220,7 +234,7 @@ func TestGenerator(t *testing.T) {
 			}
 
 			// Run "weaver generate".
-			output, err := runGenerator(t, dir, filename, contents, []string{"sub1", "sub2"}, nil)
+			_, output, err := runGenerator(t, dir, filename, contents, []string{"sub1", "sub2", "sub3"}, nil, Options{})
 			if err != nil {
 				t.Fatalf("error running generator: %v", err)
 			}
@@ -263,7 +277,7 @@ func TestGeneratorBuildWithTags(t *testing.T) {
 			}
 			contents := string(bits)
 			// Run "weaver generate".
-			output, err := runGenerator(t, dir, filename, contents, nil, []string{"good"})
+			_, output, err := runGenerator(t, dir, filename, contents, nil, []string{"good"}, Options{})
 
 			if filename == "good.go" {
 				// Verify that the error is nil and the weaver_gen.go contains generated code for the good service.
@@ -280,6 +294,235 @@ func TestGeneratorBuildWithTags(t *testing.T) {
 	}
 }
 
+// TestGeneratorHTTP runs "weaver generate -http" on testdata/http.go and checks
+// that the generated HTTP handlers compile and contain the expected code.
+func TestGeneratorHTTP(t *testing.T) {
+	const dir = "testdata/http"
+	const filename = "http.go"
+	bits, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", filename, err)
+	}
+	_, output, err := runGenerator(t, dir, filename, string(bits), nil, nil, Options{HTTP: true})
+	if err != nil {
+		t.Fatalf("error running generator: %v", err)
+	}
+	for _, want := range []string{
+		"func NewCatalogHTTPHandler(comp Catalog) http.Handler {",
+		"func newInventoryHTTPHandler(comp inventory) http.Handler {",
+		`"Get": func(ctx context.Context, args []json.RawMessage) (codegen.HTTPResult, error) {`,
+		"if err := codegen.DecodeHTTPArgs(args, &a0); err != nil {",
+		"return codegen.HTTPResult{}, err",
+		"r0, err := comp.Get(ctx, a0)",
+		"return codegen.HTTPResult{Value: r0, Encode: func(enc *codegen.Encoder) {",
+		"(r0).WeaverMarshal(enc)",
+		"return codegen.HTTPResult{}, comp.Reset(ctx)",
+		"r0, r1, err := comp.Search(ctx, a0, a1...)",
+		"return codegen.HTTPResult{Value: []any{r0, r1}, Encode: func(enc *codegen.Encoder) {",
+		"enc.Int(r1)",
+		"}, weaver.RemoteCallError)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain expected string %q in\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "MainHTTPHandler") {
+		t.Errorf("output contains an HTTP handler for weaver.Main in\n%s", output)
+	}
+	if strings.Contains(output, `"Picture": func`) {
+		t.Errorf("output contains an HTTP handler for a streamed result in\n%s", output)
+	}
+}
+
+// TestGeneratorCacheable runs "weaver generate" on testdata/cacheable.go and
+// checks that a test is generated for every method marked //weaver:cacheable.
+func TestGeneratorCacheable(t *testing.T) {
+	const dir = "testdata/cacheable"
+	const filename = "cacheable.go"
+	bits, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", filename, err)
+	}
+	tmp, output, err := runGenerator(t, dir, filename, string(bits), nil, nil, Options{})
+	if err != nil {
+		t.Fatalf("error running generator: %v", err)
+	}
+	if want := "Cacheable: []int{0, 1, 3},"; !strings.Contains(output, want) {
+		t.Errorf("output does not contain expected string %q in\n%s", want, output)
+	}
+
+	tests, err := os.ReadFile(filepath.Join(tmp, generatedTestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func TestCatalogGetCacheable(t *testing.T) {",
+		`weavertest.CheckCacheable[Catalog](t, "Get", func(ctx context.Context, comp Catalog) ([]any, error) {`,
+		"r0, err := comp.Get(ctx, a0)",
+		"return []any{r0}, err",
+		"return nil, comp.Ping(ctx)",
+		"var a1 []int",
+		"r0, r1, err := comp.Search(ctx, a0, a1...)",
+		"return []any{r0, r1}, err",
+	} {
+		if !strings.Contains(string(tests), want) {
+			t.Errorf("tests do not contain expected string %q in\n%s", want, tests)
+		}
+	}
+	if strings.Contains(string(tests), "TestCatalogPutCacheable") {
+		t.Errorf("tests contain a test for Put, which is not cacheable, in\n%s", tests)
+	}
+}
+
+// TestGeneratorFuzz runs "weaver generate -fuzz" on testdata/fuzz/fuzz.go,
+// checks that a fuzz test is generated for every AutoMarshal type, and runs
+// the fuzz tests on their seed corpus.
+func TestGeneratorFuzz(t *testing.T) {
+	const dir = "testdata/fuzz"
+	const filename = "fuzz.go"
+	bits, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", filename, err)
+	}
+	tmp, _, err := runGenerator(t, dir, filename, string(bits), nil, nil, Options{Fuzz: true})
+	if err != nil {
+		t.Fatalf("error running generator: %v", err)
+	}
+
+	tests, err := os.ReadFile(filepath.Join(tmp, generatedFuzzFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"//go:build weaverfuzz",
+		"func FuzzMoneyRoundTrip(f *testing.F) {",
+		"weavertest.FuzzRoundTrip[Money](f)",
+		"func FuzzProductRoundTrip(f *testing.F) {",
+		"weavertest.FuzzRoundTrip[Product](f)",
+	} {
+		if !strings.Contains(string(tests), want) {
+			t.Errorf("tests do not contain expected string %q in\n%s", want, tests)
+		}
+	}
+
+	gotest := exec.Command("go", "test", "-tags=weaverfuzz", "-run=Fuzz")
+	gotest.Dir = tmp
+	gotest.Stdout = os.Stdout
+	gotest.Stderr = os.Stderr
+	if err := gotest.Run(); err != nil {
+		t.Fatalf("go test: %v", err)
+	}
+}
+
+// TestGeneratorSchema runs "weaver generate -schema" on testdata/schema and
+// checks the generated format descriptors against
+// testdata/schema/weaver_gen_schema.json.
+func TestGeneratorSchema(t *testing.T) {
+	const dir = "testdata/schema"
+	const filename = "schema.go"
+	bits, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", filename, err)
+	}
+	tmp, _, err := runGenerator(t, dir, filename, string(bits), []string{"money"}, nil, Options{Schema: true})
+	if err != nil {
+		t.Fatalf("error running generator: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(tmp, generatedSchemaFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, generatedSchemaFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("bad schema: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestGeneratorOpenAPI runs "weaver generate -openapi" on testdata/openapi and
+// checks the generated OpenAPI document against
+// testdata/openapi/weaver_gen_openapi.json.
+func TestGeneratorOpenAPI(t *testing.T) {
+	const dir = "testdata/openapi"
+	const filename = "openapi.go"
+	bits, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", filename, err)
+	}
+	tmp, _, err := runGenerator(t, dir, filename, string(bits), nil, nil, Options{OpenAPI: true})
+	if err != nil {
+		t.Fatalf("error running generator: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(tmp, generatedOpenAPIFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, generatedOpenAPIFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("bad OpenAPI document: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestGeneratorCLI runs "weaver generate -cli" on testdata/cli, builds the
+// generated command-line client, and checks that it calls methods with the
+// arguments passed as flags.
+func TestGeneratorCLI(t *testing.T) {
+	const dir = "testdata/cli"
+	const filename = "cli.go"
+	bits, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", filename, err)
+	}
+	tmp, _, err := runGenerator(t, dir, filename, string(bits), nil, nil, Options{CLI: true})
+	if err != nil {
+		t.Fatalf("error running generator: %v", err)
+	}
+	client := filepath.Join(tmp, "client")
+	gobuild := exec.Command("go", "build", "-o", client, "./"+generatedCLIDir)
+	gobuild.Dir = tmp
+	gobuild.Stdout = os.Stdout
+	gobuild.Stderr = os.Stderr
+	if err := gobuild.Run(); err != nil {
+		t.Fatalf("go build: %v", err)
+	}
+
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"Currency.Convert", "-from=USD", "-amount=2.5", "-to=EUR"}, "5\n"},
+		{[]string{"Currency.Place", `-order={"items":["a","b"],"quantity":3}`, "-urgent=true", "-timeout=1m"},
+			`"3 x a+b, urgent=true, timeout=1m0s"` + "\n"},
+	} {
+		t.Run(test.args[0], func(t *testing.T) {
+			cmd := exec.Command(client, test.args...)
+			cmd.Stderr = os.Stderr
+			got, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("%v: %v", cmd, err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("%v: got %q, want %q", cmd, got, test.want)
+			}
+		})
+	}
+
+	// Unknown methods and bad flags are rejected.
+	for _, args := range [][]string{
+		{"Currency.Missing"},
+		{"Currency.Convert", "-amount=lots"},
+	} {
+		if err := exec.Command(client, args...).Run(); err == nil {
+			t.Errorf("%v: unexpected success", args)
+		}
+	}
+}
+
 // TestGeneratorErrors runs "weaver generate" on all of the files in
 // testdata/errors.
 // Every file in testdata/errors must begin with a single line header that looks
@@ -329,7 +572,7 @@ func TestGeneratorErrors(t *testing.T) {
 			}
 
 			// Run "weaver generate".
-			output, err := runGenerator(t, dir, filename, contents, nil, nil)
+			_, output, err := runGenerator(t, dir, filename, contents, nil, nil, Options{})
 			errfile := strings.TrimSuffix(filename, ".go") + "_error.txt"
 			if err == nil {
 				os.Remove(filepath.Join(dir, errfile))
@@ -502,7 +745,7 @@ func TestExampleVersion(t *testing.T) {
 	got := fmt.Sprintf("%x", h.Sum(nil))
 
 	// If weaver_gen.go has changed, the codegen version may need updating.
-	const want = "fdc14ac425413ea0f80ea42d3c8d1bc555d57c511135b5639720f233668f4fe7"
+	const want = "a5aec11cbd88b290a3f408d7d096c42c30c6176e5aabff3e2169246e8e11f8cb"
 	if got != want {
 		t.Fatalf(`Unexpected SHA-256 hash of examples/weaver_gen.go: got %s, want %s. If this change is meaningful, REMEMBER TO UPDATE THE CODEGEN VERSION in runtime/version/version.go.`, got, want)
 	}
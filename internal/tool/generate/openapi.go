@@ -0,0 +1,393 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver/internal/files"
+)
+
+// generatedOpenAPIFile is the name of the file that holds the OpenAPI
+// document of a package's components. See generateOpenAPI.
+const generatedOpenAPIFile = "weaver_gen_openapi.json"
+
+// openAPIDoc is an OpenAPI 3.1 document [1]. Only the parts of the
+// specification that are needed to describe the JSON-over-HTTP handlers
+// generated by "weaver generate -http" are included.
+//
+// [1]: https://spec.openapis.org/oas/v3.1.0
+type openAPIDoc struct {
+	OpenAPI    string                  `json:"openapi"`
+	Info       openAPIInfo             `json:"info"`
+	Paths      map[string]*openAPIPath `json:"paths"`
+	Components openAPIComponents       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIPath struct {
+	Post *openAPIOperation `json:"post"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                     `json:"required"`
+	Content  map[string]*openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                   `json:"$ref,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Content     map[string]*openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema *jsonSchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas   map[string]*jsonSchema      `json:"schemas"`
+	Responses map[string]*openAPIResponse `json:"responses"`
+}
+
+// jsonSchema is a JSON Schema [1], the schema language used by OpenAPI 3.1.
+//
+// [1]: https://json-schema.org/draft/2020-12/json-schema-core
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Minimum              *int64                 `json:"minimum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	PrefixItems          []*jsonSchema          `json:"prefixItems,omitempty"`
+	MinItems             *int64                 `json:"minItems,omitempty"`
+	MaxItems             *int64                 `json:"maxItems,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+}
+
+// Names of the error responses shared by every operation.
+const (
+	openAPIBadRequest  = "BadRequest"
+	openAPIError       = "Error"
+	openAPIRemoteError = "RemoteCallError"
+)
+
+// generateOpenAPI writes, in a weaver_gen_openapi.json file, an OpenAPI 3.1
+// document that describes the JSON-over-HTTP handlers generated by "weaver
+// generate -http" for the package's components. Every method M of component
+// interface Foo is an operation served at "POST /Foo/M", assuming that the
+// handler returned by NewFooHTTPHandler is mounted under "/Foo/". For example:
+//
+//	mux.Handle("/Foo/", http.StripPrefix("/Foo", NewFooHTTPHandler(foo)))
+//
+// The request body of an operation is a JSON array of the method's arguments,
+// and the response body is the JSON encoding of the method's result, or a
+// JSON array of its results if there are more than one. The schemas of the
+// arguments and results are derived from their Go types, following the rules
+// of encoding/json. An error returned by the method is described as a
+// standard error response.
+func (g *generator) generateOpenAPI() error {
+	doc := openAPIDoc{
+		OpenAPI: "3.1.0",
+		Info:    openAPIInfo{Title: g.pkg.PkgPath, Version: "1.0.0"},
+		Paths:   map[string]*openAPIPath{},
+		Components: openAPIComponents{
+			Schemas:   map[string]*jsonSchema{},
+			Responses: openAPIErrorResponses(),
+		},
+	}
+	for _, comp := range g.components {
+		if comp.isMain {
+			continue
+		}
+		name := comp.intfName()
+		for _, m := range comp.methods() {
+			mt := m.Type().(*types.Signature)
+			if streamResult(mt) >= 0 {
+				// Streamed results aren't served over HTTP.
+				continue
+			}
+			op := &openAPIOperation{
+				OperationID: name + "_" + m.Name(),
+				Tags:        []string{name},
+				Responses: map[string]*openAPIResponse{
+					"400": {Ref: "#/components/responses/" + openAPIBadRequest},
+					"500": {Ref: "#/components/responses/" + openAPIError},
+					"502": {Ref: "#/components/responses/" + openAPIRemoteError},
+				},
+			}
+
+			// Describe the arguments, excluding the context.
+			var args []*jsonSchema
+			for i := 1; i < mt.Params().Len(); i++ {
+				param := mt.Params().At(i)
+				t := param.Type()
+				if mt.Variadic() && i == mt.Params().Len()-1 {
+					// A variadic argument is passed as a slice.
+					t = types.NewSlice(t.(*types.Slice).Elem())
+				}
+				arg := g.jsonSchemaOf(t, doc.Components.Schemas)
+				arg.Title = param.Name()
+				args = append(args, arg)
+			}
+			if len(args) > 0 {
+				op.RequestBody = &openAPIRequestBody{
+					Required: true,
+					Content:  map[string]*openAPIMedia{"application/json": {Schema: tuple(args)}},
+				}
+			}
+
+			// Describe the results, excluding the error.
+			var results []*jsonSchema
+			for i := 0; i < mt.Results().Len()-1; i++ {
+				results = append(results, g.jsonSchemaOf(mt.Results().At(i).Type(), doc.Components.Schemas))
+			}
+			var result *jsonSchema
+			switch len(results) {
+			case 0:
+				result = &jsonSchema{Type: "null"}
+			case 1:
+				result = results[0]
+			default:
+				result = tuple(results)
+			}
+			op.Responses["200"] = &openAPIResponse{
+				Description: "The result of " + name + "." + m.Name(),
+				Content:     map[string]*openAPIMedia{"application/json": {Schema: result}},
+			}
+			doc.Paths["/"+name+"/"+m.Name()] = &openAPIPath{Post: op}
+		}
+	}
+	if len(doc.Paths) == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encode OpenAPI document: %w", err)
+	}
+	dst := files.NewWriter(filepath.Join(g.pkgDir(), generatedOpenAPIFile))
+	defer dst.Cleanup()
+	if _, err := dst.Write(b.Bytes()); err != nil {
+		return err
+	}
+	return dst.Close()
+}
+
+// openAPIErrorResponses returns the error responses shared by every
+// operation. See codegen.HTTPHandler for the conditions under which they are
+// returned.
+func openAPIErrorResponses() map[string]*openAPIResponse {
+	text := func(description string) *openAPIResponse {
+		return &openAPIResponse{
+			Description: description,
+			Content:     map[string]*openAPIMedia{"text/plain": {Schema: &jsonSchema{Type: "string"}}},
+		}
+	}
+	return map[string]*openAPIResponse{
+		openAPIBadRequest:  text("The arguments are malformed."),
+		openAPIError:       text("The method returned an error."),
+		openAPIRemoteError: text("The method could not be called remotely."),
+	}
+}
+
+// tuple returns the schema of a JSON array that holds values with the
+// provided schemas, in order.
+func tuple(elems []*jsonSchema) *jsonSchema {
+	n := int64(len(elems))
+	return &jsonSchema{Type: "array", PrefixItems: elems, MinItems: &n, MaxItems: &n}
+}
+
+// jsonSchemaOf returns the schema of the JSON encoding of a value of the
+// serializable type t. Named struct types are added to schemas, keyed by
+// their qualified names, and referenced by the returned schema.
+func (g *generator) jsonSchemaOf(t types.Type, schemas map[string]*jsonSchema) *jsonSchema {
+	// Note that the cases below mirror the ones in generator.describeType.
+	switch x := unalias(t).(type) {
+	case *types.Basic:
+		switch x.Kind() {
+		case types.Bool:
+			return &jsonSchema{Type: "boolean"}
+		case types.Int8, types.Int16, types.Int32:
+			return &jsonSchema{Type: "integer", Format: "int32"}
+		case types.Int, types.Int64:
+			return &jsonSchema{Type: "integer", Format: "int64"}
+		case types.Uint8, types.Uint16, types.Uint32, types.Uint, types.Uint64:
+			var zero int64
+			return &jsonSchema{Type: "integer", Minimum: &zero}
+		case types.Float32:
+			return &jsonSchema{Type: "number", Format: "float"}
+		case types.Float64:
+			return &jsonSchema{Type: "number", Format: "double"}
+		case types.String:
+			return &jsonSchema{Type: "string"}
+		default:
+			// Complex numbers have no JSON encoding.
+			return &jsonSchema{Description: x.Name() + " values cannot be encoded as JSON"}
+		}
+
+	case *types.Pointer:
+		if isBigIntPtr(x) {
+			return &jsonSchema{Type: "integer"}
+		}
+		if isBigRatPtr(x) {
+			// encoding/json encodes a *big.Rat as a string, e.g., "1/3".
+			return &jsonSchema{Type: "string"}
+		}
+		return &jsonSchema{AnyOf: []*jsonSchema{g.jsonSchemaOf(x.Elem(), schemas), {Type: "null"}}}
+
+	case *types.Array:
+		n := x.Len()
+		return &jsonSchema{Type: "array", Items: g.jsonSchemaOf(x.Elem(), schemas), MinItems: &n, MaxItems: &n}
+
+	case *types.Slice:
+		if b, ok := x.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			// encoding/json encodes a []byte as a base64 string.
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: g.jsonSchemaOf(x.Elem(), schemas)}
+
+	case *types.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: g.jsonSchemaOf(x.Elem(), schemas)}
+
+	case *types.Struct:
+		return g.jsonSchemaOfStruct(x, schemas)
+
+	case *types.Interface:
+		return &jsonSchema{}
+
+	case *types.Named:
+		name := qualifiedName(x)
+		switch {
+		case name == "time.Time":
+			return &jsonSchema{Type: "string", Format: "date-time"}
+		case g.tset.isProto(x):
+			return &jsonSchema{Description: "protocol buffer message " + name}
+		case g.hasMethod(x, "MarshalJSON"):
+			return &jsonSchema{Description: name + " has a custom JSON encoding"}
+		case g.hasMethod(x, "MarshalText"):
+			return &jsonSchema{Type: "string"}
+		}
+		if variants, ok := g.tset.unionVariants(x); ok {
+			s := &jsonSchema{Title: name}
+			for _, v := range variants {
+				s.OneOf = append(s.OneOf, g.jsonSchemaOf(v, schemas))
+			}
+			return s
+		}
+		s, ok := x.Underlying().(*types.Struct)
+		if !ok {
+			return g.jsonSchemaOf(x.Underlying(), schemas)
+		}
+		key := openAPIKey(name)
+		if _, ok := schemas[key]; !ok {
+			schemas[key] = nil // break cycles
+			schema := g.jsonSchemaOfStruct(s, schemas)
+			schema.Title = name
+			schemas[key] = schema
+		}
+		return &jsonSchema{Ref: "#/components/schemas/" + key}
+
+	default:
+		if isEmptyInterface(t) {
+			return &jsonSchema{}
+		}
+		panic(fmt.Sprintf("jsonSchemaOf: unexpected type: %v", t))
+	}
+}
+
+// jsonSchemaOfStruct returns the schema of the JSON encoding of a struct. The
+// fields of embedded structs are promoted, and fields are renamed and omitted
+// according to their json struct tags, as with encoding/json.
+func (g *generator) jsonSchemaOfStruct(s *types.Struct, schemas map[string]*jsonSchema) *jsonSchema {
+	schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+	var addFields func(s *types.Struct)
+	addFields = func(s *types.Struct) {
+		for i := 0; i < s.NumFields(); i++ {
+			fi := s.Field(i)
+			if isWeaverAutoMarshal(fi.Type()) {
+				continue
+			}
+			name, opts, _ := strings.Cut(reflect.StructTag(s.Tag(i)).Get("json"), ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			if fi.Embedded() && name == "" {
+				t := fi.Type()
+				if p, ok := t.(*types.Pointer); ok {
+					t = p.Elem()
+				}
+				if es, ok := t.Underlying().(*types.Struct); ok {
+					addFields(es)
+					continue
+				}
+			}
+			if !fi.Exported() {
+				continue
+			}
+			if name == "" {
+				name = fi.Name()
+			}
+			schema.Properties[name] = g.jsonSchemaOf(fi.Type(), schemas)
+			if !strings.Contains(","+opts+",", ",omitempty,") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+	addFields(s)
+	return schema
+}
+
+// hasMethod returns whether t or *t has a method with the provided name.
+func (g *generator) hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, g.tset.pkg.Types, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// openAPIKeyChars matches the characters that may not appear in the key of an
+// OpenAPI component.
+var openAPIKeyChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// openAPIKey returns the key of the OpenAPI component with the provided
+// qualified name, e.g., "example.com_money.T" for "example.com/money.T".
+func openAPIKey(name string) string {
+	return openAPIKeyChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"github.com/ServiceWeaver/weaver/internal/files"
+)
+
+// generatedSchemaFile is the name of the file that holds the canonical format
+// descriptors of a package's AutoMarshal types. See generateSchema.
+const generatedSchemaFile = "weaver_gen_schema.json"
+
+// schema describes the canonical binary encoding of the AutoMarshal structs
+// declared in a package, along with the AutoMarshal structs they use, for
+// consumption by code generators for other languages. The encoding of every
+// kind of type is documented in the "Canonical Encoding" section of
+// website/docs.md.
+type schema struct {
+	Package string        `json:"package"` // package path
+	Types   []*schemaType `json:"types"`   // sorted by name
+}
+
+// schemaType describes an AutoMarshal struct. A struct is encoded as the
+// concatenation of the encodings of its fields, in order, without framing,
+// unless it is Tagged, in which case every field is framed by its Number and
+// length (see codegen.EncodeTaggedField).
+type schemaType struct {
+	Name   string         `json:"name"`             // qualified name, e.g., "example.com/money.T"
+	Tagged bool           `json:"tagged,omitempty"` // marked //weaver:tagged?
+	Fields []*schemaField `json:"fields"`           // in encoding order
+}
+
+// schemaField describes a field of an AutoMarshal struct.
+type schemaField struct {
+	Name     string    `json:"name"`
+	Number   int       `json:"number,omitempty"` // field number, if the struct is tagged
+	Type     *typeDesc `json:"type"`
+	Encoding string    `json:"encoding,omitempty"` // "rle", "bitset", "tail", or "gorilla"
+}
+
+// typeDesc describes a serializable type. Kind is one of:
+//
+//   - "bool", "int8", "int16", "int32", "int64", "uint8", "uint16",
+//     "uint32", "uint64", "float32", "float64", "complex64", "complex128",
+//     or "string";
+//   - "bigint", "bigrat", "latlng", or "bbox";
+//   - "pointer", with Elem;
+//   - "array", with Len and Elem;
+//   - "slice", with Elem;
+//   - "map", with Key and Elem;
+//   - "struct", with the Name of an AutoMarshal struct in the schema;
+//   - "enum", with the Name of the enum and its underlying integer type Elem;
+//   - "union", with the Name of the union and its Variants;
+//   - "any", for a value of a type registered with codegen.RegisterType;
+//   - "error", for an error encoded by codegen.Encoder.Error;
+//   - "proto", "binary", or "custom", with the Name of a type that is
+//     encoded by its protobuf encoding, by its MarshalBinary method, or by a
+//     hand-written WeaverMarshal method respectively;
+//   - "marshaler", with the Name of a type that is encoded by the marshaler
+//     registered for it with codegen.RegisterMarshaler.
+type typeDesc struct {
+	Kind     string      `json:"kind"`
+	Name     string      `json:"name,omitempty"`
+	Len      int64       `json:"len,omitempty"`
+	Key      *typeDesc   `json:"key,omitempty"`
+	Elem     *typeDesc   `json:"elem,omitempty"`
+	Variants []*typeDesc `json:"variants,omitempty"`
+}
+
+// generateSchema writes, in a weaver_gen_schema.json file, the canonical
+// format descriptors of the AutoMarshal structs declared in the package, and
+// of the AutoMarshal structs that they use, directly or indirectly. For
+// example, given the following types:
+//
+//	type Product struct {
+//	    weaver.AutoMarshal
+//	    Name  string
+//	    Price money.T
+//	}
+//
+// the schema holds descriptors for both Product and money.T.
+func (g *generator) generateSchema() error {
+	if g.tset.automarshalCandidates.Len() == 0 {
+		return nil
+	}
+
+	// Describe the package's types and, transitively, the types they use.
+	described := map[string]*schemaType{}
+	var describe func(t *types.Named)
+	describe = func(t *types.Named) {
+		name := qualifiedName(t)
+		if _, ok := described[name]; ok {
+			return
+		}
+		st := &schemaType{Name: name, Tagged: g.isTagged(t), Fields: []*schemaField{}}
+		described[name] = st
+		s := t.Underlying().(*types.Struct)
+		for i := 0; i < s.NumFields(); i++ {
+			fi := s.Field(i)
+			if isWeaverAutoMarshal(fi.Type()) || fi.Name() == "_" {
+				continue
+			}
+			field := &schemaField{Name: fi.Name(), Encoding: weaverTag(s, i)}
+			if st.Tagged {
+				field.Number = taggedFieldNumber(s, i)
+			}
+			if field.Encoding == "version" {
+				// Versions don't change the encoding.
+				field.Encoding = ""
+			}
+			field.Type = g.describeType(fi.Type(), describe)
+			st.Fields = append(st.Fields, field)
+		}
+	}
+	for _, t := range g.tset.automarshalCandidates.Keys() {
+		describe(t.(*types.Named))
+	}
+
+	s := schema{Package: g.pkg.PkgPath}
+	for _, st := range described {
+		s.Types = append(s.Types, st)
+	}
+	sort.Slice(s.Types, func(i, j int) bool {
+		return s.Types[i].Name < s.Types[j].Name
+	})
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("encode schema: %w", err)
+	}
+	dst := files.NewWriter(filepath.Join(g.pkgDir(), generatedSchemaFile))
+	defer dst.Cleanup()
+	if _, err := dst.Write(b.Bytes()); err != nil {
+		return err
+	}
+	return dst.Close()
+}
+
+// describeType returns the descriptor of the serializable type t. It calls
+// describe on every AutoMarshal struct that t uses.
+func (g *generator) describeType(t types.Type, describe func(*types.Named)) *typeDesc {
+	// Note that the cases below mirror the ones in generator.encode.
+	if g.tset.hasMarshaler(t) {
+		return &typeDesc{Kind: "marshaler", Name: types.TypeString(t, func(p *types.Package) string { return p.Path() })}
+	}
+	switch x := unalias(t).(type) {
+	case *types.Basic:
+		switch x.Kind() {
+		case types.Int:
+			// An int is encoded as an int64, and a uint as a uint64.
+			return &typeDesc{Kind: "int64"}
+		case types.Uint:
+			return &typeDesc{Kind: "uint64"}
+		default:
+			return &typeDesc{Kind: x.Name()}
+		}
+
+	case *types.Pointer:
+		if isBigIntPtr(x) {
+			return &typeDesc{Kind: "bigint"}
+		}
+		if isBigRatPtr(x) {
+			return &typeDesc{Kind: "bigrat"}
+		}
+		return &typeDesc{Kind: "pointer", Elem: g.describeType(x.Elem(), describe)}
+
+	case *types.Array:
+		return &typeDesc{Kind: "array", Len: x.Len(), Elem: g.describeType(x.Elem(), describe)}
+
+	case *types.Slice:
+		return &typeDesc{Kind: "slice", Elem: g.describeType(x.Elem(), describe)}
+
+	case *types.Map:
+		return &typeDesc{
+			Kind: "map",
+			Key:  g.describeType(x.Key(), describe),
+			Elem: g.describeType(x.Elem(), describe),
+		}
+
+	case *types.Interface:
+		return &typeDesc{Kind: "any"}
+
+	case *types.Named:
+		name := qualifiedName(x)
+		switch {
+		case isError(x):
+			return &typeDesc{Kind: "error"}
+		case isWeaverLatLng(x):
+			return &typeDesc{Kind: "latlng"}
+		case isWeaverBBox(x):
+			return &typeDesc{Kind: "bbox"}
+		case isUUID(x):
+			// A UUID is encoded as its raw 16 bytes.
+			return g.describeType(x.Underlying(), describe)
+		case g.tset.isProto(x):
+			return &typeDesc{Kind: "proto", Name: name}
+		case g.tset.automarshals.At(x) != nil || g.tset.automarshalCandidates.At(x) != nil || embedsAutoMarshal(x):
+			describe(x)
+			return &typeDesc{Kind: "struct", Name: name}
+		case g.tset.implementsAutoMarshal(x):
+			return &typeDesc{Kind: "custom", Name: name}
+		case g.tset.hasMarshalBinary(x):
+			return &typeDesc{Kind: "binary", Name: name}
+		}
+		if variants, ok := g.tset.unionVariants(x); ok {
+			d := &typeDesc{Kind: "union", Name: name}
+			for _, v := range variants {
+				d.Variants = append(d.Variants, g.describeType(v, describe))
+			}
+			return d
+		}
+		if g.isEnum(x) {
+			return &typeDesc{Kind: "enum", Name: name, Elem: g.describeType(x.Underlying(), describe)}
+		}
+		return g.describeType(x.Underlying(), describe)
+
+	default:
+		if isEmptyInterface(t) {
+			return &typeDesc{Kind: "any"}
+		}
+		panic(fmt.Sprintf("describeType: unexpected type: %v", t))
+	}
+}
+
+// embedsAutoMarshal returns whether t is a struct that embeds
+// weaver.AutoMarshal.
+func embedsAutoMarshal(t *types.Named) bool {
+	s, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		if f := s.Field(i); f.Embedded() && isWeaverAutoMarshal(f.Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedName returns the package-qualified name of t, e.g.,
+// "example.com/money.T".
+func qualifiedName(t *types.Named) string {
+	if t.Obj().Pkg() == nil {
+		return t.Obj().Name()
+	}
+	return t.Obj().Pkg().Path() + "." + t.Obj().Name()
+}
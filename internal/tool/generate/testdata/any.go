@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// enc.Any(x.Metadata)
+// x.Metadata = dec.Any()
+// enc.Any((interface{})(x.Note))
+// *(*interface{})(&x.Note) = dec.Any()
+// enc.Any(v)
+// v = dec.Any()
+// r0 = dec.Any()
+
+// Verify that fields of empty interface types are encoded with the types
+// registered with codegen.RegisterType.
+package foo
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+type Note interface{}
+
+type Refund struct {
+	weaver.AutoMarshal
+	Amount int
+}
+
+type Transaction struct {
+	weaver.AutoMarshal
+	Metadata any
+	Note     Note
+	Tags     map[string]any
+}
+
+func init() {
+	codegen.RegisterType("refund", reflect.TypeOf(Refund{}))
+}
+
+type foo interface {
+	Process(context.Context, Transaction) (any, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) Process(context.Context, Transaction) (any, error) {
+	return nil, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// codegen.EncodeBitset(enc, x.enabled)
+// codegen.EncodeBitset(enc, x.flags)
+// x.enabled = codegen.DecodeBitset(dec)
+// x.flags = codegen.DecodeBitset(dec)
+// serviceweaver_enc_slice_bool
+
+// Verify that AutoMarshal fields tagged with weaver:"bitset" are encoded as
+// bitsets and that untagged fields are not.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type flags []bool
+
+type features struct {
+	weaver.AutoMarshal
+	enabled []bool `weaver:"bitset"`
+	flags   flags  `weaver:"bitset"`
+	plain   []bool
+}
+
+type foo interface {
+	M(context.Context, features) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) M(context.Context, features) error { return nil }
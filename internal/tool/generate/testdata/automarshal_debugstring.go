@@ -0,0 +1,56 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// EXPECTED
+// func (x *product) DebugString() string {
+// codegen.DebugField{Name: "Name", Value: x.Name},
+// codegen.DebugField{Name: "sku", Value: x.sku},
+// codegen.DebugField{Name: "payload", Value: x.payload},
+
+// UNEXPECTED
+// codegen.DebugField{Name: "_", Value: x._},
+// func (x *money) DebugString() string {
+
+// Verify that AutoMarshal structs get a DebugString method that renders every
+// serialized field, unless they already have a DebugString method.
+package foo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type product struct {
+	weaver.AutoMarshal
+	Name    string
+	sku     [4]byte
+	_       int
+	payload []byte `weaver:"tail"`
+}
+
+type money struct {
+	weaver.AutoMarshal
+	cents int64
+}
+
+func (m money) DebugString() string { return fmt.Sprintf("$%d.%02d", m.cents/100, m.cents%100) }
+
+type foo interface {
+	M(context.Context, money, product) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) M(context.Context, money, product) error { return nil }
@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// EXPECTED
+// enc.Error(x.Err)
+// x.Err = dec.Error()
+// enc.Error(arg[i])
+// res[i] = dec.Error()
+
+// UNEXPECTED
+// serviceweaver_enc_error
+// serviceweaver_dec_error
+
+// Verify that error fields of AutoMarshal structs, and slices of errors, are
+// encoded with Encoder.Error and decoded with Decoder.Error.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type item struct {
+	weaver.AutoMarshal
+	ID  int
+	Err error
+}
+
+type transaction struct {
+	weaver.AutoMarshal
+	Items  []item
+	Errors []error
+}
+
+type foo interface {
+	Commit(context.Context, transaction) (transaction, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Commit(_ context.Context, t transaction) (transaction, error) { return t, nil }
@@ -0,0 +1,55 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// codegen.EncodeRLE(enc, x.prices, (*codegen.Encoder).Float64)
+// codegen.EncodeRLE(enc, x.flags, (*codegen.Encoder).Bool)
+// codegen.EncodeRLE(enc, x.row, (*codegen.Encoder).Int)
+// x.prices = codegen.DecodeRLE(dec, (*codegen.Decoder).Float64)
+// x.flags = codegen.DecodeRLE(dec, (*codegen.Decoder).Bool)
+// x.row = codegen.DecodeRLE(dec, (*codegen.Decoder).Int)
+// serviceweaver_enc_slice_string
+// serviceweaver_dec_slice_string
+
+// UNEXPECTED
+// serviceweaver_enc_slice_float64
+// serviceweaver_enc_slice_bool
+
+// Verify that AutoMarshal fields tagged with weaver:"rle" are run-length
+// encoded and that untagged fields are not.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type row []int
+
+type matrix struct {
+	weaver.AutoMarshal
+	prices []float64 `weaver:"rle"`
+	flags  []bool    `weaver:"rle"`
+	row    row       `weaver:"rle"`
+	names  []string
+}
+
+type foo interface {
+	M(context.Context, matrix) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) M(context.Context, matrix) error { return nil }
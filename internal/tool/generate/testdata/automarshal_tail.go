@@ -0,0 +1,46 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// codegen.EncodeTail(enc, x.payload)
+// x.payload = codegen.DecodeTail(dec)
+// serviceweaver_enc_slice_byte_87461245(enc, x.plain)
+
+// Verify that the last AutoMarshal field tagged with weaver:"tail" is encoded
+// without a length, and that a struct with a tail can be the last argument of
+// a method.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type message struct {
+	weaver.AutoMarshal
+	metadata map[string]string
+	plain    []byte
+	payload  []byte `weaver:"tail"`
+}
+
+type foo interface {
+	M(context.Context, int, message) error
+	N(context.Context, *message) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) M(context.Context, int, message) error { return nil }
+func (impl) N(context.Context, *message) error     { return nil }
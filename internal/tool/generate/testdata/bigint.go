@@ -0,0 +1,54 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// enc.BigInt(a0)
+// r0 = dec.BigInt()
+// enc.BigInt(x.balance)
+// x.balance = dec.BigInt()
+// serviceweaver_enc_slice_ptr_Int_
+// serviceweaver_enc_map_string_ptr_Int_
+
+// UNEXPECTED
+// func serviceweaver_enc_ptr_Int_
+// func serviceweaver_dec_ptr_Int_
+
+// Verify that *big.Int arguments, results, and fields are serializable.
+package foo
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type account struct {
+	weaver.AutoMarshal
+	balance *big.Int
+}
+
+type foo interface {
+	Add(context.Context, *big.Int, *big.Int) (*big.Int, error)
+	Sum(context.Context, []*big.Int, map[string]*big.Int) error
+	Get(context.Context) (account, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Add(_ context.Context, x, y *big.Int) (*big.Int, error) {
+	return new(big.Int).Add(x, y), nil
+}
+func (impl) Sum(context.Context, []*big.Int, map[string]*big.Int) error { return nil }
+func (impl) Get(context.Context) (account, error)                       { return account{}, nil }
@@ -0,0 +1,54 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// enc.BigRat(a0)
+// r0 = dec.BigRat()
+// enc.BigRat(x.price)
+// x.price = dec.BigRat()
+// serviceweaver_enc_slice_ptr_Rat_
+// serviceweaver_enc_map_string_ptr_Rat_
+
+// UNEXPECTED
+// func serviceweaver_enc_ptr_Rat_
+// func serviceweaver_dec_ptr_Rat_
+
+// Verify that *big.Rat arguments, results, and fields are serializable.
+package foo
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type product struct {
+	weaver.AutoMarshal
+	price *big.Rat
+}
+
+type foo interface {
+	Add(context.Context, *big.Rat, *big.Rat) (*big.Rat, error)
+	Sum(context.Context, []*big.Rat, map[string]*big.Rat) error
+	Get(context.Context) (product, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Add(_ context.Context, x, y *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Add(x, y), nil
+}
+func (impl) Sum(context.Context, []*big.Rat, map[string]*big.Rat) error { return nil }
+func (impl) Get(context.Context) (product, error)                       { return product{}, nil }
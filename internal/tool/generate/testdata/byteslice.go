@@ -0,0 +1,47 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// EXPECTED
+// enc.ByteSlice(arg)
+// return dec.ByteSlice()
+// serviceweaver_enc_slice_byte_87461245(enc, x.payload)
+// x.payload = serviceweaver_dec_slice_byte_87461245(dec)
+
+// UNEXPECTED
+// enc.Byte(arg[i])
+// dec.Byte()
+
+// Verify that byte slices are encoded as is, rather than byte by byte.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type blob struct {
+	weaver.AutoMarshal
+	payload []byte
+	chunks  [][]byte
+}
+
+type foo interface {
+	A(context.Context, []byte) ([]byte, error)
+	B(context.Context, blob) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) A(context.Context, []byte) ([]byte, error) { return nil, nil }
+func (impl) B(context.Context, blob) error             { return nil }
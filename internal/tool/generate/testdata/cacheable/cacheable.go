@@ -0,0 +1,48 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Verify that methods marked //weaver:cacheable are recorded and that a test
+// is generated for each of them.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Catalog interface {
+	//weaver:cacheable
+	Get(context.Context, string) (string, error)
+
+	//weaver:cacheable
+	Search(context.Context, string, ...int) ([]string, int, error)
+
+	//weaver:cacheable
+	Ping(context.Context) error
+
+	Put(context.Context, string, string) error
+}
+
+type catalog struct {
+	weaver.Implements[Catalog]
+}
+
+func (c *catalog) Get(context.Context, string) (string, error) { return "", nil }
+func (c *catalog) Ping(context.Context) error                  { return nil }
+func (c *catalog) Put(context.Context, string, string) error   { return nil }
+
+func (c *catalog) Search(context.Context, string, ...int) ([]string, int, error) {
+	return nil, 0, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Verify that "weaver generate -cli" generates a client that parses the
+// arguments of component methods from flags.
+package foo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Order struct {
+	weaver.AutoMarshal
+	Items    []string `json:"items"`
+	Quantity int      `json:"quantity"`
+}
+
+type Currency interface {
+	Convert(ctx context.Context, from string, amount float64, to string) (float64, error)
+	Place(ctx context.Context, order Order, urgent bool, timeout time.Duration) (string, error)
+}
+
+type currency struct {
+	weaver.Implements[Currency]
+}
+
+func (currency) Convert(_ context.Context, from string, amount float64, to string) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+	return amount * 2, nil
+}
+
+func (currency) Place(_ context.Context, order Order, urgent bool, timeout time.Duration) (string, error) {
+	return fmt.Sprintf("%d x %s, urgent=%t, timeout=%v", order.Quantity, strings.Join(order.Items, "+"), urgent, timeout), nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// ReadOnly: []int{0},
+// ReadOnly: []int{1},
+// func (s admin_client_stub) Get(ctx context.Context) (r0 string, err error)
+// func (s admin_server_stub) get(ctx context.Context, args []byte) (res []byte, err error)
+// case "Get":
+
+// Verify that methods promoted from embedded component interfaces, and their
+// directives, are included in the embedding component.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Reader interface {
+	//weaver:readonly
+	Get(context.Context) (string, error)
+}
+
+type Admin interface {
+	Reader
+	Delete(context.Context) error
+}
+
+type reader struct{ weaver.Implements[Reader] }
+
+func (r *reader) Get(context.Context) (string, error) { return "", nil }
+
+type admin struct{ weaver.Implements[Admin] }
+
+func (a *admin) Get(context.Context) (string, error) { return "", nil }
+func (a *admin) Delete(context.Context) error        { return nil }
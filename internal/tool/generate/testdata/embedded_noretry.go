@@ -0,0 +1,40 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// NoRetry: []int{0, 2}
+
+// Verify that methods promoted from an embedded interface declared in another
+// package are not retriable if they are declared so in that package.
+package foo
+
+import (
+	"context"
+
+	"foo/sub3"
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Admin interface {
+	sub3.Store
+	Delete(context.Context, string) error
+}
+
+type admin struct{ weaver.Implements[Admin] }
+
+func (a *admin) Get(context.Context, string) (string, error) { return "", nil }
+func (a *admin) Put(context.Context, string, string) error   { return nil }
+func (a *admin) Delete(context.Context, string) error        { return nil }
+
+var _ weaver.NotRetriable = Admin.Delete
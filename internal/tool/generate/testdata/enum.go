@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// func (x Status) IsValid() bool {
+// case Pending, Settled, Failed:
+// x.Status = serviceweaver_dec_Status_
+// panic(codegen.InvalidEnumError("Status", res))
+// enc.Int((int)(x.Status))
+
+// UNEXPECTED
+// func (x Priority) IsValid() bool {
+// case Pending, Settled, Failed, Unknown:
+// serviceweaver_dec_Priority_
+// InvalidEnumError("Priority"
+
+// Verify that enums are validated when decoded.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+//weaver:enum
+type Status int
+
+const (
+	Pending Status = iota
+	Settled
+	Failed
+	Unknown = Failed // duplicate values are checked once
+)
+
+// Priority has a hand-written IsValid method, but isn't marked //weaver:enum,
+// so decoded values are not validated.
+type Priority uint8
+
+func (p Priority) IsValid() bool { return p < 3 }
+
+type Transaction struct {
+	weaver.AutoMarshal
+	ID     string
+	Status Status
+}
+
+type foo interface {
+	Get(context.Context, Transaction) (Priority, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) Get(context.Context, Transaction) (Priority, error) {
+	return 0, nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ERROR: field Samples has tag weaver:"gorilla", but type []Sample is not a slice of structs with one int64 and one float64 field
+
+package foo
+
+import "github.com/ServiceWeaver/weaver"
+
+type Sample struct {
+	weaver.AutoMarshal
+	Timestamp int64
+	Value     float64
+	Label     string
+}
+
+type Series struct {
+	weaver.AutoMarshal
+	Samples []Sample `weaver:"gorilla"`
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ERROR: named structs are not serializable by default
+
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Shape interface {
+	isShape()
+}
+
+type Circle struct {
+	Radius float64
+}
+
+func (Circle) isShape() {}
+
+type foo interface {
+	Area(context.Context, Shape) (float64, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) Area(context.Context, Shape) (float64, error) {
+	return 0, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ERROR: must return exactly one weaver.Offset
+
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type entry struct {
+	weaver.AutoMarshal
+	Payload string
+}
+
+type foo interface {
+	Fetch(context.Context, weaver.Offset) ([]entry, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Fetch(context.Context, weaver.Offset) ([]entry, error) { return nil, nil }
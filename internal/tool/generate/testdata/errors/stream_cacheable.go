@@ -0,0 +1,32 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ERROR: a method that returns an io.ReadCloser cannot be //weaver:cacheable
+
+package foo
+
+import (
+	"context"
+	"io"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type foo interface {
+	//weaver:cacheable
+	Picture(context.Context, string) (io.ReadCloser, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Picture(context.Context, string) (io.ReadCloser, error) { return nil, nil }
@@ -0,0 +1,26 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ERROR: fields Name and Price have the same field number 1
+
+package foo
+
+import "github.com/ServiceWeaver/weaver"
+
+//weaver:tagged
+type Product struct {
+	weaver.AutoMarshal
+	Name  string `weaver:"tag=1"`
+	Price int64  `weaver:"tag=1"`
+}
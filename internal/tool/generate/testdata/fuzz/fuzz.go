@@ -0,0 +1,61 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Verify that a round-trip fuzz test is generated for every AutoMarshal type,
+// and that the tests pass.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Product struct {
+	weaver.AutoMarshal
+	Name       string
+	PriceUSD   Money
+	Categories []string
+	Stock      map[string]int32
+	Discount   *Money
+	Color      color
+	sku        [4]byte
+}
+
+type Money struct {
+	weaver.AutoMarshal
+	CurrencyCode string
+	Units        int64
+	Nanos        int32
+}
+
+//weaver:enum
+type color int
+
+const (
+	red color = iota + 1
+	green
+)
+
+type Catalog interface {
+	Get(context.Context, string) (Product, error)
+}
+
+type catalog struct {
+	weaver.Implements[Catalog]
+}
+
+func (catalog) Get(context.Context, string) (Product, error) {
+	return Product{}, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// size += 8
+// size += 16
+// size += (4 + (len(a0) * 8))
+// (a0).WeaverMarshal(enc)
+// (&a0).WeaverUnmarshal(dec)
+// (x.location).WeaverMarshal(enc)
+// (&x.location).WeaverUnmarshal(dec)
+
+// UNEXPECTED
+// func serviceweaver_size_LatLng
+// func serviceweaver_size_BBox
+
+// Verify that weaver.LatLng and weaver.BBox arguments, results, and fields are
+// serializable and have a fixed size.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type store struct {
+	weaver.AutoMarshal
+	name     string
+	location weaver.LatLng
+}
+
+type foo interface {
+	Nearest(context.Context, weaver.LatLng) (store, error)
+	Within(context.Context, weaver.BBox) ([]weaver.LatLng, error)
+	Bounds(context.Context, []weaver.LatLng) (weaver.BBox, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Nearest(context.Context, weaver.LatLng) (store, error)        { return store{}, nil }
+func (impl) Within(context.Context, weaver.BBox) ([]weaver.LatLng, error) { return nil, nil }
+func (impl) Bounds(context.Context, []weaver.LatLng) (weaver.BBox, error) { return weaver.BBox{}, nil }
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// codegen.EncodeGorilla(enc, x.Samples, func(s Sample) (int64, float64) { return s.Timestamp, s.Value })
+// x.Samples = codegen.DecodeGorilla(dec, func(t int64, v float64) Sample { return Sample{Timestamp: t, Value: v} })
+// codegen.EncodeGorilla(enc, x.Points, func(s point) (int64, float64) { return s.t, s.v })
+
+// UNEXPECTED
+// serviceweaver_enc_slice_Sample
+
+// Verify that time series tagged with weaver:"gorilla" are Gorilla compressed.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Sample struct {
+	weaver.AutoMarshal
+	Timestamp int64
+	Value     float64
+}
+
+type point struct {
+	weaver.AutoMarshal
+	v float64
+	t int64
+}
+
+type Series struct {
+	weaver.AutoMarshal
+	Name    string
+	Samples []Sample `weaver:"gorilla"`
+	Points  []point  `weaver:"gorilla"`
+}
+
+type foo interface {
+	Query(context.Context, string) (Series, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) Query(context.Context, string) (Series, error) {
+	return Series{}, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Verify that "weaver generate -http" generates JSON-over-HTTP handlers.
+package foo
+
+import (
+	"context"
+	"io"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Product struct {
+	weaver.AutoMarshal
+	ID    string
+	Price float64
+}
+
+type Catalog interface {
+	Get(context.Context, string) (Product, error)
+	Picture(context.Context, string) (io.ReadCloser, error)
+	Reset(context.Context) error
+	Search(context.Context, int, ...string) ([]Product, int, error)
+}
+
+type inventory interface {
+	Count(context.Context, string) (int, error)
+}
+
+type catalog struct {
+	weaver.Implements[Catalog]
+}
+
+func (catalog) Get(context.Context, string) (Product, error)                   { return Product{}, nil }
+func (catalog) Picture(context.Context, string) (io.ReadCloser, error)         { return nil, nil }
+func (catalog) Reset(context.Context) error                                    { return nil }
+func (catalog) Search(context.Context, int, ...string) ([]Product, int, error) { return nil, 0, nil }
+
+type inventoryImpl struct {
+	weaver.Implements[inventory]
+}
+
+func (inventoryImpl) Count(context.Context, string) (int, error) { return 0, nil }
+
+type app struct {
+	weaver.Implements[weaver.Main]
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// if codegen.Intercepted("foo/foo") {
+// err = codegen.Intercept(ctx, "foo/foo", "A", func(ctx context.Context) (err error) {
+// err = s.impl.A(ctx, a0)
+// return s.impl.A(ctx, a0)
+// appErr = codegen.Intercept(ctx, "foo/foo", "B", func(ctx context.Context) (err error) {
+// r0, r1, err = s.impl.B(ctx)
+// r0, r1, appErr = s.impl.B(ctx)
+
+// Verify that local and server stubs call methods through interceptors.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type foo interface {
+	A(context.Context, int) error
+	B(context.Context) (string, bool, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) A(context.Context, int) error {
+	return nil
+}
+
+func (l *impl) B(context.Context) (string, bool, error) {
+	return "", false, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// EXPECTED
+// codegen.EncodeWithMarshaler[*big.Float](enc, x.Amount)
+// x.Amount = codegen.DecodeWithMarshaler[*big.Float](dec)
+// codegen.EncodeWithMarshaler[*big.Float](enc, a0)
+// codegen.EncodeWithMarshaler[*big.Float](enc, arg[i])
+
+// UNEXPECTED
+// serviceweaver_enc_ptr_Float
+// serviceweaver_size_ptr_payment
+
+// Verify that types with a marshaler registered by the package are encoded
+// with it.
+package foo
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+func init() {
+	codegen.RegisterMarshaler(
+		func(enc *codegen.Encoder, f *big.Float) { enc.String(f.Text('g', -1)) },
+		func(dec *codegen.Decoder) *big.Float {
+			f, _, _ := big.ParseFloat(dec.String(), 10, 0, big.ToNearestEven)
+			return f
+		},
+	)
+}
+
+type payment struct {
+	weaver.AutoMarshal
+	Amount *big.Float
+}
+
+type foo interface {
+	Pay(context.Context, *big.Float, []*big.Float, payment) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Pay(context.Context, *big.Float, []*big.Float, payment) error { return nil }
@@ -13,6 +13,7 @@
 // limitations under the License.
 
 // EXPECTED
+// dec.SetMaxAlloc(codegen.MaxServerAlloc)
 // Preallocate
 // serviceweaver_enc_slice_X
 // serviceweaver_enc_slice_int
@@ -17,7 +17,8 @@
 // type foo_client_stub struct
 // type foo_server_stub struct
 // A(ctx context.Context, a0 string, a1 int, a2 Bar, a3 Other) (err error)
-// s.stub.Run(ctx, 0, enc.Data(), shardKey)
+// codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+// enc.Release()
 // enc.String(a0)
 // enc.Int(a1)
 // func (x *Bar) WeaverMarshal(enc *codegen.Encoder)
@@ -17,12 +17,23 @@
 // type foo_client_stub struct
 // type foo_server_stub struct
 // A(ctx context.Context) (r0 string, r1 int, r2 Bar, err error)
-// results, err = s.stub.Run(ctx, 0, nil, shardKey)
+// results, err = codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
 // r0 = dec.String()
 // r1 = dec.Int()
 // func (x *Bar) WeaverMarshal(enc *codegen.Encoder)
 // func (x *Bar) WeaverUnmarshal(dec *codegen.Decoder)
 // err = s.caller("A", ctx, []any{}, []any{&r0, &r1, &r2})
+// B(ctx context.Context) (r0 Bar, r1 []string, r2 bool, r3 int, err error)
+// (&r0).WeaverUnmarshal(dec)
+// r1 = serviceweaver_dec_slice_string_
+// r2 = dec.Bool()
+// r3 = dec.Int()
+// r0, r1, r2, r3, appErr = s.impl.B(ctx)
+// (r0).WeaverMarshal(enc)
+// serviceweaver_enc_slice_string_4af10117(enc, r1)
+// enc.Bool(r2)
+// enc.Int(r3)
+// enc.Error(appErr)
 
 // UNEXPECTED
 // Preallocate
@@ -38,6 +49,7 @@ import (
 
 type Foo interface {
 	A(context.Context) (string, int, Bar, error)
+	B(context.Context) (Bar, []string, bool, int, error)
 }
 
 type Bar struct {
@@ -49,3 +61,7 @@ type impl struct{ weaver.Implements[Foo] }
 func (l *impl) A(context.Context) (string, int, Bar, error) {
 	return "", 0, Bar{}, nil
 }
+
+func (l *impl) B(context.Context) (Bar, []string, bool, int, error) {
+	return Bar{}, nil, false, 0, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// serviceweaver_enc_slice_map_string_slice_Product_
+// serviceweaver_dec_slice_map_string_slice_Product_
+// serviceweaver_enc_map_string_slice_map_int_slice_slice_Product_
+// serviceweaver_dec_map_string_slice_map_int_slice_slice_Product_
+
+// Deeply nested slices, maps, and structs, some of which are used by more than
+// one method and by struct fields.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Product struct {
+	weaver.AutoMarshal
+	Name string
+	Tags []map[string][]string
+}
+
+type Catalog struct {
+	weaver.AutoMarshal
+	Pages []map[string][]Product
+}
+
+// Pages is an alias, so it shares helpers with []map[string][]Product.
+type Pages = []map[string][]Product
+
+// Shelves is a named type, so it gets helpers of its own.
+type Shelves [][3]map[string][]Product
+
+type foo interface {
+	A(context.Context, []map[string][]Product) ([]map[string][]Product, error)
+	B(context.Context, []map[string][]Product, Catalog) error
+	C(context.Context, map[string][]map[int][][]Product) (map[string][]map[int][][]Product, error)
+	D(context.Context, [][]map[string][]map[int][]*Product) ([]map[string][]Product, error)
+	E(context.Context, Pages, Shelves) (map[[2]string][]Pages, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) A(context.Context, []map[string][]Product) ([]map[string][]Product, error) {
+	return nil, nil
+}
+
+func (l *impl) B(context.Context, []map[string][]Product, Catalog) error {
+	return nil
+}
+
+func (l *impl) C(context.Context, map[string][]map[int][][]Product) (map[string][]map[int][][]Product, error) {
+	return nil, nil
+}
+
+func (l *impl) D(context.Context, [][]map[string][]map[int][]*Product) ([]map[string][]Product, error) {
+	return nil, nil
+}
+
+func (l *impl) E(context.Context, Pages, Shelves) (map[[2]string][]Pages, error) {
+	return nil, nil
+}
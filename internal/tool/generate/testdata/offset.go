@@ -0,0 +1,54 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// span.SetAttributes(attribute.Int64("serviceweaver.offset", int64(a0)))
+// span.SetAttributes(attribute.Int64("serviceweaver.offset", int64(a1)))
+// enc.Uint64((uint64)(a0))
+// *(*uint64)(&r1) = dec.Uint64()
+
+// Verify that the offset cursors of methods fetching entries from a log are
+// serialized and recorded in traces.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type entry struct {
+	weaver.AutoMarshal
+	Payload string
+}
+
+type foo interface {
+	Fetch(context.Context, weaver.Offset) ([]entry, weaver.Offset, error)
+	FetchN(context.Context, int, weaver.Offset) ([]entry, weaver.Offset, error)
+	Append(context.Context, entry) (weaver.Offset, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) Fetch(context.Context, weaver.Offset) ([]entry, weaver.Offset, error) {
+	return nil, 0, nil
+}
+
+func (impl) FetchN(context.Context, int, weaver.Offset) ([]entry, weaver.Offset, error) {
+	return nil, 0, nil
+}
+
+func (impl) Append(context.Context, entry) (weaver.Offset, error) {
+	return 0, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Verify that "weaver generate -openapi" describes every component method as
+// an operation, with schemas for slices, maps, and nested AutoMarshal structs.
+package foo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Money struct {
+	weaver.AutoMarshal
+	Currency string `json:"currency"`
+	Units    int64  `json:"units"`
+	Nanos    int32  `json:"nanos,omitempty"`
+}
+
+type Product struct {
+	weaver.AutoMarshal
+	ID         string            `json:"id"`
+	Price      Money             `json:"price"`
+	Categories []string          `json:"categories"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Discount   *Money            `json:"discount,omitempty"`
+	Thumbnail  []byte            `json:"thumbnail"`
+	Updated    time.Time         `json:"updated"`
+	internal   int
+}
+
+type Catalog interface {
+	Get(ctx context.Context, id string) (Product, error)
+	Search(ctx context.Context, query string, limit int, categories ...string) ([]Product, int, error)
+	Prices(ctx context.Context, ids []string) (map[string]Money, error)
+	Clear(ctx context.Context) error
+}
+
+type catalog struct{ weaver.Implements[Catalog] }
+
+func (c *catalog) Get(context.Context, string) (Product, error) {
+	return Product{}, nil
+}
+
+func (c *catalog) Search(context.Context, string, int, ...string) ([]Product, int, error) {
+	return nil, 0, nil
+}
+
+func (c *catalog) Prices(context.Context, []string) (map[string]Money, error) {
+	return nil, nil
+}
+
+func (c *catalog) Clear(context.Context) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// var a0 int
+// err = codegen.CatchServerPanics("foo/foo", "A", r, a0)
+// err = codegen.CatchServerPanics("foo/foo", "B", recover())
+
+// Verify that server stubs report panics, along with the arguments of the
+// call, to the registered panic handlers.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type foo interface {
+	A(context.Context, int) error
+	B(context.Context) (string, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) A(context.Context, int) error {
+	return nil
+}
+
+func (l *impl) B(context.Context) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// wEaVeReDgE:foo/searcher→foo/shard
+
+// Verify that weaver.Refs[T] fields are component references.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type searcher interface {
+	Search(context.Context, string) ([]string, error)
+}
+
+type shard interface {
+	Search(context.Context, string) ([]string, error)
+}
+
+type searcherImpl struct {
+	weaver.Implements[searcher]
+	shards weaver.Refs[shard]
+}
+
+func (s *searcherImpl) Search(ctx context.Context, query string) ([]string, error) {
+	results, err := weaver.CallAll(ctx, s.shards, func(ctx context.Context, shard shard) ([]string, error) {
+		return shard.Search(ctx, query)
+	})
+	var all []string
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	return all, err
+}
+
+type shardImpl struct {
+	weaver.Implements[shard]
+}
+
+func (s *shardImpl) Search(context.Context, string) ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// Routed: true,
+// shardKey := _hashCatalog(a0)
+// shardKey := _hashCatalog(a1)
+// s.addLoad(_hashCatalog(a0), 1.0)
+// func _hashCatalog(r string) uint64 {
+// var _ weaver.Unrouted = (*catalog)(nil)
+
+// UNEXPECTED
+// r.GetProduct(
+
+// Verify that methods marked //weaver:route are routed on the provided
+// argument.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Catalog interface {
+	//weaver:route(id)
+	GetProduct(ctx context.Context, id string) (string, error)
+
+	//weaver:route(a1)
+	Rename(context.Context, int, string) error
+
+	List(context.Context) ([]string, error)
+}
+
+type catalog struct{ weaver.Implements[Catalog] }
+
+func (c *catalog) GetProduct(context.Context, string) (string, error) { return "", nil }
+func (c *catalog) Rename(context.Context, int, string) error          { return nil }
+func (c *catalog) List(context.Context) ([]string, error)             { return nil, nil }
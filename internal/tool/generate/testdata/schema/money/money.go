@@ -0,0 +1,28 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package money mirrors the money type of the onlineboutique example.
+package money
+
+import "github.com/ServiceWeaver/weaver"
+
+// T represents an amount of money along with the currency type.
+//
+//weaver:tagged
+type T struct {
+	weaver.AutoMarshal
+	CurrencyCode string `weaver:"tag=1"`
+	Units        int64  `weaver:"tag=2"`
+	Nanos        int32  `weaver:"tag=3"`
+}
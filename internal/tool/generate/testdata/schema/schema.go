@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Verify that "weaver generate -schema" describes the canonical encoding of
+// every AutoMarshal type, including the types they reference.
+package foo
+
+import (
+	"context"
+
+	"foo/money"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+//weaver:enum
+type Availability int
+
+const (
+	InStock Availability = iota
+	OutOfStock
+)
+
+type Product struct {
+	weaver.AutoMarshal
+	ID          string
+	Name        string
+	Description string
+	Picture     string
+	PriceUSD    money.T
+	Categories  []string
+}
+
+type Listing struct {
+	weaver.AutoMarshal
+	Product      *Product
+	Availability Availability
+	Stock        map[string]int
+	Ratings      [5]uint32
+	History      []float64 `weaver:"rle"`
+}
+
+type Catalog interface {
+	List(context.Context, string) ([]Listing, error)
+}
+
+type catalog struct{ weaver.Implements[Catalog] }
+
+func (c *catalog) List(context.Context, string) ([]Listing, error) {
+	return nil, nil
+}
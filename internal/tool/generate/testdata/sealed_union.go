@@ -0,0 +1,83 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// func serviceweaver_enc_Shape_
+// switch x := arg.(type) {
+// case nil:
+// enc.Uint32(0)
+// case *Circle:
+// enc.Uint32(1)
+// case Square:
+// enc.Uint32(2)
+// (x).WeaverMarshal(enc)
+// panic(codegen.UnknownVariantError("Shape", x))
+// func serviceweaver_dec_Shape_
+// switch tag := dec.Uint32(); tag {
+// var res *Circle
+// var res Square
+// (&res).WeaverUnmarshal(dec)
+// panic(codegen.UnknownTagError("Shape", tag))
+// r0 = serviceweaver_dec_Shape_
+
+// UNEXPECTED
+// case Triangle:
+
+// Verify that sealed unions are encoded as a tag followed by the variant.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Shape interface {
+	isShape()
+}
+
+type Circle struct {
+	weaver.AutoMarshal
+	Radius float64
+}
+
+func (*Circle) isShape() {}
+
+type Square struct {
+	weaver.AutoMarshal
+	Side float64
+}
+
+func (Square) isShape() {}
+
+// Triangle doesn't implement Shape.
+type Triangle struct {
+	weaver.AutoMarshal
+	A, B, C float64
+}
+
+type foo interface {
+	Area(context.Context, Shape) (float64, error)
+	Largest(context.Context, []Shape) (Shape, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) Area(context.Context, Shape) (float64, error) {
+	return 0, nil
+}
+
+func (l *impl) Largest(context.Context, []Shape) (Shape, error) {
+	return nil, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// Routed: true,
+// func _hashStore(r tenantKey) uint64 {
+// return codegen.ClampShardKey(r.Shard())
+// enc.WriteUint64(r.Shard())
+// shardKey := _hashStore(r.Get(ctx, a0, a1))
+// func _hashCatalog(r sku) uint64 {
+// return codegen.ClampShardKey(r.Shard())
+// shardKey := _hashCatalog(a0)
+
+// UNEXPECTED
+// h.WriteString(string(r.Tenant))
+
+// Verify that routing keys that implement weaver.ShardKey are hashed by their
+// Shard method.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type tenantKey struct {
+	Tenant string
+	Region string
+}
+
+func (k tenantKey) Shard() uint64 { return weaver.HashStrings(k.Tenant, k.Region) }
+
+type Store interface {
+	Get(ctx context.Context, tenant, region string) (string, error)
+}
+
+type storeRouter struct{}
+
+func (storeRouter) Get(_ context.Context, tenant, region string) tenantKey {
+	return tenantKey{tenant, region}
+}
+
+type store struct {
+	weaver.Implements[Store]
+	weaver.WithRouter[storeRouter]
+}
+
+func (s *store) Get(context.Context, string, string) (string, error) { return "", nil }
+
+// sku is a routing key that isn't a valid routing key on its own.
+type sku struct {
+	weaver.AutoMarshal
+	Parts []string
+}
+
+func (s sku) Shard() uint64 { return weaver.HashStrings(s.Parts...) }
+
+type Catalog interface {
+	//weaver:route(id)
+	GetProduct(ctx context.Context, id sku) (string, error)
+}
+
+type catalog struct{ weaver.Implements[Catalog] }
+
+func (c *catalog) GetProduct(context.Context, sku) (string, error) { return "", nil }
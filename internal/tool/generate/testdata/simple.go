@@ -22,7 +22,8 @@
 // type foo_local_stub struct
 // type foo_client_stub struct
 // M(ctx context.Context) (err error) {
-// s.stub.Run(ctx, 0, nil, shardKey)
+// codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
+// codegen.LogAccess(ctx, s.stub, 0, begin, err)
 // type foo_server_stub struct
 // func (s foo_server_stub) GetStubFn
 // func (s foo_server_stub) m(ctx
@@ -31,6 +32,7 @@
 // UNEXPECTED
 // Preallocate
 // Routed:
+// serviceweaver.shard_key
 
 // Simple method with no arguments and results.
 package foo
@@ -0,0 +1,45 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// ctx = codegen.WithStream(ctx)
+// r0 = codegen.DecodeStream(ctx, s.stub, 1, shardKey, dec)
+// if res, ok := codegen.ServeStream(ctx, args); ok {
+// codegen.EncodeStream(ctx, enc, r0, appErr)
+// enc.String(r1)
+
+// UNEXPECTED
+// serviceweaver_enc_io_ReadCloser
+
+// Verify that methods returning an io.ReadCloser stream their bodies.
+package foo
+
+import (
+	"context"
+	"io"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Catalog interface {
+	GetProduct(ctx context.Context, id string) (string, error)
+	Picture(ctx context.Context, id string) (io.ReadCloser, string, error)
+}
+
+type catalog struct{ weaver.Implements[Catalog] }
+
+func (c *catalog) GetProduct(context.Context, string) (string, error) { return "", nil }
+func (c *catalog) Picture(context.Context, string) (io.ReadCloser, string, error) {
+	return nil, "", nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sub3 contains a component with a non-retriable method, embedded by
+// components in other packages.
+package sub3
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Store interface {
+	Get(context.Context, string) (string, error)
+	Put(context.Context, string, string) error
+}
+
+type store struct{ weaver.Implements[Store] }
+
+func (s *store) Get(context.Context, string) (string, error) { return "", nil }
+func (s *store) Put(context.Context, string, string) error   { return nil }
+
+var _ weaver.NotRetriable = Store.Put
@@ -0,0 +1,64 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// codegen.EncodeTaggedField(enc, 1, func(enc *codegen.Encoder) {
+// enc.String(x.Name)
+// codegen.EncodeTaggedField(enc, 4, func(enc *codegen.Encoder) {
+// codegen.EncodeTaggedField(enc, 3, func(enc *codegen.Encoder) {
+// codegen.EncodeTaggedEnd(enc)
+// *x = Product{}
+// codegen.DecodeTaggedFields(dec, func(field uint32, dec *codegen.Decoder) bool {
+// case 4:
+// x.Stock = dec.Int32()
+// case 3:
+// enc.String(x.Label)
+
+// UNEXPECTED
+// case 2:
+// x._
+
+// Verify that AutoMarshal structs marked //weaver:tagged are encoded with the
+// field numbers of their weaver:"tag=N" struct tags, that the numbers don't
+// depend on the order of the fields, and that blank fields reserve their
+// numbers.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+//weaver:tagged
+type Product struct {
+	weaver.AutoMarshal
+	Name   string    `weaver:"tag=1"`
+	_      float64   `weaver:"tag=2"` // removed field
+	Stock  int32     `weaver:"tag=4"`
+	Prices []float64 `weaver:"rle,tag=3"`
+}
+
+type Label struct {
+	weaver.AutoMarshal
+	Label string
+}
+
+type foo interface {
+	M(context.Context, Product, Label) error
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) M(context.Context, Product, Label) error { return nil }
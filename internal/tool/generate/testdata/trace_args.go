@@ -0,0 +1,54 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// span.SetAttributes(attribute.String("serviceweaver.arg.id", string(a0)))
+// span.SetAttributes(attribute.String("serviceweaver.arg.name", string(a1)), attribute.Int64("serviceweaver.arg.a2", int64(a2)))
+// span.SetAttributes(attribute.Int64("serviceweaver.arg.count", int64(a0)))
+
+// UNEXPECTED
+// serviceweaver.arg.tags
+// serviceweaver.arg.ids
+
+// Verify that the arguments of methods marked //weaver:trace_args are
+// recorded as span attributes.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type SKU string
+
+type Catalog interface {
+	//weaver:trace_args(id)
+	GetProduct(ctx context.Context, id SKU, tags []string) (string, error)
+
+	//weaver:trace_args
+	Rename(ctx context.Context, ids []string, name string, _ uint8) error
+
+	//weaver:trace_args(count)
+	List(ctx context.Context, count int, tags ...string) ([]string, error)
+
+	Delete(ctx context.Context, id string) error
+}
+
+type catalog struct{ weaver.Implements[Catalog] }
+
+func (c *catalog) GetProduct(context.Context, SKU, []string) (string, error) { return "", nil }
+func (c *catalog) Rename(context.Context, []string, string, uint8) error     { return nil }
+func (c *catalog) List(context.Context, int, ...string) ([]string, error)    { return nil, nil }
+func (c *catalog) Delete(context.Context, string) error                      { return nil }
@@ -0,0 +1,64 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// enc.Bytes16(a0)
+// r0 = dec.Bytes16()
+// enc.Bytes16(x.id)
+// x.id = dec.Bytes16()
+// enc.Bytes16(([16]byte)(x.digest))
+// *(*[16]byte)(&x.digest) = dec.Bytes16()
+// enc.ByteArray(arg[:])
+// dec.ByteArray(res[:])
+
+// UNEXPECTED
+// EncodeBinaryMarshaler
+// DecodeBinaryUnmarshaler
+// func serviceweaver_enc_array_16_byte
+// func serviceweaver_enc_UUID
+
+// Verify that UUIDs and byte arrays are encoded as raw bytes.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/google/uuid"
+)
+
+type Digest [16]byte
+
+type Hash [32]byte
+
+type order struct {
+	weaver.AutoMarshal
+	id     uuid.UUID
+	digest Digest
+	hash   Hash
+	parent *uuid.UUID
+	items  []uuid.UUID
+}
+
+type foo interface {
+	A(context.Context, uuid.UUID) (uuid.UUID, error)
+	B(context.Context, [16]byte, [32]byte) error
+	C(context.Context, order) (map[uuid.UUID][]Hash, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (impl) A(context.Context, uuid.UUID) (uuid.UUID, error)        { return uuid.UUID{}, nil }
+func (impl) B(context.Context, [16]byte, [32]byte) error            { return nil }
+func (impl) C(context.Context, order) (map[uuid.UUID][]Hash, error) { return nil, nil }
@@ -12,6 +12,23 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// EXPECTED
+// if a0 == nil {
+// a0 = []int{}
+// if a1 == nil {
+// a1 = []int{}
+// if a2 == nil {
+// a2 = [][]int{}
+// if a1 == nil {
+// a1 = []string{}
+// size += 4
+// for _, v := range a1 {
+// size += (4 + len(v))
+// shardKey := _hashFoo(r.E(ctx, a0, a1...))
+// s.addLoad(_hashFoo(r.E(ctx, a0, a1...)), 1.0)
+// return s.impl.E(ctx, a0, a1...)
+
+// Verify that variadic methods are supported.
 package foo
 
 import (
@@ -25,13 +42,20 @@ type foo interface {
 	B(context.Context, bool, ...int) error
 	C(context.Context, bool, string, ...int) error
 	D(context.Context, bool, string, ...[]int) error
+	E(context.Context, int, ...string) error
 }
 
 type impl struct {
 	weaver.Implements[foo]
+	weaver.WithRouter[fooRouter]
 }
 
 func (l *impl) A(context.Context, ...int) error                 { return nil }
 func (l *impl) B(context.Context, bool, ...int) error           { return nil }
 func (l *impl) C(context.Context, bool, string, ...int) error   { return nil }
 func (l *impl) D(context.Context, bool, string, ...[]int) error { return nil }
+func (l *impl) E(context.Context, int, ...string) error         { return nil }
+
+type fooRouter struct{}
+
+func (fooRouter) E(_ context.Context, shard int, _ ...string) int { return shard }
@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// EXPECTED
+// func (x *Account) WeaverVersion() uint64 {
+// return uint64(x.Version)
+// err = codegen.CallVersioned(ctx, s.impl, func() (err error) {
+// r0, err = s.impl.Update(ctx, a0, a1, a2)
+// }, &a0, a2)
+// appErr := codegen.CallVersioned(ctx, s.impl, func() (appErr error) {
+// r0, appErr = s.impl.Update(ctx, a0, a1, a2)
+// return s.impl.Get(ctx, a0)
+// r0, appErr = s.impl.Get(ctx, a0)
+
+// UNEXPECTED
+// func (x *Balance) WeaverVersion() uint64 {
+
+// Verify that methods receiving versioned entities check their versions.
+package foo
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+type Account struct {
+	weaver.AutoMarshal
+	Name    string
+	Version uint32 `weaver:"version"`
+}
+
+type Balance struct {
+	weaver.AutoMarshal
+	Amount int
+}
+
+type foo interface {
+	Get(context.Context, string) (Account, error)
+	Update(context.Context, Account, Balance, *Account) (bool, error)
+}
+
+type impl struct{ weaver.Implements[foo] }
+
+func (l *impl) Get(context.Context, string) (Account, error) {
+	return Account{}, nil
+}
+
+func (l *impl) Update(context.Context, Account, Balance, *Account) (bool, error) {
+	return false, nil
+}
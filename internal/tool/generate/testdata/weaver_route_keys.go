@@ -18,6 +18,8 @@
 // true,
 // func _hashFoo(r fooKey) uint64
 // func _orderedCodeFoo(r fooKey) codegen.OrderedCode
+// if span.SpanContext().IsValid() && shardKey != 0 {
+// span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "foo.foo.M"))
 
 // Service Weaver route keys.
 package foo
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"go/types"
 	"path"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -36,6 +37,7 @@ type typeSet struct {
 
 	automarshals          *typeutil.Map // types that implement AutoMarshal
 	automarshalCandidates *typeutil.Map // types that declare themselves AutoMarshal
+	marshalers            *typeutil.Map // types with a marshaler registered by pkg
 
 	// If checked[t] != nil, then checked[t] is the cached result of calling
 	// check(pkg, t, string[]{}). Otherwise, if checked[t] == nil, then t has
@@ -48,6 +50,10 @@ type typeSet struct {
 
 	// If measurable[t] != nil, then measurable[t] == isMeasurableType(t).
 	measurable typeutil.Map
+
+	// If unions[t] != nil, then unions[t] is the result of calling
+	// findUnionVariants(t).
+	unions typeutil.Map
 }
 
 // importPkg is a package imported by the generated code.
@@ -99,9 +105,36 @@ func newTypeSet(pkg *packages.Package, automarshals, automarshalCandidates *type
 		importedByName:        map[string]importPkg{},
 		automarshals:          automarshals,
 		automarshalCandidates: automarshalCandidates,
+		marshalers:            findRegisteredMarshalers(pkg),
 	}
 }
 
+// findRegisteredMarshalers returns the types T for which the provided package
+// calls codegen.RegisterMarshaler[T].
+func findRegisteredMarshalers(pkg *packages.Package) *typeutil.Map {
+	var marshalers typeutil.Map
+	if pkg.TypesInfo == nil {
+		return &marshalers
+	}
+	codegen := path.Join(weaverPackagePath, "runtime", "codegen")
+	for id, inst := range pkg.TypesInfo.Instances {
+		fn, ok := pkg.TypesInfo.Uses[id].(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != codegen || fn.Name() != "RegisterMarshaler" {
+			continue
+		}
+		if inst.TypeArgs.Len() == 1 {
+			marshalers.Set(inst.TypeArgs.At(0), true)
+		}
+	}
+	return &marshalers
+}
+
+// hasMarshaler returns whether the package registers a marshaler for the
+// provided type with codegen.RegisterMarshaler.
+func (tset *typeSet) hasMarshaler(t types.Type) bool {
+	return tset.marshalers != nil && tset.marshalers.At(t) != nil
+}
+
 // importPackage imports a package with the provided path and package name. The
 // package is imported with an alias if there is a package name clash.
 func (tset *typeSet) importPackage(path, pkg string) importPkg {
@@ -275,6 +308,12 @@ func (tset *typeSet) checkSerializable(t types.Type) []error {
 			return false
 		}
 
+		// Types with a registered marshaler are encoded by it.
+		if tset.hasMarshaler(t) {
+			tset.checked.Set(t, true)
+			return true
+		}
+
 		// Check for recursive types.
 		if stack.At(t) != nil {
 			addError(fmt.Errorf("serialization of recursive types not currently supported"))
@@ -284,17 +323,46 @@ func (tset *typeSet) checkSerializable(t types.Type) []error {
 		stack.Set(t, struct{}{})
 		defer func() { stack.Delete(t) }()
 
-		switch x := t.(type) {
+		switch x := unalias(t).(type) {
 		case *types.Named:
 			// No need to check if x is an unexported type from another package
 			// since the Go compiler takes care of that.
 
+			// Errors are encoded with codegen.Encoder.Error.
+			if isError(x) {
+				tset.checked.Set(t, true)
+				break
+			}
+
 			// Check if the type implements one of the marshaler interfaces.
 			if tset.isProto(x) || tset.automarshals.At(t) != nil || tset.implementsAutoMarshal(x) || tset.hasMarshalBinary(x) {
 				tset.checked.Set(t, true)
 				break
 			}
 
+			// Check if the type is a sealed union, in which case every
+			// variant has to be serializable.
+			if variants, ok := tset.unionVariants(x); ok {
+				if len(variants) == 0 {
+					addError(fmt.Errorf("sealed union has no variants"))
+					tset.checked.Set(t, false)
+					break
+				}
+				local := x.Obj().Pkg() == tset.pkg.Types
+				serializable := true
+				for _, v := range variants {
+					if !local && !isExported(v) {
+						addError(fmt.Errorf("sealed union variant %v is not exported", v))
+						serializable = false
+						continue
+					}
+					b := check(v, path+".("+v.String()+")", true)
+					serializable = serializable && b
+				}
+				tset.checked.Set(t, serializable)
+				break
+			}
+
 			// If the underlying type is not a struct, then we simply recurse
 			// on the underlying type.
 			s, ok := x.Underlying().(*types.Struct)
@@ -329,10 +397,17 @@ func (tset *typeSet) checkSerializable(t types.Type) []error {
 			tset.checked.Set(t, serializable)
 
 		case *types.Interface:
-			// TODO(sanjay): Support types.Interface only if we can figure out
-			// a way to instantiate the type.
-			addError(fmt.Errorf("serialization of interfaces not currently supported"))
-			tset.checked.Set(t, false)
+			// An empty interface holds values of the types registered with
+			// codegen.RegisterType, which are checked when encoded.
+			//
+			// TODO(sanjay): Support non-empty interfaces only if we can
+			// figure out a way to instantiate the type.
+			if !x.Empty() {
+				addError(fmt.Errorf("serialization of non-empty interfaces not currently supported"))
+				tset.checked.Set(t, false)
+				break
+			}
+			tset.checked.Set(t, true)
 
 		case *types.Struct:
 			addError(fmt.Errorf("struct literals are not serializable"))
@@ -365,6 +440,12 @@ func (tset *typeSet) checkSerializable(t types.Type) []error {
 			tset.checked.Set(t, check(x.Elem(), path+"[0]", true))
 
 		case *types.Pointer:
+			if isBigIntPtr(x) || isBigRatPtr(x) {
+				// *big.Int and *big.Rat are encoded directly by
+				// codegen.Encoder.BigInt and codegen.Encoder.BigRat.
+				tset.checked.Set(t, true)
+				break
+			}
 			tset.checked.Set(t, check(x.Elem(), "(*"+path+")", true))
 
 		case *types.Map:
@@ -373,6 +454,11 @@ func (tset *typeSet) checkSerializable(t types.Type) []error {
 			tset.checked.Set(t, keySerializable && valSerializable)
 
 		default:
+			if isEmptyInterface(t) {
+				// E.g., the alias any.
+				tset.checked.Set(t, true)
+				break
+			}
 			addError(fmt.Errorf("not a serializable type"))
 			// For a better error message, we don't memoize this.
 			return false
@@ -404,13 +490,20 @@ func (tset *typeSet) sizeOfType(t types.Type) int {
 	//   s(basic) = size of basic
 	//   s([N]t) = N * s(t), if t is fixed size
 	//   s(struct{..., fi:ti, ...}) = sum of s(ti), if every ti is fixed size
+	//   s(weaver.LatLng) = 8
+	//   s(weaver.BBox) = 16
 	//   s(type t u) = s(u)
 	//   s(_) = -1
+	//
+	// Types with a registered marshaler are never fixed size.
 	if size := tset.sizes.At(t); size != nil {
 		return size.(int)
 	}
+	if tset.hasMarshaler(t) {
+		return -1
+	}
 
-	switch x := t.(type) {
+	switch x := unalias(t).(type) {
 	case *types.Basic:
 		switch x.Kind() {
 		case types.Bool, types.Int8, types.Uint8:
@@ -451,6 +544,14 @@ func (tset *typeSet) sizeOfType(t types.Type) int {
 		return size
 
 	case *types.Named:
+		switch {
+		case isWeaverLatLng(x):
+			// Two fixed-precision int32 coordinates.
+			return 8
+		case isWeaverBBox(x):
+			// Two LatLngs.
+			return 16
+		}
 		size := tset.sizeOfType(x.Underlying())
 		tset.sizes.Set(t, size)
 		return size
@@ -490,13 +591,19 @@ func (tset *typeSet) isMeasurable(t types.Type) bool {
 	//     m(map[k]v) = true if k and v are fixed size.
 	//     m(struct{..., fi:ti, ...}) = true, if every ti is measurable.
 	//     m(weaver.AutoMarshal) = true
+	//     m(weaver.LatLng) = m(weaver.BBox) = true
 	//     m(type t u) = m(u), if t is package local
 	//     m(_) = false
+	//
+	// Types with a registered marshaler are never measurable.
 	if result := tset.measurable.At(t); result != nil {
 		return result.(bool)
 	}
+	if tset.hasMarshaler(t) {
+		return false
+	}
 
-	switch x := t.(type) {
+	switch x := unalias(t).(type) {
 	case *types.Basic:
 		switch x.Kind() {
 		case types.Bool,
@@ -536,7 +643,7 @@ func (tset *typeSet) isMeasurable(t types.Type) bool {
 		tset.measurable.Set(t, measurable)
 
 	case *types.Named:
-		if isWeaverAutoMarshal(x) {
+		if isWeaverAutoMarshal(x) || isWeaverLatLng(x) || isWeaverBBox(x) {
 			tset.measurable.Set(t, true)
 		} else if x.Obj().Pkg() != rootPkg {
 			tset.measurable.Set(t, false)
@@ -578,6 +685,32 @@ func (tset *typeSet) genTypeString(t types.Type) string {
 	return types.TypeString(t, qualifier)
 }
 
+// unalias returns t with any type aliases resolved. For example, given "type
+// Pages = []Page", unalias returns []Page for Pages. Note that only t itself
+// is resolved, not the types it is composed of.
+func unalias(t types.Type) types.Type {
+	// Aliases are represented by *types.Alias, which has an Rhs method, in
+	// Go 1.23 and above. We don't refer to types.Alias directly since we
+	// support older versions of Go.
+	for {
+		alias, ok := t.(interface{ Rhs() types.Type })
+		if !ok {
+			return t
+		}
+		t = alias.Rhs()
+	}
+}
+
+// isEmptyInterface returns whether t is an unnamed empty interface type, like
+// interface{} or its alias any.
+func isEmptyInterface(t types.Type) bool {
+	if _, ok := t.(*types.Named); ok {
+		return false
+	}
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
 // isInvalid returns true iff the given type is invalid.
 func isInvalid(t types.Type) bool {
 	return t.String() == "invalid type"
@@ -780,6 +913,85 @@ func (tset *typeSet) hasMarshalBinary(t types.Type) bool {
 	return isMarshalBinary(t, marshal) && isUnmarshalBinary(t, unmarshal)
 }
 
+// unionVariants returns whether t is a sealed union and, if so, the variants
+// of the union. A sealed union is a named interface type whose only method is
+// an unexported marker method with no arguments and no results. For example:
+//
+//	type SearchResult interface {
+//	    isSearchResult()
+//	}
+//
+// Because the marker method is unexported, only types declared in the same
+// package as the union can implement it. The variants of the union are the
+// named types T in that package for which T (or otherwise *T) implements the
+// union. Variants are returned in the order they are declared in the
+// package scope (i.e., sorted by name), which determines their tags.
+func (tset *typeSet) unionVariants(t *types.Named) ([]types.Type, bool) {
+	if result := tset.unions.At(t); result != nil {
+		variants := result.([]types.Type)
+		return variants, variants != nil
+	}
+	variants, ok := findUnionVariants(t)
+	if !ok {
+		tset.unions.Set(t, []types.Type(nil))
+		return nil, false
+	}
+	if variants == nil {
+		variants = []types.Type{}
+	}
+	tset.unions.Set(t, variants)
+	return variants, true
+}
+
+// findUnionVariants is the uncached version of unionVariants.
+func findUnionVariants(t *types.Named) ([]types.Type, bool) {
+	iface, ok := t.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() != 1 || t.TypeParams().Len() != 0 {
+		return nil, false
+	}
+	m := iface.Method(0)
+	sig := m.Type().(*types.Signature)
+	if m.Exported() || sig.Params().Len() != 0 || sig.Results().Len() != 0 {
+		return nil, false
+	}
+	pkg := t.Obj().Pkg()
+	if pkg == nil {
+		return nil, false
+	}
+
+	var variants []types.Type
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		n, ok := tn.Type().(*types.Named)
+		if !ok || n.TypeParams().Len() != 0 {
+			continue
+		}
+		if _, ok := n.Underlying().(*types.Interface); ok {
+			continue
+		}
+		if types.Implements(n, iface) {
+			variants = append(variants, n)
+		} else if p := types.NewPointer(n); types.Implements(p, iface) {
+			variants = append(variants, p)
+		}
+	}
+	return variants, true
+}
+
+// isExported returns whether the named type t, or the named type t points to,
+// is exported.
+func isExported(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Exported()
+}
+
 func isByteSlice(t types.Type) bool {
 	s, ok := t.(*types.Slice)
 	if !ok {
@@ -854,6 +1066,286 @@ func isUnmarshalBinary(t types.Type, m *types.Func) bool {
 	}
 }
 
+// weaverTag returns the encoding in the `weaver:"..."` struct tag of the i-th
+// field of s (e.g., "rle"), or "" if the field doesn't have one. The field
+// number of a field of a tagged struct (see fieldNumberTag) is not part of
+// the encoding, so the encoding of a field tagged `weaver:"rle,tag=2"` is
+// "rle".
+func weaverTag(s *types.Struct, i int) string {
+	encoding, _, _ := splitWeaverTag(s, i)
+	return encoding
+}
+
+// fieldNumberTag returns the "N" in the `weaver:"tag=N"` struct tag of the
+// i-th field of s, and whether the field has one. Fields of structs marked
+// //weaver:tagged are encoded with these numbers (see findTagged).
+func fieldNumberTag(s *types.Struct, i int) (string, bool) {
+	_, number, ok := splitWeaverTag(s, i)
+	return number, ok
+}
+
+// splitWeaverTag splits the `weaver:"..."` struct tag of the i-th field of s
+// into its encoding and its field number, if any.
+func splitWeaverTag(s *types.Struct, i int) (encoding, number string, hasNumber bool) {
+	var encodings []string
+	for _, opt := range strings.Split(reflect.StructTag(s.Tag(i)).Get("weaver"), ",") {
+		if n, ok := strings.CutPrefix(opt, "tag="); ok {
+			number, hasNumber = n, true
+		} else if opt != "" {
+			encodings = append(encodings, opt)
+		}
+	}
+	return strings.Join(encodings, ","), number, hasNumber
+}
+
+// checkWeaverTags checks that the `weaver:"..."` struct tags on the fields of
+// the AutoMarshal struct t are valid. For example, the following field is
+// run-length encoded:
+//
+//	type Matrix struct {
+//	    weaver.AutoMarshal
+//	    Prices []float64 `weaver:"rle"`
+//	}
+//
+// and the following field is Gorilla compressed:
+//
+//	type Series struct {
+//	    weaver.AutoMarshal
+//	    Samples []Sample `weaver:"gorilla"`
+//	}
+//
+// and the following field is encoded as a bitset:
+//
+//	type Features struct {
+//	    weaver.AutoMarshal
+//	    Enabled []bool `weaver:"bitset"`
+//	}
+//
+// and the following last field is encoded without a length prefix:
+//
+//	type Message struct {
+//	    weaver.AutoMarshal
+//	    Metadata map[string]string
+//	    Payload  []byte `weaver:"tail"`
+//	}
+//
+// A struct with a tail field extends to the end of the encoding that contains
+// it, so it can't be nested inside another type (see containsTail).
+func checkWeaverTags(pkg *packages.Package, t *types.Named) []error {
+	s, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var errs []error
+	var version string // name of the version field, if any
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		switch tag := weaverTag(s, i); tag {
+		case "":
+			if containsTail(f.Type()) {
+				errs = append(errs, fmt.Errorf(`field %s has type %s, which contains a struct with a weaver:"tail" field. Such structs can only be the last argument of a component method`, f.Name(), formatType(pkg, f.Type())))
+			}
+		case "rle":
+			if !isRLEEncodable(f.Type()) {
+				errs = append(errs, fmt.Errorf(`field %s has tag weaver:"rle", but type %s is not a slice of primitive types`, f.Name(), formatType(pkg, f.Type())))
+			}
+		case "bitset":
+			if !isBitsetEncodable(f.Type()) {
+				errs = append(errs, fmt.Errorf(`field %s has tag weaver:"bitset", but type %s is not a slice of bool`, f.Name(), formatType(pkg, f.Type())))
+			}
+		case "gorilla":
+			if _, _, ok := gorillaFields(pkg, f.Type()); !ok {
+				errs = append(errs, fmt.Errorf(`field %s has tag weaver:"gorilla", but type %s is not a slice of structs with one int64 and one float64 field`, f.Name(), formatType(pkg, f.Type())))
+			}
+		case "version":
+			if !isInteger(f.Type()) {
+				errs = append(errs, fmt.Errorf(`field %s has tag weaver:"version", but type %s is not an integer type`, f.Name(), formatType(pkg, f.Type())))
+			}
+			if version != "" {
+				errs = append(errs, fmt.Errorf(`fields %s and %s both have tag weaver:"version"`, version, f.Name()))
+			}
+			version = f.Name()
+		case "tail":
+			if i != s.NumFields()-1 {
+				errs = append(errs, fmt.Errorf(`field %s has tag weaver:"tail", but is not the last field`, f.Name()))
+			}
+			if !isTailEncodable(f.Type()) {
+				errs = append(errs, fmt.Errorf(`field %s has tag weaver:"tail", but type %s is not a slice of bytes`, f.Name(), formatType(pkg, f.Type())))
+			}
+		default:
+			errs = append(errs, fmt.Errorf(`field %s has unknown tag weaver:%q`, f.Name(), tag))
+		}
+	}
+	return errs
+}
+
+// isRLEEncodable returns whether values of type t can be run-length encoded.
+// Only slices of (unnamed) primitive types can be.
+func isRLEEncodable(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	b, ok := s.Elem().(*types.Basic)
+	if !ok {
+		return false
+	}
+	switch b.Kind() {
+	case types.Bool,
+		types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+		types.Float32, types.Float64,
+		types.Complex64, types.Complex128,
+		types.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// isBitsetEncodable returns whether values of type t can be encoded as a
+// bitset. Only slices of (unnamed) bool can be.
+func isBitsetEncodable(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	return ok && types.Identical(s.Elem(), types.Typ[types.Bool])
+}
+
+// isTailEncodable returns whether values of type t can be encoded as the tail
+// of a struct. Only slices of bytes can be.
+func isTailEncodable(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	return ok && types.Identical(s.Elem(), types.Typ[types.Byte])
+}
+
+// hasTail returns whether t, or the type t points to, is a struct whose last
+// field has a `weaver:"tail"` tag.
+func hasTail(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, ok := t.Underlying().(*types.Struct)
+	return ok && s.NumFields() > 0 && weaverTag(s, s.NumFields()-1) == "tail"
+}
+
+// containsTail returns whether a value of type t contains a struct with a
+// `weaver:"tail"` field, either directly (see hasTail) or nested inside
+// another type.
+func containsTail(t types.Type) bool {
+	seen := map[types.Type]bool{}
+	var contains func(t types.Type) bool
+	contains = func(t types.Type) bool {
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		if hasTail(t) {
+			return true
+		}
+		switch x := unalias(t).(type) {
+		case *types.Named:
+			return contains(x.Underlying())
+		case *types.Pointer:
+			return contains(x.Elem())
+		case *types.Array:
+			return contains(x.Elem())
+		case *types.Slice:
+			return contains(x.Elem())
+		case *types.Map:
+			return contains(x.Key()) || contains(x.Elem())
+		case *types.Struct:
+			for i := 0; i < x.NumFields(); i++ {
+				if contains(x.Field(i).Type()) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return contains(t)
+}
+
+// gorillaFields returns the names of the timestamp and value fields of the
+// elements of a time series of type t, or false if values of type t cannot be
+// Gorilla compressed. A time series is a slice of structs with exactly one
+// int64 field (the timestamp) and one float64 field (the value), ignoring an
+// embedded weaver.AutoMarshal. For example:
+//
+//	type Sample struct {
+//	    weaver.AutoMarshal
+//	    Timestamp int64
+//	    Value     float64
+//	}
+//
+// The fields must be accessible from pkg.
+func gorillaFields(pkg *packages.Package, t types.Type) (string, string, bool) {
+	sl, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return "", "", false
+	}
+	s, ok := sl.Elem().Underlying().(*types.Struct)
+	if !ok {
+		return "", "", false
+	}
+	var timestamp, value string
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		if f.Embedded() && isWeaverAutoMarshal(f.Type()) {
+			continue
+		}
+		if !f.Exported() && f.Pkg() != pkg.Types {
+			return "", "", false
+		}
+		b, ok := f.Type().(*types.Basic)
+		switch {
+		case ok && b.Kind() == types.Int64 && timestamp == "":
+			timestamp = f.Name()
+		case ok && b.Kind() == types.Float64 && value == "":
+			value = f.Name()
+		default:
+			return "", "", false
+		}
+	}
+	return timestamp, value, timestamp != "" && value != ""
+}
+
+// versionField returns the name of the field of t tagged with
+// `weaver:"version"`, or false if t is not an AutoMarshal struct with such a
+// field. For example, Version is the version field of the following struct:
+//
+//	type Account struct {
+//	    weaver.AutoMarshal
+//	    Balance int
+//	    Version uint64 `weaver:"version"`
+//	}
+func versionField(t types.Type) (string, bool) {
+	n, ok := t.(*types.Named)
+	if !ok {
+		return "", false
+	}
+	s, ok := n.Underlying().(*types.Struct)
+	if !ok {
+		return "", false
+	}
+	automarshal := false
+	version := ""
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		switch {
+		case f.Embedded() && isWeaverAutoMarshal(f.Type()):
+			automarshal = true
+		case weaverTag(s, i) == "version" && isInteger(f.Type()):
+			version = f.Name()
+		}
+	}
+	return version, automarshal && version != ""
+}
+
+// isInteger returns whether t's underlying type is an integer type.
+func isInteger(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
 // isWeaverType returns true iff t is a named type from the weaver package with
 // the specified name and n type arguments.
 func isWeaverType(t types.Type, name string, n int) bool {
@@ -873,6 +1365,10 @@ func isWeaverRef(t types.Type) bool {
 	return isWeaverType(t, "Ref", 1)
 }
 
+func isWeaverRefs(t types.Type) bool {
+	return isWeaverType(t, "Refs", 1)
+}
+
 func isWeaverListener(t types.Type) bool {
 	return isWeaverType(t, "Listener", 0)
 }
@@ -893,6 +1389,76 @@ func isWeaverNotRetriable(t types.Type) bool {
 	return isWeaverType(t, "NotRetriable", 0)
 }
 
+func isWeaverLatLng(t types.Type) bool {
+	return isWeaverType(t, "LatLng", 0)
+}
+
+func isWeaverBBox(t types.Type) bool {
+	return isWeaverType(t, "BBox", 0)
+}
+
+func isWeaverOffset(t types.Type) bool {
+	return isWeaverType(t, "Offset", 0)
+}
+
+// isBigIntPtr returns true iff t is *big.Int.
+func isBigIntPtr(t types.Type) bool {
+	return isBigPtr(t, "Int")
+}
+
+// isBigRatPtr returns true iff t is *big.Rat.
+func isBigRatPtr(t types.Type) bool {
+	return isBigPtr(t, "Rat")
+}
+
+// isBigPtr returns true iff t is a pointer to the named type of the math/big
+// package.
+func isBigPtr(t types.Type, name string) bool {
+	p, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := p.Elem().(*types.Named)
+	return ok &&
+		named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == "math/big" &&
+		named.Obj().Name() == name
+}
+
+// isByteArray returns true iff t is an array of bytes, e.g., [32]byte.
+func isByteArray(t types.Type) bool {
+	a, ok := unalias(t).(*types.Array)
+	if !ok {
+		return false
+	}
+	b, ok := unalias(a.Elem()).(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+// isBytes16 returns true iff t is [16]byte.
+func isBytes16(t types.Type) bool {
+	return isByteArray(t) && unalias(t).(*types.Array).Len() == 16
+}
+
+// uuidPackages are the paths of the packages that declare well-known UUID
+// types. Every such package declares a "type UUID [16]byte".
+var uuidPackages = map[string]bool{
+	"github.com/google/uuid":    true,
+	"github.com/gofrs/uuid":     true,
+	"github.com/gofrs/uuid/v5":  true,
+	"github.com/satori/go.uuid": true,
+}
+
+// isUUID returns true iff t is one of the well-known UUID types, e.g.,
+// github.com/google/uuid.UUID. UUIDs are encoded as their raw 16 bytes, even
+// though they typically implement encoding.BinaryMarshaler.
+func isUUID(t *types.Named) bool {
+	return t.Obj().Pkg() != nil &&
+		uuidPackages[t.Obj().Pkg().Path()] &&
+		t.Obj().Name() == "UUID" &&
+		isBytes16(t.Underlying())
+}
+
 func isString(t types.Type) bool {
 	b, ok := t.(*types.Basic)
 	return ok && b.Kind() == types.String
@@ -914,6 +1480,16 @@ func isError(t types.Type) bool {
 	return n.Obj().Pkg() == nil && n.Obj().Name() == "error"
 }
 
+// isReadCloser returns whether the provided type is io.ReadCloser.
+func isReadCloser(t types.Type) bool {
+	n, ok := unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := n.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "io" && obj.Name() == "ReadCloser"
+}
+
 // isPrimitiveRouter returns whether the provided type is a valid primitive
 // router type (i.e. an integer, a float, or a string).
 func isPrimitiveRouter(t types.Type) bool {
@@ -935,8 +1511,12 @@ func isPrimitiveRouter(t types.Type) bool {
 // A router type can be one of the following: an integer (signed or unsigned),
 // a float, or a string. Alternatively, it can be a struct that may optioanly
 // embed the weaver.AutoMarshal struct and rest of the fields must be either
-// integers, floats, or strings.
+// integers, floats, or strings. Any type that implements weaver.ShardKey is
+// also a valid router type.
 func isValidRouterType(t types.Type) bool {
+	if implementsShardKey(t) {
+		return true
+	}
 	t = t.Underlying()
 	if isPrimitiveRouter(t) {
 		return true
@@ -953,3 +1533,19 @@ func isValidRouterType(t types.Type) bool {
 	}
 	return true
 }
+
+// implementsShardKey returns whether the provided type implements the
+// weaver.ShardKey interface, i.e., whether it has a "Shard() uint64" method.
+func implementsShardKey(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, "Shard")
+	f, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := f.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	b, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && b.Kind() == types.Uint64
+}
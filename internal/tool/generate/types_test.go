@@ -56,6 +56,9 @@ func TestCheckSerializable(t *testing.T) {
 		{"slice", "type target []byte", ""},
 		{"map", "type target map[string]bool", ""},
 		{"pointer", "type target *int", ""},
+		{"any", "type target any", ""},
+		{"empty interface", "type target interface{}", ""},
+		{"error slice", "type target []error", ""},
 		{"otherpkg", `
 import "time"
 
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ServiceWeaver/weaver/internal/status"
 	itool "github.com/ServiceWeaver/weaver/internal/tool"
@@ -39,6 +40,10 @@ import (
 const (
 	configKey      = "github.com/ServiceWeaver/weaver/multi"
 	shortConfigKey = "multi"
+
+	// configPollInterval is how often the deployer checks the config file
+	// for changes.
+	configPollInterval = 2 * time.Second
 )
 
 var deployCmd = tool.Command{
@@ -151,6 +156,11 @@ persists, please file an issue at https://github.com/ServiceWeaver/weaver/issues
 		return fmt.Errorf("start main process: %w", err)
 	}
 
+	// Reload the config file when it changes.
+	go runtime.WatchConfigFile(ctx, configFile, string(bytes), configPollInterval, func(contents string) {
+		d.reloadConfig(configFile, contents)
+	})
+
 	// Wait for the status server to become active.
 	client := status.NewClient(lis.Addr().String())
 	for r := retry.Begin(); r.Continue(ctx); {
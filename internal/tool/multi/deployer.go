@@ -36,6 +36,7 @@ import (
 	"github.com/ServiceWeaver/weaver/internal/tool/certs"
 	"github.com/ServiceWeaver/weaver/runtime"
 	"github.com/ServiceWeaver/weaver/runtime/bin"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"github.com/ServiceWeaver/weaver/runtime/colors"
 	"github.com/ServiceWeaver/weaver/runtime/envelope"
 	"github.com/ServiceWeaver/weaver/runtime/graph"
@@ -72,10 +73,11 @@ type deployer struct {
 	// statsProcessor tracks and computes stats to be rendered on the /statusz page.
 	statsProcessor *imetrics.StatsProcessor
 
-	mu      sync.Mutex            // guards the following
-	err     error                 // error that stopped the babysitter
-	groups  map[string]*group     // groups, by component name
-	proxies map[string]*proxyInfo // proxies, by listener name
+	mu       sync.Mutex            // guards the following
+	err      error                 // error that stopped the babysitter
+	groups   map[string]*group     // groups, by component name
+	proxies  map[string]*proxyInfo // proxies, by listener name
+	sections map[string]string     // latest config sections, if reloaded
 }
 
 // A group contains information about a co-location group.
@@ -358,6 +360,12 @@ func (d *deployer) startColocationGroup(g *group) error {
 		if err := d.registerReplica(g, e.WeaveletAddress()); err != nil {
 			return err
 		}
+		if d.sections != nil {
+			// The config has been reloaded since the deployer started.
+			if err := e.UpdateConfig(d.sections); err != nil {
+				return err
+			}
+		}
 		if err := e.UpdateComponents(components); err != nil {
 			return err
 		}
@@ -366,6 +374,29 @@ func (d *deployer) startColocationGroup(g *group) error {
 	return nil
 }
 
+// reloadConfig sends the provided new contents of the named config file to
+// every weavelet.
+//
+// REQUIRES: d.mu is NOT held.
+func (d *deployer) reloadConfig(file, contents string) {
+	app, err := runtime.ParseConfig(file, contents, codegen.ComponentConfigValidator)
+	if err != nil {
+		d.logger.Error("Failed to reload config", "file", file, "err", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sections = app.Sections
+	for _, g := range d.groups {
+		for _, e := range g.envelopes {
+			if err := e.UpdateConfig(app.Sections); err != nil {
+				d.logger.Error("Failed to reload config", "group", g.name, "err", err)
+			}
+		}
+	}
+}
+
 func (d *deployer) startMain() error {
 	return d.activateComponent(&protos.ActivateComponentRequest{
 		Component: runtime.Main,
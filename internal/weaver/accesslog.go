@@ -0,0 +1,56 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures access logs.
+	accessLogKey      = "github.com/ServiceWeaver/weaver/accesslog"
+	shortAccessLogKey = "accesslog"
+)
+
+// accessLogConfig is the "[accesslog]" section of a config file. For example,
+// the following config logs 1% of the calls made to remote components:
+//
+//	[accesslog]
+//	sample_rate = 0.01
+type accessLogConfig struct {
+	// SampleRate is the fraction of remote method calls, between 0 and 1, that
+	// are logged by the caller. If zero, no calls are logged.
+	SampleRate float64 `toml:"sample_rate"`
+}
+
+// parseAccessLogConfig parses the access log section of the provided config
+// sections and returns the configured sample rate.
+func parseAccessLogConfig(sections map[string]string) (float64, error) {
+	var config accessLogConfig
+	if err := runtime.ParseConfigSection(accessLogKey, shortAccessLogKey, sections, &config); err != nil {
+		return 0, fmt.Errorf("parse access log config: %w", err)
+	}
+	return config.SampleRate, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *accessLogConfig) Validate() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("sample_rate %v not in [0, 1]", c.SampleRate)
+	}
+	return nil
+}
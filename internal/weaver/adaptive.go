@@ -0,0 +1,96 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures adaptive
+	// concurrency limits.
+	adaptiveConcurrencyKey      = "github.com/ServiceWeaver/weaver/adaptive_concurrency"
+	shortAdaptiveConcurrencyKey = "adaptive_concurrency"
+)
+
+var adaptiveConcurrencyLimit = metrics.NewGaugeMap[adaptiveConcurrencyLabels](
+	imetrics.AdaptiveConcurrencyLimitName,
+	"Adaptive limit on the number of calls in flight from a process to a Service Weaver component",
+)
+
+type adaptiveConcurrencyLabels struct {
+	Component string // full called component name
+}
+
+// adaptiveConcurrencyConfig is the "[adaptive_concurrency]" section of a config
+// file. It maps full component names to the bounds of the adaptive limit on
+// the number of calls that a process has in flight to the component. The limit
+// grows while the component's latency is stable and shrinks when it rises.
+// Calls beyond the limit wait for a call in flight to finish. Every bound is
+// optional. For example:
+//
+//	[adaptive_concurrency]
+//	"github.com/example/catalog/Catalog" = {initial_limit = 20, min_limit = 1, max_limit = 1000}
+type adaptiveConcurrencyConfig map[string]adaptiveConcurrencyOptions
+
+// adaptiveConcurrencyOptions configures the adaptive concurrency limit of
+// calls to a component. Zero values mean the defaults of call.LimiterOptions.
+type adaptiveConcurrencyOptions struct {
+	InitialLimit int `toml:"initial_limit"`
+	MinLimit     int `toml:"min_limit"`
+	MaxLimit     int `toml:"max_limit"`
+}
+
+// parseAdaptiveConcurrencyConfig parses the adaptive concurrency section of
+// the provided config sections and returns the limiter options of every
+// configured component, keyed by full component name.
+func parseAdaptiveConcurrencyConfig(sections map[string]string) (map[string]call.LimiterOptions, error) {
+	var config adaptiveConcurrencyConfig
+	if err := runtime.ParseConfigSection(adaptiveConcurrencyKey, shortAdaptiveConcurrencyKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse adaptive concurrency config: %w", err)
+	}
+	result := map[string]call.LimiterOptions{}
+	for name, opts := range config {
+		gauge := adaptiveConcurrencyLimit.Get(adaptiveConcurrencyLabels{Component: name})
+		result[name] = call.LimiterOptions{
+			InitialLimit: opts.InitialLimit,
+			MinLimit:     opts.MinLimit,
+			MaxLimit:     opts.MaxLimit,
+			OnLimit:      func(limit int) { gauge.Set(float64(limit)) },
+		}
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *adaptiveConcurrencyConfig) Validate() error {
+	for name, opts := range *c {
+		if opts.InitialLimit < 0 || opts.MinLimit < 0 || opts.MaxLimit < 0 {
+			return fmt.Errorf("component %q: negative limit", name)
+		}
+		if opts.MinLimit > 0 && opts.MaxLimit > 0 && opts.MinLimit > opts.MaxLimit {
+			return fmt.Errorf("component %q: min_limit %d is larger than max_limit %d", name, opts.MinLimit, opts.MaxLimit)
+		}
+		if opts.InitialLimit > 0 && opts.MaxLimit > 0 && opts.InitialLimit > opts.MaxLimit {
+			return fmt.Errorf("component %q: initial_limit %d is larger than max_limit %d", name, opts.InitialLimit, opts.MaxLimit)
+		}
+	}
+	return nil
+}
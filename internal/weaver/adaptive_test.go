@@ -0,0 +1,43 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import "testing"
+
+func TestParseAdaptiveConcurrencyConfig(t *testing.T) {
+	const name = "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortAdaptiveConcurrencyKey: `"` + name + `" = {initial_limit = 10, min_limit = 2, max_limit = 50}`}
+	got, err := parseAdaptiveConcurrencyConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, ok := got[name]
+	if !ok {
+		t.Fatalf("no limiter options for %q", name)
+	}
+	if opts.InitialLimit != 10 || opts.MinLimit != 2 || opts.MaxLimit != 50 {
+		t.Fatalf("got limits (%d, %d, %d), want (10, 2, 50)", opts.InitialLimit, opts.MinLimit, opts.MaxLimit)
+	}
+
+	for _, bad := range []string{
+		`"` + name + `" = {initial_limit = -1}`,
+		`"` + name + `" = {min_limit = 10, max_limit = 5}`,
+		`"` + name + `" = {initial_limit = 10, max_limit = 5}`,
+	} {
+		if _, err := parseAdaptiveConcurrencyConfig(map[string]string{shortAdaptiveConcurrencyKey: bad}); err == nil {
+			t.Errorf("%s: unexpected success", bad)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures admission.
+	admissionKey      = "github.com/ServiceWeaver/weaver/admission"
+	shortAdmissionKey = "admission"
+)
+
+// admissionConfig is the "[admission]" section of a config file. If present, a
+// weavelet runs at most max_concurrent_calls calls from other processes at
+// once, across all of its components. The other calls wait for a running call
+// to return and are then run in priority order (see metadata.WithPriority),
+// with calls of the same priority run in the order they arrived. For example:
+//
+//	[admission]
+//	max_concurrent_calls = 256
+type admissionConfig struct {
+	// MaxConcurrentCalls is the maximum number of calls from other processes
+	// that run at once.
+	MaxConcurrentCalls int `toml:"max_concurrent_calls"`
+}
+
+// parseAdmissionConfig parses the admission section of the provided config
+// sections. It returns the maximum number of calls from other processes that
+// run at once, or zero if admission is not configured.
+func parseAdmissionConfig(sections map[string]string) (int, error) {
+	var config admissionConfig
+	if err := runtime.ParseConfigSection(admissionKey, shortAdmissionKey, sections, &config); err != nil {
+		return 0, fmt.Errorf("parse admission config: %w", err)
+	}
+	return config.MaxConcurrentCalls, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *admissionConfig) Validate() error {
+	if c.MaxConcurrentCalls < 0 {
+		return fmt.Errorf("negative max_concurrent_calls %d", c.MaxConcurrentCalls)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures backpressure.
+	backpressureKey      = "github.com/ServiceWeaver/weaver/backpressure"
+	shortBackpressureKey = "backpressure"
+)
+
+// backpressureConfig is the "[backpressure]" section of a config file. If
+// present, every connection on which a weavelet serves remote calls bounds the
+// number of bytes of replies that it has produced but not yet written to the
+// caller. When a caller reads its replies slowly, the connection stops running
+// calls, and hence stops producing replies, until the caller catches up. This
+// bounds the memory a slow caller can pin. For example:
+//
+//	[backpressure]
+//	max_pending_reply_bytes = 16777216
+type backpressureConfig struct {
+	// MaxPendingReplyBytes is the maximum number of bytes of unwritten
+	// replies per connection. A single reply larger than the budget is still
+	// sent.
+	MaxPendingReplyBytes int `toml:"max_pending_reply_bytes"`
+}
+
+// parseBackpressureConfig parses the backpressure section of the provided
+// config sections. It returns the maximum number of bytes of pending replies
+// per connection, or zero if backpressure is not configured.
+func parseBackpressureConfig(sections map[string]string) (int, error) {
+	var config backpressureConfig
+	if err := runtime.ParseConfigSection(backpressureKey, shortBackpressureKey, sections, &config); err != nil {
+		return 0, fmt.Errorf("parse backpressure config: %w", err)
+	}
+	return config.MaxPendingReplyBytes, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *backpressureConfig) Validate() error {
+	if c.MaxPendingReplyBytes < 0 {
+		return fmt.Errorf("negative max_pending_reply_bytes %d", c.MaxPendingReplyBytes)
+	}
+	return nil
+}
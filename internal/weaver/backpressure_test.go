@@ -0,0 +1,44 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import "testing"
+
+func TestParseBackpressureConfig(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		sections map[string]string
+		want     int
+	}{
+		{"disabled", map[string]string{}, 0},
+		{"empty", map[string]string{shortBackpressureKey: ""}, 0},
+		{"budget", map[string]string{backpressureKey: "max_pending_reply_bytes = 1024"}, 1024},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseBackpressureConfig(test.sections)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+
+	sections := map[string]string{shortBackpressureKey: "max_pending_reply_bytes = -1"}
+	if _, err := parseBackpressureConfig(sections); err == nil {
+		t.Error("negative budget: unexpected success")
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures bulkheads.
+	bulkheadsKey      = "github.com/ServiceWeaver/weaver/bulkheads"
+	shortBulkheadsKey = "bulkheads"
+)
+
+// BulkheadFullError is the error returned by a call from a component to
+// another when the caller already has as many calls in flight to the callee
+// as its bulkhead allows. The error is marked retryable.
+var BulkheadFullError = errors.New("Service Weaver component bulkhead is full")
+
+var bulkheadInUse = metrics.NewGaugeMap[bulkheadLabels](
+	imetrics.BulkheadInUseName,
+	"Number of calls from a Service Weaver component to another that hold a slot of the caller's bulkhead",
+)
+
+type bulkheadLabels struct {
+	Caller    string // full calling component name
+	Component string // full called component name
+}
+
+// bulkheadsConfig is the "[bulkheads]" section of a config file. It maps full
+// caller component names to the components they call, along with the maximum
+// number of calls the caller can have in flight to each of them. For example,
+// the following config allows Frontend to have at most 50 calls in flight to
+// Catalog:
+//
+//	[bulkheads]
+//	"github.com/example/frontend/Frontend" = {"github.com/example/catalog/Catalog" = 50}
+type bulkheadsConfig map[string]map[string]int
+
+// parseBulkheadsConfig parses the bulkheads section of the provided config
+// sections and returns a bulkhead for every configured pair of caller and
+// callee, keyed by full caller component name and then by full component
+// name.
+func parseBulkheadsConfig(sections map[string]string) (map[string]map[string]*bulkhead, error) {
+	var config bulkheadsConfig
+	if err := runtime.ParseConfigSection(bulkheadsKey, shortBulkheadsKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse bulkheads config: %w", err)
+	}
+	result := map[string]map[string]*bulkhead{}
+	for caller, components := range config {
+		result[caller] = map[string]*bulkhead{}
+		for name, max := range components {
+			result[caller][name] = newBulkhead(caller, name, max)
+		}
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *bulkheadsConfig) Validate() error {
+	for caller, components := range *c {
+		for name, max := range components {
+			if max <= 0 {
+				return fmt.Errorf("caller %q: component %q: non-positive limit %d", caller, name, max)
+			}
+		}
+	}
+	return nil
+}
+
+// A bulkhead limits the number of calls that a component has in flight to
+// another component, so that a slow callee can't tie up all of the caller's
+// goroutines. Calls beyond the limit are rejected rather than queued.
+//
+// A bulkhead is safe for concurrent use.
+type bulkhead struct {
+	caller    string        // full calling component name
+	component string        // full called component name
+	slots     chan struct{} // holds a value for every call in flight
+	inUse     *metrics.Gauge
+}
+
+// newBulkhead returns a bulkhead that allows the provided caller at most max
+// calls in flight to the provided component.
+func newBulkhead(caller, component string, max int) *bulkhead {
+	return &bulkhead{
+		caller:    caller,
+		component: component,
+		slots:     make(chan struct{}, max),
+		inUse:     bulkheadInUse.Get(bulkheadLabels{Caller: caller, Component: component}),
+	}
+}
+
+// acquire acquires a slot of the bulkhead, returning an error that wraps
+// BulkheadFullError if there is none left. The slot must be released by
+// calling release.
+func (b *bulkhead) acquire() error {
+	select {
+	case b.slots <- struct{}{}:
+		b.inUse.Add(1)
+		return nil
+	default:
+		err := fmt.Errorf("component %q has %d calls in flight to component %q: %w", b.caller, cap(b.slots), b.component, BulkheadFullError)
+		return codegen.Retryable(err)
+	}
+}
+
+// release releases a slot acquired by acquire.
+func (b *bulkhead) release() {
+	b.inUse.Sub(1)
+	<-b.slots
+}
+
+// bulkheadStub is a Stub that acquires a slot of a bulkhead for the duration
+// of every call made through it. It forwards the optional AccessLogger,
+// Hedger, and Queuer methods to the stub it wraps.
+type bulkheadStub struct {
+	codegen.Stub
+	bulkhead *bulkhead
+}
+
+var (
+	_ codegen.AccessLogger = &bulkheadStub{}
+	_ codegen.Hedger       = &bulkheadStub{}
+	_ codegen.Queuer       = &bulkheadStub{}
+)
+
+// Run implements the codegen.Stub interface.
+func (s *bulkheadStub) Run(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, error) {
+	results, _, err := s.RunQueued(ctx, method, args, shardKey)
+	return results, err
+}
+
+// RunQueued implements the codegen.Queuer interface.
+func (s *bulkheadStub) RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, time.Duration, error) {
+	if err := s.bulkhead.acquire(); err != nil {
+		return nil, 0, err
+	}
+	defer s.bulkhead.release()
+	if q, ok := s.Stub.(codegen.Queuer); ok {
+		return q.RunQueued(ctx, method, args, shardKey)
+	}
+	results, err := s.Stub.Run(ctx, method, args, shardKey)
+	return results, 0, err
+}
+
+// HedgeDelay implements the codegen.Hedger interface.
+func (s *bulkheadStub) HedgeDelay(method int) time.Duration {
+	if h, ok := s.Stub.(codegen.Hedger); ok {
+		return h.HedgeDelay(method)
+	}
+	return 0
+}
+
+// LogAccess implements the codegen.AccessLogger interface.
+func (s *bulkheadStub) LogAccess(ctx context.Context, method int, duration time.Duration, err error) {
+	if l, ok := s.Stub.(codegen.AccessLogger); ok {
+		l.LogAccess(ctx, method, duration, err)
+	}
+}
+
+// localBulkheadStub returns a stub of the component registered as reg that
+// acquires a slot of the provided bulkhead for the duration of every call made
+// through it, and forwards the call to local, a local stub of the component.
+// It is the counterpart of bulkheadStub for calls to colocated components.
+func localBulkheadStub(reg *codegen.Registration, local any, b *bulkhead) any {
+	v := reflect.ValueOf(local)
+	return reg.ReflectStubFn(func(method string, ctx context.Context, args []any, returns []any) error {
+		if err := b.acquire(); err != nil {
+			return err
+		}
+		defer b.release()
+		return reflection.CallMethod(v.MethodByName(method), ctx, args, returns)
+	})
+}
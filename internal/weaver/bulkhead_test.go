@@ -0,0 +1,161 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bulkheadInUseValue returns the value of the in use metric of the bulkhead
+// from the provided caller to the provided component.
+func bulkheadInUseValue(caller, component string) float64 {
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == imetrics.BulkheadInUseName && snap.Labels["caller"] == caller && snap.Labels["component"] == component {
+			return snap.Value
+		}
+	}
+	return 0
+}
+
+// blockingStub is a Stub whose calls block until unblock is closed.
+type blockingStub struct {
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (s *blockingStub) Tracer() trace.Tracer { return nil }
+
+func (s *blockingStub) Run(context.Context, int, []byte, uint64) ([]byte, error) {
+	s.started <- struct{}{}
+	<-s.unblock
+	return nil, nil
+}
+
+func TestBulkheadStub(t *testing.T) {
+	const caller, component = "TestBulkheadStub/Frontend", "TestBulkheadStub/Catalog"
+	inner := &blockingStub{started: make(chan struct{}, 2), unblock: make(chan struct{})}
+	b := newBulkhead(caller, component, 2)
+	stub := &bulkheadStub{Stub: inner, bulkhead: b}
+
+	// Fill the bulkhead.
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := stub.Run(context.Background(), 0, nil, 0)
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		<-inner.started
+	}
+	if got, want := bulkheadInUseValue(caller, component), 2.0; got != want {
+		t.Errorf("in use: got %v, want %v", got, want)
+	}
+
+	// Calls beyond the limit are rejected without being sent.
+	_, err := stub.Run(context.Background(), 0, nil, 0)
+	if !errors.Is(err, BulkheadFullError) {
+		t.Fatalf("call to a full bulkhead: got %v, want %v", err, BulkheadFullError)
+	}
+	if !codegen.IsRetryable(err) {
+		t.Errorf("call to a full bulkhead: got non-retryable error %v", err)
+	}
+
+	// Once the calls return, their slots are released.
+	close(inner.unblock)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := bulkheadInUseValue(caller, component), 0.0; got != want {
+		t.Errorf("in use: got %v, want %v", got, want)
+	}
+	go func() { <-inner.started }()
+	if _, err := stub.Run(context.Background(), 0, nil, 0); err != nil {
+		t.Fatalf("call to an empty bulkhead: %v", err)
+	}
+}
+
+func TestLocalBulkheadStub(t *testing.T) {
+	const caller, component = "TestLocalBulkheadStub/Frontend", "TestLocalBulkheadStub/Currency"
+	started, unblock := make(chan struct{}, 1), make(chan struct{})
+	local := localCurrency{convert: func(context.Context) error {
+		started <- struct{}{}
+		<-unblock
+		return nil
+	}}
+	b := newBulkhead(caller, component, 1)
+	stub := localBulkheadStub(currencyRegistration(component), local, b).(currency)
+
+	// Fill the bulkhead.
+	errs := make(chan error, 1)
+	go func() {
+		_, err := stub.Convert(context.Background(), "USD", "EUR", 1)
+		errs <- err
+	}()
+	<-started
+	if got, want := bulkheadInUseValue(caller, component), 1.0; got != want {
+		t.Errorf("in use: got %v, want %v", got, want)
+	}
+
+	// Local calls beyond the limit are rejected without reaching the
+	// component.
+	_, err := stub.Convert(context.Background(), "USD", "EUR", 1)
+	if !errors.Is(err, BulkheadFullError) {
+		t.Fatalf("call to a full bulkhead: got %v, want %v", err, BulkheadFullError)
+	}
+
+	// Once the call returns, its slot is released.
+	close(unblock)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if got, want := bulkheadInUseValue(caller, component), 0.0; got != want {
+		t.Errorf("in use: got %v, want %v", got, want)
+	}
+	go func() { <-started }()
+	if _, err := stub.Convert(context.Background(), "USD", "EUR", 1); err != nil {
+		t.Fatalf("call to an empty bulkhead: %v", err)
+	}
+}
+
+func TestParseBulkheadsConfig(t *testing.T) {
+	const caller, name = "github.com/example/frontend/Frontend", "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortBulkheadsKey: `"` + caller + `" = {"` + name + `" = 50}`}
+	got, err := parseBulkheadsConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ok := got[caller][name]
+	if !ok {
+		t.Fatalf("no bulkhead from %s to %s", caller, name)
+	}
+	if got, want := cap(b.slots), 50; got != want {
+		t.Fatalf("limit: got %d, want %d", got, want)
+	}
+
+	sections = map[string]string{shortBulkheadsKey: `"` + caller + `" = {"` + name + `" = 0}`}
+	if _, err := parseBulkheadsConfig(sections); err == nil {
+		t.Fatal("non-positive limit: unexpected success")
+	}
+}
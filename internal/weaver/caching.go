@@ -0,0 +1,91 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures client side
+	// caching.
+	cachingKey      = "github.com/ServiceWeaver/weaver/caching"
+	shortCachingKey = "caching"
+)
+
+// cachingConfig is the "[caching]" section of a config file. It maps full
+// component names to the methods whose replies are cached by client stubs,
+// along with how long a reply is cached. For example, the following config
+// caches the replies of Currency.GetSupportedCurrencies for 5 minutes:
+//
+//	[caching]
+//	"github.com/example/currency/Currency" = {GetSupportedCurrencies = "5m"}
+type cachingConfig map[string]map[string]string
+
+// parseCachingConfig parses the caching section of the provided config
+// sections and returns the cache TTL of every configured method, keyed by
+// full component name and then by method name.
+func parseCachingConfig(sections map[string]string) (map[string]map[string]time.Duration, error) {
+	var config cachingConfig
+	if err := runtime.ParseConfigSection(cachingKey, shortCachingKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse caching config: %w", err)
+	}
+	result := map[string]map[string]time.Duration{}
+	for name, methods := range config {
+		result[name] = map[string]time.Duration{}
+		for method, ttl := range methods {
+			// Validate has already checked that the TTL parses.
+			result[name][method], _ = time.ParseDuration(ttl)
+		}
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *cachingConfig) Validate() error {
+	for name, methods := range *c {
+		for method, ttl := range methods {
+			d, err := time.ParseDuration(ttl)
+			if err != nil {
+				return fmt.Errorf("component %q: method %s: invalid TTL %q: %w", name, method, ttl, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("component %q: method %s: non-positive TTL %v", name, method, d)
+			}
+		}
+	}
+	return nil
+}
+
+// checkCaching checks that every method of reg in the provided cache TTLs
+// exists and is marked //weaver:cacheable. Only the replies of methods that
+// are known to be idempotent can be cached.
+func checkCaching(reg *codegen.Registration, ttls map[string]time.Duration) error {
+	for method := range ttls {
+		m, ok := reg.Iface.MethodByName(method)
+		if !ok {
+			return fmt.Errorf("caching: component %q has no method %s", reg.Name, method)
+		}
+		if !slices.Contains(reg.Cacheable, m.Index) {
+			return fmt.Errorf("caching: method %s of component %q is not marked //weaver:cacheable", method, reg.Name)
+		}
+	}
+	return nil
+}
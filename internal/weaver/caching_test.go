@@ -0,0 +1,108 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCachingConfig(t *testing.T) {
+	const name = "github.com/example/currency/Currency"
+	sections := map[string]string{shortCachingKey: `"` + name + `" = {GetSupportedCurrencies = "5m"}`}
+	got, err := parseCachingConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]time.Duration{name: {"GetSupportedCurrencies": 5 * time.Minute}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad TTLs (-want +got):\n%s", diff)
+	}
+
+	for _, ttl := range []string{`"0s"`, `"soon"`} {
+		sections = map[string]string{shortCachingKey: `"` + name + `" = {GetSupportedCurrencies = ` + ttl + `}`}
+		if _, err := parseCachingConfig(sections); err == nil {
+			t.Errorf("unexpected success for TTL %s", ttl)
+		}
+	}
+}
+
+type currency interface {
+	Convert(context.Context, string, string, float64) (float64, error)
+	GetSupportedCurrencies(context.Context) ([]string, error)
+}
+
+// currencyReflectStub is the reflect stub of currency, as generated by
+// "weaver generate".
+type currencyReflectStub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+func (s currencyReflectStub) Convert(ctx context.Context, a0 string, a1 string, a2 float64) (r0 float64, err error) {
+	err = s.caller("Convert", ctx, []any{a0, a1, a2}, []any{&r0})
+	return
+}
+
+func (s currencyReflectStub) GetSupportedCurrencies(ctx context.Context) (r0 []string, err error) {
+	err = s.caller("GetSupportedCurrencies", ctx, []any{}, []any{&r0})
+	return
+}
+
+// currencyRegistration returns a registration of currency with the provided
+// name.
+func currencyRegistration(name string) *codegen.Registration {
+	return &codegen.Registration{
+		Name:  name,
+		Iface: reflect.TypeOf((*currency)(nil)).Elem(),
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return currencyReflectStub{caller: caller}
+		},
+	}
+}
+
+// localCurrency is a local implementation of currency whose Convert method
+// calls convert with its context.
+type localCurrency struct {
+	convert func(context.Context) error
+}
+
+func (c localCurrency) Convert(ctx context.Context, _, _ string, amount float64) (float64, error) {
+	return amount, c.convert(ctx)
+}
+
+func (c localCurrency) GetSupportedCurrencies(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestCheckCaching(t *testing.T) {
+	reg := &codegen.Registration{
+		Name:      "github.com/example/currency/Currency",
+		Iface:     reflect.TypeOf((*currency)(nil)).Elem(),
+		Cacheable: []int{1}, // GetSupportedCurrencies
+	}
+	if err := checkCaching(reg, map[string]time.Duration{"GetSupportedCurrencies": time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"Convert", "Missing"} {
+		if err := checkCaching(reg, map[string]time.Duration{method: time.Minute}); err == nil {
+			t.Errorf("%s: unexpected success", method)
+		}
+	}
+}
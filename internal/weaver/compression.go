@@ -0,0 +1,82 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures compression.
+	compressionKey      = "github.com/ServiceWeaver/weaver/compression"
+	shortCompressionKey = "compression"
+)
+
+// compressionConfig is the "[compression]" section of a config file. It maps
+// full component names to the compression used for calls made to them. For
+// example, the following config compresses requests and replies to the
+// Catalog component that are at least 64 KiB:
+//
+//	[compression]
+//	"github.com/example/catalog/Catalog" = {codec = "zstd", threshold = 65536}
+type compressionConfig map[string]compressionOptions
+
+// compressionOptions configures the compression of calls to a component.
+type compressionOptions struct {
+	// Codec is the name of the compression codec: "none", "gzip", or "zstd".
+	Codec string
+
+	// Threshold is the minimum size, in bytes, of a payload to compress. If
+	// zero, all payloads are compressed.
+	Threshold int
+}
+
+// compression is the parsed form of compressionOptions.
+type compression struct {
+	codec     call.Compression
+	threshold int
+}
+
+// parseCompressionConfig parses the compression section of the provided
+// config sections and returns the compression to use for calls to every
+// configured component, keyed by full component name.
+func parseCompressionConfig(sections map[string]string) (map[string]compression, error) {
+	var config compressionConfig
+	if err := runtime.ParseConfigSection(compressionKey, shortCompressionKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse compression config: %w", err)
+	}
+	result := map[string]compression{}
+	for name, opts := range config {
+		codec, err := call.ParseCompression(opts.Codec)
+		if err != nil {
+			return nil, fmt.Errorf("parse compression config: component %q: %w", name, err)
+		}
+		result[name] = compression{codec: codec, threshold: opts.Threshold}
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *compressionConfig) Validate() error {
+	for name, opts := range *c {
+		if opts.Threshold < 0 {
+			return fmt.Errorf("component %q: negative threshold %d", name, opts.Threshold)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures call
+	// deadlines.
+	callDeadlinesKey      = "github.com/ServiceWeaver/weaver/call_deadlines"
+	shortCallDeadlinesKey = "call_deadlines"
+)
+
+// callDeadlinesConfig is the "[call_deadlines]" section of a config file. It
+// maps full caller component names to the deadline of the calls they make to
+// other components. A caller has a default deadline, which applies to all of
+// its calls, and can override it for individual methods, keyed by full
+// component name and then by method name. For example, the following config
+// bounds the calls made by Frontend to 2 seconds, except for the calls to
+// Catalog.GetProduct, which are bounded to 500 milliseconds:
+//
+//	[call_deadlines."github.com/example/frontend/Frontend"]
+//	default = "2s"
+//	methods = {"github.com/example/catalog/Catalog" = {GetProduct = "500ms"}}
+//
+// A deadline only shortens the deadline of a call, never extends it.
+type callDeadlinesConfig map[string]callDeadlinesOptions
+
+// callDeadlinesOptions configures the deadlines of the calls made by a
+// component.
+type callDeadlinesOptions struct {
+	Default string                       `toml:"default"` // default deadline, if any
+	Methods map[string]map[string]string `toml:"methods"` // deadlines, by component and method
+}
+
+// callDeadlines holds the parsed deadlines of the calls made by a component.
+type callDeadlines struct {
+	def     time.Duration                       // default deadline, or 0 if none
+	methods map[string]map[string]time.Duration // deadlines, by component and method
+}
+
+// parseCallDeadlinesConfig parses the call deadlines section of the provided
+// config sections and returns the call deadlines of every configured caller,
+// keyed by full caller component name.
+func parseCallDeadlinesConfig(sections map[string]string) (map[string]callDeadlines, error) {
+	var config callDeadlinesConfig
+	if err := runtime.ParseConfigSection(callDeadlinesKey, shortCallDeadlinesKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse call deadlines config: %w", err)
+	}
+	result := map[string]callDeadlines{}
+	for caller, opts := range config {
+		// Validate has already checked that the deadlines parse.
+		d := callDeadlines{methods: map[string]map[string]time.Duration{}}
+		if opts.Default != "" {
+			d.def, _ = time.ParseDuration(opts.Default)
+		}
+		for name, methods := range opts.Methods {
+			d.methods[name] = map[string]time.Duration{}
+			for method, deadline := range methods {
+				d.methods[name][method], _ = time.ParseDuration(deadline)
+			}
+		}
+		result[caller] = d
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *callDeadlinesConfig) Validate() error {
+	check := func(deadline string) error {
+		d, err := time.ParseDuration(deadline)
+		if err != nil {
+			return fmt.Errorf("invalid deadline %q: %w", deadline, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("non-positive deadline %v", d)
+		}
+		return nil
+	}
+	for caller, opts := range *c {
+		if opts.Default != "" {
+			if err := check(opts.Default); err != nil {
+				return fmt.Errorf("caller %q: %w", caller, err)
+			}
+		}
+		for name, methods := range opts.Methods {
+			for method, deadline := range methods {
+				if err := check(deadline); err != nil {
+					return fmt.Errorf("caller %q: component %q: method %s: %w", caller, name, method, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// forComponent returns the deadline of every method of the component
+// registered as reg, indexed by method index, or nil if no call to the
+// component is bounded. It fails if a configured method doesn't exist.
+func (d callDeadlines) forComponent(reg *codegen.Registration) ([]time.Duration, error) {
+	methods := d.methods[reg.Name]
+	if d.def == 0 && len(methods) == 0 {
+		return nil, nil
+	}
+	deadlines := make([]time.Duration, reg.Iface.NumMethod())
+	for i := range deadlines {
+		deadlines[i] = d.def
+	}
+	for method, deadline := range methods {
+		m, ok := reg.Iface.MethodByName(method)
+		if !ok {
+			return nil, fmt.Errorf("call deadlines: component %q has no method %s", reg.Name, method)
+		}
+		deadlines[m.Index] = deadline
+	}
+	return deadlines, nil
+}
+
+// deadlineStub is a Stub that bounds the calls made through it with the
+// deadlines of their methods. It forwards the optional AccessLogger, Hedger,
+// and Queuer methods to the stub it wraps.
+type deadlineStub struct {
+	codegen.Stub
+	deadlines []time.Duration // deadlines, by method index
+}
+
+var (
+	_ codegen.AccessLogger = &deadlineStub{}
+	_ codegen.Deadliner    = &deadlineStub{}
+	_ codegen.Hedger       = &deadlineStub{}
+	_ codegen.Queuer       = &deadlineStub{}
+)
+
+// CallDeadline implements the codegen.Deadliner interface.
+func (s *deadlineStub) CallDeadline(method int) time.Duration {
+	return s.deadlines[method]
+}
+
+// RunQueued implements the codegen.Queuer interface.
+func (s *deadlineStub) RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, time.Duration, error) {
+	if q, ok := s.Stub.(codegen.Queuer); ok {
+		return q.RunQueued(ctx, method, args, shardKey)
+	}
+	results, err := s.Stub.Run(ctx, method, args, shardKey)
+	return results, 0, err
+}
+
+// HedgeDelay implements the codegen.Hedger interface.
+func (s *deadlineStub) HedgeDelay(method int) time.Duration {
+	if h, ok := s.Stub.(codegen.Hedger); ok {
+		return h.HedgeDelay(method)
+	}
+	return 0
+}
+
+// LogAccess implements the codegen.AccessLogger interface.
+func (s *deadlineStub) LogAccess(ctx context.Context, method int, duration time.Duration, err error) {
+	if l, ok := s.Stub.(codegen.AccessLogger); ok {
+		l.LogAccess(ctx, method, duration, err)
+	}
+}
+
+// boundCalls returns stub, a stub to the component registered as reg, wrapped
+// so that the calls made through it by the provided caller are bounded by the
+// caller's call deadlines, if any.
+func boundCalls(deadlines map[string]callDeadlines, caller string, reg *codegen.Registration, stub codegen.Stub) (codegen.Stub, error) {
+	d, ok := deadlines[caller]
+	if !ok {
+		return stub, nil
+	}
+	bounds, err := d.forComponent(reg)
+	if err != nil || bounds == nil {
+		return stub, err
+	}
+	return &deadlineStub{Stub: stub, deadlines: bounds}, nil
+}
+
+// boundLocalCalls is like boundCalls, but wraps local, a local stub of the
+// component registered as reg. A colocated call can't be abandoned once the
+// method runs, so the deadline of a local call is set on the context passed to
+// the method, which is expected to honor it.
+func boundLocalCalls(deadlines map[string]callDeadlines, caller string, reg *codegen.Registration, local any) (any, error) {
+	d, ok := deadlines[caller]
+	if !ok {
+		return local, nil
+	}
+	bounds, err := d.forComponent(reg)
+	if err != nil || bounds == nil {
+		return local, err
+	}
+	v := reflect.ValueOf(local)
+	return reg.ReflectStubFn(func(method string, ctx context.Context, args []any, returns []any) error {
+		m, _ := reg.Iface.MethodByName(method)
+		if timeout := bounds[m.Index]; timeout > 0 {
+			// Note that an earlier deadline of ctx is preserved.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return reflection.CallMethod(v.MethodByName(method), ctx, args, returns)
+	}), nil
+}
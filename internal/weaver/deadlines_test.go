@@ -0,0 +1,138 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCallDeadlines(t *testing.T) {
+	const (
+		frontend     = "github.com/example/frontend/Frontend"
+		currencyName = "github.com/example/currency/Currency"
+	)
+	sections := map[string]string{shortCallDeadlinesKey: `
+["` + frontend + `"]
+default = "2s"
+methods = {"` + currencyName + `" = {Convert = "500ms"}}
+`}
+	deadlines, err := parseCallDeadlinesConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := &codegen.Registration{
+		Name:  currencyName,
+		Iface: reflect.TypeOf((*currency)(nil)).Elem(),
+	}
+	stub, err := boundCalls(deadlines, frontend, reg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := stub.(codegen.Deadliner)
+	if !ok {
+		t.Fatalf("calls from %s not bounded", frontend)
+	}
+	got := []time.Duration{d.CallDeadline(0), d.CallDeadline(1)}
+	want := []time.Duration{500 * time.Millisecond, 2 * time.Second} // Convert, GetSupportedCurrencies
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad deadlines (-want +got):\n%s", diff)
+	}
+
+	// Calls from other components are not bounded.
+	if stub, err := boundCalls(deadlines, "github.com/example/Other", reg, nil); err != nil || stub != nil {
+		t.Fatalf("calls from another caller: got (%v, %v), want (nil, nil)", stub, err)
+	}
+
+	// Deadlines of unknown methods are rejected.
+	sections = map[string]string{shortCallDeadlinesKey: `"` + frontend + `" = {methods = {"` + currencyName + `" = {Missing = "1s"}}}`}
+	if deadlines, err = parseCallDeadlinesConfig(sections); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := boundCalls(deadlines, frontend, reg, nil); err == nil {
+		t.Fatal("unknown method: unexpected success")
+	}
+}
+
+func TestBoundLocalCalls(t *testing.T) {
+	const (
+		frontend     = "github.com/example/frontend/Frontend"
+		currencyName = "github.com/example/currency/Currency"
+	)
+	sections := map[string]string{shortCallDeadlinesKey: `"` + frontend + `" = {methods = {"` + currencyName + `" = {Convert = "1h"}}}`}
+	deadlines, err := parseCallDeadlinesConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var remaining time.Duration
+	local := localCurrency{convert: func(ctx context.Context) error {
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining = time.Until(deadline)
+		}
+		return nil
+	}}
+	reg := currencyRegistration(currencyName)
+	for _, test := range []struct {
+		caller  string
+		timeout time.Duration // timeout of the caller's context, if any
+		min     time.Duration // minimum time remaining, if any deadline
+		max     time.Duration // maximum time remaining, if any deadline
+	}{
+		// Local calls are bounded like remote calls.
+		{frontend, 0, 59 * time.Minute, time.Hour},
+		// An earlier deadline is preserved.
+		{frontend, time.Minute, 0, time.Minute},
+		// Calls from other components are not bounded.
+		{"github.com/example/Other", 0, 0, 0},
+	} {
+		stub, err := boundLocalCalls(deadlines, test.caller, reg, local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		remaining = 0
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if test.timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, test.timeout)
+		}
+		if _, err := stub.(currency).Convert(ctx, "USD", "EUR", 1); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		if remaining < test.min || remaining > test.max {
+			t.Errorf("caller %s: time remaining: got %v, want in [%v, %v]", test.caller, remaining, test.min, test.max)
+		}
+	}
+}
+
+func TestParseCallDeadlinesConfigErrors(t *testing.T) {
+	const name = "github.com/example/frontend/Frontend"
+	for _, config := range []string{
+		`{default = "0s"}`,
+		`{default = "soon"}`,
+		`{methods = {"github.com/example/currency/Currency" = {Convert = "-1s"}}}`,
+	} {
+		sections := map[string]string{shortCallDeadlinesKey: `"` + name + `" = ` + config}
+		if _, err := parseCallDeadlinesConfig(sections); err == nil {
+			t.Errorf("%s: unexpected success", config)
+		}
+	}
+}
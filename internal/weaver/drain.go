@@ -0,0 +1,137 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures draining.
+	drainingKey      = "github.com/ServiceWeaver/weaver/draining"
+	shortDrainingKey = "draining"
+
+	// defaultDrainTimeout is how long a weavelet that is shutting down waits
+	// for in-flight calls to finish, if the draining timeout is not set.
+	defaultDrainTimeout = 10 * time.Second
+)
+
+// UnavailableError is the error returned by a call to a component whose
+// weavelet is shutting down. The error is marked retryable, so the caller
+// retries the call, typically on another replica.
+var UnavailableError = errors.New("Service Weaver component is unavailable")
+
+// drainingConfig is the "[draining]" section of a config file. It configures
+// how long a weavelet that is shutting down waits for the calls in flight to
+// finish, and for its background workers to stop. For example:
+//
+//	[draining]
+//	timeout = "30s"
+type drainingConfig struct {
+	// Timeout is how long to wait for the calls in flight to finish, e.g.,
+	// "30s". Defaults to 10 seconds.
+	Timeout string
+}
+
+// parseDrainingConfig parses the draining section of the provided config
+// sections. It returns the draining timeout, or defaultDrainTimeout if the
+// timeout is not set.
+func parseDrainingConfig(sections map[string]string) (time.Duration, error) {
+	var config drainingConfig
+	if err := runtime.ParseConfigSection(drainingKey, shortDrainingKey, sections, &config); err != nil {
+		return 0, fmt.Errorf("parse draining config: %w", err)
+	}
+	if config.Timeout == "" {
+		return defaultDrainTimeout, nil
+	}
+	// Validate has already checked that the timeout parses.
+	timeout, _ := time.ParseDuration(config.Timeout)
+	return timeout, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *drainingConfig) Validate() error {
+	if c.Timeout == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: %w", c.Timeout, err)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("non-positive timeout %v", timeout)
+	}
+	return nil
+}
+
+// A drainer tracks the calls in flight on a weavelet and, once draining
+// begins, rejects new calls and waits for the in-flight ones to finish.
+type drainer struct {
+	mu       sync.Mutex
+	draining bool          // stop accepting new calls?
+	inflight int           // number of calls in flight
+	idle     chan struct{} // closed when draining and inflight reaches 0
+}
+
+// begin records the start of a call. It returns false, and the call should be
+// rejected, if draining has begun. Every call to begin that returns true must
+// be followed by a call to end.
+func (d *drainer) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight++
+	return true
+}
+
+// end records the end of a call.
+func (d *drainer) end() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inflight--
+	if d.draining && d.inflight == 0 {
+		close(d.idle)
+	}
+}
+
+// drain stops accepting new calls and blocks until every call in flight has
+// finished or until ctx is done.
+func (d *drainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.draining {
+		d.draining = true
+		d.idle = make(chan struct{})
+		if d.inflight == 0 {
+			close(d.idle)
+		}
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrainWaitsForInflightCalls(t *testing.T) {
+	var d drainer
+	if !d.begin() {
+		t.Fatal("begin: call rejected before draining")
+	}
+
+	drained := make(chan error)
+	go func() { drained <- d.drain(context.Background()) }()
+
+	// Wait for draining to begin, after which new calls are rejected.
+	for d.begin() {
+		d.end()
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-drained:
+		t.Fatalf("drain returned %v with a call in flight", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	d.end()
+	if err := <-drained; err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	var d drainer
+	if !d.begin() {
+		t.Fatal("begin: call rejected before draining")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("drain: got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d.begin() {
+		t.Fatal("begin: call accepted while draining")
+	}
+	d.end()
+}
+
+func TestDrainIdle(t *testing.T) {
+	var d drainer
+	if err := d.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if err := d.drain(context.Background()); err != nil {
+		t.Fatalf("second drain: %v", err)
+	}
+}
+
+func TestParseDrainingConfig(t *testing.T) {
+	got, err := parseDrainingConfig(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != defaultDrainTimeout {
+		t.Fatalf("default: got %v, want %v", got, defaultDrainTimeout)
+	}
+
+	sections := map[string]string{shortDrainingKey: `timeout = "30s"`}
+	got, err = parseDrainingConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 30 * time.Second; got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for _, section := range []string{`timeout = "soon"`, `timeout = "0s"`, `timeout = "-1s"`} {
+		sections := map[string]string{shortDrainingKey: section}
+		if _, err := parseDrainingConfig(sections); err == nil {
+			t.Errorf("%s: unexpected success", section)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures fair
+	// scheduling.
+	fairSchedulingKey      = "github.com/ServiceWeaver/weaver/fair_scheduling"
+	shortFairSchedulingKey = "fair_scheduling"
+)
+
+var fairQueueDepth = metrics.NewGaugeMap[fairQueueLabels](
+	imetrics.FairQueueDepthName,
+	"Number of calls to a Service Weaver component waiting for their caller's fair share of the component's capacity",
+)
+
+type fairQueueLabels struct {
+	Component string // full component name
+	Caller    string // full calling component name
+}
+
+// fairSchedulingConfig is the "[fair_scheduling]" section of a config file. It
+// maps full component names to the maximum number of calls from other
+// processes that a replica of the component runs at once, along with the
+// weights of its callers, keyed by full caller component name. Callers
+// without a weight have weight 1. For example, the following config runs at
+// most 16 calls to Catalog at once, and gives Frontend three times the share
+// of any other caller when calls are waiting:
+//
+//	[fair_scheduling]
+//	"github.com/example/catalog/Catalog" = {max_concurrent = 16, weights = {"github.com/example/frontend/Frontend" = 3.0}}
+type fairSchedulingConfig map[string]fairSchedulingOptions
+
+// fairSchedulingOptions configures the fair scheduling of a component.
+type fairSchedulingOptions struct {
+	MaxConcurrent int                `toml:"max_concurrent"`
+	Weights       map[string]float64 `toml:"weights"`
+}
+
+// parseFairSchedulingConfig parses the fair scheduling section of the
+// provided config sections and returns a scheduler for every configured
+// component, keyed by full component name.
+func parseFairSchedulingConfig(sections map[string]string) (map[string]*fairScheduler, error) {
+	var config fairSchedulingConfig
+	if err := runtime.ParseConfigSection(fairSchedulingKey, shortFairSchedulingKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse fair scheduling config: %w", err)
+	}
+	result := map[string]*fairScheduler{}
+	for name, opts := range config {
+		result[name] = newFairScheduler(name, opts.MaxConcurrent, opts.Weights)
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *fairSchedulingConfig) Validate() error {
+	for name, opts := range *c {
+		if opts.MaxConcurrent <= 0 {
+			return fmt.Errorf("component %q: non-positive max_concurrent %d", name, opts.MaxConcurrent)
+		}
+		for caller, weight := range opts.Weights {
+			if weight <= 0 {
+				return fmt.Errorf("component %q: caller %q: non-positive weight %v", name, caller, weight)
+			}
+		}
+	}
+	return nil
+}
+
+// A fairScheduler bounds the number of calls that run at once on a component
+// and, when calls have to wait, shares the component among their callers in
+// proportion to the callers' weights, so that a noisy caller can't starve the
+// others.
+//
+// Calls are scheduled with start-time fair queueing. Every caller has a
+// virtual finish time that advances by 1/weight for every call of the caller
+// that is admitted. A free slot goes to the waiting caller with the earliest
+// virtual start time, which is its finish time, or the scheduler's virtual
+// time if the caller has been idle. The calls of a caller run in FIFO order.
+//
+// A fairScheduler is safe for concurrent use.
+type fairScheduler struct {
+	component string             // full component name
+	limit     int                // maximum number of calls running at once
+	weights   map[string]float64 // caller weights, by full caller component name
+
+	mu      sync.Mutex
+	running int                   // number of calls running
+	waiting int                   // number of calls waiting, across callers
+	vtime   float64               // virtual start time of the last admitted call
+	queues  map[string]*fairQueue // per-caller queues, by full caller component name
+}
+
+// fairQueue holds the waiting calls of a caller.
+type fairQueue struct {
+	caller  string
+	weight  float64
+	finish  float64         // virtual finish time of the last admitted call
+	waiters []chan struct{} // waiting calls, in FIFO order
+	depth   *metrics.Gauge  // number of waiting calls
+}
+
+// newFairScheduler returns a fairScheduler that runs at most limit calls to
+// the provided component at once, weighing callers by the provided weights.
+func newFairScheduler(component string, limit int, weights map[string]float64) *fairScheduler {
+	return &fairScheduler{
+		component: component,
+		limit:     limit,
+		weights:   weights,
+		queues:    map[string]*fairQueue{},
+	}
+}
+
+// acquire blocks until the call from the provided caller is granted a slot or
+// the context is done. On success, the call must be followed by exactly one
+// call to release.
+func (s *fairScheduler) acquire(ctx context.Context, caller string) error {
+	s.mu.Lock()
+	q := s.queueLocked(caller)
+	if s.running < s.limit && s.waiting == 0 {
+		s.running++
+		s.admitLocked(q)
+		s.mu.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+	q.depth.Add(1)
+	s.waiting++
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, other := range q.waiters {
+			if other == ready {
+				q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+				q.depth.Sub(1)
+				s.waiting--
+				return ctx.Err()
+			}
+		}
+		// We were granted a slot concurrently with the context being
+		// canceled. Give the slot back.
+		s.running--
+		s.wakeLocked()
+		return ctx.Err()
+	}
+}
+
+// release releases a slot acquired by acquire.
+func (s *fairScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.wakeLocked()
+}
+
+// queueLocked returns the queue of the provided caller, creating it if
+// needed.
+//
+// REQUIRES: s.mu is held.
+func (s *fairScheduler) queueLocked(caller string) *fairQueue {
+	q, ok := s.queues[caller]
+	if !ok {
+		weight, ok := s.weights[caller]
+		if !ok {
+			weight = 1
+		}
+		q = &fairQueue{
+			caller: caller,
+			weight: weight,
+			finish: s.vtime,
+			depth:  fairQueueDepth.Get(fairQueueLabels{Component: s.component, Caller: caller}),
+		}
+		s.queues[caller] = q
+	}
+	return q
+}
+
+// admitLocked advances the virtual times for a call of q that is admitted.
+//
+// REQUIRES: s.mu is held.
+func (s *fairScheduler) admitLocked(q *fairQueue) {
+	s.vtime = max(q.finish, s.vtime)
+	q.finish = s.vtime + 1/q.weight
+}
+
+// wakeLocked hands out free slots to waiting calls.
+//
+// REQUIRES: s.mu is held.
+func (s *fairScheduler) wakeLocked() {
+	for s.waiting > 0 && s.running < s.limit {
+		// Pick the waiting caller with the earliest virtual start time,
+		// breaking ties by name so that scheduling is deterministic.
+		var next *fairQueue
+		var nextStart float64
+		for _, q := range s.queues {
+			if len(q.waiters) == 0 {
+				continue
+			}
+			start := max(q.finish, s.vtime)
+			if next == nil || start < nextStart || (start == nextStart && q.caller < next.caller) {
+				next, nextStart = q, start
+			}
+		}
+
+		ready := next.waiters[0]
+		next.waiters = next.waiters[1:]
+		next.depth.Sub(1)
+		s.waiting--
+		s.running++
+		s.admitLocked(next)
+		close(ready)
+	}
+}
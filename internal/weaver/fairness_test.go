@@ -0,0 +1,160 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fairQueueDepthValue returns the value of the queue depth metric of the
+// provided caller of the provided component.
+func fairQueueDepthValue(component, caller string) float64 {
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == imetrics.FairQueueDepthName && snap.Labels["component"] == component && snap.Labels["caller"] == caller {
+			return snap.Value
+		}
+	}
+	return 0
+}
+
+// waitForWaiting waits until the scheduler has n waiting calls.
+func waitForWaiting(t *testing.T, s *fairScheduler, n int) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		waiting := s.waiting
+		s.mu.Unlock()
+		if waiting == n {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %d waiting calls", n)
+}
+
+func TestFairScheduler(t *testing.T) {
+	const component = "TestFairScheduler/Catalog"
+	const noisy, quiet = "TestFairScheduler/Noisy", "TestFairScheduler/Quiet"
+	ctx := context.Background()
+	s := newFairScheduler(component, 1, map[string]float64{quiet: 2})
+
+	// Occupy the only slot.
+	if err := s.acquire(ctx, "TestFairScheduler/Other"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queue four calls from every caller, the noisy caller's first.
+	admitted := make(chan string)
+	enqueue := func(caller string, n int) {
+		go func() {
+			if err := s.acquire(ctx, caller); err != nil {
+				t.Error(err)
+			}
+			admitted <- caller
+		}()
+		waitForWaiting(t, s, n)
+	}
+	for i := 1; i <= 4; i++ {
+		enqueue(noisy, i)
+	}
+	for i := 5; i <= 8; i++ {
+		enqueue(quiet, i)
+	}
+	for _, caller := range []string{noisy, quiet} {
+		if got, want := fairQueueDepthValue(component, caller), 4.0; got != want {
+			t.Errorf("%s queue depth: got %v, want %v", caller, got, want)
+		}
+	}
+
+	// Run the calls one at a time. The quiet caller, which has twice the
+	// weight, gets twice the share of the noisy caller while both wait.
+	var got []string
+	for i := 0; i < 8; i++ {
+		s.release()
+		got = append(got, <-admitted)
+	}
+	want := []string{noisy, quiet, quiet, noisy, quiet, quiet, noisy, noisy}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("bad admission order (-want +got):\n%s", diff)
+	}
+	for _, caller := range []string{noisy, quiet} {
+		if got := fairQueueDepthValue(component, caller); got != 0 {
+			t.Errorf("%s queue depth: got %v, want 0", caller, got)
+		}
+	}
+}
+
+func TestFairSchedulerCancel(t *testing.T) {
+	const component, caller = "TestFairSchedulerCancel/Catalog", "TestFairSchedulerCancel/Frontend"
+	s := newFairScheduler(component, 1, nil)
+	if err := s.acquire(context.Background(), caller); err != nil {
+		t.Fatal(err)
+	}
+
+	// A waiting call whose context is canceled leaves the queue.
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- s.acquire(ctx, caller) }()
+	waitForWaiting(t, s, 1)
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire: got %v, want %v", err, context.Canceled)
+	}
+	if got := fairQueueDepthValue(component, caller); got != 0 {
+		t.Errorf("queue depth: got %v, want 0", got)
+	}
+
+	// The slot is handed out again once released.
+	s.release()
+	if err := s.acquire(context.Background(), caller); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseFairSchedulingConfig(t *testing.T) {
+	const name = "github.com/example/catalog/Catalog"
+	const caller = "github.com/example/frontend/Frontend"
+	sections := map[string]string{shortFairSchedulingKey: `"` + name + `" = {max_concurrent = 16, weights = {"` + caller + `" = 3.0}}`}
+	got, err := parseFairSchedulingConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := got[name]
+	if !ok {
+		t.Fatalf("no scheduler for %q", name)
+	}
+	if s.limit != 16 {
+		t.Errorf("limit: got %d, want 16", s.limit)
+	}
+	if diff := cmp.Diff(map[string]float64{caller: 3}, s.weights); diff != "" {
+		t.Errorf("bad weights (-want +got):\n%s", diff)
+	}
+
+	for _, config := range []string{
+		`"` + name + `" = {max_concurrent = 0}`,
+		`"` + name + `" = {max_concurrent = 1, weights = {"` + caller + `" = 0.0}}`,
+	} {
+		sections := map[string]string{shortFairSchedulingKey: config}
+		if _, err := parseFairSchedulingConfig(sections); err == nil {
+			t.Errorf("%s: unexpected success", config)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures the routing
+	// fallback of routed components.
+	routingFallbackKey      = "github.com/ServiceWeaver/weaver/routing_fallback"
+	shortRoutingFallbackKey = "routing_fallback"
+)
+
+var rerouted = metrics.NewCounterMap[reroutedLabels](
+	imetrics.RoutingReroutedName,
+	"Number of calls to a routed Service Weaver component that were rerouted away from the unreachable replicas of their key",
+)
+
+type reroutedLabels struct {
+	Component string // full component name
+}
+
+// routingFallbackConfig is the "[routing_fallback]" section of a config file.
+// When none of the replicas that a routed call's key is assigned to can be
+// reached, a call to a listed component is rerouted to the next reachable
+// replica in the ring of assigned slices, instead of waiting for one of the
+// key's replicas to come back. For example:
+//
+//	[routing_fallback]
+//	components = ["github.com/example/cache/Cache"]
+type routingFallbackConfig struct {
+	// Components are the full names of the routed components whose calls
+	// are rerouted.
+	Components []string
+}
+
+// parseRoutingFallbackConfig parses the routing fallback section of the
+// provided config sections and returns the set of components whose calls are
+// rerouted.
+func parseRoutingFallbackConfig(sections map[string]string) (map[string]bool, error) {
+	var config routingFallbackConfig
+	if err := runtime.ParseConfigSection(routingFallbackKey, shortRoutingFallbackKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse routing fallback config: %w", err)
+	}
+	fallback := map[string]bool{}
+	for _, name := range config.Components {
+		fallback[name] = true
+	}
+	return fallback, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// Key and short key of the config section that configures components
+	// served by external gRPC servers.
+	grpcKey      = "github.com/ServiceWeaver/weaver/grpc"
+	shortGRPCKey = "grpc"
+)
+
+// grpcConfig is the "[grpc]" section of a config file. It maps full component
+// names to external gRPC servers that serve them. Calls to such a component
+// are sent to the gRPC server, and the component is never started by Service
+// Weaver. For example:
+//
+//	[grpc]
+//	"github.com/example/bank/Ledger" = {address = "ledger.example.com:50051", tls = true}
+//
+// Every component method M is invoked as the unary gRPC method
+// "/<full component name>/M". The request and response messages are the
+// Service Weaver encodings of the method's arguments and results, the same
+// payloads that codegen.Server handlers consume and produce.
+type grpcConfig map[string]grpcEndpoint
+
+// grpcEndpoint is an external gRPC server.
+type grpcEndpoint struct {
+	// Address is the gRPC dial target, e.g., "dns:///ledger:50051".
+	Address string
+
+	// TLS enables TLS, verified against the system roots. If false,
+	// connections are unencrypted.
+	TLS bool
+}
+
+// parseGRPCConfig parses the gRPC section of the provided config sections and
+// returns the external gRPC servers, keyed by full component name.
+func parseGRPCConfig(sections map[string]string) (map[string]grpcEndpoint, error) {
+	var config grpcConfig
+	if err := runtime.ParseConfigSection(grpcKey, shortGRPCKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse grpc config: %w", err)
+	}
+	return config, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *grpcConfig) Validate() error {
+	for name, endpoint := range *c {
+		if name == runtime.Main {
+			return fmt.Errorf("component %q cannot be served by gRPC", name)
+		}
+		if endpoint.Address == "" {
+			return fmt.Errorf("component %q: missing address", name)
+		}
+	}
+	return nil
+}
+
+// grpcStub is a codegen.Stub that invokes the methods of a component served by
+// an external gRPC server. See grpcConfig.
+type grpcStub struct {
+	conn    *grpc.ClientConn
+	methods []string // full gRPC method names, by method index
+	tracer  trace.Tracer
+}
+
+var _ codegen.Stub = (*grpcStub)(nil)
+
+// newGRPCStub returns a stub for the component with the provided registration
+// that sends calls to the provided gRPC server.
+func newGRPCStub(reg *codegen.Registration, endpoint grpcEndpoint, tracer trace.Tracer) (*grpcStub, error) {
+	creds := insecure.NewCredentials()
+	if endpoint.TLS {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	conn, err := grpc.Dial(endpoint.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dial %q for component %q: %w", endpoint.Address, reg.Name, err)
+	}
+	methods := make([]string, reg.Iface.NumMethod())
+	for i := range methods {
+		methods[i] = fmt.Sprintf("/%s/%s", reg.Name, reg.Iface.Method(i).Name)
+	}
+	return &grpcStub{conn: conn, methods: methods, tracer: tracer}, nil
+}
+
+// Tracer implements the codegen.Stub interface.
+func (s *grpcStub) Tracer() trace.Tracer {
+	return s.tracer
+}
+
+// Run implements the codegen.Stub interface.
+func (s *grpcStub) Run(ctx context.Context, method int, args []byte, _ uint64) ([]byte, error) {
+	if args == nil {
+		// gRPC rejects nil messages.
+		args = []byte{}
+	}
+	var results []byte
+	if err := s.conn.Invoke(ctx, s.methods[method], &args, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// rawCodec is a gRPC codec whose messages are already encoded byte slices.
+// Messages must have type *[]byte.
+type rawCodec struct{}
+
+// Marshal implements the encoding.Codec interface.
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+// Unmarshal implements the encoding.Codec interface.
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Name implements the encoding.Codec interface.
+func (rawCodec) Name() string {
+	return "serviceweaver-raw"
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures handler
+	// pools.
+	handlerPoolsKey      = "github.com/ServiceWeaver/weaver/handler_pools"
+	shortHandlerPoolsKey = "handler_pools"
+)
+
+// HandlerPoolFullError is the error returned by a remote call to a component
+// whose handler pool has no idle worker and no room left in its queue. The
+// error is marked retryable.
+var HandlerPoolFullError = errors.New("Service Weaver component handler pool is full")
+
+var (
+	handlerPoolBusy = metrics.NewGaugeMap[handlerPoolLabels](
+		imetrics.HandlerPoolBusyName,
+		"Number of workers of a Service Weaver component's handler pool that are running a call",
+	)
+	handlerPoolQueueDepth = metrics.NewGaugeMap[handlerPoolLabels](
+		imetrics.HandlerPoolQueueDepthName,
+		"Number of calls to a Service Weaver component waiting for a worker of the component's handler pool",
+	)
+	handlerPoolQueueWait = metrics.NewHistogramMap[handlerPoolLabels](
+		imetrics.HandlerPoolQueueWaitName,
+		"Duration, in microseconds, that a call to a Service Weaver component waits for a worker of the component's handler pool",
+		imetrics.GeneratedBuckets,
+	)
+	handlerPoolRejected = metrics.NewCounterMap[handlerPoolLabels](
+		imetrics.HandlerPoolRejectedName,
+		"Number of calls to a Service Weaver component rejected because the component's handler pool was full",
+	)
+)
+
+type handlerPoolLabels struct {
+	Component string // full component name
+}
+
+// handlerPoolsConfig is the "[handler_pools]" section of a config file. It
+// maps full component names to the number of workers that run the calls from
+// other processes to a replica of the component, along with the number of
+// calls that may wait for a worker. For example, the following config runs
+// calls to Catalog on 16 workers, with at most 64 more calls waiting:
+//
+//	[handler_pools]
+//	"github.com/example/catalog/Catalog" = {workers = 16, queue = 64}
+type handlerPoolsConfig map[string]handlerPoolOptions
+
+// handlerPoolOptions configures the handler pool of a component.
+type handlerPoolOptions struct {
+	Workers int `toml:"workers"`
+	Queue   int `toml:"queue"`
+}
+
+// parseHandlerPoolsConfig parses the handler pools section of the provided
+// config sections and returns the options of every configured component,
+// keyed by full component name.
+func parseHandlerPoolsConfig(sections map[string]string) (map[string]handlerPoolOptions, error) {
+	var config handlerPoolsConfig
+	if err := runtime.ParseConfigSection(handlerPoolsKey, shortHandlerPoolsKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse handler pools config: %w", err)
+	}
+	return config, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *handlerPoolsConfig) Validate() error {
+	for name, opts := range *c {
+		if opts.Workers <= 0 {
+			return fmt.Errorf("component %q: non-positive workers %d", name, opts.Workers)
+		}
+		if opts.Queue < 0 {
+			return fmt.Errorf("component %q: negative queue %d", name, opts.Queue)
+		}
+	}
+	return nil
+}
+
+// A handlerPool runs the calls to a component on a fixed number of worker
+// goroutines, so that a burst of calls can't make the component run an
+// unbounded number of them at once. Calls wait for a worker in a bounded
+// queue, in FIFO order. Calls that find the queue full are rejected rather
+// than queued.
+//
+// A handlerPool is safe for concurrent use.
+type handlerPool struct {
+	component string
+	queue     chan *handlerTask
+	busy      *metrics.Gauge     // number of workers running a call
+	depth     *metrics.Gauge     // number of queued calls
+	wait      *metrics.Histogram // time calls spend queued
+	rejected  *metrics.Counter   // number of rejected calls
+}
+
+// handlerTask is a call queued on a handlerPool.
+type handlerTask struct {
+	ctx      context.Context
+	f        func(context.Context)
+	enqueued time.Time
+	done     chan struct{} // closed once f returns, or is skipped
+}
+
+// newHandlerPool returns a handlerPool that runs the calls to the provided
+// component on the provided number of workers, with at most queue calls
+// waiting for a worker. The workers exit when ctx is done.
+func newHandlerPool(ctx context.Context, component string, workers, queue int) *handlerPool {
+	labels := handlerPoolLabels{Component: component}
+	p := &handlerPool{
+		component: component,
+		queue:     make(chan *handlerTask, queue),
+		busy:      handlerPoolBusy.Get(labels),
+		depth:     handlerPoolQueueDepth.Get(labels),
+		wait:      handlerPoolQueueWait.Get(labels),
+		rejected:  handlerPoolRejected.Get(labels),
+	}
+	for i := 0; i < workers; i++ {
+		go p.work(ctx)
+	}
+	return p
+}
+
+// run runs f on a worker of the pool and waits for it to return. It returns
+// an error that wraps HandlerPoolFullError, without running f, if the pool has
+// no idle worker and its queue is full. If ctx is done before f returns, run
+// returns ctx.Err() without waiting for f, and f is not run at all if it is
+// still queued.
+func (p *handlerPool) run(ctx context.Context, f func(context.Context)) error {
+	t := &handlerTask{ctx: ctx, f: f, enqueued: time.Now(), done: make(chan struct{})}
+	p.depth.Add(1)
+	select {
+	case p.queue <- t:
+	default:
+		p.depth.Sub(1)
+		p.rejected.Add(1)
+		err := fmt.Errorf("component %q has %d calls queued: %w", p.component, cap(p.queue), HandlerPoolFullError)
+		return codegen.Retryable(err)
+	}
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work runs queued calls until ctx is done.
+func (p *handlerPool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-p.queue:
+			p.depth.Sub(1)
+			p.wait.Put(float64(time.Since(t.enqueued).Microseconds()))
+			if t.ctx.Err() == nil {
+				p.busy.Add(1)
+				t.f(t.ctx)
+				p.busy.Sub(1)
+			}
+			close(t.done)
+		}
+	}
+}
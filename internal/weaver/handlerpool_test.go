@@ -0,0 +1,158 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+)
+
+// handlerPoolValue returns the value of the named metric of the handler pool
+// of the provided component.
+func handlerPoolValue(name, component string) float64 {
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == name && snap.Labels["component"] == component {
+			return snap.Value
+		}
+	}
+	return 0
+}
+
+func TestHandlerPool(t *testing.T) {
+	const component = "TestHandlerPool/Catalog"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newHandlerPool(ctx, component, 1, 1)
+
+	// Occupy the only worker.
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	errs := make(chan error, 2)
+	go func() {
+		errs <- p.run(ctx, func(context.Context) {
+			close(started)
+			<-unblock
+		})
+	}()
+	<-started
+	if got, want := handlerPoolValue(imetrics.HandlerPoolBusyName, component), 1.0; got != want {
+		t.Errorf("busy: got %v, want %v", got, want)
+	}
+
+	// Fill the queue.
+	ran := make(chan struct{})
+	go func() {
+		errs <- p.run(ctx, func(context.Context) { close(ran) })
+	}()
+	for handlerPoolValue(imetrics.HandlerPoolQueueDepthName, component) != 1 {
+		// Wait for the call to be queued.
+	}
+
+	// Calls beyond the queue are rejected without being run.
+	before := handlerPoolValue(imetrics.HandlerPoolRejectedName, component)
+	err := p.run(ctx, func(context.Context) { t.Error("rejected call ran") })
+	if !errors.Is(err, HandlerPoolFullError) {
+		t.Fatalf("call to a full pool: got %v, want %v", err, HandlerPoolFullError)
+	}
+	if !codegen.IsRetryable(err) {
+		t.Errorf("call to a full pool: got non-retryable error %v", err)
+	}
+	if got, want := handlerPoolValue(imetrics.HandlerPoolRejectedName, component)-before, 1.0; got != want {
+		t.Errorf("rejected: got %v, want %v", got, want)
+	}
+
+	// Once the worker is free, the queued call runs.
+	close(unblock)
+	<-ran
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := handlerPoolValue(imetrics.HandlerPoolQueueDepthName, component), 0.0; got != want {
+		t.Errorf("queue depth: got %v, want %v", got, want)
+	}
+	if got, want := handlerPoolValue(imetrics.HandlerPoolBusyName, component), 0.0; got != want {
+		t.Errorf("busy: got %v, want %v", got, want)
+	}
+}
+
+func TestHandlerPoolCanceled(t *testing.T) {
+	const component = "TestHandlerPoolCanceled/Catalog"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newHandlerPool(ctx, component, 1, 1)
+
+	// Occupy the only worker.
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- p.run(ctx, func(context.Context) {
+			close(started)
+			<-unblock
+		})
+	}()
+	<-started
+
+	// A queued call whose context is canceled returns without being run.
+	callCtx, callCancel := context.WithCancel(ctx)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- p.run(callCtx, func(context.Context) { t.Error("canceled call ran") })
+	}()
+	for handlerPoolValue(imetrics.HandlerPoolQueueDepthName, component) != 1 {
+		// Wait for the call to be queued.
+	}
+	callCancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled call: got %v, want %v", err, context.Canceled)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	// The worker skips the canceled call and serves new calls.
+	if err := p.run(ctx, func(context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseHandlerPoolsConfig(t *testing.T) {
+	const name = "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortHandlerPoolsKey: `"` + name + `" = {workers = 16, queue = 64}`}
+	got, err := parseHandlerPoolsConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (handlerPoolOptions{Workers: 16, Queue: 64}); got[name] != want {
+		t.Fatalf("got %+v, want %+v", got[name], want)
+	}
+
+	for _, bad := range []string{
+		`"` + name + `" = {workers = 0, queue = 64}`,
+		`"` + name + `" = {workers = 16, queue = -1}`,
+	} {
+		if _, err := parseHandlerPoolsConfig(map[string]string{shortHandlerPoolsKey: bad}); err == nil {
+			t.Errorf("%s: unexpected success", bad)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures health gating.
+	healthGatingKey      = "github.com/ServiceWeaver/weaver/health_gating"
+	shortHealthGatingKey = "health_gating"
+
+	// A component is considered unhealthy if it reported unhealthy in a poll
+	// that finished less than maxHealthStaleness polling intervals ago.
+	maxHealthStaleness = 2
+)
+
+// healthGatingConfig is the "[health_gating]" section of a config file. It
+// maps full caller component names to the components whose health they
+// consult before every call, along with how often the health of those
+// components is polled. For example, the following config makes the calls
+// from Frontend to Catalog fail fast while Catalog reports that it is
+// unhealthy, polling the health of Catalog every 5 seconds:
+//
+//	[health_gating]
+//	"github.com/example/frontend/Frontend" = {"github.com/example/catalog/Catalog" = "5s"}
+//
+// A component is polled once, at the shortest interval configured for it,
+// no matter how many callers consult its health.
+type healthGatingConfig map[string]map[string]string
+
+// parseHealthGatingConfig parses the health gating section of the provided
+// config sections and returns the polling interval of every gated component,
+// keyed by full caller component name and then by full component name.
+func parseHealthGatingConfig(sections map[string]string) (map[string]map[string]time.Duration, error) {
+	var config healthGatingConfig
+	if err := runtime.ParseConfigSection(healthGatingKey, shortHealthGatingKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse health gating config: %w", err)
+	}
+	result := map[string]map[string]time.Duration{}
+	for caller, components := range config {
+		result[caller] = map[string]time.Duration{}
+		for name, interval := range components {
+			// Validate has already checked that the interval parses.
+			result[caller][name], _ = time.ParseDuration(interval)
+		}
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *healthGatingConfig) Validate() error {
+	for caller, components := range *c {
+		for name, interval := range components {
+			d, err := time.ParseDuration(interval)
+			if err != nil {
+				return fmt.Errorf("caller %q: component %q: invalid interval %q: %w", caller, name, interval, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("caller %q: component %q: non-positive interval %v", caller, name, d)
+			}
+		}
+	}
+	return nil
+}
+
+// healthChecker is the interface implemented by components that report their
+// own health.
+type healthChecker interface {
+	Healthy(context.Context) error
+}
+
+// checkHealthGating checks that the component with the provided registration
+// reports its own health, i.e. that it has a "Healthy(context.Context) error"
+// method.
+func checkHealthGating(reg *codegen.Registration) error {
+	if !reg.Iface.Implements(reflect.TypeOf((*healthChecker)(nil)).Elem()) {
+		return fmt.Errorf("health gating: component %q has no Healthy(context.Context) error method", reg.Name)
+	}
+	return nil
+}
+
+// healthPoller periodically polls the health of a component.
+type healthPoller struct {
+	name     string        // full component name
+	interval time.Duration // polling interval
+
+	mu     sync.Mutex
+	err    error     // the error returned by the last poll
+	polled time.Time // when the last poll finished, or zero if none has
+}
+
+// newHealthPoller returns a poller that polls the health of the provided
+// component at the provided interval, once run is called.
+func newHealthPoller(name string, interval time.Duration) *healthPoller {
+	return &healthPoller{name: name, interval: interval}
+}
+
+// run polls the health of the component, using c, until ctx is done.
+func (p *healthPoller) run(ctx context.Context, c healthChecker) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.poll(ctx, c)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll polls the health of the component once, using c.
+func (p *healthPoller) poll(ctx context.Context, c healthChecker) {
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+	err := c.Healthy(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+	p.polled = clock.Now()
+}
+
+// check returns an error that wraps UnavailableError if the component
+// reported that it is unhealthy recently, or nil otherwise.
+func (p *healthPoller) check() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil || clock.Since(p.polled) >= maxHealthStaleness*p.interval {
+		return nil
+	}
+	return fmt.Errorf("component %q reported that it is unhealthy: %w: %w", p.name, UnavailableError, p.err)
+}
+
+// replicaHealth polls the health of every replica of a component separately,
+// so that calls made to every replica (see RemoteWeavelet.getReplicas) can
+// skip the replicas that report that they are unhealthy.
+//
+// replicaHealth is not safe for concurrent use.
+type replicaHealth struct {
+	name     string                        // full component name
+	interval time.Duration                 // polling interval
+	pollers  map[string]*healthPoller      // pollers, by replica address
+	cancels  map[string]context.CancelFunc // stop the pollers, by replica address
+}
+
+// newReplicaHealth returns a replicaHealth that polls the health of the
+// replicas of the provided component at the provided interval.
+func newReplicaHealth(name string, interval time.Duration) *replicaHealth {
+	return &replicaHealth{
+		name:     name,
+		interval: interval,
+		pollers:  map[string]*healthPoller{},
+		cancels:  map[string]context.CancelFunc{},
+	}
+}
+
+// update starts polling the health of the replicas in clients, keyed by
+// address, that are not polled yet, using their clients, until ctx is done.
+// It stops polling the replicas that are not in clients.
+func (h *replicaHealth) update(ctx context.Context, clients map[string]healthChecker) {
+	for addr, client := range clients {
+		if _, ok := h.pollers[addr]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		p := newHealthPoller(h.name, h.interval)
+		h.pollers[addr] = p
+		h.cancels[addr] = cancel
+		go p.run(ctx, client)
+	}
+	for addr, cancel := range h.cancels {
+		if _, ok := clients[addr]; !ok {
+			cancel()
+			delete(h.pollers, addr)
+			delete(h.cancels, addr)
+		}
+	}
+}
+
+// check returns an error that wraps UnavailableError if the replica with the
+// provided address reported that it is unhealthy recently, or nil otherwise.
+func (h *replicaHealth) check(addr string) error {
+	if p, ok := h.pollers[addr]; ok {
+		return p.check()
+	}
+	return nil
+}
+
+// healthGatedStub is a Stub that fails calls fast, without sending them, while
+// the component it calls reports that it is unhealthy. Calls to the Healthy
+// method itself are never gated. It forwards the optional AccessLogger,
+// Hedger, and Queuer methods to the stub it wraps.
+type healthGatedStub struct {
+	codegen.Stub
+	poller  *healthPoller
+	healthy int // index of the Healthy method
+}
+
+var (
+	_ codegen.AccessLogger = &healthGatedStub{}
+	_ codegen.Hedger       = &healthGatedStub{}
+	_ codegen.Queuer       = &healthGatedStub{}
+)
+
+// Run implements the codegen.Stub interface.
+func (s *healthGatedStub) Run(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, error) {
+	results, _, err := s.RunQueued(ctx, method, args, shardKey)
+	return results, err
+}
+
+// RunQueued implements the codegen.Queuer interface.
+func (s *healthGatedStub) RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, time.Duration, error) {
+	if method != s.healthy {
+		if err := s.poller.check(); err != nil {
+			return nil, 0, err
+		}
+	}
+	if q, ok := s.Stub.(codegen.Queuer); ok {
+		return q.RunQueued(ctx, method, args, shardKey)
+	}
+	results, err := s.Stub.Run(ctx, method, args, shardKey)
+	return results, 0, err
+}
+
+// HedgeDelay implements the codegen.Hedger interface.
+func (s *healthGatedStub) HedgeDelay(method int) time.Duration {
+	if h, ok := s.Stub.(codegen.Hedger); ok {
+		return h.HedgeDelay(method)
+	}
+	return 0
+}
+
+// LogAccess implements the codegen.AccessLogger interface.
+func (s *healthGatedStub) LogAccess(ctx context.Context, method int, duration time.Duration, err error) {
+	if l, ok := s.Stub.(codegen.AccessLogger); ok {
+		l.LogAccess(ctx, method, duration, err)
+	}
+}
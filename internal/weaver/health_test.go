@@ -0,0 +1,147 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestParseHealthGatingConfig(t *testing.T) {
+	const caller = "github.com/example/frontend/Frontend"
+	const name = "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortHealthGatingKey: `"` + caller + `" = {"` + name + `" = "5s"}`}
+	got, err := parseHealthGatingConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]time.Duration{caller: {name: 5 * time.Second}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad intervals (-want +got):\n%s", diff)
+	}
+
+	for _, interval := range []string{`"0s"`, `"often"`} {
+		sections = map[string]string{shortHealthGatingKey: `"` + caller + `" = {"` + name + `" = ` + interval + `}`}
+		if _, err := parseHealthGatingConfig(sections); err == nil {
+			t.Errorf("unexpected success for interval %s", interval)
+		}
+	}
+}
+
+// fakeHealthChecker is a healthChecker that returns err.
+type fakeHealthChecker struct {
+	err error
+}
+
+func (f *fakeHealthChecker) Healthy(context.Context) error { return f.err }
+
+// manualClock is a clock.Clock that only advances when told to.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+// countingStub is a codegen.Stub that counts the calls made through it.
+type countingStub struct {
+	calls int
+}
+
+func (s *countingStub) Tracer() trace.Tracer { return nil }
+
+func (s *countingStub) Run(context.Context, int, []byte, uint64) ([]byte, error) {
+	s.calls++
+	return nil, nil
+}
+
+func TestHealthGatedStub(t *testing.T) {
+	c := &manualClock{now: time.Now()}
+	defer clock.Set(c)()
+
+	const healthy = 1 // index of the Healthy method
+	ctx := context.Background()
+	poller := newHealthPoller("pkg/Catalog", time.Second)
+	inner := &countingStub{}
+	stub := &healthGatedStub{Stub: inner, poller: poller, healthy: healthy}
+	checker := &fakeHealthChecker{}
+
+	// Calls go through while the component is healthy, or hasn't been
+	// polled yet.
+	if _, err := stub.Run(ctx, 0, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	poller.poll(ctx, checker)
+	if _, err := stub.Run(ctx, 0, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Calls fail fast while the component is unhealthy, except for calls to
+	// Healthy.
+	checker.err = errors.New("database is down")
+	poller.poll(ctx, checker)
+	if _, err := stub.Run(ctx, 0, nil, 0); !errors.Is(err, UnavailableError) || !errors.Is(err, checker.err) {
+		t.Fatalf("got %v, want error wrapping %v and %v", err, UnavailableError, checker.err)
+	}
+	if _, err := stub.Run(ctx, healthy, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Calls go through again once the unhealthy status is stale.
+	c.now = c.now.Add(maxHealthStaleness * time.Second)
+	if _, err := stub.Run(ctx, 0, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := inner.calls, 4; got != want {
+		t.Errorf("got %d calls, want %d", got, want)
+	}
+}
+
+func TestReplicaHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := newReplicaHealth("pkg/Catalog", time.Hour)
+	healthy := &fakeHealthChecker{}
+	unhealthy := &fakeHealthChecker{err: errors.New("database is down")}
+	h.update(ctx, map[string]healthChecker{"a": healthy, "b": unhealthy})
+
+	// Wait for the first poll of the unhealthy replica.
+	for deadline := time.Now().Add(10 * time.Second); h.check("b") == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("unhealthy replica not reported as unhealthy")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := h.check("b"); !errors.Is(err, UnavailableError) || !errors.Is(err, unhealthy.err) {
+		t.Fatalf("unhealthy replica: got %v, want error wrapping %v and %v", err, UnavailableError, unhealthy.err)
+	}
+	if err := h.check("a"); err != nil {
+		t.Fatalf("healthy replica: %v", err)
+	}
+
+	// Replicas that are no longer available are no longer polled.
+	h.update(ctx, map[string]healthChecker{"a": healthy})
+	if err := h.check("b"); err != nil {
+		t.Fatalf("unavailable replica: %v", err)
+	}
+	if got, want := len(h.pollers), 1; got != want {
+		t.Fatalf("got %d pollers, want %d", got, want)
+	}
+}
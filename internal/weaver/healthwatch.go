@@ -0,0 +1,177 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures the health
+	// polling of OnHealthChange.
+	healthPollingKey      = "github.com/ServiceWeaver/weaver/health_polling"
+	shortHealthPollingKey = "health_polling"
+
+	// Defaults of the health polling interval and jitter.
+	defaultHealthPollInterval = 10 * time.Second
+	defaultHealthPollJitter   = 0.2
+)
+
+// HealthStatus is the health of a component, as reported by its
+// "Healthy(context.Context) error" method.
+type HealthStatus int
+
+const (
+	HealthUnknown   HealthStatus = iota // not polled yet
+	HealthHealthy                       // Healthy returned nil
+	HealthUnhealthy                     // Healthy returned an error
+)
+
+// String implements the fmt.Stringer interface.
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthUnknown:
+		return "unknown"
+	case HealthHealthy:
+		return "healthy"
+	case HealthUnhealthy:
+		return "unhealthy"
+	default:
+		return fmt.Sprintf("HealthStatus(%d)", int(s))
+	}
+}
+
+// healthPollingConfig is the "[health_polling]" section of a config file. It
+// configures how often the health of a component is polled on behalf of the
+// subscribers registered with OnHealthChange. Every wait between two polls is
+// the interval, randomly adjusted by up to the jitter fraction of it in either
+// direction, so that the replicas of a component are not polled in lockstep.
+// For example:
+//
+//	[health_polling]
+//	interval = "5s"
+//	jitter = 0.1
+type healthPollingConfig struct {
+	// Interval is how often the health of a component is polled, e.g., "5s".
+	// Defaults to 10 seconds.
+	Interval string
+
+	// Jitter is the fraction of the interval, in [0, 1), by which every wait
+	// is randomly adjusted. Defaults to 0.2.
+	Jitter *float64
+}
+
+// healthPolling is the parsed health polling section of a config file.
+type healthPolling struct {
+	interval time.Duration
+	jitter   float64
+}
+
+// parseHealthPollingConfig parses the health polling section of the provided
+// config sections, filling in defaults for missing values.
+func parseHealthPollingConfig(sections map[string]string) (healthPolling, error) {
+	var config healthPollingConfig
+	if err := runtime.ParseConfigSection(healthPollingKey, shortHealthPollingKey, sections, &config); err != nil {
+		return healthPolling{}, fmt.Errorf("parse health polling config: %w", err)
+	}
+	polling := healthPolling{interval: defaultHealthPollInterval, jitter: defaultHealthPollJitter}
+	if config.Interval != "" {
+		// Validate has already checked that the interval parses.
+		polling.interval, _ = time.ParseDuration(config.Interval)
+	}
+	if config.Jitter != nil {
+		polling.jitter = *config.Jitter
+	}
+	return polling, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *healthPollingConfig) Validate() error {
+	if c.Interval != "" {
+		interval, err := time.ParseDuration(c.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q: %w", c.Interval, err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("non-positive interval %v", interval)
+		}
+	}
+	if c.Jitter != nil && (*c.Jitter < 0 || *c.Jitter >= 1) {
+		return fmt.Errorf("jitter %v not in [0, 1)", *c.Jitter)
+	}
+	return nil
+}
+
+// watchHealth polls the health of a component, using c, and calls f with the
+// old and new status of the component every time its status changes, until ctx
+// is done. The first poll changes the status from HealthUnknown. Calls to f
+// are made sequentially, from the goroutine that called watchHealth.
+func watchHealth(ctx context.Context, c healthChecker, polling healthPolling, f func(old, new HealthStatus)) {
+	status := HealthUnknown
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, polling.interval)
+		err := c.Healthy(pollCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		next := HealthHealthy
+		if err != nil {
+			next = HealthUnhealthy
+		}
+		if next != status {
+			f(status, next)
+			status = next
+		}
+
+		timer := time.NewTimer(polling.jittered())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// jittered returns the polling interval, randomly adjusted by up to the
+// jitter fraction of it in either direction.
+func (p healthPolling) jittered() time.Duration {
+	return time.Duration(float64(p.interval) * (1 + p.jitter*(2*rand.Float64()-1)))
+}
+
+// onHealthChange implements Weavelet.OnHealthChange for a weavelet that
+// returns the handle of a component with get, and runs background loops in
+// workers.
+func onHealthChange(ctx context.Context, name string, get func(string) (any, error), polling healthPolling, workers *workerGroup, f func(old, new HealthStatus)) error {
+	c, err := get(name)
+	if err != nil {
+		return fmt.Errorf("OnHealthChange: %w", err)
+	}
+	checker, ok := c.(healthChecker)
+	if !ok {
+		return fmt.Errorf("OnHealthChange: component %q has no Healthy(context.Context) error method", name)
+	}
+	workers.spawn(ctx, func(ctx context.Context) {
+		watchHealth(ctx, checker, polling, f)
+	})
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseHealthPollingConfig(t *testing.T) {
+	got, err := parseHealthPollingConfig(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (healthPolling{defaultHealthPollInterval, defaultHealthPollJitter}); got != want {
+		t.Errorf("defaults: got %+v, want %+v", got, want)
+	}
+
+	sections := map[string]string{shortHealthPollingKey: "interval = \"5s\"\njitter = 0.0"}
+	got, err = parseHealthPollingConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (healthPolling{5 * time.Second, 0}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, section := range []string{`interval = "0s"`, `interval = "often"`, `jitter = 1.0`, `jitter = -0.1`} {
+		sections := map[string]string{shortHealthPollingKey: section}
+		if _, err := parseHealthPollingConfig(sections); err == nil {
+			t.Errorf("unexpected success for %s", section)
+		}
+	}
+}
+
+func TestHealthPollingJitter(t *testing.T) {
+	p := healthPolling{interval: time.Second, jitter: 0.2}
+	for i := 0; i < 1000; i++ {
+		if d := p.jittered(); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("jittered interval %v not within 20%% of %v", d, p.interval)
+		}
+	}
+}
+
+// sequenceHealthChecker is a healthChecker that returns the provided errors in
+// order, and then nil.
+type sequenceHealthChecker struct {
+	errs []error
+}
+
+func (s *sequenceHealthChecker) Healthy(context.Context) error {
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func TestWatchHealth(t *testing.T) {
+	type transition struct{ old, new HealthStatus }
+	var got []transition
+	unhealthy := errors.New("unhealthy")
+	checker := &sequenceHealthChecker{errs: []error{nil, nil, unhealthy, unhealthy, nil}}
+
+	// watchHealth returns once ctx is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	polling := healthPolling{interval: time.Millisecond}
+	time.AfterFunc(100*time.Millisecond, cancel)
+	watchHealth(ctx, checker, polling, func(old, new HealthStatus) {
+		got = append(got, transition{old, new})
+	})
+
+	want := []transition{
+		{HealthUnknown, HealthHealthy},
+		{HealthHealthy, HealthUnhealthy},
+		{HealthUnhealthy, HealthHealthy},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got transitions %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got transitions %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures hedged calls.
+	hedgingKey      = "github.com/ServiceWeaver/weaver/hedging"
+	shortHedgingKey = "hedging"
+)
+
+// hedgingConfig is the "[hedging]" section of a config file. It maps full
+// component names to the methods whose calls are hedged, along with how long
+// to wait before sending the hedged call. For example, the following config
+// sends a second call to Catalog.GetProduct if the first one hasn't returned
+// after 20 milliseconds:
+//
+//	[hedging]
+//	"github.com/example/catalog/Catalog" = {GetProduct = "20ms"}
+type hedgingConfig map[string]map[string]string
+
+// parseHedgingConfig parses the hedging section of the provided config
+// sections and returns the hedging delay of every configured method, keyed
+// by full component name and then by method name.
+func parseHedgingConfig(sections map[string]string) (map[string]map[string]time.Duration, error) {
+	var config hedgingConfig
+	if err := runtime.ParseConfigSection(hedgingKey, shortHedgingKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse hedging config: %w", err)
+	}
+	result := map[string]map[string]time.Duration{}
+	for name, methods := range config {
+		result[name] = map[string]time.Duration{}
+		for method, delay := range methods {
+			// Validate has already checked that the delay parses.
+			result[name][method], _ = time.ParseDuration(delay)
+		}
+	}
+	return result, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *hedgingConfig) Validate() error {
+	for name, methods := range *c {
+		for method, delay := range methods {
+			d, err := time.ParseDuration(delay)
+			if err != nil {
+				return fmt.Errorf("component %q: method %s: invalid delay %q: %w", name, method, delay, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("component %q: method %s: non-positive delay %v", name, method, d)
+			}
+		}
+	}
+	return nil
+}
+
+// checkHedging checks that every method of reg in the provided hedging delays
+// exists and is retriable. Hedging sends the same call twice, so it is only
+// safe for methods that can be retried.
+func checkHedging(reg *codegen.Registration, delays map[string]time.Duration) error {
+	for method := range delays {
+		m, ok := reg.Iface.MethodByName(method)
+		if !ok {
+			return fmt.Errorf("hedging: component %q has no method %s", reg.Name, method)
+		}
+		for _, i := range reg.NoRetry {
+			if i == m.Index {
+				return fmt.Errorf("hedging: method %s of component %q is not retriable", method, reg.Name)
+			}
+		}
+	}
+	return nil
+}
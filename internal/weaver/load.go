@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/DataDog/hyperloglog"
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	"github.com/ServiceWeaver/weaver/runtime/protos"
 	"github.com/lightstep/varopt"
 )
@@ -76,8 +77,8 @@ func newLoadCollector(component string, addr string) *loadCollector {
 	return &loadCollector{
 		component: component,
 		addr:      addr,
-		now:       func() time.Time { return time.Now() },
-		start:     time.Now(),
+		now:       clock.Now,
+		start:     clock.Now(),
 		slices:    map[uint64]*sliceSummary{},
 	}
 }
@@ -378,11 +379,18 @@ func newIndex(proto *protos.Assignment) index {
 // find finds the slice that contains the given key in O(log n) time where n is
 // the number of slices in the assignment.
 func (ind index) find(key uint64) (slice, bool) {
-	i := sort.Search(len(ind), func(i int) bool {
-		return key < ind[i].end
-	})
-	if i == len(ind) {
+	i, ok := ind.position(key)
+	if !ok {
 		return slice{}, false
 	}
 	return ind[i], true
 }
+
+// position returns the position in the index of the slice that contains the
+// given key.
+func (ind index) position(key uint64) (int, bool) {
+	i := sort.Search(len(ind), func(i int) bool {
+		return key < ind[i].end
+	})
+	return i, i < len(ind)
+}
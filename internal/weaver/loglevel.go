@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+// allLevels is the level of a component that doesn't set a log level. It
+// enables all log entries.
+const allLevels = slog.Level(math.MinInt)
+
+// logLevels tracks the log levels of components, as configured by the
+// log_level key in each component's config section. For example:
+//
+//	["github.com/example/app/Cache"]
+//	log_level = "debug"
+//
+// Every component logger consults a *slog.LevelVar returned by get, so calls
+// to update take effect in loggers that have already been handed out.
+//
+// The zero value of logLevels is ready to use.
+type logLevels struct {
+	mu       sync.Mutex
+	sections map[string]string         // latest config sections
+	levels   map[string]*slog.LevelVar // levels, by component name
+}
+
+// get returns the level of the named component.
+func (l *logLevels) get(name string) *slog.LevelVar {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if v, ok := l.levels[name]; ok {
+		return v
+	}
+	if l.levels == nil {
+		l.levels = map[string]*slog.LevelVar{}
+	}
+	v := &slog.LevelVar{}
+	v.Set(allLevels)
+	// Deployers validate component config sections before the weavelet
+	// starts, and update validates them afterwards, so we ignore errors.
+	if level, ok, _ := runtime.ParseLogLevel(name, l.sections); ok {
+		v.Set(level)
+	}
+	l.levels[name] = v
+	return v
+}
+
+// update updates the levels of all components to the levels set in the
+// provided config sections. A component with no log level has all levels
+// enabled.
+func (l *logLevels) update(sections map[string]string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Validate all levels before changing any of them.
+	levels := map[string]slog.Level{}
+	for name := range l.levels {
+		level, ok, err := runtime.ParseLogLevel(name, sections)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			level = allLevels
+		}
+		levels[name] = level
+	}
+	for name, level := range levels {
+		l.levels[name].Set(level)
+	}
+	l.sections = sections
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestLogLevels(t *testing.T) {
+	var levels logLevels
+	if err := levels.update(map[string]string{"a": `log_level = "warn"`}); err != nil {
+		t.Fatal(err)
+	}
+	a, b := levels.get("a"), levels.get("b")
+	if got, want := a.Level(), slog.LevelWarn; got != want {
+		t.Fatalf("a: got %v, want %v", got, want)
+	}
+	if got, want := b.Level(), allLevels; got != want {
+		t.Fatalf("b: got %v, want %v", got, want)
+	}
+
+	// Updates are visible through previously returned levels.
+	if err := levels.update(map[string]string{"b": `log_level = "error"`}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Level(), allLevels; got != want {
+		t.Fatalf("a: got %v, want %v", got, want)
+	}
+	if got, want := b.Level(), slog.LevelError; got != want {
+		t.Fatalf("b: got %v, want %v", got, want)
+	}
+
+	// A bad update leaves all levels unchanged.
+	if err := levels.update(map[string]string{
+		"a": `log_level = "debug"`,
+		"b": `log_level = "loud"`,
+	}); err == nil {
+		t.Fatal("unexpected success")
+	}
+	if got, want := a.Level(), allLevels; got != want {
+		t.Fatalf("a: got %v, want %v", got, want)
+	}
+	if got, want := b.Level(), slog.LevelError; got != want {
+		t.Fatalf("b: got %v, want %v", got, want)
+	}
+}
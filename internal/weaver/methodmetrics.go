@@ -0,0 +1,72 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures component
+	// method metrics.
+	methodMetricsKey      = "github.com/ServiceWeaver/weaver/method_metrics"
+	shortMethodMetricsKey = "method_metrics"
+)
+
+// methodMetricsConfig is the "[method_metrics]" section of a config file. It
+// configures which components have their method metrics aggregated across
+// callers, dropping the per-caller breakdown. For example, the following
+// config aggregates the metrics of every component except Catalog:
+//
+//	[method_metrics]
+//	aggregate_callers = true
+//
+//	[method_metrics.components]
+//	"github.com/example/catalog/Catalog" = {aggregate_callers = false}
+type methodMetricsConfig struct {
+	// AggregateCallers, if true, aggregates the method metrics of every
+	// component across callers.
+	AggregateCallers bool `toml:"aggregate_callers"`
+
+	// Components overrides AggregateCallers for individual components, keyed
+	// by full component name.
+	Components map[string]methodMetricsOptions
+}
+
+// methodMetricsOptions configures the method metrics of a component.
+type methodMetricsOptions struct {
+	// AggregateCallers is whether the method metrics of the component are
+	// aggregated across callers.
+	AggregateCallers bool `toml:"aggregate_callers"`
+}
+
+// parseMethodMetricsConfig parses the method metrics section of the provided
+// config sections and configures codegen.MethodMetricsFor accordingly. By
+// default, no component is aggregated.
+func parseMethodMetricsConfig(sections map[string]string) error {
+	var config methodMetricsConfig
+	if err := runtime.ParseConfigSection(methodMetricsKey, shortMethodMetricsKey, sections, &config); err != nil {
+		return fmt.Errorf("parse method metrics config: %w", err)
+	}
+	components := map[string]bool{}
+	for name, opts := range config.Components {
+		components[name] = opts.AggregateCallers
+	}
+	codegen.SetCallerAggregation(config.AggregateCallers, components)
+	return nil
+}
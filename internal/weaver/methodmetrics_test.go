@@ -0,0 +1,78 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+)
+
+// TestParseMethodMetricsConfig tests that the "[method_metrics]" section
+// controls which components have their method metrics aggregated across
+// callers.
+func TestParseMethodMetricsConfig(t *testing.T) {
+	t.Cleanup(func() { codegen.SetCallerAggregation(false, nil) })
+	for _, test := range []struct {
+		name    string
+		section string
+		want    map[string]bool // by component
+	}{
+		{"Default", "", map[string]bool{"a": false, "b": false}},
+		{"Global", "aggregate_callers = true", map[string]bool{"a": true, "b": true}},
+		{
+			"Override",
+			`aggregate_callers = true
+			 components = {"b" = {aggregate_callers = false}}`,
+			map[string]bool{"a": true, "b": false},
+		},
+		{
+			"Component",
+			`components = {"a" = {aggregate_callers = true}}`,
+			map[string]bool{"a": true, "b": false},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sections := map[string]string{shortMethodMetricsKey: test.section}
+			if err := parseMethodMetricsConfig(sections); err != nil {
+				t.Fatal(err)
+			}
+			method := "TestParseMethodMetricsConfig/" + test.name
+			for component := range test.want {
+				m := codegen.MethodMetricsFor(codegen.MethodLabels{
+					Caller:    "caller",
+					Component: component,
+					Method:    method,
+				})
+				m.End(m.Begin(), false, 0, 0)
+			}
+			found := map[string]bool{}
+			for _, snap := range metrics.Snapshot() {
+				if snap.Labels["method"] != method || len(snap.Bounds) > 0 {
+					continue
+				}
+				component := snap.Labels["component"]
+				found[component] = true
+				if got, want := snap.Labels["caller"] == "", test.want[component]; got != want {
+					t.Errorf("component %q: aggregated = %t, want %t", component, got, want)
+				}
+			}
+			if len(found) != len(test.want) {
+				t.Fatalf("found metrics of %d components, want %d", len(found), len(test.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures outlier
+	// detection.
+	outlierKey      = "github.com/ServiceWeaver/weaver/outlier_detection"
+	shortOutlierKey = "outlier_detection"
+)
+
+// outlierConfig is the "[outlier_detection]" section of a config file. If
+// present, calls to remote components track the error rate of every replica
+// and temporarily eject replicas that fail too often. For example:
+//
+//	[outlier_detection]
+//	error_rate = 0.5
+//	min_calls = 20
+//	cooldown = "30s"
+//
+// Omitted fields take the defaults documented in call.OutlierOptions.
+type outlierConfig struct {
+	// ErrorRate is the fraction of failed calls, between 0 and 1, at or above
+	// which a replica is ejected.
+	ErrorRate float64 `toml:"error_rate"`
+
+	// MinCalls is the number of calls to a replica over which its error rate
+	// is computed.
+	MinCalls int `toml:"min_calls"`
+
+	// Cooldown is how long an ejected replica stays ejected, e.g., "30s".
+	Cooldown string
+
+	// MaxEjectedFraction is the maximum fraction of replicas, between 0 and
+	// 1, that can be ejected at the same time.
+	MaxEjectedFraction float64 `toml:"max_ejected_fraction"`
+}
+
+// parseOutlierConfig parses the outlier detection section of the provided
+// config sections. It returns nil if outlier detection is not configured.
+func parseOutlierConfig(sections map[string]string) (*call.OutlierOptions, error) {
+	if _, ok := sections[outlierKey]; !ok {
+		if _, ok := sections[shortOutlierKey]; !ok {
+			return nil, nil
+		}
+	}
+	var config outlierConfig
+	if err := runtime.ParseConfigSection(outlierKey, shortOutlierKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse outlier detection config: %w", err)
+	}
+	opts := &call.OutlierOptions{
+		ErrorRate:          config.ErrorRate,
+		MinCalls:           config.MinCalls,
+		MaxEjectedFraction: config.MaxEjectedFraction,
+	}
+	if config.Cooldown != "" {
+		// Validate has already checked that the cooldown parses.
+		opts.Cooldown, _ = time.ParseDuration(config.Cooldown)
+	}
+	return opts, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *outlierConfig) Validate() error {
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return fmt.Errorf("error_rate %v not in [0, 1]", c.ErrorRate)
+	}
+	if c.MinCalls < 0 {
+		return fmt.Errorf("negative min_calls %d", c.MinCalls)
+	}
+	if c.MaxEjectedFraction < 0 || c.MaxEjectedFraction > 1 {
+		return fmt.Errorf("max_ejected_fraction %v not in [0, 1]", c.MaxEjectedFraction)
+	}
+	if c.Cooldown != "" {
+		cooldown, err := time.ParseDuration(c.Cooldown)
+		if err != nil {
+			return fmt.Errorf("invalid cooldown %q: %w", c.Cooldown, err)
+		}
+		if cooldown < 0 {
+			return fmt.Errorf("negative cooldown %v", cooldown)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures payload limits.
+	payloadLimitsKey      = "github.com/ServiceWeaver/weaver/payload_limits"
+	shortPayloadLimitsKey = "payload_limits"
+)
+
+// payloadLimitsConfig is the "[payload_limits]" section of a config file. It
+// maps full component names to the maximum sizes of the serialized requests
+// and replies of calls made to them. For example, the following config fails
+// calls to Catalog whose request is over 1 MiB or whose reply is over 4 MiB:
+//
+//	[payload_limits]
+//	"github.com/example/catalog/Catalog" = {max_request = 1048576, max_reply = 4194304}
+//
+// Omitted or zero limits mean that payloads are unlimited.
+type payloadLimitsConfig map[string]payloadLimits
+
+// payloadLimits are the payload limits of calls to a component.
+type payloadLimits struct {
+	// MaxRequest is the maximum size, in bytes, of a serialized request.
+	MaxRequest int `toml:"max_request"`
+
+	// MaxReply is the maximum size, in bytes, of a serialized reply.
+	MaxReply int `toml:"max_reply"`
+}
+
+// parsePayloadLimitsConfig parses the payload limits section of the provided
+// config sections and returns the payload limits of calls to every configured
+// component, keyed by full component name.
+func parsePayloadLimitsConfig(sections map[string]string) (map[string]payloadLimits, error) {
+	var config payloadLimitsConfig
+	if err := runtime.ParseConfigSection(payloadLimitsKey, shortPayloadLimitsKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse payload limits config: %w", err)
+	}
+	return config, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *payloadLimitsConfig) Validate() error {
+	for name, limits := range *c {
+		if limits.MaxRequest < 0 {
+			return fmt.Errorf("component %q: negative max_request %d", name, limits.MaxRequest)
+		}
+		if limits.MaxReply < 0 {
+			return fmt.Errorf("component %q: negative max_reply %d", name, limits.MaxReply)
+		}
+	}
+	return nil
+}
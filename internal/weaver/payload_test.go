@@ -0,0 +1,41 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePayloadLimitsConfig(t *testing.T) {
+	const name = "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortPayloadLimitsKey: `"` + name + `" = {max_request = 1024, max_reply = 4096}`}
+	got, err := parsePayloadLimitsConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]payloadLimits{name: {MaxRequest: 1024, MaxReply: 4096}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad limits (-want +got):\n%s", diff)
+	}
+
+	for _, limits := range []string{`{max_request = -1}`, `{max_reply = -1}`} {
+		sections = map[string]string{shortPayloadLimitsKey: `"` + name + `" = ` + limits}
+		if _, err := parsePayloadLimitsConfig(sections); err == nil {
+			t.Errorf("unexpected success for limits %s", limits)
+		}
+	}
+}
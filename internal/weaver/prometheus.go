@@ -0,0 +1,87 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	swruntime "github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/prometheus"
+)
+
+const (
+	// Key and short key of the config section that configures the
+	// Prometheus endpoint.
+	prometheusKey      = "github.com/ServiceWeaver/weaver/prometheus"
+	shortPrometheusKey = "prometheus"
+
+	// prometheusPath is the path at which metrics are exposed.
+	prometheusPath = "/metrics"
+)
+
+// prometheusConfig is the "[prometheus]" section of a config file. If
+// present, every process serves the metrics it exports, including the method
+// metrics of the components it hosts, in Prometheus text format at
+// "/metrics" on the provided address. For example:
+//
+//	[prometheus]
+//	address = "localhost:9090"
+//
+// Use port 0 (e.g., "localhost:0") if multiple processes share a machine.
+// Otherwise, only the first process to listen on the address serves its
+// metrics; the others log an error and keep running.
+type prometheusConfig struct {
+	// Address is the host:port on which the endpoint listens.
+	Address string
+}
+
+// parsePrometheusConfig parses the Prometheus section of the provided config
+// sections. It returns the address of the endpoint, or the empty string if
+// the endpoint is not enabled.
+func parsePrometheusConfig(sections map[string]string) (string, error) {
+	var config prometheusConfig
+	if err := swruntime.ParseConfigSection(prometheusKey, shortPrometheusKey, sections, &config); err != nil {
+		return "", fmt.Errorf("parse prometheus config: %w", err)
+	}
+	return config.Address, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *prometheusConfig) Validate() error {
+	if c.Address == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", c.Address, err)
+	}
+	return nil
+}
+
+// servePrometheus serves the metrics of the current process in Prometheus
+// text format on the provided address until ctx is cancelled.
+func servePrometheus(ctx context.Context, addr string, logger *slog.Logger) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("prometheus endpoint: %w", err)
+	}
+	logger.Info("Serving Prometheus metrics", "address", "http://"+lis.Addr().String()+prometheusPath)
+	mux := http.NewServeMux()
+	mux.Handle(prometheusPath, prometheus.Handler())
+	return serveHTTP(ctx, lis, mux)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+)
+
+func TestParsePrometheusConfig(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		sections map[string]string
+		want     string
+	}{
+		{"disabled", map[string]string{}, ""},
+		{"short", map[string]string{shortPrometheusKey: `address = "localhost:9090"`}, "localhost:9090"},
+		{"long", map[string]string{prometheusKey: `address = ":0"`}, ":0"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parsePrometheusConfig(test.sections)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParsePrometheusConfigErrors(t *testing.T) {
+	sections := map[string]string{shortPrometheusKey: `address = "localhost"`}
+	if _, err := parsePrometheusConfig(sections); err == nil {
+		t.Error("unexpected success")
+	}
+}
+
+// TestServePrometheus tests that servePrometheus exposes method metrics in
+// Prometheus text format.
+func TestServePrometheus(t *testing.T) {
+	// Pick a free port.
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	counter := metrics.NewCounterMap[struct{ Caller, Component, Method string }](
+		"test_prometheus_method_count", "Test method count")
+	counter.Get(struct{ Caller, Component, Method string }{"caller", "Foo", "Bar"}).Add(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- servePrometheus(ctx, addr, logging.NewTestSlogger(t, false)) }()
+	defer func() {
+		cancel()
+		<-errs
+	}()
+
+	var body string
+	for i := 0; ; i++ {
+		resp, err := http.Get("http://" + addr + prometheusPath)
+		if err != nil {
+			if i == 100 {
+				t.Fatal(err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = string(b)
+		break
+	}
+	const want = `test_prometheus_method_count{caller="caller",component="Foo",method="Bar"} 1`
+	if !strings.Contains(body, want) {
+		t.Fatalf("metrics missing %q:\n%s", want, body)
+	}
+}
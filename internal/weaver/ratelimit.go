@@ -0,0 +1,245 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures rate limits.
+	rateLimitsKey      = "github.com/ServiceWeaver/weaver/rate_limits"
+	shortRateLimitsKey = "rate_limits"
+)
+
+// RateLimitedError is the error returned by a call to a method of a component
+// that exceeds the rate limit of its caller. The error is marked retryable.
+var RateLimitedError = errors.New("Service Weaver component method is rate limited")
+
+var rateLimited = metrics.NewCounterMap[rateLimitedLabels](
+	imetrics.MethodRateLimitedName,
+	"Count of Service Weaver component method calls rejected because they exceeded a rate limit",
+)
+
+type rateLimitedLabels struct {
+	Component string // full component name
+	Method    string // method name
+	Caller    string // full calling component name
+}
+
+// rateLimitsConfig is the "[rate_limits]" section of a config file. It maps
+// full component names to the methods whose calls are rate limited, along
+// with the sustained rate, in calls per second, and the burst of calls that
+// every caller of the method is allowed. For example, the following config
+// allows every caller of Catalog.GetProduct to make 100 calls per second, with
+// bursts of up to 20 calls:
+//
+//	[rate_limits]
+//	"github.com/example/catalog/Catalog" = {GetProduct = {rate = 100.0, burst = 20}}
+//
+// The burst defaults to the rate, rounded up.
+type rateLimitsConfig map[string]map[string]rateLimit
+
+// rateLimit is the rate limit of a method.
+type rateLimit struct {
+	Rate  float64 `toml:"rate"`  // sustained calls per second
+	Burst int     `toml:"burst"` // maximum calls at once
+}
+
+// parseRateLimitsConfig parses the rate limits section of the provided config
+// sections and returns the rate limit of every configured method, keyed by
+// full component name and then by method name.
+func parseRateLimitsConfig(sections map[string]string) (map[string]map[string]rateLimit, error) {
+	var config rateLimitsConfig
+	if err := runtime.ParseConfigSection(rateLimitsKey, shortRateLimitsKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse rate limits config: %w", err)
+	}
+	for _, methods := range config {
+		for method, limit := range methods {
+			if limit.Burst == 0 {
+				limit.Burst = int(math.Ceil(limit.Rate))
+				methods[method] = limit
+			}
+		}
+	}
+	return config, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *rateLimitsConfig) Validate() error {
+	for name, methods := range *c {
+		for method, limit := range methods {
+			if limit.Rate <= 0 {
+				return fmt.Errorf("component %q: method %s: non-positive rate %v", name, method, limit.Rate)
+			}
+			if limit.Burst < 0 {
+				return fmt.Errorf("component %q: method %s: negative burst %d", name, method, limit.Burst)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRateLimits checks that every method of reg in the provided rate limits
+// exists.
+func checkRateLimits(reg *codegen.Registration, limits map[string]rateLimit) error {
+	for method := range limits {
+		if _, ok := reg.Iface.MethodByName(method); !ok {
+			return fmt.Errorf("rate limits: component %q has no method %s", reg.Name, method)
+		}
+	}
+	return nil
+}
+
+// A rateLimiter limits the rate of calls to the methods of a component. Every
+// caller of a method has its own token bucket, so a caller that exceeds its
+// rate doesn't eat into the budget of the others.
+//
+// A rateLimiter is safe for concurrent use.
+type rateLimiter struct {
+	component string               // full component name
+	limits    map[string]rateLimit // rate limits, by method name
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*tokenBucket
+}
+
+// bucketKey identifies the token bucket of a caller of a method.
+type bucketKey struct {
+	method string // method name
+	caller string // full calling component name, or "" if unknown
+}
+
+// tokenBucket is a token bucket. It holds tokens as of last.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a rateLimiter for the methods of the provided
+// component with the provided rate limits.
+func newRateLimiter(component string, limits map[string]rateLimit) *rateLimiter {
+	return &rateLimiter{
+		component: component,
+		limits:    limits,
+		buckets:   map[bucketKey]*tokenBucket{},
+	}
+}
+
+// allow returns whether a call to the provided method by the provided caller
+// at the provided time is within the rate limit, in which case it consumes a
+// token. Calls to methods without a rate limit are always allowed.
+func (r *rateLimiter) allow(method, caller string, now time.Time) bool {
+	limit, ok := r.limits[method]
+	if !ok {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := bucketKey{method, caller}
+	b, ok := r.buckets[key]
+	if !ok {
+		// A new caller starts with a full bucket.
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		r.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed.Seconds()*limit.Rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		rateLimited.Get(rateLimitedLabels{Component: r.component, Method: method, Caller: caller}).Inc()
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// callerStub is a Stub that records, in the context of every call, the
+// component making the call, so that the callee can rate limit every caller
+// separately. It forwards the optional AccessLogger, Hedger, and Queuer
+// methods to the stub it wraps.
+type callerStub struct {
+	codegen.Stub
+	caller string // full calling component name
+}
+
+var (
+	_ codegen.AccessLogger = &callerStub{}
+	_ codegen.Hedger       = &callerStub{}
+	_ codegen.Queuer       = &callerStub{}
+)
+
+// Run implements the codegen.Stub interface.
+func (s *callerStub) Run(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, error) {
+	results, _, err := s.RunQueued(ctx, method, args, shardKey)
+	return results, err
+}
+
+// RunQueued implements the codegen.Queuer interface.
+func (s *callerStub) RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, time.Duration, error) {
+	ctx = call.WithCaller(ctx, s.caller)
+	if q, ok := s.Stub.(codegen.Queuer); ok {
+		return q.RunQueued(ctx, method, args, shardKey)
+	}
+	results, err := s.Stub.Run(ctx, method, args, shardKey)
+	return results, 0, err
+}
+
+// HedgeDelay implements the codegen.Hedger interface.
+func (s *callerStub) HedgeDelay(method int) time.Duration {
+	if h, ok := s.Stub.(codegen.Hedger); ok {
+		return h.HedgeDelay(method)
+	}
+	return 0
+}
+
+// LogAccess implements the codegen.AccessLogger interface.
+func (s *callerStub) LogAccess(ctx context.Context, method int, duration time.Duration, err error) {
+	if l, ok := s.Stub.(codegen.AccessLogger); ok {
+		l.LogAccess(ctx, method, duration, err)
+	}
+}
+
+// localRateLimitedStub returns a stub of the component registered as reg that
+// forwards the calls made through it by the provided caller to local, a local
+// stub of the component, and rejects the calls that exceed the caller's rate
+// limit with RateLimitedError. Calls from other processes are rate limited by
+// the handlers of the component, so localRateLimitedStub is only needed for
+// calls from colocated components.
+func localRateLimitedStub(reg *codegen.Registration, local any, limiter *rateLimiter, caller string) any {
+	v := reflect.ValueOf(local)
+	return reg.ReflectStubFn(func(method string, ctx context.Context, args []any, returns []any) error {
+		if !limiter.allow(method, caller, clock.Now()) {
+			return codegen.Retryable(RateLimitedError)
+		}
+		return reflection.CallMethod(v.MethodByName(method), ctx, args, returns)
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRateLimiter(t *testing.T) {
+	const frontend, backend = "Frontend", "Backend"
+	r := newRateLimiter("Catalog", map[string]rateLimit{"Get": {Rate: 10, Burst: 2}})
+	now := time.Now()
+	if !r.allow("Get", frontend, now) || !r.allow("Get", frontend, now) {
+		t.Fatal("calls within the burst rate limited")
+	}
+	if r.allow("Get", frontend, now) {
+		t.Fatal("call beyond the burst not rate limited")
+	}
+
+	// Every caller has its own budget.
+	if !r.allow("Get", backend, now) {
+		t.Fatal("call from another caller rate limited")
+	}
+
+	// Methods without a rate limit are never rate limited.
+	for i := 0; i < 10; i++ {
+		if !r.allow("Put", frontend, now) {
+			t.Fatal("call to method without a rate limit rate limited")
+		}
+	}
+
+	// A token is added every 100ms.
+	now = now.Add(50 * time.Millisecond)
+	if r.allow("Get", frontend, now) {
+		t.Fatal("call before a token is added not rate limited")
+	}
+	now = now.Add(50 * time.Millisecond)
+	if !r.allow("Get", frontend, now) {
+		t.Fatal("call after a token is added rate limited")
+	}
+
+	// Tokens don't accumulate beyond the burst.
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if !r.allow("Get", frontend, now) {
+			t.Fatal("call within the burst rate limited")
+		}
+	}
+	if r.allow("Get", frontend, now) {
+		t.Fatal("call beyond the burst not rate limited")
+	}
+}
+
+func TestLocalRateLimitedStub(t *testing.T) {
+	const name = "TestLocalRateLimitedStub/Currency"
+	var calls int
+	local := localCurrency{convert: func(context.Context) error {
+		calls++
+		return nil
+	}}
+	r := newRateLimiter(name, map[string]rateLimit{"Convert": {Rate: 0.001, Burst: 1}})
+	reg := currencyRegistration(name)
+	frontend := localRateLimitedStub(reg, local, r, "Frontend").(currency)
+	backend := localRateLimitedStub(reg, local, r, "Backend").(currency)
+
+	ctx := context.Background()
+	if _, err := frontend.Convert(ctx, "USD", "EUR", 1); err != nil {
+		t.Fatalf("call within the burst: %v", err)
+	}
+	_, err := frontend.Convert(ctx, "USD", "EUR", 1)
+	if !errors.Is(err, RateLimitedError) {
+		t.Fatalf("call beyond the burst: got %v, want %v", err, RateLimitedError)
+	}
+	if !codegen.IsRetryable(err) {
+		t.Errorf("call beyond the burst: got non-retryable error %v", err)
+	}
+
+	// Every caller has its own budget.
+	if _, err := backend.Convert(ctx, "USD", "EUR", 1); err != nil {
+		t.Fatalf("call from another caller: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls: got %d, want 2", calls)
+	}
+}
+
+func TestParseRateLimitsConfig(t *testing.T) {
+	const name = "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortRateLimitsKey: `"` + name + `" = {Get = {rate = 100.0, burst = 20}, Put = {rate = 2.5}}`}
+	got, err := parseRateLimitsConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]rateLimit{
+		name: {
+			"Get": {Rate: 100, Burst: 20},
+			"Put": {Rate: 2.5, Burst: 3},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad limits (-want +got):\n%s", diff)
+	}
+
+	for _, limit := range []string{`{rate = 0.0}`, `{rate = 1.0, burst = -1}`} {
+		sections = map[string]string{shortRateLimitsKey: `"` + name + `" = {Get = ` + limit + `}`}
+		if _, err := parseRateLimitsConfig(sections); err == nil {
+			t.Errorf("%s: unexpected success", limit)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/runtime"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+const (
+	// Key and short key of the config section that configures read-only mode.
+	readOnlyKey      = "github.com/ServiceWeaver/weaver/readonly"
+	shortReadOnlyKey = "readonly"
+)
+
+// ReadOnlyError is the error returned when a method that is not marked
+// //weaver:readonly is invoked on a component running in read-only mode.
+var ReadOnlyError = errors.New("Service Weaver component is read-only")
+
+// readOnlyConfig is the "[readonly]" section of a config file. For example:
+//
+//	[readonly]
+//	components = ["github.com/example/bank/balancereader/BalanceReader"]
+type readOnlyConfig struct {
+	// Components are the full names of the components that run in read-only
+	// mode.
+	Components []string
+}
+
+// parseReadOnlyConfig parses the read-only section of the provided config
+// sections and returns the set of components that run in read-only mode.
+func parseReadOnlyConfig(sections map[string]string) (map[string]bool, error) {
+	var config readOnlyConfig
+	if err := runtime.ParseConfigSection(readOnlyKey, shortReadOnlyKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse read-only config: %w", err)
+	}
+	readOnly := map[string]bool{}
+	for _, name := range config.Components {
+		readOnly[name] = true
+	}
+	return readOnly, nil
+}
+
+// readOnlyMethods returns the set of names of the methods of the provided
+// component that are marked //weaver:readonly. These are the only methods
+// that can be invoked on a component running in read-only mode.
+func readOnlyMethods(reg *codegen.Registration) map[string]bool {
+	methods := map[string]bool{}
+	for _, i := range reg.ReadOnly {
+		methods[reg.Iface.Method(i).Name] = true
+	}
+	return methods
+}
+
+// readOnlyStub returns a stub of the provided component, which runs in
+// read-only mode, that wraps local, a local stub of the component. Calls to
+// methods that are not marked //weaver:readonly fail with ReadOnlyError
+// without reaching the component. Other calls are forwarded to local.
+//
+// Calls from other processes are checked by the handlers of the component, so
+// readOnlyStub is only needed for calls from colocated components.
+func readOnlyStub(reg *codegen.Registration, local any) any {
+	allowed := readOnlyMethods(reg)
+	v := reflect.ValueOf(local)
+	return reg.ReflectStubFn(func(method string, ctx context.Context, args []any, returns []any) error {
+		if !allowed[method] {
+			return ReadOnlyError
+		}
+		return reflection.CallMethod(v.MethodByName(method), ctx, args, returns)
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/config"
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+// configPollInterval is how often a weavelet checks its config file for
+// changes.
+const configPollInterval = 2 * time.Second
+
+// A reconfigurer delivers config updates to the components that have a
+// Reconfigure method (see weaver.WithConfig). Updates are delivered one at a
+// time, so a component's Reconfigure method is never called concurrently with
+// itself.
+//
+// The zero value of reconfigurer is ready to use.
+type reconfigurer struct {
+	mu         sync.Mutex
+	sections   map[string]string          // latest config sections, if updated
+	components map[string]*reconfigurable // by component name
+}
+
+// reconfigurable is a component with a Reconfigure method.
+type reconfigurable struct {
+	impl        any           // component implementation
+	configType  reflect.Type  // type T of the component's config
+	reconfigure reflect.Value // the Reconfigure(T) error method of impl
+	section     string        // config section the component was last configured with
+}
+
+// reconfigureMethod returns the Reconfigure method of the provided component
+// implementation, if it has a config of type T and a Reconfigure(T) error
+// method.
+func reconfigureMethod(impl any) (reflect.Value, reflect.Type, bool) {
+	cfg := config.Config(reflect.ValueOf(impl))
+	if cfg == nil {
+		return reflect.Value{}, nil, false
+	}
+	t := reflect.TypeOf(cfg).Elem()
+	m := reflect.ValueOf(impl).MethodByName("Reconfigure")
+	if !m.IsValid() {
+		return reflect.Value{}, nil, false
+	}
+	mt := m.Type()
+	if mt.NumIn() != 1 || mt.In(0) != t || mt.NumOut() != 1 || mt.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		return reflect.Value{}, nil, false
+	}
+	return m, t, true
+}
+
+// prepare prepares the provided component implementation to be reconfigured,
+// if it has a Reconfigure method. It must be called before the component is
+// used, i.e., before its Init method is called.
+func (r *reconfigurer) prepare(impl any) {
+	if _, _, ok := reconfigureMethod(impl); ok {
+		PrepareConfig(impl)
+	}
+}
+
+// add registers the named component implementation, which was configured
+// with the provided config section, to receive config updates, if it has a
+// Reconfigure method. If the config has been updated since the component was
+// configured, the component is reconfigured right away.
+//
+// REQUIRES: prepare has been called on impl.
+func (r *reconfigurer) add(name string, impl any, section string) error {
+	m, t, ok := reconfigureMethod(impl)
+	if !ok {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.components == nil {
+		r.components = map[string]*reconfigurable{}
+	}
+	c := &reconfigurable{impl: impl, configType: t, reconfigure: m, section: section}
+	r.components[name] = c
+	if r.sections == nil {
+		return nil
+	}
+	return r.reconfigureLocked(name, c)
+}
+
+// update reconfigures every component whose config section differs in the
+// provided config sections. A component whose new config is invalid, or whose
+// Reconfigure method fails, keeps its current config. update returns the
+// errors of the components that failed to be reconfigured, joined together.
+func (r *reconfigurer) update(sections map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sections = sections
+	var errs []error
+	for name, c := range r.components {
+		errs = append(errs, r.reconfigureLocked(name, c))
+	}
+	return errors.Join(errs...)
+}
+
+// reconfigureLocked reconfigures the named component if its config section
+// has changed.
+//
+// REQUIRES: r.mu is held.
+func (r *reconfigurer) reconfigureLocked(name string, c *reconfigurable) error {
+	section := r.sections[name]
+	if section == c.section {
+		return nil
+	}
+	cfg := reflect.New(c.configType)
+	if err := runtime.ParseComponentConfigSection(name, r.sections, cfg.Interface()); err != nil {
+		return fmt.Errorf("component %q: reconfigure: %w", name, err)
+	}
+	if err, _ := c.reconfigure.Call([]reflect.Value{cfg.Elem()})[0].Interface().(error); err != nil {
+		return fmt.Errorf("component %q: reconfigure: %w", name, err)
+	}
+	SetConfig(c.impl, cfg.Interface())
+	c.section = section
+	return nil
+}
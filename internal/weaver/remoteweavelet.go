@@ -29,7 +29,9 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	"github.com/ServiceWeaver/weaver/internal/config"
 	"github.com/ServiceWeaver/weaver/internal/control"
 	"github.com/ServiceWeaver/weaver/internal/net/call"
@@ -42,6 +44,7 @@ import (
 	"github.com/ServiceWeaver/weaver/runtime/metrics"
 	"github.com/ServiceWeaver/weaver/runtime/protos"
 	"github.com/ServiceWeaver/weaver/runtime/retry"
+	"github.com/ServiceWeaver/weaver/runtime/transport"
 	"github.com/ServiceWeaver/weaver/runtime/version"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/maps"
@@ -56,6 +59,10 @@ const readyMethodName = "ready"
 type RemoteWeaveletOptions struct {
 	Fakes         map[reflect.Type]any // component fakes, by component interface type
 	InjectRetries int                  // Number of artificial retries to inject per retriable call
+
+	// Faults to inject into remote calls, by component name and method name.
+	// Used for testing.
+	Faults map[string]map[string]func() error
 }
 
 // RemoteWeavelet is a weavelet that runs some components locally, but
@@ -75,6 +82,8 @@ type RemoteWeavelet struct {
 	deployer   control.DeployerControl // component to control deployer
 	logDst     *remoteLogger           // for writing log entries
 	syslogger  *slog.Logger            // system logger
+	logLevels  logLevels               // log levels of components
+	reconfig   reconfigurer            // delivers config updates to components
 	tracer     trace.Tracer            // tracer used by all components
 	metrics    metrics.Exporter        // helper for sending metrics to envelope
 
@@ -84,7 +93,30 @@ type RemoteWeavelet struct {
 	initDone   chan struct{}
 
 	// Ready to use by the time initDone is closed.
-	sectionConfig map[string]string
+	sectionConfig        map[string]string
+	readOnly             map[string]bool                     // components running in read-only mode
+	accessLogRate        float64                             // fraction of remote calls to log
+	compression          map[string]compression              // compression of calls, by component
+	payloadLimits        map[string]payloadLimits            // payload limits of calls, by component
+	hedging              map[string]map[string]time.Duration // hedging delays, by component and method
+	caching              map[string]map[string]time.Duration // cache TTLs, by component and method
+	callDeadlines        map[string]callDeadlines            // call deadlines, by caller
+	bulkheads            map[string]map[string]*bulkhead     // bulkheads, by caller and component
+	healthGating         map[string]map[string]time.Duration // health polling intervals, by caller and component
+	healthPolling        healthPolling                       // health polling of OnHealthChange
+	drainTimeout         time.Duration                       // how long to wait for in-flight calls when shutting down
+	shedders             map[string]*shedder                 // load shedders, by component
+	rateLimiters         map[string]*rateLimiter             // rate limiters, by component
+	fairSchedulers       map[string]*fairScheduler           // fair schedulers, by component
+	handlerPools         map[string]*handlerPool             // handler pools, by component
+	adaptiveConcurrency  map[string]call.LimiterOptions      // adaptive concurrency limits, by component
+	routingFallback      map[string]bool                     // components whose calls are rerouted
+	outlier              *call.OutlierOptions                // outlier detection, if enabled
+	maxPendingReplyBytes int                                 // per-connection budget of unwritten replies, if positive
+	maxConcurrentCalls   int                                 // bound on concurrently served calls, if positive
+	runtimeEvery         time.Duration                       // runtime metrics interval, or 0 if disabled
+	promAddr             string                              // Prometheus endpoint address, or "" if disabled
+	grpcServers          map[string]grpcEndpoint             // components served by external gRPC servers
 
 	// channel that is closed when deployer is ready.
 	deployerReady chan struct{}
@@ -96,6 +128,9 @@ type RemoteWeavelet struct {
 
 	lismu     sync.Mutex           // guards listeners
 	listeners map[string]*listener // listeners, by name
+
+	drainer drainer      // tracks in-flight calls, to drain them on shutdown
+	workers *workerGroup // background workers of components
 }
 
 var _ control.WeaveletControl = (*RemoteWeavelet)(nil)
@@ -128,6 +163,13 @@ type component struct {
 	stubErr  error        // non-nil if stub creation fails
 	stub     codegen.Stub // network stub to remote component
 
+	replicasMu    sync.Mutex              // guards replicaStubs and replicaHealth
+	replicaStubs  map[string]codegen.Stub // network stubs to replicas, by address
+	replicaHealth *replicaHealth          // polls the health of replicas, if gated
+
+	pollerInit sync.Once     // used to initialize poller
+	poller     *healthPoller // polls the health of the component, if gated
+
 	local register.WriteOnce[bool] // routed locally?
 	load  *loadCollector           // non-nil for routed components
 }
@@ -152,8 +194,17 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 		return nil, err
 	}
 
-	// Make internal listener.
-	lis, err := net.Listen("tcp", args.InternalAddress)
+	// Make internal listener, with the transport named by the internal
+	// address, if any.
+	network, address := "tcp", args.InternalAddress
+	if n, a, ok := strings.Cut(address, "://"); ok {
+		network, address = n, a
+	}
+	t, ok := transport.Lookup(network)
+	if !ok {
+		return nil, fmt.Errorf("internal address %q: unknown transport %q; maybe you forgot to import the package that registers it", args.InternalAddress, network)
+	}
+	lis, err := t.Listen(address)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +217,7 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 	}()
 
 	// Pre-construct reply for InitWeavelet call.
-	dialAddr := fmt.Sprintf("tcp://%s", lis.Addr().String())
+	dialAddr := fmt.Sprintf("%s://%s", network, lis.Addr().String())
 	if args.Mtls {
 		dialAddr = fmt.Sprintf("mtls://%s", dialAddr)
 	}
@@ -187,6 +238,7 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 		componentsByImpl: map[reflect.Type]*component{},
 		redirects:        map[string]redirect{},
 		listeners:        map[string]*listener{},
+		workers:          newWorkerGroup(ctx),
 	}
 
 	info := bootstrap.Args
@@ -233,7 +285,7 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 
 		// Initialize the resolver and balancer.
 		c.resolver = newRoutingResolver()
-		c.balancer = newRoutingBalancer(c.clientTLS)
+		c.balancer = newRoutingBalancer(reg.Name, c.clientTLS, reg.RouterFn)
 	}
 
 	// Process all redirects.
@@ -273,13 +325,37 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 		// Ready to serve
 	}
 
+	// Export Go runtime metrics, if enabled.
+	if w.runtimeEvery > 0 {
+		servers.Go(func() error {
+			var collector runtimeCollector
+			collector.run(ctx, w.runtimeEvery, w.readyComponents)
+			return nil
+		})
+	}
+
+	// Serve metrics to Prometheus, if enabled.
+	if w.promAddr != "" {
+		servers.Go(func() error {
+			// Failing to serve metrics, e.g., because another weavelet on the
+			// same machine already listens on the address, is logged rather
+			// than returned, so that it doesn't stop the weavelet.
+			if err := servePrometheus(ctx, w.promAddr, w.syslogger); err != nil {
+				w.syslogger.Error("Prometheus endpoint stopped", "err", err)
+			}
+			return nil
+		})
+	}
+
 	// Serve RPC requests from other weavelets.
 	cleanupListener = false // handing listener to server
 	servers.Go(func() error {
 		server := &server{Listener: lis, wlet: w}
 		opts := call.ServerOptions{
-			Logger: w.syslogger,
-			Tracer: w.tracer,
+			Logger:               w.syslogger,
+			Tracer:               w.tracer,
+			MaxConcurrentCalls:   w.maxConcurrentCalls,
+			MaxPendingReplyBytes: w.maxPendingReplyBytes,
 		}
 		if err := call.Serve(w.ctx, server, opts); err != nil {
 			w.syslogger.Error("RPC server failed", "err", err)
@@ -294,6 +370,21 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 	signal.Notify(done, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-done
+
+		// Stop accepting new calls and let the calls in flight finish.
+		drainCtx, cancel := context.WithTimeout(ctx, w.drainTimeout)
+		if err := w.drainer.drain(drainCtx); err != nil {
+			w.syslogger.Error("Draining in-flight calls failed", "err", err)
+		}
+		cancel()
+
+		// Stop the background workers.
+		stopCtx, cancel := context.WithTimeout(ctx, w.drainTimeout)
+		if err := w.workers.stop(stopCtx); err != nil {
+			w.syslogger.Error("Stopping background workers failed", "err", err)
+		}
+		cancel()
+
 		for _, c := range w.componentsByName {
 			if !c.implReady.Load() {
 				continue
@@ -313,11 +404,158 @@ func NewRemoteWeavelet(ctx context.Context, regs []*codegen.Registration, bootst
 }
 
 // InitWeavelet implements weaver.controller and conn.WeaverHandler interfaces.
+//
+// Only the first call initializes the weavelet. Every call updates the log
+// levels of components from req.Sections, so a deployer can change log levels
+// without restarting the weavelet. Later calls also reconfigure the components
+// that have a Reconfigure method and whose config sections have changed.
 func (w *RemoteWeavelet) InitWeavelet(ctx context.Context, req *protos.InitWeaveletRequest) (*protos.InitWeaveletReply, error) {
 	w.initMu.Lock()
 	defer w.initMu.Unlock()
-	if !w.initCalled {
+	if err := w.logLevels.update(req.Sections); err != nil {
+		return nil, err
+	}
+	if w.initCalled {
+		// Deliver config updates to the components that accept them.
+		if err := w.reconfig.update(req.Sections); err != nil {
+			return nil, err
+		}
+	} else {
+		readOnly, err := parseReadOnlyConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		accessLogRate, err := parseAccessLogConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		compression, err := parseCompressionConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		payloadLimits, err := parsePayloadLimitsConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		hedging, err := parseHedgingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		caching, err := parseCachingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		callDeadlines, err := parseCallDeadlinesConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		bulkheads, err := parseBulkheadsConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		healthGating, err := parseHealthGatingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		healthPolling, err := parseHealthPollingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		drainTimeout, err := parseDrainingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		shedLimits, err := parseLoadSheddingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		rateLimits, err := parseRateLimitsConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		for name, limits := range rateLimits {
+			if c, ok := w.componentsByName[name]; ok {
+				if err := checkRateLimits(c.reg, limits); err != nil {
+					return nil, err
+				}
+			}
+		}
+		fairSchedulers, err := parseFairSchedulingConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		adaptiveConcurrency, err := parseAdaptiveConcurrencyConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		handlerPools, err := parseHandlerPoolsConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		routingFallback, err := parseRoutingFallbackConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		outlier, err := parseOutlierConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		maxPendingReplyBytes, err := parseBackpressureConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		maxConcurrentCalls, err := parseAdmissionConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		if err := parseMethodMetricsConfig(req.Sections); err != nil {
+			return nil, err
+		}
+		runtimeEvery, err := parseRuntimeMetricsConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		promAddr, err := parsePrometheusConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
+		grpcServers, err := parseGRPCConfig(req.Sections)
+		if err != nil {
+			return nil, err
+		}
 		w.sectionConfig = req.Sections
+		w.readOnly = readOnly
+		w.accessLogRate = accessLogRate
+		w.compression = compression
+		w.payloadLimits = payloadLimits
+		w.hedging = hedging
+		w.caching = caching
+		w.callDeadlines = callDeadlines
+		w.bulkheads = bulkheads
+		w.healthGating = healthGating
+		w.healthPolling = healthPolling
+		w.drainTimeout = drainTimeout
+		w.shedders = map[string]*shedder{}
+		for name, limit := range shedLimits {
+			w.shedders[name] = newShedder(limit)
+		}
+		w.rateLimiters = map[string]*rateLimiter{}
+		for name, limits := range rateLimits {
+			w.rateLimiters[name] = newRateLimiter(name, limits)
+		}
+		w.fairSchedulers = fairSchedulers
+		w.adaptiveConcurrency = adaptiveConcurrency
+		w.handlerPools = map[string]*handlerPool{}
+		for name, opts := range handlerPools {
+			w.handlerPools[name] = newHandlerPool(w.ctx, name, opts.Workers, opts.Queue)
+		}
+		w.routingFallback = routingFallback
+		w.outlier = outlier
+		w.maxPendingReplyBytes = maxPendingReplyBytes
+		w.maxConcurrentCalls = maxConcurrentCalls
+		w.runtimeEvery = runtimeEvery
+		w.promAddr = promAddr
+		w.grpcServers = grpcServers
 		w.initCalled = true
 		close(w.initDone)
 	}
@@ -354,6 +592,9 @@ func (w *RemoteWeavelet) getIntf(t reflect.Type, requester string) (any, error)
 	if r, ok := w.redirects[c.reg.Name]; ok {
 		return w.redirect(requester, c, r.target, r.address)
 	}
+	if endpoint, ok := w.grpcServers[c.reg.Name]; ok {
+		return w.grpcClient(requester, c, endpoint)
+	}
 
 	// Activate the component.
 	c.activateInit.Do(func() {
@@ -386,7 +627,17 @@ func (w *RemoteWeavelet) getIntf(t reflect.Type, requester string) (any, error)
 		if err != nil {
 			return nil, err
 		}
-		return c.reg.LocalStubFn(impl, requester, w.tracer), nil
+		stub := c.reg.LocalStubFn(impl, requester, w.tracer)
+		if w.readOnly[c.reg.Name] {
+			stub = readOnlyStub(c.reg, stub)
+		}
+		if limiter, ok := w.rateLimiters[c.reg.Name]; ok {
+			stub = localRateLimitedStub(c.reg, stub, limiter, requester)
+		}
+		if b, ok := w.bulkheads[requester][c.reg.Name]; ok {
+			stub = localBulkheadStub(c.reg, stub, b)
+		}
+		return boundLocalCalls(w.callDeadlines, requester, c.reg, stub)
 	}
 
 	// Return a remote stub.
@@ -394,9 +645,111 @@ func (w *RemoteWeavelet) getIntf(t reflect.Type, requester string) (any, error)
 	if err != nil {
 		return nil, err
 	}
+	if _, ok := w.healthGating[requester][c.reg.Name]; ok {
+		if stub, err = w.gateOnHealth(c, stub); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := w.rateLimiters[c.reg.Name]; ok {
+		stub = &callerStub{Stub: stub, caller: requester}
+	}
+	if b, ok := w.bulkheads[requester][c.reg.Name]; ok {
+		stub = &bulkheadStub{Stub: stub, bulkhead: b}
+	}
+	if stub, err = boundCalls(w.callDeadlines, requester, c.reg, stub); err != nil {
+		return nil, err
+	}
 	return c.reg.ClientStubFn(stub, requester), nil
 }
 
+// getReplicas returns a component interface for every available replica of
+// the component with the provided interface type. A call through a returned
+// interface is always sent to the same replica. If the requester gates its
+// calls to the component on the component's health, replicas that recently
+// reported that they are unhealthy are left out. If the component is local,
+// redirected, or served by an external gRPC server, getReplicas returns the
+// single interface returned by getIntf.
+func (w *RemoteWeavelet) getReplicas(t reflect.Type, requester string) ([]any, error) {
+	// Note that getIntf activates the component and waits until it is ready.
+	intf, err := w.getIntf(t, requester)
+	if err != nil {
+		return nil, err
+	}
+	c := w.componentsByIntf[t]
+	_, redirected := w.redirects[c.reg.Name]
+	_, external := w.grpcServers[c.reg.Name]
+	if redirected || external || c.local.Read() {
+		return []any{intf}, nil
+	}
+
+	c.resolver.m.Lock()
+	endpoints := c.resolver.endpoints
+	c.resolver.m.Unlock()
+
+	c.replicasMu.Lock()
+	defer c.replicasMu.Unlock()
+	stubs := make(map[string]codegen.Stub, len(endpoints))
+	for _, endpoint := range endpoints {
+		addr := endpoint.Address()
+		stub, ok := c.replicaStubs[addr]
+		if !ok {
+			// Make a stub with a constant resolver pointing at the replica.
+			resolver := call.NewConstantResolver(endpoint)
+			stub, err = w.makeStub(c.reg.Name, c.reg, resolver, nil, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		stubs[addr] = stub
+	}
+
+	// Poll the health of every replica, if the requester is gated on it.
+	// Note that getIntf has already checked that the component reports its
+	// health.
+	_, gated := w.healthGating[requester][c.reg.Name]
+	if gated {
+		if c.replicaHealth == nil {
+			c.replicaHealth = newReplicaHealth(c.reg.Name, w.healthInterval(c.reg.Name))
+		}
+		clients := make(map[string]healthChecker, len(stubs))
+		for addr, stub := range stubs {
+			clients[addr] = c.reg.ClientStubFn(stub, "root").(healthChecker)
+		}
+		c.replicaHealth.update(w.ctx, clients)
+	}
+
+	intfs := make([]any, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		addr := endpoint.Address()
+		if gated && c.replicaHealth.check(addr) != nil {
+			continue
+		}
+		stub := stubs[addr]
+		if _, ok := w.rateLimiters[c.reg.Name]; ok {
+			stub = &callerStub{Stub: stub, caller: requester}
+		}
+		if b, ok := w.bulkheads[requester][c.reg.Name]; ok {
+			stub = &bulkheadStub{Stub: stub, bulkhead: b}
+		}
+		if stub, err = boundCalls(w.callDeadlines, requester, c.reg, stub); err != nil {
+			return nil, err
+		}
+		intfs = append(intfs, c.reg.ClientStubFn(stub, requester))
+	}
+
+	// Close the connections to the replicas that are no longer available.
+	for addr, stub := range c.replicaStubs {
+		if _, ok := stubs[addr]; ok {
+			continue
+		}
+		if closer, ok := stub.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+	c.replicaStubs = stubs
+	return intfs, nil
+}
+
 // redirect creates a component interface for c that redirects calls to the
 // component named by target at address.
 func (w *RemoteWeavelet) redirect(requester string, c *component, target, address string) (any, error) {
@@ -421,6 +774,66 @@ func (w *RemoteWeavelet) redirect(requester string, c *component, target, addres
 	return c.reg.ClientStubFn(c.stub, requester), nil
 }
 
+// grpcClient creates a component interface for c that sends calls to the
+// provided external gRPC server.
+func (w *RemoteWeavelet) grpcClient(requester string, c *component, endpoint grpcEndpoint) (any, error) {
+	// The component is served outside of the deployment, so we never
+	// activate it.
+	c.activateInit.Do(func() {})
+
+	c.stubInit.Do(func() {
+		c.stub, c.stubErr = newGRPCStub(c.reg, endpoint, w.tracer)
+	})
+	if c.stubErr != nil {
+		return nil, c.stubErr
+	}
+	return c.reg.ClientStubFn(c.stub, requester), nil
+}
+
+// OnHealthChange implements the Weavelet interface.
+func (w *RemoteWeavelet) OnHealthChange(ctx context.Context, name string, f func(old, new HealthStatus)) error {
+	get := func(name string) (any, error) {
+		c, ok := w.componentsByName[name]
+		if !ok {
+			return nil, fmt.Errorf("component %q not found", name)
+		}
+		return w.GetIntf(c.reg.Iface)
+	}
+	select {
+	case <-w.initDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return onHealthChange(ctx, name, get, w.healthPolling, w.workers, f)
+}
+
+// Placement implements the Weavelet interface.
+func (w *RemoteWeavelet) Placement(name string) Placement {
+	c, ok := w.componentsByName[name]
+	if !ok {
+		return PlacementUnknown
+	}
+	if _, ok := w.redirects[name]; ok {
+		return PlacementRemote
+	}
+	select {
+	case <-w.initDone:
+		if _, ok := w.grpcServers[name]; ok {
+			return PlacementRemote
+		}
+	default:
+	}
+	local, ok := c.local.TryRead()
+	switch {
+	case !ok:
+		return PlacementUnknown
+	case local:
+		return PlacementLocal
+	default:
+		return PlacementRemote
+	}
+}
+
 // GetImpl implements the Weavelet interface.
 func (w *RemoteWeavelet) GetImpl(t reflect.Type) (any, error) {
 	c, ok := w.componentsByImpl[t]
@@ -467,10 +880,11 @@ func (w *RemoteWeavelet) createComponent(ctx context.Context, reg *codegen.Regis
 
 	// Fill config if necessary.
 	if cfg := config.Config(v); cfg != nil {
-		if err := runtime.ParseConfigSection(reg.Name, "", w.sectionConfig, cfg); err != nil {
+		if err := runtime.ParseComponentConfigSection(reg.Name, w.sectionConfig, cfg); err != nil {
 			return nil, err
 		}
 	}
+	w.reconfig.prepare(obj)
 
 	// Set logger.
 	if err := SetLogger(obj, w.logger(reg.Name)); err != nil {
@@ -488,6 +902,11 @@ func (w *RemoteWeavelet) createComponent(ctx context.Context, reg *codegen.Regis
 	}); err != nil {
 		return nil, err
 	}
+	if err := FillReplicaRefs(obj, func(t reflect.Type) func() ([]any, error) {
+		return func() ([]any, error) { return w.getReplicas(t, reg.Name) }
+	}); err != nil {
+		return nil, err
+	}
 
 	// Fill listener fields.
 	if err := FillListeners(obj, func(name string) (net.Listener, string, error) {
@@ -506,22 +925,66 @@ func (w *RemoteWeavelet) createComponent(ctx context.Context, reg *codegen.Regis
 			return nil, fmt.Errorf("component %q initialization failed: %w", reg.Name, err)
 		}
 	}
+
+	// Receive config updates, if the component accepts them.
+	if err := w.reconfig.add(reg.Name, obj, w.sectionConfig[reg.Name]); err != nil {
+		w.syslogger.Error("Failed to reconfigure", "component", reg.Name, "err", err)
+	}
+
+	// Start background workers.
+	w.workers.start(reg.Name, obj, w.logger(reg.Name))
 	return obj, nil
 }
 
 // getStub returns a component's client stub, initializing it if necessary.
 func (w *RemoteWeavelet) getStub(c *component) (codegen.Stub, error) {
 	c.stubInit.Do(func() {
+		c.balancer.setFallback(w.routingFallback[c.reg.Name])
 		c.stub, c.stubErr = w.makeStub(c.reg.Name, c.reg, c.resolver, c.balancer, true)
+		if c.stubErr == nil {
+			c.stub = codegen.RecordingStub(c.reg, c.stub)
+		}
 	})
 	return c.stub, c.stubErr
 }
 
+// gateOnHealth returns a stub that fails calls made through stub, a stub to
+// component c, while c reports that it is unhealthy. It starts polling the
+// health of c if needed.
+func (w *RemoteWeavelet) gateOnHealth(c *component, stub codegen.Stub) (codegen.Stub, error) {
+	if err := checkHealthGating(c.reg); err != nil {
+		return nil, err
+	}
+	c.pollerInit.Do(func() {
+		c.poller = newHealthPoller(c.reg.Name, w.healthInterval(c.reg.Name))
+		client := c.reg.ClientStubFn(stub, "root").(healthChecker)
+		go c.poller.run(w.ctx, client)
+	})
+	m, _ := c.reg.Iface.MethodByName("Healthy")
+	return &healthGatedStub{Stub: stub, poller: c.poller, healthy: m.Index}, nil
+}
+
+// healthInterval returns the interval at which to poll the health of the
+// provided component, i.e., the shortest interval configured for it by any
+// caller.
+func (w *RemoteWeavelet) healthInterval(component string) time.Duration {
+	var interval time.Duration
+	for _, intervals := range w.healthGating {
+		if d, ok := intervals[component]; ok && (interval == 0 || d < interval) {
+			interval = d
+		}
+	}
+	return interval
+}
+
 // makeStub makes a new stub with the provided resolver and balancer.
 func (w *RemoteWeavelet) makeStub(fullName string, reg *codegen.Registration, resolver call.Resolver, balancer call.Balancer, wait bool) (codegen.Stub, error) {
 	// Create the client connection.
 	name := logging.ShortenComponent(fullName)
 	w.syslogger.Debug("Connecting to remote", "component", name)
+	if w.outlier != nil && balancer != nil {
+		balancer = call.OutlierDetection(balancer, *w.outlier)
+	}
 	opts := call.ClientOptions{
 		Balancer: balancer,
 		Logger:   w.syslogger,
@@ -538,7 +1001,38 @@ func (w *RemoteWeavelet) makeStub(fullName string, reg *codegen.Registration, re
 		}
 	}
 	w.syslogger.Debug("Connected to remote", "component", name)
-	return call.NewStub(fullName, reg, conn, w.tracer, w.opts.InjectRetries), nil
+	stubOpts := call.StubOptions{
+		InjectRetries: w.opts.InjectRetries,
+		Faults:        w.opts.Faults[fullName],
+	}
+	if opts, ok := w.adaptiveConcurrency[fullName]; ok {
+		stubOpts.Limiter = call.NewLimiter(opts)
+	}
+	if w.accessLogRate > 0 {
+		stubOpts.AccessLogger = w.logger(fullName)
+		stubOpts.AccessLogRate = w.accessLogRate
+	}
+	if c, ok := w.compression[fullName]; ok {
+		stubOpts.Compression = c.codec
+		stubOpts.CompressionThreshold = c.threshold
+	}
+	if l, ok := w.payloadLimits[fullName]; ok {
+		stubOpts.MaxRequestBytes = l.MaxRequest
+		stubOpts.MaxReplyBytes = l.MaxReply
+	}
+	if delays, ok := w.hedging[fullName]; ok {
+		if err := checkHedging(reg, delays); err != nil {
+			return nil, err
+		}
+		stubOpts.Hedging = delays
+	}
+	if ttls, ok := w.caching[fullName]; ok {
+		if err := checkCaching(reg, ttls); err != nil {
+			return nil, err
+		}
+		stubOpts.Caching = ttls
+	}
+	return call.NewStub(fullName, reg, conn, w.tracer, stubOpts), nil
 }
 
 // GetLoad implements controller interface.
@@ -664,6 +1158,18 @@ func (w *RemoteWeavelet) UpdateRoutingInfo(_ context.Context, req *protos.Update
 	return &protos.UpdateRoutingInfoReply{}, nil
 }
 
+// readyComponents returns the names of the components that have been
+// successfully initialized in this weavelet.
+func (w *RemoteWeavelet) readyComponents() []string {
+	var names []string
+	for name, c := range w.componentsByName {
+		if c.implReady.Load() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // GetHealth implements controller.GetHealth.
 func (w *RemoteWeavelet) GetHealth(context.Context, *protos.GetHealthRequest) (*protos.GetHealthReply, error) {
 	// Get the health status for all components. For now, we consider a component
@@ -730,9 +1236,24 @@ func (w *RemoteWeavelet) getComponent(name string) (*component, error) {
 // that (1) creates the local component if it hasn't been created yet and (2)
 // calls m.
 func (w *RemoteWeavelet) addHandlers(handlers *call.HandlerMap, c *component) {
+	readOnlyMethods := readOnlyMethods(c.reg)
+	limits := w.payloadLimits[c.reg.Name]
+	limiter := w.rateLimiters[c.reg.Name]
+	scheduler := w.fairSchedulers[c.reg.Name]
+	pool := w.handlerPools[c.reg.Name]
+	logger := w.logger(c.reg.Name)
 	for i, n := 0, c.reg.Iface.NumMethod(); i < n; i++ {
 		mname := c.reg.Iface.Method(i).Name
+		allowedIfReadOnly := readOnlyMethods[mname]
+		fullMethod := c.reg.Name + "." + mname
 		handler := func(ctx context.Context, args []byte) (res []byte, err error) {
+			// Reject the call if the weavelet is shutting down. The caller
+			// retries it, typically on another replica.
+			if !w.drainer.begin() {
+				return nil, codegen.Retryable(UnavailableError)
+			}
+			defer w.drainer.end()
+
 			// This handler is supposed to invoke the method named mname on the
 			// local component. However, it is possible that the component has
 			// not yet been started. w.GetImpl will start the component if it
@@ -741,10 +1262,69 @@ func (w *RemoteWeavelet) addHandlers(handlers *call.HandlerMap, c *component) {
 			if _, err := w.GetImpl(c.reg.Impl); err != nil {
 				return nil, err
 			}
+			if !allowedIfReadOnly && w.readOnly[c.reg.Name] {
+				// The component is running in read-only mode, and mname is
+				// not marked //weaver:readonly.
+				return nil, ReadOnlyError
+			}
+			if limiter != nil && !limiter.allow(mname, call.CallerFromContext(ctx), clock.Now()) {
+				// The caller exceeded its rate limit. It retries the call,
+				// with backoff.
+				return nil, codegen.Retryable(RateLimitedError)
+			}
+			if s, ok := w.shedders[c.reg.Name]; ok {
+				if !s.acquire() {
+					// The component is overloaded. The server stub reports
+					// the load of the call and rejects it.
+					ctx = codegen.Shed(ctx)
+				} else {
+					defer s.release()
+				}
+			}
+			if err := codegen.CheckPayloadSize("request of "+fullMethod, len(args), limits.MaxRequest); err != nil {
+				return nil, err
+			}
+			if scheduler != nil {
+				// Wait for the caller's share of the component.
+				if err := scheduler.acquire(ctx, call.CallerFromContext(ctx)); err != nil {
+					return nil, err
+				}
+				defer scheduler.release()
+			}
 			fn := c.serverStub.GetStubFn(mname)
-			return fn(ctx, args)
+			if pool != nil {
+				// Run the call on a worker of the component's pool. Note that
+				// the worker sets its own copies of the results, which are
+				// discarded if ctx is done before the call returns.
+				var poolRes []byte
+				var poolErr error
+				if err := pool.run(ctx, func(ctx context.Context) {
+					poolRes, poolErr = fn(ctx, args)
+				}); err != nil {
+					return nil, err
+				}
+				res, err = poolRes, poolErr
+			} else {
+				res, err = fn(ctx, args)
+			}
+			if stack := codegen.PanicStack(err); stack != nil {
+				// Log the stack of the panic, but don't send it to the caller.
+				logger.Error("Recovered from panic", "method", fullMethod, "err", err, "stack", string(stack))
+				return nil, fmt.Errorf("panic in %s: %w", fullMethod, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Don't send oversized replies, which the caller would drop.
+			if err := codegen.CheckPayloadSize("reply of "+fullMethod, len(res), limits.MaxReply); err != nil {
+				return nil, err
+			}
+			return res, nil
 		}
 		handlers.Set(c.reg.Name, mname, handler)
+		// Bound the decompression of requests by the same limit, so that
+		// a small compressed request can't expand past it.
+		handlers.SetMaxRequestBytes(c.reg.Name, mname, limits.MaxRequest)
 	}
 
 	// Add the special "component is ready" method handler, which is used by
@@ -798,6 +1378,7 @@ func (w *RemoteWeavelet) logger(name string, attrs ...string) *slog.Logger {
 			Attrs:      attrs,
 		},
 		Write: w.logDst.log,
+		Level: w.logLevels.get(name),
 	})
 }
 
@@ -18,34 +18,46 @@ import (
 	"context"
 	"crypto/tls"
 	"math/rand"
+	"sort"
 	"sync"
 
 	"github.com/ServiceWeaver/weaver/internal/cond"
 	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"github.com/ServiceWeaver/weaver/runtime/protos"
 	"github.com/google/uuid"
 )
 
 // routingBalancer balances requests according to a routing assignment.
 type routingBalancer struct {
-	balancer  call.Balancer // balancer to use for non-routed calls
-	tlsConfig *tls.Config   // tls config to use; may be nil.
+	component string           // full component name
+	balancer  call.Balancer    // balancer to use for non-routed calls
+	tlsConfig *tls.Config      // tls config to use; may be nil.
+	routerFn  codegen.RouterFn // routing override; may be nil.
 
 	mu         sync.RWMutex
 	assignment *protos.Assignment
 	index      index
+	fallback   bool // reroute calls whose replicas are all unavailable?
 
 	// Map from address to connection. We currently allow just one
 	// connection per address.
 	// Guarded by mu.
 	conns map[string]call.ReplicaConnection
+
+	// The sorted addresses of conns, passed to routerFn. The slice is
+	// replaced, never modified, when conns changes.
+	// Guarded by mu.
+	replicas []string
 }
 
 // newRoutingBalancer returns a new routingBalancer.
-func newRoutingBalancer(tlsConfig *tls.Config) *routingBalancer {
+func newRoutingBalancer(component string, tlsConfig *tls.Config, routerFn codegen.RouterFn) *routingBalancer {
 	return &routingBalancer{
+		component: component,
 		balancer:  call.RoundRobin(),
 		tlsConfig: tlsConfig,
+		routerFn:  routerFn,
 		conns:     map[string]call.ReplicaConnection{},
 	}
 }
@@ -57,6 +69,7 @@ func (rb *routingBalancer) Add(c call.ReplicaConnection) {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
 	rb.conns[c.Address()] = c
+	rb.sortReplicasLocked()
 }
 
 // Remove removes c from the set of connections we are balancing across.
@@ -66,6 +79,22 @@ func (rb *routingBalancer) Remove(c call.ReplicaConnection) {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
 	delete(rb.conns, c.Address())
+	rb.sortReplicasLocked()
+}
+
+// sortReplicasLocked recomputes rb.replicas from rb.conns.
+//
+// REQUIRES: rb.mu is held.
+func (rb *routingBalancer) sortReplicasLocked() {
+	if rb.routerFn == nil {
+		return
+	}
+	replicas := make([]string, 0, len(rb.conns))
+	for addr := range rb.conns {
+		replicas = append(replicas, addr)
+	}
+	sort.Strings(replicas)
+	rb.replicas = replicas
 }
 
 // update updates the balancer with the provided assignment
@@ -81,6 +110,14 @@ func (rb *routingBalancer) update(assignment *protos.Assignment) {
 	rb.index = index
 }
 
+// setFallback sets whether calls whose assigned replicas are all unavailable
+// are rerouted to other replicas.
+func (rb *routingBalancer) setFallback(fallback bool) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.fallback = fallback
+}
+
 // Pick implements the call.Balancer interface.
 func (rb *routingBalancer) Pick(opts call.CallOptions) (call.ReplicaConnection, bool) {
 	if opts.ShardKey == 0 {
@@ -89,6 +126,13 @@ func (rb *routingBalancer) Pick(opts call.CallOptions) (call.ReplicaConnection,
 		return rb.balancer.Pick(opts)
 	}
 
+	if rb.routerFn != nil {
+		// The routing function, if any, overrides the assignment.
+		if c, ok := rb.pickRouted(opts.ShardKey); ok {
+			return c, true
+		}
+	}
+
 	// Grab the current assignment. It's possible that the current assignment
 	// changes between when we release the lock and when we pick an endpoint,
 	// but using a slightly stale assignment is okay.
@@ -103,12 +147,13 @@ func (rb *routingBalancer) Pick(opts call.CallOptions) (call.ReplicaConnection,
 		return rb.balancer.Pick(opts)
 	}
 
-	slice, ok := index.find(opts.ShardKey)
+	i, ok := index.position(opts.ShardKey)
 	if !ok {
 		// TODO(mwhittaker): Shouldn't this be impossible. Understand better
 		// when this happens.
 		return rb.balancer.Pick(opts)
 	}
+	slice := index[i]
 
 	// Search for an available ReplicConnection starting at a random offset.
 	// TODO(sanjay):Precompute the set of available ReplicaConnections per slice.
@@ -124,9 +169,60 @@ func (rb *routingBalancer) Pick(opts call.CallOptions) (call.ReplicaConnection,
 			return c, true
 		}
 	}
+	if rb.fallback {
+		return rb.rerouteLocked(index, i)
+	}
 	return nil, false
 }
 
+// rerouteLocked picks the first available replica of the slices that follow
+// index[i] in the ring of slices, skipping the replicas of index[i] itself. It
+// returns false if there is none.
+//
+// Replicas are removed from the balancer only when the connection to them
+// breaks, so calls are rerouted only when a replica is unreachable, never
+// because a call to it returned an application error.
+//
+// REQUIRES: rb.mu is held.
+func (rb *routingBalancer) rerouteLocked(index index, i int) (call.ReplicaConnection, bool) {
+	for j := 1; j < len(index); j++ {
+		next := index[(i+j)%len(index)]
+		for _, replica := range next.replicas {
+			if index[i].replicaSet[replica] {
+				continue
+			}
+			if c, ok := rb.conns[replica]; ok {
+				rerouted.Get(reroutedLabels{Component: rb.component}).Inc()
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// pickRouted picks the connection to the replica chosen by the routing
+// function for the provided shard key. It returns false if the routing
+// function doesn't pick an available replica.
+func (rb *routingBalancer) pickRouted(shardKey uint64) (call.ReplicaConnection, bool) {
+	rb.mu.RLock()
+	replicas := rb.replicas
+	rb.mu.RUnlock()
+	if len(replicas) == 0 {
+		return nil, false
+	}
+
+	// Note that we don't hold the lock while calling the user-provided
+	// routing function.
+	addr := rb.routerFn(shardKey, replicas)
+	if addr == "" {
+		return nil, false
+	}
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+	c, ok := rb.conns[addr]
+	return c, ok
+}
+
 // routingResolver is a dummy resolver that returns whatever endpoints are
 // passed to the update method.
 type routingResolver struct {
@@ -21,7 +21,9 @@ import (
 	"testing"
 	"time"
 
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
 	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
 	"github.com/ServiceWeaver/weaver/runtime/protos"
 	"github.com/google/go-cmp/cmp"
 )
@@ -107,6 +109,149 @@ func TestRoutingBalancer(t *testing.T) {
 	}
 }
 
+// TestRoutingBalancerRouterFn tests that a routingBalancer with a routing
+// function picks endpoints using the routing function, falling back to its
+// assignment when the routing function doesn't pick an available replica.
+func TestRoutingBalancerRouterFn(t *testing.T) {
+	b := call.BalancerFunc(func([]call.ReplicaConnection, call.CallOptions) (call.ReplicaConnection, bool) {
+		t.Fatal("default balancer called")
+		return nil, false
+	})
+	wantReplicas := []string{"a", "b", "c"}
+	routerFn := func(shardKey uint64, replicas []string) string {
+		if !cmp.Equal(replicas, wantReplicas) {
+			t.Errorf("replicas: got %v, want %v", replicas, wantReplicas)
+		}
+		switch shardKey {
+		case 1:
+			return "c"
+		case 2:
+			return "unknown"
+		default:
+			return ""
+		}
+	}
+	rb := routingBalancer{balancer: b, routerFn: routerFn, conns: map[string]call.ReplicaConnection{}}
+	rb.update(&protos.Assignment{
+		Slices: []*protos.Assignment_Slice{{Start: 0, Replicas: []string{"a"}}},
+	})
+	rb.Add(fakeConn("c"))
+	rb.Add(fakeConn("a"))
+	rb.Add(fakeConn("b"))
+
+	for _, test := range []struct {
+		shardKey uint64
+		want     string
+	}{
+		{1, "c"}, // routed by routerFn
+		{2, "a"}, // unknown replica; routed by assignment
+		{3, "a"}, // no replica; routed by assignment
+	} {
+		t.Run(fmt.Sprint(test.shardKey), func(t *testing.T) {
+			got, ok := rb.Pick(call.CallOptions{ShardKey: test.shardKey})
+			if !ok {
+				t.Fatal("did not find replica")
+			}
+			if got.Address() != test.want {
+				t.Fatalf("rb.Pick(%d): got %s, want %s", test.shardKey, got.Address(), test.want)
+			}
+		})
+	}
+
+	// Removing a replica updates the replicas passed to the routing function.
+	rb.Remove(fakeConn("b"))
+	wantReplicas = []string{"a", "c"}
+	if got, ok := rb.Pick(call.CallOptions{ShardKey: 1}); !ok || got.Address() != "c" {
+		t.Fatalf("rb.Pick(1) after Remove: got (%v, %t), want (c, true)", got, ok)
+	}
+}
+
+// reroutedValue returns the value of the rerouted calls metric of the provided
+// component.
+func reroutedValue(component string) float64 {
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == imetrics.RoutingReroutedName && snap.Labels["component"] == component {
+			return snap.Value
+		}
+	}
+	return 0
+}
+
+// TestRoutingBalancerFallback tests that a routingBalancer with fallback
+// enabled reroutes a call whose assigned replicas are all unavailable to the
+// next available replica in the ring of slices.
+func TestRoutingBalancerFallback(t *testing.T) {
+	const component = "TestRoutingBalancerFallback/Cache"
+	b := call.BalancerFunc(func([]call.ReplicaConnection, call.CallOptions) (call.ReplicaConnection, bool) {
+		t.Fatal("default balancer called")
+		return nil, false
+	})
+	rb := routingBalancer{component: component, balancer: b, conns: map[string]call.ReplicaConnection{}}
+	rb.update(&protos.Assignment{
+		Slices: []*protos.Assignment_Slice{
+			{Start: 0, Replicas: []string{"a"}},
+			{Start: 100, Replicas: []string{"a", "b"}},
+			{Start: 200, Replicas: []string{"c"}},
+		},
+	})
+	rb.Add(fakeConn("b"))
+	rb.Add(fakeConn("c"))
+
+	// Without fallback, a call whose replicas are unavailable finds none.
+	if got, ok := rb.Pick(call.CallOptions{ShardKey: 20}); ok {
+		t.Fatalf("rb.Pick(20) unexpectedly returned %s", got.Address())
+	}
+
+	rb.setFallback(true)
+	for _, test := range []struct {
+		shardKey uint64
+		want     string
+		rerouted bool
+	}{
+		{20, "b", true},   // "a" is unavailable; rerouted to the next slice
+		{120, "b", false}, // "b" is available
+		{220, "c", false}, // "c" is available
+	} {
+		before := reroutedValue(component)
+		got, ok := rb.Pick(call.CallOptions{ShardKey: test.shardKey})
+		if !ok {
+			t.Fatalf("rb.Pick(%d): did not find replica", test.shardKey)
+		}
+		if got.Address() != test.want {
+			t.Errorf("rb.Pick(%d): got %s, want %s", test.shardKey, got.Address(), test.want)
+		}
+		want := 0.0
+		if test.rerouted {
+			want = 1
+		}
+		if got := reroutedValue(component) - before; got != want {
+			t.Errorf("rb.Pick(%d): rerouted calls: got %v, want %v", test.shardKey, got, want)
+		}
+	}
+
+	// The ring wraps around, and a call fails if no replica is available.
+	rb.Remove(fakeConn("b"))
+	if got, ok := rb.Pick(call.CallOptions{ShardKey: 120}); !ok || got.Address() != "c" {
+		t.Errorf("rb.Pick(120): got %v, %v, want c, true", got, ok)
+	}
+	rb.Remove(fakeConn("c"))
+	if got, ok := rb.Pick(call.CallOptions{ShardKey: 220}); ok {
+		t.Errorf("rb.Pick(220) unexpectedly returned %s", got.Address())
+	}
+}
+
+func TestParseRoutingFallbackConfig(t *testing.T) {
+	const name = "github.com/example/cache/Cache"
+	sections := map[string]string{shortRoutingFallbackKey: `components = ["` + name + `"]`}
+	got, err := parseRoutingFallbackConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]bool{name: true}, got); diff != "" {
+		t.Errorf("bad config (-want +got):\n%s", diff)
+	}
+}
+
 // TestRoutingResolverInitialResolve tests that the first Resolve invocation on
 // a routingResolver returns a nil set of endpoints but a non-nil version.
 func TestRoutingResolverInitialResolve(t *testing.T) {
@@ -0,0 +1,155 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+	swruntime "github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures Go runtime
+	// metrics.
+	runtimeMetricsKey      = "github.com/ServiceWeaver/weaver/runtime_metrics"
+	shortRuntimeMetricsKey = "runtime_metrics"
+
+	// defaultRuntimeMetricsInterval is the default interval at which Go
+	// runtime metrics are collected.
+	defaultRuntimeMetricsInterval = 10 * time.Second
+)
+
+var (
+	runtimeGoroutines = metrics.NewGaugeMap[runtimeLabels](
+		imetrics.RuntimeGoroutinesName,
+		"Number of goroutines in the process hosting a Service Weaver component",
+	)
+	runtimeHeapBytes = metrics.NewGaugeMap[runtimeLabels](
+		imetrics.RuntimeHeapBytesName,
+		"Bytes of allocated heap objects in the process hosting a Service Weaver component",
+	)
+	runtimeGCCount = metrics.NewCounterMap[runtimeLabels](
+		imetrics.RuntimeGCCountName,
+		"Count of completed garbage collections in the process hosting a Service Weaver component",
+	)
+	runtimeGCPause = metrics.NewCounterMap[runtimeLabels](
+		imetrics.RuntimeGCPauseName,
+		"Total duration, in microseconds, of garbage collection pauses in the process hosting a Service Weaver component",
+	)
+)
+
+type runtimeLabels struct {
+	Component string // full component name
+	Generated bool   `weaver:"serviceweaver_generated"` // Is this an autogenerated metric?
+}
+
+// runtimeMetricsConfig is the "[runtime_metrics]" section of a config file.
+// If present, every process periodically exports Go runtime metrics
+// (goroutines, heap size, and garbage collection pauses), labeled with the
+// name of every component it hosts. For example:
+//
+//	[runtime_metrics]
+//	interval = "5s"
+//
+// Components that share a process report the same values.
+type runtimeMetricsConfig struct {
+	// Interval is how often metrics are collected, e.g., "5s". Defaults to
+	// 10 seconds.
+	Interval string
+}
+
+// parseRuntimeMetricsConfig parses the runtime metrics section of the
+// provided config sections. It returns the collection interval, or zero if
+// runtime metrics are not enabled.
+func parseRuntimeMetricsConfig(sections map[string]string) (time.Duration, error) {
+	if _, ok := sections[runtimeMetricsKey]; !ok {
+		if _, ok := sections[shortRuntimeMetricsKey]; !ok {
+			return 0, nil
+		}
+	}
+	var config runtimeMetricsConfig
+	if err := swruntime.ParseConfigSection(runtimeMetricsKey, shortRuntimeMetricsKey, sections, &config); err != nil {
+		return 0, fmt.Errorf("parse runtime metrics config: %w", err)
+	}
+	if config.Interval == "" {
+		return defaultRuntimeMetricsInterval, nil
+	}
+	// Validate has already checked that the interval parses.
+	interval, _ := time.ParseDuration(config.Interval)
+	return interval, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *runtimeMetricsConfig) Validate() error {
+	if c.Interval == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid interval %q: %w", c.Interval, err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("non-positive interval %v", interval)
+	}
+	return nil
+}
+
+// runtimeCollector exports Go runtime metrics for the components hosted by
+// the current process.
+type runtimeCollector struct {
+	mu      sync.Mutex // guards the following fields
+	numGC   uint32     // number of GCs at the previous collection
+	pauseNs uint64     // total GC pause, in nanoseconds, at the previous collection
+}
+
+// collect updates the runtime metrics of the provided components.
+func (c *runtimeCollector) collect(components []string) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	goroutines := runtime.NumGoroutine()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	numGC, pauseNs := stats.NumGC-c.numGC, stats.PauseTotalNs-c.pauseNs
+	c.numGC, c.pauseNs = stats.NumGC, stats.PauseTotalNs
+	for _, component := range components {
+		labels := runtimeLabels{Component: component, Generated: true}
+		runtimeGoroutines.Get(labels).Set(float64(goroutines))
+		runtimeHeapBytes.Get(labels).Set(float64(stats.HeapAlloc))
+		runtimeGCCount.Get(labels).Add(float64(numGC))
+		runtimeGCPause.Get(labels).Add(float64(pauseNs) / 1000)
+	}
+}
+
+// run calls collect every interval until ctx is cancelled. components returns
+// the names of the components hosted by the current process.
+func (c *runtimeCollector) run(ctx context.Context, interval time.Duration, components func() []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.collect(components())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+)
+
+// TestRuntimeMetrics tests that runtimeCollector registers and populates Go
+// runtime metrics labeled with the provided component names.
+func TestRuntimeMetrics(t *testing.T) {
+	var collector runtimeCollector
+	collector.collect([]string{"a", "b"})
+	runtime.GC()
+	collector.collect([]string{"a", "b"})
+
+	// Index the runtime metrics of component "a" by name.
+	got := map[string]float64{}
+	for _, snap := range metrics.Snapshot() {
+		if snap.Labels["component"] == "a" && snap.Labels["serviceweaver_generated"] == "true" {
+			got[snap.Name] = snap.Value
+		}
+	}
+	for _, name := range []string{
+		imetrics.RuntimeGoroutinesName,
+		imetrics.RuntimeHeapBytesName,
+		imetrics.RuntimeGCCountName,
+	} {
+		value, ok := got[name]
+		if !ok {
+			t.Errorf("metric %s not registered", name)
+			continue
+		}
+		if value <= 0 {
+			t.Errorf("metric %s: got %v, want > 0", name, value)
+		}
+	}
+	if _, ok := got[imetrics.RuntimeGCPauseName]; !ok {
+		t.Errorf("metric %s not registered", imetrics.RuntimeGCPauseName)
+	}
+}
+
+func TestParseRuntimeMetricsConfig(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		sections map[string]string
+		want     time.Duration
+	}{
+		{"disabled", map[string]string{}, 0},
+		{"default", map[string]string{shortRuntimeMetricsKey: ""}, defaultRuntimeMetricsInterval},
+		{"interval", map[string]string{runtimeMetricsKey: `interval = "5s"`}, 5 * time.Second},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseRuntimeMetricsConfig(test.sections)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	for _, interval := range []string{`"soon"`, `"-1s"`} {
+		sections := map[string]string{shortRuntimeMetricsKey: "interval = " + interval}
+		if _, err := parseRuntimeMetricsConfig(sections); err == nil {
+			t.Errorf("interval %s: unexpected success", interval)
+		}
+	}
+}
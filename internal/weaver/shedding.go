@@ -0,0 +1,92 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ServiceWeaver/weaver/runtime"
+)
+
+const (
+	// Key and short key of the config section that configures load
+	// shedding.
+	loadSheddingKey      = "github.com/ServiceWeaver/weaver/load_shedding"
+	shortLoadSheddingKey = "load_shedding"
+)
+
+// loadSheddingConfig is the "[load_shedding]" section of a config file. It
+// maps full component names to the maximum number of calls from other
+// processes that a replica of the component runs at once. Calls beyond the
+// limit are rejected with a retryable weaver.OverloadedError, and the caller
+// retries them, typically on another replica. For example:
+//
+//	[load_shedding]
+//	"github.com/example/catalog/Catalog" = 100
+type loadSheddingConfig map[string]int
+
+// parseLoadSheddingConfig parses the load shedding section of the provided
+// config sections and returns the limit on in-flight calls of every
+// configured component, keyed by full component name.
+func parseLoadSheddingConfig(sections map[string]string) (map[string]int, error) {
+	var config loadSheddingConfig
+	if err := runtime.ParseConfigSection(loadSheddingKey, shortLoadSheddingKey, sections, &config); err != nil {
+		return nil, fmt.Errorf("parse load shedding config: %w", err)
+	}
+	return config, nil
+}
+
+// Validate implements the runtime.ParseConfigSection validation hook.
+func (c *loadSheddingConfig) Validate() error {
+	for name, limit := range *c {
+		if limit <= 0 {
+			return fmt.Errorf("component %q: non-positive limit %d", name, limit)
+		}
+	}
+	return nil
+}
+
+// A shedder bounds the number of calls in flight on a component. Unlike the
+// admission of call.ServerOptions.MaxConcurrentCalls, which queues the calls
+// beyond the limit, a shedder rejects them.
+//
+// A shedder is safe for concurrent use.
+type shedder struct {
+	limit    int64        // maximum number of calls in flight
+	inflight atomic.Int64 // number of calls in flight
+}
+
+// newShedder returns a shedder that admits at most limit calls at once.
+func newShedder(limit int) *shedder {
+	return &shedder{limit: int64(limit)}
+}
+
+// acquire records the start of a call. It returns false, and the call should
+// be shed, if the component already has the maximum number of calls in
+// flight. Every call to acquire that returns true must be followed by a call
+// to release.
+func (s *shedder) acquire() bool {
+	if s.inflight.Add(1) > s.limit {
+		s.inflight.Add(-1)
+		return false
+	}
+	return true
+}
+
+// release records the end of a call.
+func (s *shedder) release() {
+	s.inflight.Add(-1)
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShedder(t *testing.T) {
+	s := newShedder(2)
+	if !s.acquire() || !s.acquire() {
+		t.Fatal("calls under the limit shed")
+	}
+	if s.acquire() {
+		t.Fatal("call over the limit not shed")
+	}
+	s.release()
+	if !s.acquire() {
+		t.Fatal("call under the limit shed after release")
+	}
+}
+
+func TestParseLoadSheddingConfig(t *testing.T) {
+	const name = "github.com/example/catalog/Catalog"
+	sections := map[string]string{shortLoadSheddingKey: `"` + name + `" = 100`}
+	got, err := parseLoadSheddingConfig(sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]int{name: 100}, got); diff != "" {
+		t.Fatalf("bad limits (-want +got):\n%s", diff)
+	}
+
+	sections = map[string]string{shortLoadSheddingKey: `"` + name + `" = 0`}
+	if _, err := parseLoadSheddingConfig(sections); err == nil {
+		t.Fatal("unexpected success for non-positive limit")
+	}
+}
@@ -15,7 +15,9 @@
 package weaver
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -28,6 +30,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	"github.com/ServiceWeaver/weaver/internal/config"
 	"github.com/ServiceWeaver/weaver/internal/env"
 	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
@@ -53,6 +56,12 @@ type SingleWeaveletOptions struct {
 	Config         string               // TOML config contents
 	Fakes          map[reflect.Type]any // component fakes, by component interface type
 	Quiet          bool                 // if true, do not print or log anything
+
+	// If true, calls between components are serialized, in memory, as if
+	// they were remote calls. Tests use this to exercise the encoding and
+	// decoding of method arguments and results without starting a network
+	// server.
+	Serialize bool
 }
 
 // SingleWeavelet is a weavelet that runs all components locally in a single
@@ -75,14 +84,31 @@ type SingleWeavelet struct {
 	createdAt    time.Time             // time at which the weavelet was created
 
 	// Logging, tracing, and metrics.
-	pp     *logging.PrettyPrinter   // pretty printer for logger
-	tracer trace.Tracer             // tracer used by all components
-	stats  *imetrics.StatsProcessor // metrics aggregator
+	pp        *logging.PrettyPrinter   // pretty printer for logger
+	logLevels logLevels                // log levels of components
+	reconfig  reconfigurer             // delivers config updates to components
+	tracer    trace.Tracer             // tracer used by all components
+	stats     *imetrics.StatsProcessor // metrics aggregator
+
+	// Components served by external gRPC servers, by name.
+	grpcServers map[string]grpcEndpoint
+
+	// Components running in read-only mode, by name.
+	readOnly map[string]bool
+
+	// Background workers of components, the health polling of
+	// OnHealthChange, and how long to wait for the workers to stop when
+	// shutting down.
+	workers       *workerGroup
+	healthPolling healthPolling
+	drainTimeout  time.Duration
 
 	// Components and listeners.
 	mu         sync.Mutex              // guards the following fields
 	components map[string]any          // components, by name
 	listeners  map[string]net.Listener // listeners, by name
+	grpcStubs  map[string]*grpcStub    // stubs of grpcServers, by name
+	serialized map[string]codegen.Stub // serializing stubs, by name; see opts.Serialize
 }
 
 // NewSingleWeavelet returns a new SingleWeavelet that hosts the components
@@ -134,6 +160,7 @@ func NewSingleWeavelet(ctx context.Context, regs []*codegen.Registration, opts S
 
 	w := &SingleWeavelet{
 		ctx:          ctx,
+		workers:      newWorkerGroup(ctx),
 		regs:         regs,
 		regsByName:   regsByName,
 		regsByIntf:   regsByIntf,
@@ -143,12 +170,66 @@ func NewSingleWeavelet(ctx context.Context, regs []*codegen.Registration, opts S
 		deploymentId: deploymentId,
 		id:           id,
 		weaverInfo:   &WeaverInfo{DeploymentID: id},
-		createdAt:    time.Now(),
+		createdAt:    clock.Now(),
 		pp:           logging.NewPrettyPrinter(colors.Enabled()),
 		tracer:       tracer,
 		stats:        imetrics.NewStatsProcessor(),
 		components:   map[string]any{},
 		listeners:    map[string]net.Listener{},
+		grpcStubs:    map[string]*grpcStub{},
+		serialized:   map[string]codegen.Stub{},
+	}
+	if err := w.logLevels.update(config.App.Sections); err != nil {
+		return nil, err
+	}
+	if w.grpcServers, err = parseGRPCConfig(config.App.Sections); err != nil {
+		return nil, err
+	}
+	if w.readOnly, err = parseReadOnlyConfig(config.App.Sections); err != nil {
+		return nil, err
+	}
+	if w.healthPolling, err = parseHealthPollingConfig(config.App.Sections); err != nil {
+		return nil, err
+	}
+	if w.drainTimeout, err = parseDrainingConfig(config.App.Sections); err != nil {
+		return nil, err
+	}
+	if err := parseMethodMetricsConfig(config.App.Sections); err != nil {
+		return nil, err
+	}
+
+	// Export Go runtime metrics, if enabled.
+	runtimeEvery, err := parseRuntimeMetricsConfig(config.App.Sections)
+	if err != nil {
+		return nil, err
+	}
+	if runtimeEvery > 0 {
+		var collector runtimeCollector
+		go collector.run(ctx, runtimeEvery, w.componentNames)
+	}
+
+	// Serve metrics to Prometheus, if enabled.
+	promAddr, err := parsePrometheusConfig(config.App.Sections)
+	if err != nil {
+		return nil, err
+	}
+	if promAddr != "" {
+		logger := w.logger("weavelet")
+		go func() {
+			if err := servePrometheus(ctx, promAddr, logger); err != nil {
+				logger.Error("Prometheus endpoint stopped", "err", err)
+			}
+		}()
+	}
+
+	// Watch the config file for changes.
+	if opts.ConfigFilename != "" {
+		reload := func(contents string) {
+			if err := w.Reload(contents); err != nil {
+				w.logger("weavelet").Error("Failed to reload config", "err", err)
+			}
+		}
+		go runtime.WatchConfigFile(ctx, opts.ConfigFilename, opts.Config, configPollInterval, reload)
 	}
 
 	// Start a signal handler to detect when the process is killed. This isn't
@@ -158,6 +239,13 @@ func NewSingleWeavelet(ctx context.Context, regs []*codegen.Registration, opts S
 	go func() {
 		<-done
 
+		// Stop the background workers.
+		stopCtx, cancel := context.WithTimeout(ctx, w.drainTimeout)
+		if err := w.workers.stop(stopCtx); err != nil {
+			fmt.Printf("Failed to stop background workers: %v\n", err)
+		}
+		cancel()
+
 		w.mu.Lock()
 		defer w.mu.Unlock()
 		for c, impl := range w.components {
@@ -174,6 +262,29 @@ func NewSingleWeavelet(ctx context.Context, regs []*codegen.Registration, opts S
 	return w, nil
 }
 
+// Reload applies the provided new contents of the config file. It updates the
+// log levels of components and reconfigures the components that accept
+// config updates. Other changes take effect only when the application is
+// restarted. Reload returns an error if the config is invalid or if some
+// component fails to be reconfigured, in which case the component keeps its
+// current config.
+//
+// The weavelet calls Reload whenever its config file changes.
+func (w *SingleWeavelet) Reload(contents string) error {
+	config, err := parseSingleConfig(w.regs, w.opts.ConfigFilename, contents)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if err := w.logLevels.update(config.App.Sections); err != nil {
+		errs = append(errs, err)
+	}
+	if err := w.reconfig.update(config.App.Sections); err != nil {
+		errs = append(errs, fmt.Errorf("reconfigure: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
 // parseSingleConfig parses the "[single]" section of a config file.
 func parseSingleConfig(regs []*codegen.Registration, filename, contents string) (*single.SingleConfig, error) {
 	// Parse the config file, if one is given.
@@ -234,6 +345,27 @@ func (w *SingleWeavelet) GetImpl(t reflect.Type) (any, error) {
 	return w.getImpl(t)
 }
 
+// OnHealthChange implements the Weavelet interface.
+func (w *SingleWeavelet) OnHealthChange(ctx context.Context, name string, f func(old, new HealthStatus)) error {
+	get := func(name string) (any, error) {
+		reg, ok := w.regsByName[name]
+		if !ok {
+			return nil, fmt.Errorf("component %q not found", name)
+		}
+		return w.GetIntf(reg.Iface)
+	}
+	return onHealthChange(ctx, name, get, w.healthPolling, w.workers, f)
+}
+
+// Placement implements the Weavelet interface. All components run in the
+// weavelet's process.
+func (w *SingleWeavelet) Placement(name string) Placement {
+	if _, ok := w.regsByName[name]; !ok {
+		return PlacementUnknown
+	}
+	return PlacementLocal
+}
+
 // getIntf returns the component with the provided interface type. The returned
 // value has type t.
 //
@@ -243,11 +375,38 @@ func (w *SingleWeavelet) getIntf(t reflect.Type, requester string) (any, error)
 	if !ok {
 		return nil, fmt.Errorf("component %v not found; maybe you forgot to run weaver generate", t)
 	}
+	if endpoint, ok := w.grpcServers[reg.Name]; ok {
+		// The component is served by an external gRPC server.
+		stub, ok := w.grpcStubs[reg.Name]
+		if !ok {
+			var err error
+			stub, err = newGRPCStub(reg, endpoint, w.tracer)
+			if err != nil {
+				return nil, err
+			}
+			w.grpcStubs[reg.Name] = stub
+		}
+		return reg.ClientStubFn(stub, requester), nil
+	}
 	c, err := w.get(reg)
 	if err != nil {
 		return nil, err
 	}
-	return reg.LocalStubFn(c, requester, w.tracer), nil
+	var stub any
+	if w.opts.Serialize {
+		s, ok := w.serialized[reg.Name]
+		if !ok {
+			s = newSerializingStub(reg, c, w.tracer)
+			w.serialized[reg.Name] = s
+		}
+		stub = reg.ClientStubFn(s, requester)
+	} else {
+		stub = reg.LocalStubFn(c, requester, w.tracer)
+	}
+	if w.readOnly[reg.Name] {
+		stub = readOnlyStub(reg, stub)
+	}
+	return stub, nil
 }
 
 // getImpl returns the component with the provided implementation type. The
@@ -283,10 +442,11 @@ func (w *SingleWeavelet) get(reg *codegen.Registration) (any, error) {
 
 	// Fill config.
 	if cfg := config.Config(v); cfg != nil {
-		if err := runtime.ParseConfigSection(reg.Name, "", w.config.App.Sections, cfg); err != nil {
+		if err := runtime.ParseComponentConfigSection(reg.Name, w.config.App.Sections, cfg); err != nil {
 			return nil, err
 		}
 	}
+	w.reconfig.prepare(obj)
 
 	// Set logger.
 	if err := SetLogger(obj, w.logger(reg.Name)); err != nil {
@@ -304,6 +464,20 @@ func (w *SingleWeavelet) get(reg *codegen.Registration) (any, error) {
 	}); err != nil {
 		return nil, err
 	}
+	if err := FillReplicaRefs(obj, func(t reflect.Type) func() ([]any, error) {
+		// Every component has a single replica.
+		return func() ([]any, error) {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			intf, err := w.getIntf(t, reg.Name)
+			if err != nil {
+				return nil, err
+			}
+			return []any{intf}, nil
+		}
+	}); err != nil {
+		return nil, err
+	}
 
 	// Fill listener fields.
 	if err := FillListeners(obj, func(name string) (net.Listener, string, error) {
@@ -320,6 +494,14 @@ func (w *SingleWeavelet) get(reg *codegen.Registration) (any, error) {
 		}
 	}
 
+	// Receive config updates, if the component accepts them.
+	if err := w.reconfig.add(reg.Name, obj, w.config.App.Sections[reg.Name]); err != nil {
+		w.logger("weavelet").Error("Failed to reconfigure", "component", reg.Name, "err", err)
+	}
+
+	// Start background workers.
+	w.workers.start(reg.Name, obj, w.logger(reg.Name))
+
 	w.components[reg.Name] = obj
 	return obj, nil
 }
@@ -348,6 +530,17 @@ func (w *SingleWeavelet) listener(name string) (net.Listener, error) {
 	return lis, err
 }
 
+// componentNames returns the names of the components that have been created.
+func (w *SingleWeavelet) componentNames() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := make([]string, 0, len(w.components))
+	for name := range w.components {
+		names = append(names, name)
+	}
+	return names
+}
+
 // logger returns a logger for the component with the provided name.
 func (w *SingleWeavelet) logger(name string) *slog.Logger {
 	write := func(entry *protos.LogEntry) {
@@ -368,6 +561,7 @@ func (w *SingleWeavelet) logger(name string) *slog.Logger {
 			Weavelet:   w.id,
 		},
 		Write: write,
+		Level: w.logLevels.get(name),
 	})
 }
 
@@ -542,3 +736,36 @@ func serveHTTP(ctx context.Context, lis net.Listener, handler http.Handler) erro
 		return server.Shutdown(ctx)
 	}
 }
+
+// serializingStub is a codegen.Stub that calls a component in the same process
+// through its server stub, so that the arguments and results of every call are
+// encoded and decoded exactly as for a remote call. See
+// SingleWeaveletOptions.Serialize.
+type serializingStub struct {
+	reg    *codegen.Registration
+	server codegen.Server
+	tracer trace.Tracer
+}
+
+var _ codegen.Stub = (*serializingStub)(nil)
+
+// newSerializingStub returns a serializingStub for the provided component.
+func newSerializingStub(reg *codegen.Registration, impl any, tracer trace.Tracer) *serializingStub {
+	// Load is only reported for routed components, and there is no one to
+	// report it to.
+	server := reg.ServerStubFn(impl, func(uint64, float64) {})
+	return &serializingStub{reg: reg, server: server, tracer: tracer}
+}
+
+// Tracer implements the codegen.Stub interface.
+func (s *serializingStub) Tracer() trace.Tracer {
+	return s.tracer
+}
+
+// Run implements the codegen.Stub interface.
+func (s *serializingStub) Run(ctx context.Context, method int, args []byte, _ uint64) ([]byte, error) {
+	fn := codegen.MethodHandler(s.reg, s.server, method)
+	// The caller reuses args once the call returns, but the server stub may
+	// retain parts of it, as it would the bytes read from the network.
+	return fn(ctx, bytes.Clone(args))
+}
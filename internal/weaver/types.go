@@ -15,6 +15,7 @@
 package weaver
 
 import (
+	"context"
 	"log/slog"
 	"net"
 	"reflect"
@@ -41,6 +42,14 @@ var (
 	//     type T when passed the reflect.Type for T.
 	FillRefs func(impl any, get func(reflect.Type) (any, error)) error
 
+	// FillReplicaRefs initializes Refs[T] fields in a component implement
+	// struct.
+	//   - impl should be a pointer to the implementation struct
+	//   - get should be a function that, when passed the reflect.Type for T,
+	//     returns a function that returns every replica of the component of
+	//     interface type T.
+	FillReplicaRefs func(impl any, get func(reflect.Type) func() ([]any, error)) error
+
 	// HasListeners returns whether the provided component implementation has
 	// weaver.Listener fields.
 	HasListeners func(impl any) bool
@@ -52,6 +61,10 @@ var (
 	//     namely the network listener and the proxy address.
 	FillListeners func(impl any, get func(string) (net.Listener, string, error)) error
 
+	// GetWorkers returns the non-nil weaver.Worker fields in the provided
+	// component implementation, keyed by worker name.
+	GetWorkers func(impl any) map[string]func(context.Context) error
+
 	// HasConfig returns whether the provided component implementation has
 	// an embedded weaver.Config field.
 	HasConfig func(impl any) bool
@@ -59,6 +72,16 @@ var (
 	// GetConfig returns the config stored in the provided component
 	// implementation, or returns nil if there is no config.
 	GetConfig func(impl any) any
+
+	// PrepareConfig prepares the config of the provided component
+	// implementation to be replaced by SetConfig. It must be called before
+	// the component is used, i.e., before its Init method is called.
+	PrepareConfig func(impl any)
+
+	// SetConfig replaces the config returned by the Config method of the
+	// provided component implementation with cfg, a pointer to a new config.
+	// PrepareConfig must have been called on the implementation.
+	SetConfig func(impl any, cfg any)
 )
 
 // Copy of the same struct in the main weaver package.
@@ -15,6 +15,8 @@
 package weaver
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 )
 
@@ -30,4 +32,50 @@ type Weavelet interface {
 	// component interface Foo and implementing struct foo, GetImpl(foo)
 	// returns an instance of type *foo.
 	GetImpl(t reflect.Type) (any, error)
+
+	// OnHealthChange periodically polls the health of the component with the
+	// provided full name, and calls f every time the component's health
+	// status changes, until ctx is done or the weavelet shuts down.
+	OnHealthChange(ctx context.Context, name string, f func(old, new HealthStatus)) error
+
+	// Placement returns where the component with the provided full name runs,
+	// relative to the weavelet. It never activates the component.
+	Placement(name string) Placement
+}
+
+// Placement is where a component runs, relative to a weavelet.
+type Placement int
+
+const (
+	PlacementUnknown Placement = iota // not known yet, e.g., not activated
+	PlacementLocal                    // in the weavelet's process
+	PlacementRemote                   // in another process
+)
+
+// String implements the fmt.Stringer interface.
+func (p Placement) String() string {
+	switch p {
+	case PlacementUnknown:
+		return "unknown"
+	case PlacementLocal:
+		return "local"
+	case PlacementRemote:
+		return "remote"
+	default:
+		return fmt.Sprintf("Placement(%d)", int(p))
+	}
+}
+
+// weaveletKey is the context key for a Weavelet.
+type weaveletKey struct{}
+
+// NewContext returns a copy of ctx that carries the provided weavelet.
+func NewContext(ctx context.Context, w Weavelet) context.Context {
+	return context.WithValue(ctx, weaveletKey{}, w)
+}
+
+// FromContext returns the weavelet carried by ctx, if any.
+func FromContext(ctx context.Context) (Weavelet, bool) {
+	w, ok := ctx.Value(weaveletKey{}).(Weavelet)
+	return w, ok
 }
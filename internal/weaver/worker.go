@@ -0,0 +1,134 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/retry"
+)
+
+// workerHealthyAfter is how long a worker has to run before it is considered
+// healthy, at which point the backoff between its restarts is reset.
+const workerHealthyAfter = time.Minute
+
+var workerRestarts = metrics.NewCounterMap[workerLabels](
+	imetrics.WorkerRestartsName,
+	"Count of Service Weaver component background workers restarted after a panic or error",
+)
+
+type workerLabels struct {
+	Component string // full component name
+	Worker    string // worker name
+}
+
+// A workerGroup runs the background workers of the components hosted by a
+// weavelet. See weaver.Worker.
+type workerGroup struct {
+	ctx    context.Context    // passed to workers; cancelled to stop them
+	cancel context.CancelFunc // cancels ctx
+	wg     sync.WaitGroup     // tracks running workers
+}
+
+// newWorkerGroup returns a workerGroup whose workers run until ctx is done or
+// until stop is called.
+func newWorkerGroup(ctx context.Context) *workerGroup {
+	ctx, cancel := context.WithCancel(ctx)
+	return &workerGroup{ctx: ctx, cancel: cancel}
+}
+
+// start starts the workers of the provided component implementation, if any.
+// It should be called once the component has been initialized.
+func (g *workerGroup) start(component string, impl any, logger *slog.Logger) {
+	for name, fn := range GetWorkers(impl) {
+		g.wg.Add(1)
+		go g.run(component, name, fn, logger)
+	}
+}
+
+// spawn runs f in a goroutine that the group tracks like a worker. The context
+// passed to f is cancelled when ctx is done or when the group is stopped,
+// whichever happens first.
+func (g *workerGroup) spawn(ctx context.Context, f func(context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(g.ctx, cancel)
+		defer stop()
+		f(ctx)
+	}()
+}
+
+// run runs the provided worker, restarting it with backoff whenever it panics
+// or returns an error, until the worker returns nil or the group is stopped.
+func (g *workerGroup) run(component, name string, fn func(context.Context) error, logger *slog.Logger) {
+	defer g.wg.Done()
+	restarts := workerRestarts.Get(workerLabels{Component: component, Worker: name})
+	r := retry.Begin()
+	for attempt := 0; r.Continue(g.ctx); attempt++ {
+		if attempt > 0 {
+			restarts.Inc()
+		}
+		start := time.Now()
+		err := runWorker(g.ctx, fn)
+		if g.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			logger.Debug("Worker finished", "worker", name)
+			return
+		}
+		logger.Error("Worker failed; restarting", "worker", name, "err", err)
+		if time.Since(start) >= workerHealthyAfter {
+			r.Reset()
+		}
+	}
+}
+
+// runWorker runs the provided worker, converting a panic into an error.
+func runWorker(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("panic: %v\n%s", x, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
+
+// stop cancels the context passed to the workers and waits for them to return
+// or for ctx to be done.
+func (g *workerGroup) stop(ctx context.Context) error {
+	g.cancel()
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+)
+
+// workerRestartCount returns the value of the restart metric of the provided
+// worker.
+func workerRestartCount(component, worker string) float64 {
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == imetrics.WorkerRestartsName && snap.Labels["component"] == component && snap.Labels["worker"] == worker {
+			return snap.Value
+		}
+	}
+	return 0
+}
+
+func TestWorkerRestartsOnFailure(t *testing.T) {
+	const component = "TestWorkerRestartsOnFailure"
+	before := workerRestartCount(component, "w")
+	g := newWorkerGroup(context.Background())
+	var runs atomic.Int32
+	stopped := make(chan struct{})
+	g.wg.Add(1)
+	go g.run(component, "w", func(ctx context.Context) error {
+		switch runs.Add(1) {
+		case 1:
+			panic("boom")
+		case 2:
+			return errors.New("failed")
+		default:
+			<-ctx.Done()
+			close(stopped)
+			return ctx.Err()
+		}
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Wait for the worker to be restarted twice.
+	for deadline := time.Now().Add(10 * time.Second); runs.Load() < 3; {
+		if time.Now().After(deadline) {
+			t.Fatalf("worker ran %d times, want 3", runs.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got, want := workerRestartCount(component, "w")-before, 2.0; got != want {
+		t.Errorf("restarts: got %v, want %v", got, want)
+	}
+
+	// Stopping the group should cancel the worker and wait for it.
+	if err := g.stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("worker not cancelled")
+	}
+	if got, want := runs.Load(), int32(3); got != want {
+		t.Errorf("runs: got %d, want %d", got, want)
+	}
+}
+
+func TestWorkerNotRestartedOnSuccess(t *testing.T) {
+	const component = "TestWorkerNotRestartedOnSuccess"
+	before := workerRestartCount(component, "w")
+	g := newWorkerGroup(context.Background())
+	var runs atomic.Int32
+	g.wg.Add(1)
+	go g.run(component, "w", func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	g.wg.Wait()
+	if err := g.stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := runs.Load(), int32(1); got != want {
+		t.Errorf("runs: got %d, want %d", got, want)
+	}
+	if got := workerRestartCount(component, "w") - before; got != 0 {
+		t.Errorf("restarts: got %v, want 0", got)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+// lazyRefTag is the struct tag of a Ref field that is filled lazily. See Ref.
+const lazyRefTag = "lazy"
+
+type lazyRefLabels struct {
+	Caller    string // full name of the component that holds the ref
+	Component string // full name of the referenced component
+
+	// Is this a metric implicitly created by the framework?
+	Generated bool `weaver:"serviceweaver_generated"`
+}
+
+var lazyRefDialLatencyMicros = metrics.NewHistogramMap[lazyRefLabels](
+	"serviceweaver_ref_first_dial_latency_micros",
+	"Duration, in microseconds, of the first dial of a lazy weaver.Ref",
+	imetrics.GeneratedBuckets,
+)
+
+// lazyRef is a handle to a component that is dialed on its first method call.
+type lazyRef struct {
+	get     func() (any, error) // dials the component
+	latency *metrics.Histogram  // records the latency of the first dial
+
+	mu    sync.Mutex                    // serializes dials
+	value atomic.Pointer[reflect.Value] // the dialed component, once dialed
+}
+
+// newLazyRef returns a handle to the component registered as reg, which is
+// dialed with get the first time one of its methods is called. caller is the
+// full name of the component that holds the handle.
+func newLazyRef(reg *codegen.Registration, caller string, get func() (any, error)) any {
+	r := &lazyRef{
+		get: get,
+		latency: lazyRefDialLatencyMicros.Get(lazyRefLabels{
+			Caller:    caller,
+			Component: reg.Name,
+			Generated: true,
+		}),
+	}
+	return reg.ReflectStubFn(r.call)
+}
+
+// dial returns the dialed component, dialing it if needed. A failed dial is
+// retried by the next method call.
+func (r *lazyRef) dial() (reflect.Value, error) {
+	if v := r.value.Load(); v != nil {
+		return *v, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v := r.value.Load(); v != nil {
+		return *v, nil
+	}
+	start := time.Now()
+	component, err := r.get()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	r.latency.Put(float64(time.Since(start).Microseconds()))
+	v := reflect.ValueOf(component)
+	r.value.Store(&v)
+	return v, nil
+}
+
+// call calls the provided method on the dialed component, storing its results
+// in returns and returning its error. It is passed to a ReflectStubFn.
+func (r *lazyRef) call(method string, ctx context.Context, args []any, returns []any) error {
+	component, err := r.dial()
+	if err != nil {
+		return err
+	}
+	m := component.MethodByName(method)
+	if !m.IsValid() {
+		return fmt.Errorf("lazy ref: %v has no method %s", component.Type(), method)
+	}
+	return reflection.CallMethod(m, ctx, args, returns)
+}
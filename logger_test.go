@@ -0,0 +1,58 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestLoggerTraceContext tests that Implements.Logger labels log entries with
+// the trace id and span id of the provided context.
+func TestLoggerTraceContext(t *testing.T) {
+	var got []string
+	var i Implements[int]
+	i.setLogger(slog.New(&logging.LogHandler{
+		Write: func(e *protos.LogEntry) { got = e.Attrs },
+	}))
+
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	i.Logger(ctx).Info("hello", "foo", "bar")
+	want := []string{
+		"traceid", traceID.String(),
+		"spanid", spanID.String(),
+		"foo", "bar",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("attributes (-want +got):\n%s", diff)
+	}
+
+	// A context without a span adds no attributes.
+	i.Logger(context.Background()).Info("hello", "foo", "bar")
+	if diff := cmp.Diff([]string{"foo", "bar"}, got); diff != "" {
+		t.Fatalf("attributes (-want +got):\n%s", diff)
+	}
+}
@@ -32,11 +32,23 @@
 //	if found {
 //		  value := meta["foo"]
 //	}
+//
+// A context can also be marked for debugging by calling WithDebug. Component
+// methods invoked with a debug context, directly or transitively, produce
+// verbose logs and detailed traces, while other calls are unaffected.
+//
+// Finally, a context can carry a session, started by calling WithSession. A
+// component method can issue an opaque session token to its caller by calling
+// SetSessionToken. Subsequent calls made with the caller's session context
+// send the token back, where it can be read with SessionToken, and are routed
+// to the replica that issued it, if that replica is still available.
 package metadata
 
 import (
 	"context"
 	"maps"
+
+	"github.com/ServiceWeaver/weaver/internal/session"
 )
 
 // metaKey is an unexported type for the key that stores the metadata.
@@ -56,3 +68,79 @@ func FromContext(ctx context.Context) (map[string]string, bool) {
 	out := maps.Clone(meta)
 	return out, true
 }
+
+// debugKey is an unexported type for the key that stores the debug flag.
+type debugKey struct{}
+
+// WithDebug returns a new context that marks the calls made with it for
+// debugging. The servers of remote calls made with a debug context log every
+// call and record detailed traces for it. The debug flag is propagated to the
+// callee, so calls made by the callee with its context are debugged as well.
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// IsDebug returns whether ctx is marked for debugging by WithDebug.
+func IsDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugKey{}).(bool)
+	return debug
+}
+
+// priorityKey is an unexported type for the key that stores the priority.
+type priorityKey struct{}
+
+// WithPriority returns a new context that sets the priority of the calls made
+// with it. Calls have priority 0 by default, and calls with a higher priority
+// are more urgent: a server that limits the number of calls it runs at once
+// runs the waiting calls with the highest priority first. The priority is
+// propagated to the callee, so calls made by the callee with its context have
+// the same priority.
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// Priority returns the priority set in ctx by WithPriority, or 0 if there is
+// none.
+func Priority(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityKey{}).(int)
+	return priority
+}
+
+// WithSession returns a new context that starts a new session. Calls made
+// with the returned context hold on to the session token most recently issued
+// by the component methods they call, like a cookie jar. Every subsequent call
+// sends the token to the callee and, if the callee is hosted in another
+// process, is routed to the replica that issued the token, if that replica is
+// still available. Otherwise, the call is routed as usual.
+func WithSession(ctx context.Context) context.Context {
+	return session.WithJar(ctx)
+}
+
+// SetSessionToken issues a session token to the caller of the component method
+// that received ctx. The token is opaque to the caller, which sends it back on
+// subsequent calls made with its session context (see WithSession).
+// SetSessionToken is a no-op if the caller didn't start a session.
+func SetSessionToken(ctx context.Context, token string) {
+	if s, ok := session.ServerFromContext(ctx); ok {
+		s.Issue(token)
+		return
+	}
+	if jar, ok := session.JarFromContext(ctx); ok {
+		// The caller is colocated with us, so there is no replica to route
+		// subsequent calls to.
+		jar.Set(token, "")
+	}
+}
+
+// SessionToken returns the session token sent by the caller of the component
+// method that received ctx, or the token the method issued with
+// SetSessionToken, if any.
+func SessionToken(ctx context.Context) (string, bool) {
+	var token string
+	if s, ok := session.ServerFromContext(ctx); ok {
+		token = s.Token()
+	} else if jar, ok := session.JarFromContext(ctx); ok {
+		token, _ = jar.Get()
+	}
+	return token, token != ""
+}
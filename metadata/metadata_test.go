@@ -92,3 +92,42 @@ func TestContextMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestDebug(t *testing.T) {
+	ctx := context.Background()
+	if IsDebug(ctx) {
+		t.Fatal("IsDebug: got true for a fresh context")
+	}
+	if !IsDebug(WithDebug(ctx)) {
+		t.Fatal("IsDebug: got false for a debug context")
+	}
+}
+
+func TestPriority(t *testing.T) {
+	ctx := context.Background()
+	if got := Priority(ctx); got != 0 {
+		t.Fatalf("Priority: got %d for a fresh context, want 0", got)
+	}
+	if got := Priority(WithPriority(ctx, -1)); got != -1 {
+		t.Fatalf("Priority: got %d, want -1", got)
+	}
+}
+
+func TestSession(t *testing.T) {
+	// Outside of a session, tokens are dropped.
+	ctx := context.Background()
+	SetSessionToken(ctx, "dropped")
+	if token, ok := SessionToken(ctx); ok {
+		t.Fatalf("SessionToken: got %q, want none", token)
+	}
+
+	// In a session, a token issued by a colocated method is kept.
+	ctx = WithSession(ctx)
+	if token, ok := SessionToken(ctx); ok {
+		t.Fatalf("SessionToken: got %q, want none", token)
+	}
+	SetSessionToken(ctx, "token")
+	if token, ok := SessionToken(ctx); !ok || token != "token" {
+		t.Fatalf("SessionToken: got %q, %v, want %q, true", token, ok, "token")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import (
+	"context"
+	"fmt"
+)
+
+// Offset is a position in an append-only log, like the entries of a ledger
+// served by a component. A component method that fetches entries from a log
+// receives the Offset to fetch from and returns the fetched entries along with
+// the Offset that follows them. For example:
+//
+//	type Ledger interface {
+//	    Fetch(ctx context.Context, from weaver.Offset, max int) ([]Entry, weaver.Offset, error)
+//	}
+//
+// Offsets are opaque to clients. A client starts reading at offset 0 and
+// passes the returned offset to the next call. Fetching entries doesn't
+// change the log, so a client that saves the last returned offset can resume
+// reading from it after a failure or restart. See ReadLog.
+//
+// weaver generate checks that a method that receives an Offset returns the
+// next Offset.
+type Offset uint64
+
+// ReadLog reads the log served by fetch, starting at offset from. fetch
+// returns a batch of entries at or after the provided offset, along with the
+// offset that follows the batch. ReadLog passes every non-empty batch to f,
+// along with the offset that follows it, and returns once fetch returns no
+// entries. For example:
+//
+//	next, err := weaver.ReadLog(ctx, saved,
+//	    func(ctx context.Context, from weaver.Offset) ([]Entry, weaver.Offset, error) {
+//	        return ledger.Fetch(ctx, from, 100)
+//	    },
+//	    func(entries []Entry, next weaver.Offset) error {
+//	        process(entries)
+//	        saved = next
+//	        return nil
+//	    })
+//
+// ReadLog returns the offset that follows the last batch that f processed
+// successfully, or from if there is no such batch, even if it also returns an
+// error. A client can resume reading from this offset later.
+func ReadLog[T any](ctx context.Context, from Offset, fetch func(context.Context, Offset) ([]T, Offset, error), f func([]T, Offset) error) (Offset, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return from, err
+		}
+		entries, next, err := fetch(ctx, from)
+		if err != nil {
+			return from, err
+		}
+		if len(entries) == 0 {
+			return from, nil
+		}
+		if next == from {
+			return from, fmt.Errorf("weaver.ReadLog: offset %d did not advance after fetching %d entries", from, len(entries))
+		}
+		if err := f(entries, next); err != nil {
+			return from, err
+		}
+		from = next
+	}
+}
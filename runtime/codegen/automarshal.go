@@ -32,6 +32,9 @@ var (
 	typesMu  sync.Mutex
 	types    map[string]reflect.Type // Registered serializable types
 	typeKeys map[reflect.Type]string // Keys of registered serializable types
+
+	taggedTypes map[string]reflect.Type // Types registered with RegisterType, by tag
+	typeTags    map[reflect.Type]string // Tags of types registered with RegisterType
 )
 
 // RegisterSerializable records type T as serializable. This is needed to
@@ -59,6 +62,57 @@ func RegisterSerializable[T AutoMarshal]() {
 	typeKeys[t] = key
 }
 
+// RegisterType registers type t under the provided tag, so that values of
+// type t can be stored in fields of type any (or of a named empty interface
+// type) of AutoMarshal structs. Such a value is encoded as its tag, followed by
+// its serialization, and the receiver uses the tag to instantiate a value of
+// the same type. For example:
+//
+//	type Transaction struct {
+//	    weaver.AutoMarshal
+//	    Metadata any // holds a Refund or a Transfer
+//	}
+//
+//	func init() {
+//	    codegen.RegisterType("refund", reflect.TypeOf(Refund{}))
+//	    codegen.RegisterType("transfer", reflect.TypeOf(&Transfer{}))
+//	}
+//
+// The tag must be non-empty and stable across the processes of an
+// application. t, or a pointer to t, must implement AutoMarshal. RegisterType
+// panics if these conditions are not met, or if the tag or the type has
+// already been registered differently.
+func RegisterType(tag string, t reflect.Type) {
+	if tag == "" {
+		panic(fmt.Sprintf("empty tag for type %v", t))
+	}
+	autoMarshal := reflect.TypeOf((*AutoMarshal)(nil)).Elem()
+	if t.Kind() == reflect.Pointer && !t.Implements(autoMarshal) ||
+		t.Kind() != reflect.Pointer && !reflect.PointerTo(t).Implements(autoMarshal) {
+		panic(fmt.Sprintf("type %v registered with tag %q is not serializable", t, tag))
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	if taggedTypes == nil {
+		taggedTypes = map[string]reflect.Type{}
+		typeTags = map[reflect.Type]string{}
+	}
+	existingType, tagFound := taggedTypes[tag]
+	existingTag, typeFound := typeTags[t]
+	if tagFound && existingType == t {
+		return
+	}
+	if tagFound {
+		panic(fmt.Sprintf("multiple types (%v and %v) have the same tag %q", existingType, t, tag))
+	}
+	if typeFound {
+		panic(fmt.Sprintf("type %v has multiple tags (%q and %q)", t, existingTag, tag))
+	}
+	taggedTypes[tag] = t
+	typeTags[t] = tag
+}
+
 // typeKey returns the key to use to identify the type of value.
 // The returned key is stable across processes.
 func typeKey(value any) string {
@@ -0,0 +1,66 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+// Bitsets
+//
+// Struct fields of type []bool tagged with `weaver:"bitset"` are encoded as
+// bitsets. A slice is encoded as its length (-1 for a nil slice) followed by
+// ceil(length/8) bytes. Element i is stored in bit i%8 (least significant bit
+// first) of byte i/8, and unused bits of the last byte are zero. For example,
+// the slice [true, false, true, true, false, false, false, false, true] is
+// encoded as
+//
+//     9 | 0b00001101 | 0b00000001
+//
+// A bitset takes one bit per element, rather than the one byte per element of
+// the plain encoding.
+
+// EncodeBitset encodes s into enc as a bitset.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeBitset(enc *Encoder, s []bool) {
+	if s == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(s))
+	data := enc.Grow((len(s) + 7) / 8)
+	clear(data)
+	for i, b := range s {
+		if b {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+}
+
+// DecodeBitset decodes a slice that was encoded using EncodeBitset.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeBitset(dec *Decoder) []bool {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	data := dec.Read((n + 7) / 8)
+	if n%8 != 0 && data[len(data)-1]>>(n%8) != 0 {
+		panic(makeDecodeError("bitset of length %d has non-zero padding bits", n))
+	}
+	res := make([]bool, n)
+	for i := range res {
+		res[i] = data[i/8]&(1<<(i%8)) != 0
+	}
+	return res
+}
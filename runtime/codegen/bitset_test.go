@@ -0,0 +1,120 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestBitsetRoundTrip encodes and decodes a number of slices as bitsets.
+// Verify that the slices, including their lengths, are decoded as expected.
+func TestBitsetRoundTrip(t *testing.T) {
+	alternating := func(n int) []bool {
+		s := make([]bool, n)
+		for i := range s {
+			s[i] = i%2 == 0
+		}
+		return s
+	}
+	for _, test := range []struct {
+		name string
+		s    []bool
+	}{
+		{"Nil", nil},
+		{"Empty", []bool{}},
+		{"One", []bool{true}},
+		{"AllFalse", make([]bool, 13)},
+		{"Seven", alternating(7)},
+		{"Eight", alternating(8)},
+		{"Nine", alternating(9)},
+		{"Large", alternating(1001)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc := NewEncoder()
+			EncodeBitset(enc, test.s)
+			if got, want := len(enc.Data()), 4+(len(test.s)+7)/8; got != want {
+				t.Fatalf("size: got %d, want %d", got, want)
+			}
+			dec := NewDecoder(enc.Data())
+			got := DecodeBitset(dec)
+			if diff := cmp.Diff(test.s, got); diff != "" {
+				t.Fatalf("(-want,+got):\n%s", diff)
+			}
+			if (test.s == nil) != (got == nil) {
+				t.Fatalf("nil mismatch: want %v, got %v", test.s == nil, got == nil)
+			}
+			if !dec.Empty() {
+				t.Fatalf("unexpected bytes left to be read: %d", len(dec.data))
+			}
+		})
+	}
+}
+
+// TestBitsetLayout checks the encoding of a bitset against the documented
+// layout.
+func TestBitsetLayout(t *testing.T) {
+	enc := NewEncoder()
+	EncodeBitset(enc, []bool{true, false, true, true, false, false, false, false, true})
+	want := NewEncoder()
+	want.Len(9)
+	want.Byte(0b00001101)
+	want.Byte(0b00000001)
+	if diff := cmp.Diff(want.Data(), enc.Data()); diff != "" {
+		t.Fatalf("(-want,+got):\n%s", diff)
+	}
+}
+
+// TestBitsetReusedEncoder encodes a bitset into a released encoder whose
+// buffer holds stale data. Verify that the stale data doesn't leak into the
+// bitset.
+func TestBitsetReusedEncoder(t *testing.T) {
+	enc := NewEncoder()
+	enc.Reset(16)
+	for i := 0; i < 16; i++ {
+		enc.Byte(0xff)
+	}
+	enc.Reset(16)
+	EncodeBitset(enc, make([]bool, 16))
+	got := DecodeBitset(NewDecoder(enc.Data()))
+	if diff := cmp.Diff(make([]bool, 16), got); diff != "" {
+		t.Fatalf("(-want,+got):\n%s", diff)
+	}
+}
+
+// TestBitsetInvalid decodes malformed bitsets. Verify that decoding fails.
+func TestBitsetInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data func(*Encoder)
+		want string
+	}{
+		{"Padding", func(enc *Encoder) { enc.Len(3); enc.Byte(0b00001000) }, "non-zero padding bits"},
+		{"Truncated", func(enc *Encoder) { enc.Len(9); enc.Byte(0) }, "unable to read"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc := NewEncoder()
+			test.data(enc)
+			err := convertCallPanicToError(func() {
+				DecodeBitset(NewDecoder(enc.Data()))
+			})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("DecodeBitset: got %v, want %q error", err, test.want)
+			}
+		})
+	}
+}
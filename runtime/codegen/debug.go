@@ -0,0 +1,177 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Debug strings
+//
+// The code generator gives every AutoMarshal struct a DebugString method that
+// renders, as a JSON object, exactly the fields that WeaverMarshal encodes:
+// every field, exported or not, except for blank fields and the embedded
+// weaver.AutoMarshal. The fields appear in declaration order, keyed by their
+// Go names. For example, the following struct
+//
+//     type Product struct {
+//         weaver.AutoMarshal
+//         Name  string
+//         price float64
+//     }
+//
+// is rendered as {"Name":"Lamp","price":19.99}.
+//
+// Nested AutoMarshal structs are rendered with their own DebugString methods,
+// or as strings if those methods are hand-written and don't return JSON.
+// Errors are rendered as their messages. Other values are rendered with
+// encoding/json, except that values that JSON can't represent, like NaN floats
+// and complex numbers, are rendered as strings, and map keys are rendered with
+// fmt.Sprint.
+
+// DebugField is a field of a struct rendered by DebugString.
+type DebugField struct {
+	Name  string // field name
+	Value any    // field value
+}
+
+// debugStringer is the interface of the structs given a DebugString method
+// by the code generator.
+type debugStringer interface {
+	AutoMarshal
+	DebugString() string
+}
+
+// DebugString returns a JSON object with the provided fields, in order.
+//
+// NOTE that this function should be called only in the generated code.
+func DebugString(fields ...DebugField) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.Name)
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(debugJSON(f.Value))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// debugJSON returns the JSON rendering of v.
+func debugJSON(v any) []byte {
+	data, err := json.Marshal(debugValue(reflect.ValueOf(v)))
+	if err != nil {
+		// The value contains a type that JSON can't represent, deep inside a
+		// value that debugValue doesn't traverse.
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+// debugValue returns a value that encoding/json renders as the debug string
+// of v.
+func debugValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(debugStringerType) {
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		s := p.Interface().(debugStringer).DebugString()
+		if !json.Valid([]byte(s)) {
+			// A hand-written DebugString method.
+			return s
+		}
+		return json.RawMessage(s)
+	}
+	if t.Implements(errorType) && v.CanInterface() && !(nillable(t.Kind()) && v.IsNil()) {
+		// An error is rendered as its message, which is what survives
+		// encoding with Encoder.Error.
+		return v.Interface().(error).Error()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return debugValue(v.Elem())
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprint(f)
+		}
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v.Interface() // rendered as base64, like in encoding/json
+		}
+		fallthrough
+	case reflect.Array:
+		elems := make([]any, v.Len())
+		for i := range elems {
+			elems[i] = debugValue(v.Index(i))
+		}
+		return elems
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[debugKey(iter.Key())] = debugValue(iter.Value())
+		}
+		return m
+	}
+	if !v.CanInterface() {
+		return fmt.Sprint(v)
+	}
+	return v.Interface()
+}
+
+// nillable returns whether values of the provided kind can be nil.
+func nillable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// debugKey returns the JSON object key of the map key k.
+func debugKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return fmt.Sprint(k)
+}
+
+var (
+	debugStringerType = reflect.TypeOf((*debugStringer)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+)
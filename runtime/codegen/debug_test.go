@@ -0,0 +1,83 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// money is an AutoMarshal struct with a hand-written DebugString method.
+type money struct {
+	cents int64
+}
+
+func (m *money) WeaverMarshal(enc *Encoder)   { enc.Int64(m.cents) }
+func (m *money) WeaverUnmarshal(dec *Decoder) { m.cents = dec.Int64() }
+func (m *money) DebugString() string {
+	return DebugString(DebugField{Name: "cents", Value: m.cents})
+}
+
+// label is an AutoMarshal struct with a DebugString method that doesn't
+// return JSON.
+type label struct {
+	text string
+}
+
+func (l *label) WeaverMarshal(enc *Encoder)   { enc.String(l.text) }
+func (l *label) WeaverUnmarshal(dec *Decoder) { l.text = dec.String() }
+func (l *label) DebugString() string          { return "<" + l.text + ">" }
+
+func TestDebugString(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"String", "a\"b", `"a\"b"`},
+		{"Int", 42, `42`},
+		{"NaN", math.NaN(), `"NaN"`},
+		{"Inf", math.Inf(-1), `"-Inf"`},
+		{"Complex", complex(1, 2), `"(1+2i)"`},
+		{"Bytes", []byte("abc"), `"YWJj"`},
+		{"NilSlice", []string(nil), `null`},
+		{"Array", [2]float64{1, math.NaN()}, `[1,"NaN"]`},
+		{"IntKeys", map[int]bool{2: true, 1: false}, `{"1":false,"2":true}`},
+		{"StructKeys", map[[2]int]int{{1, 2}: 3}, `{"[1 2]":3}`},
+		{"NilPointer", (*int)(nil), `null`},
+		{"AutoMarshal", money{150}, `{"cents":150}`},
+		{"AutoMarshalPointer", &money{150}, `{"cents":150}`},
+		{"NotJSON", label{"x"}, `"\u003cx\u003e"`},
+		{"NestedAutoMarshal", map[string][]money{"x": {{1}, {2}}}, `{"x":[{"cents":1},{"cents":2}]}`},
+		{"Error", errors.New("boom"), `"boom"`},
+		{"NilError", error(nil), `null`},
+		{"Errors", []error{nil, errors.New("boom")}, `[null,"boom"]`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := DebugString(DebugField{Name: "f", Value: test.value})
+			if want := `{"f":` + test.want + `}`; got != want {
+				t.Fatalf("DebugString: got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDebugStringFieldOrder(t *testing.T) {
+	got := DebugString(DebugField{Name: "b", Value: 1}, DebugField{Name: "a", Value: 2})
+	if want := `{"b":1,"a":2}`; got != want {
+		t.Fatalf("DebugString: got %s, want %s", got, want)
+	}
+}
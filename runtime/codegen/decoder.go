@@ -15,11 +15,13 @@
 package codegen
 
 import (
+	"context"
 	"encoding"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"reflect"
 
 	"google.golang.org/protobuf/proto"
@@ -41,14 +43,111 @@ func (d decoderError) Unwrap() error {
 	return d.err
 }
 
+// MaxServerAlloc is the maximum total number of elements that a server stub
+// decodes into the slices and maps of the arguments of a single call. It
+// prevents a malformed or malicious request from making the server allocate a
+// huge slice or map from a bogus length. Strings and byte slices are not
+// counted, since their length is checked against the size of the request
+// before they are allocated. See Decoder.SetMaxAlloc.
+const MaxServerAlloc = 1 << 24
+
 // Decoder deserializes data from a byte slice data in the expected results.
 type Decoder struct {
 	data []byte
+
+	// If limited is true, budget is the number of elements that Len may still
+	// return. See SetMaxAlloc.
+	limited bool
+	budget  int
+
+	// hint, if not nil, is a slice that MakeSlice may reuse. See
+	// SetSliceHint.
+	hint any
+
+	// If zeroCopy is true, ByteSlice returns sub-slices of data. See
+	// SetZeroCopy.
+	zeroCopy bool
 }
 
 // NewDecoder instantiates a new Decoder for a given byte slice.
 func NewDecoder(data []byte) *Decoder {
-	return &Decoder{data}
+	return &Decoder{data: data}
+}
+
+// sliceHintKey is the context key for the slice hint set by WithSliceHint.
+type sliceHintKey struct{}
+
+// WithSliceHint returns a context that lets the results of a remote method
+// call made with it be decoded into the backing array of s, rather than into a
+// newly allocated slice. The first slice of type []T decoded from the results
+// reuses s if cap(s) is at least the decoded length; otherwise, it is
+// allocated as usual. For example, a hot loop that lists products can reuse
+// the slice returned by the previous call:
+//
+//	var products []catalog.Product
+//	for ... {
+//	    products, err = client.ListProducts(codegen.WithSliceHint(ctx, products))
+//	    ...
+//	}
+//
+// The contents of s are overwritten, so the caller must not use s, or any
+// slice sharing its backing array, once the call is made. For the same reason,
+// the returned context must not be used for concurrent calls. Local method
+// calls ignore the hint. Applications call it through weaver.WithSliceHint.
+func WithSliceHint[T any](ctx context.Context, s []T) context.Context {
+	if cap(s) == 0 {
+		// There is nothing to reuse.
+		return ctx
+	}
+	return context.WithValue(ctx, sliceHintKey{}, s[:cap(s)])
+}
+
+// NewResultDecoder returns a Decoder for the results of a method call made
+// with the provided context. The Decoder reuses the slice hint set on the
+// context by WithSliceHint, if any.
+func NewResultDecoder(ctx context.Context, data []byte) *Decoder {
+	return &Decoder{data: data, hint: ctx.Value(sliceHintKey{})}
+}
+
+// SetSliceHint provides a slice that d may reuse instead of allocating a new
+// slice of the same type. See MakeSlice.
+func (d *Decoder) SetSliceHint(s any) {
+	d.hint = s
+}
+
+// MakeSlice returns a slice of n zero values for d to decode into. If d has a
+// slice hint of type []T with a capacity of at least n, MakeSlice reuses the
+// hint's backing array, and the hint is consumed. Otherwise, MakeSlice
+// allocates a new slice.
+func MakeSlice[T any](d *Decoder, n int) []T {
+	if s, ok := d.hint.([]T); ok && cap(s) >= n {
+		d.hint = nil
+		s = s[:n]
+		clear(s)
+		return s
+	}
+	return make([]T, n)
+}
+
+// SetMaxAlloc limits the total number of elements of the slices and maps that
+// d decodes to n. Once the limit is reached, decoding a non-empty slice or map
+// fails with a decoding error, rather than allocating memory for a length read
+// from a possibly malformed input. Strings and byte slices are bounded by the
+// size of the input instead, and don't count against the limit. If n is
+// negative, d decodes slices and maps of any length, which is the default.
+func (d *Decoder) SetMaxAlloc(n int) {
+	d.limited = n >= 0
+	d.budget = n
+}
+
+// SetZeroCopy makes ByteSlice return sub-slices of the data that d decodes,
+// rather than copies. This avoids a copy per decoded byte slice, but the
+// returned slices share memory with the data passed to NewDecoder: they are
+// valid only as long as the data is neither modified nor reused, e.g., after
+// being returned to a buffer pool, and modifying them modifies the data.
+// Appending to a returned slice never overwrites the data, though.
+func (d *Decoder) SetZeroCopy() {
+	d.zeroCopy = true
 }
 
 // Empty returns true iff all bytes in d have been consumed.
@@ -87,6 +186,19 @@ func (d *Decoder) Read(n int) []byte {
 	return b
 }
 
+// Bytes16 decodes a value of type [16]byte encoded by Encoder.Bytes16.
+func (d *Decoder) Bytes16() [16]byte {
+	var b [16]byte
+	copy(b[:], d.Read(16))
+	return b
+}
+
+// ByteArray decodes the contents of a byte array encoded by
+// Encoder.ByteArray into res. It reads exactly len(res) bytes.
+func (d *Decoder) ByteArray(res []byte) {
+	copy(res, d.Read(len(res)))
+}
+
 // Uint8 decodes a value of type uint8.
 func (d *Decoder) Uint8() uint8 {
 	return d.Read(1)[0]
@@ -199,9 +311,82 @@ func (d *Decoder) Bytes() []byte {
 	return d.Read(int(n))
 }
 
+// ByteSlice decodes a value of type []byte encoded by Encoder.ByteSlice. The
+// returned slice is a copy, unless SetZeroCopy was called.
+//
+// The length of the slice is checked against the remaining data before the
+// slice is allocated, so byte slices don't count against the limit set by
+// SetMaxAlloc.
+func (d *Decoder) ByteSlice() []byte {
+	n := int(d.Int32())
+	if n == -1 {
+		return nil
+	}
+	if n < -1 {
+		panic(makeDecodeError("length can't be smaller than -1"))
+	}
+	b := d.Read(n)
+	if d.zeroCopy {
+		return b[:n:n]
+	}
+	res := MakeSlice[byte](d, n)
+	copy(res, b)
+	return res
+}
+
+// BigInt decodes a value of type *big.Int.
+func (d *Decoder) BigInt() *big.Int {
+	sign := d.Int8()
+	if sign == -1 {
+		return nil
+	}
+	if sign != 0 && sign != 1 {
+		panic(makeDecodeError("unable to decode big.Int; expected sign in {-1, 0, 1} got %d", sign))
+	}
+	x := new(big.Int).SetBytes(d.Bytes())
+	if sign == 1 {
+		x.Neg(x)
+	}
+	return x
+}
+
+// BigRat decodes a value of type *big.Rat.
+func (d *Decoder) BigRat() *big.Rat {
+	num := d.BigInt()
+	if num == nil {
+		return nil
+	}
+	denom := new(big.Int).SetBytes(d.Bytes())
+	if denom.Sign() == 0 {
+		panic(makeDecodeError("unable to decode big.Rat; zero denominator"))
+	}
+	return new(big.Rat).SetFrac(num, denom)
+}
+
+// Latitude decodes a latitude, in degrees, encoded by Encoder.Latitude.
+func (d *Decoder) Latitude() float64 {
+	return d.degrees("latitude", 90)
+}
+
+// Longitude decodes a longitude, in degrees, encoded by Encoder.Longitude.
+func (d *Decoder) Longitude() float64 {
+	return d.degrees("longitude", 180)
+}
+
+// degrees decodes a fixed-precision int32 encoded by Encoder.degrees. Panics if
+// the decoded value is not in the range [-max, max].
+func (d *Decoder) degrees(kind string, max int32) float64 {
+	units := d.Int32()
+	if limit := max * degreesScale; units < -limit || units > limit {
+		panic(makeDecodeError("unable to decode %s; expected value in [%d, %d] got %v", kind, -max, max, float64(units)/degreesScale))
+	}
+	return float64(units) / degreesScale
+}
+
 // Len attempts to decode an int32.
 //
-// Panics if the result is negative (except -1).
+// Panics if the result is negative (except -1), or if it exceeds the limit set
+// by SetMaxAlloc.
 //
 // NOTE that this method should be called only in the generated code, to avoid
 // generating repetitive code that decodes the length of a non-basic type (e.g., slice, map).
@@ -210,44 +395,101 @@ func (d *Decoder) Len() int {
 	if n < -1 {
 		panic(makeDecodeError("length can't be smaller than -1"))
 	}
+	if d.limited && n > 0 {
+		if n > d.budget {
+			panic(makeDecodeError("length %d exceeds the allocation limit; %d elements remaining", n, d.budget))
+		}
+		d.budget -= n
+	}
 	return n
 }
 
-// Error decodes an error. We construct an instance of a special error value
+// Error decodes an error encoded by Encoder.Error, returning nil if the
+// encoded error was nil. We construct an instance of a special error value
 // that provides Is and Unwrap support.
 func (d *Decoder) Error() error {
 	// Decode the list of errors produced by Encoder.Error().
 	var list []error
+	var codes []int32
+	retryable := false
 	for {
 		tag := d.Uint8()
 		if tag == endOfErrors {
 			break
+		} else if tag == retryableHint {
+			retryable = true
+		} else if tag == codeHint {
+			codes = append(codes, d.Int32())
 		} else if tag == serializedErrorVal {
 			val := d.Interface()
 			if e, ok := val.(error); ok {
 				list = append(list, e)
 				continue
 			}
-			panic(fmt.Sprintf("received type %T which is not an error", val))
+			panic(makeDecodeError("received type %T which is not an error", val))
 		} else if tag == serializedErrorPtr {
 			val := d.Interface()
 			if e, ok := pointee(val).(error); ok {
 				list = append(list, e)
 				continue
 			}
-			panic(fmt.Sprintf("received type %T which is not a pointer to error", val))
+			panic(makeDecodeError("received type %T which is not a pointer to error", val))
 		} else if tag == emulatedError {
 			msg := d.String()
 			f := d.String()
 			list = append(list, decodedError{msg, f})
 		} else {
-			panic(fmt.Sprintf("invalid error list tag %d", tag))
+			panic(makeDecodeError("invalid error list tag %d", tag))
 		}
 	}
+	if len(list) == 0 {
+		// Hints that don't wrap any error don't make a non-nil error.
+		return nil
+	}
+	var err error
 	if len(list) == 1 {
-		return list[0] // Preserve original error instead of wrapping it via Join
+		err = list[0] // Preserve original error instead of wrapping it via Join
+	} else {
+		err = errors.Join(list...)
+	}
+	// Wrap err in the codes in reverse order, so that errors.As finds the
+	// code that was found first, as it would on the original error.
+	for i := len(codes) - 1; i >= 0; i-- {
+		err = CodedError{codes[i], err}
+	}
+	if retryable {
+		return Retryable(err)
+	}
+	return err
+}
+
+// Any decodes a value encoded by Encoder.Any. It panics with a decoding error
+// if the value's tag was not registered with RegisterType.
+func (d *Decoder) Any() any {
+	tag := d.String()
+	if tag == "" {
+		return nil
+	}
+	typesMu.Lock()
+	t, ok := taggedTypes[tag]
+	typesMu.Unlock()
+	if !ok {
+		panic(makeDecodeError("received value with unknown type tag %q", tag))
+	}
+
+	// Allocate space for the value. RegisterType checked that the pointer
+	// implements AutoMarshal.
+	var ptr reflect.Value
+	if t.Kind() == reflect.Pointer {
+		ptr = reflect.New(t.Elem())
+	} else {
+		ptr = reflect.New(t)
+	}
+	ptr.Interface().(AutoMarshal).WeaverUnmarshal(d)
+	if t.Kind() != reflect.Pointer {
+		return ptr.Elem().Interface()
 	}
-	return errors.Join(list...)
+	return ptr.Interface()
 }
 
 // Interface decodes a value encoded by Encoder.Interface.
@@ -259,7 +501,7 @@ func (d *Decoder) Interface() any {
 	defer typesMu.Unlock()
 	t, ok := types[key]
 	if !ok {
-		panic(fmt.Sprintf("received value for non-registered type %q", key))
+		panic(makeDecodeError("received value for non-registered type %q", key))
 	}
 
 	// Allocate space for the value.
@@ -271,7 +513,7 @@ func (d *Decoder) Interface() any {
 	}
 	am, ok := ptr.Interface().(AutoMarshal)
 	if !ok {
-		panic(fmt.Sprintf("received value for non-serializable type %v", t))
+		panic(makeDecodeError("received value for non-serializable type %v", t))
 	}
 	am.WeaverUnmarshal(d)
 
@@ -19,6 +19,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/big"
+	"reflect"
+	"sync"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -45,10 +48,38 @@ type Encoder struct {
 	space [100]byte // Prellocated buffer to avoid allocations for small size arguments.
 }
 
+// maxPooledCap is the largest buffer capacity of an Encoder that is returned
+// to encoderPool. Larger Encoders are left to the garbage collector, so that a
+// single large call doesn't pin a large buffer in the pool.
+const maxPooledCap = 64 << 10
+
+// encoderPool is a pool of Encoders, used to avoid allocating a new Encoder
+// and buffer for every remote method call.
+var encoderPool = sync.Pool{
+	New: func() any {
+		var enc Encoder
+		enc.data = enc.space[:0] // Arrange to use builtin buffer
+		return &enc
+	},
+}
+
+// NewEncoder returns an empty Encoder. The Encoder may be drawn from a pool of
+// previously released Encoders. Call Release to return the Encoder to the pool
+// once its data is no longer needed.
 func NewEncoder() *Encoder {
-	var enc Encoder
-	enc.data = enc.space[:0] // Arrange to use builtin buffer
-	return &enc
+	return encoderPool.Get().(*Encoder)
+}
+
+// Release returns the Encoder to the pool used by NewEncoder. Neither the
+// Encoder nor the slice returned by Data may be used after Release is called.
+// Calling Release is optional; an Encoder that is not released is garbage
+// collected as usual.
+func (e *Encoder) Release() {
+	if cap(e.data) > maxPooledCap {
+		return
+	}
+	e.data = e.data[:0]
+	encoderPool.Put(e)
 }
 
 // Reset resets the Encoder to use a buffer with a capacity of at least the
@@ -107,6 +138,19 @@ func (e *Encoder) Grow(bytesNeeded int) []byte {
 	return e.data[n:]
 }
 
+// Bytes16 encodes an arg of type [16]byte, e.g., a UUID. Unlike Bytes, the
+// bytes are written as is, without a length prefix.
+func (e *Encoder) Bytes16(arg [16]byte) {
+	copy(e.Grow(16), arg[:])
+}
+
+// ByteArray encodes the contents of a byte array. Unlike Bytes, the bytes are
+// written as is, without a length prefix, since the length of an array is
+// known when decoding it.
+func (e *Encoder) ByteArray(arg []byte) {
+	copy(e.Grow(len(arg)), arg)
+}
+
 // Uint8 encodes an arg of type uint8.
 func (e *Encoder) Uint8(arg uint8) {
 	e.Grow(1)[0] = arg
@@ -233,6 +277,77 @@ func (e *Encoder) Bytes(arg []byte) {
 	copy(data[4:], arg)
 }
 
+// ByteSlice encodes an arg of type []byte. It encodes the length of the slice,
+// or -1 if the slice is nil, followed by its contents, copied as is.
+func (e *Encoder) ByteSlice(arg []byte) {
+	if arg == nil {
+		e.Len(-1)
+		return
+	}
+	e.Len(len(arg))
+	copy(e.Grow(len(arg)), arg)
+}
+
+// BigInt encodes an arg of type *big.Int.
+// For a nil pointer, we encode a sign of -1. Otherwise, we encode the sign (0
+// for non-negative and 1 for negative numbers), followed by the big-endian
+// bytes of the absolute value.
+func (e *Encoder) BigInt(arg *big.Int) {
+	if arg == nil {
+		e.Int8(-1)
+		return
+	}
+	if arg.Sign() < 0 {
+		e.Int8(1)
+	} else {
+		e.Int8(0)
+	}
+	e.Bytes(arg.Bytes())
+}
+
+// BigRat encodes an arg of type *big.Rat. The numerator is encoded like a
+// *big.Int, with a nil pointer encoded as a sign of -1, followed by the
+// big-endian bytes of the denominator. The value is encoded exactly, so
+// decimal amounts like 0.1 survive a round trip.
+func (e *Encoder) BigRat(arg *big.Rat) {
+	if arg == nil {
+		e.Int8(-1)
+		return
+	}
+	e.BigInt(arg.Num())
+	e.Bytes(arg.Denom().Bytes())
+}
+
+// degreesScale is the number of fixed-precision units per degree used to
+// encode latitudes and longitudes. One unit is 1e-7 degrees, or about 1.1cm at
+// the equator.
+const degreesScale = 10_000_000
+
+// Latitude encodes a latitude, in degrees, as a fixed-precision int32.
+//
+// Panics if the latitude is not in the range [-90, 90].
+func (e *Encoder) Latitude(deg float64) {
+	e.degrees("latitude", deg, 90)
+}
+
+// Longitude encodes a longitude, in degrees, as a fixed-precision int32.
+//
+// Panics if the longitude is not in the range [-180, 180].
+func (e *Encoder) Longitude(deg float64) {
+	e.degrees("longitude", deg, 180)
+}
+
+// degrees encodes deg, rounded to the nearest fixed-precision unit, as an
+// int32. Panics if the rounded value is not in the range [-max, max].
+func (e *Encoder) degrees(kind string, deg float64, max int32) {
+	units := math.Round(deg * degreesScale)
+	limit := float64(max) * degreesScale
+	if !(units >= -limit && units <= limit) { // also rejects NaN
+		panic(makeEncodeError("unable to encode %s %v; expected value in [%d, %d]", kind, deg, -max, max))
+	}
+	e.Int32(int32(units))
+}
+
 // Len attempts to encode l as an int32.
 //
 // Panics if l is bigger than an int32 or a negative length (except -1).
@@ -264,15 +379,25 @@ func (e *Encoder) Len(l int) {
 // registered as serializable.
 //
 // <emulatedError,message,fmtError> for unregistered error types.
+//
+// <retryableHint> marks the error as safe to retry (see Retryable). The hint
+// is followed by the encoding of the wrapped error.
+//
+// <codeHint,code> records the code of a CodedError. The hint is followed by
+// the encoding of the wrapped error.
 const (
 	endOfErrors        uint8 = 0
 	serializedErrorVal uint8 = 1
 	serializedErrorPtr uint8 = 2
 	emulatedError      uint8 = 3
+	retryableHint      uint8 = 4
+	codeHint           uint8 = 5
 )
 
 // Error encodes an arg of type error. We save enough type information
 // to allow errors.Unwrap(), errors.Is(), and errors.As() to work correctly.
+// The encoding is self-delimiting, so errors can be encoded anywhere in a
+// stream, like in the fields of a struct, and a nil error is decoded as nil.
 func (e *Encoder) Error(err error) {
 	// Convert the tree of wrapped errors into a single list.
 	// We do not use errors.Unwrap() since it does not visit the
@@ -295,6 +420,21 @@ func (e *Encoder) Error(err error) {
 		}
 		seen[err] = struct{}{}
 
+		// Record the retryable hint and encode the error it wraps.
+		if r, ok := err.(retryableError); ok {
+			e.Uint8(retryableHint)
+			dfs(r.err)
+			return
+		}
+
+		// Record the code and encode the error it wraps.
+		if c, ok := err.(CodedError); ok {
+			e.Uint8(codeHint)
+			e.Int32(c.code)
+			dfs(c.err)
+			return
+		}
+
 		// If err can be marshaled, do that and skip extracting its children
 		// since serialized form should contain all of them.
 		if am, ok := err.(AutoMarshal); ok {
@@ -330,6 +470,32 @@ func (e *Encoder) Error(err error) {
 	e.Uint8(endOfErrors)
 }
 
+// Any encodes value, which must be nil or of a type registered with
+// RegisterType, as its tag followed by its serialization. nil is encoded as an
+// empty tag.
+func (e *Encoder) Any(value any) {
+	if value == nil {
+		e.String("")
+		return
+	}
+	t := reflect.TypeOf(value)
+	typesMu.Lock()
+	tag, ok := typeTags[t]
+	typesMu.Unlock()
+	if !ok {
+		panic(makeEncodeError("unable to encode value of type %v; register the type with codegen.RegisterType", t))
+	}
+	if t.Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil() {
+		panic(makeEncodeError("unable to encode nil %v", t))
+	}
+	e.String(tag)
+	if am, ok := value.(AutoMarshal); ok {
+		am.WeaverMarshal(e)
+	} else {
+		pointerTo(value).(AutoMarshal).WeaverMarshal(e)
+	}
+}
+
 // Interface encodes value prefixed with its concrete type.
 func (e *Encoder) Interface(value AutoMarshal) {
 	e.String(typeKey(value))
@@ -15,8 +15,12 @@
 package codegen
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"math/rand"
 	"os"
 	"reflect"
@@ -74,6 +78,153 @@ func TestReset(t *testing.T) {
 	}
 }
 
+// TestRelease releases an encoder and gets a new one. Verify that the new
+// encoder is empty.
+func TestRelease(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		enc := NewEncoder()
+		if got := len(enc.Data()); got != 0 {
+			t.Fatalf("len(enc.Data()): got %d, want 0", got)
+		}
+		enc.String("this is garbage text that should not be seen again")
+		enc.Release()
+	}
+}
+
+// BenchmarkGetProduct measures the allocations made when encoding the
+// arguments of a hot method, like a GetProduct(ctx, id, options) method, with
+// and without releasing the encoder.
+func BenchmarkGetProduct(b *testing.B) {
+	const id = "OLJCESPC7Z-a-fairly-long-product-identifier-with-a-category-suffix"
+	encode := func() *Encoder {
+		enc := NewEncoder()
+		enc.Reset(4 + len(id) + 4 + 3*len("currency") + 8)
+		enc.String(id)
+		enc.Len(3)
+		for i := 0; i < 3; i++ {
+			enc.String("currency")
+		}
+		enc.Int64(42)
+		return enc
+	}
+
+	b.Run("NoRelease", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encode()
+		}
+	})
+	b.Run("Release", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encode().Release()
+		}
+	})
+}
+
+// product is a hand-written stand-in for an AutoMarshal struct returned by a
+// hot ListProducts(ctx) ([]Product, error) method.
+type product struct {
+	ID   string
+	Name string
+}
+
+func encodeProducts(enc *Encoder, products []product) {
+	enc.Len(len(products))
+	for _, p := range products {
+		enc.String(p.ID)
+		enc.String(p.Name)
+	}
+}
+
+// decodeProducts decodes a []product the way generated code does.
+func decodeProducts(dec *Decoder) []product {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := MakeSlice[product](dec, n)
+	for i := 0; i < n; i++ {
+		res[i].ID = dec.String()
+		res[i].Name = dec.String()
+	}
+	return res
+}
+
+func TestWithSliceHint(t *testing.T) {
+	want := []product{{"1", "one"}, {"2", "two"}}
+	enc := NewEncoder()
+	encodeProducts(enc, want)
+	data := enc.Data()
+
+	for _, test := range []struct {
+		name   string
+		hint   []product
+		reused bool
+	}{
+		{"NoHint", nil, false},
+		{"Reused", make([]product, 1, 3), true},
+		{"TooSmall", make([]product, 1), false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := WithSliceHint(context.Background(), test.hint)
+			got := decodeProducts(NewResultDecoder(ctx, data))
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("decode (-want +got):\n%s", diff)
+			}
+			reused := cap(test.hint) > 0 && &test.hint[:1][0] == &got[0]
+			if reused != test.reused {
+				t.Fatalf("reused hint: got %t, want %t", reused, test.reused)
+			}
+		})
+	}
+
+	t.Run("ConsumedOnce", func(t *testing.T) {
+		ctx := WithSliceHint(context.Background(), make([]product, 4))
+		dec := NewResultDecoder(ctx, append(append([]byte{}, data...), data...))
+		first, second := decodeProducts(dec), decodeProducts(dec)
+		if &first[0] == &second[0] {
+			t.Fatal("two decoded slices share a backing array")
+		}
+	})
+
+	t.Run("OtherType", func(t *testing.T) {
+		ctx := WithSliceHint(context.Background(), make([]string, 4))
+		if got := decodeProducts(NewResultDecoder(ctx, data)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("decode: got %v, want %v", got, want)
+		}
+	})
+}
+
+// BenchmarkListProducts measures the allocations made when decoding the
+// results of a hot ListProducts method, with and without reusing the slice
+// returned by the previous call.
+func BenchmarkListProducts(b *testing.B) {
+	products := make([]product, 100)
+	for i := range products {
+		products[i] = product{ID: fmt.Sprintf("product-%d", i), Name: "name"}
+	}
+	enc := NewEncoder()
+	encodeProducts(enc, products)
+	data := enc.Data()
+
+	b.Run("NoHint", func(b *testing.B) {
+		b.ReportAllocs()
+		ctx := context.Background()
+		for i := 0; i < b.N; i++ {
+			decodeProducts(NewResultDecoder(ctx, data))
+		}
+	})
+	b.Run("Hint", func(b *testing.B) {
+		b.ReportAllocs()
+		var got []product
+		for i := 0; i < b.N; i++ {
+			ctx := WithSliceHint(context.Background(), got)
+			got = decodeProducts(NewResultDecoder(ctx, data))
+		}
+	})
+}
+
 // TestEncodeDecode encodes a value and then decodes it. Verify that the value
 // is decoded as expected.
 func TestEncodeDecode(t *testing.T) {
@@ -139,7 +290,7 @@ func TestErrorDecUnableToRead(t *testing.T) {
 		enc := newEncoder()
 		enc.Int(12345)
 
-		dec := Decoder{enc.data}
+		dec := Decoder{data: enc.data}
 		dec.Int()
 		dec.Bool()
 	})
@@ -156,7 +307,7 @@ func TestErrorUnableToDecBool(t *testing.T) {
 		enc := newEncoder()
 		enc.Int(123)
 
-		dec := Decoder{enc.data}
+		dec := Decoder{data: enc.data}
 		dec.Bool()
 	})
 	if !strings.Contains(err.Error(), "unable to decode bool") {
@@ -172,7 +323,7 @@ func TestErrorUnableToDecBytes(t *testing.T) {
 		enc := newEncoder()
 		enc.Int(-10)
 
-		dec := Decoder{enc.data}
+		dec := Decoder{data: enc.data}
 		dec.Bytes()
 	})
 	if !strings.Contains(err.Error(), "unable to decode bytes; expected length") {
@@ -180,6 +331,380 @@ func TestErrorUnableToDecBytes(t *testing.T) {
 	}
 }
 
+// TestErrorDecTruncated encodes a string and a slice and attempts to decode
+// them from every proper prefix of the encoding. Verify that a decoding error,
+// rather than a runtime panic, is triggered for every prefix.
+func TestErrorDecTruncated(t *testing.T) {
+	enc := newEncoder()
+	enc.String("hello")
+	enc.Len(3)
+	for _, x := range []int64{1, 2, 3} {
+		enc.Int64(x)
+	}
+	data := enc.Data()
+
+	for i := 0; i < len(data); i++ {
+		err := convertCallPanicToError(func() {
+			dec := NewDecoder(data[:i])
+			_ = dec.String()
+			n := dec.Len()
+			for j := 0; j < n; j++ {
+				dec.Int64()
+			}
+		})
+		if err == nil || !strings.Contains(err.Error(), "unable to read #bytes") {
+			t.Fatalf("prefix of length %d: got %v, want decoding error", i, err)
+		}
+	}
+}
+
+// TestErrorDecOversizedLen encodes a huge length and attempts to decode it
+// with an allocation limit. Verify that a decoding error is triggered.
+func TestErrorDecOversizedLen(t *testing.T) {
+	enc := newEncoder()
+	enc.Len(math.MaxInt32)
+
+	err := convertCallPanicToError(func() {
+		dec := NewDecoder(enc.Data())
+		dec.SetMaxAlloc(MaxServerAlloc)
+		dec.Len()
+	})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the allocation limit") {
+		t.Fatal(err)
+	}
+}
+
+// TestMaxAlloc decodes a number of lengths with an allocation limit. Verify
+// that the limit applies to the total number of elements.
+func TestMaxAlloc(t *testing.T) {
+	enc := newEncoder()
+	enc.Len(-1)
+	enc.Len(0)
+	enc.Len(6)
+	enc.Len(4)
+	enc.Len(1)
+
+	dec := NewDecoder(enc.Data())
+	dec.SetMaxAlloc(10)
+	for _, want := range []int{-1, 0, 6, 4} {
+		if got := dec.Len(); got != want {
+			t.Fatalf("Len: got %d, want %d", got, want)
+		}
+	}
+	err := convertCallPanicToError(func() { dec.Len() })
+	if err == nil || !strings.Contains(err.Error(), "exceeds the allocation limit") {
+		t.Fatal(err)
+	}
+}
+
+// TestBigInt encodes and decodes a number of big integers. Verify that they are
+// decoded as expected.
+func TestBigInt(t *testing.T) {
+	huge, ok := new(big.Int).SetString("123456789012345678901234567890123456789", 10)
+	if !ok {
+		t.Fatal("bad big.Int literal")
+	}
+	for _, x := range []*big.Int{
+		nil,
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(-1),
+		big.NewInt(math.MaxInt64),
+		big.NewInt(math.MinInt64),
+		huge,
+		new(big.Int).Neg(huge),
+	} {
+		t.Run(fmt.Sprint(x), func(t *testing.T) {
+			enc := newEncoder()
+			enc.BigInt(x)
+			dec := Decoder{data: enc.data}
+			got := dec.BigInt()
+			if x == nil {
+				if got != nil {
+					t.Fatalf("BigInt: got %v, want nil", got)
+				}
+			} else if got == nil || got.Cmp(x) != 0 {
+				t.Fatalf("BigInt: got %v, want %v", got, x)
+			}
+			if len(dec.data) > 0 {
+				t.Fatalf("unexpected bytes left to be read: %d", len(dec.data))
+			}
+		})
+	}
+}
+
+// TestErrorUnableToDecBigInt encodes an invalid sign and attempts to decode a
+// big integer. Verify that a decoding error is triggered.
+func TestErrorUnableToDecBigInt(t *testing.T) {
+	err := convertCallPanicToError(func() {
+		enc := newEncoder()
+		enc.Int8(2)
+
+		dec := Decoder{data: enc.data}
+		dec.BigInt()
+	})
+	if !strings.Contains(err.Error(), "unable to decode big.Int") {
+		t.Fatal(err.Error())
+	}
+}
+
+// TestBigRat encodes and decodes a number of big rationals. Verify that they
+// are decoded exactly.
+func TestBigRat(t *testing.T) {
+	huge, ok := new(big.Rat).SetString("-123456789012345678901234567890.123456789")
+	if !ok {
+		t.Fatal("bad big.Rat literal")
+	}
+	for _, x := range []*big.Rat{
+		nil,
+		new(big.Rat),
+		big.NewRat(1, 1),
+		big.NewRat(-1, 3),
+		big.NewRat(1, 10),
+		new(big.Rat).SetFloat64(math.MaxFloat64),
+		huge,
+	} {
+		t.Run(fmt.Sprint(x), func(t *testing.T) {
+			enc := newEncoder()
+			enc.BigRat(x)
+			dec := Decoder{data: enc.data}
+			got := dec.BigRat()
+			if x == nil {
+				if got != nil {
+					t.Fatalf("BigRat: got %v, want nil", got)
+				}
+			} else if got == nil || got.Cmp(x) != 0 {
+				t.Fatalf("BigRat: got %v, want %v", got, x)
+			}
+			if len(dec.data) > 0 {
+				t.Fatalf("unexpected bytes left to be read: %d", len(dec.data))
+			}
+		})
+	}
+}
+
+// TestErrorUnableToDecBigRat encodes a zero denominator and attempts to
+// decode a big rational. Verify that a decoding error is triggered.
+func TestErrorUnableToDecBigRat(t *testing.T) {
+	err := convertCallPanicToError(func() {
+		enc := newEncoder()
+		enc.BigInt(big.NewInt(1))
+		enc.Bytes(nil)
+
+		dec := Decoder{data: enc.data}
+		dec.BigRat()
+	})
+	if !strings.Contains(err.Error(), "unable to decode big.Rat") {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestByteArrays(t *testing.T) {
+	id := [16]byte{0: 1, 7: 0xff, 15: 42}
+	hash := [32]byte{0: 0xaa, 31: 0xbb}
+	enc := newEncoder()
+	enc.Bytes16(id)
+	enc.ByteArray(hash[:])
+
+	// The arrays are encoded as is, without length prefixes.
+	if got, want := len(enc.Data()), 16+32; got != want {
+		t.Fatalf("encoded %d bytes, want %d", got, want)
+	}
+
+	dec := NewDecoder(enc.Data())
+	if got := dec.Bytes16(); got != id {
+		t.Errorf("Bytes16: got %v, want %v", got, id)
+	}
+	var got [32]byte
+	dec.ByteArray(got[:])
+	if got != hash {
+		t.Errorf("ByteArray: got %v, want %v", got, hash)
+	}
+	if !dec.Empty() {
+		t.Errorf("unexpected bytes left to be read: %d", len(dec.data))
+	}
+}
+
+func TestByteSlice(t *testing.T) {
+	for _, zeroCopy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("zerocopy=%t", zeroCopy), func(t *testing.T) {
+			enc := newEncoder()
+			enc.ByteSlice([]byte("hello"))
+			enc.ByteSlice([]byte{})
+			enc.ByteSlice(nil)
+
+			data := enc.Data()
+			dec := NewDecoder(data)
+			if zeroCopy {
+				dec.SetZeroCopy()
+			}
+			hello := dec.ByteSlice()
+			if got, want := string(hello), "hello"; got != want {
+				t.Errorf("ByteSlice: got %q, want %q", got, want)
+			}
+			if got := dec.ByteSlice(); got == nil || len(got) != 0 {
+				t.Errorf("ByteSlice: got %v, want empty slice", got)
+			}
+			if got := dec.ByteSlice(); got != nil {
+				t.Errorf("ByteSlice: got %v, want nil", got)
+			}
+			if !dec.Empty() {
+				t.Errorf("unexpected bytes left to be read: %d", len(dec.data))
+			}
+
+			// A zero-copy slice shares memory with the decoded data, but
+			// can't be appended to in place.
+			data[4] = 'j'
+			if got, want := string(hello) == "jello", zeroCopy; got != want {
+				t.Errorf("ByteSlice: got %q after modifying the data", hello)
+			}
+			if cap(hello) != len(hello) {
+				t.Errorf("ByteSlice: got capacity %d, want %d", cap(hello), len(hello))
+			}
+		})
+	}
+}
+
+// TestByteSliceMaxAlloc decodes a byte slice argument larger than
+// MaxServerAlloc the way a server stub does. Verify that it is decoded, since
+// its length is bounded by the request itself.
+func TestByteSliceMaxAlloc(t *testing.T) {
+	want := make([]byte, MaxServerAlloc+1)
+	want[len(want)-1] = 42
+	enc := newEncoder()
+	enc.ByteSlice(want)
+	enc.Len(1)
+
+	dec := NewDecoder(enc.Data())
+	dec.SetMaxAlloc(MaxServerAlloc)
+	if got := dec.ByteSlice(); !bytes.Equal(got, want) {
+		t.Fatalf("ByteSlice: got %d bytes, want %d bytes", len(got), len(want))
+	}
+	// Byte slices don't count against the allocation limit.
+	if got := dec.Len(); got != 1 {
+		t.Fatalf("Len: got %d, want 1", got)
+	}
+}
+
+// TestByteSliceOversizedLen decodes a byte slice whose length exceeds the
+// remaining data. Verify that a decoding error is triggered.
+func TestByteSliceOversizedLen(t *testing.T) {
+	enc := newEncoder()
+	enc.Len(math.MaxInt32)
+	err := convertCallPanicToError(func() {
+		dec := NewDecoder(enc.Data())
+		dec.SetMaxAlloc(MaxServerAlloc)
+		dec.ByteSlice()
+	})
+	if err == nil || !strings.Contains(err.Error(), "unable to read #bytes") {
+		t.Fatalf("ByteSlice: got error %v, want decoding error", err)
+	}
+}
+
+func TestErrorUnableToDecBytes16(t *testing.T) {
+	err := convertCallPanicToError(func() {
+		enc := newEncoder()
+		enc.ByteArray(make([]byte, 15))
+
+		dec := Decoder{data: enc.data}
+		dec.Bytes16()
+	})
+	if !strings.Contains(err.Error(), "unable to read #bytes: 16") {
+		t.Fatal(err.Error())
+	}
+}
+
+// TestDegrees encodes and decodes a number of latitudes and longitudes. Verify
+// that they are decoded to within the encoding's precision and that decoded
+// values encode back to themselves.
+func TestDegrees(t *testing.T) {
+	for _, test := range []struct {
+		lat, lng float64
+	}{
+		{0, 0},
+		{37.7749295, -122.4194155},
+		{-33.8688197, 151.2092955},
+		{90, 180},
+		{-90, -180},
+		{0.00000004, 179.99999996},   // rounds to 180
+		{-0.00000004, -179.99999996}, // rounds to -180
+		{1e-9, 179.9999999},
+		{-1e-9, -179.9999999},
+	} {
+		t.Run(fmt.Sprintf("%v,%v", test.lat, test.lng), func(t *testing.T) {
+			enc := newEncoder()
+			enc.Latitude(test.lat)
+			enc.Longitude(test.lng)
+			if got, want := len(enc.data), 8; got != want {
+				t.Fatalf("encoded size: got %d, want %d", got, want)
+			}
+			dec := Decoder{data: enc.data}
+			lat, lng := dec.Latitude(), dec.Longitude()
+			const epsilon = 0.5e-7
+			if math.Abs(lat-test.lat) > epsilon || math.Abs(lng-test.lng) > epsilon {
+				t.Fatalf("got %v,%v, want %v,%v", lat, lng, test.lat, test.lng)
+			}
+			if lng < -180 || lng > 180 {
+				t.Fatalf("longitude %v not in [-180, 180]", lng)
+			}
+
+			// Decoded values should round trip exactly.
+			enc = newEncoder()
+			enc.Latitude(lat)
+			enc.Longitude(lng)
+			dec = Decoder{data: enc.data}
+			if gotLat, gotLng := dec.Latitude(), dec.Longitude(); gotLat != lat || gotLng != lng {
+				t.Fatalf("re-encoded: got %v,%v, want %v,%v", gotLat, gotLng, lat, lng)
+			}
+		})
+	}
+}
+
+// TestErrorUnableToEncDegrees attempts to encode out-of-range latitudes and
+// longitudes. Verify that an encoding error is triggered.
+func TestErrorUnableToEncDegrees(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		f    func(*Encoder)
+	}{
+		{"latitude", func(enc *Encoder) { enc.Latitude(90.0000001) }},
+		{"latitude", func(enc *Encoder) { enc.Latitude(math.NaN()) }},
+		{"longitude", func(enc *Encoder) { enc.Longitude(-180.0000001) }},
+		{"longitude", func(enc *Encoder) { enc.Longitude(math.Inf(1)) }},
+	} {
+		err := convertCallPanicToError(func() {
+			enc := newEncoder()
+			test.f(&enc)
+		})
+		if err == nil || !strings.Contains(err.Error(), "unable to encode "+test.name) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// TestErrorUnableToDecDegrees encodes out-of-range integers and attempts to
+// decode a latitude and a longitude. Verify that a decoding error is triggered.
+func TestErrorUnableToDecDegrees(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		units int32
+		f     func(*Decoder)
+	}{
+		{"latitude", 900_000_001, func(dec *Decoder) { dec.Latitude() }},
+		{"longitude", -1_800_000_001, func(dec *Decoder) { dec.Longitude() }},
+	} {
+		err := convertCallPanicToError(func() {
+			enc := newEncoder()
+			enc.Int32(test.units)
+			dec := Decoder{data: enc.data}
+			test.f(&dec)
+		})
+		if err == nil || !strings.Contains(err.Error(), "unable to decode "+test.name) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
 // Some custom error types. There are manually made serializable since we do
 // not want this package to depend on the code generator.
 
@@ -272,6 +797,131 @@ func TestErrorValues(t *testing.T) {
 	}
 }
 
+func TestErrorMidStream(t *testing.T) {
+	// Errors can be encoded between other values, like in struct fields.
+	enc := newEncoder()
+	enc.String("a")
+	enc.Error(nil)
+	enc.Int(1)
+	enc.Error(fmt.Errorf("item 2: %w", os.ErrNotExist))
+	enc.Int(2)
+
+	dec := Decoder{data: enc.data}
+	if got, want := dec.String(), "a"; got != want {
+		t.Fatalf("String: got %q, want %q", got, want)
+	}
+	if err := dec.Error(); err != nil {
+		t.Fatalf("Error: got %v, want nil", err)
+	}
+	if got, want := dec.Int(), 1; got != want {
+		t.Fatalf("Int: got %d, want %d", got, want)
+	}
+	if err := dec.Error(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Error: got %v, want an error wrapping %v", err, os.ErrNotExist)
+	}
+	if got, want := dec.Int(), 2; got != want {
+		t.Fatalf("Int: got %d, want %d", got, want)
+	}
+	if !dec.Empty() {
+		t.Fatalf("leftover bytes in decoder")
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		val       error
+		retryable bool
+	}{
+		{"flat", errors.New("hello"), false},
+		{"retryable", Retryable(os.ErrNotExist), true},
+		{"wrapped", fmt.Errorf("hello %w", Retryable(os.ErrNotExist)), true},
+		{"custom", Retryable(customTestError{"a"}), true},
+		{"joined", errors.Join(os.ErrClosed, Retryable(os.ErrNotExist)), true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			enc := newEncoder()
+			enc.Error(c.val)
+			dec := Decoder{data: enc.data}
+			dst := dec.Error()
+			if !dec.Empty() {
+				t.Fatalf("leftover bytes in decoder")
+			}
+			if got := IsRetryable(dst); got != c.retryable {
+				t.Errorf("IsRetryable(%v): got %t, want %t", dst, got, c.retryable)
+			}
+			if c.retryable && !errors.Is(dst, os.ErrNotExist) && !errors.Is(dst, customTestError{"a"}) {
+				t.Errorf("decoded error (%v) does not match the wrapped error", dst)
+			}
+		})
+	}
+}
+
+func TestCodedError(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		val   error
+		coded bool
+		code  int32
+	}{
+		{"flat", errors.New("hello"), false, 0},
+		{"coded", WithCode(42, os.ErrNotExist), true, 42},
+		{"wrapped", fmt.Errorf("hello %w", WithCode(42, os.ErrNotExist)), true, 42},
+		{"custom", WithCode(-1, customTestError{"a"}), true, -1},
+		{"joined", errors.Join(os.ErrClosed, WithCode(42, os.ErrNotExist)), true, 42},
+		{"nested", WithCode(1, fmt.Errorf("hello %w", WithCode(2, os.ErrNotExist))), true, 1},
+		{"retryable", Retryable(WithCode(42, os.ErrNotExist)), true, 42},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			enc := newEncoder()
+			enc.Error(c.val)
+			dec := Decoder{data: enc.data}
+			dst := dec.Error()
+			if !dec.Empty() {
+				t.Fatalf("leftover bytes in decoder")
+			}
+			var coded CodedError
+			if got := errors.As(dst, &coded); got != c.coded {
+				t.Fatalf("errors.As(%v, CodedError): got %t, want %t", dst, got, c.coded)
+			}
+			if !c.coded {
+				return
+			}
+			if got := coded.Code(); got != c.code {
+				t.Errorf("Code(): got %d, want %d", got, c.code)
+			}
+			if !errors.Is(dst, os.ErrNotExist) && !errors.Is(dst, customTestError{"a"}) {
+				t.Errorf("decoded error (%v) does not match the wrapped error", dst)
+			}
+		})
+	}
+}
+
+func TestErrorHintsWithoutErrors(t *testing.T) {
+	// Hints that don't wrap any error are decoded as a nil error.
+	for _, c := range []struct {
+		name   string
+		encode func(enc *Encoder)
+	}{
+		{"retryable", func(enc *Encoder) { enc.Uint8(retryableHint) }},
+		{"coded", func(enc *Encoder) { enc.Uint8(codeHint); enc.Int32(42) }},
+		{"both", func(enc *Encoder) { enc.Uint8(retryableHint); enc.Uint8(codeHint); enc.Int32(42) }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			enc := newEncoder()
+			c.encode(&enc)
+			enc.Uint8(endOfErrors)
+			dec := Decoder{data: enc.data}
+			if err := dec.Error(); err != nil {
+				t.Errorf("Error(): got %v, want nil", err)
+			}
+			if !dec.Empty() {
+				t.Fatalf("leftover bytes in decoder")
+			}
+		})
+	}
+}
+
 func TestCyclicError(t *testing.T) {
 	// Special test for cyclic errors since errors.Is etc. can get
 	// into an infinite loop on cycles.
@@ -293,6 +943,90 @@ func TestCyclicError(t *testing.T) {
 	}
 }
 
+type refund struct{ amount int }
+
+func (r *refund) WeaverMarshal(e *Encoder)   { e.Int(r.amount) }
+func (r *refund) WeaverUnmarshal(d *Decoder) { r.amount = d.Int() }
+
+type transfer struct{ to string }
+
+func (t *transfer) WeaverMarshal(e *Encoder)   { e.String(t.to) }
+func (t *transfer) WeaverUnmarshal(d *Decoder) { t.to = d.String() }
+
+func init() {
+	RegisterType("refund", reflect.TypeOf(refund{}))
+	RegisterType("transfer", reflect.TypeOf(&transfer{}))
+}
+
+// TestAny encodes and decodes values of registered types. Verify that they are
+// decoded with their original types.
+func TestAny(t *testing.T) {
+	for _, want := range []any{nil, refund{42}, &transfer{"alice"}} {
+		t.Run(fmt.Sprintf("%T", want), func(t *testing.T) {
+			enc := newEncoder()
+			enc.Any(want)
+			dec := Decoder{data: enc.data}
+			got := dec.Any()
+			if !dec.Empty() {
+				t.Fatalf("leftover bytes in decoder")
+			}
+			if diff := cmp.Diff(want, got, cmp.AllowUnexported(refund{}, transfer{})); diff != "" {
+				t.Fatalf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestErrorUnableToEncAny encodes a value of a type that isn't registered.
+// Verify that an encoding error is triggered.
+func TestErrorUnableToEncAny(t *testing.T) {
+	err := convertCallPanicToError(func() {
+		enc := newEncoder()
+		enc.Any(transfer{"alice"}) // only *transfer is registered
+	})
+	if err == nil || !strings.Contains(err.Error(), "register the type with codegen.RegisterType") {
+		t.Fatal(err)
+	}
+}
+
+// TestErrorUnableToDecAny encodes an unknown tag and attempts to decode a
+// value. Verify that a decoding error is triggered.
+func TestErrorUnableToDecAny(t *testing.T) {
+	err := convertCallPanicToError(func() {
+		enc := newEncoder()
+		enc.String("unknown")
+
+		dec := Decoder{data: enc.data}
+		dec.Any()
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown type tag "unknown"`) {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterTypeErrors(t *testing.T) {
+	type notSerializable struct{}
+	for _, test := range []struct {
+		name string
+		tag  string
+		t    reflect.Type
+	}{
+		{"EmptyTag", "", reflect.TypeOf(refund{})},
+		{"NotSerializable", "other", reflect.TypeOf(notSerializable{})},
+		{"DuplicateTag", "refund", reflect.TypeOf(&transfer{})},
+		{"DuplicateType", "other", reflect.TypeOf(refund{})},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("RegisterType: unexpected success")
+				}
+			}()
+			RegisterType(test.tag, test.t)
+		})
+	}
+}
+
 // encode serializes args using the encoder enc.
 func encode(enc *Encoder, args []interface{}) {
 	for _, elem := range args {
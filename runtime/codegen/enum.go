@@ -0,0 +1,24 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+// InvalidEnumError returns a decoding error for a value v of the provided
+// enum type that is not equal to any of the enum's constants. The returned
+// error should be panicked and later caught by CatchPanics.
+//
+// NOTE that this function should be called only in the generated code.
+func InvalidEnumError(enum string, v any) error {
+	return makeDecodeError("unable to decode %s: invalid value %d", enum, v)
+}
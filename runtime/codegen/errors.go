@@ -15,11 +15,19 @@
 package codegen
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ServiceWeaver/weaver/runtime/retry"
 )
 
 // CatchPanics recovers from panic() calls that occur during encoding,
-// decoding, and RPC execution.
+// decoding, and RPC execution. The returned error records the stack of the
+// panic, which can be retrieved with PanicStack.
 func CatchPanics(r interface{}) error {
 	if r == nil {
 		return nil
@@ -29,7 +37,204 @@ func CatchPanics(r interface{}) error {
 		panic(r)
 	}
 	if errors.As(err, &encoderError{}) || errors.As(err, &decoderError{}) {
-		return err
+		// Note that CatchPanics is called by a deferred function while the
+		// goroutine is panicking, so the stack includes the panic site.
+		return &panicError{err: err, stack: debug.Stack()}
 	}
 	panic(r)
 }
+
+// panicError is an error recovered by CatchPanics, along with the stack of the
+// panic. The stack is not part of the error message, and is therefore never
+// sent to a remote caller. panicError is always used as a pointer, since
+// errors may be used as map keys (e.g., by Encoder.Error) and a []byte is not
+// comparable.
+type panicError struct {
+	err   error
+	stack []byte
+}
+
+// Error implements the error interface.
+func (e *panicError) Error() string { return e.err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e *panicError) Unwrap() error { return e.err }
+
+// PanicStack returns the stack of the panic that err was recovered from by
+// CatchPanics, or nil if err was not recovered from a panic.
+func PanicStack(err error) []byte {
+	var p *panicError
+	if errors.As(err, &p) {
+		return p.stack
+	}
+	return nil
+}
+
+// PanicInfo describes a panic recovered by a server stub. See
+// weaver.PanicInfo.
+type PanicInfo struct {
+	Component string // full component name
+	Method    string // method name
+	Value     any    // value passed to panic
+	Stack     []byte // stack of the panicking goroutine
+
+	// Args holds the arguments of the call, excluding the context, with the
+	// variadic arguments, if any, as a single slice. Arguments that were not
+	// decoded when the panic happened hold their zero value.
+	Args []any
+}
+
+// panicHandlers holds the registered panic handlers. It is nil if no handlers
+// were ever registered, so that server stubs can check for handlers without
+// locking.
+var panicHandlers atomic.Pointer[[]func(PanicInfo)]
+
+// panicHandlersMu serializes calls to RegisterPanicHandler.
+var panicHandlersMu sync.Mutex
+
+// RegisterPanicHandler registers a handler that is called with every panic
+// recovered by a server stub.
+func RegisterPanicHandler(f func(PanicInfo)) {
+	panicHandlersMu.Lock()
+	defer panicHandlersMu.Unlock()
+	var handlers []func(PanicInfo)
+	if old := panicHandlers.Load(); old != nil {
+		handlers = append(handlers, *old...)
+	}
+	handlers = append(handlers, f)
+	panicHandlers.Store(&handlers)
+}
+
+// CatchServerPanics is like CatchPanics, but is called by the server stub of
+// the provided method, and reports the recovered value, if any, along with
+// the arguments of the call, to the registered panic handlers before handling
+// it like CatchPanics. The handlers are called even if the panic is not
+// recovered from, i.e., before the panic is resumed.
+func CatchServerPanics(component, method string, r any, args ...any) error {
+	if r == nil {
+		return nil
+	}
+	if handlers := panicHandlers.Load(); handlers != nil {
+		info := PanicInfo{
+			Component: component,
+			Method:    method,
+			Value:     r,
+			Stack:     debug.Stack(),
+			Args:      args,
+		}
+		for _, f := range *handlers {
+			f(info)
+		}
+	}
+	return CatchPanics(r)
+}
+
+// retryableError is an error that is safe to retry. See weaver.Retryable.
+type retryableError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e retryableError) Error() string { return e.err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e retryableError) Unwrap() error { return e.err }
+
+// Retryable returns an error that wraps err and marks it as safe to retry.
+// Retryable returns nil if err is nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err}
+}
+
+// IsRetryable returns whether err, or any error it wraps, has been marked as
+// safe to retry by Retryable.
+func IsRetryable(err error) bool {
+	var r retryableError
+	return errors.As(err, &r)
+}
+
+// CodedError is an error that carries an application-defined code. See
+// weaver.WithCode.
+type CodedError struct {
+	code int32
+	err  error
+}
+
+// WithCode returns an error that wraps err and carries the provided code.
+// WithCode returns nil if err is nil.
+func WithCode(code int32, err error) error {
+	if err == nil {
+		return nil
+	}
+	return CodedError{code, err}
+}
+
+// Code returns the code of the error.
+func (e CodedError) Code() int32 { return e.code }
+
+// Error implements the error interface.
+func (e CodedError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("error with code %d", e.code)
+	}
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e CodedError) Unwrap() error { return e.err }
+
+// maxRetryableAttempts is the maximum number of times a client stub calls a
+// method that keeps returning retryable errors.
+const maxRetryableAttempts = 5
+
+// A Retrier decides whether a client stub should retry a remote method call
+// that returned an application error. The zero value is ready to use.
+type Retrier struct {
+	r        *retry.Retry
+	attempts int
+}
+
+// Retry returns whether the call that returned err should be retried. A call
+// is retried, with exponential backoff, if err is marked as retryable and the
+// call has not been attempted too many times already. Retry returns false if
+// ctx becomes done while backing off.
+func (r *Retrier) Retry(ctx context.Context, err error) bool {
+	r.attempts++
+	if r.attempts >= maxRetryableAttempts || !IsRetryable(err) {
+		return false
+	}
+	if r.r == nil {
+		r.r = retry.Begin()
+		r.r.Continue(ctx) // the first call to Continue does not back off
+	}
+	return r.r.Continue(ctx)
+}
+
+// PayloadTooLargeError is returned by a remote call whose request or reply is
+// larger than the limit configured for the callee. See
+// weaver.PayloadTooLargeError.
+var PayloadTooLargeError = errors.New("Service Weaver payload is too large")
+
+// CheckPayloadSize returns an error that wraps PayloadTooLargeError if a
+// payload of n bytes exceeds limit, or nil otherwise. A non-positive limit
+// means that payloads are unlimited. what describes the payload, e.g.,
+// "request of Catalog.ListProducts".
+func CheckPayloadSize(what string, n, limit int) error {
+	if limit > 0 && n > limit {
+		return fmt.Errorf("%s is %d bytes, over the limit of %d bytes: %w", what, n, limit, PayloadTooLargeError)
+	}
+	return nil
+}
+
+// UnknownMethodError is returned by a call to a component method that the
+// callee doesn't have. See weaver.UnknownMethodError.
+var UnknownMethodError = errors.New("Service Weaver method is unknown")
+
+// UnknownMethod returns a retryable error that wraps UnknownMethodError,
+// reporting that the provided component has no method with the provided name.
+func UnknownMethod(component, method string) error {
+	return Retryable(fmt.Errorf("component %q has no method %q: %w", component, method, UnknownMethodError))
+}
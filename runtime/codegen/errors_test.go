@@ -0,0 +1,123 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCatchPanicsStack(t *testing.T) {
+	err := convertCallPanicToError(func() {
+		dec := NewDecoder(nil)
+		dec.Int()
+	})
+	if !errors.As(err, &decoderError{}) {
+		t.Fatalf("got %v, want a decoder error", err)
+	}
+	stack := string(PanicStack(err))
+	if !strings.Contains(stack, "TestCatchPanicsStack") {
+		t.Errorf("stack does not contain the panic site:\n%s", stack)
+	}
+	if strings.Contains(err.Error(), "TestCatchPanicsStack") {
+		t.Errorf("error message contains the stack: %v", err)
+	}
+}
+
+func TestEncodeCaughtPanic(t *testing.T) {
+	// A recovered panic can be returned, and wrapped, by a component method,
+	// so it must be encodable as an error.
+	err := convertCallPanicToError(func() {
+		dec := NewDecoder(nil)
+		dec.Int()
+	})
+	enc := NewEncoder()
+	enc.Error(fmt.Errorf("wrapped: %w", err))
+	got := NewDecoder(enc.Data()).Error()
+	if got == nil || !strings.Contains(got.Error(), err.Error()) {
+		t.Errorf("got %v, want an error containing %v", got, err)
+	}
+}
+
+func TestPanicStackNoPanic(t *testing.T) {
+	if stack := PanicStack(errors.New("not a panic")); stack != nil {
+		t.Errorf("got stack %s, want nil", stack)
+	}
+	if stack := PanicStack(nil); stack != nil {
+		t.Errorf("got stack %s, want nil", stack)
+	}
+}
+
+func TestCatchServerPanics(t *testing.T) {
+	const component = "TestCatchServerPanics/foo"
+	var infos []PanicInfo
+	RegisterPanicHandler(func(info PanicInfo) {
+		if info.Component == component {
+			infos = append(infos, info)
+		}
+	})
+
+	// A panic while decoding is returned as an error.
+	serve := func() (err error) {
+		defer func() {
+			if err == nil {
+				err = CatchServerPanics(component, "Decode", recover())
+			}
+		}()
+		dec := NewDecoder(nil)
+		dec.Int()
+		return nil
+	}
+	if err := serve(); !errors.As(err, &decoderError{}) {
+		t.Fatalf("got %v, want a decoder error", err)
+	}
+
+	// A panic in the method is resumed, after it is reported.
+	func() {
+		defer func() {
+			if r := recover(); r != "oops" {
+				t.Errorf("recovered %v, want oops", r)
+			}
+		}()
+		defer func() {
+			CatchServerPanics(component, "Method", recover(), "arg", []int{1, 2})
+		}()
+		panic("oops")
+	}()
+
+	if got, want := len(infos), 2; got != want {
+		t.Fatalf("got %d panics, want %d", got, want)
+	}
+	if got, want := infos[0].Method, "Decode"; got != want {
+		t.Errorf("method: got %q, want %q", got, want)
+	}
+	if err, ok := infos[0].Value.(error); !ok || !errors.As(err, &decoderError{}) {
+		t.Errorf("value: got %v, want a decoder error", infos[0].Value)
+	}
+	if got, want := infos[1].Method, "Method"; got != want {
+		t.Errorf("method: got %q, want %q", got, want)
+	}
+	if got, want := infos[1].Value, "oops"; got != want {
+		t.Errorf("value: got %v, want %v", got, want)
+	}
+	if got, want := fmt.Sprint(infos[1].Args), "[arg [1 2]]"; got != want {
+		t.Errorf("args: got %s, want %s", got, want)
+	}
+	if stack := string(infos[1].Stack); !strings.Contains(stack, "TestCatchServerPanics") {
+		t.Errorf("stack does not contain the panic site:\n%s", stack)
+	}
+}
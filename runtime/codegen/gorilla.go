@@ -0,0 +1,233 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Gorilla encoding
+//
+// Struct fields tagged with `weaver:"gorilla"` hold time series: slices of
+// samples, each with an int64 timestamp and a float64 value. They are
+// compressed using the scheme described in "Gorilla: A Fast, Scalable,
+// In-Memory Time Series Database" (VLDB 2015). A time series is encoded as its
+// length (-1 for a nil slice) followed, if it is not empty, by a bit stream:
+//
+//   - The first timestamp and value are written in full (64 bits each).
+//   - Every subsequent timestamp is written as the delta of its delta from
+//     the previous timestamp. A delta-of-delta of 0, which is common for
+//     regularly spaced samples, takes a single bit. Small deltas-of-deltas
+//     take 9, 12, or 16 bits, and all others take 68 bits.
+//   - Every subsequent value is XORed with the previous value. An XOR of 0
+//     takes a single bit. Otherwise, only the meaningful bits of the XOR (i.e.
+//     without its leading and trailing zeroes) are written, reusing the
+//     previous leading and trailing zero counts when possible.
+//
+// Timestamps of regularly spaced samples with slowly changing values compress
+// to a small fraction of their plain encoding.
+
+// EncodeGorilla encodes the time series s into enc using Gorilla compression.
+// sample returns the timestamp and value of an individual element of s.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeGorilla[T any](enc *Encoder, s []T, sample func(T) (int64, float64)) {
+	if s == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(s))
+	if len(s) == 0 {
+		return
+	}
+
+	var w bitWriter
+	t, v := sample(s[0])
+	w.writeBits(uint64(t), 64)
+	w.writeBits(math.Float64bits(v), 64)
+
+	prevT, prevDelta := t, int64(0)
+	prevV := math.Float64bits(v)
+	prevLeading, prevTrailing := -1, 0
+	for _, x := range s[1:] {
+		t, v := sample(x)
+
+		// Encode the delta-of-delta of the timestamp.
+		delta := t - prevT
+		dod := zigzag(delta - prevDelta)
+		switch {
+		case dod == 0:
+			w.writeBits(0b0, 1)
+		case dod < 1<<7:
+			w.writeBits(0b10, 2)
+			w.writeBits(dod, 7)
+		case dod < 1<<9:
+			w.writeBits(0b110, 3)
+			w.writeBits(dod, 9)
+		case dod < 1<<12:
+			w.writeBits(0b1110, 4)
+			w.writeBits(dod, 12)
+		default:
+			w.writeBits(0b1111, 4)
+			w.writeBits(dod, 64)
+		}
+		prevT, prevDelta = t, delta
+
+		// Encode the XOR of the value with the previous value.
+		bits64 := math.Float64bits(v)
+		xor := bits64 ^ prevV
+		prevV = bits64
+		if xor == 0 {
+			w.writeBits(0b0, 1)
+			continue
+		}
+		leading := min(bits.LeadingZeros64(xor), 31)
+		trailing := bits.TrailingZeros64(xor)
+		if prevLeading >= 0 && leading >= prevLeading && trailing >= prevTrailing {
+			// The meaningful bits fit in the previous window.
+			w.writeBits(0b10, 2)
+			w.writeBits(xor>>prevTrailing, 64-prevLeading-prevTrailing)
+			continue
+		}
+		// Write a new window: 5 bits of leading zeroes and 6 bits of
+		// meaningful bits, where 64 meaningful bits are written as 0.
+		meaningful := 64 - leading - trailing
+		w.writeBits(0b11, 2)
+		w.writeBits(uint64(leading), 5)
+		w.writeBits(uint64(meaningful&63), 6)
+		w.writeBits(xor>>trailing, meaningful)
+		prevLeading, prevTrailing = leading, trailing
+	}
+	enc.Bytes(w.buf)
+}
+
+// DecodeGorilla decodes a time series that was encoded using EncodeGorilla.
+// sample constructs an individual element of the time series from its
+// timestamp and value.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeGorilla[T any](dec *Decoder, sample func(int64, float64) T) []T {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]T, n)
+	if n == 0 {
+		return res
+	}
+
+	r := bitReader{buf: dec.Bytes()}
+	t := int64(r.readBits(64))
+	v := r.readBits(64)
+	res[0] = sample(t, math.Float64frombits(v))
+
+	var delta int64
+	leading, trailing := -1, 0
+	for i := 1; i < n; i++ {
+		// Decode the timestamp.
+		var dod uint64
+		switch {
+		case r.readBits(1) == 0:
+		case r.readBits(1) == 0:
+			dod = r.readBits(7)
+		case r.readBits(1) == 0:
+			dod = r.readBits(9)
+		case r.readBits(1) == 0:
+			dod = r.readBits(12)
+		default:
+			dod = r.readBits(64)
+		}
+		delta += unzigzag(dod)
+		t += delta
+
+		// Decode the value.
+		if r.readBits(1) == 1 {
+			if r.readBits(1) == 1 {
+				leading = int(r.readBits(5))
+				meaningful := int(r.readBits(6))
+				if meaningful == 0 {
+					meaningful = 64
+				}
+				trailing = 64 - leading - meaningful
+				if trailing < 0 {
+					panic(makeDecodeError("invalid gorilla window at index %d of %d", i, n))
+				}
+			} else if leading < 0 {
+				panic(makeDecodeError("missing gorilla window at index %d of %d", i, n))
+			}
+			v ^= r.readBits(64-leading-trailing) << trailing
+		}
+		res[i] = sample(t, math.Float64frombits(v))
+	}
+	return res
+}
+
+// zigzag maps signed integers to unsigned integers so that numbers with a
+// small absolute value have a small encoding: 0, -1, 1, -2, 2, ... are mapped
+// to 0, 1, 2, 3, 4, ...
+func zigzag(x int64) uint64 {
+	return uint64(x<<1) ^ uint64(x>>63)
+}
+
+// unzigzag is the inverse of zigzag.
+func unzigzag(x uint64) int64 {
+	return int64(x>>1) ^ -int64(x&1)
+}
+
+// bitWriter writes a stream of bits, most significant bit first.
+type bitWriter struct {
+	buf  []byte
+	free int // number of unused bits in the last byte of buf
+}
+
+// writeBits writes the n least significant bits of x.
+func (w *bitWriter) writeBits(x uint64, n int) {
+	for n > 0 {
+		if w.free == 0 {
+			w.buf = append(w.buf, 0)
+			w.free = 8
+		}
+		k := min(n, w.free)
+		b := byte(x>>(n-k)) & byte(1<<k-1)
+		w.buf[len(w.buf)-1] |= b << (w.free - k)
+		w.free -= k
+		n -= k
+	}
+}
+
+// bitReader reads a stream of bits written by a bitWriter.
+type bitReader struct {
+	buf []byte
+	pos int // index of the next bit to read
+}
+
+// readBits reads n bits.
+func (r *bitReader) readBits(n int) uint64 {
+	var x uint64
+	for n > 0 {
+		i := r.pos / 8
+		if i >= len(r.buf) {
+			panic(makeDecodeError("unable to decode gorilla stream: out of bits"))
+		}
+		avail := 8 - r.pos%8
+		k := min(n, avail)
+		b := (r.buf[i] >> (avail - k)) & byte(1<<k-1)
+		x = x<<k | uint64(b)
+		r.pos += k
+		n -= k
+	}
+	return x
+}
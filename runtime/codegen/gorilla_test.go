@@ -0,0 +1,142 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sample is a time series sample.
+type sample struct {
+	t int64
+	v float64
+}
+
+func encodeGorilla(s []sample) []byte {
+	enc := NewEncoder()
+	EncodeGorilla(enc, s, func(x sample) (int64, float64) { return x.t, x.v })
+	return enc.Data()
+}
+
+func decodeGorilla(data []byte) []sample {
+	return DecodeGorilla(NewDecoder(data), func(t int64, v float64) sample { return sample{t, v} })
+}
+
+// encodePlain encodes s without compression, like the generated code does for
+// a slice of structs.
+func encodePlain(s []sample) []byte {
+	enc := NewEncoder()
+	enc.Len(len(s))
+	for _, x := range s {
+		enc.Int64(x.t)
+		enc.Float64(x.v)
+	}
+	return enc.Data()
+}
+
+// regular returns a time series of n samples taken every 15 seconds, with
+// slowly changing values.
+func regular(n int) []sample {
+	s := make([]sample, n)
+	for i := range s {
+		s[i] = sample{t: 1700000000000 + int64(i)*15000, v: 42 + float64(i%4)*0.5}
+	}
+	return s
+}
+
+// TestGorillaRoundTrip encodes and decodes a number of time series. Verify
+// that the time series are decoded exactly.
+func TestGorillaRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	random := make([]sample, 1000)
+	for i := range random {
+		random[i] = sample{t: r.Int63() - r.Int63(), v: r.NormFloat64() * 1e6}
+	}
+	jittered := regular(1000)
+	for i := range jittered {
+		jittered[i].t += int64(r.Intn(2000)) - 1000
+	}
+
+	for _, test := range []struct {
+		name string
+		s    []sample
+	}{
+		{"Nil", nil},
+		{"Empty", []sample{}},
+		{"Single", []sample{{42, 3.14}}},
+		{"Regular", regular(1000)},
+		{"Jittered", jittered},
+		{"Random", random},
+		{"Constant", []sample{{1, 7}, {2, 7}, {3, 7}, {4, 7}}},
+		{"Decreasing", []sample{{100, 1}, {50, 2}, {-50, 3}, {-1000, 4}}},
+		{"Extremes", []sample{
+			{math.MinInt64, math.Inf(-1)},
+			{math.MaxInt64, math.Inf(1)},
+			{math.MinInt64, math.NaN()},
+			{0, math.Copysign(0, -1)},
+			{math.MaxInt64, math.MaxFloat64},
+			{1, math.SmallestNonzeroFloat64},
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := decodeGorilla(encodeGorilla(test.s))
+			if (got == nil) != (test.s == nil) {
+				t.Fatalf("got nil = %t, want nil = %t", got == nil, test.s == nil)
+			}
+			if len(got) != len(test.s) {
+				t.Fatalf("got %d samples, want %d", len(got), len(test.s))
+			}
+			for i := range got {
+				want := test.s[i]
+				if got[i].t != want.t || math.Float64bits(got[i].v) != math.Float64bits(want.v) {
+					t.Fatalf("sample %d: got %v, want %v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestGorillaSize checks that Gorilla compression shrinks a regular time
+// series well below its plain encoding.
+func TestGorillaSize(t *testing.T) {
+	s := regular(1000)
+	gorilla, plain := len(encodeGorilla(s)), len(encodePlain(s))
+	t.Logf("gorilla: %d bytes, plain: %d bytes", gorilla, plain)
+	if gorilla*8 > plain {
+		t.Fatalf("gorilla encoding is %d bytes, want at most 1/8 of %d bytes", gorilla, plain)
+	}
+}
+
+// TestGorillaTruncated checks that decoding a truncated time series fails.
+func TestGorillaTruncated(t *testing.T) {
+	data := encodeGorilla(regular(100))
+	enc := NewEncoder()
+	enc.Len(100)
+	dec := NewDecoder(data)
+	dec.Len()
+	stream := dec.Bytes()
+	enc.Bytes(stream[:len(stream)/2])
+
+	err := func() (err error) {
+		defer func() { err = CatchPanics(recover()) }()
+		decodeGorilla(enc.Data())
+		return nil
+	}()
+	if err == nil {
+		t.Fatal("unexpected success decoding a truncated time series")
+	}
+}
@@ -36,7 +36,12 @@ type Hasher struct {
 // The resulting is in the range [1,2^64-2], i.e., it is never 0 or math.MaxUint64.
 func (h *Hasher) Sum64() uint64 {
 	bytes := sha256.Sum256(h.enc.Data())
-	hash := binary.LittleEndian.Uint64(bytes[:8])
+	return ClampShardKey(binary.LittleEndian.Uint64(bytes[:8]))
+}
+
+// ClampShardKey returns the provided hash, clamped to the range [1,2^64-2] of
+// valid shard keys.
+func ClampShardKey(hash uint64) uint64 {
 	if hash == 0 {
 		// We avoid using a hash of 0 so that a default value of 0 can be
 		// interpreted as an invalid or missing hash.
@@ -14,7 +14,10 @@
 
 package codegen
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestHash(t *testing.T) {
 	// Test that the Hash function is deterministic and handles all supported types.
@@ -45,3 +48,17 @@ func TestHash(t *testing.T) {
 		t.Errorf("unstable hash value %016x (expecting %016x)", a, expected)
 	}
 }
+
+func TestClampShardKey(t *testing.T) {
+	for _, test := range []struct{ hash, want uint64 }{
+		{0, 1},
+		{1, 1},
+		{42, 42},
+		{math.MaxUint64 - 1, math.MaxUint64 - 1},
+		{math.MaxUint64, math.MaxUint64 - 1},
+	} {
+		if got := ClampShardKey(test.hash); got != test.want {
+			t.Errorf("ClampShardKey(%d): got %d, want %d", test.hash, got, test.want)
+		}
+	}
+}
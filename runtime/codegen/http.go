@@ -0,0 +1,330 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HTTPMethod is a component method served by a JSON-over-HTTP gateway
+// generated by "weaver generate -http". It decodes the JSON-encoded arguments,
+// calls the method, and returns the method's results, to be encoded in the
+// content type negotiated with the client.
+type HTTPMethod func(ctx context.Context, args []json.RawMessage) (HTTPResult, error)
+
+// HTTPResult holds the results of a method served by an HTTPHandler.
+type HTTPResult struct {
+	// Value is the method's result if it has one, a []any of its results if
+	// it has more than one, or nil if it has none.
+	Value any
+
+	// Encode, if not nil, encodes the method's results, in order, using
+	// Service Weaver's binary serialization format.
+	Encode func(*Encoder)
+}
+
+const (
+	// JSONContentType is the content type of HTTP responses that hold the
+	// JSON encoding of HTTPResult.Value. It is the default content type.
+	JSONContentType = "application/json"
+
+	// BinaryContentType is the content type of HTTP responses that hold
+	// method results encoded by HTTPResult.Encode.
+	BinaryContentType = "application/x-serviceweaver"
+)
+
+// An HTTPCodec encodes the results of the methods served by an HTTPHandler in
+// a particular content type. See RegisterHTTPCodec.
+type HTTPCodec interface {
+	Encode(HTTPResult) ([]byte, error)
+}
+
+// HTTPCodecFunc is an adapter that allows the use of an ordinary function as
+// an HTTPCodec.
+type HTTPCodecFunc func(HTTPResult) ([]byte, error)
+
+// Encode implements the HTTPCodec interface.
+func (f HTTPCodecFunc) Encode(result HTTPResult) ([]byte, error) {
+	return f(result)
+}
+
+// httpCodec is an HTTPCodec registered for a content type.
+type httpCodec struct {
+	contentType string
+	codec       HTTPCodec
+}
+
+// httpCodecs holds the registered codecs, in order of registration. The JSON
+// codec is always first.
+var httpCodecs = struct {
+	mu     sync.RWMutex
+	codecs []httpCodec
+}{
+	codecs: []httpCodec{
+		{JSONContentType, HTTPCodecFunc(func(result HTTPResult) ([]byte, error) {
+			return json.Marshal(result.Value)
+		})},
+		{BinaryContentType, HTTPCodecFunc(func(result HTTPResult) ([]byte, error) {
+			enc := NewEncoder()
+			if result.Encode != nil {
+				result.Encode(enc)
+			}
+			return enc.Data(), nil
+		})},
+	},
+}
+
+// RegisterHTTPCodec registers the codec that HTTPHandler uses to encode the
+// results of a method for clients that accept the provided content type, e.g.,
+// "application/msgpack". It replaces any codec previously registered for the
+// content type, including the builtin JSON and binary codecs. Panics if the
+// content type is not a valid media type.
+//
+// HTTPHandler picks the registered content type that a client prefers, based
+// on the request's Accept header. Requests with no Accept header get JSON.
+func RegisterHTTPCodec(contentType string, codec HTTPCodec) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.Contains(mediaType, "/") || strings.Contains(mediaType, "*") {
+		panic(fmt.Sprintf("RegisterHTTPCodec: invalid content type %q", contentType))
+	}
+	httpCodecs.mu.Lock()
+	defer httpCodecs.mu.Unlock()
+	for i, c := range httpCodecs.codecs {
+		if c.contentType == mediaType {
+			httpCodecs.codecs[i].codec = codec
+			return
+		}
+	}
+	httpCodecs.codecs = append(httpCodecs.codecs, httpCodec{mediaType, codec})
+}
+
+// mediaRange is a media range, e.g., "application/*", in an Accept header.
+type mediaRange struct {
+	typ, subtype string  // e.g., "application" and "*"
+	q            float64 // quality, between 0 and 1
+}
+
+// parseAccept parses the media ranges in an Accept header. Malformed media
+// ranges are ignored.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ, subtype, q})
+	}
+	return ranges
+}
+
+// quality returns the quality that the provided media ranges assign to the
+// provided content type, along with the index of the most specific media range
+// that matches it. It returns false if no media range matches.
+func quality(ranges []mediaRange, contentType string) (float64, int, bool) {
+	typ, subtype, _ := strings.Cut(contentType, "/")
+	best, specificity := -1, -1
+	for i, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			best, specificity = i, s
+		}
+	}
+	if best < 0 {
+		return 0, 0, false
+	}
+	return ranges[best].q, best, true
+}
+
+// negotiateHTTPCodec returns the registered content type, and its codec, that
+// is preferred by a client that sent the provided Accept header. Content types
+// with a higher quality are preferred, then content types matched by an
+// earlier media range in the header, then content types registered earlier.
+// It returns false if the client accepts none of the registered content types.
+func negotiateHTTPCodec(accept string) (httpCodec, bool) {
+	httpCodecs.mu.RLock()
+	defer httpCodecs.mu.RUnlock()
+	if strings.TrimSpace(accept) == "" {
+		return httpCodecs.codecs[0], true
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ, bestIndex := -1, 0.0, 0
+	for i, c := range httpCodecs.codecs {
+		q, index, ok := quality(ranges, c.contentType)
+		if !ok || q == 0 {
+			continue
+		}
+		if best < 0 || q > bestQ || (q == bestQ && index < bestIndex) {
+			best, bestQ, bestIndex = i, q, index
+		}
+	}
+	if best < 0 {
+		return httpCodec{}, false
+	}
+	return httpCodecs.codecs[best], true
+}
+
+// httpContentTypes returns the registered content types.
+func httpContentTypes() []string {
+	httpCodecs.mu.RLock()
+	defer httpCodecs.mu.RUnlock()
+	types := make([]string, len(httpCodecs.codecs))
+	for i, c := range httpCodecs.codecs {
+		types[i] = c.contentType
+	}
+	return types
+}
+
+// maxHTTPRequestBytes is the maximum size of the body of a request served by
+// an HTTPHandler.
+const maxHTTPRequestBytes = 32 << 20 // 32 MiB
+
+// httpArgsError is the error returned by DecodeHTTPArgs when the arguments of
+// a request are malformed.
+type httpArgsError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e httpArgsError) Error() string { return e.err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e httpArgsError) Unwrap() error { return e.err }
+
+// DecodeHTTPArgs decodes the JSON-encoded arguments of a request served by an
+// HTTPMethod into dsts, which must be pointers.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeHTTPArgs(args []json.RawMessage, dsts ...any) error {
+	if len(args) != len(dsts) {
+		return httpArgsError{fmt.Errorf("got %d arguments, want %d", len(args), len(dsts))}
+	}
+	for i, arg := range args {
+		if err := json.Unmarshal(arg, dsts[i]); err != nil {
+			return httpArgsError{fmt.Errorf("argument %d: %w", i, err)}
+		}
+	}
+	return nil
+}
+
+// HTTPHandler returns an http.Handler that serves the provided methods, keyed
+// by method name. Method M is served at "POST /M". The body of a request is a
+// JSON array of the method's arguments, excluding the context, and the body of
+// a successful response is the encoding of the method's results in the content
+// type negotiated with the client (see RegisterHTTPCodec), JSON by default.
+//
+// A request that accepts none of the registered content types fails with
+// status 406. A request whose body is larger than 32 MiB fails with status
+// 413, and a request with malformed arguments fails with status 400. A method
+// call that returns an error wrapping remoteErr (i.e., weaver.RemoteCallError)
+// fails with status 502, and a method call that returns any other error fails
+// with status 500.
+//
+// NOTE that this function should be called only in the generated code.
+func HTTPHandler(methods map[string]HTTPMethod, remoteErr error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		method, ok := methods[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Pick the content type of the response.
+		w.Header().Add("Vary", "Accept")
+		codec, ok := negotiateHTTPCodec(r.Header.Get("Accept"))
+		if !ok {
+			msg := fmt.Sprintf("no acceptable content type; available content types are %s", strings.Join(httpContentTypes(), ", "))
+			http.Error(w, msg, http.StatusNotAcceptable)
+			return
+		}
+
+		// Decode the arguments. An empty body is an empty list of arguments.
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPRequestBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, fmt.Sprintf("request body larger than %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var args []json.RawMessage
+		if len(strings.TrimSpace(string(body))) > 0 {
+			if err := json.Unmarshal(body, &args); err != nil {
+				http.Error(w, fmt.Sprintf("arguments are not a JSON array: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Call the method.
+		result, err := method(r.Context(), args)
+		if err != nil {
+			var argsErr httpArgsError
+			switch {
+			case errors.As(err, &argsErr):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case remoteErr != nil && errors.Is(err, remoteErr):
+				http.Error(w, err.Error(), http.StatusBadGateway)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// Encode the results.
+		reply, err := codec.codec.Encode(result)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("encode results: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", codec.contentType)
+		w.Write(reply)
+	})
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errRemote = errors.New("remote call error")
+
+// testHTTPHandler returns an HTTPHandler that serves a hand-written
+// equivalent of a generated handler for the following component:
+//
+//	type Calc interface {
+//	    Add(context.Context, int, int) (int, error)
+//	    Fail(context.Context, bool) error
+//	    DivMod(context.Context, int, int) (int, int, error)
+//	}
+func testHTTPHandler() http.Handler {
+	return HTTPHandler(map[string]HTTPMethod{
+		"Add": func(ctx context.Context, args []json.RawMessage) (HTTPResult, error) {
+			var a0, a1 int
+			if err := DecodeHTTPArgs(args, &a0, &a1); err != nil {
+				return HTTPResult{}, err
+			}
+			r0 := a0 + a1
+			return HTTPResult{Value: r0, Encode: func(enc *Encoder) {
+				enc.Int(r0)
+			}}, nil
+		},
+		"Fail": func(ctx context.Context, args []json.RawMessage) (HTTPResult, error) {
+			var a0 bool
+			if err := DecodeHTTPArgs(args, &a0); err != nil {
+				return HTTPResult{}, err
+			}
+			if a0 {
+				return HTTPResult{}, fmt.Errorf("Fail: %w", errRemote)
+			}
+			return HTTPResult{}, errors.New("Fail: application error")
+		},
+		"DivMod": func(ctx context.Context, args []json.RawMessage) (HTTPResult, error) {
+			var a0, a1 int
+			if err := DecodeHTTPArgs(args, &a0, &a1); err != nil {
+				return HTTPResult{}, err
+			}
+			r0, r1 := a0/a1, a0%a1
+			return HTTPResult{Value: []any{r0, r1}, Encode: func(enc *Encoder) {
+				enc.Int(r0)
+				enc.Int(r1)
+			}}, nil
+		},
+	}, errRemote)
+}
+
+// TestHTTPHandler tests the status codes and bodies of the responses returned
+// by an HTTPHandler.
+func TestHTTPHandler(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		reply  string
+	}{
+		{"Add", http.MethodPost, "/Add", "[1, 2]", http.StatusOK, "3"},
+		{"DivMod", http.MethodPost, "/DivMod", "[7, 2]", http.StatusOK, "[3,1]"},
+		{"AppError", http.MethodPost, "/Fail", "[false]", http.StatusInternalServerError, "application error"},
+		{"RemoteError", http.MethodPost, "/Fail", "[true]", http.StatusBadGateway, "remote call error"},
+		{"TooFewArgs", http.MethodPost, "/Add", "[1]", http.StatusBadRequest, "got 1 arguments, want 2"},
+		{"NoArgs", http.MethodPost, "/Add", "", http.StatusBadRequest, "got 0 arguments, want 2"},
+		{"BadArg", http.MethodPost, "/Add", `[1, "two"]`, http.StatusBadRequest, "argument 1"},
+		{"NotArray", http.MethodPost, "/Add", `{"a": 1}`, http.StatusBadRequest, "not a JSON array"},
+		{"NotJSON", http.MethodPost, "/Add", `[1, 2`, http.StatusBadRequest, "not a JSON array"},
+		{"TooLarge", http.MethodPost, "/Add", "[" + strings.Repeat(" ", maxHTTPRequestBytes) + "1, 2]", http.StatusRequestEntityTooLarge, "request body larger than"},
+		{"UnknownMethod", http.MethodPost, "/Sub", "[1, 2]", http.StatusNotFound, "not found"},
+		{"Get", http.MethodGet, "/Add", "", http.StatusMethodNotAllowed, "not allowed"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			testHTTPHandler().ServeHTTP(w, req)
+			if got, want := w.Code, test.status; got != want {
+				t.Fatalf("status: got %d, want %d (body %q)", got, want, w.Body.String())
+			}
+			if got, want := strings.TrimSpace(w.Body.String()), test.reply; !strings.Contains(got, want) {
+				t.Fatalf("body: got %q, want %q", got, want)
+			}
+			if test.status == http.StatusOK {
+				if got, want := w.Header().Get("Content-Type"), "application/json"; got != want {
+					t.Fatalf("Content-Type: got %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestHTTPContentNegotiation tests that an HTTPHandler encodes results in the
+// content type preferred by the Accept header of a request.
+func TestHTTPContentNegotiation(t *testing.T) {
+	// Register a codec that encodes results as text.
+	const textContentType = "text/x-weaver-test"
+	RegisterHTTPCodec(textContentType, HTTPCodecFunc(func(result HTTPResult) ([]byte, error) {
+		return []byte(fmt.Sprint(result.Value)), nil
+	}))
+
+	binary := func(xs ...int) string {
+		enc := NewEncoder()
+		for _, x := range xs {
+			enc.Int(x)
+		}
+		return string(enc.Data())
+	}
+	bodies := map[string]string{"/Add": "[1, 2]", "/DivMod": "[7, 2]"}
+
+	for _, test := range []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		reply       string
+	}{
+		{"NoAccept", "/Add", "", JSONContentType, "3"},
+		{"JSON", "/Add", "application/json", JSONContentType, "3"},
+		{"Binary", "/Add", BinaryContentType, BinaryContentType, binary(3)},
+		{"BinaryMultipleResults", "/DivMod", BinaryContentType, BinaryContentType, binary(3, 1)},
+		{"Text", "/DivMod", textContentType, textContentType, "[3 1]"},
+		{"Any", "/Add", "*/*", JSONContentType, "3"},
+		{"AnyApplication", "/Add", "application/*", JSONContentType, "3"},
+		{"FirstListed", "/Add", BinaryContentType + ", application/json", BinaryContentType, binary(3)},
+		{"HigherQuality", "/Add", "application/json;q=0.5, " + BinaryContentType + ";q=0.9", BinaryContentType, binary(3)},
+		{"SpecificOverWildcard", "/Add", "application/*;q=0.1, " + BinaryContentType, BinaryContentType, binary(3)},
+		{"ExcludedByZeroQuality", "/Add", "application/json;q=0, */*;q=0.5", BinaryContentType, binary(3)},
+		{"Parameters", "/Add", "application/json; charset=utf-8", JSONContentType, "3"},
+		{"MalformedIgnored", "/Add", "bogus, " + BinaryContentType, BinaryContentType, binary(3)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(bodies[test.path]))
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			w := httptest.NewRecorder()
+			testHTTPHandler().ServeHTTP(w, req)
+			if got, want := w.Code, http.StatusOK; got != want {
+				t.Fatalf("status: got %d, want %d (body %q)", got, want, w.Body.String())
+			}
+			if got, want := w.Header().Get("Content-Type"), test.contentType; got != want {
+				t.Fatalf("Content-Type: got %q, want %q", got, want)
+			}
+			if got, want := w.Header().Get("Vary"), "Accept"; got != want {
+				t.Fatalf("Vary: got %q, want %q", got, want)
+			}
+			if got, want := strings.TrimSpace(w.Body.String()), test.reply; got != want {
+				t.Fatalf("body: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestHTTPNotAcceptable tests that an HTTPHandler rejects requests that accept
+// none of the registered content types without calling the method.
+func TestHTTPNotAcceptable(t *testing.T) {
+	called := false
+	handler := HTTPHandler(map[string]HTTPMethod{
+		"Get": func(context.Context, []json.RawMessage) (HTTPResult, error) {
+			called = true
+			return HTTPResult{}, nil
+		},
+	}, errRemote)
+
+	for _, accept := range []string{"text/html", "image/*", "application/json;q=0, " + BinaryContentType + ";q=0"} {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/Get", nil)
+			req.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if got, want := w.Code, http.StatusNotAcceptable; got != want {
+				t.Fatalf("status: got %d, want %d (body %q)", got, want, w.Body.String())
+			}
+			if got, want := w.Body.String(), JSONContentType; !strings.Contains(got, want) {
+				t.Fatalf("body: got %q, want it to list %q", got, want)
+			}
+			if called {
+				t.Fatal("method called")
+			}
+		})
+	}
+}
+
+func TestRegisterHTTPCodecInvalid(t *testing.T) {
+	for _, contentType := range []string{"", "application", "application/*", "not a media type"} {
+		t.Run(contentType, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("RegisterHTTPCodec(%q): unexpected success", contentType)
+				}
+			}()
+			RegisterHTTPCodec(contentType, HTTPCodecFunc(func(HTTPResult) ([]byte, error) { return nil, nil }))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// CallInfo describes a component method call intercepted by an Interceptor.
+type CallInfo struct {
+	Component string // full component name
+	Method    string // method name
+}
+
+// An Interceptor wraps a component method call. It is passed the call's
+// context and a description of the call, and must call next to run the
+// method, optionally with a derived context. The error returned by the
+// Interceptor is the error returned by the method call.
+type Interceptor func(ctx context.Context, info CallInfo, next func(context.Context) error) error
+
+// interceptors holds the registered interceptors, by full component name. It
+// is nil if no interceptors were ever registered, so that method calls can
+// check for interceptors without locking.
+var interceptors atomic.Pointer[map[string][]Interceptor]
+
+// interceptorsMu serializes calls to RegisterInterceptors.
+var interceptorsMu sync.Mutex
+
+// RegisterInterceptors registers interceptors that wrap every call to a method
+// of the component with the provided full name. Interceptors run in the order
+// they are registered, with the first interceptor outermost.
+//
+// RegisterInterceptors is typically called in an init function. Calls that
+// are already in progress when it is called are not intercepted.
+func RegisterInterceptors(component string, is ...Interceptor) {
+	if len(is) == 0 {
+		return
+	}
+	interceptorsMu.Lock()
+	defer interceptorsMu.Unlock()
+	m := map[string][]Interceptor{}
+	if old := interceptors.Load(); old != nil {
+		for name, existing := range *old {
+			m[name] = existing
+		}
+	}
+	m[component] = append(append([]Interceptor{}, m[component]...), is...)
+	interceptors.Store(&m)
+}
+
+// Intercepted returns true if any interceptors are registered for the
+// component with the provided full name. It is called by generated stubs
+// before every method call.
+func Intercepted(component string) bool {
+	m := interceptors.Load()
+	if m == nil {
+		return false
+	}
+	return len((*m)[component]) > 0
+}
+
+// Intercept calls f through the interceptors registered for the provided
+// component, returning the error returned by the outermost interceptor. It is
+// called by generated stubs in place of a direct method call when
+// Intercepted(component) is true.
+func Intercept(ctx context.Context, component, method string, f func(context.Context) error) error {
+	var is []Interceptor
+	if m := interceptors.Load(); m != nil {
+		is = (*m)[component]
+	}
+	info := CallInfo{Component: component, Method: method}
+	var next func(int, context.Context) error
+	next = func(i int, ctx context.Context) error {
+		if i == len(is) {
+			return f(ctx)
+		}
+		return is[i](ctx, info, func(ctx context.Context) error {
+			return next(i+1, ctx)
+		})
+	}
+	return next(0, ctx)
+}
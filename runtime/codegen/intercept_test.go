@@ -0,0 +1,83 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIntercept(t *testing.T) {
+	const component = "example.com/intercept/Foo"
+	if Intercepted(component) {
+		t.Fatalf("Intercepted(%q) before registration", component)
+	}
+
+	type key struct{}
+	var calls []string
+	logger := func(name string) Interceptor {
+		return func(ctx context.Context, info CallInfo, next func(context.Context) error) error {
+			calls = append(calls, name+" "+info.Component+"."+info.Method)
+			return next(context.WithValue(ctx, key{}, name))
+		}
+	}
+	RegisterInterceptors(component, logger("outer"))
+	RegisterInterceptors(component, logger("inner"))
+	if !Intercepted(component) {
+		t.Fatalf("Intercepted(%q) after registration", component)
+	}
+	if Intercepted("example.com/intercept/Bar") {
+		t.Fatal("Intercepted an unregistered component")
+	}
+
+	errFoo := errors.New("foo")
+	err := Intercept(context.Background(), component, "Foo", func(ctx context.Context) error {
+		// The method sees the context passed by the innermost interceptor.
+		if got := ctx.Value(key{}); got != "inner" {
+			t.Errorf("context value: got %v, want inner", got)
+		}
+		calls = append(calls, "method")
+		return errFoo
+	})
+	if !errors.Is(err, errFoo) {
+		t.Errorf("Intercept: got %v, want %v", err, errFoo)
+	}
+	want := []string{
+		"outer example.com/intercept/Foo.Foo",
+		"inner example.com/intercept/Foo.Foo",
+		"method",
+	}
+	if diff := cmp.Diff(want, calls); diff != "" {
+		t.Errorf("calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestInterceptShortCircuit(t *testing.T) {
+	const component = "example.com/intercept/Baz"
+	errRejected := errors.New("rejected")
+	RegisterInterceptors(component, func(context.Context, CallInfo, func(context.Context) error) error {
+		return errRejected
+	})
+	err := Intercept(context.Background(), component, "Baz", func(context.Context) error {
+		t.Fatal("unexpected method call")
+		return nil
+	})
+	if !errors.Is(err, errRejected) {
+		t.Errorf("Intercept: got %v, want %v", err, errRejected)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+)
+
+// Table of the marshalers registered with RegisterMarshaler, by type.
+var (
+	marshalersMu sync.Mutex
+	marshalers   = map[reflect.Type]any{} // holds a marshaler[T] for type T
+)
+
+// marshaler holds the encoding and decoding functions of type T.
+type marshaler[T any] struct {
+	encode func(*Encoder, T)
+	decode func(*Decoder) T
+}
+
+// RegisterMarshaler registers functions that encode and decode values of type
+// T, typically a type declared in a third-party package that can't embed
+// weaver.AutoMarshal. For example:
+//
+//	func init() {
+//	    codegen.RegisterMarshaler(
+//	        func(enc *codegen.Encoder, d decimal.Decimal) { enc.String(d.String()) },
+//	        func(dec *codegen.Decoder) decimal.Decimal { return decimal.RequireFromString(dec.String()) },
+//	    )
+//	}
+//
+// "weaver generate" encodes the values of T with the registered functions,
+// instead of rejecting T as not serializable, in every package that calls
+// RegisterMarshaler[T]. A package that uses T in a component method or in an
+// AutoMarshal struct must therefore call RegisterMarshaler[T] itself, even if
+// another package already does. The first registration of T is used, so every
+// registration of T must encode it the same way. decode must read exactly
+// what encode writes. If decode panics, e.g., because the encoded value is
+// malformed, the panic is turned into a decoding error, which fails the call
+// instead of crashing the process.
+func RegisterMarshaler[T any](encode func(*Encoder, T), decode func(*Decoder) T) {
+	if encode == nil || decode == nil {
+		panic("RegisterMarshaler: nil encode or decode function")
+	}
+	t := reflection.Type[T]()
+	marshalersMu.Lock()
+	defer marshalersMu.Unlock()
+	if _, ok := marshalers[t]; !ok {
+		marshalers[t] = marshaler[T]{encode, decode}
+	}
+}
+
+// lookupMarshaler returns the marshaler registered for type T, if any.
+func lookupMarshaler[T any]() (marshaler[T], bool) {
+	marshalersMu.Lock()
+	defer marshalersMu.Unlock()
+	m, ok := marshalers[reflection.Type[T]()]
+	if !ok {
+		return marshaler[T]{}, false
+	}
+	return m.(marshaler[T]), true
+}
+
+// EncodeWithMarshaler encodes x with the encoding function registered for
+// type T. It panics with an encoding error if there is none.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeWithMarshaler[T any](enc *Encoder, x T) {
+	m, ok := lookupMarshaler[T]()
+	if !ok {
+		panic(makeEncodeError("no marshaler registered for type %v", reflection.Type[T]()))
+	}
+	m.encode(enc, x)
+}
+
+// DecodeWithMarshaler decodes a value of type T with the decoding function
+// registered for T. It panics with a decoding error if there is none, or if
+// the decoding function panics.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeWithMarshaler[T any](dec *Decoder) T {
+	m, ok := lookupMarshaler[T]()
+	if !ok {
+		panic(makeDecodeError("no marshaler registered for type %v", reflection.Type[T]()))
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err, ok := r.(error)
+		switch {
+		case !ok:
+			panic(makeDecodeError("decoding %v: %v", reflection.Type[T](), r))
+		case errors.As(err, &decoderError{}):
+			panic(r)
+		default:
+			panic(makeDecodeError("decoding %v: %w", reflection.Type[T](), err))
+		}
+	}()
+	return m.decode(dec)
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// celsius is a type without a marshaler registered.
+type celsius float64
+
+func init() {
+	RegisterMarshaler(
+		func(enc *Encoder, loc *time.Location) { enc.String(loc.String()) },
+		func(dec *Decoder) *time.Location {
+			loc, err := time.LoadLocation(dec.String())
+			if err != nil {
+				panic(makeDecodeError("%w", err))
+			}
+			return loc
+		},
+	)
+	// A later registration of the same type is ignored.
+	RegisterMarshaler(
+		func(enc *Encoder, loc *time.Location) { panic("unexpected call") },
+		func(dec *Decoder) *time.Location { panic("unexpected call") },
+	)
+}
+
+func TestMarshaler(t *testing.T) {
+	enc := NewEncoder()
+	EncodeWithMarshaler(enc, time.UTC)
+	enc.Int(42)
+
+	dec := NewDecoder(enc.Data())
+	if got := DecodeWithMarshaler[*time.Location](dec); got != time.UTC {
+		t.Fatalf("DecodeWithMarshaler: got %v, want %v", got, time.UTC)
+	}
+	if got := dec.Int(); got != 42 {
+		t.Fatalf("Int: got %d, want 42", got)
+	}
+	if !dec.Empty() {
+		t.Fatal("leftover bytes in decoder")
+	}
+}
+
+func TestMarshalerDecodePanics(t *testing.T) {
+	// The decoding function of *time.Location panics with a decoding error on
+	// unknown locations, and the one of *time.Timer with a plain error.
+	RegisterMarshaler(
+		func(*Encoder, *time.Timer) {},
+		func(*Decoder) *time.Timer { panic(errors.New("malformed timer")) },
+	)
+	RegisterMarshaler(
+		func(*Encoder, *time.Ticker) {},
+		func(*Decoder) *time.Ticker { panic("malformed ticker") },
+	)
+	for _, test := range []struct {
+		name   string
+		decode func(*Decoder)
+		want   string
+	}{
+		{"DecodeError", func(dec *Decoder) { DecodeWithMarshaler[*time.Location](dec) }, "unknown time zone"},
+		{"Error", func(dec *Decoder) { DecodeWithMarshaler[*time.Timer](dec) }, "malformed timer"},
+		{"Value", func(dec *Decoder) { DecodeWithMarshaler[*time.Ticker](dec) }, "malformed ticker"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc := NewEncoder()
+			enc.String("Not/A_Location")
+			err := func() (err error) {
+				defer func() { err = CatchPanics(recover()) }()
+				test.decode(NewDecoder(enc.Data()))
+				return nil
+			}()
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("DecodeWithMarshaler: got %v, want error containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestMarshalerNotRegistered(t *testing.T) {
+	err := func() (err error) {
+		defer func() { err = CatchPanics(recover()) }()
+		EncodeWithMarshaler(NewEncoder(), celsius(21))
+		return nil
+	}()
+	if err == nil || !strings.Contains(err.Error(), "no marshaler registered") {
+		t.Fatalf("EncodeWithMarshaler: got %v, want no marshaler error", err)
+	}
+
+	err = func() (err error) {
+		defer func() { err = CatchPanics(recover()) }()
+		DecodeWithMarshaler[celsius](NewDecoder(nil))
+		return nil
+	}()
+	if err == nil || !strings.Contains(err.Error(), "no marshaler registered") {
+		t.Fatalf("DecodeWithMarshaler: got %v, want no marshaler error", err)
+	}
+}
@@ -15,10 +15,14 @@
 package codegen
 
 import (
+	"sync/atomic"
 	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
 	"github.com/ServiceWeaver/weaver/metrics"
+	rmetrics "github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
 )
 
 var (
@@ -31,23 +35,109 @@ var (
 		imetrics.MethodErrorsName,
 		"Count of Service Weaver component method invocations that result in an error",
 	)
-	methodLatencies = metrics.NewHistogramMap[MethodLabels](
+	methodSerializationErrors = metrics.NewCounterMap[MethodLabels](
+		imetrics.MethodSerializationErrorsName,
+		"Count of Service Weaver remote component method invocations that fail to encode their arguments or decode their results",
+	)
+
+	// The histograms are registered with the runtime metrics package, rather
+	// than the user-facing one, so that their buckets can be changed by
+	// SetLatencyBuckets and SetBytesBuckets.
+	methodLatencies = rmetrics.RegisterMap[MethodLabels](
+		protos.MetricType_HISTOGRAM,
 		imetrics.MethodLatenciesName,
 		"Duration, in microseconds, of Service Weaver component method execution",
 		imetrics.GeneratedBuckets,
 	)
-	methodBytesRequest = metrics.NewHistogramMap[MethodLabels](
+	methodQueueLatencies = rmetrics.RegisterMap[MethodLabels](
+		protos.MetricType_HISTOGRAM,
+		imetrics.MethodQueueLatenciesName,
+		"Duration, in microseconds, that remote Service Weaver component method calls spend queued before executing",
+		imetrics.GeneratedBuckets,
+	)
+	methodBytesRequest = rmetrics.RegisterMap[MethodLabels](
+		protos.MetricType_HISTOGRAM,
 		imetrics.MethodBytesRequestName,
 		"Number of bytes in Service Weaver component method requests",
 		imetrics.GeneratedBuckets,
 	)
-	methodBytesReply = metrics.NewHistogramMap[MethodLabels](
+	methodBytesReply = rmetrics.RegisterMap[MethodLabels](
+		protos.MetricType_HISTOGRAM,
 		imetrics.MethodBytesReplyName,
 		"Number of bytes in Service Weaver component method replies",
 		imetrics.GeneratedBuckets,
 	)
+	methodDeadlineBudget = rmetrics.RegisterMap[MethodLabels](
+		protos.MetricType_HISTOGRAM,
+		imetrics.MethodDeadlineBudgetName,
+		"Fraction of the time left until their deadline that remote Service Weaver component method calls take",
+		deadlineBudgetBuckets,
+	)
 )
 
+// deadlineBudgetBuckets are the bucket boundaries of the deadline budget
+// metric. Calls that overrun their deadline fall in the last bucket.
+var deadlineBudgetBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 0.75, 0.9, 1}
+
+// SetLatencyBuckets sets the histogram bucket boundaries, in microseconds, of
+// the latency metrics of component methods. The default buckets are coarse
+// for methods that take well under a millisecond; finer buckets can be used to
+// increase the resolution of their latency metrics. For example:
+//
+//	codegen.SetLatencyBuckets([]float64{10, 25, 50, 100, 250, 500, 1000})
+//
+// Only the metrics of component stubs created after the call are affected, so
+// SetLatencyBuckets should be called before weaver.Run, typically in an init
+// function. Panics if the boundaries are not in strictly ascending order.
+func SetLatencyBuckets(bounds []float64) {
+	methodLatencies.SetBounds(bounds)
+	methodQueueLatencies.SetBounds(bounds)
+}
+
+// SetBytesBuckets is like SetLatencyBuckets, but for the request and reply
+// size metrics of component methods. The boundaries are in bytes.
+func SetBytesBuckets(bounds []float64) {
+	methodBytesRequest.SetBounds(bounds)
+	methodBytesReply.SetBounds(bounds)
+}
+
+// callerAggregation records the components whose method metrics are
+// aggregated across callers. See SetCallerAggregation.
+type callerAggregation struct {
+	all        bool            // aggregate the metrics of every component
+	components map[string]bool // overrides all, by full component name
+}
+
+var aggregation atomic.Pointer[callerAggregation]
+
+// SetCallerAggregation sets which components have their method metrics
+// aggregated across callers. By default, every caller of a component method
+// gets its own set of metrics, labeled by the caller's name. For components
+// with many callers, this can produce a large number of metrics. The method
+// metrics of an aggregated component are instead shared by all of its
+// callers, and their Caller label is empty.
+//
+// If all is true, every component is aggregated. components overrides all
+// for individual components, keyed by full component name. Like
+// SetLatencyBuckets, only the metrics of stubs created after the call are
+// affected.
+func SetCallerAggregation(all bool, components map[string]bool) {
+	aggregation.Store(&callerAggregation{all: all, components: components})
+}
+
+// aggregated returns whether the method metrics of the provided component are
+// aggregated across callers.
+func aggregated(component string) bool {
+	a := aggregation.Load()
+	if a == nil {
+		return false
+	}
+	if agg, ok := a.components[component]; ok {
+		return agg
+	}
+	return a.all
+}
+
 type MethodLabels struct {
 	Caller    string // full calling component name
 	Component string // full callee component name
@@ -59,46 +149,71 @@ type MethodLabels struct {
 // MethodMetrics contains metrics for a single Service Weaver component method.
 type MethodMetrics struct {
 	remote       bool
-	count        *metrics.Counter   // See MethodCounts.
-	errorCount   *metrics.Counter   // See MethodErrors.
-	latency      *metrics.Histogram // See MethodLatencies.
-	bytesRequest *metrics.Histogram // See MethodBytesRequest.
-	bytesReply   *metrics.Histogram // See MethodBytesReply.
+	count        *metrics.Counter // See MethodCounts.
+	errorCount   *metrics.Counter // See MethodErrors.
+	serialErrors *metrics.Counter // See MethodSerializationErrors.
+	latency      *rmetrics.Metric // See MethodLatencies.
+	queueLatency *rmetrics.Metric // See MethodQueueLatencies.
+	bytesRequest *rmetrics.Metric // See MethodBytesRequest.
+	bytesReply   *rmetrics.Metric // See MethodBytesReply.
+	budget       *rmetrics.Metric // See MethodDeadlineBudget.
 }
 
-// MethodMetricsFor returns metrics for the specified method.
+// MethodMetricsFor returns metrics for the specified method. If the method's
+// component is aggregated across callers (see SetCallerAggregation), the
+// Caller label is ignored.
 func MethodMetricsFor(labels MethodLabels) *MethodMetrics {
+	if aggregated(labels.Component) {
+		labels.Caller = ""
+	}
 	return &MethodMetrics{
 		remote:       labels.Remote,
 		count:        methodCounts.Get(labels),
 		errorCount:   methodErrors.Get(labels),
+		serialErrors: methodSerializationErrors.Get(labels),
 		latency:      methodLatencies.Get(labels),
+		queueLatency: methodQueueLatencies.Get(labels),
 		bytesRequest: methodBytesRequest.Get(labels),
 		bytesReply:   methodBytesReply.Get(labels),
+		budget:       methodDeadlineBudget.Get(labels),
 	}
 }
 
 // MethodCallHandle holds information needed to finalize metric
 // updates for a method call.
 type MethodCallHandle struct {
-	start time.Time
+	start  time.Time
+	queue  time.Duration // time spent queued; see Queuer
+	budget time.Duration // time left until the deadline at start, if any; see Run
 }
 
 // Begin starts metric update recording for a call to method m.
 func (m *MethodMetrics) Begin() MethodCallHandle {
-	return MethodCallHandle{time.Now()}
+	return MethodCallHandle{start: clock.Now()}
 }
 
 // End ends metric update recording for a call to method m.
 func (m *MethodMetrics) End(h MethodCallHandle, failed bool, requestBytes, replyBytes int) {
-	latency := time.Since(h.start).Microseconds()
+	latency := clock.Since(h.start).Microseconds()
 	m.count.Inc()
 	if failed {
 		m.errorCount.Inc()
 	}
 	m.latency.Put(float64(latency))
 	if m.remote {
+		m.queueLatency.Put(float64(h.queue.Microseconds()))
 		m.bytesRequest.Put(float64(requestBytes))
 		m.bytesReply.Put(float64(replyBytes))
+		if h.budget > 0 {
+			m.budget.Put(float64(clock.Since(h.start)) / float64(h.budget))
+		}
 	}
 }
+
+// SerializationError records that a call to method m failed to encode its
+// arguments or decode its results. Such a call is also counted as failed by
+// End, but counting it separately helps tell data corruption and version skew
+// apart from network failures.
+func (m *MethodMetrics) SerializationError() {
+	m.serialErrors.Inc()
+}
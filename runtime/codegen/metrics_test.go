@@ -15,10 +15,257 @@
 package codegen
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/trace"
 )
 
+func TestSetLatencyBuckets(t *testing.T) {
+	bounds := []float64{10, 25, 50, 100}
+	SetLatencyBuckets(bounds)
+	SetBytesBuckets(bounds)
+	t.Cleanup(func() {
+		SetLatencyBuckets(imetrics.GeneratedBuckets)
+		SetBytesBuckets(imetrics.GeneratedBuckets)
+	})
+
+	m := MethodMetricsFor(MethodLabels{
+		Caller:    "caller",
+		Component: "component",
+		Method:    "TestSetLatencyBuckets",
+		Remote:    true,
+	})
+	m.End(m.Begin(), false, 30, 200)
+
+	found := 0
+	for _, snap := range metrics.Snapshot() {
+		if snap.Labels["method"] != "TestSetLatencyBuckets" || len(snap.Bounds) == 0 {
+			continue
+		}
+		if snap.Name == imetrics.MethodDeadlineBudgetName {
+			// Deadline budgets are ratios, with fixed buckets.
+			continue
+		}
+		found++
+		if diff := cmp.Diff(bounds, snap.Bounds); diff != "" {
+			t.Errorf("%s: bad bounds (-want +got):\n%s", snap.Name, diff)
+		}
+	}
+	if found != 4 {
+		t.Fatalf("found %d histograms, want 4", found)
+	}
+}
+
+// queuedStub is a Stub and Queuer whose calls spend the provided time queued.
+type queuedStub struct {
+	queue time.Duration
+}
+
+func (s queuedStub) Tracer() trace.Tracer { return nil }
+
+func (s queuedStub) Run(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, error) {
+	results, _, err := s.RunQueued(ctx, method, args, shardKey)
+	return results, err
+}
+
+func (s queuedStub) RunQueued(context.Context, int, []byte, uint64) ([]byte, time.Duration, error) {
+	return nil, s.queue, nil
+}
+
+func TestQueueLatency(t *testing.T) {
+	m := MethodMetricsFor(MethodLabels{
+		Caller:    "caller",
+		Component: "component",
+		Method:    "TestQueueLatency",
+		Remote:    true,
+	})
+	begin := m.Begin()
+	if _, err := Run(context.Background(), queuedStub{queue: 1500 * time.Microsecond}, &begin, 0, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	m.End(begin, false, 0, 0)
+
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name != imetrics.MethodQueueLatenciesName || snap.Labels["method"] != "TestQueueLatency" {
+			continue
+		}
+		if got, want := snap.Value, 1500.0; got != want {
+			t.Fatalf("queue latency: got %v, want %v", got, want)
+		}
+		return
+	}
+	t.Fatal("queue latency metric not found")
+}
+
+// fakeClock is a clock.Clock that only advances when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// advancingStub is a Stub whose calls advance the provided clock by the
+// provided duration. It bounds its calls by the provided deadline, if
+// positive.
+type advancingStub struct {
+	clock    *fakeClock
+	d        time.Duration
+	deadline time.Duration
+}
+
+var _ Deadliner = advancingStub{}
+
+func (s advancingStub) Tracer() trace.Tracer { return nil }
+
+func (s advancingStub) Run(context.Context, int, []byte, uint64) ([]byte, error) {
+	s.clock.advance(s.d)
+	return nil, nil
+}
+
+func (s advancingStub) CallDeadline(int) time.Duration { return s.deadline }
+
+func TestDeadlineBudget(t *testing.T) {
+	// Start the fake clock well ahead of the real one, so that deadlines
+	// computed from the fake clock don't expire during the test.
+	c := &fakeClock{now: time.Now().Add(time.Hour)}
+	defer clock.Set(c)()
+
+	// deadlineBudget returns the sum and the count of the deadline budget
+	// observations of the provided method.
+	deadlineBudget := func(method string) (float64, uint64) {
+		for _, snap := range metrics.Snapshot() {
+			if snap.Name != imetrics.MethodDeadlineBudgetName || snap.Labels["method"] != method {
+				continue
+			}
+			var n uint64
+			for _, c := range snap.Counts {
+				n += c
+			}
+			return snap.Value, n
+		}
+		return 0, 0
+	}
+
+	for _, test := range []struct {
+		name     string
+		timeout  time.Duration // timeout of the context, if positive
+		deadline time.Duration // deadline of the method, if positive
+		want     float64       // fraction of the budget used, or 0 if none
+	}{
+		{"Context", 100 * time.Millisecond, 0, 0.5},
+		{"Method", 0, 200 * time.Millisecond, 0.25},
+		{"EarlierContext", 100 * time.Millisecond, 200 * time.Millisecond, 0.5},
+		{"EarlierMethod", time.Minute, 200 * time.Millisecond, 0.25},
+		{"None", 0, 0, 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			method := "TestDeadlineBudget" + test.name
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, c.Now().Add(test.timeout))
+				defer cancel()
+			}
+			m := MethodMetricsFor(MethodLabels{
+				Caller:    "caller",
+				Component: "component",
+				Method:    method,
+				Remote:    true,
+			})
+			stub := advancingStub{clock: c, d: 50 * time.Millisecond, deadline: test.deadline}
+			before, beforeN := deadlineBudget(method)
+			begin := m.Begin()
+			if _, err := Run(ctx, stub, &begin, 0, nil, 0); err != nil {
+				t.Fatal(err)
+			}
+			m.End(begin, false, 0, 0)
+			after, afterN := deadlineBudget(method)
+
+			if test.want == 0 {
+				// A call without a deadline isn't recorded.
+				if afterN != beforeN {
+					t.Fatalf("deadline budget: got %d observations, want 0", afterN-beforeN)
+				}
+				return
+			}
+			if got, n := after-before, afterN-beforeN; n != 1 || got != test.want {
+				t.Fatalf("deadline budget: got %v (%d observations), want %v (1 observation)", got, n, test.want)
+			}
+		})
+	}
+}
+
+func TestSerializationErrors(t *testing.T) {
+	m := MethodMetricsFor(MethodLabels{
+		Caller:    "caller",
+		Component: "component",
+		Method:    "TestSerializationErrors",
+		Remote:    true,
+	})
+	m.SerializationError()
+	m.End(m.Begin(), true, 0, 0)
+
+	want := map[string]float64{
+		imetrics.MethodErrorsName:              1,
+		imetrics.MethodSerializationErrorsName: 1,
+	}
+	got := map[string]float64{}
+	for _, snap := range metrics.Snapshot() {
+		if _, ok := want[snap.Name]; ok && snap.Labels["method"] == "TestSerializationErrors" {
+			got[snap.Name] = snap.Value
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad error counts (-want +got):\n%s", diff)
+	}
+}
+
+func TestCallerAggregation(t *testing.T) {
+	SetCallerAggregation(false, map[string]bool{"aggregated": true})
+	t.Cleanup(func() { SetCallerAggregation(false, nil) })
+
+	for _, component := range []string{"aggregated", "split"} {
+		for _, caller := range []string{"a", "b"} {
+			m := MethodMetricsFor(MethodLabels{
+				Caller:    caller,
+				Component: component,
+				Method:    "TestCallerAggregation",
+			})
+			m.End(m.Begin(), false, 0, 0)
+		}
+	}
+
+	// Index the call counts by component and caller.
+	got := map[string]float64{}
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == imetrics.MethodCountsName && snap.Labels["method"] == "TestCallerAggregation" {
+			got[snap.Labels["component"]+"/"+snap.Labels["caller"]] = snap.Value
+		}
+	}
+	want := map[string]float64{"aggregated/": 2, "split/a": 1, "split/b": 1}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("bad call counts (-want +got):\n%s", diff)
+	}
+}
+
 func BenchmarkMetrics(b *testing.B) {
 	metrics := MethodMetricsFor(MethodLabels{
 		Caller:    "caller",
@@ -0,0 +1,255 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// A Record is a remote method call captured by RecordRPCs. Args and Reply are
+// the serialized arguments and results of the call, exactly as they were sent
+// over the wire. Note that the results of a method include the error it
+// returned, if any.
+type Record struct {
+	Component string `json:"component"` // full component name
+	Method    string `json:"method"`    // method name
+	Args      []byte `json:"args"`      // serialized arguments
+	Reply     []byte `json:"reply"`     // serialized results
+}
+
+// recordFilePattern matches the files written by RecordRPCs.
+const recordFilePattern = "rpcs-*.jsonl"
+
+// recorder, if not nil, records every remote method call made by this process.
+var recorder atomic.Pointer[rpcRecorder]
+
+// rpcRecorder appends Records, one JSON object per line, to a file.
+type rpcRecorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// RecordRPCs arranges for every remote method call made by this process to be
+// recorded in a file in the provided directory. See [Record] for the
+// information recorded about every call, and [ReadRecords] for how to read the
+// recorded calls back.
+//
+// Every process writes to a different file, so multiple processes can record
+// calls to the same directory. RecordRPCs must be called before the process's
+// client stubs are created.
+func RecordRPCs(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("record RPCs: %w", err)
+	}
+	filename := filepath.Join(dir, fmt.Sprintf("rpcs-%d.jsonl", os.Getpid()))
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("record RPCs: %w", err)
+	}
+	r := &rpcRecorder{f: f, enc: json.NewEncoder(f)}
+	if old := recorder.Swap(r); old != nil {
+		old.close()
+	}
+	return nil
+}
+
+// record appends a Record to the recorder's file.
+func (r *rpcRecorder) record(rec Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(rec)
+}
+
+// close closes the recorder's file.
+func (r *rpcRecorder) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.f.Close()
+}
+
+// ReadRecords returns the method calls recorded in the provided directory by
+// RecordRPCs, in the order they were recorded by every process.
+func ReadRecords(dir string) ([]Record, error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, recordFilePattern))
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bufio.NewReader(f))
+		for dec.More() {
+			var rec Record
+			if err := dec.Decode(&rec); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("read records from %q: %w", filename, err)
+			}
+			records = append(records, rec)
+		}
+		f.Close()
+	}
+	return records, nil
+}
+
+// RecordingStub returns a Stub that records the calls made through stub, if
+// RecordRPCs was called, or stub otherwise. reg is the registration of the
+// component that stub calls.
+func RecordingStub(reg *Registration, stub Stub) Stub {
+	r := recorder.Load()
+	if r == nil {
+		return stub
+	}
+	return &recordingStub{Stub: stub, reg: reg, recorder: r}
+}
+
+// recordingStub is a Stub that records the calls made through it. It forwards
+// the optional AccessLogger, Hedger, and Queuer methods to the stub it wraps.
+type recordingStub struct {
+	Stub
+	reg      *Registration
+	recorder *rpcRecorder
+}
+
+var (
+	_ AccessLogger = &recordingStub{}
+	_ Hedger       = &recordingStub{}
+	_ Queuer       = &recordingStub{}
+)
+
+// Run implements the Stub interface.
+func (s *recordingStub) Run(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, error) {
+	results, _, err := s.RunQueued(ctx, method, args, shardKey)
+	return results, err
+}
+
+// RunQueued implements the Queuer interface.
+func (s *recordingStub) RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) ([]byte, time.Duration, error) {
+	// The caller is free to reuse args once the call returns, so we copy it
+	// before making the call.
+	rec := Record{
+		Component: s.reg.Name,
+		Method:    s.reg.Iface.Method(method).Name,
+		Args:      bytes.Clone(args),
+	}
+	r := runOnce(ctx, s.Stub, method, args, shardKey)
+	if r.err != nil {
+		// The call failed before the method returned, so there is no reply
+		// to record.
+		return r.results, r.queue, r.err
+	}
+	rec.Reply = r.results
+	if err := s.recorder.record(rec); err != nil {
+		// Failing to record a call should not fail the call.
+		slog.Error("cannot record RPC", "component", rec.Component, "method", rec.Method, "err", err)
+	}
+	return r.results, r.queue, nil
+}
+
+// HedgeDelay implements the Hedger interface.
+func (s *recordingStub) HedgeDelay(method int) time.Duration {
+	if h, ok := s.Stub.(Hedger); ok {
+		return h.HedgeDelay(method)
+	}
+	return 0
+}
+
+// LogAccess implements the AccessLogger interface.
+func (s *recordingStub) LogAccess(ctx context.Context, method int, duration time.Duration, err error) {
+	if l, ok := s.Stub.(AccessLogger); ok {
+		l.LogAccess(ctx, method, duration, err)
+	}
+}
+
+// ErrNoRecord is returned by a replay stub when it has no recorded reply for
+// a call. See NewReplayStub.
+var ErrNoRecord = errors.New("no recorded reply")
+
+// NewReplayStub returns a Stub that replays the calls to the component with
+// the provided registration that are in records. A call through the stub
+// returns the recorded reply of a call to the same method with the same
+// serialized arguments. If the same call was recorded more than once, the
+// recorded replies are returned in order, and the last one is repeated once
+// they are exhausted. A call that wasn't recorded fails with ErrNoRecord.
+//
+// Note that arguments are matched byte for byte. Arguments whose encoding is
+// not deterministic, like maps with more than one entry, will typically not
+// match the recorded calls.
+func NewReplayStub(reg *Registration, records []Record, tracer trace.Tracer) Stub {
+	s := &replayStub{reg: reg, tracer: tracer, replies: map[replayKey][][]byte{}}
+	for _, rec := range records {
+		if rec.Component != reg.Name {
+			continue
+		}
+		key := replayKey{rec.Method, string(rec.Args)}
+		s.replies[key] = append(s.replies[key], rec.Reply)
+	}
+	return s
+}
+
+// replayKey identifies a recorded call.
+type replayKey struct {
+	method string // method name
+	args   string // serialized arguments
+}
+
+// replayStub is a Stub that replays recorded calls. See NewReplayStub.
+type replayStub struct {
+	reg    *Registration
+	tracer trace.Tracer
+
+	mu      sync.Mutex
+	replies map[replayKey][][]byte // remaining recorded replies, by call
+}
+
+// Tracer implements the Stub interface.
+func (s *replayStub) Tracer() trace.Tracer {
+	return s.tracer
+}
+
+// Run implements the Stub interface.
+func (s *replayStub) Run(_ context.Context, method int, args []byte, _ uint64) ([]byte, error) {
+	name := s.reg.Iface.Method(method).Name
+	key := replayKey{name, string(args)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replies := s.replies[key]
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("%s.%s: %w", s.reg.Name, name, ErrNoRecord)
+	}
+	reply := replies[0]
+	if len(replies) > 1 {
+		s.replies[key] = replies[1:]
+	}
+	// The caller may modify the results, so we return a copy.
+	return bytes.Clone(reply), nil
+}
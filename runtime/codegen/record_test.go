@@ -0,0 +1,101 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// echoer is the interface of a component used to test recording and replay.
+type echoer interface {
+	Echo(context.Context, string) (string, error)
+	Fail(context.Context) error
+}
+
+// echoStub is a Stub that echoes the arguments of its calls, or fails if
+// err is not nil.
+type echoStub struct {
+	err error
+}
+
+func (s echoStub) Tracer() trace.Tracer { return nil }
+
+func (s echoStub) Run(_ context.Context, _ int, args []byte, _ uint64) ([]byte, error) {
+	return append([]byte("reply to "), args...), s.err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	if err := RecordRPCs(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if r := recorder.Swap(nil); r != nil {
+			r.close()
+		}
+	})
+
+	// Record some calls.
+	reg := &Registration{Name: "pkg/Echoer", Iface: reflect.TypeOf((*echoer)(nil)).Elem()}
+	stub := RecordingStub(reg, echoStub{})
+	ctx := context.Background()
+	for _, args := range []string{"a", "b", "a"} {
+		if _, err := stub.Run(ctx, 0, []byte(args), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failing := RecordingStub(reg, echoStub{err: errors.New("unreachable")})
+	if _, err := failing.Run(ctx, 1, nil, 0); err == nil {
+		t.Fatal("unexpected success")
+	}
+
+	// Read the recorded calls. The failed call is not recorded.
+	records, err := ReadRecords(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Component: "pkg/Echoer", Method: "Echo", Args: []byte("a"), Reply: []byte("reply to a")},
+		{Component: "pkg/Echoer", Method: "Echo", Args: []byte("b"), Reply: []byte("reply to b")},
+		{Component: "pkg/Echoer", Method: "Echo", Args: []byte("a"), Reply: []byte("reply to a")},
+	}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Fatalf("ReadRecords (-want +got):\n%s", diff)
+	}
+
+	// Replay the recorded calls.
+	replay := NewReplayStub(reg, records, nil)
+	for _, args := range []string{"b", "a", "a", "a"} {
+		got, err := replay.Run(ctx, 0, []byte(args), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "reply to " + args; string(got) != want {
+			t.Errorf("Run(%q): got %q, want %q", args, got, want)
+		}
+	}
+	if _, err := replay.Run(ctx, 0, []byte("c"), 0); !errors.Is(err, ErrNoRecord) {
+		t.Errorf("Run(%q): got %v, want %v", "c", err, ErrNoRecord)
+	}
+	if _, err := replay.Run(ctx, 1, nil, 0); !errors.Is(err, ErrNoRecord) {
+		t.Errorf("Run(Fail): got %v, want %v", err, ErrNoRecord)
+	}
+}
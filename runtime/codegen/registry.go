@@ -24,7 +24,6 @@ import (
 
 	"github.com/ServiceWeaver/weaver/internal/config"
 	"github.com/ServiceWeaver/weaver/runtime"
-	"github.com/ServiceWeaver/weaver/runtime/protos"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -48,6 +47,22 @@ func Find(name string) (*Registration, bool) {
 	return globalRegistry.find(name)
 }
 
+// SetRouterFn overrides the routing of calls to the routed component with
+// the provided interface type. See [RouterFn] for details.
+func SetRouterFn(iface reflect.Type, fn RouterFn) error {
+	return globalRegistry.setRouterFn(iface, fn)
+}
+
+// RouterFn maps the shard key of a routed call to the address of the replica
+// that should handle the call. replicas holds the sorted, non-empty list of
+// addresses of the replicas that are currently available, which the RouterFn
+// must not modify. A RouterFn may return the empty string to route the call
+// using the default routing assignment instead.
+//
+// Note that a shard key is the [Hasher] sum of the routing key returned by
+// the component's router.
+type RouterFn func(shardKey uint64, replicas []string) string
+
 // registry is a repository for registered Service Weaver components.
 // Entries are typically added to the default registry by calls
 // to Register in init functions in code generated by "weaver generate".
@@ -65,6 +80,9 @@ type Registration struct {
 	Routed    bool         // True if calls to this component should be routed
 	Listeners []string     // the names of any weaver.Listeners
 	NoRetry   []int        // indices of methods that should not be retried
+	ReadOnly  []int        // indices of methods marked //weaver:readonly
+	Cacheable []int        // indices of methods marked //weaver:cacheable
+	RouterFn  RouterFn     // routing override for routed components, or nil
 
 	// Functions that return different types of stubs.
 	LocalStubFn   func(impl any, caller string, tracer trace.Tracer) any
@@ -102,6 +120,22 @@ func (r *registry) register(reg Registration) error {
 	return nil
 }
 
+// setRouterFn sets the RouterFn of the component with the provided interface
+// type.
+func (r *registry) setRouterFn(iface reflect.Type, fn RouterFn) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	reg, ok := r.components[iface]
+	if !ok {
+		return fmt.Errorf("component %v not registered", iface)
+	}
+	if !reg.Routed {
+		return fmt.Errorf("component %s is not routed", reg.Name)
+	}
+	reg.RouterFn = fn
+	return nil
+}
+
 func verifyRegistration(reg Registration) error {
 	if reg.Iface == nil {
 		return errors.New("missing component type")
@@ -157,15 +191,22 @@ func ComponentConfigValidator(path, cfg string) error {
 		// Not for a known component.
 		return nil
 	}
+	sections := map[string]string{path: cfg}
 	componentConfig := config.Config(reflect.New(info.Impl))
 	if componentConfig == nil {
-		return fmt.Errorf("unexpected configuration for component %v "+
-			"that does not support configuration (add a "+
-			"weaver.WithConfig[configType] embedded field to %v)",
-			info.Name, info.Iface)
+		// Components without configuration may still set a log level.
+		if _, _, err := runtime.ParseLogLevel(path, sections); err != nil {
+			return fmt.Errorf("%v: bad config: %w", info.Iface, err)
+		}
+		if err := runtime.ParseComponentConfigSection(path, sections, &struct{}{}); err != nil {
+			return fmt.Errorf("unexpected configuration for component %v "+
+				"that does not support configuration (add a "+
+				"weaver.WithConfig[configType] embedded field to %v)",
+				info.Name, info.Iface)
+		}
+		return nil
 	}
-	config := &protos.AppConfig{Sections: map[string]string{path: cfg}}
-	if err := runtime.ParseConfigSection(path, "", config.Sections, componentConfig); err != nil {
+	if err := runtime.ParseComponentConfigSection(path, sections, componentConfig); err != nil {
 		return fmt.Errorf("%v: bad config: %w", info.Iface, err)
 	}
 	return nil
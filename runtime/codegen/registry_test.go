@@ -80,6 +80,39 @@ func TestCallGraph(t *testing.T) {
 	}
 }
 
+func TestSetRouterFn(t *testing.T) {
+	fn := func(uint64, []string) string { return "" }
+	if err := codegen.SetRouterFn(reflection.Type[C](), fn); err != nil {
+		t.Fatal(err)
+	}
+	reg, ok := codegen.Find("codegen_test/C")
+	if !ok {
+		t.Fatal("component C not found")
+	}
+	if reg.RouterFn == nil {
+		t.Fatal("unexpected nil RouterFn")
+	}
+}
+
+func TestSetRouterFnErrors(t *testing.T) {
+	type unregistered interface{}
+	fn := func(uint64, []string) string { return "" }
+	for _, test := range []struct {
+		iface reflect.Type
+		want  string
+	}{
+		{reflection.Type[unregistered](), "not registered"},
+		{reflection.Type[A](), "not routed"},
+	} {
+		t.Run(test.want, func(t *testing.T) {
+			err := codegen.SetRouterFn(test.iface, fn)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("SetRouterFn: got %v, want error containing %q", err, test.want)
+			}
+		})
+	}
+}
+
 const (
 	typeWithoutConfig = "codegen_test/withoutConfig"
 	typeWithConfig    = "codegen_test/withConfig"
@@ -117,12 +150,16 @@ type bimpl struct {
 	weaver.Implements[B]
 }
 
-func register[Intf, Impl any](name string) {
+type C interface{}
+type cimpl struct{}
+
+func register[Intf, Impl any](name string, routed bool) {
 	var zero Impl
 	codegen.Register(codegen.Registration{
 		Name:         name,
 		Iface:        reflection.Type[Intf](),
 		Impl:         reflect.TypeOf(zero),
+		Routed:       routed,
 		LocalStubFn:  func(any, string, trace.Tracer) any { return nil },
 		ClientStubFn: func(codegen.Stub, string) any { return nil },
 		ServerStubFn: func(any, func(uint64, float64)) codegen.Server { return nil },
@@ -131,8 +168,9 @@ func register[Intf, Impl any](name string) {
 
 // Register dummy components for test.
 func init() {
-	register[A, aimpl]("codegen_test/A")
-	register[B, bimpl]("codegen_test/B")
-	register[componentWithoutConfig, componentWithoutConfigImpl](typeWithoutConfig)
-	register[componentWithConfig, componentWithConfigImpl](typeWithConfig)
+	register[A, aimpl]("codegen_test/A", false)
+	register[B, bimpl]("codegen_test/B", false)
+	register[C, cimpl]("codegen_test/C", true)
+	register[componentWithoutConfig, componentWithoutConfigImpl](typeWithoutConfig, false)
+	register[componentWithConfig, componentWithConfigImpl](typeWithConfig, false)
 }
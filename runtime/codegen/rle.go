@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import "math"
+
+// Run-length encoding
+//
+// Struct fields tagged with `weaver:"rle"` are run-length encoded. A slice is
+// encoded as its length (-1 for a nil slice) followed by a list of runs. A run
+// is a uint32 count followed by the encoded value that is repeated count
+// times. For example, the slice [0, 0, 0, 7, 0, 0] is encoded as
+//
+//     6 | 3 0 | 1 7 | 2 0
+//
+// Run-length encoding is beneficial for large slices with long runs of
+// identical elements, like mostly-zero matrices, but it is more expensive
+// than the plain encoding for slices with few repeated elements.
+//
+// Only elements with identical encodings are merged into a run. Floating
+// point elements are compared bit by bit, so 0.0 and -0.0 are kept apart, and
+// NaNs are preserved.
+
+// EncodeRLE encodes s into enc using run-length encoding. encode is used to
+// encode an individual element of s.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeRLE[T comparable](enc *Encoder, s []T, encode func(*Encoder, T)) {
+	if s == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(s))
+	for i := 0; i < len(s); {
+		j := i + 1
+		for j < len(s) && identical(s[j], s[i]) {
+			j++
+		}
+		enc.Uint32(uint32(j - i))
+		encode(enc, s[i])
+		i = j
+	}
+}
+
+// identical returns whether a and b have the same encoding. Unlike a == b, it
+// compares floating point numbers bit by bit, so 0.0 and -0.0 differ, and a
+// NaN is identical to a NaN with the same bits.
+func identical[T comparable](a, b T) bool {
+	switch x := any(a).(type) {
+	case float32:
+		return math.Float32bits(x) == math.Float32bits(any(b).(float32))
+	case float64:
+		return math.Float64bits(x) == math.Float64bits(any(b).(float64))
+	case complex64:
+		y := any(b).(complex64)
+		return math.Float32bits(real(x)) == math.Float32bits(real(y)) &&
+			math.Float32bits(imag(x)) == math.Float32bits(imag(y))
+	case complex128:
+		y := any(b).(complex128)
+		return math.Float64bits(real(x)) == math.Float64bits(real(y)) &&
+			math.Float64bits(imag(x)) == math.Float64bits(imag(y))
+	}
+	return a == b
+}
+
+// DecodeRLE decodes a slice that was encoded using EncodeRLE. decode is used
+// to decode an individual element of the slice.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeRLE[T any](dec *Decoder, decode func(*Decoder) T) []T {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]T, n)
+	for i := 0; i < n; {
+		count := int(dec.Uint32())
+		if count == 0 || count > n-i {
+			panic(makeDecodeError("invalid run length %d at index %d of %d", count, i, n))
+		}
+		v := decode(dec)
+		for j := i; j < i+count; j++ {
+			res[j] = v
+		}
+		i += count
+	}
+	return res
+}
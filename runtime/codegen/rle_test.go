@@ -0,0 +1,146 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestRLERoundTrip run-length encodes and decodes a number of slices. Verify
+// that the slices are decoded as expected.
+func TestRLERoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		s    []int64
+	}{
+		{"Nil", nil},
+		{"Empty", []int64{}},
+		{"Single", []int64{42}},
+		{"NoRuns", []int64{1, 2, 3, 4, 5}},
+		{"OneRun", []int64{7, 7, 7, 7, 7}},
+		{"MixedRuns", []int64{0, 0, 0, 7, 0, 0, 1, 1, 2}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc := NewEncoder()
+			EncodeRLE(enc, test.s, (*Encoder).Int64)
+			dec := NewDecoder(enc.Data())
+			got := DecodeRLE(dec, (*Decoder).Int64)
+			if diff := cmp.Diff(test.s, got); diff != "" {
+				t.Fatalf("(-want,+got):\n%s", diff)
+			}
+			if (test.s == nil) != (got == nil) {
+				t.Fatalf("nil mismatch: want %v, got %v", test.s == nil, got == nil)
+			}
+			if !dec.Empty() {
+				t.Fatalf("unexpected bytes left to be read: %d", len(dec.data))
+			}
+		})
+	}
+}
+
+// TestRLEFloats run-length encodes and decodes slices of floats with signed
+// zeroes and NaNs. Verify that every element is decoded with the same bits.
+func TestRLEFloats(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	nan1 := math.Float64frombits(0x7ff8000000000001)
+	nan2 := math.Float64frombits(0x7ff8000000000002)
+	s := []float64{0, negZero, negZero, 0, nan1, nan1, nan2, math.NaN()}
+
+	enc := NewEncoder()
+	EncodeRLE(enc, s, (*Encoder).Float64)
+	dec := NewDecoder(enc.Data())
+	got := DecodeRLE(dec, (*Decoder).Float64)
+	if len(got) != len(s) {
+		t.Fatalf("got %d elements, want %d", len(got), len(s))
+	}
+	for i := range s {
+		if math.Float64bits(got[i]) != math.Float64bits(s[i]) {
+			t.Errorf("element %d: got %x, want %x", i, math.Float64bits(got[i]), math.Float64bits(s[i]))
+		}
+	}
+
+	// Identical NaNs are merged into a run, but -0.0 and 0.0 are not.
+	// 0 | -0, -0 | 0 | nan1, nan1 | nan2 | NaN
+	if got, want := len(enc.Data()), 4+6*12; got != want {
+		t.Errorf("size: got %d, want %d", got, want)
+	}
+
+	f32 := []float32{0, float32(negZero)}
+	enc = NewEncoder()
+	EncodeRLE(enc, f32, (*Encoder).Float32)
+	got32 := DecodeRLE(NewDecoder(enc.Data()), (*Decoder).Float32)
+	if !math.Signbit(float64(got32[1])) {
+		t.Errorf("float32: got %v, want -0", got32[1])
+	}
+}
+
+// TestRLESize compares the size of the run-length encoding of a
+// highly-repetitive slice against the size of its plain encoding.
+func TestRLESize(t *testing.T) {
+	// A mostly-zero pricing matrix, flattened.
+	prices := make([]float64, 10000)
+	for i := 0; i < len(prices); i += 1000 {
+		prices[i] = 9.99
+	}
+
+	plain := NewEncoder()
+	plain.Len(len(prices))
+	for _, p := range prices {
+		plain.Float64(p)
+	}
+
+	rle := NewEncoder()
+	EncodeRLE(rle, prices, (*Encoder).Float64)
+
+	if got, want := len(plain.Data()), 4+8*len(prices); got != want {
+		t.Fatalf("plain size: got %d, want %d", got, want)
+	}
+	// 10 non-zero elements and 10 runs of zeroes, each taking 4+8 bytes.
+	if got, want := len(rle.Data()), 4+20*12; got != want {
+		t.Fatalf("rle size: got %d, want %d", got, want)
+	}
+	t.Logf("plain: %d bytes, rle: %d bytes", len(plain.Data()), len(rle.Data()))
+}
+
+// TestRLEInvalidRunLength decodes a run-length encoding with invalid run
+// lengths. Verify that decoding fails.
+func TestRLEInvalidRunLength(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		runs []uint32
+	}{
+		{"Zero", []uint32{0}},
+		{"TooLong", []uint32{2, 2}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc := NewEncoder()
+			enc.Len(3)
+			for _, r := range test.runs {
+				enc.Uint32(r)
+				enc.Bool(true)
+			}
+			err := convertCallPanicToError(func() {
+				DecodeRLE(NewDecoder(enc.Data()), (*Decoder).Bool)
+			})
+			if err == nil || !strings.Contains(err.Error(), "invalid run length") {
+				t.Fatalf("DecodeRLE: got %v, want invalid run length error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// A TraceSampler decides whether a traced component method call creates a
+// span of its own. A call that isn't sampled still propagates the trace of its
+// caller, and a span is still created for it if it fails.
+type TraceSampler func(info CallInfo) bool
+
+// samplers holds the registered trace samplers, by full component name. It is
+// nil if no samplers were ever registered, so that method calls can check for
+// samplers without locking.
+var samplers atomic.Pointer[map[string]TraceSampler]
+
+// samplersMu serializes calls to RegisterTraceSampler.
+var samplersMu sync.Mutex
+
+// RegisterTraceSampler registers a sampler that decides which traced calls to
+// a method of the component with the provided full name create spans,
+// replacing any previously registered sampler. By default, every traced call
+// creates a span.
+//
+// RegisterTraceSampler is typically called in an init function.
+func RegisterTraceSampler(component string, sampler TraceSampler) {
+	samplersMu.Lock()
+	defer samplersMu.Unlock()
+	m := map[string]TraceSampler{}
+	if old := samplers.Load(); old != nil {
+		for name, existing := range *old {
+			m[name] = existing
+		}
+	}
+	m[component] = sampler
+	samplers.Store(&m)
+}
+
+// StartSpan starts a span with the provided name and kind for a call to the
+// provided method of the provided component. It is called by generated stubs
+// when ctx is traced. If the component's trace sampler doesn't sample the
+// call, StartSpan returns ctx unchanged, along with a span that is only
+// exported, with the call's start time, if the call fails.
+func StartSpan(ctx context.Context, tracer trace.Tracer, component, method, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	if m := samplers.Load(); m != nil {
+		if sampler, ok := (*m)[component]; ok && !sampler(CallInfo{Component: component, Method: method}) {
+			return ctx, &errorSpan{
+				parent: trace.SpanFromContext(ctx),
+				ctx:    ctx,
+				tracer: tracer,
+				name:   name,
+				kind:   kind,
+				start:  time.Now(),
+			}
+		}
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// errorSpan is the span of a call that wasn't sampled. It records the
+// attributes, error, and status set on it, and exports a span when it is
+// ended only if the call failed. Its span context is the span context of the
+// caller, so the call still propagates the caller's trace.
+type errorSpan struct {
+	parent trace.Span      // the caller's span
+	ctx    context.Context // the call's context
+	tracer trace.Tracer
+	name   string
+	kind   trace.SpanKind
+	start  time.Time
+
+	mu    sync.Mutex
+	attrs []attribute.KeyValue
+	errs  []error
+	code  codes.Code
+	desc  string
+	ended bool
+}
+
+var _ trace.Span = &errorSpan{}
+
+// End implements the trace.Span interface.
+func (s *errorSpan) End(options ...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.ended = true
+	if len(s.errs) == 0 && s.code != codes.Error {
+		return
+	}
+	_, span := s.tracer.Start(s.ctx, s.name,
+		trace.WithSpanKind(s.kind),
+		trace.WithTimestamp(s.start),
+		trace.WithAttributes(s.attrs...))
+	for _, err := range s.errs {
+		span.RecordError(err)
+	}
+	span.SetStatus(s.code, s.desc)
+	span.End(options...)
+}
+
+// AddEvent implements the trace.Span interface. Events are dropped.
+func (s *errorSpan) AddEvent(string, ...trace.EventOption) {}
+
+// IsRecording implements the trace.Span interface.
+func (s *errorSpan) IsRecording() bool { return false }
+
+// RecordError implements the trace.Span interface.
+func (s *errorSpan) RecordError(err error, _ ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+// SpanContext implements the trace.Span interface.
+func (s *errorSpan) SpanContext() trace.SpanContext { return s.parent.SpanContext() }
+
+// SetStatus implements the trace.Span interface.
+func (s *errorSpan) SetStatus(code codes.Code, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.code, s.desc = code, description
+}
+
+// SetName implements the trace.Span interface.
+func (s *errorSpan) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttributes implements the trace.Span interface.
+func (s *errorSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, kv...)
+}
+
+// TracerProvider implements the trace.Span interface.
+func (s *errorSpan) TracerProvider() trace.TracerProvider { return s.parent.TracerProvider() }
@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartSpanSampling(t *testing.T) {
+	const component = "github.com/example/catalog/TestStartSpanSampling"
+	RegisterTraceSampler(component, func(info CallInfo) bool { return info.Method == "Sampled" })
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "parent")
+
+	for _, test := range []struct {
+		method string
+		err    error
+		want   bool // want a span?
+	}{
+		{"Sampled", nil, true},
+		{"Unsampled", nil, false},
+		{"Unsampled", errors.New("boom"), true},
+	} {
+		before := len(recorder.Ended())
+		got, span := StartSpan(ctx, tracer, component, test.method, test.method, 0)
+		if test.method == "Unsampled" && got != ctx {
+			t.Errorf("%s: context changed", test.method)
+		}
+		if span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("%s: span not in the parent's trace", test.method)
+		}
+		if test.err != nil {
+			span.RecordError(test.err)
+			span.SetStatus(codes.Error, test.err.Error())
+		}
+		span.End()
+
+		ended := recorder.Ended()[before:]
+		if got := len(ended) == 1; got != test.want {
+			t.Errorf("%s (err=%v): got %d spans, want span %t", test.method, test.err, len(ended), test.want)
+			continue
+		}
+		if test.err != nil && ended[0].Status().Code != codes.Error {
+			t.Errorf("%s: got status %v, want %v", test.method, ended[0].Status().Code, codes.Error)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// A Codec encodes values of type T into bytes and decodes them back.
+type Codec[T any] interface {
+	Encode(T) []byte
+	Decode([]byte) (T, error)
+}
+
+// AutoMarshalCodec returns the Codec that serializes values of type T the way
+// component method arguments and results are serialized, i.e. using the
+// WeaverMarshal and WeaverUnmarshal methods generated by "weaver generate".
+func AutoMarshalCodec[T any, P interface {
+	*T
+	AutoMarshal
+}]() Codec[T] {
+	return autoMarshalCodec[T, P]{}
+}
+
+type autoMarshalCodec[T any, P interface {
+	*T
+	AutoMarshal
+}] struct{}
+
+// Encode implements the Codec interface.
+func (autoMarshalCodec[T, P]) Encode(x T) []byte {
+	enc := NewEncoder()
+	P(&x).WeaverMarshal(enc)
+	return enc.Data()
+}
+
+// Decode implements the Codec interface.
+func (autoMarshalCodec[T, P]) Decode(data []byte) (x T, err error) {
+	defer func() { err = CatchPanics(recover()) }()
+	dec := NewDecoder(data)
+	P(&x).WeaverUnmarshal(dec)
+	if !dec.Empty() {
+		return x, fmt.Errorf("%d unread bytes", len(dec.data))
+	}
+	return x, nil
+}
+
+// ProtoCodec returns the Codec that serializes protocol buffers of type T,
+// e.g., *pb.Product, in the standard protocol buffer wire format. Paired with
+// AutoMarshalCodec in a ShadowCodec, it can be used to check a migration of
+// a type from AutoMarshal to protocol buffers. Note that "weaver generate"
+// serializes component method arguments and results that are protocol
+// buffers with proto.Marshal already; see Encoder.EncodeProto.
+func ProtoCodec[T proto.Message]() Codec[T] {
+	return protoCodec[T]{}
+}
+
+type protoCodec[T proto.Message] struct{}
+
+// Encode implements the Codec interface.
+func (protoCodec[T]) Encode(x T) []byte {
+	data, err := proto.Marshal(x)
+	if err != nil {
+		panic(makeEncodeError("error encoding to proto %T: %w", x, err))
+	}
+	return data
+}
+
+// Decode implements the Codec interface.
+func (protoCodec[T]) Decode(data []byte) (T, error) {
+	// A nil pointer to a generated message still reports its message type.
+	var zero T
+	x := zero.ProtoReflect().Type().New().Interface().(T)
+	if err := proto.Unmarshal(data, x); err != nil {
+		return zero, fmt.Errorf("error decoding to proto %T: %w", x, err)
+	}
+	return x, nil
+}
+
+// ShadowOptions configure a ShadowCodec.
+type ShadowOptions[T any] struct {
+	// Logger logs divergences between the primary and shadow codecs. If nil,
+	// slog.Default() is used.
+	Logger *slog.Logger
+
+	// Equal reports whether two decoded values are equivalent. If nil,
+	// reflect.DeepEqual is used.
+	Equal func(x, y T) bool
+}
+
+// A ShadowCodec is a Codec that encodes and decodes values with a primary
+// codec, and additionally runs every encoded value through a shadow codec.
+// A ShadowCodec is used to migrate from one wire format to another: while the
+// primary (old) codec keeps serving traffic, the shadow (new) codec is
+// exercised on the same values, and any value that the two codecs do not
+// decode to equivalent results is logged as a divergence.
+//
+// A value diverges if the primary and shadow codecs decode it to values that
+// are not equal, if the shadow codec fails to decode its own encoding, or if
+// the shadow codec panics.
+type ShadowCodec[T any] struct {
+	primary     Codec[T]
+	shadow      Codec[T]
+	logger      *slog.Logger
+	equal       func(x, y T) bool
+	compared    atomic.Int64
+	divergences atomic.Int64
+}
+
+var _ Codec[int] = &ShadowCodec[int]{}
+
+// NewShadowCodec returns a ShadowCodec that serves traffic with primary and
+// compares it against shadow.
+func NewShadowCodec[T any](primary, shadow Codec[T], opts ShadowOptions[T]) *ShadowCodec[T] {
+	s := &ShadowCodec[T]{primary: primary, shadow: shadow, logger: opts.Logger, equal: opts.Equal}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	if s.equal == nil {
+		s.equal = func(x, y T) bool { return reflect.DeepEqual(x, y) }
+	}
+	return s
+}
+
+// Encode implements the Codec interface. It returns the encoding produced by
+// the primary codec.
+func (s *ShadowCodec[T]) Encode(x T) []byte {
+	data := s.primary.Encode(x)
+	s.compare(x, data)
+	return data
+}
+
+// Decode implements the Codec interface. It decodes data, which must have been
+// produced by the primary codec, using the primary codec.
+func (s *ShadowCodec[T]) Decode(data []byte) (T, error) {
+	return s.primary.Decode(data)
+}
+
+// Compared returns the number of values compared so far.
+func (s *ShadowCodec[T]) Compared() int64 {
+	return s.compared.Load()
+}
+
+// Divergences returns the number of values for which the primary and shadow
+// codecs have diverged so far.
+func (s *ShadowCodec[T]) Divergences() int64 {
+	return s.divergences.Load()
+}
+
+// compare runs x through the shadow codec and compares the result with the
+// decoding of data, the primary encoding of x.
+func (s *ShadowCodec[T]) compare(x T, data []byte) {
+	s.compared.Add(1)
+	want, err := s.primary.Decode(data)
+	if err != nil {
+		// The primary codec is broken; there is nothing to compare against.
+		s.diverge("primary decode failed", "err", err)
+		return
+	}
+
+	var got T
+	var shadowData []byte
+	err = func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("shadow codec panicked: %v", r)
+			}
+		}()
+		shadowData = s.shadow.Encode(x)
+		got, err = s.shadow.Decode(shadowData)
+		return err
+	}()
+	switch {
+	case err != nil:
+		s.diverge("shadow codec failed", "err", err)
+	case !s.equal(want, got):
+		s.diverge("decoded values differ",
+			"primary", fmt.Sprintf("%+v", want),
+			"shadow", fmt.Sprintf("%+v", got),
+			"primary_bytes", len(data),
+			"shadow_bytes", len(shadowData))
+	}
+}
+
+// diverge records and logs a divergence.
+func (s *ShadowCodec[T]) diverge(msg string, attrs ...any) {
+	s.divergences.Add(1)
+	var x T
+	attrs = append([]any{"type", fmt.Sprintf("%T", x)}, attrs...)
+	s.logger.Error("codec divergence: "+msg, attrs...)
+}
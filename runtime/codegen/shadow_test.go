@@ -0,0 +1,177 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// order is a hand-serialized struct used to test ShadowCodec.
+type order struct {
+	ID   int64
+	Qty  int64
+	Note string
+}
+
+func (o *order) WeaverMarshal(enc *Encoder) {
+	enc.Int64(o.ID)
+	enc.Int64(o.Qty)
+	enc.String(o.Note)
+}
+
+func (o *order) WeaverUnmarshal(dec *Decoder) {
+	o.ID = dec.Int64()
+	o.Qty = dec.Int64()
+	o.Note = dec.String()
+}
+
+// varintCodec encodes orders using varints. If truncate is true, it
+// deliberately truncates quantities to 32 bits.
+type varintCodec struct {
+	truncate bool
+}
+
+func (c varintCodec) Encode(o order) []byte {
+	qty := o.Qty
+	if c.truncate {
+		qty = int64(int32(qty))
+	}
+	var b []byte
+	b = binary.AppendVarint(b, o.ID)
+	b = binary.AppendVarint(b, qty)
+	return append(b, o.Note...)
+}
+
+func (c varintCodec) Decode(data []byte) (order, error) {
+	var o order
+	var n int
+	if o.ID, n = binary.Varint(data); n <= 0 {
+		return order{}, fmt.Errorf("bad id")
+	}
+	data = data[n:]
+	if o.Qty, n = binary.Varint(data); n <= 0 {
+		return order{}, fmt.Errorf("bad qty")
+	}
+	o.Note = string(data[n:])
+	return o, nil
+}
+
+// panickingCodec panics when encoding an order with an empty note.
+type panickingCodec struct{ varintCodec }
+
+func (c panickingCodec) Encode(o order) []byte {
+	if o.Note == "" {
+		panic("empty note")
+	}
+	return c.varintCodec.Encode(o)
+}
+
+var orders = []order{
+	{},
+	{ID: 1, Qty: 2, Note: "hello"},
+	{ID: -1, Qty: math.MaxInt32, Note: "max"},
+	{ID: math.MaxInt64, Qty: math.MaxInt32 + 1, Note: "overflow"},
+	{ID: math.MinInt64, Qty: math.MinInt64, Note: "min"},
+}
+
+func TestAutoMarshalCodec(t *testing.T) {
+	codec := AutoMarshalCodec[order]()
+	for _, want := range orders {
+		got, err := codec.Decode(codec.Encode(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	if _, err := codec.Decode([]byte{1, 2, 3}); err == nil {
+		t.Error("unexpected success decoding a truncated order")
+	}
+}
+
+func TestProtoCodec(t *testing.T) {
+	codec := ProtoCodec[*wrapperspb.StringValue]()
+	for _, s := range []string{"", "hello", strings.Repeat("x", 1000)} {
+		want := wrapperspb.String(s)
+		got, err := codec.Decode(codec.Encode(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	if _, err := codec.Decode([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Error("unexpected success decoding a truncated message")
+	}
+}
+
+func TestShadowCodec(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		shadow Codec[order]
+		want   int64 // expected number of divergences
+		log    string
+	}{
+		{"Equivalent", varintCodec{}, 0, ""},
+		{"Divergent", varintCodec{truncate: true}, 2, "decoded values differ"},
+		{"Panicking", panickingCodec{}, 1, "empty note"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			primary := AutoMarshalCodec[order]()
+			s := NewShadowCodec(primary, test.shadow, ShadowOptions[order]{
+				Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+			})
+			for _, want := range orders {
+				// The shadow codec must not affect the primary encoding.
+				data := s.Encode(want)
+				if !bytes.Equal(data, primary.Encode(want)) {
+					t.Fatalf("Encode(%v): got a non-primary encoding", want)
+				}
+				got, err := s.Decode(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Fatalf("got %v, want %v", got, want)
+				}
+			}
+
+			if got, want := s.Compared(), int64(len(orders)); got != want {
+				t.Errorf("Compared: got %d, want %d", got, want)
+			}
+			if got := s.Divergences(); got != test.want {
+				t.Errorf("Divergences: got %d, want %d", got, test.want)
+			}
+			if got := strings.Count(logs.String(), "codec divergence"); int64(got) != test.want {
+				t.Errorf("got %d logged divergences, want %d:\n%s", got, test.want, logs.String())
+			}
+			if !strings.Contains(logs.String(), test.log) {
+				t.Errorf("logs do not contain %q:\n%s", test.log, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+)
+
+// OverloadedError is returned, marked as retryable, by a remote call to a
+// component that has too many calls in flight. See weaver.OverloadedError.
+var OverloadedError = errors.New("Service Weaver component is overloaded")
+
+// shedKey is the context key that marks a call to be shed.
+type shedKey struct{}
+
+// Shed returns a context that marks the call it is passed to as shed. The
+// server stub of a shed call reports the call's load, but rejects the call
+// with a retryable OverloadedError instead of invoking the component method.
+func Shed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shedKey{}, true)
+}
+
+// CheckShed returns a retryable OverloadedError if ctx was marked by Shed, or
+// nil otherwise.
+//
+// NOTE that this function should be called only in the generated code.
+func CheckShed(ctx context.Context) error {
+	if shed, _ := ctx.Value(shedKey{}).(bool); shed {
+		return Retryable(OverloadedError)
+	}
+	return nil
+}
@@ -0,0 +1,408 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/internal/session"
+	"github.com/ServiceWeaver/weaver/metadata"
+	"github.com/google/uuid"
+)
+
+// A component method that returns an io.ReadCloser streams the body of the
+// reader to a remote caller in chunks, rather than buffering it in full.
+//
+// The first chunk is sent in the reply to the method call, along with the id
+// of the stream. The caller fetches every subsequent chunk with another call
+// to the same method, made once the previous chunk has been read. These calls
+// carry the id of the stream in their session token (see
+// metadata.WithSession), so they are routed to the replica that holds the
+// stream, and the server stub hands them to ServeStream instead of calling the
+// method again. Because a chunk is read from the server's reader only when
+// the caller asks for it, a slow caller slows down the server's reader, and a
+// caller that closes its reader early closes the server's reader too.
+//
+// A chunk request carries the offset of the chunk, so a request that is
+// retried or hedged returns the same chunk again, rather than skipping one.
+
+const (
+	// streamChunkSize is the maximum size of a chunk of a stream.
+	streamChunkSize = 64 << 10
+
+	// streamTokenPrefix prefixes the session token issued by the call that
+	// opens a stream. The token records the replica that holds the stream.
+	streamTokenPrefix = "\x00serviceweaver/stream/"
+
+	// chunkTokenPrefix prefixes the session token of the calls that fetch the
+	// chunks of a stream. It differs from streamTokenPrefix so that a retry
+	// of the call that opened the stream isn't mistaken for a chunk request.
+	chunkTokenPrefix = "\x00serviceweaver/chunk/"
+)
+
+// streamIdleTimeout is how long a server keeps a stream that no caller reads
+// from before closing it. It is a variable so that tests can change it.
+var streamIdleTimeout = time.Minute
+
+// streams holds the open streams of the process, by id.
+var streams = struct {
+	mu sync.Mutex
+	m  map[string]*serverStream
+}{m: map[string]*serverStream{}}
+
+// serverStream is the server side of a stream.
+type serverStream struct {
+	id        string
+	rc        io.ReadCloser
+	closeOnce sync.Once
+	closeErr  error // the error returned by rc.Close
+
+	mu     sync.Mutex  // serializes reads
+	timer  *time.Timer // closes the stream once it is idle
+	buf    []byte      // holds the last chunk
+	last   []byte      // the last chunk read from rc
+	offset uint64      // offset of the chunk that follows last
+	eof    bool        // whether last is the final chunk
+	err    error       // the error that ended the stream, if any
+}
+
+// WithStream returns the context used to call a component method that
+// returns an io.ReadCloser. Calls made with the returned context don't take
+// part in the session of ctx, if any.
+//
+// NOTE that this function should be called only in the generated code.
+func WithStream(ctx context.Context) context.Context {
+	return session.WithJar(ctx)
+}
+
+// EncodeStream encodes the provided reader, returned by a component method
+// along with the provided error, and the first chunk of its body. If err is
+// not nil, the reader is closed and encoded as nil.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeStream(ctx context.Context, enc *Encoder, rc io.ReadCloser, err error) {
+	if rc != nil && err != nil {
+		rc.Close()
+		rc = nil
+	}
+	if rc == nil {
+		enc.Bool(false)
+		return
+	}
+	enc.Bool(true)
+
+	s := &serverStream{id: uuid.NewString(), rc: rc, buf: make([]byte, streamChunkSize)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.read(ctx)
+	if s.eof {
+		// The whole body fits in the first chunk. There's no need to hold on
+		// to the stream.
+		enc.String("")
+		s.encode(enc)
+		return
+	}
+	s.timer = time.AfterFunc(streamIdleTimeout, func() { s.close() })
+	streams.mu.Lock()
+	streams.m[s.id] = s
+	streams.mu.Unlock()
+	metadata.SetSessionToken(ctx, streamTokenPrefix+s.id)
+	enc.String(s.id)
+	s.encode(enc)
+}
+
+// ServeStream serves a request for a chunk of a stream, if ctx belongs to
+// one. It returns false if ctx belongs to a regular method call instead.
+//
+// NOTE that this function should be called only in the generated code.
+func ServeStream(ctx context.Context, args []byte) ([]byte, bool) {
+	token, _ := metadata.SessionToken(ctx)
+	id, ok := strings.CutPrefix(token, chunkTokenPrefix)
+	if !ok {
+		return nil, false
+	}
+	dec := NewDecoder(args)
+	offset := dec.Uint64()
+	closing := dec.Bool()
+
+	streams.mu.Lock()
+	s := streams.m[id]
+	streams.mu.Unlock()
+
+	enc := NewEncoder()
+	switch {
+	case s == nil:
+		// The stream has been closed already, or it has expired.
+		if closing {
+			enc.Bool(true)
+			enc.String("")
+		} else {
+			enc.Bytes(nil)
+			enc.Bool(true)
+			enc.String(fmt.Sprintf("stream %s not found", id))
+		}
+	case closing:
+		enc.Bool(true)
+		if err := s.close(); err != nil {
+			enc.String(err.Error())
+		} else {
+			enc.String("")
+		}
+	default:
+		s.next(ctx, offset, enc)
+	}
+	return enc.Data(), true
+}
+
+// next encodes the chunk of s at the provided offset.
+func (s *serverStream) next(ctx context.Context, offset uint64, enc *Encoder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timer.Reset(streamIdleTimeout)
+	switch {
+	case offset == s.offset-uint64(len(s.last)):
+		// A retried or hedged request for the last chunk.
+	case offset != s.offset:
+		enc.Bytes(nil)
+		enc.Bool(true)
+		enc.String(fmt.Sprintf("stream %s: chunk at offset %d requested, but the stream is at offset %d", s.id, offset, s.offset))
+		return
+	default:
+		s.read(ctx)
+	}
+	s.encode(enc)
+}
+
+// read reads the next chunk of s. If ctx is canceled while reading, the
+// stream is closed.
+//
+// REQUIRES: s.mu is held.
+func (s *serverStream) read(ctx context.Context) {
+	if s.eof {
+		return
+	}
+	stop := context.AfterFunc(ctx, func() { s.closeReader() })
+	defer stop()
+
+	var n int
+	var err error
+	for n == 0 && err == nil {
+		n, err = s.rc.Read(s.buf)
+	}
+	s.last = s.buf[:n]
+	s.offset += uint64(n)
+	if err == nil {
+		return
+	}
+	s.eof = true
+	if !errors.Is(err, io.EOF) {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		s.err = err
+	}
+	// Close the reader right away, but remember the final chunk until the
+	// stream is idle, in case its request is retried.
+	s.closeReader()
+}
+
+// encode encodes the last chunk read from s.
+//
+// REQUIRES: s.mu is held.
+func (s *serverStream) encode(enc *Encoder) {
+	enc.Bytes(s.last)
+	enc.Bool(s.eof)
+	if s.err != nil {
+		enc.String(s.err.Error())
+	} else {
+		enc.String("")
+	}
+}
+
+// closeReader closes the reader of s, stopping any read in progress, and
+// returns the error returned by its Close method.
+func (s *serverStream) closeReader() error {
+	s.closeOnce.Do(func() { s.closeErr = s.rc.Close() })
+	return s.closeErr
+}
+
+// close closes s and forgets it.
+func (s *serverStream) close() error {
+	err := s.closeReader()
+	s.mu.Lock()
+	s.timer.Stop()
+	s.mu.Unlock()
+	streams.mu.Lock()
+	delete(streams.m, s.id)
+	streams.mu.Unlock()
+	return err
+}
+
+// DecodeStream decodes a reader encoded by EncodeStream in the reply to a call
+// to the provided method of the provided stub, made with ctx. The returned
+// reader fetches the rest of the body through the stub as it is read.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeStream(ctx context.Context, stub Stub, method int, shardKey uint64, dec *Decoder) io.ReadCloser {
+	if !dec.Bool() {
+		return nil
+	}
+	s := &clientStream{stub: stub, method: method, shardKey: shardKey}
+	id := dec.String()
+	s.decode(dec)
+	if id == "" {
+		s.closed = true
+		return s
+	}
+
+	// Route the chunk requests to the replica that issued the stream token.
+	var replica string
+	if jar, ok := session.JarFromContext(ctx); ok {
+		_, replica = jar.Get()
+	}
+	s.ctx = session.WithJar(ctx)
+	jar, _ := session.JarFromContext(s.ctx)
+	jar.Set(chunkTokenPrefix+id, replica)
+	return s
+}
+
+// clientStream is the client side of a stream.
+type clientStream struct {
+	ctx      context.Context
+	stub     Stub
+	method   int
+	shardKey uint64
+
+	mu     sync.Mutex
+	chunk  []byte // the unread part of the last chunk
+	offset uint64 // offset of the next chunk
+	eof    bool   // whether the last chunk is the final one
+	err    error  // the error returned once the body has been read
+	closed bool   // whether the server side of the stream is closed
+}
+
+var _ io.ReadCloser = &clientStream{}
+
+// Read implements the io.Reader interface.
+func (s *clientStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.chunk) == 0 {
+		if s.eof {
+			return 0, s.err
+		}
+		if err := s.fetch(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.chunk)
+	s.chunk = s.chunk[n:]
+	return n, nil
+}
+
+// fetch fetches the next chunk of the stream.
+//
+// REQUIRES: s.mu is held.
+func (s *clientStream) fetch() (err error) {
+	defer func() {
+		if err == nil {
+			err = CatchPanics(recover())
+		}
+		if err != nil {
+			s.eof, s.err = true, err
+			s.closeServer()
+		}
+	}()
+	enc := NewEncoder()
+	defer enc.Release()
+	enc.Uint64(s.offset)
+	enc.Bool(false)
+	results, err := s.stub.Run(s.ctx, s.method, enc.Data(), s.shardKey)
+	if err != nil {
+		return err
+	}
+	s.decode(NewDecoder(results))
+	if s.eof {
+		s.closed = true
+	}
+	if len(s.chunk) == 0 && !s.eof {
+		return fmt.Errorf("stream: empty chunk at offset %d", s.offset)
+	}
+	return nil
+}
+
+// decode decodes a chunk of the stream.
+//
+// REQUIRES: s.mu is held.
+func (s *clientStream) decode(dec *Decoder) {
+	s.chunk = dec.Bytes()
+	s.offset += uint64(len(s.chunk))
+	s.eof = dec.Bool()
+	s.err = io.EOF
+	if msg := dec.String(); msg != "" {
+		s.err = errors.New(msg)
+	}
+}
+
+// Close implements the io.Closer interface. Closing a stream before reading
+// its whole body closes the reader returned by the component method.
+func (s *clientStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunk, s.eof = nil, true
+	if s.err == nil || s.err == io.EOF {
+		s.err = errors.New("stream: read on closed stream")
+	}
+	return s.closeServer()
+}
+
+// closeServer closes the server side of the stream, if it is still open.
+//
+// REQUIRES: s.mu is held.
+func (s *clientStream) closeServer() (err error) {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	defer func() {
+		if err == nil {
+			err = CatchPanics(recover())
+		}
+	}()
+
+	// Close the stream even if ctx has been canceled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), 10*time.Second)
+	defer cancel()
+	enc := NewEncoder()
+	defer enc.Release()
+	enc.Uint64(s.offset)
+	enc.Bool(true)
+	results, err := s.stub.Run(ctx, s.method, enc.Data(), s.shardKey)
+	if err != nil {
+		return err
+	}
+	dec := NewDecoder(results)
+	dec.Bool()
+	if msg := dec.String(); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// streamStub is a Stub for a method that returns the reader returned by
+// open, the way a generated server stub would serve it.
+type streamStub struct {
+	open  func() io.ReadCloser
+	calls int  // number of calls to Run
+	twice bool // if true, run every chunk request twice
+}
+
+var _ Stub = &streamStub{}
+
+func (s *streamStub) Tracer() trace.Tracer { return nil }
+
+func (s *streamStub) Run(ctx context.Context, _ int, args []byte, _ uint64) ([]byte, error) {
+	s.calls++
+	if res, ok := ServeStream(ctx, args); ok {
+		if s.twice {
+			res, _ = ServeStream(ctx, args)
+		}
+		return res, nil
+	}
+	enc := NewEncoder()
+	EncodeStream(ctx, enc, s.open(), nil)
+	return enc.Data(), nil
+}
+
+// call calls the streamed method of the provided stub, like a generated client
+// stub would.
+func (s *streamStub) call(ctx context.Context) io.ReadCloser {
+	ctx = WithStream(ctx)
+	results, err := s.Run(ctx, 0, nil, 0)
+	if err != nil {
+		panic(err)
+	}
+	return DecodeStream(ctx, s, 0, 0, NewDecoder(results))
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name   string
+		size   int
+		twice  bool
+		chunks int // the expected number of calls
+	}{
+		{"Empty", 0, false, 1},
+		{"OneChunk", streamChunkSize, false, 1},
+		{"ManyChunks", 3*streamChunkSize + 100, false, 4},
+		{"RetriedChunks", 3*streamChunkSize + 100, true, 4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			want := make([]byte, test.size)
+			rand.Read(want)
+			stub := &streamStub{
+				open:  func() io.ReadCloser { return io.NopCloser(bytes.NewReader(want)) },
+				twice: test.twice,
+			}
+			r := stub.call(ctx)
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("read %d bytes, want %d", len(got), len(want))
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+			// A body shorter than a chunk is returned in full. Other
+			// bodies take an extra call to learn that the stream has ended.
+			if test.size >= streamChunkSize {
+				test.chunks++
+			}
+			if stub.calls != test.chunks {
+				t.Errorf("calls: got %d, want %d", stub.calls, test.chunks)
+			}
+		})
+	}
+}
+
+func TestStreamNil(t *testing.T) {
+	stub := &streamStub{open: func() io.ReadCloser { return nil }}
+	if r := stub.call(context.Background()); r != nil {
+		t.Fatalf("got %v, want nil", r)
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	want := errors.New("disk on fire")
+	stub := &streamStub{open: func() io.ReadCloser {
+		r := io.MultiReader(strings.NewReader(strings.Repeat("x", 2*streamChunkSize)), iotestErrReader{want})
+		return io.NopCloser(r)
+	}}
+	r := stub.call(context.Background())
+	_, err := io.ReadAll(r)
+	if err == nil || err.Error() != want.Error() {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+}
+
+// iotestErrReader is a reader that always fails with the provided error.
+type iotestErrReader struct{ err error }
+
+func (r iotestErrReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestStreamBackpressureAndClose(t *testing.T) {
+	// The server writes an endless body into a pipe. It can only write as
+	// fast as the client reads, and it stops once the client closes the
+	// reader.
+	var written atomic.Int64
+	done := make(chan error, 1)
+	stub := &streamStub{open: func() io.ReadCloser {
+		pr, pw := io.Pipe()
+		go func() {
+			chunk := make([]byte, 1024)
+			for {
+				n, err := pw.Write(chunk)
+				written.Add(int64(n))
+				if err != nil {
+					done <- err
+					return
+				}
+			}
+		}()
+		return pr
+	}}
+	r := stub.call(context.Background())
+	buf := make([]byte, 10*streamChunkSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got, limit := written.Load(), int64(n+2*streamChunkSize); got > limit {
+		t.Errorf("server wrote %d bytes while the client read %d", got, n)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("server write: got %v, want %v", err, io.ErrClosedPipe)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server still writing after the client closed the stream")
+	}
+	if _, err := r.Read(buf); err == nil {
+		t.Error("read after close: unexpected success")
+	}
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	old := streamIdleTimeout
+	streamIdleTimeout = 10 * time.Millisecond
+	defer func() { streamIdleTimeout = old }()
+
+	var closed atomic.Bool
+	stub := &streamStub{open: func() io.ReadCloser {
+		body := strings.NewReader(strings.Repeat("x", 2*streamChunkSize))
+		return closeFunc{body, func() { closed.Store(true) }}
+	}}
+	r := stub.call(context.Background())
+	if _, err := r.Read(make([]byte, streamChunkSize)); err != nil {
+		t.Fatal(err)
+	}
+	for !closed.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := r.Read(make([]byte, streamChunkSize)); err == nil {
+		t.Error("read of expired stream: unexpected success")
+	}
+}
+
+// closeFunc is an io.ReadCloser that calls a function when closed.
+type closeFunc struct {
+	io.Reader
+	close func()
+}
+
+func (c closeFunc) Close() error {
+	c.close()
+	return nil
+}
@@ -15,8 +15,13 @@
 package codegen
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
+	"github.com/ServiceWeaver/weaver/metadata"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -34,9 +39,187 @@ type Stub interface {
 	// ordered. method is the index into this slice. args and results are the
 	// serialized arguments and results, respectively. shardKey is the shard
 	// key for routed components, and 0 otherwise.
+	//
+	// Method indices never leave the process. Remote calls identify methods
+	// by component and method name, so adding, removing, renaming, or
+	// reordering methods doesn't change how calls to other methods are sent.
 	Run(ctx context.Context, method int, args []byte, shardKey uint64) (results []byte, err error)
 }
 
+// An AccessLogger is a Stub that logs a sample of the method calls made
+// through it. Client stubs report every finished call to LogAccess.
+type AccessLogger interface {
+	// LogAccess logs, if sampled, a call to the provided method that took the
+	// provided duration and returned the provided error.
+	LogAccess(ctx context.Context, method int, duration time.Duration, err error)
+}
+
+// LogAccess reports a finished method call, started at the provided handle,
+// to stub if it is an AccessLogger.
+//
+// NOTE that this function should be called only in the generated code.
+func LogAccess(ctx context.Context, stub Stub, method int, h MethodCallHandle, err error) {
+	if l, ok := stub.(AccessLogger); ok {
+		l.LogAccess(ctx, method, clock.Since(h.start), err)
+	}
+}
+
+// A Hedger is a Stub that hedges calls to some of its methods. If a call to a
+// hedged method hasn't returned after the method's hedging delay, client stubs
+// send a second, identical call and use whichever reply arrives first.
+type Hedger interface {
+	// HedgeDelay returns how long to wait before hedging a call to the
+	// provided method, or zero if calls to the method are not hedged.
+	HedgeDelay(method int) time.Duration
+}
+
+// A Deadliner is a Stub that bounds how long calls to its methods take.
+// Client stubs apply the deadline of a method to every call to the method
+// whose context doesn't have an earlier deadline.
+type Deadliner interface {
+	// CallDeadline returns how long a call to the provided method is allowed
+	// to take, or zero if calls to the method are not bounded.
+	CallDeadline(method int) time.Duration
+}
+
+// A Queuer is a Stub that reports how long its calls spend queued.
+type Queuer interface {
+	// RunQueued is like Run, but also returns how long the call spent queued
+	// before the method started executing, both in the caller and in the
+	// callee.
+	RunQueued(ctx context.Context, method int, args []byte, shardKey uint64) (results []byte, queue time.Duration, err error)
+}
+
+// A Cacher is a Stub that caches the replies of calls to some of its methods.
+// The encoded results of a call include the error returned by the method, so
+// a Cacher can't tell failed calls apart from successful ones. Client stubs
+// instead report the replies of successful calls to CacheReply once they have
+// decoded them.
+type Cacher interface {
+	// CacheReply caches the reply of a successful call to the provided
+	// method with the provided serialized arguments, if the replies of the
+	// method are cached.
+	CacheReply(method int, args, reply []byte)
+}
+
+// CacheReply reports the reply of a successful call to the provided method,
+// i.e., a call whose method returned a nil error, to stub if it is a Cacher.
+//
+// NOTE that this function should be called only in the generated code.
+func CacheReply(stub Stub, method int, args, reply []byte) {
+	if c, ok := stub.(Cacher); ok {
+		c.CacheReply(method, args, reply)
+	}
+}
+
+// Run executes the provided method on stub, like stub.Run, and records in h
+// how long the call spent queued if stub is a Queuer. If the call has a
+// deadline, Run also records in h how much time was left until the deadline
+// when the call started, so that End can report the fraction of it that the
+// call took.
+//
+// If stub is a Hedger that hedges the method and the call hasn't returned
+// after the hedging delay, Run races a second call against the first one. It
+// returns the first successful reply, or the first error if both calls fail,
+// and cancels the other call. The second call is sent one priority level
+// lower than the first (see metadata.WithPriority).
+//
+// If stub is a Deadliner that bounds the method, the call, including any
+// hedged call, fails with context.DeadlineExceeded if it doesn't return
+// within the method's deadline.
+//
+// NOTE that this function should be called only in the generated code.
+func Run(ctx context.Context, stub Stub, h *MethodCallHandle, method int, args []byte, shardKey uint64) ([]byte, error) {
+	// Find the budget of the call, i.e., the time left until its effective
+	// deadline: the earlier of the deadline of ctx and the deadline of the
+	// method, if any.
+	var budget time.Duration
+	deadline, bounded := ctx.Deadline()
+	if bounded {
+		budget = deadline.Sub(h.start)
+	}
+	if d, ok := stub.(Deadliner); ok {
+		if timeout := d.CallDeadline(method); timeout > 0 {
+			// Note that an earlier deadline of ctx is preserved.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if !bounded || timeout < budget {
+				budget, bounded = timeout, true
+			}
+		}
+	}
+	if bounded && budget > 0 && h.budget == 0 {
+		// Retries of the call share the budget of the first attempt.
+		h.budget = budget
+	}
+	var delay time.Duration
+	if hedger, ok := stub.(Hedger); ok {
+		delay = hedger.HedgeDelay(method)
+	}
+	var r reply
+	if delay <= 0 {
+		r = runOnce(ctx, stub, method, args, shardKey)
+	} else {
+		r = runHedged(ctx, stub, delay, method, args, shardKey)
+	}
+	h.queue = r.queue
+	return r.results, r.err
+}
+
+// reply is the outcome of a call to a stub.
+type reply struct {
+	results []byte
+	queue   time.Duration
+	err     error
+}
+
+// runOnce executes the provided method on stub.
+func runOnce(ctx context.Context, stub Stub, method int, args []byte, shardKey uint64) reply {
+	if q, ok := stub.(Queuer); ok {
+		results, queue, err := q.RunQueued(ctx, method, args, shardKey)
+		return reply{results, queue, err}
+	}
+	results, err := stub.Run(ctx, method, args, shardKey)
+	return reply{results: results, err: err}
+}
+
+// runHedged executes the provided method on stub, hedging the call after the
+// provided delay.
+func runHedged(ctx context.Context, stub Stub, delay time.Duration, method int, args []byte, shardKey uint64) reply {
+	// The losing call may still be running when we return, and the caller is
+	// free to reuse args once we do, so both calls use a copy of args.
+	args = bytes.Clone(args)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	replies := make(chan reply, 2)
+	run := func(ctx context.Context) {
+		replies <- runOnce(ctx, stub, method, args, shardKey)
+	}
+
+	go run(ctx)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case r := <-replies:
+		return r
+	case <-timer.C:
+		// Send the hedged call at a lower priority than the first one, so
+		// that it doesn't hold up other calls at a busy server.
+		go run(metadata.WithPriority(ctx, metadata.Priority(ctx)-1))
+	}
+
+	first := <-replies
+	if first.err == nil {
+		return first
+	}
+	if second := <-replies; second.err == nil {
+		return second
+	}
+	return first
+}
+
 // A Server allows a Service Weaver component in one process to receive and execute
 // methods via RPC from a Service Weaver component in a different process. It is the
 // dual of a Stub.
@@ -49,3 +232,22 @@ type Server interface {
 	// TODO(mwhittaker): Rename GetHandler? This is returning a call.Handler.
 	GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error)
 }
+
+// MethodHandler returns the handler of the method with the provided index of
+// the component registered as reg and served by server. If the component has
+// no such method, because the caller was built from a newer version of the
+// component for example, the returned handler fails with a retryable error
+// that wraps UnknownMethodError, so that the caller can retry the call against
+// an up-to-date replica.
+func MethodHandler(reg *Registration, server Server, method int) func(ctx context.Context, args []byte) ([]byte, error) {
+	name := fmt.Sprintf("#%d", method)
+	if method >= 0 && method < reg.Iface.NumMethod() {
+		name = reg.Iface.Method(method).Name
+		if fn := server.GetStubFn(name); fn != nil {
+			return fn
+		}
+	}
+	return func(context.Context, []byte) ([]byte, error) {
+		return nil, UnknownMethod(reg.Name, name)
+	}
+}
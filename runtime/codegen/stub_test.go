@@ -0,0 +1,203 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/metadata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// hedgedStub is a Stub and Hedger whose calls run the provided function.
+type hedgedStub struct {
+	delay time.Duration
+	calls atomic.Int32
+	run   func(ctx context.Context, call int32) ([]byte, error)
+}
+
+func (s *hedgedStub) Tracer() trace.Tracer { return nil }
+
+func (s *hedgedStub) HedgeDelay(int) time.Duration { return s.delay }
+
+func (s *hedgedStub) Run(ctx context.Context, _ int, _ []byte, _ uint64) ([]byte, error) {
+	return s.run(ctx, s.calls.Add(1))
+}
+
+func TestRunFastCall(t *testing.T) {
+	// A call that returns before the hedging delay is not hedged.
+	stub := &hedgedStub{
+		delay: time.Hour,
+		run: func(context.Context, int32) ([]byte, error) {
+			return []byte("first"), nil
+		},
+	}
+	got, err := Run(context.Background(), stub, &MethodCallHandle{}, 0, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first" {
+		t.Fatalf("Run: got %q, want %q", got, "first")
+	}
+	if n := stub.calls.Load(); n != 1 {
+		t.Fatalf("calls: got %d, want 1", n)
+	}
+}
+
+func TestRunSlowCall(t *testing.T) {
+	// The first call hangs until canceled, so the hedged call wins.
+	canceled := make(chan struct{})
+	stub := &hedgedStub{
+		delay: time.Millisecond,
+		run: func(ctx context.Context, call int32) ([]byte, error) {
+			if call == 2 {
+				return []byte("second"), nil
+			}
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		},
+	}
+	got, err := Run(context.Background(), stub, &MethodCallHandle{}, 0, []byte("args"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "second" {
+		t.Fatalf("Run: got %q, want %q", got, "second")
+	}
+	select {
+	case <-canceled:
+	case <-time.After(10 * time.Second):
+		t.Fatal("losing call not canceled")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	// A failed call doesn't hide a successful one.
+	errFirst := errors.New("first")
+	stub := &hedgedStub{
+		delay: time.Millisecond,
+		run: func(ctx context.Context, call int32) ([]byte, error) {
+			if call == 1 {
+				time.Sleep(10 * time.Millisecond)
+				return nil, errFirst
+			}
+			time.Sleep(20 * time.Millisecond)
+			return []byte("second"), nil
+		},
+	}
+	got, err := Run(context.Background(), stub, &MethodCallHandle{}, 0, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "second" {
+		t.Fatalf("Run: got %q, want %q", got, "second")
+	}
+
+	// If both calls fail, the first error is returned.
+	stub = &hedgedStub{
+		delay: time.Millisecond,
+		run: func(ctx context.Context, call int32) ([]byte, error) {
+			if call == 1 {
+				time.Sleep(10 * time.Millisecond)
+				return nil, errFirst
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil, errors.New("second")
+		},
+	}
+	if _, err := Run(context.Background(), stub, &MethodCallHandle{}, 0, nil, 0); !errors.Is(err, errFirst) {
+		t.Fatalf("Run: got %v, want %v", err, errFirst)
+	}
+}
+
+func TestRunHedgedPriority(t *testing.T) {
+	// The hedged call is sent one priority level lower than the first call.
+	priorities := make(chan [2]int, 2)
+	stub := &hedgedStub{
+		delay: time.Millisecond,
+		run: func(ctx context.Context, call int32) ([]byte, error) {
+			priorities <- [2]int{int(call), metadata.Priority(ctx)}
+			if call == 2 {
+				return []byte("second"), nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	ctx := metadata.WithPriority(context.Background(), 5)
+	if _, err := Run(ctx, stub, &MethodCallHandle{}, 0, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]int{1: 5, 2: 4}
+	for i := 0; i < 2; i++ {
+		p := <-priorities
+		if got := p[1]; got != want[p[0]] {
+			t.Errorf("call %d priority: got %d, want %d", p[0], got, want[p[0]])
+		}
+	}
+}
+
+// deadlineStub is a Stub and Deadliner whose calls report their deadline.
+type deadlineStub struct {
+	deadline time.Duration
+}
+
+func (s *deadlineStub) Tracer() trace.Tracer { return nil }
+
+func (s *deadlineStub) CallDeadline(int) time.Duration { return s.deadline }
+
+func (s *deadlineStub) Run(ctx context.Context, _ int, _ []byte, _ uint64) ([]byte, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil, nil
+	}
+	return []byte(time.Until(deadline).Round(time.Minute).String()), nil
+}
+
+func TestRunDeadline(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		deadline time.Duration // deadline of the stub
+		timeout  time.Duration // timeout of the caller's context, if positive
+		want     string        // deadline seen by the call, rounded
+	}{
+		{"Unbounded", 0, 0, ""},
+		{"CallerOnly", 0, time.Hour, "1h0m0s"},
+		{"StubOnly", time.Hour, 0, "1h0m0s"},
+		{"EarlierCaller", 2 * time.Hour, time.Hour, "1h0m0s"},
+		{"EarlierStub", time.Hour, 2 * time.Hour, "1h0m0s"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+			got, err := Run(ctx, &deadlineStub{test.deadline}, &MethodCallHandle{}, 0, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("deadline: got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
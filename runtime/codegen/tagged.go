@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Tagged structs
+//
+// An AutoMarshal struct marked with a //weaver:tagged directive is encoded as
+// a sequence of fields followed by a varint 0. Every field is encoded as its
+// varint field number, the varint length of its encoding, and its encoding.
+// Field numbers are assigned with `weaver:"tag=N"` struct tags. For example,
+// a tagged struct with a string field "abc" tagged 1 and an int32 field tagged
+// 2 is encoded as
+//
+//     1 | 7 | <string "abc"> | 2 | 4 | <int32> | 0
+//
+// The length lets a decoder skip the fields it doesn't know about, like the
+// fields added to a newer version of the struct, and the fields that the
+// decoder doesn't find are left as zero values. This makes it safe to add
+// fields to a tagged struct, at the cost of two bytes per field for field
+// numbers and encodings smaller than 128.
+
+// EncodeTaggedField encodes the field with the provided number into enc. The
+// field's value is encoded by f.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeTaggedField(enc *Encoder, field uint32, f func(enc *Encoder)) {
+	enc.data = binary.AppendUvarint(enc.data, uint64(field))
+	start := len(enc.data)
+	f(enc)
+
+	// The length of the field's encoding is only known once it is encoded,
+	// so shift the encoding to make room for the length.
+	n := len(enc.data) - start
+	var length [binary.MaxVarintLen64]byte
+	k := binary.PutUvarint(length[:], uint64(n))
+	enc.Grow(k)
+	copy(enc.data[start+k:], enc.data[start:start+n])
+	copy(enc.data[start:], length[:k])
+}
+
+// EncodeTaggedEnd encodes the end of a tagged struct into enc.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeTaggedEnd(enc *Encoder) {
+	enc.Uint8(0)
+}
+
+// DecodeTaggedFields decodes the fields of a tagged struct that were encoded
+// using EncodeTaggedField and EncodeTaggedEnd. For every field, it calls f
+// with the field's number and a decoder of the field's value. f returns false
+// if it doesn't know the field, in which case the field is skipped.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeTaggedFields(dec *Decoder, f func(field uint32, dec *Decoder) bool) {
+	for {
+		field := decodeUvarint(dec)
+		if field == 0 {
+			return
+		}
+		if field > math.MaxUint32 {
+			panic(makeDecodeError("invalid field number %d", field))
+		}
+		n := decodeUvarint(dec)
+		if n > uint64(len(dec.data)) {
+			panic(makeDecodeError("unable to read #bytes: %d", n))
+		}
+		// The field's decoder shares dec's allocation budget.
+		fdec := &Decoder{data: dec.Read(int(n)), limited: dec.limited, budget: dec.budget}
+		if f(uint32(field), fdec) {
+			dec.budget = fdec.budget
+		}
+	}
+}
+
+// decodeUvarint decodes a varint encoded by binary.AppendUvarint.
+func decodeUvarint(dec *Decoder) uint64 {
+	v, n := binary.Uvarint(dec.data)
+	if n <= 0 {
+		panic(makeDecodeError("unable to decode varint"))
+	}
+	dec.data = dec.data[n:]
+	return v
+}
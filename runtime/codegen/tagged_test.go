@@ -0,0 +1,152 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"bytes"
+	"testing"
+)
+
+// productV1 and productV2 are two versions of a tagged struct. productV2 adds
+// a field, numbered 3.
+type productV1 struct {
+	Name  string
+	Price int64
+}
+
+type productV2 struct {
+	Name     string
+	Price    int64
+	Quantity int32
+}
+
+func (x *productV1) encode(enc *Encoder) {
+	EncodeTaggedField(enc, 1, func(enc *Encoder) { enc.String(x.Name) })
+	EncodeTaggedField(enc, 2, func(enc *Encoder) { enc.Int64(x.Price) })
+	EncodeTaggedEnd(enc)
+}
+
+func (x *productV1) decode(dec *Decoder) {
+	*x = productV1{}
+	DecodeTaggedFields(dec, func(field uint32, dec *Decoder) bool {
+		switch field {
+		case 1:
+			x.Name = dec.String()
+		case 2:
+			x.Price = dec.Int64()
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+func (x *productV2) encode(enc *Encoder) {
+	EncodeTaggedField(enc, 1, func(enc *Encoder) { enc.String(x.Name) })
+	EncodeTaggedField(enc, 2, func(enc *Encoder) { enc.Int64(x.Price) })
+	EncodeTaggedField(enc, 3, func(enc *Encoder) { enc.Int32(x.Quantity) })
+	EncodeTaggedEnd(enc)
+}
+
+func (x *productV2) decode(dec *Decoder) {
+	*x = productV2{}
+	DecodeTaggedFields(dec, func(field uint32, dec *Decoder) bool {
+		switch field {
+		case 1:
+			x.Name = dec.String()
+		case 2:
+			x.Price = dec.Int64()
+		case 3:
+			x.Quantity = dec.Int32()
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// TestTaggedEncoding checks that field numbers and lengths are encoded as
+// varints.
+func TestTaggedEncoding(t *testing.T) {
+	enc := NewEncoder()
+	EncodeTaggedField(enc, 1, func(enc *Encoder) { enc.Uint8(42) })
+	EncodeTaggedField(enc, 300, func(enc *Encoder) { enc.ByteArray(make([]byte, 200)) })
+	EncodeTaggedEnd(enc)
+
+	want := []byte{1, 1, 42, 0xac, 0x02, 0xc8, 0x01}
+	want = append(want, make([]byte, 200)...)
+	want = append(want, 0)
+	if got := enc.Data(); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestTaggedNewToOld checks that an old decoder skips the fields it doesn't
+// know about, and leaves the rest of the encoding intact.
+func TestTaggedNewToOld(t *testing.T) {
+	enc := NewEncoder()
+	v2 := productV2{Name: "mug", Price: 5, Quantity: 3}
+	v2.encode(enc)
+	enc.String("trailer")
+
+	dec := NewDecoder(enc.Data())
+	var v1 productV1
+	v1.decode(dec)
+	if want := (productV1{Name: "mug", Price: 5}); v1 != want {
+		t.Errorf("got %+v, want %+v", v1, want)
+	}
+	if got, want := dec.String(), "trailer"; got != want {
+		t.Errorf("trailer: got %q, want %q", got, want)
+	}
+	if !dec.Empty() {
+		t.Error("decoder not empty")
+	}
+}
+
+// TestTaggedOldToNew checks that a new decoder leaves the fields missing from
+// an old encoding as zero values.
+func TestTaggedOldToNew(t *testing.T) {
+	enc := NewEncoder()
+	v1 := productV1{Name: "mug", Price: 5}
+	v1.encode(enc)
+
+	dec := NewDecoder(enc.Data())
+	v2 := productV2{Quantity: 42}
+	v2.decode(dec)
+	if want := (productV2{Name: "mug", Price: 5}); v2 != want {
+		t.Errorf("got %+v, want %+v", v2, want)
+	}
+	if !dec.Empty() {
+		t.Error("decoder not empty")
+	}
+}
+
+// TestTaggedTruncated checks that decoding a truncated tagged struct fails.
+func TestTaggedTruncated(t *testing.T) {
+	enc := NewEncoder()
+	v2 := productV2{Name: "mug", Price: 5, Quantity: 3}
+	v2.encode(enc)
+	data := enc.Data()
+
+	err := func() (err error) {
+		defer func() { err = CatchPanics(recover()) }()
+		var got productV2
+		got.decode(NewDecoder(data[:len(data)-6]))
+		return nil
+	}()
+	if err == nil {
+		t.Fatal("unexpected success decoding a truncated encoding")
+	}
+}
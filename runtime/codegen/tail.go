@@ -0,0 +1,48 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+// Tails
+//
+// The last field of a struct can be tagged with `weaver:"tail"` if it has type
+// []byte. The field is encoded as its raw bytes, without the length prefix of
+// the plain encoding, and is decoded as all the bytes left in the decoder. A
+// struct with a tail field must therefore be the last value in its encoding,
+// which the code generator guarantees by only allowing such a struct to be the
+// last argument of a component method. For example, a struct with an int32
+// field and a tail of "abc" is encoded as
+//
+//     <int32> | a | b | c
+//
+// A tail saves the four bytes of the length prefix. Note that a nil tail and
+// an empty tail have the same encoding, and both are decoded as nil.
+
+// EncodeTail encodes b into enc as the tail of a struct.
+//
+// NOTE that this function should be called only in the generated code.
+func EncodeTail(enc *Encoder, b []byte) {
+	copy(enc.Grow(len(b)), b)
+}
+
+// DecodeTail decodes a tail that was encoded using EncodeTail. It consumes
+// every byte left in dec.
+//
+// NOTE that this function should be called only in the generated code.
+func DecodeTail(dec *Decoder) []byte {
+	if dec.Empty() {
+		return nil
+	}
+	return dec.Read(len(dec.data))
+}
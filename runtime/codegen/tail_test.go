@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestTailRoundTrip encodes a header followed by a tail, and decodes them.
+// Verify that the tail is decoded as expected and that it is four bytes
+// smaller than the plain encoding.
+func TestTailRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		tail []byte
+		want []byte
+	}{
+		{"Nil", nil, nil},
+		{"Empty", []byte{}, nil},
+		{"One", []byte{42}, []byte{42}},
+		{"Large", make([]byte, 4096), make([]byte, 4096)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc := NewEncoder()
+			enc.String("header")
+			EncodeTail(enc, test.tail)
+
+			plain := NewEncoder()
+			plain.String("header")
+			plain.Bytes(test.tail)
+			if got, want := len(enc.Data()), len(plain.Data())-4; got != want {
+				t.Fatalf("size: got %d, want %d", got, want)
+			}
+
+			dec := NewDecoder(enc.Data())
+			if got, want := dec.String(), "header"; got != want {
+				t.Fatalf("header: got %q, want %q", got, want)
+			}
+			got := DecodeTail(dec)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("(-want,+got):\n%s", diff)
+			}
+			if (test.want == nil) != (got == nil) {
+				t.Fatalf("nil mismatch: want %v, got %v", test.want == nil, got == nil)
+			}
+			if !dec.Empty() {
+				t.Fatalf("unexpected bytes left to be read: %d", len(dec.data))
+			}
+		})
+	}
+}
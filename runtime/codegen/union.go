@@ -0,0 +1,41 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+// Sealed unions
+//
+// A sealed union is an interface with a single unexported marker method, like
+// `isShape()`. Only types in the same package as the interface can implement
+// it, so the code generator knows every variant of the union. A union value is
+// encoded as a uint32 tag followed by the encoding of the variant. Tag 0
+// denotes a nil union, and tag i denotes the ith variant, ordered by name.
+
+// UnknownVariantError returns an encoding error for a value v that is not a
+// known variant of the provided sealed union. The returned error should be
+// panicked and later caught by CatchPanics.
+//
+// NOTE that this function should be called only in the generated code.
+func UnknownVariantError(union string, v any) error {
+	return makeEncodeError("unable to encode %s: unknown variant %T", union, v)
+}
+
+// UnknownTagError returns a decoding error for a tag that doesn't correspond
+// to any variant of the provided sealed union. The returned error should be
+// panicked and later caught by CatchPanics.
+//
+// NOTE that this function should be called only in the generated code.
+func UnknownTagError(union string, tag uint32) error {
+	return makeDecodeError("unable to decode %s: unknown variant tag %d", union, tag)
+}
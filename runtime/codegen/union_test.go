@@ -0,0 +1,57 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnknownTagError checks that panicking with an UnknownTagError is
+// converted into an error by CatchPanics.
+func TestUnknownTagError(t *testing.T) {
+	enc := NewEncoder()
+	enc.Uint32(42)
+	dec := NewDecoder(enc.Data())
+
+	err := func() (err error) {
+		defer func() { err = CatchPanics(recover()) }()
+		if tag := dec.Uint32(); tag != 0 {
+			panic(UnknownTagError("Shape", tag))
+		}
+		return nil
+	}()
+	if err == nil {
+		t.Fatal("unexpected success decoding unknown tag")
+	}
+	if want := "unknown variant tag 42"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %q does not contain %q", err, want)
+	}
+}
+
+// TestUnknownVariantError checks that panicking with an UnknownVariantError is
+// converted into an error by CatchPanics.
+func TestUnknownVariantError(t *testing.T) {
+	err := func() (err error) {
+		defer func() { err = CatchPanics(recover()) }()
+		panic(UnknownVariantError("Shape", 3.14))
+	}()
+	if err == nil {
+		t.Fatal("unexpected success encoding unknown variant")
+	}
+	if want := "unknown variant float64"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %q does not contain %q", err, want)
+	}
+}
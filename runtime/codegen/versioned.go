@@ -0,0 +1,88 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ConflictError is returned by a component method call when the version of
+// an entity passed to the method is stale. See weaver.ConflictError.
+var ConflictError = errors.New("Service Weaver version conflict")
+
+// Versioned is implemented by AutoMarshal structs that have a field tagged
+// with `weaver:"version"`. The implementation is generated by "weaver
+// generate".
+type Versioned interface {
+	// WeaverVersion returns the value of the version field.
+	WeaverVersion() uint64
+}
+
+// VersionChecker is an optional interface implemented by component
+// implementations that receive versioned entities. A component method that
+// receives versioned entities is invoked by CompareAndSet, so that checking
+// the versions, running the method and advancing the versions is one atomic
+// step.
+type VersionChecker interface {
+	// CompareAndSet atomically compares the stored versions of the provided
+	// entities with versions, where versions[i] is the version of
+	// entities[i]. If any of them differs, CompareAndSet returns false without
+	// calling apply, and the call fails with ConflictError. Otherwise, it calls
+	// apply, which runs the component method, and, if apply succeeds,
+	// advances every stored version and returns true. If apply fails,
+	// CompareAndSet returns its error without advancing any version.
+	//
+	// No other call to CompareAndSet with any of the same entities should
+	// run between the comparison and the advance of the versions. Note that
+	// apply is called while the entities are held, so the component method
+	// should not make calls that wait on them.
+	CompareAndSet(ctx context.Context, entities []any, versions []uint64, apply func() error) (bool, error)
+}
+
+// CallVersioned calls the provided function, which runs a component method
+// that receives the provided entities, using impl's CompareAndSet method.
+// Nil entities are ignored. If any version is stale, call is not invoked and
+// CallVersioned returns ConflictError. If impl doesn't implement
+// VersionChecker, call is invoked directly.
+//
+// NOTE that this function should be called only in the generated code.
+func CallVersioned(ctx context.Context, impl any, call func() error, entities ...Versioned) error {
+	checker, ok := impl.(VersionChecker)
+	if !ok {
+		return call()
+	}
+	checked := make([]any, 0, len(entities))
+	versions := make([]uint64, 0, len(entities))
+	for _, entity := range entities {
+		if v := reflect.ValueOf(entity); v.Kind() == reflect.Pointer && v.IsNil() {
+			continue
+		}
+		checked = append(checked, entity)
+		versions = append(versions, entity.WeaverVersion())
+	}
+	if len(checked) == 0 {
+		return call()
+	}
+	ok, err := checker.CompareAndSet(ctx, checked, versions, call)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ConflictError
+	}
+	return nil
+}
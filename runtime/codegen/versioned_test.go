@@ -0,0 +1,165 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// entity is a versioned entity used in tests.
+type entity struct {
+	name    string
+	version uint64
+}
+
+func (e *entity) WeaverVersion() uint64 { return e.version }
+
+// versionStore is a VersionChecker that stores the versions of entities by
+// name, along with a counter that methods increment.
+type versionStore struct {
+	mu       sync.Mutex
+	versions map[string]uint64
+	applied  int // number of times a method was applied
+}
+
+var _ VersionChecker = &versionStore{}
+
+func (s *versionStore) CompareAndSet(_ context.Context, entities []any, versions []uint64, apply func() error) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range entities {
+		if s.versions[e.(*entity).name] != versions[i] {
+			return false, nil
+		}
+	}
+	if err := apply(); err != nil {
+		return false, err
+	}
+	for _, e := range entities {
+		s.versions[e.(*entity).name]++
+	}
+	return true, nil
+}
+
+// call mimics a generated stub that invokes a method receiving the provided
+// entities, which increments s.applied unless it returns methodErr.
+func (s *versionStore) call(methodErr error, entities ...Versioned) error {
+	return CallVersioned(context.Background(), s, func() error {
+		if methodErr != nil {
+			return methodErr
+		}
+		s.applied++ // guarded by s.mu, held by CompareAndSet
+		return nil
+	}, entities...)
+}
+
+func TestCallVersioned(t *testing.T) {
+	s := &versionStore{versions: map[string]uint64{"a": 1, "b": 1}}
+	var nilEntity *entity
+	if err := s.call(nil, &entity{"a", 1}, nilEntity, &entity{"b", 1}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]uint64{"a": 2, "b": 2}, s.versions); diff != "" {
+		t.Fatalf("versions (-want +got):\n%s", diff)
+	}
+	if s.applied != 1 {
+		t.Fatalf("applied: got %d, want 1", s.applied)
+	}
+}
+
+func TestCallVersionedMethodFails(t *testing.T) {
+	// The versions are not advanced if the method fails.
+	s := &versionStore{versions: map[string]uint64{"a": 1, "b": 1}}
+	methodErr := errors.New("method failed")
+	if err := s.call(methodErr, &entity{"a", 1}, &entity{"b", 1}); !errors.Is(err, methodErr) {
+		t.Fatalf("got %v, want %v", err, methodErr)
+	}
+	if diff := cmp.Diff(map[string]uint64{"a": 1, "b": 1}, s.versions); diff != "" {
+		t.Fatalf("versions (-want +got):\n%s", diff)
+	}
+}
+
+func TestCallVersionedConflict(t *testing.T) {
+	// A stale second entity fails the call without running the method or
+	// advancing the version of the first entity.
+	s := &versionStore{versions: map[string]uint64{"a": 1, "b": 2}}
+	if err := s.call(nil, &entity{"a", 1}, &entity{"b", 1}); !errors.Is(err, ConflictError) {
+		t.Fatalf("got %v, want %v", err, ConflictError)
+	}
+	if diff := cmp.Diff(map[string]uint64{"a": 1, "b": 2}, s.versions); diff != "" {
+		t.Fatalf("versions (-want +got):\n%s", diff)
+	}
+	if s.applied != 0 {
+		t.Fatalf("applied: got %d, want 0", s.applied)
+	}
+}
+
+func TestCallVersionedConcurrentConflict(t *testing.T) {
+	// Of many concurrent calls with the same version, only one runs the
+	// method. The others fail with ConflictError without running it.
+	s := &versionStore{versions: map[string]uint64{"a": 1}}
+	const n = 100
+	errs := make([]error, n)
+	var wait sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			errs[i] = s.call(nil, &entity{"a", 1})
+		}()
+	}
+	wait.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ConflictError):
+			t.Fatalf("got %v, want nil or %v", err, ConflictError)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("succeeded: got %d, want 1", succeeded)
+	}
+	if s.applied != 1 {
+		t.Fatalf("applied: got %d, want 1", s.applied)
+	}
+	if got, want := s.versions["a"], uint64(2); got != want {
+		t.Fatalf("version: got %d, want %d", got, want)
+	}
+}
+
+func TestCallVersionedUnversioned(t *testing.T) {
+	// Methods of implementations that aren't VersionCheckers are called
+	// directly.
+	called := false
+	err := CallVersioned(context.Background(), struct{}{}, func() error {
+		called = true
+		return nil
+	}, &entity{"a", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("method not called")
+	}
+}
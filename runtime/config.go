@@ -15,8 +15,12 @@
 package runtime
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -62,6 +66,30 @@ func ParseConfig(file string, input string, sectionValidator func(string, string
 	return config, nil
 }
 
+// WatchConfigFile calls f with the contents of the named config file every
+// time they change, until ctx is done. contents are the contents of the file
+// when the watch starts, i.e., the contents that f need not be called with.
+// The file is read every interval. A file that can't be read is skipped, so
+// that a file that is briefly missing while being rewritten doesn't trigger a
+// spurious change.
+func WatchConfigFile(ctx context.Context, filename, contents string, interval time.Duration, f func(contents string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		b, err := os.ReadFile(filename)
+		if err != nil || string(b) == contents {
+			continue
+		}
+		contents = string(b)
+		f(contents)
+	}
+}
+
 // ParseConfigSection parses the config section for key into dst.
 // If shortKey is not empty, either key or shortKey is accepted.
 // If the named section is not found, returns nil without changing dst.
@@ -80,14 +108,200 @@ func ParseConfigSection(key, shortKey string, sections map[string]string, dst an
 		return nil
 	}
 
-	// Parse and validate the section.
+	return parseSection(key, section, dst)
+}
+
+// LogLevelKey is the key, inside a component's config section, that holds
+// the component's log level. For example:
+//
+//	["github.com/example/app/Cache"]
+//	log_level = "debug"
+const LogLevelKey = "log_level"
+
+// ParseComponentConfigSection parses the config section of the named
+// component into dst. It is like ParseConfigSection, except that the
+// LogLevelKey key is permitted even if dst does not have a corresponding
+// field, and that dst is validated even if the component has no config
+// section, since the zero config of a component may not be valid.
+func ParseComponentConfigSection(name string, sections map[string]string, dst any) error {
+	section, ok := sections[name]
+	if !ok {
+		return validateSection(name, dst)
+	}
+	if _, _, err := ParseLogLevel(name, sections); err != nil {
+		return err
+	}
+	section, err := expandSection(section)
+	if err != nil {
+		return fmt.Errorf("section %q: %w", name, err)
+	}
+	return parseSection(name, section, dst, LogLevelKey)
+}
+
+// expandSection returns section with the environment variable references in
+// its string values expanded. See expandEnv.
+func expandSection(section string) (string, error) {
+	if !strings.Contains(section, "$") {
+		return section, nil
+	}
+	var values map[string]any
+	if _, err := toml.Decode(section, &values); err != nil {
+		return "", err
+	}
+	if err := expandValues(values); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(values); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// expandValues expands, in place, the environment variable references in the
+// string values nested inside v, a value decoded by toml.Decode.
+func expandValues(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, x := range v {
+			if s, ok := x.(string); ok {
+				expanded, err := expandEnv(s)
+				if err != nil {
+					return fmt.Errorf("key %q: %w", key, err)
+				}
+				v[key] = expanded
+			} else if err := expandValues(x); err != nil {
+				return err
+			}
+		}
+	case []map[string]any:
+		for _, x := range v {
+			if err := expandValues(x); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, x := range v {
+			if s, ok := x.(string); ok {
+				expanded, err := expandEnv(s)
+				if err != nil {
+					return err
+				}
+				v[i] = expanded
+			} else if err := expandValues(x); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandEnv replaces every ${VAR} in s with the value of the environment
+// variable VAR, and every ${VAR:-default} with the value of VAR or, if VAR is
+// unset or empty, with default. Like in a shell, an unset variable expands to
+// the empty string. $$ is replaced with a single $, and any other $ is left
+// unchanged.
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			s = s[i+2:]
+		case '{':
+			ref, rest, ok := strings.Cut(s[i+2:], "}")
+			if !ok {
+				return "", fmt.Errorf("unterminated variable reference %q", s[i:])
+			}
+			name, def, hasDefault := strings.Cut(ref, ":-")
+			if !isEnvName(name) {
+				return "", fmt.Errorf("invalid variable reference ${%s}", ref)
+			}
+			value := os.Getenv(name)
+			if value == "" && hasDefault {
+				value = def
+			}
+			b.WriteString(value)
+			s = rest
+		default:
+			b.WriteByte('$')
+			s = s[i+1:]
+		}
+	}
+}
+
+// isEnvName returns whether name is a valid environment variable name: a
+// letter or underscore followed by letters, digits, and underscores.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLogLevel returns the log level stored under LogLevelKey in the config
+// section of the named component. The level is parsed by
+// slog.Level.UnmarshalText, so "debug", "info", "warn", "error", and offsets
+// like "info+2" are accepted. If the level is not set, ParseLogLevel returns
+// false.
+func ParseLogLevel(name string, sections map[string]string) (slog.Level, bool, error) {
+	section, ok := sections[name]
+	if !ok {
+		return 0, false, nil
+	}
+	var config struct {
+		LogLevel *string `toml:"log_level"`
+	}
+	if _, err := toml.Decode(section, &config); err != nil {
+		return 0, false, err
+	}
+	if config.LogLevel == nil {
+		return 0, false, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*config.LogLevel)); err != nil {
+		return 0, false, fmt.Errorf("section %q: invalid %s %q", name, LogLevelKey, *config.LogLevel)
+	}
+	return level, true, nil
+}
+
+// parseSection parses and validates section, stored under key, into dst. Keys
+// in ignored are permitted even if dst does not decode them.
+func parseSection(key, section string, dst any, ignored ...string) error {
 	md, err := toml.Decode(section, dst)
 	if err != nil {
 		return err
 	}
-	if unknown := md.Undecoded(); len(unknown) != 0 {
+	var unknown []toml.Key
+	for _, k := range md.Undecoded() {
+		if len(k) == 1 && slices.Contains(ignored, k[0]) {
+			continue
+		}
+		unknown = append(unknown, k)
+	}
+	if len(unknown) != 0 {
 		return fmt.Errorf("section %q has unknown keys %v", key, unknown)
 	}
+	return validateSection(key, dst)
+}
+
+// validateSection calls the Validate method of dst, parsed from the section
+// stored under key, if it has one.
+func validateSection(key string, dst any) error {
 	if x, ok := dst.(interface{ Validate() error }); ok {
 		if err := x.Validate(); err != nil {
 			return fmt.Errorf("section %q: %w", key, err)
@@ -15,10 +15,14 @@
 package runtime_test
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ServiceWeaver/weaver/runtime"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
@@ -102,6 +106,160 @@ func TestParseConfigSection(t *testing.T) {
 	}
 }
 
+func TestParseLogLevel(t *testing.T) {
+	type testCase struct {
+		name   string
+		config string
+		want   slog.Level
+		wantOK bool
+	}
+	for _, c := range []testCase{
+		{"missing", ``, 0, false},
+		{"unset", "[section]\nFoo = \"foo\"\n", 0, false},
+		{"debug", "[section]\nlog_level = \"debug\"\n", slog.LevelDebug, true},
+		{"upper", "[section]\nlog_level = \"WARN\"\n", slog.LevelWarn, true},
+		{"offset", "[section]\nlog_level = \"info+2\"\n", slog.LevelInfo + 2, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			config, err := runtime.ParseConfig("", c.config, codegen.ComponentConfigValidator)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok, err := runtime.ParseLogLevel("section", config.Sections)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want || ok != c.wantOK {
+				t.Fatalf("ParseLogLevel: got (%v, %t), want (%v, %t)", got, ok, c.want, c.wantOK)
+			}
+
+			// The log level must not be reported as an unknown key.
+			var section struct{ Foo string }
+			if err := runtime.ParseComponentConfigSection("section", config.Sections, &section); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestParseLogLevelErrors(t *testing.T) {
+	for _, section := range []string{
+		`log_level = "verbose"`,
+		`log_level = 3`,
+	} {
+		t.Run(section, func(t *testing.T) {
+			sections := map[string]string{"section": section}
+			if _, _, err := runtime.ParseLogLevel("section", sections); err == nil {
+				t.Fatal("ParseLogLevel: unexpected success")
+			}
+			var dst struct{}
+			if err := runtime.ParseComponentConfigSection("section", sections, &dst); err == nil {
+				t.Fatal("ParseComponentConfigSection: unexpected success")
+			}
+		})
+	}
+}
+
+func TestParseComponentConfigSectionEnv(t *testing.T) {
+	t.Setenv("WEAVER_TEST_HOST", "example.com")
+	t.Setenv("WEAVER_TEST_EMPTY", "")
+	type section struct {
+		Addr  string
+		Port  int
+		Names []string
+		Inner struct{ Password string }
+	}
+	type testCase struct {
+		name    string
+		section string
+		want    section
+	}
+	for _, c := range []testCase{
+		{"none", `Addr = "localhost"`, section{Addr: "localhost"}},
+		{"set", `Addr = "${WEAVER_TEST_HOST}:80"`, section{Addr: "example.com:80"}},
+		{"unset", `Addr = "[${WEAVER_TEST_UNSET}]"`, section{Addr: "[]"}},
+		{"default", `Addr = "${WEAVER_TEST_UNSET:-localhost}"`, section{Addr: "localhost"}},
+		{"empty default", `Addr = "${WEAVER_TEST_EMPTY:-localhost}"`, section{Addr: "localhost"}},
+		{"ignored default", `Addr = "${WEAVER_TEST_HOST:-localhost}"`, section{Addr: "example.com"}},
+		{"escaped", `Addr = "$${WEAVER_TEST_HOST}"`, section{Addr: "${WEAVER_TEST_HOST}"}},
+		{"lone dollar", `Addr = "$5 $"`, section{Addr: "$5 $"}},
+		{"non-string", "Port = 80\nAddr = \"${WEAVER_TEST_HOST}\"", section{Addr: "example.com", Port: 80}},
+		{"array", `Names = ["${WEAVER_TEST_HOST}", "b"]`, section{Names: []string{"example.com", "b"}}},
+		{"table", "[Inner]\nPassword = \"${WEAVER_TEST_HOST}\"", section{Inner: struct{ Password string }{"example.com"}}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var got section
+			sections := map[string]string{"section": c.section}
+			if err := runtime.ParseComponentConfigSection("section", sections, &got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Fatalf("ParseComponentConfigSection: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseComponentConfigSectionEnvErrors(t *testing.T) {
+	for _, section := range []string{
+		`Addr = "${WEAVER_TEST_HOST"`,
+		`Addr = "${}"`,
+		`Addr = "${1FOO}"`,
+		`Addr = "${FOO BAR}"`,
+	} {
+		t.Run(section, func(t *testing.T) {
+			var dst struct{ Addr string }
+			sections := map[string]string{"section": section}
+			if err := runtime.ParseComponentConfigSection("section", sections, &dst); err == nil {
+				t.Fatal("ParseComponentConfigSection: unexpected success")
+			}
+		})
+	}
+}
+
+// historyConfig is a component config with a Validate method.
+type historyConfig struct {
+	HistoryLimit int
+	CacheSize    int
+}
+
+func (c *historyConfig) Validate() error {
+	if c.HistoryLimit <= 0 {
+		return fmt.Errorf("HistoryLimit must be positive, got %d", c.HistoryLimit)
+	}
+	if c.CacheSize <= 0 {
+		return fmt.Errorf("CacheSize must be positive, got %d", c.CacheSize)
+	}
+	return nil
+}
+
+func TestParseComponentConfigSectionValidate(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		section string // if empty, the component has no config section
+		want    string // expected error, if any
+	}{
+		{"Valid", "HistoryLimit = 100\nCacheSize = 1000", ""},
+		{"Invalid", "HistoryLimit = 100\nCacheSize = -1", "CacheSize must be positive"},
+		{"Missing", "", "HistoryLimit must be positive"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sections := map[string]string{}
+			if test.section != "" {
+				sections["section"] = test.section
+			}
+			var dst historyConfig
+			err := runtime.ParseComponentConfigSection("section", sections, &dst)
+			switch {
+			case test.want == "" && err != nil:
+				t.Fatal(err)
+			case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+				t.Fatalf("ParseComponentConfigSection: got %v, want error containing %q", err, test.want)
+			}
+		})
+	}
+}
+
 func TestConfigErrors(t *testing.T) {
 	type testCase struct {
 		name          string
@@ -164,3 +322,57 @@ rollout = "hello"
 		})
 	}
 }
+
+func TestWatchConfigFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "weaver.toml")
+	write := func(contents string) {
+		// Write the file atomically, so that the watch never sees it empty.
+		t.Helper()
+		tmp := filepath.Join(dir, "weaver.toml.tmp")
+		if err := os.WriteFile(tmp, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a")
+	changes := make(chan string, 10)
+	go runtime.WatchConfigFile(ctx, filename, "a", time.Millisecond, func(contents string) {
+		changes <- contents
+	})
+	next := func() string {
+		t.Helper()
+		select {
+		case contents := <-changes:
+			return contents
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for a config change")
+			return ""
+		}
+	}
+
+	// A missing file is not a change.
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	for _, contents := range []string{"a", "b", "c"} {
+		write(contents)
+		if contents == "a" {
+			// The contents haven't changed.
+			continue
+		}
+		if got := next(); got != contents {
+			t.Errorf("got %q, want %q", got, contents)
+		}
+	}
+	select {
+	case contents := <-changes:
+		t.Errorf("unexpected change %q", contents)
+	default:
+	}
+}
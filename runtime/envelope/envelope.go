@@ -369,6 +369,19 @@ func (e *Envelope) UpdateRoutingInfo(routing *protos.RoutingInfo) error {
 	return err
 }
 
+// UpdateConfig updates the weavelet with the latest config sections of the
+// application. The weavelet updates the log levels of its components and
+// reconfigures the components that accept config updates (see
+// weaver.WithConfig). Other changes take effect only when the weavelet is
+// restarted.
+func (e *Envelope) UpdateConfig(sections map[string]string) error {
+	req := &protos.InitWeaveletRequest{
+		Sections: sections,
+	}
+	_, err := e.controller.InitWeavelet(context.TODO(), req)
+	return err
+}
+
 func (e *Envelope) logLines(component string, src io.Reader, h EnvelopeHandler) error {
 	// Fill partial log entry.
 	entry := &protos.LogEntry{
@@ -422,7 +435,7 @@ func getWeaveletControlStub(ctx context.Context, socket string, options Options)
 		return nil, err
 	}
 	// We skip waitUntilReady() and rely on automatic retries of methods
-	stub := call.NewStub(control.WeaveletPath, controllerReg, conn, options.Tracer, 0)
+	stub := call.NewStub(control.WeaveletPath, controllerReg, conn, options.Tracer, call.StubOptions{})
 	obj := controllerReg.ClientStubFn(stub, "envelope")
 	return obj.(control.WeaveletControl), nil
 }
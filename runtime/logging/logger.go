@@ -59,6 +59,11 @@ type Options struct {
 type LogHandler struct {
 	Opts  Options                      // configures the log entries
 	Write func(entry *protos.LogEntry) // called on every log entry
+
+	// If non-nil, log entries below Level are dropped. Level is consulted on
+	// every log call, so a *slog.LevelVar can be used to change the level of
+	// a running logger. If nil, all levels are enabled.
+	Level slog.Leveler
 }
 
 var _ slog.Handler = &LogHandler{}
@@ -70,9 +75,12 @@ func (h *LogHandler) Handle(ctx context.Context, rec slog.Record) error {
 }
 
 // Enabled implements the slog.Handler interface.
-func (h *LogHandler) Enabled(context.Context, slog.Level) bool {
-	// Support all logging levels.
-	return true
+func (h *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.Level == nil {
+		// Support all logging levels.
+		return true
+	}
+	return level >= h.Level.Level()
 }
 
 // WithAttrs implements the slog.Handler interface.
@@ -85,6 +93,7 @@ func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	rh := &LogHandler{
 		Opts:  h.Opts,
 		Write: h.Write,
+		Level: h.Level,
 	}
 	rh.Opts.Attrs = appendAttrs(rh.Opts.Attrs, attrs)
 	return rh
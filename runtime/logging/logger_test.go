@@ -73,6 +73,29 @@ func TestWithAttributes(t *testing.T) {
 	}
 }
 
+func TestLevel(t *testing.T) {
+	var got []string
+	var level slog.LevelVar
+	logger := slog.New(&LogHandler{
+		Write: func(e *protos.LogEntry) { got = append(got, e.Msg) },
+		Level: &level,
+	}).With("foo", "bar")
+
+	level.Set(slog.LevelInfo)
+	logger.Debug("debug 1")
+	logger.Info("info 1")
+	level.Set(slog.LevelDebug)
+	logger.Debug("debug 2")
+	level.Set(slog.LevelError)
+	logger.Warn("warn 1")
+	logger.Error("error 1")
+
+	want := []string{"info 1", "debug 2", "error 1"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+}
+
 func TestConcurrentAttributes(t *testing.T) {
 	// Test plan: start a number of goroutines that emit attributes with sequential
 	// values. Confirm that attributes are saved in the same sequential order
@@ -320,16 +320,7 @@ func RegisterMap[L comparable](typ protos.MetricType, name string, help string,
 	if typ == protos.MetricType_INVALID {
 		panic(fmt.Errorf("metric %q: invalid metric type %v", name, typ))
 	}
-	for _, x := range bounds {
-		if math.IsNaN(x) {
-			panic(fmt.Errorf("metric %q: NaN histogram bound", name))
-		}
-	}
-	for i := 0; i < len(bounds)-1; i++ {
-		if bounds[i] >= bounds[i+1] {
-			panic(fmt.Errorf("metric %q: non-ascending histogram bounds %v", name, bounds))
-		}
-	}
+	checkBounds(name, bounds)
 
 	metricNamesMu.Lock()
 	defer metricNamesMu.Unlock()
@@ -344,11 +335,36 @@ func RegisterMap[L comparable](typ protos.MetricType, name string, help string,
 	}
 }
 
+// checkBounds panics if the provided histogram bounds of the named metric are
+// invalid.
+func checkBounds(name string, bounds []float64) {
+	for _, x := range bounds {
+		if math.IsNaN(x) {
+			panic(fmt.Errorf("metric %q: NaN histogram bound", name))
+		}
+	}
+	for i := 0; i < len(bounds)-1; i++ {
+		if bounds[i] >= bounds[i+1] {
+			panic(fmt.Errorf("metric %q: non-ascending histogram bounds %v", name, bounds))
+		}
+	}
+}
+
 // Name returns the name of the metricMap.
 func (mm *MetricMap[L]) Name() string {
 	return mm.config.Name
 }
 
+// SetBounds sets the histogram bounds of the metrics constructed by future
+// calls to Get. Metrics that have already been constructed keep their bounds.
+// Panics if the bounds are invalid.
+func (mm *MetricMap[L]) SetBounds(bounds []float64) {
+	checkBounds(mm.config.Name, bounds)
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.config.Bounds = slices.Clone(bounds)
+}
+
 // Get returns the metric with the provided labels, constructing it if it
 // doesn't already exist. Multiple calls to Get with the same labels will
 // return the same metric.
@@ -225,6 +225,37 @@ func TestEmptyName(t *testing.T) {
 	Register(counterType, "", "", nil)
 }
 
+func TestSetBounds(t *testing.T) {
+	clear()
+	histogram := RegisterMap[struct{ Name string }](histogramType, "TestSetBounds/histogram", "", []float64{1, 10})
+	before := histogram.Get(struct{ Name string }{"before"})
+	histogram.SetBounds([]float64{1, 2, 5, 10})
+	after := histogram.Get(struct{ Name string }{"after"})
+
+	// Metrics constructed before the call to SetBounds keep their bounds.
+	if diff := cmp.Diff([]float64{1, 10}, before.Snapshot().Bounds); diff != "" {
+		t.Fatalf("bad bounds before SetBounds (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]float64{1, 2, 5, 10}, after.Snapshot().Bounds); diff != "" {
+		t.Fatalf("bad bounds after SetBounds (-want +got):\n%s", diff)
+	}
+	after.Put(3)
+	if diff := cmp.Diff([]uint64{0, 0, 1, 0, 0}, after.Snapshot().Counts); diff != "" {
+		t.Fatalf("bad puts (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetInvalidBounds(t *testing.T) {
+	clear()
+	histogram := RegisterMap[struct{}](histogramType, "TestSetInvalidBounds/histogram", "", nil)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("unexpected success")
+		}
+	}()
+	histogram.SetBounds([]float64{1, 3, 2})
+}
+
 func TestInvalidBounds(t *testing.T) {
 	clear()
 	defer func() {
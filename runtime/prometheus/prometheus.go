@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
@@ -35,6 +36,19 @@ import (
 // [1] https://github.com/prometheus/docs/blob/main/content/docs/instrumenting/exposition_formats.md#text-format-details
 var escaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
 
+// Handler returns an HTTP handler that serves a snapshot of the metrics
+// exported by the current process in a text format that can be scraped by
+// Prometheus. These include the method metrics of every component hosted by
+// the process, labeled by caller, component, and method.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b bytes.Buffer
+		TranslateMetricsToPrometheusTextFormat(&b, metrics.Snapshot(), r.Host, r.URL.Path)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(b.Bytes())
+	})
+}
+
 // TranslateMetricsToPrometheusTextFormat translates Service Weaver
 // metrics (keyed by weavelet id) to a text format that can be
 // scraped by Prometheus [1].
@@ -383,6 +383,10 @@ type WeaveletArgs struct {
 	// (e.g., "localhost:12345", ":0"). If the address is empty, it defaults to
 	// ":0", like net.Listen.
 	//
+	// The address can be prefixed with the network name of the transport that
+	// carries the calls to the weavelet (e.g., "quic://:9000"). Addresses
+	// without a prefix use "tcp". See the runtime/transport package.
+	//
 	// Note that for some deployers, the internal network listener can listen on
 	// an arbitrary port (don't set the port number). However, for deployers where
 	// listeners are prestarted (e.g., Kubernetes deployers), the port number
@@ -0,0 +1,231 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quic registers a transport, named "quic", that carries the calls
+// between weavelets over QUIC (RFC 9000). Import the package for its side
+// effect in the application binary:
+//
+//	import _ "github.com/ServiceWeaver/weaver/runtime/transport/quic"
+//
+// and have the deployer pass internal addresses like "quic://:9000" to the
+// weavelets (see the runtime/transport package).
+//
+// Every connection between two weavelets is a single bidirectional stream of
+// its own QUIC connection. QUIC connections are always encrypted, but the
+// transport, like the built-in "tcp" transport, doesn't authenticate the
+// weavelets: the listener presents a self-signed certificate that isn't
+// verified when dialing. Use mTLS to authenticate the weavelets.
+//
+// The package lives in its own module, so that the core module doesn't
+// depend on a QUIC implementation.
+package quic
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/transport"
+	quicgo "github.com/quic-go/quic-go"
+)
+
+// alpn is the application protocol negotiated by the transport.
+const alpn = "serviceweaver"
+
+// config is the configuration of the QUIC connections. The keep-alives stop
+// idle connections between weavelets from timing out.
+var config = &quicgo.Config{KeepAlivePeriod: 10 * time.Second}
+
+func init() {
+	transport.Register("quic", quicTransport{})
+}
+
+// quicTransport is a transport.Transport that uses QUIC.
+type quicTransport struct{}
+
+// Listen implements the transport.Transport interface.
+func (quicTransport) Listen(address string) (net.Listener, error) {
+	cert, err := certificate()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{alpn},
+	}
+	lis, err := quicgo.ListenAddr(address, tlsConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &listener{
+		lis:    lis,
+		conns:  make(chan net.Conn),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go l.acceptConnections()
+	return l, nil
+}
+
+// Dial implements the transport.Transport interface.
+func (quicTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true, // see the package comment
+		NextProtos:         []string{alpn},
+	}
+	qconn, err := quicgo.DialAddr(ctx, address, tlsConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := qconn.OpenStreamSync(ctx)
+	if err != nil {
+		qconn.CloseWithError(0, "")
+		return nil, err
+	}
+	return &conn{Stream: stream, qconn: qconn}, nil
+}
+
+// listener is a net.Listener that returns the first stream opened on every
+// QUIC connection accepted by a QUIC listener.
+type listener struct {
+	lis       *quicgo.Listener
+	conns     chan net.Conn // accepted connections
+	ctx       context.Context
+	cancel    context.CancelFunc // cancels ctx when the listener is closed
+	closeOnce sync.Once
+}
+
+// acceptConnections accepts QUIC connections until the listener is closed.
+// The streams are accepted in the background, so that a peer that is slow to
+// open its stream doesn't hold up the others.
+func (l *listener) acceptConnections() {
+	for {
+		qconn, err := l.lis.Accept(l.ctx)
+		if err != nil {
+			return
+		}
+		go l.acceptStream(qconn)
+	}
+}
+
+// acceptStream accepts the stream opened on the provided QUIC connection.
+func (l *listener) acceptStream(qconn quicgo.Connection) {
+	stream, err := qconn.AcceptStream(l.ctx)
+	if err != nil {
+		qconn.CloseWithError(0, "")
+		return
+	}
+	select {
+	case l.conns <- &conn{Stream: stream, qconn: qconn}:
+	case <-l.ctx.Done():
+		qconn.CloseWithError(0, "")
+	}
+}
+
+// Accept implements the net.Listener interface.
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.ctx.Done():
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements the net.Listener interface. Connections that were already
+// accepted stay open.
+func (l *listener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		l.cancel()
+		err = l.lis.Close()
+	})
+	return err
+}
+
+// Addr implements the net.Listener interface.
+func (l *listener) Addr() net.Addr {
+	return l.lis.Addr()
+}
+
+// conn is a net.Conn that reads and writes a QUIC stream.
+type conn struct {
+	quicgo.Stream
+	qconn quicgo.Connection // the connection that carries the stream
+}
+
+var _ net.Conn = &conn{}
+
+// Close implements the net.Conn interface. It closes the QUIC connection,
+// since the connection carries no other streams.
+func (c *conn) Close() error {
+	c.Stream.Close()
+	return c.qconn.CloseWithError(0, "")
+}
+
+// LocalAddr implements the net.Conn interface.
+func (c *conn) LocalAddr() net.Addr {
+	return c.qconn.LocalAddr()
+}
+
+// RemoteAddr implements the net.Conn interface.
+func (c *conn) RemoteAddr() net.Addr {
+	return c.qconn.RemoteAddr()
+}
+
+var (
+	certOnce sync.Once
+	cert     tls.Certificate
+	certErr  error
+)
+
+// certificate returns the self-signed certificate presented by the
+// listeners of the process. It is generated on first use.
+func certificate() (tls.Certificate, error) {
+	certOnce.Do(func() {
+		cert, certErr = selfSignedCertificate()
+	})
+	return cert, certErr
+}
+
+// selfSignedCertificate returns a new self-signed certificate.
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("quic: generate key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Service Weaver"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(100 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("quic: create certificate: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quic_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/internal/net/call"
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/ServiceWeaver/weaver/runtime/transport"
+	_ "github.com/ServiceWeaver/weaver/runtime/transport/quic"
+)
+
+var echoKey = call.MakeMethodKey("", "echo")
+
+// hmapListener is a call.Listener that serves the provided handlers.
+type hmapListener struct {
+	net.Listener
+	hmap *call.HandlerMap
+}
+
+func (l hmapListener) Accept() (net.Conn, *call.HandlerMap, error) {
+	conn, err := l.Listener.Accept()
+	return conn, l.hmap, err
+}
+
+// listen returns a listener on localhost for the provided transport.
+func listen(t testing.TB, network string) net.Listener {
+	t.Helper()
+	tr, ok := transport.Lookup(network)
+	if !ok {
+		t.Fatalf("transport %q not registered", network)
+	}
+	lis, err := tr.Listen("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	return lis
+}
+
+func TestEcho(t *testing.T) {
+	lis := listen(t, "quic")
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	quic, _ := transport.Lookup("quic")
+	for i := 0; i < 3; i++ {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			conn, err := quic.Dial(context.Background(), lis.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			want := bytes.Repeat([]byte{byte('a' + i)}, 1<<20)
+			go conn.Write(want)
+			got := make([]byte, len(want))
+			if _, err := io.ReadFull(conn, got); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatal("echoed bytes differ")
+			}
+		})
+	}
+}
+
+func TestClosedListener(t *testing.T) {
+	lis := listen(t, "quic")
+	lis.Close()
+	if _, err := lis.Accept(); err != net.ErrClosed {
+		t.Fatalf("Accept: got %v, want %v", err, net.ErrClosed)
+	}
+}
+
+func BenchmarkCall(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hmap := call.NewHandlerMap()
+	hmap.Set("", "echo", func(_ context.Context, arg []byte) ([]byte, error) {
+		return arg, nil
+	})
+	logger := logging.NewTestSlogger(b, testing.Verbose())
+
+	// Compare the default transport with QUIC.
+	for _, network := range []string{"tcp", "quic"} {
+		lis := listen(b, network)
+		go call.Serve(ctx, hmapListener{lis, hmap}, call.ServerOptions{Logger: logger})
+		endpoint := call.NetEndpoint{Net: network, Addr: lis.Addr().String()}
+		client, err := call.Connect(ctx, call.NewConstantResolver(endpoint), call.ClientOptions{Logger: logger})
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer client.Close()
+
+		for _, msgSize := range []int{1, 1 << 16, 1 << 20} {
+			b.Run(fmt.Sprintf("%s/Msg-%d", network, msgSize), func(b *testing.B) {
+				msg := bytes.Repeat([]byte{'x'}, msgSize)
+				b.SetBytes(int64(msgSize))
+				for i := 0; i < b.N; i++ {
+					result, err := client.Call(ctx, echoKey, msg, call.CallOptions{})
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(result) != len(msg) {
+						b.Fatalf("wrong length %d; expecting %d", len(result), len(msg))
+					}
+				}
+			})
+		}
+	}
+}
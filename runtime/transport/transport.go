@@ -0,0 +1,115 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transport contains the registry of the network transports that
+// carry the remote method calls between the weavelets of an application.
+//
+// Every transport has a network name, like "tcp". A weavelet listens for calls
+// with the transport named in its internal address (see
+// protos.WeaveletArgs.InternalAddress), and other weavelets dial it with the
+// same transport. Addresses without a network name, like "localhost:0", use
+// "tcp". For example, a deployer that passes the internal address
+// "quic://:9000" makes the weavelet listen with the transport registered as
+// "quic":
+//
+//	func init() {
+//	    transport.Register("quic", quicTransport{})
+//	}
+//
+// Transports are registered in the application binary, typically by the init
+// function of a package imported by it, since that is where calls are made
+// and served. The "tcp" and "unix" transports are built in. The "quic"
+// transport is registered by the runtime/transport/quic package, which is a
+// separate module.
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// A Transport carries the connections between weavelets. The connections are
+// used as reliable, ordered byte streams.
+type Transport interface {
+	// Listen returns a listener on the provided address. The address of the
+	// returned listener is the address that other weavelets dial.
+	Listen(address string) (net.Listener, error)
+
+	// Dial returns a connection to the listener with the provided address.
+	Dial(ctx context.Context, address string) (net.Conn, error)
+}
+
+var (
+	mu         sync.Mutex
+	transports = map[string]Transport{}
+)
+
+// builtin holds the built-in transports, by network name.
+var builtin = map[string]Transport{
+	"tcp":  netTransport{"tcp"},
+	"unix": netTransport{"unix"},
+}
+
+// Register registers the transport with the provided network name. It panics
+// if the name is already used, is the name of a built-in transport, or is not
+// a valid address scheme.
+func Register(network string, t Transport) {
+	if network == "" || strings.ContainsAny(network, ":/") {
+		panic(fmt.Sprintf("transport: invalid network name %q", network))
+	}
+	if network == "mtls" {
+		// "mtls://" prefixes the addresses of weavelets that use mTLS.
+		panic(fmt.Sprintf("transport: reserved network name %q", network))
+	}
+	if _, ok := builtin[network]; ok {
+		panic(fmt.Sprintf("transport: %q is a built-in transport", network))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := transports[network]; ok {
+		panic(fmt.Sprintf("transport: %q registered twice", network))
+	}
+	transports[network] = t
+}
+
+// Lookup returns the transport with the provided network name, built in or
+// registered, if any.
+func Lookup(network string) (Transport, bool) {
+	if t, ok := builtin[network]; ok {
+		return t, true
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t, ok := transports[network]
+	return t, ok
+}
+
+// netTransport is a Transport that uses the net package.
+type netTransport struct {
+	network string // e.g., "tcp", "unix"
+}
+
+// Listen implements the Transport interface.
+func (t netTransport) Listen(address string) (net.Listener, error) {
+	return net.Listen(t.network, address)
+}
+
+// Dial implements the Transport interface.
+func (t netTransport) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, t.network, address)
+}
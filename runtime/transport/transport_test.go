@@ -0,0 +1,79 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// fakeTransport is a Transport that fails to listen and dial.
+type fakeTransport struct{}
+
+func (fakeTransport) Listen(string) (net.Listener, error) { return nil, net.ErrClosed }
+func (fakeTransport) Dial(context.Context, string) (net.Conn, error) {
+	return nil, net.ErrClosed
+}
+
+func TestRegisterAndLookup(t *testing.T) {
+	Register("fake", fakeTransport{})
+	for _, network := range []string{"tcp", "unix", "fake"} {
+		if _, ok := Lookup(network); !ok {
+			t.Errorf("Lookup(%q): not found", network)
+		}
+	}
+	if _, ok := Lookup("unknown"); ok {
+		t.Errorf("Lookup(%q): unexpectedly found", "unknown")
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	Register("taken", fakeTransport{})
+	for _, network := range []string{"", "a:b", "a/b", "mtls", "tcp", "unix", "taken"} {
+		t.Run(network, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q): unexpected success", network)
+				}
+			}()
+			Register(network, fakeTransport{})
+		})
+	}
+}
+
+func TestBuiltinTCP(t *testing.T) {
+	tcp, _ := Lookup("tcp")
+	lis, err := tcp.Listen("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go func() {
+		if conn, err := lis.Accept(); err == nil {
+			conn.Write([]byte("x"))
+			conn.Close()
+		}
+	}()
+	conn, err := tcp.Dial(context.Background(), lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err != nil || buf[0] != 'x' {
+		t.Fatalf("Read: got %q, %v; want %q, nil", buf, err, "x")
+	}
+}
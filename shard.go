@@ -0,0 +1,68 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import "github.com/ServiceWeaver/weaver/runtime/codegen"
+
+// A ShardKey is a routing key that computes its own shard key. By default, the
+// routing key returned by a router (see [WithRouter]) is hashed field by field
+// into a shard key. If the routing key type implements ShardKey, the shard key
+// of a call is instead the value returned by its Shard method. For example,
+// the following routing key routes calls by tenant and region:
+//
+//	type tenantKey struct {
+//	    Tenant string
+//	    Region string
+//	}
+//
+//	func (k tenantKey) Shard() uint64 {
+//	    return weaver.HashStrings(k.Tenant, k.Region)
+//	}
+//
+//	type storeRouter struct{}
+//	func (storeRouter) Get(_ context.Context, tenant, region, key string) tenantKey {
+//	    return tenantKey{tenant, region}
+//	}
+//
+// Shard must return the same value for equal routing keys, in every process.
+// The shard keys 0 and 2^64-1 are reserved; they are replaced with 1 and
+// 2^64-2, respectively. The shard key of a call is also the shard key passed to
+// the function registered with [Route].
+type ShardKey interface {
+	Shard() uint64
+}
+
+// A ShardHasher hashes the fields of a composite routing key into a shard
+// key. The hash of a sequence of values is the same in every process. For
+// example:
+//
+//	func (k tenantKey) Shard() uint64 {
+//	    var h weaver.ShardHasher
+//	    h.WriteString(k.Tenant)
+//	    h.WriteInt(k.Partition)
+//	    return h.Sum64()
+//	}
+type ShardHasher = codegen.Hasher
+
+// HashStrings returns the shard key of a routing key made of the provided
+// strings. It is shorthand for hashing the strings, in order, with a
+// [ShardHasher].
+func HashStrings(parts ...string) uint64 {
+	var h ShardHasher
+	for _, part := range parts {
+		h.WriteString(part)
+	}
+	return h.Sum64()
+}
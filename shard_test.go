@@ -0,0 +1,31 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weaver
+
+import "testing"
+
+func TestHashStrings(t *testing.T) {
+	var h ShardHasher
+	h.WriteString("acme")
+	h.WriteString("us-east1")
+	if got, want := HashStrings("acme", "us-east1"), h.Sum64(); got != want {
+		t.Fatalf("HashStrings: got %016x, want %016x", got, want)
+	}
+
+	// The strings are hashed separately, not concatenated.
+	if HashStrings("acme", "us-east1") == HashStrings("acmeus", "-east1") {
+		t.Fatal("HashStrings: different keys with the same concatenation have the same hash")
+	}
+}
@@ -79,7 +79,7 @@ func (s bank_local_stub) Deposit(ctx context.Context, a0 string, a1 int) (r0 int
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "bank.Bank.Deposit", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Deposit", "bank.Bank.Deposit", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -89,6 +89,13 @@ func (s bank_local_stub) Deposit(ctx context.Context, a0 string, a1 int) (r0 int
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Bank") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Deposit", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Deposit(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Deposit(ctx, a0, a1)
 }
 
@@ -99,7 +106,7 @@ func (s bank_local_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 in
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "bank.Bank.Withdraw", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Withdraw", "bank.Bank.Withdraw", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -109,6 +116,13 @@ func (s bank_local_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 in
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Bank") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Withdraw", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Withdraw(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Withdraw(ctx, a0, a1)
 }
 
@@ -129,7 +143,7 @@ func (s store_local_stub) Add(ctx context.Context, a0 string, a1 int) (r0 int, e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "bank.Store.Add", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Add", "bank.Store.Add", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -139,6 +153,13 @@ func (s store_local_stub) Add(ctx context.Context, a0 string, a1 int) (r0 int, e
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Store") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Add", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Add(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Add(ctx, a0, a1)
 }
 
@@ -149,7 +170,7 @@ func (s store_local_stub) Get(ctx context.Context, a0 string) (r0 int, err error
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "bank.Store.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Get", "bank.Store.Get", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -159,6 +180,13 @@ func (s store_local_stub) Get(ctx context.Context, a0 string) (r0 int, err error
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Store") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Get(ctx, a0)
 }
 
@@ -182,7 +210,7 @@ func (s bank_client_stub) Deposit(ctx context.Context, a0 string, a1 int) (r0 in
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "bank.Bank.Deposit", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Deposit", "bank.Bank.Deposit", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -190,6 +218,7 @@ func (s bank_client_stub) Deposit(ctx context.Context, a0 string, a1 int) (r0 in
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.depositMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -200,6 +229,8 @@ func (s bank_client_stub) Deposit(ctx context.Context, a0 string, a1 int) (r0 in
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -214,21 +245,27 @@ func (s bank_client_stub) Deposit(ctx context.Context, a0 string, a1 int) (r0 in
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s bank_client_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 int, err error) {
@@ -240,7 +277,7 @@ func (s bank_client_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 i
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "bank.Bank.Withdraw", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Withdraw", "bank.Bank.Withdraw", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -248,6 +285,7 @@ func (s bank_client_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 i
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.withdrawMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -258,6 +296,8 @@ func (s bank_client_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 i
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -272,21 +312,27 @@ func (s bank_client_stub) Withdraw(ctx context.Context, a0 string, a1 int) (r0 i
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type store_client_stub struct {
@@ -307,7 +353,7 @@ func (s store_client_stub) Add(ctx context.Context, a0 string, a1 int) (r0 int,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "bank.Store.Add", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Add", "bank.Store.Add", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -315,6 +361,7 @@ func (s store_client_stub) Add(ctx context.Context, a0 string, a1 int) (r0 int,
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.addMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -325,6 +372,8 @@ func (s store_client_stub) Add(ctx context.Context, a0 string, a1 int) (r0 int,
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -339,21 +388,27 @@ func (s store_client_stub) Add(ctx context.Context, a0 string, a1 int) (r0 int,
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s store_client_stub) Get(ctx context.Context, a0 string) (r0 int, err error) {
@@ -365,7 +420,7 @@ func (s store_client_stub) Get(ctx context.Context, a0 string) (r0 int, err erro
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "bank.Store.Get", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Get", "bank.Store.Get", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -373,6 +428,7 @@ func (s store_client_stub) Get(ctx context.Context, a0 string) (r0 int, err erro
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -383,6 +439,8 @@ func (s store_client_stub) Get(ctx context.Context, a0 string) (r0 int, err erro
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -395,30 +453,36 @@ func (s store_client_stub) Get(ctx context.Context, a0 string) (r0 int, err erro
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -458,24 +522,43 @@ func (s bank_server_stub) GetStubFn(method string) func(ctx context.Context, arg
 }
 
 func (s bank_server_stub) deposit(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Deposit", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Deposit(ctx, a0, a1)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Bank") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Deposit", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Deposit(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Deposit(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -485,24 +568,43 @@ func (s bank_server_stub) deposit(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s bank_server_stub) withdraw(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Withdraw", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Withdraw(ctx, a0, a1)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Bank") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Bank", "Withdraw", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Withdraw(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Withdraw(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -532,24 +634,43 @@ func (s store_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s store_server_stub) add(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Add", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Add(ctx, a0, a1)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Store") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Add", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Add(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Add(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -559,22 +680,41 @@ func (s store_server_stub) add(ctx context.Context, args []byte) (res []byte, er
 }
 
 func (s store_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Get", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Get(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/internal/bank/Store") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/internal/bank/Store", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Get(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -159,7 +159,7 @@ func (s blocker_local_stub) Block(ctx context.Context) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "sim.blocker.Block", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/blocker", "Block", "sim.blocker.Block", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -169,6 +169,13 @@ func (s blocker_local_stub) Block(ctx context.Context) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/blocker") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/blocker", "Block", func(ctx context.Context) (err error) {
+			err = s.impl.Block(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.Block(ctx)
 }
 
@@ -188,7 +195,7 @@ func (s div_local_stub) Div(ctx context.Context, a0 int, a1 int) (r0 int, err er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "sim.div.Div", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/div", "Div", "sim.div.Div", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -198,6 +205,13 @@ func (s div_local_stub) Div(ctx context.Context, a0 int, a1 int) (r0 int, err er
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/div") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/div", "Div", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Div(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Div(ctx, a0, a1)
 }
 
@@ -217,7 +231,7 @@ func (s divMod_local_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "sim.divMod.DivMod", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/divMod", "DivMod", "sim.divMod.DivMod", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -227,6 +241,13 @@ func (s divMod_local_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/divMod") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/divMod", "DivMod", func(ctx context.Context) (err error) {
+			r0, r1, err = s.impl.DivMod(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.DivMod(ctx, a0, a1)
 }
 
@@ -246,7 +267,7 @@ func (s identity_local_stub) Identity(ctx context.Context, a0 int) (r0 int, err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "sim.identity.Identity", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/identity", "Identity", "sim.identity.Identity", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -256,6 +277,13 @@ func (s identity_local_stub) Identity(ctx context.Context, a0 int) (r0 int, err
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/identity") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/identity", "Identity", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Identity(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Identity(ctx, a0)
 }
 
@@ -275,7 +303,7 @@ func (s mod_local_stub) Mod(ctx context.Context, a0 int, a1 int) (r0 int, err er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "sim.mod.Mod", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/mod", "Mod", "sim.mod.Mod", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -285,6 +313,13 @@ func (s mod_local_stub) Mod(ctx context.Context, a0 int, a1 int) (r0 int, err er
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/mod") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/mod", "Mod", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Mod(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Mod(ctx, a0, a1)
 }
 
@@ -304,7 +339,7 @@ func (s panicker_local_stub) Panic(ctx context.Context, a0 bool) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "sim.panicker.Panic", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/sim/panicker", "Panic", "sim.panicker.Panic", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -314,6 +349,13 @@ func (s panicker_local_stub) Panic(ctx context.Context, a0 bool) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/panicker") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/panicker", "Panic", func(ctx context.Context) (err error) {
+			err = s.impl.Panic(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Panic(ctx, a0)
 }
 
@@ -336,7 +378,7 @@ func (s blocker_client_stub) Block(ctx context.Context) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "sim.blocker.Block", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/blocker", "Block", "sim.blocker.Block", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -344,6 +386,7 @@ func (s blocker_client_stub) Block(ctx context.Context) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.blockMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -354,23 +397,30 @@ func (s blocker_client_stub) Block(ctx context.Context) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type div_client_stub struct {
@@ -390,7 +440,7 @@ func (s div_client_stub) Div(ctx context.Context, a0 int, a1 int) (r0 int, err e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "sim.div.Div", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/div", "Div", "sim.div.Div", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -398,6 +448,7 @@ func (s div_client_stub) Div(ctx context.Context, a0 int, a1 int) (r0 int, err e
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.divMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -408,6 +459,8 @@ func (s div_client_stub) Div(ctx context.Context, a0 int, a1 int) (r0 int, err e
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -422,21 +475,27 @@ func (s div_client_stub) Div(ctx context.Context, a0 int, a1 int) (r0 int, err e
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type divMod_client_stub struct {
@@ -456,7 +515,7 @@ func (s divMod_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "sim.divMod.DivMod", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/divMod", "DivMod", "sim.divMod.DivMod", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -464,6 +523,7 @@ func (s divMod_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.divModMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -474,6 +534,8 @@ func (s divMod_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -488,22 +550,28 @@ func (s divMod_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	r1 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		r1 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type identity_client_stub struct {
@@ -523,7 +591,7 @@ func (s identity_client_stub) Identity(ctx context.Context, a0 int) (r0 int, err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "sim.identity.Identity", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/identity", "Identity", "sim.identity.Identity", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -531,6 +599,7 @@ func (s identity_client_stub) Identity(ctx context.Context, a0 int) (r0 int, err
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.identityMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -541,6 +610,8 @@ func (s identity_client_stub) Identity(ctx context.Context, a0 int) (r0 int, err
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -553,21 +624,27 @@ func (s identity_client_stub) Identity(ctx context.Context, a0 int) (r0 int, err
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type mod_client_stub struct {
@@ -587,7 +664,7 @@ func (s mod_client_stub) Mod(ctx context.Context, a0 int, a1 int) (r0 int, err e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "sim.mod.Mod", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/mod", "Mod", "sim.mod.Mod", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -595,6 +672,7 @@ func (s mod_client_stub) Mod(ctx context.Context, a0 int, a1 int) (r0 int, err e
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.modMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -605,6 +683,8 @@ func (s mod_client_stub) Mod(ctx context.Context, a0 int, a1 int) (r0 int, err e
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -619,21 +699,27 @@ func (s mod_client_stub) Mod(ctx context.Context, a0 int, a1 int) (r0 int, err e
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type panicker_client_stub struct {
@@ -653,7 +739,7 @@ func (s panicker_client_stub) Panic(ctx context.Context, a0 bool) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "sim.panicker.Panic", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/sim/panicker", "Panic", "sim.panicker.Panic", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -661,6 +747,7 @@ func (s panicker_client_stub) Panic(ctx context.Context, a0 bool) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.panicMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -671,6 +758,8 @@ func (s panicker_client_stub) Panic(ctx context.Context, a0 bool) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -683,29 +772,35 @@ func (s panicker_client_stub) Panic(ctx context.Context, a0 bool) (err error) {
 	enc.Bool(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -746,14 +841,27 @@ func (s blocker_server_stub) block(ctx context.Context, args []byte) (res []byte
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/blocker", "Block", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Block(ctx)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/blocker") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/blocker", "Block", func(ctx context.Context) (err error) {
+			err = s.impl.Block(ctx)
+			return err
+		})
+	} else {
+		appErr = s.impl.Block(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -780,24 +888,43 @@ func (s div_server_stub) GetStubFn(method string) func(ctx context.Context, args
 }
 
 func (s div_server_stub) div(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/div", "Div", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Div(ctx, a0, a1)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/div") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/div", "Div", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Div(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Div(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -825,24 +952,44 @@ func (s divMod_server_stub) GetStubFn(method string) func(ctx context.Context, a
 }
 
 func (s divMod_server_stub) divMod(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/divMod", "DivMod", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, r1, appErr := s.impl.DivMod(ctx, a0, a1)
+	var r0 int
+	var r1 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/divMod") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/divMod", "DivMod", func(ctx context.Context) (err error) {
+			r0, r1, err = s.impl.DivMod(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, r1, appErr = s.impl.DivMod(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -871,22 +1018,41 @@ func (s identity_server_stub) GetStubFn(method string) func(ctx context.Context,
 }
 
 func (s identity_server_stub) identity(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/identity", "Identity", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Identity(ctx, a0)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/identity") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/identity", "Identity", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Identity(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Identity(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -914,24 +1080,43 @@ func (s mod_server_stub) GetStubFn(method string) func(ctx context.Context, args
 }
 
 func (s mod_server_stub) mod(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+	var a1 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/mod", "Mod", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
-	var a1 int
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Mod(ctx, a0, a1)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/mod") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/mod", "Mod", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Mod(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Mod(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -959,22 +1144,40 @@ func (s panicker_server_stub) GetStubFn(method string) func(ctx context.Context,
 }
 
 func (s panicker_server_stub) panic(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 bool
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/sim/panicker", "Panic", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 bool
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Bool()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Panic(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/sim/panicker") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/sim/panicker", "Panic", func(ctx context.Context) (err error) {
+			err = s.impl.Panic(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.Panic(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1075,4 +1278,13 @@ func (x *zeroError) WeaverUnmarshal(dec *codegen.Decoder) {
 		panic(fmt.Errorf("zeroError.WeaverUnmarshal: nil receiver"))
 	}
 }
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *zeroError) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString()
+}
 func init() { codegen.RegisterSerializable[*zeroError]() }
@@ -45,6 +45,10 @@ func validateRegistrations(regs []*codegen.Registration) error {
 			switch {
 			case f.Type.Implements(reflection.Type[interface{ isRef() }]()):
 				// f is a weaver.Ref[T].
+				if tag, ok := f.Tag.Lookup("weaver"); ok && tag != lazyRefTag {
+					err := fmt.Errorf("component implementation struct %v has component reference field %v with invalid tag %q; the only valid tag is %q", reg.Impl, f.Name, tag, lazyRefTag)
+					errs = append(errs, err)
+				}
 				v := f.Type.Field(0) // a Ref[T]'s value field
 				if _, ok := intfs[v.Type]; !ok {
 					// T is not a registered component interface.
@@ -55,6 +59,17 @@ func validateRegistrations(regs []*codegen.Registration) error {
 					errs = append(errs, err)
 				}
 
+			case f.Type.Implements(reflection.Type[interface{ isRefs() }]()):
+				// f is a weaver.Refs[T].
+				t := f.Type.Field(0).Type.Out(0).Elem() // a Refs[T]'s get field returns []T
+				if _, ok := intfs[t]; !ok {
+					err := fmt.Errorf(
+						"component implementation struct %v has component references field %v, but component %v was not registered; maybe you forgot to run 'weaver generate'",
+						reg.Impl, f.Type, t,
+					)
+					errs = append(errs, err)
+				}
+
 			case f.Type == reflection.Type[Listener]():
 				// f is a weaver.Listener.
 				name := f.Name
@@ -83,6 +83,52 @@ func TestValidateUnregisteredRef(t *testing.T) {
 	}
 }
 
+// TestValidateInvalidRefTag tests that validateRegistrations fails when a
+// component has a weaver.Ref with a tag other than "lazy".
+func TestValidateInvalidRefTag(t *testing.T) {
+	type foo interface{}
+	type fooImpl struct {
+		f Ref[foo] `weaver:"eager"`
+	}
+	regs := []*codegen.Registration{
+		{
+			Name:  "foo",
+			Iface: reflection.Type[foo](),
+			Impl:  reflection.Type[fooImpl](),
+		},
+	}
+	err := validateRegistrations(regs)
+	if err == nil {
+		t.Fatal("unexpected validateRegistrations success")
+	}
+	const want = `invalid tag "eager"`
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("validateRegistrations: got %q, want %q", err, want)
+	}
+}
+
+// TestValidateUnregisteredRefs tests that validateRegistrations fails when a
+// component has a weaver.Refs on an unregistered component.
+func TestValidateUnregisteredRefs(t *testing.T) {
+	type foo interface{}
+	type fooImpl struct{ readers Refs[io.Reader] }
+	regs := []*codegen.Registration{
+		{
+			Name:  "foo",
+			Iface: reflection.Type[foo](),
+			Impl:  reflection.Type[fooImpl](),
+		},
+	}
+	err := validateRegistrations(regs)
+	if err == nil {
+		t.Fatal("unexpected validateRegistrations success")
+	}
+	const want = "component io.Reader was not registered"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("validateRegistrations: got %q, want %q", err, want)
+	}
+}
+
 // TestValidateInvalidListenerNames tests that validateRegistrations fails on
 // invalid listener names.
 func TestValidateInvalidListenerNames(t *testing.T) {
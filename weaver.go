@@ -29,13 +29,20 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	"github.com/ServiceWeaver/weaver/internal/reflection"
 	"github.com/ServiceWeaver/weaver/internal/weaver"
+	"github.com/ServiceWeaver/weaver/metadata"
 	"github.com/ServiceWeaver/weaver/runtime"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"go.opentelemetry.io/otel/trace"
@@ -71,6 +78,139 @@ import (
 // example.
 var RemoteCallError = errors.New("Service Weaver remote call error")
 
+// ReadOnlyError is returned by a call to a method of a component that is
+// running in read-only mode when the method is not marked //weaver:readonly.
+// For example, consider the following component:
+//
+//	type BalanceReader interface {
+//	    //weaver:readonly
+//	    GetBalance(context.Context, string) (int64, error)
+//
+//	    //weaver:write
+//	    SetBalance(context.Context, string, int64) error
+//	}
+//
+// If the BalanceReader component is listed in the "[readonly]" section of the
+// config file, then calls to GetBalance are served as usual, but calls to
+// SetBalance fail with an error that wraps ReadOnlyError:
+//
+//	[readonly]
+//	components = ["github.com/example/bank/BalanceReader"]
+//
+// Read-only mode is enforced on all calls, whether the caller runs in the
+// same process as the component or not.
+var ReadOnlyError = weaver.ReadOnlyError
+
+// UnavailableError is returned by a remote call to a component whose process
+// is shutting down. When a process receives a SIGINT or SIGTERM signal, it
+// stops accepting new calls and lets the calls in flight finish before calling
+// the Shutdown methods of its components. Calls rejected in the meantime fail
+// with an error that wraps UnavailableError. The error is marked as Retryable,
+// so the caller retries the call, typically on another replica.
+//
+// Calls to a component that reports that it is unhealthy also fail with an
+// error that wraps UnavailableError, if the caller is configured to consult
+// the component's health in the "[health_gating]" section of the config file.
+var UnavailableError = weaver.UnavailableError
+
+// OverloadedError is returned by a remote call to a component that has too
+// many calls in flight. You can bound the number of calls that every replica
+// of a component runs at once in the "[load_shedding]" section of the config
+// file:
+//
+//	[load_shedding]
+//	"github.com/example/catalog/Catalog" = 100
+//
+// Calls beyond the limit are rejected, instead of piling up, with an error
+// that wraps OverloadedError. The error is marked as Retryable, so the caller
+// retries the call, typically on another replica. The load of a rejected call
+// is still reported, so routed components are rebalanced as usual.
+var OverloadedError = codegen.OverloadedError
+
+// RateLimitedError is returned by a remote call to a component method that
+// exceeds the rate limit of its caller. You can limit the rate of calls to the
+// methods of a component in the "[rate_limits]" section of the config file:
+//
+//	[rate_limits]
+//	"github.com/example/catalog/Catalog" = {GetProduct = {rate = 100.0, burst = 20}}
+//
+// Every calling component has its own budget of rate calls per second, with
+// bursts of up to burst calls. Calls beyond the budget are rejected, without
+// running the method, with an error that wraps RateLimitedError. The error is
+// marked as Retryable, so the caller retries the call with backoff.
+var RateLimitedError = weaver.RateLimitedError
+
+// BulkheadFullError is returned by a remote call from a component to another
+// when the caller already has as many calls in flight to the callee as it is
+// allowed. You can bound the calls in flight between two components in the
+// "[bulkheads]" section of the config file:
+//
+//	[bulkheads]
+//	"github.com/example/frontend/Frontend" = {"github.com/example/catalog/Catalog" = 50}
+//
+// Calls beyond the limit are rejected by the caller, without being sent, with
+// an error that wraps BulkheadFullError. The error is marked as Retryable.
+var BulkheadFullError = weaver.BulkheadFullError
+
+// HandlerPoolFullError is returned by a remote call to a component whose
+// handler pool is full. You can run the calls to every replica of a component
+// on a fixed number of workers in the "[handler_pools]" section of the config
+// file:
+//
+//	[handler_pools]
+//	"github.com/example/catalog/Catalog" = {workers = 16, queue = 64}
+//
+// Calls wait for a free worker in a queue of at most queue calls. Calls that
+// find the queue full are rejected, without running the method, with an error
+// that wraps HandlerPoolFullError. The error is marked as Retryable.
+var HandlerPoolFullError = weaver.HandlerPoolFullError
+
+// PayloadTooLargeError is returned by a remote call whose serialized request
+// or reply is larger than the limit configured for the callee in the
+// "[payload_limits]" section of the config file:
+//
+//	[payload_limits]
+//	"github.com/example/catalog/Catalog" = {max_request = 1048576, max_reply = 4194304}
+//
+// Oversized requests are rejected by the caller before they are sent, and
+// oversized replies are rejected by the callee before they are sent, and by
+// the caller before they are decoded.
+var PayloadTooLargeError = codegen.PayloadTooLargeError
+
+// ConflictError is returned by a call to a component method that receives a
+// stale versioned entity. An entity is versioned if it is an AutoMarshal
+// struct with an integer field tagged with `weaver:"version"`:
+//
+//	type Account struct {
+//	    weaver.AutoMarshal
+//	    Balance int64
+//	    Version uint64 `weaver:"version"`
+//	}
+//
+// If a component implementation has a method with the following signature,
+// then it is used to check and advance the versions of the versioned
+// arguments of its component methods:
+//
+//	CompareAndSet(ctx context.Context, entities []any, versions []uint64, apply func() error) (bool, error)
+//
+// A component method that receives versioned arguments is invoked by
+// CompareAndSet, which should atomically check that the stored version of
+// every entity equals the corresponding version, call apply to run the
+// component method, and advance every version if apply succeeds. If any
+// version is stale, CompareAndSet should return false without calling apply,
+// and the call fails with an error that wraps ConflictError. No version
+// should be advanced if apply fails. The caller can then read the entities
+// again and retry.
+var ConflictError = codegen.ConflictError
+
+// UnknownMethodError is returned by a call to a component method that the
+// callee doesn't have. During a rolling upgrade, a caller built from a newer
+// version of a component may call a method that replicas running the older
+// version don't have yet. These calls fail with an error that wraps
+// UnknownMethodError and is marked as Retryable, so the caller retries the
+// call, possibly against an up-to-date replica.
+var UnknownMethodError = codegen.UnknownMethodError
+
 // HealthzHandler is a health-check handler that returns an OK status for all
 // incoming HTTP requests.
 var HealthzHandler = func(w http.ResponseWriter, _ *http.Request) {
@@ -177,7 +317,7 @@ func runLocal[T any, _ PointerToMain[T]](ctx context.Context, app func(context.C
 	if err != nil {
 		return err
 	}
-	return app(ctx, main.(*T))
+	return app(weaver.NewContext(ctx, wlet), main.(*T))
 }
 
 func runRemote[T any, _ PointerToMain[T]](ctx context.Context, app func(context.Context, *T) error, bootstrap runtime.Bootstrap) error {
@@ -201,7 +341,7 @@ func runRemote[T any, _ PointerToMain[T]](ctx context.Context, app func(context.
 			return err
 		}
 		go func() {
-			errs <- app(ctx, main.(*T))
+			errs <- app(weaver.NewContext(ctx, wlet), main.(*T))
 		}()
 	}
 	go func() {
@@ -225,6 +365,12 @@ type WeaverInfo struct {
 	// time, name of the deployer).
 }
 
+// Clock is a source of time. See Implements.Clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
 // Implements[T] is a type that is be embedded inside a component
 // implementation struct to indicate that the struct implements a component of
 // type T. For example, consider a Cache component.
@@ -268,7 +414,8 @@ type Implements[T any] struct {
 
 // Logger returns a logger that associates its log entries with this component.
 // Log entries are labeled with any OpenTelemetry trace id and span id in the
-// provided context.
+// provided context, and with debug=true if the context is marked for debugging
+// (see metadata.WithDebug).
 func (i Implements[T]) Logger(ctx context.Context) *slog.Logger {
 	logger := i.logger
 	s := trace.SpanContextFromContext(ctx)
@@ -278,6 +425,9 @@ func (i Implements[T]) Logger(ctx context.Context) *slog.Logger {
 	if s.HasSpanID() {
 		logger = logger.With("spanid", s.SpanID().String())
 	}
+	if metadata.IsDebug(ctx) {
+		logger = logger.With("debug", true)
+	}
 	return logger
 }
 
@@ -294,6 +444,14 @@ func (i *Implements[T]) setWeaverInfo(info *weaver.WeaverInfo) {
 	i.weaverInfo = info
 }
 
+// Clock returns the clock that the component should use, rather than
+// time.Now, to tell the time. It is the system clock, except in tests that
+// replace it with a fake clock (see weavertest.Runner.Clock). Service Weaver
+// also uses this clock to measure method latencies.
+func (i Implements[T]) Clock() Clock {
+	return clock.Get()
+}
+
 // implements is a method that can only be implemented inside the weaver
 // package. It exists so that a component struct that embeds Implements[T]
 // implements the InstanceOf[T] interface.
@@ -310,6 +468,22 @@ type InstanceOf[T any] interface {
 // Ref[T] is a field that can be placed inside a component implementation
 // struct. T must be a component type. Service Weaver will automatically
 // fill such a field with a handle to the corresponding component.
+//
+// # Lazy Refs
+//
+// By default, the handle is dialed when the component that holds the Ref is
+// initialized. In a large deployment, where every component dials all of its
+// refs at once, this can cause a burst of connections at startup. A Ref tagged
+// with `weaver:"lazy"` is instead dialed the first time one of its methods is
+// called:
+//
+//	type app struct {
+//	    weaver.Implements[weaver.Main]
+//	    catalog weaver.Ref[Catalog] `weaver:"lazy"`
+//	}
+//
+// A lazy Ref is used just like any other Ref. The latency of its first dial
+// is recorded by the serviceweaver_ref_first_dial_latency_micros metric.
 type Ref[T any] struct {
 	value T
 }
@@ -326,6 +500,189 @@ func (r *Ref[T]) setRef(value any) {
 	r.value = value.(T)
 }
 
+// Refs[T] is a field that can be placed inside a component implementation
+// struct. T must be a component type. Service Weaver will automatically fill
+// such a field with a way to reach every replica of the corresponding
+// component, e.g., to scatter a request across the shards of a sharded
+// component and gather the results. See [CallAll] and [CallQuorum].
+type Refs[T any] struct {
+	get func() ([]T, error)
+}
+
+// Get returns a handle to every replica of the component of type T that is
+// currently available. A call through a returned handle is always sent to the
+// same replica. If the component is colocated with the caller, Get returns a
+// single handle to the local replica. If the caller's calls to the component
+// are gated on the component's health (see the health_gating config section),
+// the replicas that recently reported that they are unhealthy are left out.
+//
+// The set of replicas changes over time, so Get should be called every time
+// the replicas are needed, rather than once.
+func (r Refs[T]) Get() ([]T, error) {
+	if r.get == nil {
+		return nil, fmt.Errorf("weaver.Refs[%v] not initialized", reflection.Type[T]())
+	}
+	return r.get()
+}
+
+// isRefs is an internal method that is only implemented by Refs[T] and is
+// used internally to check that a value is of type Refs[T].
+func (r Refs[T]) isRefs() {}
+
+// setRefs sets the function that returns the replicas of a Refs.
+func (r *Refs[T]) setRefs(get func() ([]any, error)) {
+	r.get = func() ([]T, error) {
+		replicas, err := get()
+		if err != nil {
+			return nil, err
+		}
+		ts := make([]T, len(replicas))
+		for i, replica := range replicas {
+			ts[i] = replica.(T)
+		}
+		return ts, nil
+	}
+}
+
+// CallAll calls f concurrently with a handle to every replica in refs, and
+// returns the results in replica order. For example:
+//
+//	type searcher struct {
+//	    weaver.Implements[Searcher]
+//	    shards weaver.Refs[Shard]
+//	}
+//
+//	func (s *searcher) Search(ctx context.Context, query string) ([]Item, error) {
+//	    results, err := weaver.CallAll(ctx, s.shards, func(ctx context.Context, shard Shard) ([]Item, error) {
+//	        return shard.Search(ctx, query)
+//	    })
+//	    ...
+//	}
+//
+// If some calls fail, CallAll returns the errors joined together, along with
+// the results of every replica, where a failed replica's result is the zero
+// value of R.
+func CallAll[T, R any](ctx context.Context, refs Refs[T], f func(context.Context, T) (R, error)) ([]R, error) {
+	replicas, err := refs.Get()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]R, len(replicas))
+	errs := make([]error, len(replicas))
+	var wg sync.WaitGroup
+	for i, replica := range replicas {
+		i, replica := i, replica
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = f(ctx, replica)
+		}()
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
+
+// CallQuorum calls f concurrently with a handle to every replica in refs, and
+// returns as soon as quorum of the calls succeed, canceling the context of the
+// calls still running. For example, a write that must reach a majority of the
+// replicas of a component can be made as follows:
+//
+//	func (s *store) Put(ctx context.Context, key, value string) error {
+//	    replicas, err := s.replicas.Get()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    _, err = weaver.CallQuorum(ctx, s.replicas, len(replicas)/2+1, func(ctx context.Context, r Replica) (struct{}, error) {
+//	        return struct{}{}, r.Put(ctx, key, value)
+//	    })
+//	    return err
+//	}
+//
+// The i-th result is the result of the call to the i-th replica, or the zero
+// value of R if the call didn't succeed before CallQuorum returned. If so many
+// calls fail that a quorum can't be reached, CallQuorum cancels the remaining
+// calls and returns a *QuorumError that records which calls failed.
+func CallQuorum[T, R any](ctx context.Context, refs Refs[T], quorum int, f func(context.Context, T) (R, error)) ([]R, error) {
+	replicas, err := refs.Get()
+	if err != nil {
+		return nil, err
+	}
+	if quorum <= 0 {
+		return nil, fmt.Errorf("weaver.CallQuorum: non-positive quorum %d", quorum)
+	}
+	if quorum > len(replicas) {
+		return nil, &QuorumError{Quorum: quorum, Errs: make([]error, len(replicas))}
+	}
+
+	type outcome struct {
+		i      int
+		result R
+		err    error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	outcomes := make(chan outcome, len(replicas))
+	for i, replica := range replicas {
+		i, replica := i, replica
+		go func() {
+			result, err := f(ctx, replica)
+			outcomes <- outcome{i, result, err}
+		}()
+	}
+
+	results := make([]R, len(replicas))
+	errs := make([]error, len(replicas))
+	succeeded, failed := 0, 0
+	for succeeded < quorum {
+		o := <-outcomes
+		if o.err != nil {
+			errs[o.i] = o.err
+			failed++
+			if len(replicas)-failed < quorum {
+				return results, &QuorumError{Quorum: quorum, Succeeded: succeeded, Errs: errs}
+			}
+			continue
+		}
+		results[o.i] = o.result
+		succeeded++
+	}
+	return results, nil
+}
+
+// QuorumError is the error returned by [CallQuorum] when fewer than a quorum
+// of the calls succeed.
+type QuorumError struct {
+	Quorum    int // number of calls that had to succeed
+	Succeeded int // number of calls that succeeded
+
+	// Errs[i] is the error returned by the call to the i-th replica, or nil
+	// if the call succeeded or was canceled.
+	Errs []error
+}
+
+// Error implements the error interface.
+func (e *QuorumError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "weaver.CallQuorum: %d of %d replicas succeeded, want %d", e.Succeeded, len(e.Errs), e.Quorum)
+	for i, err := range e.Errs {
+		if err != nil {
+			fmt.Fprintf(&b, "\nreplica %d: %v", i, err)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the calls that failed.
+func (e *QuorumError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // Listener is a network listener that can be placed as a field inside a
 // component implementation struct. Once placed, Service Weaver automatically
 // initializes the Listener and makes it suitable for receiving network
@@ -387,6 +744,35 @@ func (l *Listener) ProxyAddr() string {
 	return l.proxyAddr
 }
 
+// Worker is a long-running background loop that can be placed as a field
+// inside a component implementation struct. Assign the field in the
+// component's Init method, and Service Weaver runs the worker in its own
+// goroutine once Init returns successfully. For example:
+//
+//	type ledger struct {
+//	    weaver.Implements[Ledger]
+//	    reader weaver.Worker
+//	}
+//
+//	func (l *ledger) Init(context.Context) error {
+//	    l.reader = l.readLedger
+//	    return nil
+//	}
+//
+//	func (l *ledger) readLedger(ctx context.Context) error {
+//	    for {
+//	        // Read the next entry, returning when ctx is cancelled...
+//	    }
+//	}
+//
+// The context passed to a worker is cancelled when the component shuts down,
+// before its Shutdown method, if any, is called. If a worker panics or returns
+// a non-nil error before then, it is logged and restarted after a backoff. A
+// worker that returns nil is done and is not restarted. Workers are identified
+// by their field names, which are reported in the
+// "serviceweaver_worker_restart_count" metric that counts restarts.
+type Worker func(ctx context.Context) error
+
 // WithConfig[T] is a type that can be embedded inside a component
 // implementation. The Service Weaver runtime will take per-component
 // configuration information found in the application config file and use it to
@@ -433,8 +819,49 @@ func (l *Listener) ProxyAddr() string {
 //
 //	["example.com/mypkg/Cache"]
 //	my_custom_name = 1000
+//
+// # Validation
+//
+// If *T has a Validate() error method, it is called after the config is
+// loaded, and before the component's Init method is called, even if the config
+// file has no section for the component. If Validate returns an error, the
+// component fails to start with that error.
+//
+//	func (c *cacheConfig) Validate() error {
+//	    if c.Size <= 0 {
+//	        return fmt.Errorf("Size must be positive, got %d", c.Size)
+//	    }
+//	    return nil
+//	}
+//
+// # Reconfiguration
+//
+// If the component implementation has a Reconfigure(T) error method, the
+// component is reconfigured in place when its config section changes, rather
+// than keeping the config it was started with until it is restarted:
+//
+//	func (c *cache) Reconfigure(cfg cacheConfig) error {
+//	    c.resize(cfg.Size)
+//	    return nil
+//	}
+//
+// Reconfigure is passed the new config, after it is validated. If Reconfigure
+// returns nil, Config returns the new config from then on; otherwise, the
+// component keeps its current config. Reconfigure is never called
+// concurrently with itself, but it is called concurrently with the methods of
+// the component, so it must synchronize with them. Config never modifies a
+// config that it has returned, so a method call in flight keeps using the
+// config it read, even if the component is reconfigured in the meantime.
+//
+// Components without a Reconfigure method ignore changes to their config.
 type WithConfig[T any] struct {
 	config T
+
+	// current holds the latest config of a component that can be
+	// reconfigured, or nil if the component hasn't been reconfigured yet.
+	// current is nil for other components. It is a pointer so that
+	// WithConfig can be copied, e.g., by methods with value receivers.
+	current *atomic.Pointer[T]
 }
 
 // Config returns the configuration information for the component that embeds
@@ -446,6 +873,11 @@ type WithConfig[T any] struct {
 // Any fields in the application config file that are not present in T will be
 // flagged as an error at application startup.
 func (wc *WithConfig[T]) Config() *T {
+	if wc.current != nil {
+		if current := wc.current.Load(); current != nil {
+			return current
+		}
+	}
 	return &wc.config
 }
 
@@ -454,6 +886,19 @@ func (wc *WithConfig[T]) getConfig() any {
 	return &wc.config
 }
 
+// prepareConfig prepares the config to be replaced by setConfig. It must be
+// called before the component is used.
+func (wc *WithConfig[T]) prepareConfig() {
+	wc.current = &atomic.Pointer[T]{}
+}
+
+// setConfig replaces the config returned by Config with cfg, of type *T.
+//
+// REQUIRES: prepareConfig has been called.
+func (wc *WithConfig[T]) setConfig(cfg any) {
+	wc.current.Store(cfg.(*T))
+}
+
 // WithRouter[T] is a type that can be embedded inside a component
 // implementation struct to indicate that calls to a method M on the component
 // must be routed according to the the value returned by T.M().
@@ -505,6 +950,10 @@ func (wc *WithConfig[T]) getConfig() any {
 //	struct{x int; y string}
 //	struct{weaver.AutoMarshal; x int; y string}
 //
+// A routing key can also be any type that implements [ShardKey], in which
+// case its Shard method, rather than a hash of its fields, determines the
+// shard key of a call.
+//
 // Every router method must return the same routing key type. The following,
 // for example, is invalid:
 //
@@ -548,6 +997,138 @@ type Unrouted interface {
 
 var _ Unrouted = (*implementsImpl)(nil)
 
+// Route overrides how calls to the routed component with interface type T are
+// routed across its replicas. By default, a call is routed according to a
+// routing assignment that Service Weaver computes from the load of each shard
+// key. With Route, a call with shard key k is instead routed to the replica
+// at address fn(k, replicas), where replicas holds the sorted, non-empty list
+// of addresses of the replicas that are currently available. fn may return the empty string
+// to route the call using the default assignment.
+//
+// A shard key is a hash of the routing key returned by the component's router
+// (see [WithRouter]). The mapping from shard keys to replicas is up to fn. For
+// example, it can pin some shard keys to a fixed replica for cache locality.
+//
+// Route returns an error if T is not a routed component. It must be called
+// before [Run], e.g., at the beginning of main.
+//
+// NOTE that the routing of a call is still best-effort, and the same caveats
+// listed for [WithRouter] apply.
+func Route[T any](fn func(shardKey uint64, replicas []string) string) error {
+	return codegen.SetRouterFn(reflection.Type[T](), fn)
+}
+
+// RecordRPCs records every remote method call made by this process in a file
+// in directory dir. The serialized arguments and results of every call are
+// recorded exactly as they are sent over the wire. The recorded calls can be
+// replayed in tests with weavertest.Replay. For example:
+//
+//	func main() {
+//	    if err := weaver.RecordRPCs("/tmp/rpcs"); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    if err := weaver.Run(context.Background(), serve); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+//
+// Calls between components that are colocated in the same process are not
+// serialized, and are therefore not recorded. RecordRPCs must be called before
+// [Run], e.g., at the beginning of main.
+func RecordRPCs(dir string) error {
+	return codegen.RecordRPCs(dir)
+}
+
+// An Interceptor wraps the calls to the methods of a component. It is passed
+// the call's context and a [CallInfo] describing the call, and must call next
+// to run the method, optionally with a derived context. The error returned by
+// the interceptor is returned to the caller. See [Intercept].
+type Interceptor = codegen.Interceptor
+
+// CallInfo describes a component method call passed to an [Interceptor].
+type CallInfo = codegen.CallInfo
+
+// Intercept registers interceptors that wrap every call to a method of the
+// component with interface type T. For example:
+//
+//	func init() {
+//	    weaver.Intercept[Cache](func(ctx context.Context, info weaver.CallInfo, next func(context.Context) error) error {
+//	        start := time.Now()
+//	        err := next(ctx)
+//	        log.Printf("%s.%s took %v", info.Component, info.Method, time.Since(start))
+//	        return err
+//	    })
+//	}
+//
+// Interceptors run in the process hosting the component, whether the call is
+// local or remote, and in the order they are registered, with the first
+// interceptor outermost. Intercept should be called in an init function, so
+// that every process hosting the component registers the same interceptors.
+func Intercept[T any](interceptors ...Interceptor) {
+	codegen.RegisterInterceptors(reflection.ComponentName[T](), interceptors...)
+}
+
+// PanicInfo describes a panic in a component method that was called remotely.
+// See [OnPanic].
+type PanicInfo = codegen.PanicInfo
+
+// OnPanic registers f to be called with every panic that occurs while a
+// remote call to a component method is served, whether the panic happens
+// while decoding the call's arguments, running the method, or encoding its
+// results. For example:
+//
+//	func init() {
+//	    weaver.OnPanic(func(info weaver.PanicInfo) {
+//	        log.Printf("%s.%s%v panicked: %v\n%s", info.Component, info.Method, info.Args, info.Value, info.Stack)
+//	    })
+//	}
+//
+// f is called in the process hosting the component, on the goroutine that
+// panicked and before the call returns. A panic caused by malformed arguments
+// or results is returned to the caller as an error, but a panic in the method
+// itself still crashes the process once f returns, so f should record the
+// panic synchronously, e.g., by flushing it to durable storage. f is not called
+// for panics in local calls. OnPanic should be called in an init function, so
+// that every process hosting components registers the same handlers.
+func OnPanic(f func(PanicInfo)) {
+	codegen.RegisterPanicHandler(f)
+}
+
+// A TraceSampler decides whether a traced call to a component method creates
+// a span. See [SampleTraces].
+type TraceSampler = codegen.TraceSampler
+
+// SampleTraces registers a sampler that decides which traced calls to the
+// methods of the component with interface type T create spans. By default,
+// every call made as part of a trace creates a span in the caller and, if the
+// component is remote, in the callee. Under high load, a sampler can reduce
+// the cost of tracing frequent calls. For example, the following creates
+// spans for 1% of the calls to Catalog.GetProduct:
+//
+//	func init() {
+//	    sample := weaver.SampleFraction(0.01)
+//	    weaver.SampleTraces[Catalog](func(info weaver.CallInfo) bool {
+//	        return info.Method != "GetProduct" || sample(info)
+//	    })
+//	}
+//
+// A call that isn't sampled still propagates the trace, so the spans of the
+// calls it makes are attached to the span of its caller. A call that fails
+// always creates a span, even if it isn't sampled. SampleTraces should be
+// called in an init function, so that every process that calls the component
+// registers the same sampler.
+func SampleTraces[T any](sampler TraceSampler) {
+	codegen.RegisterTraceSampler(reflection.ComponentName[T](), sampler)
+}
+
+// SampleFraction returns a TraceSampler that samples the provided fraction of
+// calls, chosen at random.
+func SampleFraction(fraction float64) TraceSampler {
+	return func(CallInfo) bool {
+		return rand.Float64() < fraction
+	}
+}
+
 // AutoMarshal is a type that can be embedded within a struct to indicate that
 // "weaver generate" should generate serialization methods for the struct.
 //
@@ -560,7 +1141,9 @@ var _ Unrouted = (*implementsImpl)(nil)
 //	}
 //
 // The AutoMarshal embedding instructs "weaver generate" to generate
-// serialization methods for the struct, Pair in this example.
+// serialization methods for the struct, Pair in this example. It also
+// generates a DebugString method that renders the serialized fields of the
+// struct as JSON, e.g., {"x":1,"y":2}, unless the struct already has one.
 //
 // Note, however, that AutoMarshal cannot magically make any type serializable.
 // For example, "weaver generate" will raise an error for the following code
@@ -582,3 +1165,111 @@ func (AutoMarshal) WeaverMarshal(*codegen.Encoder)   {}
 func (AutoMarshal) WeaverUnmarshal(*codegen.Decoder) {}
 
 type NotRetriable interface{}
+
+// Retryable returns an error that wraps err and marks it as safe to retry. When
+// a component method called remotely returns a retryable error, the caller
+// retries the call with exponential backoff, a bounded number of times, even
+// if the method is NotRetriable. For example:
+//
+//	func (c *cache) Get(ctx context.Context, key string) (string, error) {
+//	    if c.loading.Load() {
+//	        // The cache is still warming up; try again shortly.
+//	        return "", weaver.Retryable(errLoading)
+//	    }
+//	    ...
+//	}
+//
+// If every attempt fails, the caller receives the error returned by the last
+// attempt; errors.Is and errors.As see through the wrapper. Calls to a
+// component in the same process are not retried. Retryable returns nil if err
+// is nil.
+func Retryable(err error) error {
+	return codegen.Retryable(err)
+}
+
+// CodedError is an error that carries an application-defined code, in
+// addition to its message. A CodedError is created with WithCode, and its code
+// is preserved when it is returned by a component method called remotely, so
+// that callers can branch on the code rather than on the error message:
+//
+//	var coded weaver.CodedError
+//	if errors.As(err, &coded) && coded.Code() == codeNotFound {
+//	    ...
+//	}
+type CodedError = codegen.CodedError
+
+// WithCode returns an error that wraps err and carries the provided code. For
+// example:
+//
+//	func (c *catalog) Get(ctx context.Context, id string) (Product, error) {
+//	    p, ok := c.products[id]
+//	    if !ok {
+//	        return Product{}, weaver.WithCode(codeNotFound, fmt.Errorf("product %q not found", id))
+//	    }
+//	    return p, nil
+//	}
+//
+// errors.Is and errors.As see through the wrapper. WithCode returns nil if err
+// is nil.
+func WithCode(code int32, err error) error {
+	return codegen.WithCode(code, err)
+}
+
+// BypassCache returns a new context that makes a call to a component method
+// skip the client side cache of the method's replies, if any. The reply of the
+// call is still cached for subsequent calls. The replies of a method marked
+// //weaver:cacheable are cached by the callers if the method is listed in the
+// "[caching]" section of the config file:
+//
+//	[caching]
+//	"github.com/example/currency/Currency" = {GetSupportedCurrencies = "5m"}
+func BypassCache(ctx context.Context) context.Context {
+	return codegen.BypassCache(ctx)
+}
+
+// WithSliceHint returns a new context that lets a component method call made
+// with it decode a slice result into s, rather than into a newly allocated
+// slice, if s has enough capacity. This saves an allocation per call for
+// callers that repeatedly receive a slice, e.g., by passing the slice returned
+// by the previous call:
+//
+//	var products []catalog.Product
+//	for ... {
+//	    products, err = client.ListProducts(weaver.WithSliceHint(ctx, products))
+//	    ...
+//	}
+//
+// The contents of s are overwritten, so the caller must not use s once the
+// call is made, and the returned context must not be used for concurrent
+// calls. Local method calls ignore the hint.
+func WithSliceHint[T any](ctx context.Context, s []T) context.Context {
+	return codegen.WithSliceHint(ctx, s)
+}
+
+// WithMetadata returns a new context that carries the provided metadata, in
+// addition to any metadata already in ctx. Values in meta replace existing
+// values with the same key. For example:
+//
+//	ctx = weaver.WithMetadata(ctx, map[string]string{"request_id": id})
+//	ctx = weaver.WithMetadata(ctx, map[string]string{"tenant_id": tenant})
+//
+// The metadata is sent along with every component method call made with the
+// returned context, and is available to the callee through
+// MetadataFromContext, whether or not the callee runs in the same process.
+// Because the callee's context carries the metadata, it is propagated through
+// arbitrarily deep call chains.
+func WithMetadata(ctx context.Context, meta map[string]string) context.Context {
+	merged, _ := metadata.FromContext(ctx)
+	if merged == nil {
+		merged = make(map[string]string, len(meta))
+	}
+	maps.Copy(merged, meta)
+	return metadata.NewContext(ctx, merged)
+}
+
+// MetadataFromContext returns a copy of the metadata carried by ctx (see
+// WithMetadata), or nil if there is none.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	meta, _ := metadata.FromContext(ctx)
+	return meta
+}
@@ -85,7 +85,7 @@ func (s deployerControl_local_stub) ActivateComponent(ctx context.Context, a0 *p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.ActivateComponent", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "ActivateComponent", "weaver.deployerControl.ActivateComponent", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -95,6 +95,13 @@ func (s deployerControl_local_stub) ActivateComponent(ctx context.Context, a0 *p
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "ActivateComponent", func(ctx context.Context) (err error) {
+			r0, err = s.impl.ActivateComponent(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.ActivateComponent(ctx, a0)
 }
 
@@ -105,7 +112,7 @@ func (s deployerControl_local_stub) ExportListener(ctx context.Context, a0 *prot
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.ExportListener", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "ExportListener", "weaver.deployerControl.ExportListener", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -115,6 +122,13 @@ func (s deployerControl_local_stub) ExportListener(ctx context.Context, a0 *prot
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "ExportListener", func(ctx context.Context) (err error) {
+			r0, err = s.impl.ExportListener(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.ExportListener(ctx, a0)
 }
 
@@ -125,7 +139,7 @@ func (s deployerControl_local_stub) GetListenerAddress(ctx context.Context, a0 *
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.GetListenerAddress", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "GetListenerAddress", "weaver.deployerControl.GetListenerAddress", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -135,6 +149,13 @@ func (s deployerControl_local_stub) GetListenerAddress(ctx context.Context, a0 *
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "GetListenerAddress", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetListenerAddress(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetListenerAddress(ctx, a0)
 }
 
@@ -145,7 +166,7 @@ func (s deployerControl_local_stub) GetSelfCertificate(ctx context.Context, a0 *
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.GetSelfCertificate", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "GetSelfCertificate", "weaver.deployerControl.GetSelfCertificate", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -155,6 +176,13 @@ func (s deployerControl_local_stub) GetSelfCertificate(ctx context.Context, a0 *
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "GetSelfCertificate", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetSelfCertificate(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetSelfCertificate(ctx, a0)
 }
 
@@ -165,7 +193,7 @@ func (s deployerControl_local_stub) HandleTraceSpans(ctx context.Context, a0 *pr
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.HandleTraceSpans", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "HandleTraceSpans", "weaver.deployerControl.HandleTraceSpans", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -175,6 +203,13 @@ func (s deployerControl_local_stub) HandleTraceSpans(ctx context.Context, a0 *pr
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "HandleTraceSpans", func(ctx context.Context) (err error) {
+			err = s.impl.HandleTraceSpans(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.HandleTraceSpans(ctx, a0)
 }
 
@@ -185,7 +220,7 @@ func (s deployerControl_local_stub) LogBatch(ctx context.Context, a0 *protos.Log
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.LogBatch", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "LogBatch", "weaver.deployerControl.LogBatch", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -195,6 +230,13 @@ func (s deployerControl_local_stub) LogBatch(ctx context.Context, a0 *protos.Log
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "LogBatch", func(ctx context.Context) (err error) {
+			err = s.impl.LogBatch(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.LogBatch(ctx, a0)
 }
 
@@ -205,7 +247,7 @@ func (s deployerControl_local_stub) VerifyClientCertificate(ctx context.Context,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.VerifyClientCertificate", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "VerifyClientCertificate", "weaver.deployerControl.VerifyClientCertificate", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -215,6 +257,13 @@ func (s deployerControl_local_stub) VerifyClientCertificate(ctx context.Context,
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "VerifyClientCertificate", func(ctx context.Context) (err error) {
+			r0, err = s.impl.VerifyClientCertificate(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.VerifyClientCertificate(ctx, a0)
 }
 
@@ -225,7 +274,7 @@ func (s deployerControl_local_stub) VerifyServerCertificate(ctx context.Context,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.deployerControl.VerifyServerCertificate", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/deployerControl", "VerifyServerCertificate", "weaver.deployerControl.VerifyServerCertificate", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -235,6 +284,13 @@ func (s deployerControl_local_stub) VerifyServerCertificate(ctx context.Context,
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "VerifyServerCertificate", func(ctx context.Context) (err error) {
+			r0, err = s.impl.VerifyServerCertificate(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.VerifyServerCertificate(ctx, a0)
 }
 
@@ -260,7 +316,7 @@ func (s weaveletControl_local_stub) GetHealth(ctx context.Context, a0 *protos.Ge
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.GetHealth", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "GetHealth", "weaver.weaveletControl.GetHealth", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -270,6 +326,13 @@ func (s weaveletControl_local_stub) GetHealth(ctx context.Context, a0 *protos.Ge
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetHealth", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetHealth(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetHealth(ctx, a0)
 }
 
@@ -280,7 +343,7 @@ func (s weaveletControl_local_stub) GetLoad(ctx context.Context, a0 *protos.GetL
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.GetLoad", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "GetLoad", "weaver.weaveletControl.GetLoad", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -290,6 +353,13 @@ func (s weaveletControl_local_stub) GetLoad(ctx context.Context, a0 *protos.GetL
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetLoad", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetLoad(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetLoad(ctx, a0)
 }
 
@@ -300,7 +370,7 @@ func (s weaveletControl_local_stub) GetMetrics(ctx context.Context, a0 *protos.G
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.GetMetrics", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "GetMetrics", "weaver.weaveletControl.GetMetrics", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -310,6 +380,13 @@ func (s weaveletControl_local_stub) GetMetrics(ctx context.Context, a0 *protos.G
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetMetrics", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetMetrics(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetMetrics(ctx, a0)
 }
 
@@ -320,7 +397,7 @@ func (s weaveletControl_local_stub) GetProfile(ctx context.Context, a0 *protos.G
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.GetProfile", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "GetProfile", "weaver.weaveletControl.GetProfile", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -330,6 +407,13 @@ func (s weaveletControl_local_stub) GetProfile(ctx context.Context, a0 *protos.G
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetProfile", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetProfile(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetProfile(ctx, a0)
 }
 
@@ -340,7 +424,7 @@ func (s weaveletControl_local_stub) InitWeavelet(ctx context.Context, a0 *protos
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.InitWeavelet", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "InitWeavelet", "weaver.weaveletControl.InitWeavelet", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -350,6 +434,13 @@ func (s weaveletControl_local_stub) InitWeavelet(ctx context.Context, a0 *protos
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "InitWeavelet", func(ctx context.Context) (err error) {
+			r0, err = s.impl.InitWeavelet(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.InitWeavelet(ctx, a0)
 }
 
@@ -360,7 +451,7 @@ func (s weaveletControl_local_stub) UpdateComponents(ctx context.Context, a0 *pr
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.UpdateComponents", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateComponents", "weaver.weaveletControl.UpdateComponents", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -370,6 +461,13 @@ func (s weaveletControl_local_stub) UpdateComponents(ctx context.Context, a0 *pr
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateComponents", func(ctx context.Context) (err error) {
+			r0, err = s.impl.UpdateComponents(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.UpdateComponents(ctx, a0)
 }
 
@@ -380,7 +478,7 @@ func (s weaveletControl_local_stub) UpdateRoutingInfo(ctx context.Context, a0 *p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "weaver.weaveletControl.UpdateRoutingInfo", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateRoutingInfo", "weaver.weaveletControl.UpdateRoutingInfo", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -390,6 +488,13 @@ func (s weaveletControl_local_stub) UpdateRoutingInfo(ctx context.Context, a0 *p
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateRoutingInfo", func(ctx context.Context) (err error) {
+			r0, err = s.impl.UpdateRoutingInfo(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.UpdateRoutingInfo(ctx, a0)
 }
 
@@ -419,7 +524,7 @@ func (s deployerControl_client_stub) ActivateComponent(ctx context.Context, a0 *
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.ActivateComponent", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "ActivateComponent", "weaver.deployerControl.ActivateComponent", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -427,6 +532,7 @@ func (s deployerControl_client_stub) ActivateComponent(ctx context.Context, a0 *
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.activateComponentMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -437,6 +543,8 @@ func (s deployerControl_client_stub) ActivateComponent(ctx context.Context, a0 *
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -444,21 +552,27 @@ func (s deployerControl_client_stub) ActivateComponent(ctx context.Context, a0 *
 	serviceweaver_enc_ptr_ActivateComponentRequest_73adf343(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_ActivateComponentReply_5e57d605(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_ActivateComponentReply_5e57d605(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) ExportListener(ctx context.Context, a0 *protos.ExportListenerRequest) (r0 *protos.ExportListenerReply, err error) {
@@ -470,7 +584,7 @@ func (s deployerControl_client_stub) ExportListener(ctx context.Context, a0 *pro
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.ExportListener", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "ExportListener", "weaver.deployerControl.ExportListener", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -478,6 +592,7 @@ func (s deployerControl_client_stub) ExportListener(ctx context.Context, a0 *pro
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.exportListenerMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -488,6 +603,8 @@ func (s deployerControl_client_stub) ExportListener(ctx context.Context, a0 *pro
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -495,21 +612,27 @@ func (s deployerControl_client_stub) ExportListener(ctx context.Context, a0 *pro
 	serviceweaver_enc_ptr_ExportListenerRequest_b494514e(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_ExportListenerReply_b0fc34d0(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_ExportListenerReply_b0fc34d0(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) GetListenerAddress(ctx context.Context, a0 *protos.GetListenerAddressRequest) (r0 *protos.GetListenerAddressReply, err error) {
@@ -521,7 +644,7 @@ func (s deployerControl_client_stub) GetListenerAddress(ctx context.Context, a0
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.GetListenerAddress", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "GetListenerAddress", "weaver.deployerControl.GetListenerAddress", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -529,6 +652,7 @@ func (s deployerControl_client_stub) GetListenerAddress(ctx context.Context, a0
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getListenerAddressMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -539,6 +663,8 @@ func (s deployerControl_client_stub) GetListenerAddress(ctx context.Context, a0
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 2, begin, err)
 	}()
 
 	// Encode arguments.
@@ -546,21 +672,27 @@ func (s deployerControl_client_stub) GetListenerAddress(ctx context.Context, a0
 	serviceweaver_enc_ptr_GetListenerAddressRequest_5a58feb0(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 2, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_GetListenerAddressReply_8bfe2caa(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_GetListenerAddressReply_8bfe2caa(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) GetSelfCertificate(ctx context.Context, a0 *protos.GetSelfCertificateRequest) (r0 *protos.GetSelfCertificateReply, err error) {
@@ -572,7 +704,7 @@ func (s deployerControl_client_stub) GetSelfCertificate(ctx context.Context, a0
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.GetSelfCertificate", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "GetSelfCertificate", "weaver.deployerControl.GetSelfCertificate", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -580,6 +712,7 @@ func (s deployerControl_client_stub) GetSelfCertificate(ctx context.Context, a0
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getSelfCertificateMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -590,6 +723,8 @@ func (s deployerControl_client_stub) GetSelfCertificate(ctx context.Context, a0
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 3, begin, err)
 	}()
 
 	// Encode arguments.
@@ -597,21 +732,27 @@ func (s deployerControl_client_stub) GetSelfCertificate(ctx context.Context, a0
 	serviceweaver_enc_ptr_GetSelfCertificateRequest_0de4e3b4(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 3, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_GetSelfCertificateReply_12277ec8(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_GetSelfCertificateReply_12277ec8(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) HandleTraceSpans(ctx context.Context, a0 *protos.TraceSpans) (err error) {
@@ -623,7 +764,7 @@ func (s deployerControl_client_stub) HandleTraceSpans(ctx context.Context, a0 *p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.HandleTraceSpans", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "HandleTraceSpans", "weaver.deployerControl.HandleTraceSpans", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -631,6 +772,7 @@ func (s deployerControl_client_stub) HandleTraceSpans(ctx context.Context, a0 *p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.handleTraceSpansMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -641,6 +783,8 @@ func (s deployerControl_client_stub) HandleTraceSpans(ctx context.Context, a0 *p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 4, begin, err)
 	}()
 
 	// Encode arguments.
@@ -648,20 +792,26 @@ func (s deployerControl_client_stub) HandleTraceSpans(ctx context.Context, a0 *p
 	serviceweaver_enc_ptr_TraceSpans_af16efd0(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 4, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) LogBatch(ctx context.Context, a0 *protos.LogEntryBatch) (err error) {
@@ -673,7 +823,7 @@ func (s deployerControl_client_stub) LogBatch(ctx context.Context, a0 *protos.Lo
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.LogBatch", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "LogBatch", "weaver.deployerControl.LogBatch", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -681,6 +831,7 @@ func (s deployerControl_client_stub) LogBatch(ctx context.Context, a0 *protos.Lo
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.logBatchMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -691,6 +842,8 @@ func (s deployerControl_client_stub) LogBatch(ctx context.Context, a0 *protos.Lo
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 5, begin, err)
 	}()
 
 	// Encode arguments.
@@ -698,20 +851,26 @@ func (s deployerControl_client_stub) LogBatch(ctx context.Context, a0 *protos.Lo
 	serviceweaver_enc_ptr_LogEntryBatch_fec9a5d4(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 5, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 5, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) VerifyClientCertificate(ctx context.Context, a0 *protos.VerifyClientCertificateRequest) (r0 *protos.VerifyClientCertificateReply, err error) {
@@ -723,7 +882,7 @@ func (s deployerControl_client_stub) VerifyClientCertificate(ctx context.Context
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.VerifyClientCertificate", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "VerifyClientCertificate", "weaver.deployerControl.VerifyClientCertificate", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -731,6 +890,7 @@ func (s deployerControl_client_stub) VerifyClientCertificate(ctx context.Context
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.verifyClientCertificateMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -741,6 +901,8 @@ func (s deployerControl_client_stub) VerifyClientCertificate(ctx context.Context
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 6, begin, err)
 	}()
 
 	// Encode arguments.
@@ -748,21 +910,27 @@ func (s deployerControl_client_stub) VerifyClientCertificate(ctx context.Context
 	serviceweaver_enc_ptr_VerifyClientCertificateRequest_f8d21781(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 6, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 6, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_VerifyClientCertificateReply_c76e39ec(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_VerifyClientCertificateReply_c76e39ec(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s deployerControl_client_stub) VerifyServerCertificate(ctx context.Context, a0 *protos.VerifyServerCertificateRequest) (r0 *protos.VerifyServerCertificateReply, err error) {
@@ -774,7 +942,7 @@ func (s deployerControl_client_stub) VerifyServerCertificate(ctx context.Context
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.deployerControl.VerifyServerCertificate", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/deployerControl", "VerifyServerCertificate", "weaver.deployerControl.VerifyServerCertificate", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -782,6 +950,7 @@ func (s deployerControl_client_stub) VerifyServerCertificate(ctx context.Context
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.verifyServerCertificateMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -792,6 +961,8 @@ func (s deployerControl_client_stub) VerifyServerCertificate(ctx context.Context
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 7, begin, err)
 	}()
 
 	// Encode arguments.
@@ -799,21 +970,27 @@ func (s deployerControl_client_stub) VerifyServerCertificate(ctx context.Context
 	serviceweaver_enc_ptr_VerifyServerCertificateRequest_9c56ee67(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 7, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 7, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_VerifyServerCertificateReply_c0d4bd3b(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_VerifyServerCertificateReply_c0d4bd3b(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type weaveletControl_client_stub struct {
@@ -839,7 +1016,7 @@ func (s weaveletControl_client_stub) GetHealth(ctx context.Context, a0 *protos.G
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.GetHealth", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "GetHealth", "weaver.weaveletControl.GetHealth", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -847,6 +1024,7 @@ func (s weaveletControl_client_stub) GetHealth(ctx context.Context, a0 *protos.G
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getHealthMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -857,6 +1035,8 @@ func (s weaveletControl_client_stub) GetHealth(ctx context.Context, a0 *protos.G
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -864,21 +1044,27 @@ func (s weaveletControl_client_stub) GetHealth(ctx context.Context, a0 *protos.G
 	serviceweaver_enc_ptr_GetHealthRequest_fd6083fb(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_GetHealthReply_b2d11423(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_GetHealthReply_b2d11423(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s weaveletControl_client_stub) GetLoad(ctx context.Context, a0 *protos.GetLoadRequest) (r0 *protos.GetLoadReply, err error) {
@@ -890,7 +1076,7 @@ func (s weaveletControl_client_stub) GetLoad(ctx context.Context, a0 *protos.Get
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.GetLoad", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "GetLoad", "weaver.weaveletControl.GetLoad", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -898,6 +1084,7 @@ func (s weaveletControl_client_stub) GetLoad(ctx context.Context, a0 *protos.Get
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getLoadMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -908,6 +1095,8 @@ func (s weaveletControl_client_stub) GetLoad(ctx context.Context, a0 *protos.Get
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
 	// Encode arguments.
@@ -915,21 +1104,27 @@ func (s weaveletControl_client_stub) GetLoad(ctx context.Context, a0 *protos.Get
 	serviceweaver_enc_ptr_GetLoadRequest_d733b2cf(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_GetLoadReply_cf8279ad(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_GetLoadReply_cf8279ad(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s weaveletControl_client_stub) GetMetrics(ctx context.Context, a0 *protos.GetMetricsRequest) (r0 *protos.GetMetricsReply, err error) {
@@ -941,7 +1136,7 @@ func (s weaveletControl_client_stub) GetMetrics(ctx context.Context, a0 *protos.
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.GetMetrics", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "GetMetrics", "weaver.weaveletControl.GetMetrics", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -949,6 +1144,7 @@ func (s weaveletControl_client_stub) GetMetrics(ctx context.Context, a0 *protos.
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getMetricsMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -959,6 +1155,8 @@ func (s weaveletControl_client_stub) GetMetrics(ctx context.Context, a0 *protos.
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 2, begin, err)
 	}()
 
 	// Encode arguments.
@@ -966,21 +1164,27 @@ func (s weaveletControl_client_stub) GetMetrics(ctx context.Context, a0 *protos.
 	serviceweaver_enc_ptr_GetMetricsRequest_010b3cd9(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 2, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_GetMetricsReply_3c7180e4(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_GetMetricsReply_3c7180e4(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s weaveletControl_client_stub) GetProfile(ctx context.Context, a0 *protos.GetProfileRequest) (r0 *protos.GetProfileReply, err error) {
@@ -992,7 +1196,7 @@ func (s weaveletControl_client_stub) GetProfile(ctx context.Context, a0 *protos.
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.GetProfile", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "GetProfile", "weaver.weaveletControl.GetProfile", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1000,6 +1204,7 @@ func (s weaveletControl_client_stub) GetProfile(ctx context.Context, a0 *protos.
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getProfileMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -1010,6 +1215,8 @@ func (s weaveletControl_client_stub) GetProfile(ctx context.Context, a0 *protos.
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 3, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1017,21 +1224,27 @@ func (s weaveletControl_client_stub) GetProfile(ctx context.Context, a0 *protos.
 	serviceweaver_enc_ptr_GetProfileRequest_d1544fcf(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 3, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_GetProfileReply_10a79dcc(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_GetProfileReply_10a79dcc(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s weaveletControl_client_stub) InitWeavelet(ctx context.Context, a0 *protos.InitWeaveletRequest) (r0 *protos.InitWeaveletReply, err error) {
@@ -1043,7 +1256,7 @@ func (s weaveletControl_client_stub) InitWeavelet(ctx context.Context, a0 *proto
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.InitWeavelet", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "InitWeavelet", "weaver.weaveletControl.InitWeavelet", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1051,6 +1264,7 @@ func (s weaveletControl_client_stub) InitWeavelet(ctx context.Context, a0 *proto
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.initWeaveletMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -1061,6 +1275,8 @@ func (s weaveletControl_client_stub) InitWeavelet(ctx context.Context, a0 *proto
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 4, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1068,21 +1284,27 @@ func (s weaveletControl_client_stub) InitWeavelet(ctx context.Context, a0 *proto
 	serviceweaver_enc_ptr_InitWeaveletRequest_d1f5204c(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 4, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_InitWeaveletReply_565d8c96(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_InitWeaveletReply_565d8c96(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s weaveletControl_client_stub) UpdateComponents(ctx context.Context, a0 *protos.UpdateComponentsRequest) (r0 *protos.UpdateComponentsReply, err error) {
@@ -1094,7 +1316,7 @@ func (s weaveletControl_client_stub) UpdateComponents(ctx context.Context, a0 *p
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.UpdateComponents", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateComponents", "weaver.weaveletControl.UpdateComponents", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1102,6 +1324,7 @@ func (s weaveletControl_client_stub) UpdateComponents(ctx context.Context, a0 *p
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.updateComponentsMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -1112,6 +1335,8 @@ func (s weaveletControl_client_stub) UpdateComponents(ctx context.Context, a0 *p
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 5, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1119,21 +1344,27 @@ func (s weaveletControl_client_stub) UpdateComponents(ctx context.Context, a0 *p
 	serviceweaver_enc_ptr_UpdateComponentsRequest_d1b56e1f(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 5, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 5, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_UpdateComponentsReply_93bebb77(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_UpdateComponentsReply_93bebb77(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s weaveletControl_client_stub) UpdateRoutingInfo(ctx context.Context, a0 *protos.UpdateRoutingInfoRequest) (r0 *protos.UpdateRoutingInfoReply, err error) {
@@ -1145,7 +1376,7 @@ func (s weaveletControl_client_stub) UpdateRoutingInfo(ctx context.Context, a0 *
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "weaver.weaveletControl.UpdateRoutingInfo", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateRoutingInfo", "weaver.weaveletControl.UpdateRoutingInfo", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -1153,6 +1384,7 @@ func (s weaveletControl_client_stub) UpdateRoutingInfo(ctx context.Context, a0 *
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.updateRoutingInfoMetrics.SerializationError()
 				err = errors.Join(RemoteCallError, err)
 			}
 		}
@@ -1163,6 +1395,8 @@ func (s weaveletControl_client_stub) UpdateRoutingInfo(ctx context.Context, a0 *
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 6, begin, err)
 	}()
 
 	// Encode arguments.
@@ -1170,30 +1404,36 @@ func (s weaveletControl_client_stub) UpdateRoutingInfo(ctx context.Context, a0 *
 	serviceweaver_enc_ptr_UpdateRoutingInfoRequest_e752cfad(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 6, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 6, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_UpdateRoutingInfoReply_d1854fd5(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_UpdateRoutingInfoReply_d1854fd5(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -1245,22 +1485,41 @@ func (s deployerControl_server_stub) GetStubFn(method string) func(ctx context.C
 }
 
 func (s deployerControl_server_stub) activateComponent(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.ActivateComponentRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "ActivateComponent", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.ActivateComponentRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_ActivateComponentRequest_73adf343(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.ActivateComponent(ctx, a0)
+	var r0 *protos.ActivateComponentReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "ActivateComponent", func(ctx context.Context) (err error) {
+			r0, err = s.impl.ActivateComponent(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.ActivateComponent(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1270,22 +1529,41 @@ func (s deployerControl_server_stub) activateComponent(ctx context.Context, args
 }
 
 func (s deployerControl_server_stub) exportListener(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.ExportListenerRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "ExportListener", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.ExportListenerRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_ExportListenerRequest_b494514e(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.ExportListener(ctx, a0)
+	var r0 *protos.ExportListenerReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "ExportListener", func(ctx context.Context) (err error) {
+			r0, err = s.impl.ExportListener(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.ExportListener(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1295,22 +1573,41 @@ func (s deployerControl_server_stub) exportListener(ctx context.Context, args []
 }
 
 func (s deployerControl_server_stub) getListenerAddress(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.GetListenerAddressRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "GetListenerAddress", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.GetListenerAddressRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_GetListenerAddressRequest_5a58feb0(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetListenerAddress(ctx, a0)
+	var r0 *protos.GetListenerAddressReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "GetListenerAddress", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetListenerAddress(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetListenerAddress(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1320,22 +1617,41 @@ func (s deployerControl_server_stub) getListenerAddress(ctx context.Context, arg
 }
 
 func (s deployerControl_server_stub) getSelfCertificate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.GetSelfCertificateRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "GetSelfCertificate", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.GetSelfCertificateRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_GetSelfCertificateRequest_0de4e3b4(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetSelfCertificate(ctx, a0)
+	var r0 *protos.GetSelfCertificateReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "GetSelfCertificate", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetSelfCertificate(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetSelfCertificate(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1345,22 +1661,40 @@ func (s deployerControl_server_stub) getSelfCertificate(ctx context.Context, arg
 }
 
 func (s deployerControl_server_stub) handleTraceSpans(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.TraceSpans
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "HandleTraceSpans", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.TraceSpans
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_TraceSpans_af16efd0(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.HandleTraceSpans(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "HandleTraceSpans", func(ctx context.Context) (err error) {
+			err = s.impl.HandleTraceSpans(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.HandleTraceSpans(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1369,22 +1703,40 @@ func (s deployerControl_server_stub) handleTraceSpans(ctx context.Context, args
 }
 
 func (s deployerControl_server_stub) logBatch(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.LogEntryBatch
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "LogBatch", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.LogEntryBatch
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_LogEntryBatch_fec9a5d4(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.LogBatch(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "LogBatch", func(ctx context.Context) (err error) {
+			err = s.impl.LogBatch(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.LogBatch(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1393,22 +1745,41 @@ func (s deployerControl_server_stub) logBatch(ctx context.Context, args []byte)
 }
 
 func (s deployerControl_server_stub) verifyClientCertificate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.VerifyClientCertificateRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "VerifyClientCertificate", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.VerifyClientCertificateRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_VerifyClientCertificateRequest_f8d21781(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.VerifyClientCertificate(ctx, a0)
+	var r0 *protos.VerifyClientCertificateReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "VerifyClientCertificate", func(ctx context.Context) (err error) {
+			r0, err = s.impl.VerifyClientCertificate(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.VerifyClientCertificate(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1418,22 +1789,41 @@ func (s deployerControl_server_stub) verifyClientCertificate(ctx context.Context
 }
 
 func (s deployerControl_server_stub) verifyServerCertificate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.VerifyServerCertificateRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/deployerControl", "VerifyServerCertificate", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.VerifyServerCertificateRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_VerifyServerCertificateRequest_9c56ee67(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.VerifyServerCertificate(ctx, a0)
+	var r0 *protos.VerifyServerCertificateReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/deployerControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/deployerControl", "VerifyServerCertificate", func(ctx context.Context) (err error) {
+			r0, err = s.impl.VerifyServerCertificate(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.VerifyServerCertificate(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1473,22 +1863,41 @@ func (s weaveletControl_server_stub) GetStubFn(method string) func(ctx context.C
 }
 
 func (s weaveletControl_server_stub) getHealth(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.GetHealthRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "GetHealth", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.GetHealthRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_GetHealthRequest_fd6083fb(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetHealth(ctx, a0)
+	var r0 *protos.GetHealthReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetHealth", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetHealth(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetHealth(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1498,22 +1907,41 @@ func (s weaveletControl_server_stub) getHealth(ctx context.Context, args []byte)
 }
 
 func (s weaveletControl_server_stub) getLoad(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.GetLoadRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "GetLoad", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.GetLoadRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_GetLoadRequest_d733b2cf(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetLoad(ctx, a0)
+	var r0 *protos.GetLoadReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetLoad", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetLoad(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetLoad(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1523,22 +1951,41 @@ func (s weaveletControl_server_stub) getLoad(ctx context.Context, args []byte) (
 }
 
 func (s weaveletControl_server_stub) getMetrics(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.GetMetricsRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "GetMetrics", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.GetMetricsRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_GetMetricsRequest_010b3cd9(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetMetrics(ctx, a0)
+	var r0 *protos.GetMetricsReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetMetrics", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetMetrics(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetMetrics(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1548,22 +1995,41 @@ func (s weaveletControl_server_stub) getMetrics(ctx context.Context, args []byte
 }
 
 func (s weaveletControl_server_stub) getProfile(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.GetProfileRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "GetProfile", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.GetProfileRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_GetProfileRequest_d1544fcf(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetProfile(ctx, a0)
+	var r0 *protos.GetProfileReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "GetProfile", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetProfile(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetProfile(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1573,22 +2039,41 @@ func (s weaveletControl_server_stub) getProfile(ctx context.Context, args []byte
 }
 
 func (s weaveletControl_server_stub) initWeavelet(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.InitWeaveletRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "InitWeavelet", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.InitWeaveletRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_InitWeaveletRequest_d1f5204c(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.InitWeavelet(ctx, a0)
+	var r0 *protos.InitWeaveletReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "InitWeavelet", func(ctx context.Context) (err error) {
+			r0, err = s.impl.InitWeavelet(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.InitWeavelet(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1598,22 +2083,41 @@ func (s weaveletControl_server_stub) initWeavelet(ctx context.Context, args []by
 }
 
 func (s weaveletControl_server_stub) updateComponents(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.UpdateComponentsRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "UpdateComponents", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.UpdateComponentsRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_UpdateComponentsRequest_d1b56e1f(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.UpdateComponents(ctx, a0)
+	var r0 *protos.UpdateComponentsReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateComponents", func(ctx context.Context) (err error) {
+			r0, err = s.impl.UpdateComponents(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.UpdateComponents(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1623,22 +2127,41 @@ func (s weaveletControl_server_stub) updateComponents(ctx context.Context, args
 }
 
 func (s weaveletControl_server_stub) updateRoutingInfo(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *protos.UpdateRoutingInfoRequest
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weaveletControl", "UpdateRoutingInfo", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *protos.UpdateRoutingInfoRequest
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_UpdateRoutingInfoRequest_e752cfad(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.UpdateRoutingInfo(ctx, a0)
+	var r0 *protos.UpdateRoutingInfoReply
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weaveletControl") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weaveletControl", "UpdateRoutingInfo", func(ctx context.Context) (err error) {
+			r0, err = s.impl.UpdateRoutingInfo(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.UpdateRoutingInfo(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
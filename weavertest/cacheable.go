@@ -0,0 +1,112 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weavertest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+)
+
+// CheckCacheable checks that a method of component T marked
+// //weaver:cacheable is idempotent. It calls the method twice, using call, and
+// fails t if the two calls return different results or errors, or if either
+// call invokes a method of another component that is not marked
+// //weaver:cacheable or //weaver:readonly.
+//
+// The check runs T with the Local runner. The components that T depends on
+// are replaced by spies that record the methods invoked on them and return
+// zero values, so the method under test sees the same dependencies on both
+// calls.
+//
+// "weaver generate" generates a test that calls CheckCacheable for every
+// method marked //weaver:cacheable, in a weaver_gen_test.go file.
+func CheckCacheable[T any](t *testing.T, method string, call func(context.Context, T) ([]any, error)) {
+	t.Helper()
+	name := reflection.ComponentName[T]()
+	reg, ok := codegen.Find(name)
+	if !ok {
+		t.Fatalf("component %s not found", name)
+	}
+
+	// Replace every dependency of T with a spy.
+	s := &spy{}
+	var fakes []FakeComponent
+	for _, edge := range codegen.ExtractEdges([]byte(reg.RefData)) {
+		dep, ok := codegen.Find(edge[1])
+		if !ok {
+			t.Fatalf("component %s not found", edge[1])
+		}
+		fakes = append(fakes, FakeComponent{intf: dep.Iface, impl: dep.ReflectStubFn(s.caller(dep))})
+	}
+
+	runner := Local
+	runner.Fakes = fakes
+	runner.Test(t, func(t *testing.T, comp T) {
+		ctx := context.Background()
+		r1, err1 := call(ctx, comp)
+		r2, err2 := call(ctx, comp)
+		if !reflect.DeepEqual(r1, r2) {
+			t.Errorf("%s: calls returned different results: %v != %v", method, r1, r2)
+		}
+		if fmt.Sprint(err1) != fmt.Sprint(err2) {
+			t.Errorf("%s: calls returned different errors: %v != %v", method, err1, err2)
+		}
+		for _, m := range s.mutations() {
+			t.Errorf("%s: called %s, which is not marked //weaver:cacheable or //weaver:readonly", method, m)
+		}
+	})
+}
+
+// spy records the mutating methods invoked on the dependencies of a component.
+type spy struct {
+	mu      sync.Mutex
+	invoked []string // mutating methods invoked, as "Component.Method"
+}
+
+// caller returns a function, to be passed to the ReflectStubFn of the provided
+// component, that records the invocations of its mutating methods and returns
+// zero values.
+func (s *spy) caller(reg *codegen.Registration) func(string, context.Context, []any, []any) error {
+	reads := map[string]bool{}
+	for _, i := range reg.ReadOnly {
+		reads[reg.Iface.Method(i).Name] = true
+	}
+	for _, i := range reg.Cacheable {
+		reads[reg.Iface.Method(i).Name] = true
+	}
+	component := logging.ShortenComponent(reg.Name)
+	return func(method string, _ context.Context, _, _ []any) error {
+		if !reads[method] {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.invoked = append(s.invoked, component+"."+method)
+		}
+		return nil
+	}
+}
+
+// mutations returns the mutating methods invoked so far.
+func (s *spy) mutations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.invoked...)
+}
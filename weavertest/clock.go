@@ -0,0 +1,64 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weavertest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock that only moves forward when advanced explicitly. It is
+// typically placed in Runner.Clock to make tests of time-dependent code
+// deterministic. For example:
+//
+//	clock := weavertest.NewFakeClock(time.Unix(0, 0))
+//	runner := weavertest.Local
+//	runner.Clock = clock
+//	runner.Test(t, func(t *testing.T, cache Cache) {
+//	    cache.Put(ctx, "key", "value")
+//	    clock.Advance(time.Hour)
+//	    // The entry should now be expired ...
+//	})
+//
+// A FakeClock is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a new fake clock whose current time is now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+//
+// REQUIRES: d >= 0
+func (c *FakeClock) Advance(d time.Duration) {
+	if d < 0 {
+		panic(fmt.Sprintf("FakeClock.Advance: negative duration %v", d))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -165,7 +165,11 @@ func (d *deployer) start(opts weaver.RemoteWeaveletOptions) (*weaver.RemoteWeave
 	// NOTE: NewEnvelope initiates a blocking handshake with the weavelet
 	// and therefore we run the rest of the initialization in a goroutine which
 	// will wait for weaver.Run to create a weavelet.
-	go func() {
+	//
+	// The goroutine is added to d.running before start returns, and hence
+	// before cleanup can wait on d.running, so that the goroutines it adds to
+	// d.running are never added while cleanup is waiting.
+	d.running.Go(func() error {
 		e, err := envelope.NewEnvelope(d.ctx, wlet, d.config, envelope.Options{
 			TmpDir: d.tmpDir,
 			Logger: d.sysLogger,
@@ -173,14 +177,14 @@ func (d *deployer) start(opts weaver.RemoteWeaveletOptions) (*weaver.RemoteWeave
 		})
 		if err != nil {
 			d.stop(err)
-			return
+			return nil
 		}
 
 		// Get weavelet info when it is ready.
 		w := <-wchan
 		if w.err != nil {
 			d.stop(err)
-			return
+			return nil
 		}
 
 		d.mu.Lock()
@@ -201,7 +205,8 @@ func (d *deployer) start(opts weaver.RemoteWeaveletOptions) (*weaver.RemoteWeave
 		if err := d.registerReplica(g, e.WeaveletAddress()); err != nil {
 			d.stopLocked(fmt.Errorf(`cannot register the replica for "main": %w`, err))
 		}
-	}()
+		return nil
+	})
 
 	w := <-wchan
 	return w.weavelet, w.err
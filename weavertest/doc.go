@@ -33,7 +33,7 @@
 //
 // Multiple Runners are provided by weavertest. The runners differ in
 // whether or not components are placed in different processes and
-// whether or not RPCs are used for components in the same process.
+// whether or not calls to components in the same process are serialized.
 //
 //  1. Local: all components are placed in a single process and method
 //     invocations are local procedure calls. This is similar to what
@@ -48,6 +48,12 @@
 //     method calls will use RPCs. This mode is most useful when
 //     profiling or collecting coverage information.
 //
+//  4. SerializedLocal: all components are placed in a single process and
+//     method invocations are local procedure calls, but the arguments and
+//     results of every call are encoded and decoded, in memory, as they
+//     would be for an RPC. This mode catches serialization bugs without
+//     the cost of RPCs.
+//
 // Example:
 //
 //	func TestReverseSingle(t *testing.T) {
@@ -0,0 +1,193 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weavertest
+
+import (
+	"encoding"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/internal/reflection"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+)
+
+// maxFuzzDepth is the depth past which random values of recursive types have
+// nil pointers, slices, and maps.
+const maxFuzzDepth = 4
+
+// FuzzRoundTrip is a fuzz target that checks that AutoMarshal type T survives
+// an encoding round trip. For every input, it generates a random value of
+// type T, encodes it with WeaverMarshal, decodes it with WeaverUnmarshal, and
+// fails if the decoded value is not deeply equal to the original one. This
+// catches asymmetries between the generated encoder and decoder, like a nil
+// slice that is decoded as an empty one.
+//
+// The random values fill every field of T, exported or not, except for fields
+// of interface types, of types that encode themselves with
+// encoding.BinaryMarshaler or protocol buffers, and of struct types declared
+// in other packages, which are left zero. Integer types with an IsValid
+// method, like the enums marked //weaver:enum, only get valid values.
+//
+// "weaver generate -fuzz" generates a fuzz test that calls FuzzRoundTrip for
+// every AutoMarshal type, in a weaver_gen_fuzz_test.go file.
+func FuzzRoundTrip[T any, P interface {
+	*T
+	codegen.AutoMarshal
+}](f *testing.F) {
+	f.Helper()
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(seed)
+	}
+	typ := reflection.Type[T]()
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		var want T
+		fillRandom(reflect.ValueOf(&want).Elem(), r, typ.PkgPath(), 0)
+
+		enc := codegen.NewEncoder()
+		P(&want).WeaverMarshal(enc)
+		var got T
+		dec := codegen.NewDecoder(enc.Data())
+		if err := decodeInto(P(&got), dec); err != nil {
+			t.Fatalf("%v: decode %+v: %v", typ, want, err)
+		}
+		if !dec.Empty() {
+			t.Fatalf("%v: decode %+v: trailing bytes", typ, want)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("%v: round trip changed the value:\nencoded %+v\ndecoded %+v", typ, want, got)
+		}
+	})
+}
+
+// decodeInto decodes dst from dec, returning the decoding error, if any.
+func decodeInto(dst codegen.AutoMarshal, dec *codegen.Decoder) (err error) {
+	defer func() { err = codegen.CatchPanics(recover()) }()
+	dst.WeaverUnmarshal(dec)
+	return nil
+}
+
+// fillRandom fills v, a settable value, with random contents. pkg is the
+// package of the type under test, and depth is the nesting depth of v.
+func fillRandom(v reflect.Value, r *rand.Rand, pkg string, depth int) {
+	t := v.Type()
+	if leaveZero(t, pkg) {
+		return
+	}
+	if m, ok := reflect.PointerTo(t).MethodByName("IsValid"); ok && isInteger(t.Kind()) &&
+		m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Bool {
+		// An enum. Search for a valid value, keeping the zero value if
+		// none is found.
+		for i := 0; i < 100; i++ {
+			x := reflect.New(t)
+			if t.Kind() >= reflect.Uint {
+				x.Elem().SetUint(uint64(r.Intn(64)))
+			} else {
+				x.Elem().SetInt(int64(r.Intn(64)))
+			}
+			if x.MethodByName("IsValid").Call(nil)[0].Bool() {
+				v.Set(x.Elem())
+				return
+			}
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(r.Int63() >> (64 - t.Bits()))
+		if r.Intn(2) == 0 {
+			v.SetInt(-v.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(r.Uint64() >> (64 - t.Bits()))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(r.NormFloat64() * 1e6)
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(r.NormFloat64(), r.NormFloat64()))
+	case reflect.String:
+		b := make([]byte, r.Intn(16))
+		r.Read(b)
+		v.SetString(string(b))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillRandom(v.Index(i), r, pkg, depth+1)
+		}
+	case reflect.Slice:
+		if depth >= maxFuzzDepth || r.Intn(4) == 0 {
+			return // nil
+		}
+		s := reflect.MakeSlice(t, r.Intn(4), r.Intn(4)+4)
+		for i := 0; i < s.Len(); i++ {
+			fillRandom(s.Index(i), r, pkg, depth+1)
+		}
+		v.Set(s)
+	case reflect.Map:
+		if depth >= maxFuzzDepth || r.Intn(4) == 0 {
+			return // nil
+		}
+		m := reflect.MakeMap(t)
+		for i, n := 0, r.Intn(4); i < n; i++ {
+			k, e := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+			fillRandom(k, r, pkg, depth+1)
+			fillRandom(e, r, pkg, depth+1)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	case reflect.Pointer:
+		if depth >= maxFuzzDepth || r.Intn(4) == 0 {
+			return // nil
+		}
+		p := reflect.New(t.Elem())
+		fillRandom(p.Elem(), r, pkg, depth+1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// Unexported fields are set through an addressable alias.
+			f := v.Field(i)
+			f = reflect.NewAt(f.Type(), f.Addr().UnsafePointer()).Elem()
+			fillRandom(f, r, pkg, depth+1)
+			if t.Field(i).Tag.Get("weaver") == "tail" && f.Len() == 0 {
+				// An empty tail is decoded as nil. See codegen.EncodeTail.
+				f.SetZero()
+			}
+		}
+	}
+}
+
+// leaveZero returns whether fillRandom leaves values of type t zero. See
+// FuzzRoundTrip.
+func leaveZero(t reflect.Type, pkg string) bool {
+	switch {
+	case t.Kind() == reflect.Interface:
+		return true
+	case t.Kind() == reflect.Struct && t.PkgPath() != "" && t.PkgPath() != pkg:
+		return true
+	}
+	p := reflect.PointerTo(t)
+	if p.Implements(reflection.Type[encoding.BinaryMarshaler]()) {
+		return true
+	}
+	_, ok := p.MethodByName("ProtoReflect")
+	return ok
+}
+
+// isInteger returns whether k is an integer kind.
+func isInteger(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Uintptr
+}
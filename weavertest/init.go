@@ -24,16 +24,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ServiceWeaver/weaver/internal/clock"
 	"github.com/ServiceWeaver/weaver/internal/reflection"
 	"github.com/ServiceWeaver/weaver/internal/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Runner runs user-supplied testing code as a weaver application.
 type Runner struct {
 	multi         bool // Use multiple processes
 	forceRPC      bool // Use RPCs even for local calls
+	serialize     bool // Serialize local calls in memory
 	injectRetries int  // Number of fake retries to add to remote retriable calls
 
 	// Name is used as the name of the sub-test created by
@@ -53,6 +56,19 @@ type Runner struct {
 	// The typical use is to override some subset of the application
 	// code being tested with test-specific component implementations.
 	Fakes []FakeComponent
+
+	// Faults holds a list of faults to inject into remote method calls. The
+	// typical use is to exercise the error handling paths of the application
+	// code being tested. See InjectFault.
+	Faults []Fault
+
+	// Clock, if not nil, replaces the system clock used by the Service Weaver
+	// runtime, e.g., to measure method latencies, and returned by the Clock
+	// method of weaver.Implements. The clock is shared by all components in
+	// the test binary, so tests that set Clock must not run in parallel with
+	// other weavertest tests. Clock is not supported by the Multi runner,
+	// whose components run in separate processes.
+	Clock *FakeClock
 }
 
 var (
@@ -60,6 +76,14 @@ var (
 	// and uses local procedure calls for method invocations.
 	Local = Runner{Name: "Local"}
 
+	// SerializedLocal is a Runner that places all components in the same
+	// process and uses local procedure calls for method invocations, but
+	// encodes and decodes the arguments and results of every call, in memory,
+	// exactly as for a remote call. It catches serialization bugs, e.g., in
+	// custom WeaverMarshal and WeaverUnmarshal methods, without the cost of
+	// RPCs.
+	SerializedLocal = Runner{serialize: true, Name: "SerializedLocal"}
+
 	// RPC is a Runner that places all components in the same process
 	// and uses RPCs for method invocations. We also add an extra retry to
 	// every retriable RPC to discover methods that should have been
@@ -74,7 +98,7 @@ var (
 )
 
 // AllRunners returns a slice of all builtin weavertest runners.
-func AllRunners() []Runner { return []Runner{Local, RPC, Multi} }
+func AllRunners() []Runner { return []Runner{Local, SerializedLocal, RPC, Multi} }
 
 // FakeComponent records the implementation to use for a specific component type.
 type FakeComponent struct {
@@ -93,6 +117,68 @@ func Fake[T any](impl any) FakeComponent {
 	return FakeComponent{intf: t, impl: impl}
 }
 
+// Replay returns a fake for the component type T that replays the calls to T
+// recorded in directory dir by weaver.RecordRPCs. A call to the fake returns
+// the recorded reply of a call to the same method with the same arguments, or
+// fails with an error if no such call was recorded. The result is typically
+// placed in Runner.Fakes. For example:
+//
+//	runner := weavertest.Local
+//	runner.Fakes = []weavertest.FakeComponent{
+//	    weavertest.Replay[Catalog](t, "testdata/rpcs"),
+//	}
+//
+// Arguments are matched by their serialized form, byte for byte. As a result,
+// calls with arguments whose serialization is not deterministic, like maps
+// with more than one entry, are typically not replayed.
+func Replay[T any](t testing.TB, dir string) FakeComponent {
+	t.Helper()
+	name := reflection.ComponentName[T]()
+	reg, ok := codegen.Find(name)
+	if !ok {
+		t.Fatalf("component %s not found", name)
+	}
+	records, err := codegen.ReadRecords(dir)
+	if err != nil {
+		t.Fatalf("read recorded calls: %v", err)
+	}
+	stub := codegen.NewReplayStub(reg, records, trace.NewNoopTracerProvider().Tracer(""))
+	return Fake[T](reg.ClientStubFn(stub, "weavertest.Replay"))
+}
+
+// Fault records a fault to inject into calls to a specific component method.
+type Fault struct {
+	component string       // full component name
+	method    string       // method name
+	fn        func() error // returns the error to inject, if any
+}
+
+// InjectFault arranges for every remote call of the provided method of the
+// component type T to first call fn. If fn returns a non-nil error, the call
+// is not sent to the component, and it fails with an error that wraps both
+// weaver.RemoteCallError and the returned error. The result is typically
+// placed in Runner.Faults. For example:
+//
+//	runner := weavertest.RPC
+//	runner.Faults = []weavertest.Fault{
+//	    weavertest.InjectFault[Cache]("Get", func() error {
+//	        return errors.New("injected fault")
+//	    }),
+//	}
+//
+// Faults are injected only into remote calls made from the test process. In
+// particular, they have no effect with the Local runner, which doesn't make
+// remote calls.
+//
+// REQUIRES: T must have a method with the provided name.
+func InjectFault[T any](method string, fn func() error) Fault {
+	t := reflection.Type[T]()
+	if _, ok := t.MethodByName(method); !ok {
+		panic(fmt.Sprintf("%v has no method %q", t, method))
+	}
+	return Fault{component: reflection.ComponentName[T](), method: method, fn: fn}
+}
+
 // Test runs a sub-test of t that tests the supplied Service Weaver
 // application code. It fails at runtime if body is not a function
 // whose signature looks like:
@@ -170,6 +256,14 @@ func (r Runner) sub(t testing.TB, isBench bool, testBody any) {
 		}
 	}
 
+	if r.Clock != nil {
+		if r.multi {
+			t.Fatal("weavertest: Clock is not supported by multi-process runners")
+		}
+		// Restore the system clock once everything else has shut down.
+		defer clock.Set(r.Clock)()
+	}
+
 	var cleanup func() error
 	ctx, cancelFn := context.WithCancel(context.Background())
 	defer func() {
@@ -203,9 +297,10 @@ func (r Runner) sub(t testing.TB, isBench bool, testBody any) {
 	var runner weaver.Weavelet
 	if !r.multi && !r.forceRPC {
 		opts := weaver.SingleWeaveletOptions{
-			Fakes:  fakes,
-			Config: r.Config,
-			Quiet:  !testing.Verbose(),
+			Fakes:     fakes,
+			Config:    r.Config,
+			Quiet:     !testing.Verbose(),
+			Serialize: r.serialize,
 		}
 		var err error
 		runner, err = weaver.NewSingleWeavelet(ctx, codegen.Registered(), opts)
@@ -213,7 +308,14 @@ func (r Runner) sub(t testing.TB, isBench bool, testBody any) {
 			t.Fatal(err)
 		}
 	} else {
-		opts := weaver.RemoteWeaveletOptions{Fakes: fakes, InjectRetries: r.injectRetries}
+		faults := map[string]map[string]func() error{}
+		for _, f := range r.Faults {
+			if faults[f.component] == nil {
+				faults[f.component] = map[string]func() error{}
+			}
+			faults[f.component][f.method] = f.fn
+		}
+		opts := weaver.RemoteWeaveletOptions{Fakes: fakes, InjectRetries: r.injectRetries, Faults: faults}
 		logger := logging.NewTestLogger(t, testing.Verbose())
 		wlet, multiCleanup, err := initMultiProcess(ctx, t, isBench, r, intfs, logger.Log, opts)
 		if err != nil {
@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission contains a component with a method that blocks, used to
+// test the order in which a weavelet admits waiting calls.
+package admission
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+//go:generate ../../../cmd/weaver/weaver generate
+
+// Worker runs named calls. Calls are routed by directory, so all the calls
+// that share a directory run on the same replica.
+type Worker interface {
+	// Run appends the name of the call to the "names" file in dir. The first
+	// call with a given dir blocks until an "unblock" file exists in dir.
+	Run(ctx context.Context, dir, name string) error
+}
+
+type workerRouter struct{}
+
+func (workerRouter) Run(_ context.Context, dir, _ string) string {
+	return dir
+}
+
+type worker struct {
+	weaver.Implements[Worker]
+	weaver.WithRouter[workerRouter]
+	mu    sync.Mutex
+	calls map[string]int // number of calls, by dir
+}
+
+func (w *worker) Run(ctx context.Context, dir, name string) error {
+	w.mu.Lock()
+	if w.calls == nil {
+		w.calls = map[string]int{}
+	}
+	first := w.calls[dir] == 0
+	w.calls[dir]++
+	err := appendName(dir, name)
+	w.mu.Unlock()
+	if err != nil || !first {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "unblock")); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// appendName appends the provided name to the "names" file in dir.
+func appendName(dir, name string) error {
+	f, err := os.OpenFile(filepath.Join(dir, "names"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(name + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/metadata"
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+// names returns the names of the calls that ran with the provided dir, in
+// order.
+func names(t *testing.T, dir string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "names"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(data))
+}
+
+// TestAdmissionPriority tests that a weavelet configured to run one call at a
+// time runs the waiting calls in priority order.
+func TestAdmissionPriority(t *testing.T) {
+	runner := weavertest.Multi
+	runner.Config = `
+		[admission]
+		max_concurrent_calls = 1
+	`
+
+	// Worker is replicated, and every replica admits calls on its own. Route
+	// all calls to the same replica, once every replica is available.
+	var available atomic.Int64
+	if err := weaver.Route[Worker](func(_ uint64, replicas []string) string {
+		available.Store(int64(len(replicas)))
+		return replicas[0]
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	runner.Test(t, func(t *testing.T, w Worker) {
+		ctx := context.Background()
+		warmup := t.TempDir()
+		if err := os.WriteFile(filepath.Join(warmup, "unblock"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		for available.Load() < weavertest.DefaultReplication {
+			if err := w.Run(ctx, warmup, "warmup"); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		dir := t.TempDir()
+		errs := make(chan error, 3)
+		run := func(ctx context.Context, name string) {
+			go func() { errs <- w.Run(ctx, dir, name) }()
+		}
+
+		// Occupy the only slot.
+		run(ctx, "first")
+		for len(names(t, dir)) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Queue a low priority call, and then a default priority call.
+		run(metadata.WithPriority(ctx, -1), "low")
+		time.Sleep(100 * time.Millisecond)
+		run(ctx, "high")
+		time.Sleep(100 * time.Millisecond)
+
+		// The default priority call runs first, even though it was queued
+		// after the low priority call.
+		if err := os.WriteFile(filepath.Join(dir, "unblock"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			if err := <-errs; err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, want := names(t, dir), []string{"first", "high", "low"}; !slices.Equal(got, want) {
+			t.Fatalf("calls: got %v, want %v", got, want)
+		}
+	})
+}
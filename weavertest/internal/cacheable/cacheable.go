@@ -0,0 +1,76 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cacheable contains components with methods marked
+// //weaver:cacheable, for testing weavertest.CheckCacheable.
+package cacheable
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Rates stores exchange rates, in USD cents.
+type Rates interface {
+	//weaver:readonly
+	Lookup(context.Context, string) (int64, error)
+
+	//weaver:write
+	Set(context.Context, string, int64) error
+}
+
+type rates struct {
+	weaver.Implements[Rates]
+	mu    sync.Mutex
+	rates map[string]int64
+}
+
+func (r *rates) Init(context.Context) error {
+	r.rates = map[string]int64{"USD": 100}
+	return nil
+}
+
+func (r *rates) Lookup(_ context.Context, currency string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rates[currency], nil
+}
+
+func (r *rates) Set(_ context.Context, currency string, rate int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates[currency] = rate
+	return nil
+}
+
+// Prices converts prices between currencies.
+type Prices interface {
+	//weaver:cacheable
+	Convert(context.Context, int64, string) (int64, error)
+}
+
+type prices struct {
+	weaver.Implements[Prices]
+	rates weaver.Ref[Rates]
+}
+
+func (p *prices) Convert(ctx context.Context, cents int64, currency string) (int64, error) {
+	rate, err := p.rates.Get().Lookup(ctx, currency)
+	if err != nil || rate == 0 {
+		return 0, err
+	}
+	return cents * 100 / rate, nil
+}
@@ -98,7 +98,7 @@ func (s a_local_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "chain.A.Propagate", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/A", "Propagate", "chain.A.Propagate", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -108,6 +108,13 @@ func (s a_local_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/chain/A") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/A", "Propagate", func(ctx context.Context) (err error) {
+			err = s.impl.Propagate(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Propagate(ctx, a0)
 }
 
@@ -127,7 +134,7 @@ func (s b_local_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "chain.B.Propagate", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/B", "Propagate", "chain.B.Propagate", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -137,6 +144,13 @@ func (s b_local_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/chain/B") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/B", "Propagate", func(ctx context.Context) (err error) {
+			err = s.impl.Propagate(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Propagate(ctx, a0)
 }
 
@@ -156,7 +170,7 @@ func (s c_local_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "chain.C.Propagate", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/C", "Propagate", "chain.C.Propagate", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -166,6 +180,13 @@ func (s c_local_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/chain/C") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/C", "Propagate", func(ctx context.Context) (err error) {
+			err = s.impl.Propagate(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Propagate(ctx, a0)
 }
 
@@ -188,7 +209,7 @@ func (s a_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "chain.A.Propagate", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/chain/A", "Propagate", "chain.A.Propagate", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -196,6 +217,7 @@ func (s a_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.propagateMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -206,6 +228,8 @@ func (s a_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -218,20 +242,26 @@ func (s a_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type b_client_stub struct {
@@ -251,7 +281,7 @@ func (s b_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "chain.B.Propagate", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/chain/B", "Propagate", "chain.B.Propagate", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -259,6 +289,7 @@ func (s b_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.propagateMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -269,6 +300,8 @@ func (s b_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -281,20 +314,26 @@ func (s b_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type c_client_stub struct {
@@ -314,7 +353,7 @@ func (s c_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "chain.C.Propagate", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/chain/C", "Propagate", "chain.C.Propagate", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -322,6 +361,7 @@ func (s c_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.propagateMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -332,6 +372,8 @@ func (s c_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -344,29 +386,35 @@ func (s c_client_stub) Propagate(ctx context.Context, a0 int) (err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -404,22 +452,40 @@ func (s a_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s a_server_stub) propagate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/chain/A", "Propagate", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Propagate(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/chain/A") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/A", "Propagate", func(ctx context.Context) (err error) {
+			err = s.impl.Propagate(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.Propagate(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -446,22 +512,40 @@ func (s b_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s b_server_stub) propagate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/chain/B", "Propagate", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Propagate(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/chain/B") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/B", "Propagate", func(ctx context.Context) (err error) {
+			err = s.impl.Propagate(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.Propagate(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -488,22 +572,40 @@ func (s c_server_stub) GetStubFn(method string) func(ctx context.Context, args [
 }
 
 func (s c_server_stub) propagate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/chain/C", "Propagate", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Propagate(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/chain/C") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/chain/C", "Propagate", func(ctx context.Context) (err error) {
+			err = s.impl.Propagate(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.Propagate(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -0,0 +1,82 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clocked contains a component that uses the clock provided by
+// weaver.Implements, for testing weavertest.FakeClock.
+package clocked
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// ttl is how long a Cache entry lives.
+const ttl = time.Minute
+
+// Cache is a cache whose entries expire after one minute.
+type Cache interface {
+	Put(context.Context, string, string) error
+	Get(context.Context, string) (string, bool, error)
+
+	// Sleep advances the fake clock by the provided duration.
+	Sleep(context.Context, time.Duration) error
+}
+
+var _ weaver.NotRetriable = Cache.Sleep
+
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+type cache struct {
+	weaver.Implements[Cache]
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+func (c *cache) Init(context.Context) error {
+	c.entries = map[string]entry{}
+	return nil
+}
+
+func (c *cache) Put(_ context.Context, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry{value: value, expires: c.Clock().Now().Add(ttl)}
+	return nil
+}
+
+func (c *cache) Get(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.Clock().Now().Before(e.expires) {
+		return "", false, nil
+	}
+	return e.value, true, nil
+}
+
+func (c *cache) Sleep(_ context.Context, d time.Duration) error {
+	clock, ok := c.Clock().(interface{ Advance(time.Duration) })
+	if !ok {
+		return fmt.Errorf("clock %T cannot be advanced", c.Clock())
+	}
+	clock.Advance(d)
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clocked
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	imetrics "github.com/ServiceWeaver/weaver/internal/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestExpiry(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		clock := weavertest.NewFakeClock(time.Unix(0, 0))
+		runner.Clock = clock
+		runner.Test(t, func(t *testing.T, c Cache) {
+			if err := c.Put(ctx, "key", "value"); err != nil {
+				t.Fatal(err)
+			}
+			clock.Advance(ttl - time.Second)
+			if _, ok, err := c.Get(ctx, "key"); err != nil || !ok {
+				t.Fatalf("Get before expiry: got (%v, %v), want (true, nil)", ok, err)
+			}
+			clock.Advance(time.Second)
+			if _, ok, err := c.Get(ctx, "key"); err != nil || ok {
+				t.Fatalf("Get after expiry: got (%v, %v), want (false, nil)", ok, err)
+			}
+		})
+	}
+}
+
+// sleepLatency returns the total latency, in microseconds, recorded for calls
+// to Cache.Sleep.
+func sleepLatency() float64 {
+	var total float64
+	for _, snap := range metrics.Snapshot() {
+		if snap.Name == imetrics.MethodLatenciesName && snap.Labels["method"] == "Sleep" {
+			total += snap.Value
+		}
+	}
+	return total
+}
+
+func TestLatencyMetrics(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Clock = weavertest.NewFakeClock(time.Unix(0, 0))
+		runner.Test(t, func(t *testing.T, c Cache) {
+			before := sleepLatency()
+			if err := c.Sleep(ctx, 1234*time.Microsecond); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := sleepLatency()-before, 1234.0; got != want {
+				t.Fatalf("latency: got %vµs, want %vµs", got, want)
+			}
+		})
+	}
+}
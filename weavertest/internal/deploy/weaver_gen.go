@@ -78,7 +78,7 @@ func (s started_local_stub) MarkStarted(ctx context.Context, a0 string) (err err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "deploy.Started.MarkStarted", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started", "MarkStarted", "deploy.Started.MarkStarted", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -88,6 +88,13 @@ func (s started_local_stub) MarkStarted(ctx context.Context, a0 string) (err err
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started", "MarkStarted", func(ctx context.Context) (err error) {
+			err = s.impl.MarkStarted(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.MarkStarted(ctx, a0)
 }
 
@@ -107,7 +114,7 @@ func (s widget_local_stub) Use(ctx context.Context, a0 string) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "deploy.Widget.Use", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget", "Use", "deploy.Widget.Use", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -117,6 +124,13 @@ func (s widget_local_stub) Use(ctx context.Context, a0 string) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget", "Use", func(ctx context.Context) (err error) {
+			err = s.impl.Use(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Use(ctx, a0)
 }
 
@@ -139,7 +153,7 @@ func (s started_client_stub) MarkStarted(ctx context.Context, a0 string) (err er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "deploy.Started.MarkStarted", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started", "MarkStarted", "deploy.Started.MarkStarted", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -147,6 +161,7 @@ func (s started_client_stub) MarkStarted(ctx context.Context, a0 string) (err er
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.markStartedMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -157,6 +172,8 @@ func (s started_client_stub) MarkStarted(ctx context.Context, a0 string) (err er
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -169,20 +186,26 @@ func (s started_client_stub) MarkStarted(ctx context.Context, a0 string) (err er
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type widget_client_stub struct {
@@ -202,7 +225,7 @@ func (s widget_client_stub) Use(ctx context.Context, a0 string) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "deploy.Widget.Use", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget", "Use", "deploy.Widget.Use", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -210,6 +233,7 @@ func (s widget_client_stub) Use(ctx context.Context, a0 string) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.useMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -220,6 +244,8 @@ func (s widget_client_stub) Use(ctx context.Context, a0 string) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -232,29 +258,35 @@ func (s widget_client_stub) Use(ctx context.Context, a0 string) (err error) {
 	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -292,22 +324,40 @@ func (s started_server_stub) GetStubFn(method string) func(ctx context.Context,
 }
 
 func (s started_server_stub) markStarted(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started", "MarkStarted", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.MarkStarted(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Started", "MarkStarted", func(ctx context.Context) (err error) {
+			err = s.impl.MarkStarted(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.MarkStarted(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -334,22 +384,40 @@ func (s widget_server_stub) GetStubFn(method string) func(ctx context.Context, a
 }
 
 func (s widget_server_stub) use(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget", "Use", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Use(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/deploy/Widget", "Use", func(ctx context.Context) (err error) {
+			err = s.impl.Use(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.Use(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -24,7 +24,8 @@ import (
 
 //go:generate ../../../cmd/weaver/weaver generate ./...
 
-// TestDealiasing demonstrates that pointers are only de-aliased when we use RPCs.
+// TestDealiasing demonstrates that pointers are only de-aliased when calls are
+// serialized, i.e., with every runner but Local.
 func TestDealiasing(t *testing.T) {
 	for _, runner := range weavertest.AllRunners() {
 		runner.Test(t, func(t *testing.T, p Pointer) {
@@ -79,7 +79,7 @@ func (s errer_local_stub) Err(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "diverge.Errer.Err", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer", "Err", "diverge.Errer.Err", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -89,6 +89,13 @@ func (s errer_local_stub) Err(ctx context.Context, a0 int) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer", "Err", func(ctx context.Context) (err error) {
+			err = s.impl.Err(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Err(ctx, a0)
 }
 
@@ -108,7 +115,7 @@ func (s pointer_local_stub) Get(ctx context.Context) (r0 Pair, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "diverge.Pointer.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer", "Get", "diverge.Pointer.Get", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -118,6 +125,13 @@ func (s pointer_local_stub) Get(ctx context.Context) (r0 Pair, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.Get(ctx)
 }
 
@@ -140,7 +154,7 @@ func (s errer_client_stub) Err(ctx context.Context, a0 int) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "diverge.Errer.Err", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer", "Err", "diverge.Errer.Err", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -148,6 +162,7 @@ func (s errer_client_stub) Err(ctx context.Context, a0 int) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.errMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -158,6 +173,8 @@ func (s errer_client_stub) Err(ctx context.Context, a0 int) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -170,20 +187,26 @@ func (s errer_client_stub) Err(ctx context.Context, a0 int) (err error) {
 	enc.Int(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type pointer_client_stub struct {
@@ -203,7 +226,7 @@ func (s pointer_client_stub) Get(ctx context.Context) (r0 Pair, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "diverge.Pointer.Get", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer", "Get", "diverge.Pointer.Get", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -211,6 +234,7 @@ func (s pointer_client_stub) Get(ctx context.Context) (r0 Pair, err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -221,33 +245,40 @@ func (s pointer_client_stub) Get(ctx context.Context) (r0 Pair, err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	(&r0).WeaverUnmarshal(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -285,22 +316,40 @@ func (s errer_server_stub) GetStubFn(method string) func(ctx context.Context, ar
 }
 
 func (s errer_server_stub) err(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer", "Err", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Err(ctx, a0)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Errer", "Err", func(ctx context.Context) (err error) {
+			err = s.impl.Err(ctx, a0)
+			return err
+		})
+	} else {
+		appErr = s.impl.Err(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -330,14 +379,28 @@ func (s pointer_server_stub) get(ctx context.Context, args []byte) (res []byte,
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer", "Get", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Get(ctx)
+	var r0 Pair
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/diverge/Pointer", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Get(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -400,6 +463,18 @@ func (x *Pair) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.Y = serviceweaver_dec_ptr_int_98a2a745(dec)
 }
 
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *Pair) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "X", Value: x.X},
+		codegen.DebugField{Name: "Y", Value: x.Y},
+	)
+}
+
 func serviceweaver_enc_ptr_int_98a2a745(enc *codegen.Encoder, arg *int) {
 	if arg == nil {
 		enc.Bool(false)
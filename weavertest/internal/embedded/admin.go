@@ -0,0 +1,51 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package embedded contains a component whose interface embeds the interface
+// of a component in another package.
+package embedded
+
+import (
+	"context"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/weavertest/internal/embedded/catalog"
+)
+
+// AdminT is a product catalog that can also delete products. Its GetProduct
+// and ListProducts methods, and their //weaver:readonly directives, are
+// promoted from catalog.T.
+type AdminT interface {
+	catalog.T
+	DeleteProduct(ctx context.Context, id string) error
+}
+
+var _ weaver.NotRetriable = AdminT.DeleteProduct
+
+type admin struct {
+	weaver.Implements[AdminT]
+	*catalog.Products
+}
+
+func (a *admin) Init(context.Context) error {
+	a.Products = catalog.NewProducts(
+		catalog.Product{ID: "sunglasses", Name: "Sunglasses"},
+		catalog.Product{ID: "watch", Name: "Watch"},
+	)
+	return nil
+}
+
+func (a *admin) DeleteProduct(_ context.Context, id string) error {
+	return a.Delete(id)
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedded
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/ServiceWeaver/weaver/weavertest/internal/embedded/catalog"
+	"github.com/google/go-cmp/cmp"
+)
+
+//go:generate ../../../cmd/weaver/weaver generate ./...
+
+// TestPromotedMethods tests that the methods an interface promotes from an
+// embedded component interface can be called like any other method.
+func TestPromotedMethods(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, admin AdminT, c catalog.T) {
+			got, err := admin.ListProducts(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []catalog.Product{
+				{ID: "sunglasses", Name: "Sunglasses"},
+				{ID: "watch", Name: "Watch"},
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("ListProducts (-want +got):\n%s", diff)
+			}
+			product, err := admin.GetProduct(ctx, "watch")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := product.Name, "Watch"; got != want {
+				t.Fatalf("GetProduct: got %q, want %q", got, want)
+			}
+			if err := admin.DeleteProduct(ctx, "unknown"); err == nil {
+				t.Fatal("DeleteProduct: unexpected success")
+			}
+
+			// The embedded component is a separate component.
+			if _, err := c.GetProduct(ctx, "watch"); err == nil {
+				t.Fatal("catalog.T.GetProduct: unexpected success")
+			}
+		})
+	}
+}
+
+// TestPromotedDirectives tests that the //weaver:readonly directives of the
+// methods promoted from an embedded component interface are honored.
+func TestPromotedDirectives(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.RPC, weavertest.Multi} {
+		runner.Config = `
+[readonly]
+components = ["github.com/ServiceWeaver/weaver/weavertest/internal/embedded/AdminT"]
+`
+		runner.Test(t, func(t *testing.T, admin AdminT) {
+			if _, err := admin.GetProduct(ctx, "watch"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := admin.ListProducts(ctx); err != nil {
+				t.Fatal(err)
+			}
+			err := admin.DeleteProduct(ctx, "watch")
+			if !errors.Is(err, weaver.ReadOnlyError) {
+				t.Fatalf("DeleteProduct: got %v, want %v", err, weaver.ReadOnlyError)
+			}
+		})
+	}
+}
+
+// TestUnknownMethod tests that a call to a method that the catalog.T server
+// stub doesn't have, like a caller built from a newer version of catalog.T
+// would make, fails with a retryable weaver.UnknownMethodError.
+func TestUnknownMethod(t *testing.T) {
+	reg, ok := codegen.Find("github.com/ServiceWeaver/weaver/weavertest/internal/embedded/catalog/T")
+	if !ok {
+		t.Fatal("catalog.T not registered")
+	}
+	server := reg.ServerStubFn(unimplementedCatalog{}, func(uint64, float64) {})
+	fn := codegen.MethodHandler(reg, server, 99)
+	_, err := fn(context.Background(), nil)
+	if !errors.Is(err, weaver.UnknownMethodError) {
+		t.Fatalf("method 99: got %v, want %v", err, weaver.UnknownMethodError)
+	}
+	if !codegen.IsRetryable(err) {
+		t.Fatalf("method 99: got non-retryable error %v", err)
+	}
+}
+
+// unimplementedCatalog is a catalog.T whose methods are never called.
+type unimplementedCatalog struct{ catalog.T }
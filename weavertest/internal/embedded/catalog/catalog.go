@@ -0,0 +1,102 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog contains a component whose interface is embedded in the
+// interface of a component in another package. See package embedded.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Product is a product in the catalog.
+type Product struct {
+	weaver.AutoMarshal
+	ID   string
+	Name string
+}
+
+// T is a product catalog.
+type T interface {
+	//weaver:readonly
+	GetProduct(ctx context.Context, id string) (Product, error)
+
+	//weaver:readonly
+	ListProducts(ctx context.Context) ([]Product, error)
+}
+
+// Products is a catalog of products that can be embedded in the
+// implementation of a component whose interface embeds T.
+type Products struct {
+	mu       sync.Mutex
+	products map[string]Product
+}
+
+// NewProducts returns a catalog with the provided products.
+func NewProducts(products ...Product) *Products {
+	p := &Products{products: map[string]Product{}}
+	for _, product := range products {
+		p.products[product.ID] = product
+	}
+	return p
+}
+
+// GetProduct implements the T interface.
+func (p *Products) GetProduct(_ context.Context, id string) (Product, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	product, ok := p.products[id]
+	if !ok {
+		return Product{}, fmt.Errorf("product %q not found", id)
+	}
+	return product, nil
+}
+
+// ListProducts implements the T interface.
+func (p *Products) ListProducts(context.Context) ([]Product, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	products := make([]Product, 0, len(p.products))
+	for _, product := range p.products {
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+// Delete deletes the product with the provided id.
+func (p *Products) Delete(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.products[id]; !ok {
+		return fmt.Errorf("product %q not found", id)
+	}
+	delete(p.products, id)
+	return nil
+}
+
+type impl struct {
+	weaver.Implements[T]
+	*Products
+}
+
+func (c *impl) Init(context.Context) error {
+	c.Products = NewProducts(Product{ID: "sunglasses", Name: "Sunglasses"})
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package external contains a component that is served by an external gRPC
+// server, used to test the "[grpc]" config section.
+package external
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Greeter greets people. In tests, it is served by an external gRPC server.
+type Greeter interface {
+	Greet(ctx context.Context, name string) (string, error)
+	Fail(ctx context.Context) error
+}
+
+type greeter struct {
+	weaver.Implements[Greeter]
+
+	// greeting is the greeting used by Greet. It is set only by the external
+	// gRPC server in tests, so callers can tell who served a call.
+	greeting string
+}
+
+func (g *greeter) Greet(_ context.Context, name string) (string, error) {
+	greeting := g.greeting
+	if greeting == "" {
+		greeting = "Hello"
+	}
+	return fmt.Sprintf("%s, %s!", greeting, name), nil
+}
+
+func (g *greeter) Fail(context.Context) error {
+	return fmt.Errorf("greeter: %w", errFail)
+}
+
+// errFail is the error returned by Fail.
+var errFail = failError{}
+
+type failError struct {
+	weaver.AutoMarshal
+}
+
+func (failError) Error() string { return "fail" }
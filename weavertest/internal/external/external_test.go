@@ -0,0 +1,127 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"google.golang.org/grpc"
+)
+
+//go:generate ../../../cmd/weaver/weaver generate ./...
+
+// rawCodec is a gRPC codec whose messages are already encoded byte slices.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error)      { return *v.(*[]byte), nil }
+func (rawCodec) Unmarshal(data []byte, v any) error { *v.(*[]byte) = data; return nil }
+func (rawCodec) Name() string                       { return "raw" }
+
+// serveGRPC serves impl, an implementation of the component with the provided
+// name, on a gRPC server. It returns the address of the server and the number
+// of calls it has served.
+func serveGRPC(t *testing.T, name string, impl any) (string, *atomic.Int32) {
+	t.Helper()
+	reg, ok := codegen.Find(name)
+	if !ok {
+		t.Fatalf("component %q not found", name)
+	}
+	server := reg.ServerStubFn(impl, func(uint64, float64) {})
+	var calls atomic.Int32
+	handler := func(_ any, stream grpc.ServerStream) error {
+		fullMethod, _ := grpc.MethodFromServerStream(stream)
+		i := strings.LastIndex(fullMethod, "/")
+		service, method := fullMethod[1:i], fullMethod[i+1:]
+		if service != name {
+			return fmt.Errorf("unknown service %q", service)
+		}
+		var args []byte
+		if err := stream.RecvMsg(&args); err != nil {
+			return err
+		}
+		calls.Add(1)
+		results, err := server.GetStubFn(method)(stream.Context(), args)
+		if err != nil {
+			return err
+		}
+		return stream.SendMsg(&results)
+	}
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnknownServiceHandler(handler), grpc.ForceServerCodec(rawCodec{}))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), &calls
+}
+
+// TestGRPC tests that calls to a component listed in the "[grpc]" config
+// section are served by the external gRPC server.
+func TestGRPC(t *testing.T) {
+	const name = "github.com/ServiceWeaver/weaver/weavertest/internal/external/Greeter"
+	ctx := context.Background()
+	for _, runner := range weavertest.AllRunners() {
+		addr, calls := serveGRPC(t, name, &greeter{greeting: "Hi"})
+		runner.Config = fmt.Sprintf("[grpc]\n%q = {address = %q}\n", name, addr)
+		runner.Test(t, func(t *testing.T, g Greeter) {
+			got, err := g.Greet(ctx, "Alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "Hi, Alice!"; got != want {
+				t.Fatalf("Greet: got %q, want %q", got, want)
+			}
+
+			// Application errors are propagated.
+			if err := g.Fail(ctx); !errors.Is(err, errFail) {
+				t.Fatalf("Fail: got %v, want %v", err, errFail)
+			}
+			if got, want := calls.Load(), int32(2); got != want {
+				t.Fatalf("gRPC server calls: got %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+// TestGRPCUnavailable tests that calls to a component whose gRPC server is
+// down fail with weaver.RemoteCallError.
+func TestGRPCUnavailable(t *testing.T) {
+	const name = "github.com/ServiceWeaver/weaver/weavertest/internal/external/Greeter"
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	runner := weavertest.Local
+	runner.Config = fmt.Sprintf("[grpc]\n%q = {address = %q}\n", name, addr)
+	runner.Test(t, func(t *testing.T, g Greeter) {
+		if _, err := g.Greet(context.Background(), "Alice"); !errors.Is(err, weaver.RemoteCallError) {
+			t.Fatalf("Greet: got %v, want %v", err, weaver.RemoteCallError)
+		}
+	})
+}
@@ -20,8 +20,11 @@ package generate
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
 )
 
 //go:generate ../../../cmd/weaver/weaver generate
@@ -42,10 +45,89 @@ type customErrorValue struct {
 
 func (c customErrorValue) Error() string { return fmt.Sprintf("customError(%s)", c.key) }
 
+// shape is a sealed union with variants *circle and square.
+type shape interface {
+	isShape()
+}
+
+type circle struct {
+	weaver.AutoMarshal
+	Radius float64
+}
+
+func (*circle) isShape() {}
+
+type square struct {
+	weaver.AutoMarshal
+	Side float64
+}
+
+func (square) isShape() {}
+
+// status is an enum. Decoding a status that isn't one of the constants below
+// fails.
+//
+//weaver:enum
+type status int
+
+const (
+	pending status = iota
+	settled
+	failed
+)
+
+// transaction is the result of a batch of operations, some of which may have
+// failed.
+type transaction struct {
+	weaver.AutoMarshal
+	Items []transactionItem
+}
+
+type transactionItem struct {
+	weaver.AutoMarshal
+	Key string
+	Err error // nil if the operation succeeded
+}
+
+// *big.Float can't embed weaver.AutoMarshal, so it is encoded by a registered
+// marshaler. A nil *big.Float is encoded as such, since its text form can't be
+// unmarshaled.
+func init() {
+	codegen.RegisterMarshaler(
+		func(enc *codegen.Encoder, f *big.Float) {
+			enc.Bool(f != nil)
+			if f == nil {
+				return
+			}
+			text, err := f.MarshalText()
+			if err != nil {
+				panic(err)
+			}
+			enc.Bytes(text)
+		},
+		func(dec *codegen.Decoder) *big.Float {
+			if !dec.Bool() {
+				return nil
+			}
+			f := new(big.Float)
+			if err := f.UnmarshalText(dec.Bytes()); err != nil {
+				panic(err)
+			}
+			return f
+		},
+	)
+}
+
 type testApp interface {
 	Get(_ context.Context, key string, behavior behaviorType) (int, error)
 	IncPointer(_ context.Context, arg *int) (*int, error)
 	DivMod(_ context.Context, numerator int, denominator int) (int, int, error)
+	Scale(_ context.Context, s shape, factor float64) (shape, error)
+	BatchGet(_ context.Context, keys ...string) ([]string, error)
+	Settle(_ context.Context, s status) (status, error)
+	Apply(_ context.Context, keys []string) (transaction, error)
+	Sum(_ context.Context, xs []*big.Float) (*big.Float, error)
+	Total(_ context.Context, prices []*big.Rat) (*big.Rat, error)
 }
 
 type impl struct {
@@ -84,3 +166,72 @@ func (p *impl) DivMod(_ context.Context, n, d int) (int, int, error) {
 	}
 	return n / d, n % d, nil
 }
+
+// Scale returns s scaled by the provided factor.
+func (p *impl) Scale(_ context.Context, s shape, factor float64) (shape, error) {
+	switch x := s.(type) {
+	case nil:
+		return nil, nil
+	case *circle:
+		return &circle{Radius: x.Radius * factor}, nil
+	case square:
+		return square{Side: x.Side * factor}, nil
+	}
+	return nil, fmt.Errorf("unexpected shape %T", s)
+}
+
+// BatchGet returns the values of the provided keys. It returns an empty,
+// non-nil slice if there are no keys.
+func (p *impl) BatchGet(_ context.Context, keys ...string) ([]string, error) {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = "value of " + key
+	}
+	return values, nil
+}
+
+// Settle settles a pending status.
+func (p *impl) Settle(_ context.Context, s status) (status, error) {
+	if s != pending {
+		return s, fmt.Errorf("cannot settle status %d", s)
+	}
+	return settled, nil
+}
+
+// Apply applies an operation to every provided key. The operations on the
+// keys that start with "bad" fail.
+func (p *impl) Apply(_ context.Context, keys []string) (transaction, error) {
+	var t transaction
+	for _, key := range keys {
+		item := transactionItem{Key: key}
+		if strings.HasPrefix(key, "bad") {
+			item.Err = customErrorValue{key: key}
+		}
+		t.Items = append(t.Items, item)
+	}
+	return t, nil
+}
+
+// Sum returns the sum of the provided numbers. nil numbers are ignored.
+func (p *impl) Sum(_ context.Context, xs []*big.Float) (*big.Float, error) {
+	sum := new(big.Float)
+	for _, x := range xs {
+		if x != nil {
+			sum.Add(sum, x)
+		}
+	}
+	return sum, nil
+}
+
+// Total returns the exact sum of the provided prices, or nil if there are no
+// prices.
+func (p *impl) Total(_ context.Context, prices []*big.Rat) (*big.Rat, error) {
+	if len(prices) == 0 {
+		return nil, nil
+	}
+	total := new(big.Rat)
+	for _, price := range prices {
+		total.Add(total, price)
+	}
+	return total, nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 
@@ -25,6 +26,9 @@ import (
 	"github.com/ServiceWeaver/weaver/internal/reflection"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TODO(mwhittaker): Induce an error in the encoding, decoding, and RPC call.
@@ -115,6 +119,129 @@ func TestPointers(t *testing.T) {
 	}
 }
 
+func TestSealedUnions(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		ctx := context.Background()
+		runner.Test(t, func(t *testing.T, client testApp) {
+			for _, test := range []struct {
+				name string
+				s    shape
+				want shape
+			}{
+				{"Nil", nil, nil},
+				{"Circle", &circle{Radius: 1}, &circle{Radius: 2}},
+				{"Square", square{Side: 3}, square{Side: 6}},
+			} {
+				t.Run(test.name, func(t *testing.T) {
+					got, err := client.Scale(ctx, test.s, 2)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(test.want, got, cmpopts.IgnoreUnexported(circle{}, square{})); diff != "" {
+						t.Fatalf("Scale (-want +got):\n%s", diff)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestVariadic(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		ctx := context.Background()
+		runner.Test(t, func(t *testing.T, client testApp) {
+			for _, test := range []struct {
+				name string
+				keys []string
+				want []string
+			}{
+				{"None", nil, []string{}},
+				{"One", []string{"a"}, []string{"value of a"}},
+				{"Many", []string{"a", "b", "c"}, []string{"value of a", "value of b", "value of c"}},
+			} {
+				t.Run(test.name, func(t *testing.T) {
+					got, err := client.BatchGet(ctx, test.keys...)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(test.want, got); diff != "" {
+						t.Fatalf("BatchGet (-want +got):\n%s", diff)
+					}
+				})
+			}
+		})
+	}
+}
+
+// argsStub is a codegen.Stub that records the serialized arguments of every
+// call before passing them to a generated server stub.
+type argsStub struct {
+	server codegen.Server
+	args   []byte // serialized arguments of the last call
+}
+
+func (s *argsStub) Tracer() trace.Tracer {
+	return trace.NewNoopTracerProvider().Tracer("")
+}
+
+func (s *argsStub) Run(ctx context.Context, method int, args []byte, _ uint64) ([]byte, error) {
+	s.args = append([]byte(nil), args...)
+	name := reflection.Type[testApp]().Method(method).Name
+	return s.server.GetStubFn(name)(ctx, args)
+}
+
+// keysRecorder is a testApp that records the keys passed to BatchGet.
+type keysRecorder struct {
+	impl
+	keys []string
+}
+
+func (r *keysRecorder) BatchGet(ctx context.Context, keys ...string) ([]string, error) {
+	r.keys = keys
+	return r.impl.BatchGet(ctx, keys...)
+}
+
+func TestVariadicArgs(t *testing.T) {
+	// Call BatchGet through the generated client and server stubs, and check
+	// the variadic arguments both on the wire and as received by the method.
+	cname := reflection.ComponentName[testApp]()
+	reg, ok := codegen.Find(cname)
+	if !ok {
+		t.Fatalf("component %q is not registered", cname)
+	}
+	for _, test := range []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{"None", nil, []string{}},
+		{"Empty", []string{}, []string{}},
+		{"Many", []string{"a", "b", "c"}, []string{"a", "b", "c"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &keysRecorder{}
+			stub := &argsStub{server: reg.ServerStubFn(recorder, func(uint64, float64) {})}
+			client := reg.ClientStubFn(stub, "test").(testApp)
+			if _, err := client.BatchGet(context.Background(), test.keys...); err != nil {
+				t.Fatal(err)
+			}
+
+			// Zero variadic arguments are encoded as an empty, not nil, slice.
+			dec := codegen.NewDecoder(stub.args)
+			encoded := serviceweaver_dec_slice_string_4af10117(dec)
+			if !dec.Empty() {
+				t.Fatalf("leftover bytes in encoded arguments")
+			}
+			if diff := cmp.Diff(test.want, encoded); diff != "" {
+				t.Errorf("encoded keys (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.want, recorder.keys); diff != "" {
+				t.Errorf("decoded keys (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestReflectStubs(t *testing.T) {
 	fakeErr := fmt.Errorf("fake error")
 	call := func(method string, _ context.Context, args, returns []any) error {
@@ -145,3 +272,126 @@ func TestReflectStubs(t *testing.T) {
 		t.Errorf("bad err: got %v, want %v", err, fakeErr)
 	}
 }
+
+func TestEnums(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		ctx := context.Background()
+		runner.Test(t, func(t *testing.T, client testApp) {
+			got, err := client.Settle(ctx, pending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != settled {
+				t.Fatalf("Settle: got %d, want %d", got, settled)
+			}
+
+			// Invalid statuses are rejected when decoded by a remote
+			// component. Local calls don't decode their arguments.
+			_, err = client.Settle(ctx, status(42))
+			if runner.Name == weavertest.Local.Name {
+				if err == nil || errors.Is(err, weaver.RemoteCallError) {
+					t.Fatalf("Settle: got %v, want application error", err)
+				}
+				return
+			}
+			if !errors.Is(err, weaver.RemoteCallError) || !strings.Contains(err.Error(), "invalid value 42") {
+				t.Fatalf("Settle: got %v, want decoding error", err)
+			}
+		})
+	}
+}
+
+func TestErrorFields(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		ctx := context.Background()
+		runner.Test(t, func(t *testing.T, client testApp) {
+			got, err := client.Apply(ctx, []string{"good", "bad"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Items) != 2 {
+				t.Fatalf("Apply: got %d items, want 2", len(got.Items))
+			}
+			if err := got.Items[0].Err; err != nil {
+				t.Errorf("Apply: item %q: got %v, want nil", got.Items[0].Key, err)
+			}
+			var custom customErrorValue
+			if err := got.Items[1].Err; !errors.As(err, &custom) || custom.key != "bad" {
+				t.Errorf("Apply: item %q: got %v, want %v", got.Items[1].Key, err, customErrorValue{key: "bad"})
+			}
+		})
+	}
+}
+
+func TestRegisteredMarshaler(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		ctx := context.Background()
+		runner.Test(t, func(t *testing.T, client testApp) {
+			got, err := client.Sum(ctx, []*big.Float{big.NewFloat(1.5), big.NewFloat(2.25)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := big.NewFloat(3.75); got.Cmp(want) != 0 {
+				t.Fatalf("Sum: got %v, want %v", got, want)
+			}
+
+			// nil numbers are encoded as such.
+			got, err = client.Sum(ctx, []*big.Float{nil, big.NewFloat(1.5)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := big.NewFloat(1.5); got.Cmp(want) != 0 {
+				t.Fatalf("Sum: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBigRat(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		ctx := context.Background()
+		runner.Test(t, func(t *testing.T, client testApp) {
+			// Decimal amounts are summed exactly.
+			got, err := client.Total(ctx, []*big.Rat{big.NewRat(1, 10), big.NewRat(2, 10)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := big.NewRat(3, 10); got == nil || got.Cmp(want) != 0 {
+				t.Fatalf("Total: got %v, want %v", got, want)
+			}
+
+			// A nil *big.Rat is passed as nil.
+			got, err = client.Total(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != nil {
+				t.Fatalf("Total: got %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestDebugString(t *testing.T) {
+	// Unexported fields are serialized, so they are rendered too.
+	v := customErrorValue{key: "missing"}
+	if got, want := v.DebugString(), `{"key":"missing"}`; got != want {
+		t.Errorf("DebugString: got %s, want %s", got, want)
+	}
+	if got, want := (&circle{Radius: 1.5}).DebugString(), `{"Radius":1.5}`; got != want {
+		t.Errorf("DebugString: got %s, want %s", got, want)
+	}
+	if got, want := (*circle)(nil).DebugString(), `null`; got != want {
+		t.Errorf("DebugString: got %s, want %s", got, want)
+	}
+}
+
+// FuzzCustomErrorValueRoundTrip is like the round-trip fuzz tests generated by
+// "weaver generate -fuzz", for a type with an unexported field.
+func FuzzCustomErrorValueRoundTrip(f *testing.F) {
+	weavertest.FuzzRoundTrip[customErrorValue](f)
+}
+
+func FuzzCircleRoundTrip(f *testing.F) {
+	weavertest.FuzzRoundTrip[circle](f)
+}
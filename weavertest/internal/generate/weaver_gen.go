@@ -11,6 +11,7 @@ import (
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"math/big"
 	"reflect"
 )
 
@@ -20,10 +21,10 @@ func init() {
 		Iface: reflect.TypeOf((*testApp)(nil)).Elem(),
 		Impl:  reflect.TypeOf(impl{}),
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return testApp_local_stub{impl: impl.(testApp), tracer: tracer, divModMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "DivMod", Remote: false, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Get", Remote: false, Generated: true}), incPointerMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "IncPointer", Remote: false, Generated: true})}
+			return testApp_local_stub{impl: impl.(testApp), tracer: tracer, applyMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Apply", Remote: false, Generated: true}), batchGetMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "BatchGet", Remote: false, Generated: true}), divModMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "DivMod", Remote: false, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Get", Remote: false, Generated: true}), incPointerMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "IncPointer", Remote: false, Generated: true}), scaleMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Scale", Remote: false, Generated: true}), settleMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Settle", Remote: false, Generated: true}), sumMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Sum", Remote: false, Generated: true}), totalMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Total", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return testApp_client_stub{stub: stub, divModMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "DivMod", Remote: true, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Get", Remote: true, Generated: true}), incPointerMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "IncPointer", Remote: true, Generated: true})}
+			return testApp_client_stub{stub: stub, applyMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Apply", Remote: true, Generated: true}), batchGetMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "BatchGet", Remote: true, Generated: true}), divModMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "DivMod", Remote: true, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Get", Remote: true, Generated: true}), incPointerMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "IncPointer", Remote: true, Generated: true}), scaleMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Scale", Remote: true, Generated: true}), settleMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Settle", Remote: true, Generated: true}), sumMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Sum", Remote: true, Generated: true}), totalMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", Method: "Total", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return testApp_server_stub{impl: impl.(testApp), addLoad: addLoad}
@@ -46,14 +47,74 @@ var _ weaver.Unrouted = (*impl)(nil)
 type testApp_local_stub struct {
 	impl              testApp
 	tracer            trace.Tracer
+	applyMetrics      *codegen.MethodMetrics
+	batchGetMetrics   *codegen.MethodMetrics
 	divModMetrics     *codegen.MethodMetrics
 	getMetrics        *codegen.MethodMetrics
 	incPointerMetrics *codegen.MethodMetrics
+	scaleMetrics      *codegen.MethodMetrics
+	settleMetrics     *codegen.MethodMetrics
+	sumMetrics        *codegen.MethodMetrics
+	totalMetrics      *codegen.MethodMetrics
 }
 
 // Check that testApp_local_stub implements the testApp interface.
 var _ testApp = (*testApp_local_stub)(nil)
 
+func (s testApp_local_stub) Apply(ctx context.Context, a0 []string) (r0 transaction, err error) {
+	// Update metrics.
+	begin := s.applyMetrics.Begin()
+	defer func() { s.applyMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Apply", "generate.testApp.Apply", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Apply", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Apply(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Apply(ctx, a0)
+}
+
+func (s testApp_local_stub) BatchGet(ctx context.Context, a0 ...string) (r0 []string, err error) {
+	// Update metrics.
+	begin := s.batchGetMetrics.Begin()
+	defer func() { s.batchGetMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "BatchGet", "generate.testApp.BatchGet", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "BatchGet", func(ctx context.Context) (err error) {
+			r0, err = s.impl.BatchGet(ctx, a0...)
+			return err
+		})
+		return
+	}
+	return s.impl.BatchGet(ctx, a0...)
+}
+
 func (s testApp_local_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int, r1 int, err error) {
 	// Update metrics.
 	begin := s.divModMetrics.Begin()
@@ -61,7 +122,7 @@ func (s testApp_local_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "generate.testApp.DivMod", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "DivMod", "generate.testApp.DivMod", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -71,6 +132,13 @@ func (s testApp_local_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int,
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "DivMod", func(ctx context.Context) (err error) {
+			r0, r1, err = s.impl.DivMod(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.DivMod(ctx, a0, a1)
 }
 
@@ -81,7 +149,7 @@ func (s testApp_local_stub) Get(ctx context.Context, a0 string, a1 behaviorType)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "generate.testApp.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Get", "generate.testApp.Get", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -91,6 +159,13 @@ func (s testApp_local_stub) Get(ctx context.Context, a0 string, a1 behaviorType)
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Get(ctx, a0, a1)
 }
 
@@ -101,7 +176,7 @@ func (s testApp_local_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int, e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "generate.testApp.IncPointer", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "IncPointer", "generate.testApp.IncPointer", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -111,21 +186,273 @@ func (s testApp_local_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int, e
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "IncPointer", func(ctx context.Context) (err error) {
+			r0, err = s.impl.IncPointer(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.IncPointer(ctx, a0)
 }
 
+func (s testApp_local_stub) Scale(ctx context.Context, a0 shape, a1 float64) (r0 shape, err error) {
+	// Update metrics.
+	begin := s.scaleMetrics.Begin()
+	defer func() { s.scaleMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Scale", "generate.testApp.Scale", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Scale", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Scale(ctx, a0, a1)
+			return err
+		})
+		return
+	}
+	return s.impl.Scale(ctx, a0, a1)
+}
+
+func (s testApp_local_stub) Settle(ctx context.Context, a0 status) (r0 status, err error) {
+	// Update metrics.
+	begin := s.settleMetrics.Begin()
+	defer func() { s.settleMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Settle", "generate.testApp.Settle", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Settle", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Settle(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Settle(ctx, a0)
+}
+
+func (s testApp_local_stub) Sum(ctx context.Context, a0 []*big.Float) (r0 *big.Float, err error) {
+	// Update metrics.
+	begin := s.sumMetrics.Begin()
+	defer func() { s.sumMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Sum", "generate.testApp.Sum", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Sum", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Sum(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Sum(ctx, a0)
+}
+
+func (s testApp_local_stub) Total(ctx context.Context, a0 []*big.Rat) (r0 *big.Rat, err error) {
+	// Update metrics.
+	begin := s.totalMetrics.Begin()
+	defer func() { s.totalMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Total", "generate.testApp.Total", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Total", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Total(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Total(ctx, a0)
+}
+
 // Client stub implementations.
 
 type testApp_client_stub struct {
 	stub              codegen.Stub
+	applyMetrics      *codegen.MethodMetrics
+	batchGetMetrics   *codegen.MethodMetrics
 	divModMetrics     *codegen.MethodMetrics
 	getMetrics        *codegen.MethodMetrics
 	incPointerMetrics *codegen.MethodMetrics
+	scaleMetrics      *codegen.MethodMetrics
+	settleMetrics     *codegen.MethodMetrics
+	sumMetrics        *codegen.MethodMetrics
+	totalMetrics      *codegen.MethodMetrics
 }
 
 // Check that testApp_client_stub implements the testApp interface.
 var _ testApp = (*testApp_client_stub)(nil)
 
+func (s testApp_client_stub) Apply(ctx context.Context, a0 []string) (r0 transaction, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.applyMetrics.Begin()
+	defer func() { s.applyMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Apply", "generate.testApp.Apply", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.applyMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s testApp_client_stub) BatchGet(ctx context.Context, a0 ...string) (r0 []string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.batchGetMetrics.Begin()
+	defer func() { s.batchGetMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "BatchGet", "generate.testApp.BatchGet", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.batchGetMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += 4
+	for _, v := range a0 {
+		size += (4 + len(v))
+	}
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	if a0 == nil {
+		a0 = []string{}
+	}
+	serviceweaver_enc_slice_string_4af10117(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_string_4af10117(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
 func (s testApp_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int, r1 int, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
@@ -135,7 +462,7 @@ func (s testApp_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "generate.testApp.DivMod", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "DivMod", "generate.testApp.DivMod", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -143,6 +470,7 @@ func (s testApp_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.divModMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -153,6 +481,8 @@ func (s testApp_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 2, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -167,22 +497,28 @@ func (s testApp_client_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int
 	enc.Int(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 2, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	r1 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		r1 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s testApp_client_stub) Get(ctx context.Context, a0 string, a1 behaviorType) (r0 int, err error) {
@@ -194,7 +530,7 @@ func (s testApp_client_stub) Get(ctx context.Context, a0 string, a1 behaviorType
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "generate.testApp.Get", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Get", "generate.testApp.Get", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -202,6 +538,7 @@ func (s testApp_client_stub) Get(ctx context.Context, a0 string, a1 behaviorType
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -212,6 +549,8 @@ func (s testApp_client_stub) Get(ctx context.Context, a0 string, a1 behaviorType
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 3, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -226,21 +565,27 @@ func (s testApp_client_stub) Get(ctx context.Context, a0 string, a1 behaviorType
 	enc.Int((int)(a1))
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 3, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s testApp_client_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int, err error) {
@@ -252,7 +597,7 @@ func (s testApp_client_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int,
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "generate.testApp.IncPointer", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "IncPointer", "generate.testApp.IncPointer", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -260,6 +605,7 @@ func (s testApp_client_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int,
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.incPointerMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -270,6 +616,8 @@ func (s testApp_client_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int,
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 4, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -282,47 +630,299 @@ func (s testApp_client_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int,
 	serviceweaver_enc_ptr_int_98a2a745(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 4, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_int_98a2a745(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_int_98a2a745(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-// Note that "weaver generate" will always generate the error message below.
-// Everything is okay. The error message is only relevant if you see it when
-// you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+func (s testApp_client_stub) Scale(ctx context.Context, a0 shape, a1 float64) (r0 shape, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.scaleMetrics.Begin()
+	defer func() { s.scaleMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
-ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
-github.com/ServiceWeaver/weaver module that you're using. The weaver module
-version can be found in your go.mod file or by running the following command.
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Scale", "generate.testApp.Scale", trace.SpanKindClient)
+	}
 
-    go list -m github.com/ServiceWeaver/weaver
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.scaleMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
 
-We recommend updating the weaver module and the 'weaver generate' command by
-running the following.
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
 
-    go get github.com/ServiceWeaver/weaver@latest
-    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 5, begin, err)
+	}()
 
-Then, re-run 'weaver generate' and re-build your code. If the problem persists,
-please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_shape_94cdd6db(enc, a0)
+	enc.Float64(a1)
+	var shardKey uint64
 
-`)
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 5, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-// Server stub implementations.
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_shape_94cdd6db(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s testApp_client_stub) Settle(ctx context.Context, a0 status) (r0 status, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.settleMetrics.Begin()
+	defer func() { s.settleMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Settle", "generate.testApp.Settle", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.settleMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 6, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.Int((int)(a0))
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 6, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_status_980e747f(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s testApp_client_stub) Sum(ctx context.Context, a0 []*big.Float) (r0 *big.Float, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.sumMetrics.Begin()
+	defer func() { s.sumMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Sum", "generate.testApp.Sum", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.sumMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 7, begin, err)
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_ptr_Float_8924d94a(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 7, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = codegen.DecodeWithMarshaler[*big.Float](dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s testApp_client_stub) Total(ctx context.Context, a0 []*big.Rat) (r0 *big.Rat, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.totalMetrics.Begin()
+	defer func() { s.totalMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Total", "generate.testApp.Total", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.totalMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 8, begin, err)
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_ptr_Rat_fe469d4b(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 8, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.BigRat()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' (devel) (codegen
+version v0.41.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
 
 type testApp_server_stub struct {
 	impl    testApp
@@ -335,36 +935,156 @@ var _ codegen.Server = (*testApp_server_stub)(nil)
 // GetStubFn implements the codegen.Server interface.
 func (s testApp_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
 	switch method {
+	case "Apply":
+		return s.apply
+	case "BatchGet":
+		return s.batchGet
 	case "DivMod":
 		return s.divMod
 	case "Get":
 		return s.get
 	case "IncPointer":
 		return s.incPointer
+	case "Scale":
+		return s.scale
+	case "Settle":
+		return s.settle
+	case "Sum":
+		return s.sum
+	case "Total":
+		return s.total
 	default:
 		return nil
 	}
 }
 
-func (s testApp_server_stub) divMod(ctx context.Context, args []byte) (res []byte, err error) {
+func (s testApp_server_stub) apply(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 []string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Apply", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = serviceweaver_dec_slice_string_4af10117(dec)
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 transaction
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Apply", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Apply(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Apply(ctx, a0)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s testApp_server_stub) batchGet(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 []string
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "BatchGet", r, a0)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = serviceweaver_dec_slice_string_4af10117(dec)
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 []string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "BatchGet", func(ctx context.Context) (err error) {
+			r0, err = s.impl.BatchGet(ctx, a0...)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.BatchGet(ctx, a0...)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s testApp_server_stub) divMod(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
 	var a0 int
-	a0 = dec.Int()
 	var a1 int
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "DivMod", r, a0, a1)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.Int()
 	a1 = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, r1, appErr := s.impl.DivMod(ctx, a0, a1)
+	var r0 int
+	var r1 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "DivMod", func(ctx context.Context) (err error) {
+			r0, r1, err = s.impl.DivMod(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, r1, appErr = s.impl.DivMod(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -375,24 +1095,43 @@ func (s testApp_server_stub) divMod(ctx context.Context, args []byte) (res []byt
 }
 
 func (s testApp_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 behaviorType
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Get", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 behaviorType
 	*(*int)(&a1) = dec.Int()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Get(ctx, a0, a1)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Get", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Get(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Get(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -402,22 +1141,41 @@ func (s testApp_server_stub) get(ctx context.Context, args []byte) (res []byte,
 }
 
 func (s testApp_server_stub) incPointer(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *int
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "IncPointer", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *int
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_int_98a2a745(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.IncPointer(ctx, a0)
+	var r0 *int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "IncPointer", func(ctx context.Context) (err error) {
+			r0, err = s.impl.IncPointer(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.IncPointer(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -426,6 +1184,184 @@ func (s testApp_server_stub) incPointer(ctx context.Context, args []byte) (res [
 	return enc.Data(), nil
 }
 
+func (s testApp_server_stub) scale(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 shape
+	var a1 float64
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Scale", r, a0, a1)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = serviceweaver_dec_shape_94cdd6db(dec)
+	a1 = dec.Float64()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 shape
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Scale", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Scale(ctx, a0, a1)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Scale(ctx, a0, a1)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_shape_94cdd6db(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s testApp_server_stub) settle(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 status
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Settle", r, a0)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = serviceweaver_dec_status_980e747f(dec)
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 status
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Settle", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Settle(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Settle(ctx, a0)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Int((int)(r0))
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s testApp_server_stub) sum(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 []*big.Float
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Sum", r, a0)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = serviceweaver_dec_slice_ptr_Float_8924d94a(dec)
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 *big.Float
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Sum", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Sum(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Sum(ctx, a0)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	codegen.EncodeWithMarshaler[*big.Float](enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s testApp_server_stub) total(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 []*big.Rat
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Total", r, a0)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = serviceweaver_dec_slice_ptr_Rat_fe469d4b(dec)
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 *big.Rat
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/generate/testApp", "Total", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Total(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Total(ctx, a0)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.BigRat(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 // Reflect stub implementations.
 
 type testApp_reflect_stub struct {
@@ -435,6 +1371,16 @@ type testApp_reflect_stub struct {
 // Check that testApp_reflect_stub implements the testApp interface.
 var _ testApp = (*testApp_reflect_stub)(nil)
 
+func (s testApp_reflect_stub) Apply(ctx context.Context, a0 []string) (r0 transaction, err error) {
+	err = s.caller("Apply", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s testApp_reflect_stub) BatchGet(ctx context.Context, a0 ...string) (r0 []string, err error) {
+	err = s.caller("BatchGet", ctx, []any{a0}, []any{&r0})
+	return
+}
+
 func (s testApp_reflect_stub) DivMod(ctx context.Context, a0 int, a1 int) (r0 int, r1 int, err error) {
 	err = s.caller("DivMod", ctx, []any{a0, a1}, []any{&r0, &r1})
 	return
@@ -450,8 +1396,62 @@ func (s testApp_reflect_stub) IncPointer(ctx context.Context, a0 *int) (r0 *int,
 	return
 }
 
+func (s testApp_reflect_stub) Scale(ctx context.Context, a0 shape, a1 float64) (r0 shape, err error) {
+	err = s.caller("Scale", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
+func (s testApp_reflect_stub) Settle(ctx context.Context, a0 status) (r0 status, err error) {
+	err = s.caller("Settle", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s testApp_reflect_stub) Sum(ctx context.Context, a0 []*big.Float) (r0 *big.Float, err error) {
+	err = s.caller("Sum", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s testApp_reflect_stub) Total(ctx context.Context, a0 []*big.Rat) (r0 *big.Rat, err error) {
+	err = s.caller("Total", ctx, []any{a0}, []any{&r0})
+	return
+}
+
 // AutoMarshal implementations.
 
+var _ codegen.AutoMarshal = (*circle)(nil)
+
+type __is_circle[T ~struct {
+	weaver.AutoMarshal
+	Radius float64
+}] struct{}
+
+var _ __is_circle[circle]
+
+func (x *circle) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("circle.WeaverMarshal: nil receiver"))
+	}
+	enc.Float64(x.Radius)
+}
+
+func (x *circle) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("circle.WeaverUnmarshal: nil receiver"))
+	}
+	x.Radius = dec.Float64()
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *circle) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Radius", Value: x.Radius},
+	)
+}
+
 var _ codegen.AutoMarshal = (*customErrorValue)(nil)
 
 type __is_customErrorValue[T ~struct {
@@ -474,10 +1474,185 @@ func (x *customErrorValue) WeaverUnmarshal(dec *codegen.Decoder) {
 	}
 	x.key = dec.String()
 }
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *customErrorValue) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "key", Value: x.key},
+	)
+}
 func init() { codegen.RegisterSerializable[*customErrorValue]() }
 
+var _ codegen.AutoMarshal = (*square)(nil)
+
+type __is_square[T ~struct {
+	weaver.AutoMarshal
+	Side float64
+}] struct{}
+
+var _ __is_square[square]
+
+func (x *square) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("square.WeaverMarshal: nil receiver"))
+	}
+	enc.Float64(x.Side)
+}
+
+func (x *square) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("square.WeaverUnmarshal: nil receiver"))
+	}
+	x.Side = dec.Float64()
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *square) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Side", Value: x.Side},
+	)
+}
+
+var _ codegen.AutoMarshal = (*transaction)(nil)
+
+type __is_transaction[T ~struct {
+	weaver.AutoMarshal
+	Items []transactionItem
+}] struct{}
+
+var _ __is_transaction[transaction]
+
+func (x *transaction) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("transaction.WeaverMarshal: nil receiver"))
+	}
+	serviceweaver_enc_slice_transactionItem_0d0d86c8(enc, x.Items)
+}
+
+func (x *transaction) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("transaction.WeaverUnmarshal: nil receiver"))
+	}
+	x.Items = serviceweaver_dec_slice_transactionItem_0d0d86c8(dec)
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *transaction) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Items", Value: x.Items},
+	)
+}
+
+func serviceweaver_enc_slice_transactionItem_0d0d86c8(enc *codegen.Encoder, arg []transactionItem) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_transactionItem_0d0d86c8(dec *codegen.Decoder) []transactionItem {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := codegen.MakeSlice[transactionItem](dec, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*transactionItem)(nil)
+
+type __is_transactionItem[T ~struct {
+	weaver.AutoMarshal
+	Key string
+	Err error
+}] struct{}
+
+var _ __is_transactionItem[transactionItem]
+
+func (x *transactionItem) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("transactionItem.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Key)
+	enc.Error(x.Err)
+}
+
+func (x *transactionItem) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("transactionItem.WeaverUnmarshal: nil receiver"))
+	}
+	x.Key = dec.String()
+	x.Err = dec.Error()
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *transactionItem) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "Key", Value: x.Key},
+		codegen.DebugField{Name: "Err", Value: x.Err},
+	)
+}
+
+// Enum implementations.
+
+// IsValid returns true if x is equal to one of the status constants.
+func (x status) IsValid() bool {
+	switch x {
+	case pending, settled, failed:
+		return true
+	default:
+		return false
+	}
+}
+
 // Encoding/decoding implementations.
 
+func serviceweaver_enc_slice_string_4af10117(enc *codegen.Encoder, arg []string) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.String(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := codegen.MakeSlice[string](dec, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.String()
+	}
+	return res
+}
+
 func serviceweaver_enc_ptr_int_98a2a745(enc *codegen.Encoder, arg *int) {
 	if arg == nil {
 		enc.Bool(false)
@@ -496,6 +1671,111 @@ func serviceweaver_dec_ptr_int_98a2a745(dec *codegen.Decoder) *int {
 	return &res
 }
 
+func serviceweaver_enc_ptr_circle_5378bb23(enc *codegen.Encoder, arg *circle) {
+	if arg == nil {
+		enc.Bool(false)
+	} else {
+		enc.Bool(true)
+		(*arg).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_ptr_circle_5378bb23(dec *codegen.Decoder) *circle {
+	if !dec.Bool() {
+		return nil
+	}
+	var res circle
+	(&res).WeaverUnmarshal(dec)
+	return &res
+}
+
+func serviceweaver_enc_shape_94cdd6db(enc *codegen.Encoder, arg shape) {
+	switch x := arg.(type) {
+	case nil:
+		enc.Uint32(0)
+	case *circle:
+		enc.Uint32(1)
+		serviceweaver_enc_ptr_circle_5378bb23(enc, x)
+	case square:
+		enc.Uint32(2)
+		(x).WeaverMarshal(enc)
+	default:
+		panic(codegen.UnknownVariantError("shape", x))
+	}
+}
+
+func serviceweaver_dec_shape_94cdd6db(dec *codegen.Decoder) shape {
+	switch tag := dec.Uint32(); tag {
+	case 0:
+		return nil
+	case 1:
+		var res *circle
+		res = serviceweaver_dec_ptr_circle_5378bb23(dec)
+		return res
+	case 2:
+		var res square
+		(&res).WeaverUnmarshal(dec)
+		return res
+	default:
+		panic(codegen.UnknownTagError("shape", tag))
+	}
+}
+
+func serviceweaver_dec_status_980e747f(dec *codegen.Decoder) status {
+	var res status
+	*(*int)(&res) = dec.Int()
+	if !res.IsValid() {
+		panic(codegen.InvalidEnumError("status", res))
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_ptr_Float_8924d94a(enc *codegen.Encoder, arg []*big.Float) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		codegen.EncodeWithMarshaler[*big.Float](enc, arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_ptr_Float_8924d94a(dec *codegen.Decoder) []*big.Float {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := codegen.MakeSlice[*big.Float](dec, n)
+	for i := 0; i < n; i++ {
+		res[i] = codegen.DecodeWithMarshaler[*big.Float](dec)
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_ptr_Rat_fe469d4b(enc *codegen.Encoder, arg []*big.Rat) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.BigRat(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_ptr_Rat_fe469d4b(dec *codegen.Decoder) []*big.Rat {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := codegen.MakeSlice[*big.Rat](dec, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.BigRat()
+	}
+	return res
+}
+
 // Size implementations.
 
 // serviceweaver_size_ptr_int_98a2a745 returns the size (in bytes) of the serialization
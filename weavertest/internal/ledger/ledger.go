@@ -0,0 +1,71 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ledger contains a component that serves an append-only log, used to
+// test fetching entries by offset.
+package ledger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Entry is an entry in the ledger.
+type Entry struct {
+	weaver.AutoMarshal
+	Account string
+	Amount  int
+}
+
+// Ledger is an append-only log of entries. The offset of an entry is its
+// index in the log.
+type Ledger interface {
+	// Append appends the provided entries and returns the offset that
+	// follows them.
+	Append(context.Context, []Entry) (weaver.Offset, error)
+
+	// Fetch returns at most max entries starting at the provided offset,
+	// along with the offset that follows them.
+	Fetch(ctx context.Context, from weaver.Offset, max int) ([]Entry, weaver.Offset, error)
+}
+
+// Retrying an Append that succeeded would append the entries twice.
+var _ weaver.NotRetriable = Ledger.Append
+
+type ledger struct {
+	weaver.Implements[Ledger]
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (l *ledger) Append(_ context.Context, entries []Entry) (weaver.Offset, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entries...)
+	return weaver.Offset(len(l.entries)), nil
+}
+
+func (l *ledger) Fetch(_ context.Context, from weaver.Offset, max int) ([]Entry, weaver.Offset, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if from >= weaver.Offset(len(l.entries)) {
+		return nil, from, nil
+	}
+	end := min(int(from)+max, len(l.entries))
+	entries := make([]Entry, end-int(from))
+	copy(entries, l.entries[from:end])
+	return entries, weaver.Offset(end), nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/google/go-cmp/cmp"
+)
+
+//go:generate ../../../cmd/weaver/weaver generate ./...
+
+func makeEntries(n int) []Entry {
+	entries := make([]Entry, n)
+	for i := range entries {
+		entries[i] = Entry{Account: fmt.Sprint("account", i%3), Amount: i}
+	}
+	return entries
+}
+
+// TestFetch tests that fetching entries incrementally returns every entry
+// exactly once, in order.
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, l Ledger) {
+			want := makeEntries(10)
+			if _, err := l.Append(ctx, want); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []Entry
+			var offset weaver.Offset
+			for {
+				entries, next, err := l.Fetch(ctx, offset, 3)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(entries) == 0 {
+					if next != offset {
+						t.Fatalf("empty fetch: got next offset %d, want %d", next, offset)
+					}
+					break
+				}
+				if want := offset + weaver.Offset(len(entries)); next != want {
+					t.Fatalf("fetch: got next offset %d, want %d", next, want)
+				}
+				got = append(got, entries...)
+				offset = next
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("entries (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestResume tests that a reader that fails midway through reading the log
+// can resume reading from the offset returned by weaver.ReadLog, including
+// entries appended in the meantime.
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, l Ledger) {
+			all := makeEntries(12)
+			if _, err := l.Append(ctx, all[:8]); err != nil {
+				t.Fatal(err)
+			}
+			fetch := func(ctx context.Context, from weaver.Offset) ([]Entry, weaver.Offset, error) {
+				return l.Fetch(ctx, from, 3)
+			}
+
+			// Read until the second batch, which fails.
+			var got []Entry
+			errDisconnect := errors.New("disconnected")
+			batches := 0
+			saved, err := weaver.ReadLog(ctx, 0, fetch, func(entries []Entry, _ weaver.Offset) error {
+				batches++
+				if batches == 2 {
+					return errDisconnect
+				}
+				got = append(got, entries...)
+				return nil
+			})
+			if !errors.Is(err, errDisconnect) {
+				t.Fatalf("ReadLog: got error %v, want %v", err, errDisconnect)
+			}
+			if want := weaver.Offset(3); saved != want {
+				t.Fatalf("ReadLog: got offset %d, want %d", saved, want)
+			}
+
+			// Append more entries and resume from the saved offset.
+			if _, err := l.Append(ctx, all[8:]); err != nil {
+				t.Fatal(err)
+			}
+			saved, err = weaver.ReadLog(ctx, saved, fetch, func(entries []Entry, _ weaver.Offset) error {
+				got = append(got, entries...)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := weaver.Offset(len(all)); saved != want {
+				t.Fatalf("ReadLog: got offset %d, want %d", saved, want)
+			}
+			if diff := cmp.Diff(all, got); diff != "" {
+				t.Fatalf("entries (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -58,7 +58,7 @@ func (s pingPonger_local_stub) Ping(ctx context.Context, a0 *Ping) (r0 *Pong, er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "protos.PingPonger.Ping", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger", "Ping", "protos.PingPonger.Ping", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -68,6 +68,13 @@ func (s pingPonger_local_stub) Ping(ctx context.Context, a0 *Ping) (r0 *Pong, er
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger", "Ping", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Ping(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.Ping(ctx, a0)
 }
 
@@ -90,7 +97,7 @@ func (s pingPonger_client_stub) Ping(ctx context.Context, a0 *Ping) (r0 *Pong, e
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "protos.PingPonger.Ping", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger", "Ping", "protos.PingPonger.Ping", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -98,6 +105,7 @@ func (s pingPonger_client_stub) Ping(ctx context.Context, a0 *Ping) (r0 *Pong, e
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.pingMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -108,6 +116,8 @@ func (s pingPonger_client_stub) Ping(ctx context.Context, a0 *Ping) (r0 *Pong, e
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Encode arguments.
@@ -115,30 +125,36 @@ func (s pingPonger_client_stub) Ping(ctx context.Context, a0 *Ping) (r0 *Pong, e
 	serviceweaver_enc_ptr_Ping_53efca65(enc, a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_ptr_Pong_10ae1a4e(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_ptr_Pong_10ae1a4e(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -176,22 +192,41 @@ func (s pingPonger_server_stub) GetStubFn(method string) func(ctx context.Contex
 }
 
 func (s pingPonger_server_stub) ping(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 *Ping
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger", "Ping", r, a0)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 *Ping
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = serviceweaver_dec_ptr_Ping_53efca65(dec)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Ping(ctx, a0)
+	var r0 *Pong
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/protos/PingPonger", "Ping", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Ping(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Ping(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -0,0 +1,72 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package readonly contains a component with read-only and write methods, and a
+// component that calls it, used to test read-only mode.
+package readonly
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Store is a simple key-value store.
+type Store interface {
+	//weaver:readonly
+	Get(context.Context, string) (string, error)
+
+	//weaver:write
+	Put(context.Context, string, string) error
+}
+
+type store struct {
+	weaver.Implements[Store]
+	mu sync.Mutex
+	kv map[string]string
+}
+
+func (s *store) Init(context.Context) error {
+	s.kv = map[string]string{"foo": "bar"}
+	return nil
+}
+
+func (s *store) Get(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.kv[key], nil
+}
+
+func (s *store) Put(_ context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kv[key] = value
+	return nil
+}
+
+// Writer writes to a Store. It is used to test calls to a Store from a
+// colocated component.
+type Writer interface {
+	Put(context.Context, string, string) error
+}
+
+type writer struct {
+	weaver.Implements[Writer]
+	store weaver.Ref[Store]
+}
+
+func (w *writer) Put(ctx context.Context, key, value string) error {
+	return w.store.Get().Put(ctx, key, value)
+}
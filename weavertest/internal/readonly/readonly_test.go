@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readonly
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+//go:generate ../../../cmd/weaver/weaver generate ./...
+
+const readOnlyConfig = `
+[readonly]
+components = ["github.com/ServiceWeaver/weaver/weavertest/internal/readonly/Store"]
+`
+
+// TestReadOnly tests that write methods are rejected in read-only mode, while
+// read-only methods succeed.
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range weavertest.AllRunners() {
+		runner.Config = readOnlyConfig
+		runner.Test(t, func(t *testing.T, s Store) {
+			got, err := s.Get(ctx, "foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "bar"; got != want {
+				t.Fatalf("Get: got %q, want %q", got, want)
+			}
+
+			err = s.Put(ctx, "foo", "baz")
+			if !errors.Is(err, weaver.ReadOnlyError) {
+				t.Fatalf("Put: got %v, want %v", err, weaver.ReadOnlyError)
+			}
+		})
+	}
+}
+
+// TestReadOnlyColocated tests that write methods are rejected in read-only
+// mode when called by a colocated component.
+func TestReadOnlyColocated(t *testing.T) {
+	ctx := context.Background()
+	colocate := weavertest.Multi
+	colocate.Name = "Colocate"
+	colocate.Config = `
+[serviceweaver]
+colocate = [[
+  "github.com/ServiceWeaver/weaver/weavertest/internal/readonly/Store",
+  "github.com/ServiceWeaver/weaver/weavertest/internal/readonly/Writer",
+]]
+`
+	for _, runner := range []weavertest.Runner{weavertest.Local, colocate} {
+		runner.Config += readOnlyConfig
+		runner.Test(t, func(t *testing.T, w Writer) {
+			err := w.Put(ctx, "foo", "baz")
+			if !errors.Is(err, weaver.ReadOnlyError) {
+				t.Fatalf("Put: got %v, want %v", err, weaver.ReadOnlyError)
+			}
+		})
+	}
+}
+
+// TestReadWrite tests that write methods succeed when not in read-only mode.
+func TestReadWrite(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, s Store) {
+			if err := s.Put(ctx, "foo", "baz"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -22,44 +22,70 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/metadata"
+	"go.opentelemetry.io/otel/baggage"
 )
 
 //go:generate ../../../cmd/weaver/weaver generate
 
 type Source interface {
 	Emit(ctx context.Context, file, msg string) error
+	Pids(ctx context.Context) ([]int, error)
 }
 
 type source struct {
 	weaver.Implements[Source]
-	dst weaver.Ref[Destination]
+	dst  weaver.Ref[Destination] `weaver:"lazy"`
+	dsts weaver.Refs[Destination]
 }
 
 func (s *source) Emit(ctx context.Context, file, msg string) error {
 	return s.dst.Get().Record(ctx, file, msg)
 }
 
+// Pids returns the pid of every replica of Destination.
+func (s *source) Pids(ctx context.Context) ([]int, error) {
+	return weaver.CallAll(ctx, s.dsts, func(ctx context.Context, dst Destination) (int, error) {
+		return dst.Getpid(ctx)
+	})
+}
+
 type Destination interface {
 	Getpid(_ context.Context) (int, error)
 	Record(_ context.Context, file, msg string) error
 	GetAll(_ context.Context, file string) ([]string, error)
+	Open(_ context.Context, file string) (io.ReadCloser, error)
 	RoutedRecord(_ context.Context, file, msg string) error
 	UpdateMetadata(_ context.Context) error
 	GetMetadata(_ context.Context) (map[string]string, error)
+	GetBaggage(_ context.Context) (map[string]string, error)
+	GetDeadline(_ context.Context) (time.Duration, bool, error)
+	Flaky(_ context.Context, key string, failures int, retryable bool) (int, error)
+	Summarize(_ context.Context, file string) (Summary, []string, bool, int, error)
+}
+
+// Summary summarizes the messages recorded in a file.
+type Summary struct {
+	weaver.AutoMarshal
+	File  string
+	Count int
 }
 
 var (
 	_ weaver.NotRetriable = Source.Emit
 	_ weaver.NotRetriable = Destination.Record
 	_ weaver.NotRetriable = Destination.RoutedRecord
+	_ weaver.NotRetriable = Destination.Flaky
 )
 
 type destRouter struct{}
@@ -73,6 +99,7 @@ type destination struct {
 	weaver.WithRouter[destRouter]
 	mu       sync.Mutex
 	metadata map[string]string
+	attempts map[string]int // number of calls to Flaky, by key
 }
 
 var pid = os.Getpid()
@@ -118,6 +145,26 @@ func (d *destination) GetAll(_ context.Context, file string) ([]string, error) {
 	return strings.Split(str, "\n"), nil
 }
 
+// Open returns the contents of the provided file, streamed to the caller.
+func (d *destination) Open(_ context.Context, file string) (io.ReadCloser, error) {
+	return os.Open(file)
+}
+
+// Summarize returns a summary of the messages recorded in the provided file,
+// the messages themselves, whether the file has any messages, and the length of
+// the longest message. It exercises methods with many results.
+func (d *destination) Summarize(ctx context.Context, file string) (Summary, []string, bool, int, error) {
+	msgs, err := d.GetAll(ctx, file)
+	if err != nil {
+		return Summary{}, nil, false, 0, err
+	}
+	longest := 0
+	for _, msg := range msgs {
+		longest = max(longest, len(msg))
+	}
+	return Summary{File: file, Count: len(msgs)}, msgs, len(msgs) > 0, longest, nil
+}
+
 func (d *destination) UpdateMetadata(ctx context.Context) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -133,6 +180,46 @@ func (d *destination) GetMetadata(_ context.Context) (map[string]string, error)
 	return d.metadata, nil
 }
 
+// GetBaggage returns the members of the OpenTelemetry baggage in ctx.
+func (d *destination) GetBaggage(ctx context.Context) (map[string]string, error) {
+	members := map[string]string{}
+	for _, m := range baggage.FromContext(ctx).Members() {
+		members[m.Key()] = m.Value()
+	}
+	return members, nil
+}
+
+// GetDeadline returns the time remaining until the deadline of ctx, if it has
+// one.
+func (d *destination) GetDeadline(ctx context.Context) (time.Duration, bool, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false, nil
+	}
+	return time.Until(deadline), true, nil
+}
+
+// Flaky fails the first failures calls with the provided key, marking the
+// errors as retryable if retryable is true. It returns the number of calls made
+// with the key so far.
+func (d *destination) Flaky(_ context.Context, key string, failures int, retryable bool) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.attempts == nil {
+		d.attempts = map[string]int{}
+	}
+	d.attempts[key]++
+	n := d.attempts[key]
+	if n > failures {
+		return n, nil
+	}
+	err := fmt.Errorf("attempt %d of %q failed", n, key)
+	if retryable {
+		err = weaver.Retryable(err)
+	}
+	return n, err
+}
+
 // Server is a component used to test Service Weaver listener handling.
 // An HTTP server is started when this component is initialized.
 // simple_test.go checks the functionality of the HTTP server by fetching
@@ -141,6 +228,7 @@ type Server interface {
 	Address(context.Context) (string, error)
 	ProxyAddress(context.Context) (string, error)
 	Shutdown(context.Context) error
+	Healthy(context.Context) error
 }
 
 const ServerTestResponse = "hello world"
@@ -151,6 +239,7 @@ type server struct {
 	proxy string
 	hello weaver.Listener
 	srv   *http.Server
+	down  atomic.Bool // has the server been shut down?
 }
 
 func (s *server) Init(ctx context.Context) error {
@@ -174,4 +263,46 @@ func (s *server) Init(ctx context.Context) error {
 
 func (s *server) Address(ctx context.Context) (string, error)      { return s.addr, nil }
 func (s *server) ProxyAddress(ctx context.Context) (string, error) { return s.proxy, nil }
-func (s *server) Shutdown(ctx context.Context) error               { return s.srv.Shutdown(ctx) }
+
+func (s *server) Shutdown(ctx context.Context) error {
+	s.down.Store(true)
+	return s.srv.Shutdown(ctx)
+}
+
+// Healthy returns an error if the server has been shut down.
+func (s *server) Healthy(context.Context) error {
+	if s.down.Load() {
+		return errors.New("server shut down")
+	}
+	return nil
+}
+
+// Greeter is a component whose config can be updated while it runs.
+type Greeter interface {
+	Greet(ctx context.Context, name string) (string, error)
+}
+
+type greeterConfig struct {
+	Greeting string
+}
+
+type greeter struct {
+	weaver.Implements[Greeter]
+	weaver.WithConfig[greeterConfig]
+}
+
+func (g *greeter) Greet(_ context.Context, name string) (string, error) {
+	greeting := g.Config().Greeting
+	if greeting == "" {
+		greeting = "Hello"
+	}
+	return fmt.Sprintf("%s, %s!", greeting, name), nil
+}
+
+// Reconfigure rejects greetings that shout.
+func (g *greeter) Reconfigure(config greeterConfig) error {
+	if strings.ToUpper(config.Greeting) == config.Greeting {
+		return fmt.Errorf("greeting %q shouts", config.Greeting)
+	}
+	return nil
+}
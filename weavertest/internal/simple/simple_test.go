@@ -15,22 +15,32 @@
 package simple_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/internal/traceio"
+	iweaver "github.com/ServiceWeaver/weaver/internal/weaver"
 	"github.com/ServiceWeaver/weaver/metadata"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/retry"
 	"github.com/ServiceWeaver/weaver/weavertest"
 	"github.com/ServiceWeaver/weaver/weavertest/internal/simple"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/baggage"
 )
 
 func TestOneComponent(t *testing.T) {
@@ -105,6 +115,212 @@ func TestContextWithMetadata(t *testing.T) {
 			if !reflect.DeepEqual(want, got) {
 				t.Errorf("unexpected metadata : expecting %v, got %v", want, got)
 			}
+
+			// Propagate metadata added in multiple steps. Verify that the
+			// returned metadata includes every key.
+			ctx = weaver.WithMetadata(context.Background(), map[string]string{"request_id": "1"})
+			ctx = weaver.WithMetadata(ctx, map[string]string{"tenant_id": "2"})
+			if err := updateMetadata(ctx, dst, runner.Name == weavertest.Multi.Name); err != nil {
+				t.Fatal(err)
+			}
+			got, err = dst.GetMetadata(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = map[string]string{"request_id": "1", "tenant_id": "2"}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("unexpected metadata : expecting %v, got %v", want, got)
+			}
+			if got := weaver.MetadataFromContext(ctx); !reflect.DeepEqual(want, got) {
+				t.Errorf("unexpected local metadata : expecting %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestContextWithBaggage(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			// Do not propagate any baggage. Verify that no baggage is received.
+			got, err := dst.GetBaggage(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 0 {
+				t.Errorf("unexpected baggage: got %v, want none", got)
+			}
+
+			// Propagate baggage. Verify that the same baggage is received.
+			want := map[string]string{"tenant": "acme", "user": "alice"}
+			var members []baggage.Member
+			for k, v := range want {
+				m, err := baggage.NewMember(k, v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				members = append(members, m)
+			}
+			b, err := baggage.New(members...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := baggage.ContextWithBaggage(context.Background(), b)
+			got, err = dst.GetBaggage(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("unexpected baggage: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeadlinePropagation(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			// Call without a deadline. Verify that the callee has no deadline.
+			_, ok, err := dst.GetDeadline(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok {
+				t.Error("unexpected deadline")
+			}
+
+			// Call with a deadline. Verify that the callee observes a
+			// deadline that is no later than the caller's.
+			const timeout = time.Minute
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			remaining, ok, err := dst.GetDeadline(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("missing deadline")
+			}
+			if remaining <= 0 || remaining > timeout {
+				t.Errorf("remaining time until deadline: got %v, want in (0, %v]", remaining, timeout)
+			}
+		})
+	}
+}
+
+func TestMultipleResults(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			ctx := context.Background()
+			file := filepath.Join(t.TempDir(), "messages")
+			for _, msg := range []string{"a", "bbb", "cc"} {
+				if err := dst.Record(ctx, file, msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Every result should be decoded into its own position.
+			summary, msgs, nonEmpty, longest, err := dst.Summarize(ctx, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (simple.Summary{File: file, Count: 3}); summary != want {
+				t.Errorf("summary: got %+v, want %+v", summary, want)
+			}
+			if want := []string{"a", "bbb", "cc"}; !reflect.DeepEqual(msgs, want) {
+				t.Errorf("messages: got %v, want %v", msgs, want)
+			}
+			if !nonEmpty {
+				t.Error("non-empty: got false, want true")
+			}
+			if longest != 3 {
+				t.Errorf("longest: got %d, want 3", longest)
+			}
+
+			// The error is always the last result.
+			if _, _, _, _, err := dst.Summarize(ctx, filepath.Join(t.TempDir(), "missing")); err == nil {
+				t.Error("unexpected success summarizing a missing file")
+			}
+		})
+	}
+}
+
+func TestStreamedResult(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			ctx := context.Background()
+			file := filepath.Join(t.TempDir(), "messages")
+			for i := 0; i < 200; i++ {
+				if err := dst.Record(ctx, file, strings.Repeat(strconv.Itoa(i%10), 1000)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The whole file should be streamed, across many chunks.
+			r, err := dst.Open(ctx, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("streamed %d bytes, want %d", len(got), len(want))
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// A stream can be closed before it is read in full.
+			r, err = dst.Open(ctx, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prefix := make([]byte, 10)
+			if _, err := io.ReadFull(r, prefix); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := dst.Open(ctx, filepath.Join(t.TempDir(), "missing")); err == nil {
+				t.Error("unexpected success opening a missing file")
+			}
+		})
+	}
+}
+
+func init() {
+	// Reject the calls to Destination that carry the "intercept" metadata key.
+	// The interceptor is registered in init so that it is registered in every
+	// process that hosts Destination.
+	weaver.Intercept[simple.Destination](func(ctx context.Context, info weaver.CallInfo, next func(context.Context) error) error {
+		if _, ok := weaver.MetadataFromContext(ctx)["intercept"]; ok {
+			return fmt.Errorf("intercepted %s.%s", info.Component, info.Method)
+		}
+		return next(ctx)
+	})
+}
+
+func TestIntercept(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			ctx := context.Background()
+			if _, err := dst.Getpid(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx = weaver.WithMetadata(ctx, map[string]string{"intercept": "true"})
+			_, _, _, _, err := dst.Summarize(ctx, "file")
+			want := "intercepted github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination.Summarize"
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("Summarize: got error %v, want %q", err, want)
+			}
 		})
 	}
 }
@@ -113,9 +329,20 @@ type fakeDest struct{ file, msg string }
 
 func (f *fakeDest) Getpid(context.Context) (int, error)                    { return 100, nil }
 func (f *fakeDest) GetAll(context.Context, string) ([]string, error)       { return nil, nil }
+func (f *fakeDest) Open(context.Context, string) (io.ReadCloser, error)    { return nil, nil }
 func (f *fakeDest) RoutedRecord(context.Context, string, string) error     { return nil }
 func (f *fakeDest) UpdateMetadata(context.Context) error                   { return nil }
 func (f *fakeDest) GetMetadata(context.Context) (map[string]string, error) { return nil, nil }
+func (f *fakeDest) GetBaggage(context.Context) (map[string]string, error)  { return nil, nil }
+func (f *fakeDest) GetDeadline(context.Context) (time.Duration, bool, error) {
+	return 0, false, nil
+}
+func (f *fakeDest) Flaky(context.Context, string, int, bool) (int, error) {
+	return 0, nil
+}
+func (f *fakeDest) Summarize(context.Context, string) (simple.Summary, []string, bool, int, error) {
+	return simple.Summary{}, nil, false, 0, nil
+}
 func (f *fakeDest) Record(ctx context.Context, file, msg string) error {
 	f.file = file
 	f.msg = msg
@@ -135,6 +362,130 @@ func TestFake(t *testing.T) {
 	}
 }
 
+func TestInjectFault(t *testing.T) {
+	// Fail the first two calls to Destination.Record, and let the rest through.
+	injected := errors.New("injected fault")
+	for _, runner := range []weavertest.Runner{weavertest.RPC, weavertest.Multi} {
+		var calls atomic.Int64
+		runner.Faults = append(runner.Faults, weavertest.InjectFault[simple.Destination]("Record", func() error {
+			if calls.Add(1) <= 2 {
+				return injected
+			}
+			return nil
+		}))
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			ctx := context.Background()
+			file := filepath.Join(t.TempDir(), "dst.txt")
+			for i := 0; i < 2; i++ {
+				err := dst.Record(ctx, file, "msg")
+				if !errors.Is(err, weaver.RemoteCallError) || !errors.Is(err, injected) {
+					t.Fatalf("Record: got %v, want %v and %v", err, weaver.RemoteCallError, injected)
+				}
+			}
+			if err := dst.Record(ctx, file, "msg"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := dst.GetAll(ctx, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"msg"}; !reflect.DeepEqual(want, got) {
+				t.Fatalf("GetAll: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	// Pin the adaptive limit on calls to Destination and check that the
+	// calling weavelet installs a limiter with it.
+	const dst = "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination"
+	runner := weavertest.Multi
+	runner.Config = `
+		[adaptive_concurrency]
+		"` + dst + `" = {initial_limit = 7, min_limit = 7, max_limit = 7}
+	`
+	runner.Test(t, func(t *testing.T, d simple.Destination) {
+		ctx := context.Background()
+		file := filepath.Join(t.TempDir(), "dst.txt")
+		for i := 0; i < 10; i++ {
+			if err := d.Record(ctx, file, "msg"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, snap := range metrics.Snapshot() {
+			if snap.Name == "serviceweaver_adaptive_concurrency_limit" && snap.Labels["component"] == dst {
+				if got, want := snap.Value, 7.0; got != want {
+					t.Fatalf("adaptive concurrency limit: got %v, want %v", got, want)
+				}
+				return
+			}
+		}
+		t.Fatal("adaptive concurrency limit not exported")
+	})
+}
+
+func TestRetryableErrors(t *testing.T) {
+	// Destination.Flaky is not retriable, but remote calls that return a
+	// retryable error should be retried anyway.
+	for _, runner := range []weavertest.Runner{weavertest.RPC, weavertest.Multi} {
+		runner.Test(t, func(t *testing.T, dst simple.Destination) {
+			ctx := context.Background()
+
+			// A retryable error is retried until the call succeeds.
+			n, err := dst.Flaky(ctx, "retryable", 2, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := 3; n != want {
+				t.Errorf("retryable: got %d attempts, want %d", n, want)
+			}
+
+			// A non-retryable error is returned right away.
+			n, err = dst.Flaky(ctx, "fatal", 2, false)
+			if err == nil || errors.Is(err, weaver.RemoteCallError) {
+				t.Fatalf("fatal: got %v, want application error", err)
+			}
+			if want := 1; n != want {
+				t.Errorf("fatal: got %d attempts, want %d", n, want)
+			}
+
+			// A call that keeps returning retryable errors eventually gives up.
+			n, err = dst.Flaky(ctx, "forever", 1000, true)
+			if err == nil {
+				t.Fatal("forever: unexpected success")
+			}
+			if n >= 1000 {
+				t.Errorf("forever: got %d attempts, want fewer", n)
+			}
+		})
+	}
+}
+
+func TestRefs(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, src simple.Source) {
+			pids, err := src.Pids(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every replica of Destination should be called exactly once.
+			want := 1
+			if runner.Name == weavertest.Multi.Name {
+				want = 2
+			}
+			distinct := map[int]bool{}
+			for _, pid := range pids {
+				distinct[pid] = true
+			}
+			if len(pids) != want || len(distinct) != want {
+				t.Fatalf("got pids %v, want %d distinct pids", pids, want)
+			}
+		})
+	}
+}
+
 func TestTwoComponents(t *testing.T) {
 	// Add a list of items to a component (dst) from another component (src). Verify that
 	// dst updates the state accordingly.
@@ -279,3 +630,224 @@ func BenchmarkTracedCall(b *testing.B) {
 		}
 	})
 }
+
+func TestListComponents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wlet, err := iweaver.NewSingleWeavelet(ctx, codegen.Registered(), iweaver.SingleWeaveletOptions{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = iweaver.NewContext(ctx, wlet)
+
+	// list returns the components in the simple package, by name.
+	list := func() map[string]weaver.ComponentInfo {
+		t.Helper()
+		infos, err := weaver.ListComponents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]weaver.ComponentInfo{}
+		for _, info := range infos {
+			if name, ok := strings.CutPrefix(info.Name, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/"); ok {
+				got[name] = info
+			}
+		}
+		return got
+	}
+
+	got := list()
+	for _, name := range []string{"Source", "Destination", "Server"} {
+		if !got[name].Healthy {
+			t.Errorf("%s: got unhealthy (%v), want healthy", name, got[name].Err)
+		}
+	}
+	if want := []string{"Emit", "Pids"}; !reflect.DeepEqual(got["Source"].Methods, want) {
+		t.Errorf("Source methods: got %v, want %v", got["Source"].Methods, want)
+	}
+	if want := []string{"Address", "Healthy", "ProxyAddress", "Shutdown"}; !reflect.DeepEqual(got["Server"].Methods, want) {
+		t.Errorf("Server methods: got %v, want %v", got["Server"].Methods, want)
+	}
+
+	// Shut down the server. It should now report that it is unhealthy.
+	srv, err := wlet.GetIntf(reflect.TypeOf((*simple.Server)(nil)).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.(simple.Server).Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if info := list()["Server"]; info.Healthy || info.Err == nil {
+		t.Errorf("Server: got healthy, want unhealthy")
+	}
+}
+
+func TestOnHealthChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config := `
+[health_polling]
+interval = "10ms"
+`
+	wlet, err := iweaver.NewSingleWeavelet(ctx, codegen.Registered(), iweaver.SingleWeaveletOptions{Config: config, Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = iweaver.NewContext(ctx, wlet)
+
+	type transition struct{ old, new weaver.HealthStatus }
+	transitions := make(chan transition, 10)
+	const name = "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server"
+	if err := weaver.OnHealthChange(ctx, name, func(old, new weaver.HealthStatus) {
+		transitions <- transition{old, new}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	next := func() transition {
+		t.Helper()
+		select {
+		case tr := <-transitions:
+			return tr
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for a health transition")
+			return transition{}
+		}
+	}
+
+	if got, want := next(), (transition{weaver.HealthUnknown, weaver.HealthHealthy}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Shut down the server. It should now report that it is unhealthy.
+	srv, err := wlet.GetIntf(reflect.TypeOf((*simple.Server)(nil)).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.(simple.Server).Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := next(), (transition{weaver.HealthHealthy, weaver.HealthUnhealthy}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Components without a Healthy method can't be watched.
+	const source = "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source"
+	if err := weaver.OnHealthChange(ctx, source, func(weaver.HealthStatus, weaver.HealthStatus) {}); err == nil {
+		t.Error("unexpected success watching a component without a Healthy method")
+	}
+}
+
+func TestListComponentsWithoutWeavelet(t *testing.T) {
+	if _, err := weaver.ListComponents(context.Background()); err == nil {
+		t.Fatal("unexpected success listing components outside weaver.Run")
+	}
+}
+
+func TestInspect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wlet, err := iweaver.NewSingleWeavelet(ctx, codegen.Registered(), iweaver.SingleWeaveletOptions{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = iweaver.NewContext(ctx, wlet)
+
+	descs, err := weaver.Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const prefix = "github.com/ServiceWeaver/weaver/weavertest/internal/simple/"
+	var source weaver.ComponentDescription
+	for _, desc := range descs {
+		if desc.Name == prefix+"Source" {
+			source = desc
+		}
+	}
+	want := []weaver.MethodDescription{
+		{Name: "Emit", Signature: "func(context.Context, string, string) error"},
+		{Name: "Pids", Signature: "func(context.Context) ([]int, error)"},
+	}
+	if !reflect.DeepEqual(source.Methods, want) {
+		t.Errorf("Source methods: got %v, want %v", source.Methods, want)
+	}
+	if got, want := source.Placement, weaver.PlacementLocal; got != want {
+		t.Errorf("Source placement: got %v, want %v", got, want)
+	}
+	if !strings.Contains(source.RefData, prefix+"Source→"+prefix+"Destination") {
+		t.Errorf("Source RefData: got %q, want an edge to Destination", source.RefData)
+	}
+
+	if _, err := weaver.Inspect(context.Background()); err == nil {
+		t.Error("unexpected success inspecting components outside weaver.Run")
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const greeter = "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter"
+	config := func(greeting string) string {
+		return fmt.Sprintf("[%q]\ngreeting = %s\n", greeter, greeting)
+	}
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "weaver.toml")
+	write := func(greeting string) {
+		// Write the file atomically, so that the weavelet never sees it empty.
+		t.Helper()
+		tmp := filepath.Join(dir, "weaver.toml.tmp")
+		if err := os.WriteFile(tmp, []byte(config(greeting)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`"Hi"`)
+	wlet, err := iweaver.NewSingleWeavelet(ctx, codegen.Registered(), iweaver.SingleWeaveletOptions{
+		ConfigFilename: filename,
+		Config:         config(`"Hi"`),
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	intf, err := wlet.GetIntf(reflect.TypeOf((*simple.Greeter)(nil)).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := intf.(simple.Greeter)
+	greet := func() string {
+		t.Helper()
+		got, err := g.Greet(ctx, "Alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if got, want := greet(), "Hi, Alice!"; got != want {
+		t.Fatalf("Greet: got %q, want %q", got, want)
+	}
+
+	// Update the config file. The greeter should pick up the new greeting.
+	write(`"Howdy"`)
+	for r := retry.Begin(); ; {
+		if greet() == "Howdy, Alice!" {
+			break
+		}
+		if !r.Continue(ctx) {
+			t.Fatalf("greeter didn't pick up the new greeting: %v", ctx.Err())
+		}
+	}
+
+	// Reload the config with an invalid greeting and with a greeting that the
+	// greeter rejects. The greeter should keep its current greeting.
+	for _, greeting := range []string{"42", `"HEY"`} {
+		if err := wlet.Reload(config(greeting)); err == nil {
+			t.Errorf("Reload with greeting %s: unexpected success", greeting)
+		}
+		if got, want := greet(), "Howdy, Alice!"; got != want {
+			t.Errorf("Greet with greeting %s: got %q, want %q", greeting, got, want)
+		}
+	}
+}
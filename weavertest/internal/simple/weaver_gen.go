@@ -6,11 +6,16 @@ package simple
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"io"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 func init() {
@@ -19,12 +24,12 @@ func init() {
 		Iface:   reflect.TypeOf((*Destination)(nil)).Elem(),
 		Impl:    reflect.TypeOf(destination{}),
 		Routed:  true,
-		NoRetry: []int{3, 4},
+		NoRetry: []int{0, 7, 8},
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return destination_local_stub{impl: impl.(Destination), tracer: tracer, getAllMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetAll", Remote: false, Generated: true}), getMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetMetadata", Remote: false, Generated: true}), getpidMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Getpid", Remote: false, Generated: true}), recordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Record", Remote: false, Generated: true}), routedRecordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "RoutedRecord", Remote: false, Generated: true}), updateMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "UpdateMetadata", Remote: false, Generated: true})}
+			return destination_local_stub{impl: impl.(Destination), tracer: tracer, flakyMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Flaky", Remote: false, Generated: true}), getAllMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetAll", Remote: false, Generated: true}), getBaggageMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetBaggage", Remote: false, Generated: true}), getDeadlineMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetDeadline", Remote: false, Generated: true}), getMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetMetadata", Remote: false, Generated: true}), getpidMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Getpid", Remote: false, Generated: true}), openMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Open", Remote: false, Generated: true}), recordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Record", Remote: false, Generated: true}), routedRecordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "RoutedRecord", Remote: false, Generated: true}), summarizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Summarize", Remote: false, Generated: true}), updateMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "UpdateMetadata", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return destination_client_stub{stub: stub, getAllMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetAll", Remote: true, Generated: true}), getMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetMetadata", Remote: true, Generated: true}), getpidMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Getpid", Remote: true, Generated: true}), recordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Record", Remote: true, Generated: true}), routedRecordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "RoutedRecord", Remote: true, Generated: true}), updateMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "UpdateMetadata", Remote: true, Generated: true})}
+			return destination_client_stub{stub: stub, flakyMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Flaky", Remote: true, Generated: true}), getAllMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetAll", Remote: true, Generated: true}), getBaggageMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetBaggage", Remote: true, Generated: true}), getDeadlineMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetDeadline", Remote: true, Generated: true}), getMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "GetMetadata", Remote: true, Generated: true}), getpidMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Getpid", Remote: true, Generated: true}), openMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Open", Remote: true, Generated: true}), recordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Record", Remote: true, Generated: true}), routedRecordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "RoutedRecord", Remote: true, Generated: true}), summarizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "Summarize", Remote: true, Generated: true}), updateMetadataMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", Method: "UpdateMetadata", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return destination_server_stub{impl: impl.(Destination), addLoad: addLoad}
@@ -34,16 +39,34 @@ func init() {
 		},
 		RefData: "",
 	})
+	codegen.Register(codegen.Registration{
+		Name:  "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter",
+		Iface: reflect.TypeOf((*Greeter)(nil)).Elem(),
+		Impl:  reflect.TypeOf(greeter{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return greeter_local_stub{impl: impl.(Greeter), tracer: tracer, greetMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", Method: "Greet", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return greeter_client_stub{stub: stub, greetMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", Method: "Greet", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return greeter_server_stub{impl: impl.(Greeter), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return greeter_reflect_stub{caller: caller}
+		},
+		RefData: "",
+	})
 	codegen.Register(codegen.Registration{
 		Name:      "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server",
 		Iface:     reflect.TypeOf((*Server)(nil)).Elem(),
 		Impl:      reflect.TypeOf(server{}),
 		Listeners: []string{"hello"},
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return server_local_stub{impl: impl.(Server), tracer: tracer, addressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Address", Remote: false, Generated: true}), proxyAddressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "ProxyAddress", Remote: false, Generated: true}), shutdownMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Shutdown", Remote: false, Generated: true})}
+			return server_local_stub{impl: impl.(Server), tracer: tracer, addressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Address", Remote: false, Generated: true}), healthyMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Healthy", Remote: false, Generated: true}), proxyAddressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "ProxyAddress", Remote: false, Generated: true}), shutdownMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Shutdown", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return server_client_stub{stub: stub, addressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Address", Remote: true, Generated: true}), proxyAddressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "ProxyAddress", Remote: true, Generated: true}), shutdownMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Shutdown", Remote: true, Generated: true})}
+			return server_client_stub{stub: stub, addressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Address", Remote: true, Generated: true}), healthyMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Healthy", Remote: true, Generated: true}), proxyAddressMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "ProxyAddress", Remote: true, Generated: true}), shutdownMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", Method: "Shutdown", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return server_server_stub{impl: impl.(Server), addLoad: addLoad}
@@ -59,10 +82,10 @@ func init() {
 		Impl:    reflect.TypeOf(source{}),
 		NoRetry: []int{0},
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return source_local_stub{impl: impl.(Source), tracer: tracer, emitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", Method: "Emit", Remote: false, Generated: true})}
+			return source_local_stub{impl: impl.(Source), tracer: tracer, emitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", Method: "Emit", Remote: false, Generated: true}), pidsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", Method: "Pids", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return source_client_stub{stub: stub, emitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", Method: "Emit", Remote: true, Generated: true})}
+			return source_client_stub{stub: stub, emitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", Method: "Emit", Remote: true, Generated: true}), pidsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", Method: "Pids", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return source_server_stub{impl: impl.(Source), addLoad: addLoad}
@@ -70,17 +93,19 @@ func init() {
 		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
 			return source_reflect_stub{caller: caller}
 		},
-		RefData: "⟦bf914175:wEaVeReDgE:github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source→github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination⟧\n",
+		RefData: "⟦bf914175:wEaVeReDgE:github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source→github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination⟧\n⟦bf914175:wEaVeReDgE:github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source→github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination⟧\n",
 	})
 }
 
 // weaver.InstanceOf checks.
 var _ weaver.InstanceOf[Destination] = (*destination)(nil)
+var _ weaver.InstanceOf[Greeter] = (*greeter)(nil)
 var _ weaver.InstanceOf[Server] = (*server)(nil)
 var _ weaver.InstanceOf[Source] = (*source)(nil)
 
 // weaver.Router checks.
 var _ weaver.RoutedBy[destRouter] = (*destination)(nil)
+var _ weaver.Unrouted = (*greeter)(nil)
 var _ weaver.Unrouted = (*server)(nil)
 var _ weaver.Unrouted = (*source)(nil)
 
@@ -92,17 +117,27 @@ type __destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_we
 
 type __destination_destRouter_embedding struct{}
 
+func (__destination_destRouter_embedding) Flaky()          {}
 func (__destination_destRouter_embedding) GetAll()         {}
+func (__destination_destRouter_embedding) GetBaggage()     {}
+func (__destination_destRouter_embedding) GetDeadline()    {}
 func (__destination_destRouter_embedding) GetMetadata()    {}
 func (__destination_destRouter_embedding) Getpid()         {}
+func (__destination_destRouter_embedding) Open()           {}
 func (__destination_destRouter_embedding) Record()         {}
+func (__destination_destRouter_embedding) Summarize()      {}
 func (__destination_destRouter_embedding) UpdateMetadata() {}
 
 var _ func(_ context.Context, file string, msg string) string = (&destRouter{}).RoutedRecord                         // routed
+var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Flaky          // unrouted
 var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).GetAll         // unrouted
+var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).GetBaggage     // unrouted
+var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).GetDeadline    // unrouted
 var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).GetMetadata    // unrouted
 var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Getpid         // unrouted
+var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Open           // unrouted
 var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Record         // unrouted
+var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Summarize      // unrouted
 var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).UpdateMetadata // unrouted
 
 // Local stub implementations.
@@ -110,17 +145,49 @@ var _ = (&__destination_destRouter_if_youre_seeing_this_you_probably_forgot_to_r
 type destination_local_stub struct {
 	impl                  Destination
 	tracer                trace.Tracer
+	flakyMetrics          *codegen.MethodMetrics
 	getAllMetrics         *codegen.MethodMetrics
+	getBaggageMetrics     *codegen.MethodMetrics
+	getDeadlineMetrics    *codegen.MethodMetrics
 	getMetadataMetrics    *codegen.MethodMetrics
 	getpidMetrics         *codegen.MethodMetrics
+	openMetrics           *codegen.MethodMetrics
 	recordMetrics         *codegen.MethodMetrics
 	routedRecordMetrics   *codegen.MethodMetrics
+	summarizeMetrics      *codegen.MethodMetrics
 	updateMetadataMetrics *codegen.MethodMetrics
 }
 
 // Check that destination_local_stub implements the Destination interface.
 var _ Destination = (*destination_local_stub)(nil)
 
+func (s destination_local_stub) Flaky(ctx context.Context, a0 string, a1 int, a2 bool) (r0 int, err error) {
+	// Update metrics.
+	begin := s.flakyMetrics.Begin()
+	defer func() { s.flakyMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Flaky", "simple.Destination.Flaky", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Flaky", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Flaky(ctx, a0, a1, a2)
+			return err
+		})
+		return
+	}
+	return s.impl.Flaky(ctx, a0, a1, a2)
+}
+
 func (s destination_local_stub) GetAll(ctx context.Context, a0 string) (r0 []string, err error) {
 	// Update metrics.
 	begin := s.getAllMetrics.Begin()
@@ -128,7 +195,7 @@ func (s destination_local_stub) GetAll(ctx context.Context, a0 string) (r0 []str
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Destination.GetAll", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetAll", "simple.Destination.GetAll", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -138,9 +205,70 @@ func (s destination_local_stub) GetAll(ctx context.Context, a0 string) (r0 []str
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetAll", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetAll(ctx, a0)
+			return err
+		})
+		return
+	}
 	return s.impl.GetAll(ctx, a0)
 }
 
+func (s destination_local_stub) GetBaggage(ctx context.Context) (r0 map[string]string, err error) {
+	// Update metrics.
+	begin := s.getBaggageMetrics.Begin()
+	defer func() { s.getBaggageMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetBaggage", "simple.Destination.GetBaggage", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetBaggage", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetBaggage(ctx)
+			return err
+		})
+		return
+	}
+	return s.impl.GetBaggage(ctx)
+}
+
+func (s destination_local_stub) GetDeadline(ctx context.Context) (r0 time.Duration, r1 bool, err error) {
+	// Update metrics.
+	begin := s.getDeadlineMetrics.Begin()
+	defer func() { s.getDeadlineMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetDeadline", "simple.Destination.GetDeadline", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetDeadline", func(ctx context.Context) (err error) {
+			r0, r1, err = s.impl.GetDeadline(ctx)
+			return err
+		})
+		return
+	}
+	return s.impl.GetDeadline(ctx)
+}
+
 func (s destination_local_stub) GetMetadata(ctx context.Context) (r0 map[string]string, err error) {
 	// Update metrics.
 	begin := s.getMetadataMetrics.Begin()
@@ -148,7 +276,7 @@ func (s destination_local_stub) GetMetadata(ctx context.Context) (r0 map[string]
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Destination.GetMetadata", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetMetadata", "simple.Destination.GetMetadata", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -158,6 +286,13 @@ func (s destination_local_stub) GetMetadata(ctx context.Context) (r0 map[string]
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetMetadata", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetMetadata(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.GetMetadata(ctx)
 }
 
@@ -168,7 +303,7 @@ func (s destination_local_stub) Getpid(ctx context.Context) (r0 int, err error)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Destination.Getpid", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Getpid", "simple.Destination.Getpid", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -178,9 +313,43 @@ func (s destination_local_stub) Getpid(ctx context.Context) (r0 int, err error)
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Getpid", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Getpid(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.Getpid(ctx)
 }
 
+func (s destination_local_stub) Open(ctx context.Context, a0 string) (r0 io.ReadCloser, err error) {
+	// Update metrics.
+	begin := s.openMetrics.Begin()
+	defer func() { s.openMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Open", "simple.Destination.Open", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Open", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Open(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Open(ctx, a0)
+}
+
 func (s destination_local_stub) Record(ctx context.Context, a0 string, a1 string) (err error) {
 	// Update metrics.
 	begin := s.recordMetrics.Begin()
@@ -188,7 +357,7 @@ func (s destination_local_stub) Record(ctx context.Context, a0 string, a1 string
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Destination.Record", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Record", "simple.Destination.Record", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -198,6 +367,13 @@ func (s destination_local_stub) Record(ctx context.Context, a0 string, a1 string
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Record", func(ctx context.Context) (err error) {
+			err = s.impl.Record(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Record(ctx, a0, a1)
 }
 
@@ -208,7 +384,7 @@ func (s destination_local_stub) RoutedRecord(ctx context.Context, a0 string, a1
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Destination.RoutedRecord", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "RoutedRecord", "simple.Destination.RoutedRecord", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -218,9 +394,43 @@ func (s destination_local_stub) RoutedRecord(ctx context.Context, a0 string, a1
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "RoutedRecord", func(ctx context.Context) (err error) {
+			err = s.impl.RoutedRecord(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.RoutedRecord(ctx, a0, a1)
 }
 
+func (s destination_local_stub) Summarize(ctx context.Context, a0 string) (r0 Summary, r1 []string, r2 bool, r3 int, err error) {
+	// Update metrics.
+	begin := s.summarizeMetrics.Begin()
+	defer func() { s.summarizeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Summarize", "simple.Destination.Summarize", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Summarize", func(ctx context.Context) (err error) {
+			r0, r1, r2, r3, err = s.impl.Summarize(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Summarize(ctx, a0)
+}
+
 func (s destination_local_stub) UpdateMetadata(ctx context.Context) (err error) {
 	// Update metrics.
 	begin := s.updateMetadataMetrics.Begin()
@@ -228,7 +438,7 @@ func (s destination_local_stub) UpdateMetadata(ctx context.Context) (err error)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Destination.UpdateMetadata", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "UpdateMetadata", "simple.Destination.UpdateMetadata", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -238,13 +448,57 @@ func (s destination_local_stub) UpdateMetadata(ctx context.Context) (err error)
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "UpdateMetadata", func(ctx context.Context) (err error) {
+			err = s.impl.UpdateMetadata(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.UpdateMetadata(ctx)
 }
 
+type greeter_local_stub struct {
+	impl         Greeter
+	tracer       trace.Tracer
+	greetMetrics *codegen.MethodMetrics
+}
+
+// Check that greeter_local_stub implements the Greeter interface.
+var _ Greeter = (*greeter_local_stub)(nil)
+
+func (s greeter_local_stub) Greet(ctx context.Context, a0 string) (r0 string, err error) {
+	// Update metrics.
+	begin := s.greetMetrics.Begin()
+	defer func() { s.greetMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", "Greet", "simple.Greeter.Greet", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", "Greet", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Greet(ctx, a0)
+			return err
+		})
+		return
+	}
+	return s.impl.Greet(ctx, a0)
+}
+
 type server_local_stub struct {
 	impl                Server
 	tracer              trace.Tracer
 	addressMetrics      *codegen.MethodMetrics
+	healthyMetrics      *codegen.MethodMetrics
 	proxyAddressMetrics *codegen.MethodMetrics
 	shutdownMetrics     *codegen.MethodMetrics
 }
@@ -259,7 +513,7 @@ func (s server_local_stub) Address(ctx context.Context) (r0 string, err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Server.Address", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Address", "simple.Server.Address", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -269,9 +523,43 @@ func (s server_local_stub) Address(ctx context.Context) (r0 string, err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Address", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Address(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.Address(ctx)
 }
 
+func (s server_local_stub) Healthy(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthyMetrics.Begin()
+	defer func() { s.healthyMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Healthy", "simple.Server.Healthy", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Healthy", func(ctx context.Context) (err error) {
+			err = s.impl.Healthy(ctx)
+			return err
+		})
+		return
+	}
+	return s.impl.Healthy(ctx)
+}
+
 func (s server_local_stub) ProxyAddress(ctx context.Context) (r0 string, err error) {
 	// Update metrics.
 	begin := s.proxyAddressMetrics.Begin()
@@ -279,7 +567,7 @@ func (s server_local_stub) ProxyAddress(ctx context.Context) (r0 string, err err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Server.ProxyAddress", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "ProxyAddress", "simple.Server.ProxyAddress", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -289,6 +577,13 @@ func (s server_local_stub) ProxyAddress(ctx context.Context) (r0 string, err err
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "ProxyAddress", func(ctx context.Context) (err error) {
+			r0, err = s.impl.ProxyAddress(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.ProxyAddress(ctx)
 }
 
@@ -299,7 +594,7 @@ func (s server_local_stub) Shutdown(ctx context.Context) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Server.Shutdown", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Shutdown", "simple.Server.Shutdown", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -309,6 +604,13 @@ func (s server_local_stub) Shutdown(ctx context.Context) (err error) {
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Shutdown", func(ctx context.Context) (err error) {
+			err = s.impl.Shutdown(ctx)
+			return err
+		})
+		return
+	}
 	return s.impl.Shutdown(ctx)
 }
 
@@ -316,6 +618,7 @@ type source_local_stub struct {
 	impl        Source
 	tracer      trace.Tracer
 	emitMetrics *codegen.MethodMetrics
+	pidsMetrics *codegen.MethodMetrics
 }
 
 // Check that source_local_stub implements the Source interface.
@@ -328,7 +631,7 @@ func (s source_local_stub) Emit(ctx context.Context, a0 string, a1 string) (err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "simple.Source.Emit", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Emit", "simple.Source.Emit", trace.SpanKindInternal)
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -338,34 +641,73 @@ func (s source_local_stub) Emit(ctx context.Context, a0 string, a1 string) (err
 		}()
 	}
 
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Emit", func(ctx context.Context) (err error) {
+			err = s.impl.Emit(ctx, a0, a1)
+			return err
+		})
+		return
+	}
 	return s.impl.Emit(ctx, a0, a1)
 }
 
+func (s source_local_stub) Pids(ctx context.Context) (r0 []int, err error) {
+	// Update metrics.
+	begin := s.pidsMetrics.Begin()
+	defer func() { s.pidsMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.tracer, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Pids", "simple.Source.Pids", trace.SpanKindInternal)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source") {
+		err = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Pids", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Pids(ctx)
+			return err
+		})
+		return
+	}
+	return s.impl.Pids(ctx)
+}
+
 // Client stub implementations.
 
 type destination_client_stub struct {
 	stub                  codegen.Stub
+	flakyMetrics          *codegen.MethodMetrics
 	getAllMetrics         *codegen.MethodMetrics
+	getBaggageMetrics     *codegen.MethodMetrics
+	getDeadlineMetrics    *codegen.MethodMetrics
 	getMetadataMetrics    *codegen.MethodMetrics
 	getpidMetrics         *codegen.MethodMetrics
+	openMetrics           *codegen.MethodMetrics
 	recordMetrics         *codegen.MethodMetrics
 	routedRecordMetrics   *codegen.MethodMetrics
+	summarizeMetrics      *codegen.MethodMetrics
 	updateMetadataMetrics *codegen.MethodMetrics
 }
 
 // Check that destination_client_stub implements the Destination interface.
 var _ Destination = (*destination_client_stub)(nil)
 
-func (s destination_client_stub) GetAll(ctx context.Context, a0 string) (r0 []string, err error) {
+func (s destination_client_stub) Flaky(ctx context.Context, a0 string, a1 int, a2 bool) (r0 int, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.getAllMetrics.Begin()
-	defer func() { s.getAllMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.flakyMetrics.Begin()
+	defer func() { s.flakyMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Destination.GetAll", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Flaky", "simple.Destination.Flaky", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -373,6 +715,7 @@ func (s destination_client_stub) GetAll(ctx context.Context, a0 string) (r0 []st
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.flakyMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -383,45 +726,57 @@ func (s destination_client_stub) GetAll(ctx context.Context, a0 string) (r0 []st
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
 	size := 0
 	size += (4 + len(a0))
+	size += 8
+	size += 1
 	enc := codegen.NewEncoder()
 	enc.Reset(size)
 
 	// Encode arguments.
 	enc.String(a0)
+	enc.Int(a1)
+	enc.Bool(a2)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_string_4af10117(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s destination_client_stub) GetMetadata(ctx context.Context) (r0 map[string]string, err error) {
+func (s destination_client_stub) GetAll(ctx context.Context, a0 string) (r0 []string, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.getMetadataMetrics.Begin()
-	defer func() { s.getMetadataMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.getAllMetrics.Begin()
+	defer func() { s.getAllMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Destination.GetMetadata", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetAll", "simple.Destination.GetAll", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -429,6 +784,7 @@ func (s destination_client_stub) GetMetadata(ctx context.Context) (r0 map[string
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getAllMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -439,36 +795,53 @@ func (s destination_client_stub) GetMetadata(ctx context.Context) (r0 map[string
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
 	}()
 
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_map_string_string_219dd46d(dec)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_string_4af10117(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s destination_client_stub) Getpid(ctx context.Context) (r0 int, err error) {
+func (s destination_client_stub) GetBaggage(ctx context.Context) (r0 map[string]string, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.getpidMetrics.Begin()
-	defer func() { s.getpidMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.getBaggageMetrics.Begin()
+	defer func() { s.getBaggageMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Destination.Getpid", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetBaggage", "simple.Destination.GetBaggage", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -476,6 +849,7 @@ func (s destination_client_stub) Getpid(ctx context.Context) (r0 int, err error)
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getBaggageMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -486,36 +860,43 @@ func (s destination_client_stub) Getpid(ctx context.Context) (r0 int, err error)
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 2, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 2, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 2, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.Int()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_map_string_string_219dd46d(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s destination_client_stub) Record(ctx context.Context, a0 string, a1 string) (err error) {
+func (s destination_client_stub) GetDeadline(ctx context.Context) (r0 time.Duration, r1 bool, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.recordMetrics.Begin()
-	defer func() { s.recordMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.getDeadlineMetrics.Begin()
+	defer func() { s.getDeadlineMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Destination.Record", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetDeadline", "simple.Destination.GetDeadline", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -523,6 +904,7 @@ func (s destination_client_stub) Record(ctx context.Context, a0 string, a1 strin
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getDeadlineMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -533,46 +915,44 @@ func (s destination_client_stub) Record(ctx context.Context, a0 string, a1 strin
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 3, begin, err)
 	}()
 
-	// Preallocate a buffer of the right size.
-	size := 0
-	size += (4 + len(a0))
-	size += (4 + len(a1))
-	enc := codegen.NewEncoder()
-	enc.Reset(size)
-
-	// Encode arguments.
-	enc.String(a0)
-	enc.String(a1)
 	var shardKey uint64
 
-	// Call the remote method.
-	requestBytes = len(enc.Data())
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 3, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		*(*int64)(&r0) = dec.Int64()
+		r1 = dec.Bool()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
-func (s destination_client_stub) RoutedRecord(ctx context.Context, a0 string, a1 string) (err error) {
+func (s destination_client_stub) GetMetadata(ctx context.Context) (r0 map[string]string, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
-	begin := s.routedRecordMetrics.Begin()
-	defer func() { s.routedRecordMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	begin := s.getMetadataMetrics.Begin()
+	defer func() { s.getMetadataMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Destination.RoutedRecord", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetMetadata", "simple.Destination.GetMetadata", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -580,6 +960,7 @@ func (s destination_client_stub) RoutedRecord(ctx context.Context, a0 string, a1
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.getMetadataMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -590,37 +971,359 @@ func (s destination_client_stub) RoutedRecord(ctx context.Context, a0 string, a1
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 4, begin, err)
 	}()
 
-	// Preallocate a buffer of the right size.
-	size := 0
-	size += (4 + len(a0))
-	size += (4 + len(a1))
-	enc := codegen.NewEncoder()
-	enc.Reset(size)
-
-	// Encode arguments.
-	enc.String(a0)
-	enc.String(a1)
-
-	// Set the shardKey.
-	var r destRouter
-	shardKey := _hashDestination(r.RoutedRecord(ctx, a0, a1))
+	var shardKey uint64
 
-	// Call the remote method.
-	requestBytes = len(enc.Data())
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 4, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_map_string_string_219dd46d(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
 	}
+}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+func (s destination_client_stub) Getpid(ctx context.Context) (r0 int, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getpidMetrics.Begin()
+	defer func() { s.getpidMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Getpid", "simple.Destination.Getpid", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.getpidMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 5, begin, err)
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 5, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s destination_client_stub) Open(ctx context.Context, a0 string) (r0 io.ReadCloser, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.openMetrics.Begin()
+	defer func() { s.openMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Open", "simple.Destination.Open", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.openMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 6, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	ctx = codegen.WithStream(ctx)
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 6, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = codegen.DecodeStream(ctx, s.stub, 6, shardKey, dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s destination_client_stub) Record(ctx context.Context, a0 string, a1 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.recordMetrics.Begin()
+	defer func() { s.recordMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Record", "simple.Destination.Record", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.recordMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 7, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 7, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s destination_client_stub) RoutedRecord(ctx context.Context, a0 string, a1 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.routedRecordMetrics.Begin()
+	defer func() { s.routedRecordMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "RoutedRecord", "simple.Destination.RoutedRecord", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.routedRecordMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 8, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+
+	// Set the shardKey.
+	var r destRouter
+	shardKey := _hashDestination(r.RoutedRecord(ctx, a0, a1))
+	if span.SpanContext().IsValid() && shardKey != 0 {
+		// Record the shard key to help debug hot shards.
+		span.SetAttributes(attribute.String("serviceweaver.shard_key", strconv.FormatUint(shardKey, 10)), attribute.String("serviceweaver.method", "simple.Destination.RoutedRecord"))
+	}
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 8, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
+}
+
+func (s destination_client_stub) Summarize(ctx context.Context, a0 string) (r0 Summary, r1 []string, r2 bool, r3 int, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.summarizeMetrics.Begin()
+	defer func() { s.summarizeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Summarize", "simple.Destination.Summarize", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.summarizeMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 9, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 9, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		(&r0).WeaverUnmarshal(dec)
+		r1 = serviceweaver_dec_slice_string_4af10117(dec)
+		r2 = dec.Bool()
+		r3 = dec.Int()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s destination_client_stub) UpdateMetadata(ctx context.Context) (err error) {
@@ -632,7 +1335,7 @@ func (s destination_client_stub) UpdateMetadata(ctx context.Context) (err error)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Destination.UpdateMetadata", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "UpdateMetadata", "simple.Destination.UpdateMetadata", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -640,6 +1343,7 @@ func (s destination_client_stub) UpdateMetadata(ctx context.Context) (err error)
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.updateMetadataMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -650,28 +1354,109 @@ func (s destination_client_stub) UpdateMetadata(ctx context.Context) (err error)
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 10, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 5, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 10, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
 	}
+}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+type greeter_client_stub struct {
+	stub         codegen.Stub
+	greetMetrics *codegen.MethodMetrics
+}
+
+// Check that greeter_client_stub implements the Greeter interface.
+var _ Greeter = (*greeter_client_stub)(nil)
+
+func (s greeter_client_stub) Greet(ctx context.Context, a0 string) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.greetMetrics.Begin()
+	defer func() { s.greetMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", "Greet", "simple.Greeter.Greet", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.greetMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	requestBytes = len(enc.Data())
+	defer enc.Release()
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.String()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type server_client_stub struct {
 	stub                codegen.Stub
 	addressMetrics      *codegen.MethodMetrics
+	healthyMetrics      *codegen.MethodMetrics
 	proxyAddressMetrics *codegen.MethodMetrics
 	shutdownMetrics     *codegen.MethodMetrics
 }
@@ -688,7 +1473,7 @@ func (s server_client_stub) Address(ctx context.Context) (r0 string, err error)
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Server.Address", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Address", "simple.Server.Address", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -696,6 +1481,7 @@ func (s server_client_stub) Address(ctx context.Context) (r0 string, err error)
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.addressMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -706,24 +1492,85 @@ func (s server_client_stub) Address(ctx context.Context) (r0 string, err error)
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.String()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
 	}
+}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.String()
-	err = dec.Error()
-	return
+func (s server_client_stub) Healthy(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthyMetrics.Begin()
+	defer func() { s.healthyMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Healthy", "simple.Server.Healthy", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.healthyMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s server_client_stub) ProxyAddress(ctx context.Context) (r0 string, err error) {
@@ -735,7 +1582,7 @@ func (s server_client_stub) ProxyAddress(ctx context.Context) (r0 string, err er
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Server.ProxyAddress", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "ProxyAddress", "simple.Server.ProxyAddress", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -743,6 +1590,7 @@ func (s server_client_stub) ProxyAddress(ctx context.Context) (r0 string, err er
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.proxyAddressMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -753,24 +1601,31 @@ func (s server_client_stub) ProxyAddress(ctx context.Context) (r0 string, err er
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 2, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 2, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.String()
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = dec.String()
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 func (s server_client_stub) Shutdown(ctx context.Context) (err error) {
@@ -782,7 +1637,7 @@ func (s server_client_stub) Shutdown(ctx context.Context) (err error) {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Server.Shutdown", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Shutdown", "simple.Server.Shutdown", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -790,6 +1645,7 @@ func (s server_client_stub) Shutdown(ctx context.Context) (err error) {
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.shutdownMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -800,28 +1656,36 @@ func (s server_client_stub) Shutdown(ctx context.Context) (err error) {
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 3, begin, err)
 	}()
 
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	var results []byte
-	results, err = s.stub.Run(ctx, 2, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 3, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 type source_client_stub struct {
 	stub        codegen.Stub
 	emitMetrics *codegen.MethodMetrics
+	pidsMetrics *codegen.MethodMetrics
 }
 
 // Check that source_client_stub implements the Source interface.
@@ -836,7 +1700,7 @@ func (s source_client_stub) Emit(ctx context.Context, a0 string, a1 string) (err
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "simple.Source.Emit", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Emit", "simple.Source.Emit", trace.SpanKindClient)
 	}
 
 	defer func() {
@@ -844,6 +1708,7 @@ func (s source_client_stub) Emit(ctx context.Context, a0 string, a1 string) (err
 		if err == nil {
 			err = codegen.CatchPanics(recover())
 			if err != nil {
+				s.emitMetrics.SerializationError()
 				err = errors.Join(weaver.RemoteCallError, err)
 			}
 		}
@@ -854,6 +1719,8 @@ func (s source_client_stub) Emit(ctx context.Context, a0 string, a1 string) (err
 		}
 		span.End()
 
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 0, begin, err)
 	}()
 
 	// Preallocate a buffer of the right size.
@@ -868,29 +1735,90 @@ func (s source_client_stub) Emit(ctx context.Context, a0 string, a1 string) (err
 	enc.String(a1)
 	var shardKey uint64
 
-	// Call the remote method.
+	// Call the remote method, retrying it while it returns a retryable error.
 	requestBytes = len(enc.Data())
+	defer enc.Release()
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 0, enc.Data(), shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
 	}
+}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+func (s source_client_stub) Pids(ctx context.Context) (r0 []int, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.pidsMetrics.Begin()
+	defer func() { s.pidsMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = codegen.StartSpan(ctx, s.stub.Tracer(), "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Pids", "simple.Source.Pids", trace.SpanKindClient)
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				s.pidsMetrics.SerializationError()
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Log the call, if sampled.
+		codegen.LogAccess(ctx, s.stub, 1, begin, err)
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method, retrying it while it returns a retryable error.
+	var results []byte
+	var retrier codegen.Retrier
+	for {
+		results, err = codegen.Run(ctx, s.stub, &begin, 1, nil, shardKey)
+		replyBytes = len(results)
+		if err != nil {
+			err = errors.Join(weaver.RemoteCallError, err)
+			return
+		}
+
+		// Decode the results.
+		dec := codegen.NewResultDecoder(ctx, results)
+		r0 = serviceweaver_dec_slice_int_7c8c8866(dec)
+		err = dec.Error()
+		if !retrier.Retry(ctx, err) {
+			return
+		}
+	}
 }
 
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
-var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+var _ codegen.LatestVersion = codegen.Version[[0][41]struct{}](`
 
 ERROR: You generated this file with 'weaver generate' (devel) (codegen
-version v0.24.0). The generated code is incompatible with the version of the
+version v0.41.0). The generated code is incompatible with the version of the
 github.com/ServiceWeaver/weaver module that you're using. The weaver module
 version can be found in your go.mod file or by running the following command.
 
@@ -920,16 +1848,26 @@ var _ codegen.Server = (*destination_server_stub)(nil)
 // GetStubFn implements the codegen.Server interface.
 func (s destination_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
 	switch method {
+	case "Flaky":
+		return s.flaky
 	case "GetAll":
 		return s.getAll
+	case "GetBaggage":
+		return s.getBaggage
+	case "GetDeadline":
+		return s.getDeadline
 	case "GetMetadata":
 		return s.getMetadata
 	case "Getpid":
 		return s.getpid
+	case "Open":
+		return s.open
 	case "Record":
 		return s.record
 	case "RoutedRecord":
 		return s.routedRecord
+	case "Summarize":
+		return s.summarize
 	case "UpdateMetadata":
 		return s.updateMetadata
 	default:
@@ -937,27 +1875,164 @@ func (s destination_server_stub) GetStubFn(method string) func(ctx context.Conte
 	}
 }
 
-func (s destination_server_stub) getAll(ctx context.Context, args []byte) (res []byte, err error) {
+func (s destination_server_stub) flaky(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 int
+	var a2 bool
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Flaky", r, a0, a1, a2)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.String()
+	a1 = dec.Int()
+	a2 = dec.Bool()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Flaky", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Flaky(ctx, a0, a1, a2)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Flaky(ctx, a0, a1, a2)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Int(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s destination_server_stub) getAll(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetAll", r, a0)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.String()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 []string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetAll", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetAll(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetAll(ctx, a0)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s destination_server_stub) getBaggage(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetBaggage", recover())
+		}
+	}()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 map[string]string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetBaggage", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetBaggage(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetBaggage(ctx)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_map_string_string_219dd46d(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s destination_server_stub) getDeadline(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetDeadline", recover())
 		}
 	}()
 
-	// Decode arguments.
-	dec := codegen.NewDecoder(args)
-	var a0 string
-	a0 = dec.String()
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
 
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetAll(ctx, a0)
+	var r0 time.Duration
+	var r1 bool
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetDeadline", func(ctx context.Context) (err error) {
+			r0, r1, err = s.impl.GetDeadline(ctx)
+			return err
+		})
+	} else {
+		r0, r1, appErr = s.impl.GetDeadline(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
-	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Int64((int64)(r0))
+	enc.Bool(r1)
 	enc.Error(appErr)
 	return enc.Data(), nil
 }
@@ -966,14 +2041,28 @@ func (s destination_server_stub) getMetadata(ctx context.Context, args []byte) (
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetMetadata", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetMetadata(ctx)
+	var r0 map[string]string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "GetMetadata", func(ctx context.Context) (err error) {
+			r0, err = s.impl.GetMetadata(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.GetMetadata(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -986,14 +2075,28 @@ func (s destination_server_stub) getpid(ctx context.Context, args []byte) (res [
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Getpid", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Getpid(ctx)
+	var r0 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Getpid", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Getpid(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Getpid(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1002,25 +2105,92 @@ func (s destination_server_stub) getpid(ctx context.Context, args []byte) (res [
 	return enc.Data(), nil
 }
 
-func (s destination_server_stub) record(ctx context.Context, args []byte) (res []byte, err error) {
+func (s destination_server_stub) open(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Open", r, a0)
+			}
 		}
 	}()
 
+	// Serve the request for the next chunk of a streamed result, if it is one.
+	if res, ok := codegen.ServeStream(ctx, args); ok {
+		return res, nil
+	}
+
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var r0 io.ReadCloser
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Open", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Open(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Open(ctx, a0)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	codegen.EncodeStream(ctx, enc, r0, appErr)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s destination_server_stub) record(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
 	var a1 string
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Record", r, a0, a1)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.String()
 	a1 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Record(ctx, a0, a1)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Record", func(ctx context.Context) (err error) {
+			err = s.impl.Record(ctx, a0, a1)
+			return err
+		})
+	} else {
+		appErr = s.impl.Record(ctx, a0, a1)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1029,29 +2199,97 @@ func (s destination_server_stub) record(ctx context.Context, args []byte) (res [
 }
 
 func (s destination_server_stub) routedRecord(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "RoutedRecord", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 string
 	a1 = dec.String()
 	var r destRouter
 	s.addLoad(_hashDestination(r.RoutedRecord(ctx, a0, a1)), 1.0)
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "RoutedRecord", func(ctx context.Context) (err error) {
+			err = s.impl.RoutedRecord(ctx, a0, a1)
+			return err
+		})
+	} else {
+		appErr = s.impl.RoutedRecord(ctx, a0, a1)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s destination_server_stub) summarize(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Summarize", r, a0)
+			}
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.String()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.RoutedRecord(ctx, a0, a1)
+	var r0 Summary
+	var r1 []string
+	var r2 bool
+	var r3 int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "Summarize", func(ctx context.Context) (err error) {
+			r0, r1, r2, r3, err = s.impl.Summarize(ctx, a0)
+			return err
+		})
+	} else {
+		r0, r1, r2, r3, appErr = s.impl.Summarize(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	serviceweaver_enc_slice_string_4af10117(enc, r1)
+	enc.Bool(r2)
+	enc.Int(r3)
 	enc.Error(appErr)
 	return enc.Data(), nil
 }
@@ -1060,17 +2298,92 @@ func (s destination_server_stub) updateMetadata(ctx context.Context, args []byte
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "UpdateMetadata", recover())
+		}
+	}()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Destination", "UpdateMetadata", func(ctx context.Context) (err error) {
+			err = s.impl.UpdateMetadata(ctx)
+			return err
+		})
+	} else {
+		appErr = s.impl.UpdateMetadata(ctx)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type greeter_server_stub struct {
+	impl    Greeter
+	addLoad func(key uint64, load float64)
+}
+
+// Check that greeter_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*greeter_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s greeter_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Greet":
+		return s.greet
+	default:
+		return nil
+	}
+}
+
+func (s greeter_server_stub) greet(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", "Greet", r, a0)
+			}
 		}
 	}()
 
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
+	a0 = dec.String()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.UpdateMetadata(ctx)
+	var r0 string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Greeter", "Greet", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Greet(ctx, a0)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Greet(ctx, a0)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
+	enc.String(r0)
 	enc.Error(appErr)
 	return enc.Data(), nil
 }
@@ -1088,6 +2401,8 @@ func (s server_server_stub) GetStubFn(method string) func(ctx context.Context, a
 	switch method {
 	case "Address":
 		return s.address
+	case "Healthy":
+		return s.healthy
 	case "ProxyAddress":
 		return s.proxyAddress
 	case "Shutdown":
@@ -1101,14 +2416,28 @@ func (s server_server_stub) address(ctx context.Context, args []byte) (res []byt
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Address", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.Address(ctx)
+	var r0 string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Address", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Address(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Address(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1117,18 +2446,64 @@ func (s server_server_stub) address(ctx context.Context, args []byte) (res []byt
 	return enc.Data(), nil
 }
 
+func (s server_server_stub) healthy(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Healthy", recover())
+		}
+	}()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Healthy", func(ctx context.Context) (err error) {
+			err = s.impl.Healthy(ctx)
+			return err
+		})
+	} else {
+		appErr = s.impl.Healthy(ctx)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 func (s server_server_stub) proxyAddress(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "ProxyAddress", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.ProxyAddress(ctx)
+	var r0 string
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "ProxyAddress", func(ctx context.Context) (err error) {
+			r0, err = s.impl.ProxyAddress(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.ProxyAddress(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1141,14 +2516,27 @@ func (s server_server_stub) shutdown(ctx context.Context, args []byte) (res []by
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Shutdown", recover())
 		}
 	}()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Shutdown(ctx)
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Server", "Shutdown", func(ctx context.Context) (err error) {
+			err = s.impl.Shutdown(ctx)
+			return err
+		})
+	} else {
+		appErr = s.impl.Shutdown(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
@@ -1169,33 +2557,87 @@ func (s source_server_stub) GetStubFn(method string) func(ctx context.Context, a
 	switch method {
 	case "Emit":
 		return s.emit
+	case "Pids":
+		return s.pids
 	default:
 		return nil
 	}
 }
 
 func (s source_server_stub) emit(ctx context.Context, args []byte) (res []byte, err error) {
+	// Arguments, reported to panic handlers if the call panics.
+	var a0 string
+	var a1 string
+
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
-			err = codegen.CatchPanics(recover())
+			if r := recover(); r != nil {
+				err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Emit", r, a0, a1)
+			}
 		}
 	}()
 
 	// Decode arguments.
 	dec := codegen.NewDecoder(args)
-	var a0 string
+	dec.SetMaxAlloc(codegen.MaxServerAlloc)
 	a0 = dec.String()
-	var a1 string
 	a1 = dec.String()
 
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Emit", func(ctx context.Context) (err error) {
+			err = s.impl.Emit(ctx, a0, a1)
+			return err
+		})
+	} else {
+		appErr = s.impl.Emit(ctx, a0, a1)
+	}
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s source_server_stub) pids(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchServerPanics("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Pids", recover())
+		}
+	}()
+
+	// Reject the call if the component is overloaded.
+	if err := codegen.CheckShed(ctx); err != nil {
+		return nil, err
+	}
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	appErr := s.impl.Emit(ctx, a0, a1)
+	var r0 []int
+	var appErr error
+	if codegen.Intercepted("github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source") {
+		appErr = codegen.Intercept(ctx, "github.com/ServiceWeaver/weaver/weavertest/internal/simple/Source", "Pids", func(ctx context.Context) (err error) {
+			r0, err = s.impl.Pids(ctx)
+			return err
+		})
+	} else {
+		r0, appErr = s.impl.Pids(ctx)
+	}
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_int_7c8c8866(enc, r0)
 	enc.Error(appErr)
 	return enc.Data(), nil
 }
@@ -1209,11 +2651,26 @@ type destination_reflect_stub struct {
 // Check that destination_reflect_stub implements the Destination interface.
 var _ Destination = (*destination_reflect_stub)(nil)
 
+func (s destination_reflect_stub) Flaky(ctx context.Context, a0 string, a1 int, a2 bool) (r0 int, err error) {
+	err = s.caller("Flaky", ctx, []any{a0, a1, a2}, []any{&r0})
+	return
+}
+
 func (s destination_reflect_stub) GetAll(ctx context.Context, a0 string) (r0 []string, err error) {
 	err = s.caller("GetAll", ctx, []any{a0}, []any{&r0})
 	return
 }
 
+func (s destination_reflect_stub) GetBaggage(ctx context.Context) (r0 map[string]string, err error) {
+	err = s.caller("GetBaggage", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s destination_reflect_stub) GetDeadline(ctx context.Context) (r0 time.Duration, r1 bool, err error) {
+	err = s.caller("GetDeadline", ctx, []any{}, []any{&r0, &r1})
+	return
+}
+
 func (s destination_reflect_stub) GetMetadata(ctx context.Context) (r0 map[string]string, err error) {
 	err = s.caller("GetMetadata", ctx, []any{}, []any{&r0})
 	return
@@ -1224,6 +2681,11 @@ func (s destination_reflect_stub) Getpid(ctx context.Context) (r0 int, err error
 	return
 }
 
+func (s destination_reflect_stub) Open(ctx context.Context, a0 string) (r0 io.ReadCloser, err error) {
+	err = s.caller("Open", ctx, []any{a0}, []any{&r0})
+	return
+}
+
 func (s destination_reflect_stub) Record(ctx context.Context, a0 string, a1 string) (err error) {
 	err = s.caller("Record", ctx, []any{a0, a1}, []any{})
 	return
@@ -1234,11 +2696,28 @@ func (s destination_reflect_stub) RoutedRecord(ctx context.Context, a0 string, a
 	return
 }
 
+func (s destination_reflect_stub) Summarize(ctx context.Context, a0 string) (r0 Summary, r1 []string, r2 bool, r3 int, err error) {
+	err = s.caller("Summarize", ctx, []any{a0}, []any{&r0, &r1, &r2, &r3})
+	return
+}
+
 func (s destination_reflect_stub) UpdateMetadata(ctx context.Context) (err error) {
 	err = s.caller("UpdateMetadata", ctx, []any{}, []any{})
 	return
 }
 
+type greeter_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that greeter_reflect_stub implements the Greeter interface.
+var _ Greeter = (*greeter_reflect_stub)(nil)
+
+func (s greeter_reflect_stub) Greet(ctx context.Context, a0 string) (r0 string, err error) {
+	err = s.caller("Greet", ctx, []any{a0}, []any{&r0})
+	return
+}
+
 type server_reflect_stub struct {
 	caller func(string, context.Context, []any, []any) error
 }
@@ -1251,6 +2730,11 @@ func (s server_reflect_stub) Address(ctx context.Context) (r0 string, err error)
 	return
 }
 
+func (s server_reflect_stub) Healthy(ctx context.Context) (err error) {
+	err = s.caller("Healthy", ctx, []any{}, []any{})
+	return
+}
+
 func (s server_reflect_stub) ProxyAddress(ctx context.Context) (r0 string, err error) {
 	err = s.caller("ProxyAddress", ctx, []any{}, []any{&r0})
 	return
@@ -1273,6 +2757,51 @@ func (s source_reflect_stub) Emit(ctx context.Context, a0 string, a1 string) (er
 	return
 }
 
+func (s source_reflect_stub) Pids(ctx context.Context) (r0 []int, err error) {
+	err = s.caller("Pids", ctx, []any{}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*Summary)(nil)
+
+type __is_Summary[T ~struct {
+	weaver.AutoMarshal
+	File  string
+	Count int
+}] struct{}
+
+var _ __is_Summary[Summary]
+
+func (x *Summary) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Summary.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.File)
+	enc.Int(x.Count)
+}
+
+func (x *Summary) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Summary.WeaverUnmarshal: nil receiver"))
+	}
+	x.File = dec.String()
+	x.Count = dec.Int()
+}
+
+// DebugString returns a JSON rendering of the fields of x that are
+// serialized by WeaverMarshal.
+func (x *Summary) DebugString() string {
+	if x == nil {
+		return "null"
+	}
+	return codegen.DebugString(
+		codegen.DebugField{Name: "File", Value: x.File},
+		codegen.DebugField{Name: "Count", Value: x.Count},
+	)
+}
+
 // Router methods.
 
 // _hashDestination returns a 64 bit hash of the provided value.
@@ -1307,7 +2836,7 @@ func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
 	if n == -1 {
 		return nil
 	}
-	res := make([]string, n)
+	res := codegen.MakeSlice[string](dec, n)
 	for i := 0; i < n; i++ {
 		res[i] = dec.String()
 	}
@@ -1341,3 +2870,26 @@ func serviceweaver_dec_map_string_string_219dd46d(dec *codegen.Decoder) map[stri
 	}
 	return res
 }
+
+func serviceweaver_enc_slice_int_7c8c8866(enc *codegen.Encoder, arg []int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Int(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := codegen.MakeSlice[int](dec, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Int()
+	}
+	return res
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package versioned contains a component that receives versioned entities,
+// used to test optimistic concurrency control.
+package versioned
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// Account is a bank account. Its Version is advanced on every update.
+type Account struct {
+	weaver.AutoMarshal
+	Name    string
+	Balance int
+	Version uint64 `weaver:"version"`
+}
+
+// Bank stores accounts.
+type Bank interface {
+	Get(context.Context, string) (Account, error)
+	Update(context.Context, Account) error
+	Transfer(ctx context.Context, from, to Account, amount int) error
+}
+
+// Retrying an Update or a Transfer that succeeded would fail with
+// weaver.ConflictError.
+var (
+	_ weaver.NotRetriable = Bank.Update
+	_ weaver.NotRetriable = Bank.Transfer
+)
+
+type bank struct {
+	weaver.Implements[Bank]
+	versionMu sync.Mutex // serializes the calls to CompareAndSet
+	mu        sync.Mutex // guards accounts
+	accounts  map[string]Account
+}
+
+func (b *bank) Init(context.Context) error {
+	b.accounts = map[string]Account{
+		"alice": {Name: "alice", Balance: 100},
+		"bob":   {Name: "bob", Balance: 100},
+	}
+	return nil
+}
+
+func (b *bank) Get(_ context.Context, name string) (Account, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	account, ok := b.accounts[name]
+	if !ok {
+		return Account{}, fmt.Errorf("account %q not found", name)
+	}
+	return account, nil
+}
+
+// Update stores the provided account. It is invoked by CompareAndSet only if
+// the account's version is current.
+func (b *bank) Update(_ context.Context, account Account) error {
+	if account.Balance < 0 {
+		return fmt.Errorf("account %q: negative balance %d", account.Name, account.Balance)
+	}
+	// Give concurrent updates of the account a chance to interleave.
+	time.Sleep(time.Millisecond)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	account.Version = b.accounts[account.Name].Version
+	b.accounts[account.Name] = account
+	return nil
+}
+
+// Transfer moves amount from one account to another.
+func (b *bank) Transfer(_ context.Context, from, to Account, amount int) error {
+	if from.Balance < amount {
+		return fmt.Errorf("account %q: insufficient balance %d", from.Name, from.Balance)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	from.Balance -= amount
+	to.Balance += amount
+	from.Version = b.accounts[from.Name].Version
+	to.Version = b.accounts[to.Name].Version
+	b.accounts[from.Name] = from
+	b.accounts[to.Name] = to
+	return nil
+}
+
+// CompareAndSet implements the codegen.VersionChecker interface.
+func (b *bank) CompareAndSet(_ context.Context, entities []any, versions []uint64, apply func() error) (bool, error) {
+	b.versionMu.Lock()
+	defer b.versionMu.Unlock()
+
+	b.mu.Lock()
+	for i, entity := range entities {
+		account, ok := entity.(*Account)
+		if !ok {
+			b.mu.Unlock()
+			return false, fmt.Errorf("unexpected entity type %T", entity)
+		}
+		stored, ok := b.accounts[account.Name]
+		if !ok {
+			b.mu.Unlock()
+			return false, fmt.Errorf("account %q not found", account.Name)
+		}
+		if stored.Version != versions[i] {
+			b.mu.Unlock()
+			return false, nil
+		}
+	}
+	b.mu.Unlock()
+
+	if err := apply(); err != nil {
+		return false, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, entity := range entities {
+		stored := b.accounts[entity.(*Account).Name]
+		stored.Version++
+		b.accounts[stored.Name] = stored
+	}
+	return true, nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioned
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+//go:generate ../../../cmd/weaver/weaver generate ./...
+
+// TestUpdate tests that an update of an up-to-date account succeeds and
+// advances the account's version.
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, b Bank) {
+			account, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			account.Balance += 10
+			if err := b.Update(ctx, account); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := 110; got.Balance != want {
+				t.Fatalf("balance: got %d, want %d", got.Balance, want)
+			}
+			if want := account.Version + 1; got.Version != want {
+				t.Fatalf("version: got %d, want %d", got.Version, want)
+			}
+		})
+	}
+}
+
+// TestConflictingUpdate tests that an update of a stale account fails with
+// weaver.ConflictError and leaves the stored account unchanged.
+func TestConflictingUpdate(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, b Bank) {
+			stale, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fresh := stale
+			fresh.Balance += 10
+			if err := b.Update(ctx, fresh); err != nil {
+				t.Fatal(err)
+			}
+
+			stale.Balance -= 10
+			err = b.Update(ctx, stale)
+			if !errors.Is(err, weaver.ConflictError) {
+				t.Fatalf("Update: got %v, want %v", err, weaver.ConflictError)
+			}
+
+			got, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := 110; got.Balance != want {
+				t.Fatalf("balance: got %d, want %d", got.Balance, want)
+			}
+		})
+	}
+}
+
+// TestFailedUpdate tests that an update that fails doesn't advance the
+// account's version.
+func TestFailedUpdate(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, b Bank) {
+			account, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			overdrawn := account
+			overdrawn.Balance = -1
+			if err := b.Update(ctx, overdrawn); err == nil {
+				t.Fatal("Update: unexpected success")
+			}
+
+			// The account is still current.
+			account.Balance += 10
+			if err := b.Update(ctx, account); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestConflictingTransfer tests that a transfer to a stale account fails with
+// weaver.ConflictError without advancing the version of the source account.
+func TestConflictingTransfer(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, b Bank) {
+			alice, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			bob, err := b.Get(ctx, "bob")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fresh := bob
+			fresh.Balance += 10
+			if err := b.Update(ctx, fresh); err != nil {
+				t.Fatal(err)
+			}
+
+			err = b.Transfer(ctx, alice, bob, 10)
+			if !errors.Is(err, weaver.ConflictError) {
+				t.Fatalf("Transfer: got %v, want %v", err, weaver.ConflictError)
+			}
+
+			// The source account is still current.
+			got, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != alice {
+				t.Fatalf("alice: got %+v, want %+v", got, alice)
+			}
+			bob.Balance = fresh.Balance
+			bob.Version++
+			if err := b.Transfer(ctx, alice, bob, 10); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestConcurrentUpdates tests that concurrent deposits of different amounts to
+// the same account don't lose any deposit: every update that fails with
+// weaver.ConflictError is retried with a fresh account, and no failed update
+// is applied.
+func TestConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, b Bank) {
+			const n = 20
+			var wait sync.WaitGroup
+			want := 100
+			for i := 0; i < n; i++ {
+				amount := i + 1
+				want += amount
+				wait.Add(1)
+				go func() {
+					defer wait.Done()
+					for {
+						account, err := b.Get(ctx, "alice")
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						account.Balance += amount
+						err = b.Update(ctx, account)
+						if errors.Is(err, weaver.ConflictError) {
+							continue
+						}
+						if err != nil {
+							t.Error(err)
+						}
+						return
+					}
+				}()
+			}
+			wait.Wait()
+
+			got, err := b.Get(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Balance != want {
+				t.Fatalf("balance: got %d, want %d", got.Balance, want)
+			}
+		})
+	}
+}